@@ -23,6 +23,7 @@ var (
 	createPriority string
 	createAssignee string
 	createTags     []string
+	createSet      []string
 )
 
 var createCmd = &cobra.Command{
@@ -41,9 +42,9 @@ var createCmd = &cobra.Command{
 		}
 
 		// Get repository path
-		repoPath, err := os.Getwd()
+		repoPath, err := findRepoRoot()
 		if err != nil {
-			return fmt.Errorf("failed to determine working directory: %w", err)
+			return fmt.Errorf("not a git repository: %w", err)
 		}
 
 		// Determine story directory using workspace structure (default consolidated).
@@ -59,16 +60,23 @@ var createCmd = &cobra.Command{
 
 		// Create service dependencies
 		idGenerator := filesystem.NewIDCounter(repoPath)
-		parser := filesystem.NewMarkdownParser()
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		fields, err := parseFieldAssignments(projectConfig, createSet)
+		if err != nil {
+			return err
+		}
 		storyRepo := filesystem.NewRepository(parser)
-		createService := services.NewCreateService(idGenerator, parser, storyRepo, storyDir)
+		createService := services.NewCreateServiceWithTransactions(idGenerator, parser, storyRepo, storyDir, loadEventBus(repoPath, projectConfig), loadTransactions(repoPath))
 
 		// Parse status and priority
 		var status core.Status
 		if createStatus != "" {
 			status = core.Status(createStatus)
 		} else {
-			status = core.StatusTodo
+			status = projectConfig.Workflow.Initial
 		}
 
 		var priority core.Priority
@@ -80,13 +88,15 @@ var createCmd = &cobra.Command{
 
 		// Build request
 		req := services.CreateStoryRequest{
-			Title:    createTitle,
-			Prefix:   createPrefix,
-			Template: createTemplate,
-			Editor:   createEditor,
-			Status:   status,
-			Priority: priority,
-			Tags:     createTags,
+			Title:            createTitle,
+			Prefix:           createPrefix,
+			Template:         createTemplate,
+			Editor:           createEditor,
+			Status:           status,
+			Priority:         priority,
+			Tags:             createTags,
+			Fields:           fields,
+			FieldDefinitions: projectConfig.Fields,
 		}
 		if createAssignee != "" {
 			req.Assignee = &createAssignee
@@ -138,10 +148,11 @@ func init() {
 	createCmd.Flags().StringVar(&createPrefix, "prefix", "US", "ID prefix (e.g., US, BUG, TS)")
 	createCmd.Flags().StringVar(&createTemplate, "template", "", "Path to custom template file (default: built-in template)")
 	createCmd.Flags().StringVar(&createEditor, "editor", "", "Editor command to use (default: $EDITOR env var, fallback: vi)")
-	createCmd.Flags().StringVar(&createStatus, "status", "todo", "Initial status (todo, doing, review, done)")
+	createCmd.Flags().StringVar(&createStatus, "status", "", "Initial status (default: the workflow's initial state, todo)")
 	createCmd.Flags().StringVar(&createPriority, "priority", "medium", "Initial priority (low, medium, high, critical)")
 	createCmd.Flags().StringVar(&createAssignee, "assignee", "", "Initial assignee")
 	createCmd.Flags().StringArrayVar(&createTags, "tag", []string{}, "Initial tags (can be specified multiple times)")
+	createCmd.Flags().StringArrayVar(&createSet, "set", []string{}, "Set a custom field declared in .gitta/config.yaml (key=value, can be specified multiple times)")
 
 	// Mark title as required
 	createCmd.MarkFlagRequired("title")
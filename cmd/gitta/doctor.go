@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
 	"github.com/gavin/gitta/internal/core"
 	"github.com/gavin/gitta/internal/services"
 	"github.com/spf13/cobra"
@@ -15,18 +17,28 @@ import (
 
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
-	Short: "Detect and repair sprint status inconsistencies",
+	Short: "Detect and repair sprint status and story branch inconsistencies",
 	Long: `Detect and repair inconsistencies between visual indicators (folder name prefixes)
-and authoritative status files (.gitta/status).
+and authoritative status files (.gitta/status), and between story files and Git branches.
 
 Scans all sprints and compares folder name prefixes with .gitta/status files.
-Reports all inconsistencies found. Use --fix to automatically repair them.
+Also reports story problems:
+  - story branches whose story file no longer exists (orphans)
+  - stories marked done whose branch is not merged
+  - merged story branches that have not been deleted
+  - stories stuck in progress with no commits for --stale-days days
+  - duplicate story IDs across directories
+
+Use --fix to automatically repair what is safe: sprint folders are renamed,
+merged branches are deleted and orphan branches are archived under archive/.
 
 Examples:
   gitta doctor                    # Check for inconsistencies (report only)
   gitta doctor --fix              # Check and automatically fix
   gitta doctor --sprint Sprint_24 # Check specific sprint only
-  gitta doctor --json             # Output result as JSON`,
+  gitta doctor --stale-days 7     # Report in-progress stories idle for more than a week
+  gitta doctor --json             # Output result as JSON
+  gitta doctor --format '{{.status}}: {{len .story_issues}} story issues'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		if ctx == nil {
@@ -37,11 +49,22 @@ Examples:
 			return fmt.Errorf("not a git repository: %w", err)
 		}
 
+		tmpl, err := formatOutput(cmd)
+		if err != nil {
+			return err
+		}
+
 		fix, _ := cmd.Flags().GetBool("fix")
 		sprintPath, _ := cmd.Flags().GetString("sprint")
+		staleDays, _ := cmd.Flags().GetInt("stale-days")
 
-		sprintRepo := filesystem.NewDefaultRepository()
-		doctorService := services.NewSprintDoctorService(sprintRepo, repoPath)
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		sprintRepo := filesystem.NewRepository(parser)
+		events := loadEventBus(repoPath, projectConfig)
+		doctorService := services.NewSprintDoctorServiceWithTransactions(sprintRepo, repoPath, events, loadTransactions(repoPath))
 
 		var inconsistencies []services.Inconsistency
 		if sprintPath != "" {
@@ -82,18 +105,42 @@ Examples:
 			}
 		}
 
-		if jsonOutput {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
+		// Story/branch checks are repository-wide and skipped when a single sprint is requested.
+		var storyIssues []services.StoryIssue
+		var storyDoctor services.StoryDoctorService
+		if sprintPath == "" {
+			gitRepo := git.NewRepository()
+			storyDoctor = services.NewStoryDoctorServiceWithEvents(sprintRepo, sprintRepo, gitRepo, gitRepo, repoPath, projectConfig.Workflow, events)
+			storyIssues, err = storyDoctor.DetectStoryIssues(ctx, services.StoryDoctorOptions{
+				StaleAfter: time.Duration(staleDays) * 24 * time.Hour,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to detect story issues: %w", err)
+			}
+		}
+
+		if tmpl != nil || jsonOutput {
+			if storyIssues == nil {
+				storyIssues = []services.StoryIssue{}
+			}
+			if inconsistencies == nil {
+				inconsistencies = []services.Inconsistency{}
+			}
 			output := map[string]interface{}{
 				"status":             "ok",
 				"sprints_checked":    len(inconsistencies), // Count of inconsistent sprints
 				"inconsistencies":    inconsistencies,
 				"current_link_valid": currentLinkValid,
+				"story_issues":       storyIssues,
 			}
-			if len(inconsistencies) > 0 {
+			if len(inconsistencies) > 0 || len(storyIssues) > 0 {
 				output["status"] = "inconsistencies_found"
 			}
+			if tmpl != nil {
+				return writeTemplate(tmpl, output)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
 			return enc.Encode(output)
 		}
 
@@ -107,7 +154,7 @@ Examples:
 			} else {
 				fmt.Println("✗ Current link is invalid or missing")
 			}
-			return nil
+			return reportStoryIssues(ctx, storyDoctor, storyIssues, fix)
 		}
 
 		fmt.Printf("✗ Found %d inconsistencies:\n\n", len(inconsistencies))
@@ -119,6 +166,7 @@ Examples:
 		}
 
 		if !fix {
+			printStoryIssues(storyIssues)
 			fmt.Println("Run with --fix to repair these issues.")
 			return fmt.Errorf("inconsistencies found")
 		}
@@ -148,12 +196,80 @@ Examples:
 			fmt.Printf("\n✓ All inconsistencies repaired\n")
 		}
 
-		return nil
+		return reportStoryIssues(ctx, storyDoctor, storyIssues, fix)
 	},
 }
 
+// reportStoryIssues prints story/branch issues and, when fix is set, applies safe repairs.
+func reportStoryIssues(ctx context.Context, doctor services.StoryDoctorService, issues []services.StoryIssue, fix bool) error {
+	if doctor == nil {
+		return nil
+	}
+
+	fmt.Println("\nChecking stories and branches...")
+	if len(issues) == 0 {
+		fmt.Println("✓ No story or branch issues found")
+		return nil
+	}
+
+	printStoryIssues(issues)
+
+	if !fix {
+		fmt.Println("Run with --fix to repair fixable issues.")
+		return fmt.Errorf("story issues found")
+	}
+
+	result, err := doctor.RepairStoryIssues(ctx, issues)
+	if err != nil {
+		return fmt.Errorf("failed to repair story issues: %w", err)
+	}
+
+	for _, issue := range result.Repaired {
+		switch issue.Kind {
+		case services.IssueMergedBranch:
+			fmt.Printf("✓ Deleted merged branch %s\n", issue.Branch)
+		case services.IssueOrphanBranch:
+			fmt.Printf("✓ Archived orphan branch %s\n", issue.Branch)
+		}
+	}
+
+	if result.FailedCount > 0 {
+		fmt.Printf("\n✗ %d repairs failed:\n", result.FailedCount)
+		for _, repairErr := range result.Errors {
+			fmt.Printf("  - %v\n", repairErr)
+		}
+		return fmt.Errorf("some repairs failed")
+	}
+
+	if remaining := len(issues) - result.RepairedCount; remaining > 0 {
+		fmt.Printf("\n%d issues need manual attention\n", remaining)
+		return fmt.Errorf("story issues found")
+	}
+	return nil
+}
+
+// printStoryIssues renders story/branch issues in human-readable form.
+func printStoryIssues(issues []services.StoryIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Printf("✗ Found %d story issues:\n\n", len(issues))
+	for i, issue := range issues {
+		fmt.Printf("%d. [%s] %s\n", i+1, issue.Kind, issue.Message)
+		for _, p := range issue.Paths {
+			fmt.Printf("   - %s\n", p)
+		}
+		if issue.Fixable {
+			fmt.Println("   → Fixable with --fix")
+		}
+	}
+	fmt.Println()
+}
+
 func init() {
 	doctorCmd.Flags().Bool("fix", false, "Automatically repair detected inconsistencies")
+	addTemplateFlags(doctorCmd)
 	doctorCmd.Flags().String("sprint", "", "Check specific sprint only (default: check all sprints)")
+	doctorCmd.Flags().Int("stale-days", 14, "Report in-progress stories with no commits for more than this many days")
 	rootCmd.AddCommand(doctorCmd)
 }
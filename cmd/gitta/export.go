@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/services"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export stories as CSV, JSON Lines, Markdown or YAML",
+	Long: `Export stories of the backlog and every sprint with all their fields: the
+built-in fields, custom frontmatter, the status derived from Git, the matching
+branch and the location (Backlog or the sprint folder).
+
+Columns: id, title, status, derived_status, priority, assignee, tags,
+location, branch, created_at, updated_at, body, then configured custom fields
+and any other frontmatter keys. By default every column except body is
+exported; use --fields to choose and order columns, or --fields all.
+
+--query selects stories with space-separated terms: key:value for sprint,
+status, priority, assignee, tag or a custom field (comma-separated values are
+alternatives), and plain words searched in the ID, title and body.
+
+CSV and JSON Lines exports can be edited and applied with 'gitta import --format'.
+
+Examples:
+  gitta export --format csv -o stories.csv
+  gitta export --format jsonl --fields id,title,status,points --query "sprint:current status:doing,review"
+  gitta export --format md --query "tag:payments assignee:alice"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		formatName, _ := cmd.Flags().GetString("format")
+		fields, _ := cmd.Flags().GetStringSlice("fields")
+		query, _ := cmd.Flags().GetString("query")
+		output, _ := cmd.Flags().GetString("output")
+
+		format, err := services.ParseTableFormat(formatName)
+		if err != nil {
+			return err
+		}
+		filter, err := services.ParseStoryQuery(query)
+		if err != nil {
+			return err
+		}
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		gitRepo := git.NewRepository()
+		board := services.NewBoardService(storyRepo, storyRepo, gitRepo, repoPath, projectConfig.Workflow)
+		exportService := services.NewExportService(board, gitRepo, projectConfig.Fields, repoPath)
+
+		table, err := exportService.Export(ctx, services.ExportOptions{Columns: fields, Filter: filter})
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		if err := table.Write(out, format); err != nil {
+			return err
+		}
+		if output != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d stories (%s) to %s\n", len(table.Rows), strings.Join(table.Columns, ", "), output)
+		}
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().String("format", "csv", "Output format (csv|jsonl|md|yaml)")
+	exportCmd.Flags().StringSlice("fields", nil, "Columns to export in order, comma-separated, or all (default: every column except body)")
+	exportCmd.Flags().String("query", "", `Select stories, e.g. "sprint:current status:doing,review tag:payments"`)
+	exportCmd.Flags().StringP("output", "o", "", "Write the export to this file instead of standard output")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// loadStoryParser loads .gitta/config.yaml from repoPath and returns a parser
+// that enforces the configured custom fields along with the loaded config.
+func loadStoryParser(repoPath string) (*filesystem.MarkdownParser, *services.ProjectConfig, error) {
+	cfg, err := services.LoadProjectConfig(repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filesystem.NewMarkdownParserWithRules(cfg.ValidationRules()), cfg, nil
+}
+
+// loadEventBus returns the event bus recording changes in the activity
+// journal of repoPath and running the hooks configured in cfg from it. Hook
+// output and warnings go to stderr so they never mix with command output.
+func loadEventBus(repoPath string, cfg *services.ProjectConfig) core.EventBus {
+	return core.EventBuses{
+		services.NewActivityJournal(services.NewActivityService(repoPath), commandLine(), os.Stderr),
+		services.NewHookBus(repoPath, cfg.Hooks, os.Getenv("PATH"), os.Stderr),
+	}
+}
+
+// loadTransactions returns the transaction log of repoPath, recording file
+// changes as one operation of this command for 'gitta undo'.
+func loadTransactions(repoPath string) *filesystem.TransactionLog {
+	return filesystem.NewTransactionLog(repoPath, commandLine())
+}
+
+// commandLine returns the command line gitta runs, as recorded in the
+// activity journal and the transaction log.
+func commandLine() string {
+	return strings.Join(append([]string{"gitta"}, os.Args[1:]...), " ")
+}
+
+// splitAssignment splits a key=value flag argument.
+func splitAssignment(flag, arg string) (string, string, error) {
+	key, value, ok := strings.Cut(arg, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid %s %q (expected key=value)", flag, arg)
+	}
+	return key, value, nil
+}
+
+// parseFieldAssignments converts --set key=value arguments into typed custom
+// field values using the configured field definitions.
+func parseFieldAssignments(cfg *services.ProjectConfig, args []string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		name, raw, err := splitAssignment("--set", arg)
+		if err != nil {
+			return nil, err
+		}
+		def, ok := cfg.Field(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q (declare it under fields: in %s)", name, services.ProjectConfigFile)
+		}
+		value, err := services.ParseFieldValue(def, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --set %s: %w", name, err)
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// parseFieldFilters converts --field key=value arguments into a custom field filter.
+func parseFieldFilters(cfg *services.ProjectConfig, args []string) (map[string][]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	filters := make(map[string][]string)
+	for _, arg := range args {
+		name, value, err := splitAssignment("--field", arg)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := cfg.Field(name); !ok {
+			return nil, fmt.Errorf("unknown field %q (declare it under fields: in %s)", name, services.ProjectConfigFile)
+		}
+		filters[name] = append(filters[name], strings.TrimSpace(value))
+	}
+	return filters, nil
+}
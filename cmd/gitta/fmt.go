@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+var (
+	fmtCheck bool
+	fmtDiff  bool
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [paths...]",
+	Short: "Rewrite story files in canonical form",
+	Long: `Rewrite story files in canonical form.
+
+Frontmatter is re-emitted in a fixed key order (id, title, assignee, priority,
+status, created_at, updated_at, tags, then custom keys alphabetically),
+timestamps are written as RFC 3339 in UTC (dates such as 2024-01-05 become
+midnight UTC), tags are lower-cased and de-duplicated, and line endings are
+normalised to LF.
+
+Without arguments, the backlog and all sprint directories are formatted.
+Arguments may be story files or directories (searched recursively).
+
+Use --check to verify formatting without writing (non-zero exit when any file
+would change), and --diff to print the changes as a unified diff.
+
+Examples:
+  gitta fmt
+  gitta fmt --check
+  gitta fmt --diff tasks/backlog`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		paths, err := absPaths(args)
+		if err != nil {
+			return err
+		}
+
+		write := !fmtCheck && !fmtDiff
+		parser, _, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		formatService := services.NewFormatServiceWithTransactions(parser, repoPath, loadTransactions(repoPath))
+		results, err := formatService.FormatStories(ctx, paths, write)
+		if err != nil {
+			return fmt.Errorf("fmt: %w", err)
+		}
+
+		var changed, failed int
+		for _, r := range results {
+			if r.Err != nil {
+				failed++
+			} else if r.Changed {
+				changed++
+			}
+		}
+
+		if jsonOutput {
+			type fileJSON struct {
+				File    string `json:"file"`
+				Changed bool   `json:"changed"`
+				Error   string `json:"error,omitempty"`
+			}
+			files := make([]fileJSON, 0, len(results))
+			for _, r := range results {
+				fj := fileJSON{File: r.File, Changed: r.Changed}
+				if r.Err != nil {
+					fj.Error = r.Err.Error()
+				}
+				files = append(files, fj)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(map[string]interface{}{
+				"files":   files,
+				"changed": changed,
+				"failed":  failed,
+				"written": write,
+			}); err != nil {
+				return err
+			}
+		} else {
+			for _, r := range results {
+				switch {
+				case r.Err != nil:
+					fmt.Printf("✗ %s: %v\n", r.File, r.Err)
+				case !r.Changed:
+					continue
+				case fmtDiff:
+					fmt.Print(ui.UnifiedDiff("a/"+r.File, "b/"+r.File, r.Original, r.Formatted))
+				case fmtCheck:
+					fmt.Println(r.File)
+				default:
+					fmt.Printf("✓ Formatted %s\n", r.File)
+				}
+			}
+		}
+
+		cmd.SilenceUsage = true
+		if failed > 0 {
+			return fmt.Errorf("%d files could not be formatted", failed)
+		}
+		if !write && changed > 0 {
+			return fmt.Errorf("%d files are not formatted", changed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "Report unformatted files without writing (non-zero exit if any)")
+	fmtCmd.Flags().BoolVar(&fmtDiff, "diff", false, "Print a unified diff of the changes without writing")
+	rootCmd.AddCommand(fmtCmd)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Forecast delivery with a Monte Carlo simulation over sprint throughput",
+	Long: `Forecast delivery with a Monte Carlo simulation over historical throughput.
+
+Throughput is the number of completed tasks in each archived sprint (see
+'gitta stats velocity'). Each trial draws random historical sprints and the
+results are reported at 50%, 85% and 95% confidence.
+
+With --items, --tag, --epic or --field, forecast answers "when will these items
+be done": the number of sprints and the date by which they finish. Without an
+item count, the unfinished backlog and sprint stories matching the selectors
+are counted.
+
+Otherwise forecast answers "how many items fit in the next sprint(s)": with
+85% confidence at least the reported number of items gets done.
+
+Use --seed for reproducible results.
+
+Examples:
+  gitta forecast                         # Items completed next sprint
+  gitta forecast --sprints 3             # Items completed in the next 3 sprints
+  gitta forecast --items 40              # When will 40 items be done?
+  gitta forecast --epic checkout         # When will the checkout epic be done?
+  gitta forecast --tag api --history 6 --seed 42`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		items, _ := cmd.Flags().GetInt("items")
+		tags, _ := cmd.Flags().GetStringArray("tag")
+		epic, _ := cmd.Flags().GetString("epic")
+		fields, _ := cmd.Flags().GetStringArray("field")
+		sprints, _ := cmd.Flags().GetInt("sprints")
+		history, _ := cmd.Flags().GetInt("history")
+		trials, _ := cmd.Flags().GetInt("trials")
+		seed, _ := cmd.Flags().GetInt64("seed")
+		sprintLengthValue, _ := cmd.Flags().GetString("sprint-length")
+		format, _ := cmd.Flags().GetString("format")
+		if jsonOutput {
+			format = "json"
+		}
+
+		switch format {
+		case "table", "", "json":
+		default:
+			return fmt.Errorf("invalid format: %s (supported: table, json)", format)
+		}
+		for _, flag := range []struct {
+			name  string
+			value int
+		}{{"--items", items}, {"--sprints", sprints}, {"--history", history}, {"--trials", trials}} {
+			if flag.value < 0 {
+				return fmt.Errorf("invalid %s: %d (must be zero or positive)", flag.name, flag.value)
+			}
+		}
+		tagPattern := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+		for _, t := range tags {
+			if !tagPattern.MatchString(t) {
+				return fmt.Errorf("invalid tag: %s (must be alphanumeric with hyphens/underscores)", t)
+			}
+		}
+		var sprintLength time.Duration
+		if sprintLengthValue != "" {
+			days, err := core.ParseDuration(sprintLengthValue)
+			if err != nil {
+				return fmt.Errorf("invalid --sprint-length: %w", err)
+			}
+			sprintLength = time.Duration(days) * 24 * time.Hour
+		}
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		filter := services.Filter{Tags: tags}
+		if filter.Fields, err = parseFieldFilters(projectConfig, fields); err != nil {
+			return fmt.Errorf("invalid filter: %w", err)
+		}
+		if epic != "" {
+			if filter.Fields == nil {
+				filter.Fields = make(map[string][]string)
+			}
+			filter.Fields["epic"] = append(filter.Fields["epic"], epic)
+		}
+
+		storyRepo := filesystem.NewRepository(parser)
+		forecastService := services.NewForecastServiceWithWorkflow(storyRepo, storyRepo, git.NewRepository(), git.NewHistoryAnalyzer(parser), repoPath, projectConfig.Workflow)
+		req := services.ForecastRequest{
+			Items:        items,
+			Filter:       filter,
+			Sprints:      sprints,
+			History:      history,
+			Trials:       trials,
+			Seed:         seed,
+			SprintLength: sprintLength,
+		}
+
+		if items == 0 && len(filter.Tags) == 0 && len(filter.Fields) == 0 {
+			forecast, err := forecastService.ForecastCapacity(ctx, req)
+			if err != nil {
+				return fmt.Errorf("forecast: %w", err)
+			}
+			if format == "json" {
+				return encodeIndented(toCapacityJSON(forecast))
+			}
+			fmt.Println(ui.RenderCapacityForecast(*forecast))
+			return nil
+		}
+
+		if sprints != 0 {
+			return fmt.Errorf("--sprints cannot be combined with --items or item selectors")
+		}
+		forecast, err := forecastService.ForecastCompletion(ctx, req)
+		if err != nil {
+			return fmt.Errorf("forecast: %w", err)
+		}
+		if format == "json" {
+			return encodeIndented(toCompletionJSON(forecast))
+		}
+		fmt.Println(ui.RenderCompletionForecast(*forecast))
+		return nil
+	},
+}
+
+type completionOutcomeJSON struct {
+	Confidence int    `json:"confidence"`
+	Sprints    int    `json:"sprints"`
+	Date       string `json:"date"`
+}
+
+type capacityOutcomeJSON struct {
+	Confidence int `json:"confidence"`
+	Items      int `json:"items"`
+}
+
+type forecastJSON struct {
+	Mode             string                  `json:"mode"`
+	Items            *int                    `json:"items,omitempty"`
+	Sprints          *int                    `json:"sprints,omitempty"`
+	SprintLengthDays *float64                `json:"sprint_length_days,omitempty"`
+	Trials           int                     `json:"trials"`
+	Throughput       []int                   `json:"throughput"`
+	Completion       []completionOutcomeJSON `json:"completion,omitempty"`
+	Capacity         []capacityOutcomeJSON   `json:"capacity,omitempty"`
+}
+
+func toCompletionJSON(forecast *core.CompletionForecast) forecastJSON {
+	days := forecast.SprintLength.Hours() / 24
+	out := forecastJSON{
+		Mode:             "completion",
+		Items:            &forecast.Items,
+		SprintLengthDays: &days,
+		Trials:           forecast.Trials,
+		Throughput:       forecast.Throughput,
+		Completion:       make([]completionOutcomeJSON, 0, len(forecast.Outcomes)),
+	}
+	for _, o := range forecast.Outcomes {
+		out.Completion = append(out.Completion, completionOutcomeJSON{
+			Confidence: o.Confidence,
+			Sprints:    o.Sprints,
+			Date:       o.Date.Format("2006-01-02"),
+		})
+	}
+	return out
+}
+
+func toCapacityJSON(forecast *core.CapacityForecast) forecastJSON {
+	out := forecastJSON{
+		Mode:       "capacity",
+		Sprints:    &forecast.Sprints,
+		Trials:     forecast.Trials,
+		Throughput: forecast.Throughput,
+		Capacity:   make([]capacityOutcomeJSON, 0, len(forecast.Outcomes)),
+	}
+	for _, o := range forecast.Outcomes {
+		out.Capacity = append(out.Capacity, capacityOutcomeJSON{Confidence: o.Confidence, Items: o.Items})
+	}
+	return out
+}
+
+func init() {
+	forecastCmd.Flags().Int("items", 0, "Forecast when this many items will be done")
+	forecastCmd.Flags().StringArray("tag", []string{}, "Forecast the remaining stories with any of these tags")
+	forecastCmd.Flags().String("epic", "", "Forecast the remaining stories whose epic field matches")
+	forecastCmd.Flags().StringArray("field", []string{}, "Forecast the remaining stories matching a custom field (key=value)")
+	forecastCmd.Flags().Int("sprints", 0, "Number of upcoming sprints for the capacity forecast (default 1)")
+	forecastCmd.Flags().Int("history", 0, "Only sample the most recent N archived sprints (0 = all)")
+	forecastCmd.Flags().Int("trials", 10000, "Number of simulation runs")
+	forecastCmd.Flags().Int64("seed", 0, "Random seed for reproducible results (0 = random)")
+	forecastCmd.Flags().String("sprint-length", "", "Sprint length for dates (e.g., 14d, 2w; default: median archived sprint)")
+	forecastCmd.Flags().String("format", "table", "Output format (table, json)")
+
+	rootCmd.AddCommand(forecastCmd)
+}
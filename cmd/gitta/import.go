@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/core/workspace"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// importCmd applies gitta exports and is the parent command for importers.
+var importCmd = &cobra.Command{
+	Use:   "import [--format csv|jsonl <file>]",
+	Short: "Import stories from exports of gitta and other tools",
+	Long: `Apply an edited 'gitta export' or import stories from Jira and Trello exports.
+
+With --format, <file> is a CSV or JSON Lines export of 'gitta export' (or "-"
+for standard input). Each row updates the story with the same id: title,
+status, priority, assignee, tags, body and custom fields that differ from the
+story are changed; derived_status, location, branch, created_at and updated_at
+are ignored. Missing columns and empty status or priority cells leave the story
+alone; other empty cells clear the field. Every row is validated before any
+story is written; use --dry-run to see the changes as a diff first.
+Status changes follow the workflow's transitions unless --force is set.
+
+Use the jira and trello subcommands to create stories from other tools.
+
+Each item becomes a story with a new ID (--prefix, default US). Its original
+key is kept in the story frontmatter (external: {jira: SHOP-12}) and in the
+mapping table .gitta/import/<source>.csv, so importing the same export again
+only adds new items.
+
+Statuses map onto the workflow by name (e.g., "In Review" becomes review), then
+by category ("In Progress" becomes the first in-progress state); use
+--status-map to override. Items not started go to the backlog; started and
+finished items without a sprint of their own go to --sprint.
+
+Examples:
+  gitta export --format csv -o stories.csv    # edit stories.csv, then
+  gitta import --format csv stories.csv --dry-run
+  gitta import --format csv stories.csv --commit`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTableImport,
+}
+
+var importJiraCmd = &cobra.Command{
+	Use:   "jira <file.csv>",
+	Short: "Import issues from a Jira CSV export",
+	Long: `Import issues from a Jira CSV export (Filters > Export > Export CSV (all fields)).
+
+Summary, description (converted from Jira markup to Markdown), status,
+priority, assignee, labels, created and updated dates are mapped onto stories.
+Custom fields whose names match fields declared in .gitta/config.yaml are
+imported (e.g., "Story Points" fills story_points); every other column is
+reported as skipped.
+
+Issues in a sprint go to the sprint folder with the same name, which is created
+as a planning sprint if needed. See 'gitta import --help' for the other rules.
+
+Examples:
+  gitta import jira export.csv --dry-run
+  gitta import jira export.csv --prefix SH --status-map "In QA=review" --user-map "Alice Smith=alice"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImport(cmd, args[0], services.ParseJiraCSV)
+	},
+}
+
+var importTrelloCmd = &cobra.Command{
+	Use:   "trello <board.json>",
+	Short: "Import cards from a Trello board export",
+	Long: `Import cards from a Trello board export (Menu > Print, export and share > Export as JSON).
+
+Card names, descriptions (already Markdown) with checklists as task lists,
+lists (as statuses), labels, the first member (as assignee) and dates are
+mapped onto stories. Custom fields whose names match fields declared in
+.gitta/config.yaml are imported; due dates, other members, attachments and
+comments are reported as skipped. Archived cards and lists are not imported.
+
+Examples:
+  gitta import trello board.json --dry-run
+  gitta import trello board.json --sprint backlog`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImport(cmd, args[0], services.ParseTrelloBoard)
+	},
+}
+
+// runTableImport applies a gitta export to the stories it names.
+func runTableImport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	formatName, _ := cmd.Flags().GetString("format")
+	if formatName == "" || len(args) != 1 {
+		if formatName == "" && len(args) == 0 {
+			return cmd.Help()
+		}
+		return fmt.Errorf("usage: gitta import --format csv|jsonl <file>, or gitta import jira|trello <file>")
+	}
+	format, err := services.ParseTableFormat(formatName)
+	if err != nil {
+		return err
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	commit, _ := cmd.Flags().GetBool("commit")
+
+	repoPath, err := findRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+
+	var in io.Reader = cmd.InOrStdin()
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	table, err := services.ReadStoryTable(in, format)
+	if err != nil {
+		return err
+	}
+
+	parser, projectConfig, err := loadStoryParser(repoPath)
+	if err != nil {
+		return err
+	}
+	structure, err := workspace.DetectStructure(ctx, repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to detect workspace structure: %w", err)
+	}
+	storyRepo := filesystem.NewRepository(parser)
+	gitRepo := git.NewRepository()
+	board := services.NewBoardService(storyRepo, storyRepo, gitRepo, repoPath, projectConfig.Workflow)
+	backlogPath := workspace.ResolveBacklogPath(repoPath, structure)
+	var committer core.GitCommitter
+	if commit {
+		committer = gitRepo
+	}
+	events := loadEventBus(repoPath, projectConfig)
+	transactions := loadTransactions(repoPath)
+	edit := services.NewStoryEditServiceWithGit(
+		parser, storyRepo, gitRepo, board,
+		services.NewCreateServiceWithTransactions(filesystem.NewIDCounter(repoPath), parser, storyRepo, backlogPath, events, transactions),
+		committer, repoPath, backlogPath, projectConfig.Workflow, events, transactions,
+	)
+	bulk := services.NewBulkUpdateService(parser, storyRepo, board, edit, projectConfig.Fields, repoPath, projectConfig.Workflow)
+
+	report, err := bulk.Apply(ctx, table, services.BulkUpdateOptions{DryRun: dryRun, Force: force})
+	if report != nil {
+		printBulkUpdateReport(cmd.OutOrStdout(), repoPath, report)
+	}
+	return err
+}
+
+// printBulkUpdateReport prints invalid rows, or each change (as a diff in a
+// dry run), then a summary.
+func printBulkUpdateReport(out io.Writer, repoPath string, report *services.BulkUpdateReport) {
+	for _, rowErr := range report.Errors {
+		fmt.Fprintf(out, "Row %d %s: %s\n", rowErr.Row, rowErr.ID, rowErr.Message)
+	}
+	if len(report.Errors) > 0 {
+		return
+	}
+	for _, change := range report.Changes {
+		if report.DryRun {
+			file := change.Path
+			if rel, err := filepath.Rel(repoPath, change.Path); err == nil {
+				file = filepath.ToSlash(rel)
+			}
+			fmt.Fprint(out, ui.UnifiedDiff("a/"+file, "b/"+file, change.Before, change.After))
+			continue
+		}
+		line := fmt.Sprintf("Updated %s (%s)", change.ID, strings.Join(change.Changed, ", "))
+		if change.Commit != "" {
+			line += " in " + change.Commit[:min(7, len(change.Commit))]
+		}
+		fmt.Fprintln(out, line)
+	}
+	if len(report.Ignored) > 0 {
+		fmt.Fprintf(out, "Read-only columns ignored: %s\n", strings.Join(report.Ignored, ", "))
+	}
+	summary := fmt.Sprintf("%d story(ies) changed, %d unchanged", len(report.Changes), report.Unchanged)
+	if report.DryRun {
+		summary = "Dry run: " + summary + " (nothing written)"
+	}
+	fmt.Fprintln(out, summary)
+}
+
+// runImport reads an export with parse and imports it into the repository.
+func runImport(cmd *cobra.Command, file string, parse func(io.Reader) (*services.ImportBatch, error)) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	repoPath, err := findRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+
+	prefix, _ := cmd.Flags().GetString("prefix")
+	sprint, _ := cmd.Flags().GetString("sprint")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	statusPairs, _ := cmd.Flags().GetStringArray("status-map")
+	userPairs, _ := cmd.Flags().GetStringArray("user-map")
+
+	opts := services.ImportOptions{
+		Prefix:    prefix,
+		Sprint:    sprint,
+		DryRun:    dryRun,
+		StatusMap: make(map[string]core.Status),
+		UserMap:   make(map[string]string),
+	}
+	for _, pair := range statusPairs {
+		name, status, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid --status-map %q: expected <source status>=<status>", pair)
+		}
+		opts.StatusMap[strings.TrimSpace(name)] = core.Status(strings.TrimSpace(status))
+	}
+	for _, pair := range userPairs {
+		name, user, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid --user-map %q: expected <source user>=<assignee>", pair)
+		}
+		opts.UserMap[strings.TrimSpace(name)] = strings.TrimSpace(user)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	batch, err := parse(f)
+	if err != nil {
+		return err
+	}
+
+	parser, projectConfig, err := loadStoryParser(repoPath)
+	if err != nil {
+		return err
+	}
+	structure, err := workspace.DetectStructure(ctx, repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to detect workspace structure: %w", err)
+	}
+	storyRepo := filesystem.NewRepository(parser)
+	board := services.NewBoardService(storyRepo, storyRepo, git.NewRepository(), repoPath, projectConfig.Workflow)
+	importService := services.NewImportService(
+		filesystem.NewIDCounter(repoPath),
+		parser,
+		board,
+		services.NewSprintPlanService(storyRepo, repoPath),
+		projectConfig.Fields,
+		repoPath,
+		workspace.ResolveBacklogPath(repoPath, structure),
+		projectConfig.Workflow,
+	)
+
+	report, err := importService.Import(ctx, batch, opts)
+	if report != nil {
+		printImportReport(cmd.OutOrStdout(), report)
+	}
+	return err
+}
+
+// printImportReport prints the imported stories, then what was skipped.
+func printImportReport(out io.Writer, report *services.ImportReport) {
+	for _, story := range report.Imported {
+		id := story.ID
+		if id == "" {
+			id = "(new)"
+		}
+		fmt.Fprintf(out, "%-16s -> %-8s %-8s %-24s %s\n", story.Key, id, story.Status, story.Location, story.Title)
+	}
+	for _, sprint := range report.Sprints {
+		fmt.Fprintf(out, "Sprint created: %s\n", sprint)
+	}
+	if len(report.Existing) > 0 {
+		fmt.Fprintf(out, "Already imported (see %s): %d\n", report.MappingFile, len(report.Existing))
+	}
+	if len(report.Skipped) > 0 {
+		fmt.Fprintln(out, "Skipped items:")
+		for _, skip := range report.Skipped {
+			fmt.Fprintf(out, "  %s %s: %s\n", skip.Key, skip.Title, skip.Reason)
+		}
+	}
+	if len(report.SkippedFields) > 0 {
+		names := make([]string, 0, len(report.SkippedFields))
+		for name := range report.SkippedFields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(out, "Skipped fields (items with a value):")
+		for _, name := range names {
+			fmt.Fprintf(out, "  %s (%d)\n", name, report.SkippedFields[name])
+		}
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(out, "Warning: %s\n", warning)
+	}
+
+	summary := fmt.Sprintf("%d imported, %d already imported, %d skipped", len(report.Imported), len(report.Existing), len(report.Skipped))
+	if report.DryRun {
+		fmt.Fprintf(out, "Dry run: %s (nothing written)\n", summary)
+		return
+	}
+	fmt.Fprintf(out, "%s; original keys are mapped in %s\n", summary, report.MappingFile)
+}
+
+func init() {
+	importCmd.Flags().String("format", "", "Format of a gitta export to apply (csv|jsonl)")
+	importCmd.Flags().Bool("dry-run", false, "Show the changes as a diff without writing anything")
+	importCmd.Flags().Bool("force", false, "Allow status changes the workflow does not declare")
+	importCmd.Flags().Bool("commit", false, "Commit every story change")
+	for _, cmd := range []*cobra.Command{importJiraCmd, importTrelloCmd} {
+		cmd.Flags().String("prefix", "US", "ID prefix of the new stories (2 uppercase letters)")
+		cmd.Flags().String("sprint", "current", `Sprint for started and finished items without a sprint ("current", a sprint name, or "backlog")`)
+		cmd.Flags().StringArray("status-map", []string{}, "Map a source status to a workflow state (name=status, can be specified multiple times)")
+		cmd.Flags().StringArray("user-map", []string{}, "Map a source user to an assignee (name=user, can be specified multiple times)")
+		cmd.Flags().Bool("dry-run", false, "Show what would be imported without writing anything")
+		importCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/internal/services"
+)
+
+var lintFormat string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [paths...]",
+	Short: "Validate story files and report problems",
+	Long: `Validate story files and report problems with file:line locations.
+
+Every story is parsed and checked against the same rules used when stories
+are written (ID format, required title, priority/status values, tags, dates).
+Malformed frontmatter and duplicate story IDs are reported as well.
+
+Without arguments, the backlog and all sprint directories are checked.
+Arguments may be story files or directories (searched recursively).
+
+The command exits with a non-zero status when any error is found, which makes
+it suitable for CI jobs and pre-commit hooks.
+
+Output formats:
+  text   file:line: severity: message [rule] (default)
+  json   machine-readable report (also selected by --json)
+  sarif  SARIF 2.1.0 for code scanning tools
+
+Examples:
+  gitta lint
+  gitta lint tasks/backlog/US-001.md
+  gitta lint --format sarif > gitta.sarif`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		paths, err := absPaths(args)
+		if err != nil {
+			return err
+		}
+
+		format := lintFormat
+		if jsonOutput {
+			format = "json"
+		}
+
+		parser, _, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		lintService := services.NewLintService(parser, repoPath)
+		report, err := lintService.LintStories(ctx, paths)
+		if err != nil {
+			return fmt.Errorf("lint: %w", err)
+		}
+
+		switch format {
+		case "text":
+			printLintText(os.Stdout, report)
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		case "sarif":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(buildSARIF(report)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid format %q (valid: text, json, sarif)", format)
+		}
+
+		if n := report.ErrorCount(); n > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d problems found", n)
+		}
+		return nil
+	},
+}
+
+// absPaths resolves command-line paths against the working directory.
+func absPaths(args []string) ([]string, error) {
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %w", arg, err)
+		}
+		paths = append(paths, abs)
+	}
+	return paths, nil
+}
+
+// printLintText writes diagnostics in the conventional file:line format.
+func printLintText(w io.Writer, report *services.LintReport) {
+	for _, d := range report.Diagnostics {
+		location := d.File
+		if d.Line > 0 {
+			location = fmt.Sprintf("%s:%d", d.File, d.Line)
+		}
+		fmt.Fprintf(w, "%s: %s: %s [%s]\n", location, d.Severity, d.Message, d.Rule)
+	}
+	if len(report.Diagnostics) == 0 {
+		fmt.Fprintf(w, "✓ %d story files checked, no problems found\n", report.FilesChecked)
+		return
+	}
+	fmt.Fprintf(w, "\n✗ %d problems in %d story files checked\n", len(report.Diagnostics), report.FilesChecked)
+}
+
+// sarifLog is the minimal subset of the SARIF 2.1.0 format emitted by gitta lint.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// buildSARIF converts a lint report into a SARIF log.
+func buildSARIF(report *services.LintReport) sarifLog {
+	ruleSet := make(map[string]bool)
+	results := make([]sarifResult, 0, len(report.Diagnostics))
+	for _, d := range report.Diagnostics {
+		ruleSet[d.Rule] = true
+		loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: d.File}}
+		if d.Line > 0 {
+			loc.Region = &sarifRegion{StartLine: d.Line}
+		}
+		results = append(results, sarifResult{
+			RuleID:    d.Rule,
+			Level:     string(d.Severity),
+			Message:   sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		})
+	}
+
+	rules := make([]sarifRule, 0, len(ruleSet))
+	for id := range ruleSet {
+		rules = append(rules, sarifRule{ID: id})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gitta",
+				Version:        buildVersion,
+				InformationURI: "https://github.com/GavinWu1991/gitta",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format (text|json|sarif)")
+	rootCmd.AddCommand(lintCmd)
+}
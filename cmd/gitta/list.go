@@ -7,6 +7,7 @@ import (
 	"os"
 	"regexp"
 	"sort"
+	"text/template"
 
 	"github.com/spf13/cobra"
 
@@ -24,32 +25,82 @@ var (
 	listAssignee []string
 	listTag      []string
 	listSort     string
+	listField    []string
+	listDrift    bool
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List Sprint tasks",
-	Long:  "Display current Sprint tasks in a formatted table. Use --all to include backlog tasks.",
+	Long: `Display current Sprint tasks in a formatted table. Use --all to include backlog tasks.
+
+Use --drift to list Sprint and backlog stories whose explicit frontmatter status
+disagrees with the status derived from Git (for example, a story marked todo
+whose branch is already merged). Clear such overrides with
+'gitta story status --sync'.
+
+Use --format with a Go template to print one line per story; the template sees
+a story of the --json output (id, title, status, priority, assignee, tags,
+created_at, updated_at, fields, derived_status).
+
+Examples:
+  gitta list --all --format '{{.id}}\t{{.status | upper}}\t{{.title | truncate 40}}'
+  gitta list --format '{{.id}} {{or .assignee "-"}} {{join "," .tags}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		if ctx == nil {
 			ctx = context.Background()
 		}
 
-		repoPath, err := os.Getwd()
+		tmpl, err := formatOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
 		if err != nil {
-			return fmt.Errorf("failed to determine working directory: %w", err)
+			return err
 		}
 
-		storyRepo := filesystem.NewDefaultRepository()
+		storyRepo := filesystem.NewRepository(parser)
 		gitRepo := git.NewRepository()
-		listService := services.NewListService(storyRepo, gitRepo)
+		listService := services.NewListServiceWithWorkflow(storyRepo, gitRepo, projectConfig.Workflow)
 
 		// Build filter from flags
-		filter, err := buildFilter(listStatus, listPriority, listAssignee, listTag)
+		filter, err := buildFilter(projectConfig.Workflow, listStatus, listPriority, listAssignee, listTag)
 		if err != nil {
 			return fmt.Errorf("invalid filter: %w", err)
 		}
+		filter.Fields, err = parseFieldFilters(projectConfig, listField)
+		if err != nil {
+			return fmt.Errorf("invalid filter: %w", err)
+		}
+
+		if listDrift {
+			if hasFilters(filter) || listAll {
+				return fmt.Errorf("--drift cannot be combined with --all or filters")
+			}
+			stories, err := listService.ListDrift(ctx, repoPath)
+			if err != nil {
+				return fmt.Errorf("list --drift: %w", err)
+			}
+
+			if tmpl != nil || jsonOutput {
+				return outputStories(tmpl, stories, true, projectConfig)
+			}
+			if len(stories) == 0 {
+				fmt.Println("No status drift found.")
+				return nil
+			}
+
+			fmt.Println(ui.RenderDriftTable(toDisplayStories(stories)))
+			return nil
+		}
 
 		// If filters are specified, use filtered listing
 		if hasFilters(filter) {
@@ -58,7 +109,7 @@ var listCmd = &cobra.Command{
 				return fmt.Errorf("list: %w", err)
 			}
 
-			if len(stories) == 0 {
+			if len(stories) == 0 && tmpl == nil {
 				if jsonOutput {
 					fmt.Println(`{"stories":[],"total":0,"filtered":true}`)
 				} else {
@@ -69,13 +120,12 @@ var listCmd = &cobra.Command{
 
 			// Sort if specified
 			if listSort != "" {
-				stories = sortStoriesByField(stories, listSort)
+				stories = sortStoriesByField(stories, listSort, projectConfig)
 			}
 
-			// Output in JSON or formatted table
-			if jsonOutput {
-				outputJSON(stories, true)
-				return nil
+			// Output in JSON, a template or formatted table
+			if tmpl != nil || jsonOutput {
+				return outputStories(tmpl, stories, true, projectConfig)
 			}
 
 			// Group by source for display
@@ -92,7 +142,7 @@ var listCmd = &cobra.Command{
 				return fmt.Errorf("list --all: %w", err)
 			}
 
-			if len(sprintStories)+len(backlogStories) == 0 {
+			if len(sprintStories)+len(backlogStories) == 0 && tmpl == nil {
 				if jsonOutput {
 					fmt.Println(`{"stories":[],"total":0,"filtered":false}`)
 				} else {
@@ -102,9 +152,8 @@ var listCmd = &cobra.Command{
 			}
 
 			allStories := append(sprintStories, backlogStories...)
-			if jsonOutput {
-				outputJSON(allStories, false)
-				return nil
+			if tmpl != nil || jsonOutput {
+				return outputStories(tmpl, allStories, false, projectConfig)
 			}
 
 			sections := map[string][]ui.DisplayStory{
@@ -122,7 +171,7 @@ var listCmd = &cobra.Command{
 			return fmt.Errorf("list: %w", err)
 		}
 
-		if len(stories) == 0 {
+		if len(stories) == 0 && tmpl == nil {
 			if jsonOutput {
 				fmt.Println(`{"stories":[],"total":0,"filtered":false}`)
 			} else {
@@ -131,9 +180,8 @@ var listCmd = &cobra.Command{
 			return nil
 		}
 
-		if jsonOutput {
-			outputJSON(stories, false)
-			return nil
+		if tmpl != nil || jsonOutput {
+			return outputStories(tmpl, stories, false, projectConfig)
 		}
 
 		sections := map[string][]ui.DisplayStory{
@@ -152,7 +200,10 @@ func init() {
 	listCmd.Flags().StringArrayVar(&listPriority, "priority", []string{}, "Filter by priority")
 	listCmd.Flags().StringArrayVar(&listAssignee, "assignee", []string{}, "Filter by assignee")
 	listCmd.Flags().StringArrayVar(&listTag, "tag", []string{}, "Filter by tags (story must have any tag)")
-	listCmd.Flags().StringVar(&listSort, "sort", "id", "Sort field (id, title, status, priority, created_at)")
+	listCmd.Flags().StringArrayVar(&listField, "field", []string{}, "Filter by custom field (key=value, can specify multiple)")
+	listCmd.Flags().BoolVar(&listDrift, "drift", false, "List stories whose explicit status disagrees with Git")
+	listCmd.Flags().StringVar(&listSort, "sort", "id", "Sort field (id, title, status, priority, created_at, or a custom field)")
+	addTemplateFlags(listCmd)
 }
 
 func toDisplayStories(stories []*services.StoryWithStatus) []ui.DisplayStory {
@@ -163,25 +214,20 @@ func toDisplayStories(stories []*services.StoryWithStatus) []ui.DisplayStory {
 			Story:    s.Story,
 			Priority: s.Story.Priority,
 			Status:   s.Status,
+			Derived:  s.Derived,
 		})
 	}
 	return display
 }
 
 // buildFilter constructs a Filter from command-line flags with validation.
-func buildFilter(statuses, priorities, assignees, tags []string) (services.Filter, error) {
+func buildFilter(workflow core.Workflow, statuses, priorities, assignees, tags []string) (services.Filter, error) {
 	filter := services.Filter{}
 
-	// Validate and parse statuses
-	validStatuses := map[string]bool{
-		"todo":   true,
-		"doing":  true,
-		"review": true,
-		"done":   true,
-	}
+	// Validate and parse statuses against the workflow
 	for _, s := range statuses {
-		if !validStatuses[s] {
-			return filter, fmt.Errorf("invalid status: %s (valid: todo, doing, review, done)", s)
+		if !workflow.Has(core.Status(s)) {
+			return filter, fmt.Errorf("invalid status: %s (valid: %s)", s, workflow)
 		}
 		filter.Statuses = append(filter.Statuses, core.Status(s))
 	}
@@ -226,7 +272,8 @@ func hasFilters(filter services.Filter) bool {
 	return len(filter.Statuses) > 0 ||
 		len(filter.Priorities) > 0 ||
 		len(filter.Assignees) > 0 ||
-		len(filter.Tags) > 0
+		len(filter.Tags) > 0 ||
+		len(filter.Fields) > 0
 }
 
 // groupBySource groups stories by their source (Sprint/Backlog).
@@ -242,17 +289,28 @@ func groupBySource(stories []*services.StoryWithStatus) map[string][]ui.DisplayS
 			Story:    s.Story,
 			Priority: s.Story.Priority,
 			Status:   s.Status,
+			Derived:  s.Derived,
 		})
 	}
 	return sections
 }
 
-// sortStoriesByField sorts stories by the specified field.
-func sortStoriesByField(stories []*services.StoryWithStatus, field string) []*services.StoryWithStatus {
+// sortStoriesByField sorts stories by the specified field. Custom fields declared
+// in cfg sort by their type, with stories missing the field last.
+func sortStoriesByField(stories []*services.StoryWithStatus, field string, cfg *services.ProjectConfig) []*services.StoryWithStatus {
 	// Create a copy to avoid modifying original
 	result := make([]*services.StoryWithStatus, len(stories))
 	copy(result, stories)
 
+	if def, ok := cfg.Field(field); ok {
+		sort.SliceStable(result, func(i, j int) bool {
+			a, _ := result[i].Story.FieldValue(def.Name)
+			b, _ := result[j].Story.FieldValue(def.Name)
+			return services.CompareFieldValues(def, a, b) < 0
+		})
+		return result
+	}
+
 	switch field {
 	case "title":
 		sort.Slice(result, func(i, j int) bool {
@@ -284,40 +342,76 @@ func sortStoriesByField(stories []*services.StoryWithStatus, field string) []*se
 	return result
 }
 
-// outputJSON outputs stories in JSON format.
-func outputJSON(stories []*services.StoryWithStatus, filtered bool) {
-	type storyJSON struct {
-		ID        string   `json:"id"`
-		Title     string   `json:"title"`
-		Status    string   `json:"status"`
-		Priority  string   `json:"priority"`
-		Assignee  *string  `json:"assignee"`
-		Tags      []string `json:"tags"`
-		CreatedAt *string  `json:"created_at,omitempty"`
-		UpdatedAt *string  `json:"updated_at,omitempty"`
+// listStoryJSON is a story in 'gitta list --json' output and the data of
+// 'gitta list --format' templates. Custom fields declared in the project
+// config are included under "fields" when set, and "derived_status" reports
+// status drift.
+type listStoryJSON struct {
+	ID        string                 `json:"id"`
+	Title     string                 `json:"title"`
+	Status    string                 `json:"status"`
+	Priority  string                 `json:"priority"`
+	Assignee  *string                `json:"assignee"`
+	Tags      []string               `json:"tags"`
+	CreatedAt *string                `json:"created_at,omitempty"`
+	UpdatedAt *string                `json:"updated_at,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	// DerivedStatus is set when an explicit status disagrees with Git.
+	DerivedStatus string `json:"derived_status,omitempty"`
+}
+
+func newListStoryJSON(s *services.StoryWithStatus, cfg *services.ProjectConfig) listStoryJSON {
+	sj := listStoryJSON{
+		ID:       s.Story.ID,
+		Title:    s.Story.Title,
+		Status:   string(s.Status),
+		Priority: string(s.Story.Priority),
+		Tags:     s.Story.Tags,
+	}
+	if s.Story.Assignee != nil {
+		sj.Assignee = s.Story.Assignee
+	}
+	if s.Drifted() {
+		sj.DerivedStatus = string(s.Derived)
 	}
+	if s.Story.CreatedAt != nil {
+		createdStr := s.Story.CreatedAt.Format("2006-01-02T15:04:05Z")
+		sj.CreatedAt = &createdStr
+	}
+	if s.Story.UpdatedAt != nil {
+		updatedStr := s.Story.UpdatedAt.Format("2006-01-02T15:04:05Z")
+		sj.UpdatedAt = &updatedStr
+	}
+	for _, def := range cfg.Fields {
+		if value, ok := s.Story.FieldValue(def.Name); ok && value != nil {
+			if sj.Fields == nil {
+				sj.Fields = make(map[string]interface{})
+			}
+			sj.Fields[def.Name] = value
+		}
+	}
+	return sj
+}
 
-	storyList := make([]storyJSON, 0, len(stories))
+// outputStories renders stories with tmpl, once per story, or as JSON.
+func outputStories(tmpl *template.Template, stories []*services.StoryWithStatus, filtered bool, cfg *services.ProjectConfig) error {
+	if tmpl == nil {
+		outputJSON(stories, filtered, cfg)
+		return nil
+	}
 	for _, s := range stories {
-		sj := storyJSON{
-			ID:       s.Story.ID,
-			Title:    s.Story.Title,
-			Status:   string(s.Status),
-			Priority: string(s.Story.Priority),
-			Tags:     s.Story.Tags,
-		}
-		if s.Story.Assignee != nil {
-			sj.Assignee = s.Story.Assignee
-		}
-		if s.Story.CreatedAt != nil {
-			createdStr := s.Story.CreatedAt.Format("2006-01-02T15:04:05Z")
-			sj.CreatedAt = &createdStr
+		if err := writeTemplate(tmpl, newListStoryJSON(s, cfg)); err != nil {
+			return err
 		}
-		if s.Story.UpdatedAt != nil {
-			updatedStr := s.Story.UpdatedAt.Format("2006-01-02T15:04:05Z")
-			sj.UpdatedAt = &updatedStr
-		}
-		storyList = append(storyList, sj)
+	}
+	return nil
+}
+
+// outputJSON outputs stories in JSON format.
+func outputJSON(stories []*services.StoryWithStatus, filtered bool, cfg *services.ProjectConfig) {
+	storyList := make([]listStoryJSON, 0, len(stories))
+	for _, s := range stories {
+		storyList = append(storyList, newListStoryJSON(s, cfg))
 	}
 
 	output := map[string]interface{}{
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/internal/services"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log [story-or-sprint-id]",
+	Short: "Show the activity log of stories and sprints",
+	Long: `Show who changed what, newest first: story creation, starts, status
+changes and moves, sprint activation, rollovers and doctor fixes, with the
+fields before and after each change.
+
+gitta records these operations in .gitta/activity/, one append-only JSON
+Lines file per Git user (<email>.jsonl). Commit the directory to share the
+log; per-user files never conflict when merged.
+
+Examples:
+  gitta log
+  gitta log US-004
+  gitta log Sprint-02 --since 14d
+  gitta log --since 2025-03-01 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		sinceValue, _ := cmd.Flags().GetString("since")
+		since, err := parseSince(sinceValue, time.Now())
+		if err != nil {
+			return err
+		}
+		filter := services.ActivityFilter{Since: since}
+		if len(args) > 0 {
+			filter.ID = args[0]
+		}
+
+		activities, err := services.NewActivityService(repoPath).List(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return encodeIndented(map[string]interface{}{"activity": activities})
+		}
+		if len(activities) == 0 {
+			fmt.Println("No activity recorded.")
+			return nil
+		}
+		printActivities(activities)
+		return nil
+	},
+}
+
+// printActivities prints journal entries as a table.
+func printActivities(activities []services.Activity) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTOR\tEVENT\tTARGET\tCHANGES")
+	for _, activity := range activities {
+		target := activity.Story
+		if target == "" {
+			target = activity.Sprint
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			activity.Time.Local().Format("2006-01-02 15:04"), activity.Actor, activity.Event, target, activity.Summary())
+	}
+	w.Flush()
+}
+
+func init() {
+	logCmd.Flags().String("since", "", "Only show activity since a date (YYYY-MM-DD) or period (e.g., 7d, 2w)")
+	rootCmd.AddCommand(logCmd)
+}
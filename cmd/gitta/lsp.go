@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core/workspace"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/ui/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run the language server for story files",
+	Long: `Run a Language Server Protocol server over stdin/stdout for editors
+editing story Markdown files.
+
+The server reports the same diagnostics as 'gitta lint' while you type,
+completes status, priority, assignee, tags and story IDs in the depends_on,
+blocked_by and blocks fields, shows the title and status of a story ID on
+hover, and jumps from a story ID to its file.
+
+Configure your editor to start 'gitta lsp' in the repository for Markdown
+files under the backlog and sprint directories.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		structure, err := workspace.DetectStructure(ctx, repoPath)
+		if err != nil {
+			return fmt.Errorf("failed to detect workspace structure: %w", err)
+		}
+		paths := workspace.BuildPaths(repoPath, structure)
+		storyRepo := filesystem.NewRepository(parser)
+
+		server := lsp.NewServer(lsp.Config{
+			RepoPath:  repoPath,
+			StoryDirs: []string{paths.BacklogPath, paths.SprintsPath},
+			Workflow:  projectConfig.Workflow,
+			Fields:    projectConfig.Fields,
+			Lint:      services.NewLintService(parser, repoPath),
+			Stories:   storyRepo,
+			Board:     services.NewBoardService(storyRepo, storyRepo, git.NewRepository(), repoPath, projectConfig.Workflow),
+			Version:   buildVersion,
+		})
+		return server.Serve(ctx, os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
@@ -11,6 +11,10 @@ var (
 )
 
 func main() {
+	// 'gitta foo' runs a gitta-foo plugin when foo is not a built-in command
+	if code, ok := dispatchPlugin(os.Args[1:]); ok {
+		os.Exit(code)
+	}
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/core/workspace"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/ui/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol server for coding assistants",
+	Long: `Run a Model Context Protocol (MCP) server over stdin/stdout.
+
+Coding assistants can list, search, create, update and move stories, start a
+story branch and read sprint burndowns through tools backed by the same
+services as the CLI, and read story files as gitta://stories/{id} resources.
+
+Changes are validated and written atomically through the story parser. With
+--commit, each change is committed with the author from the Git
+configuration.
+
+Register the server in your assistant's MCP configuration with the command
+'gitta mcp' and the repository as working directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		commit, _ := cmd.Flags().GetBool("commit")
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		structure, err := workspace.DetectStructure(ctx, repoPath)
+		if err != nil {
+			return fmt.Errorf("failed to detect workspace structure: %w", err)
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		gitRepo := git.NewRepository()
+		board := services.NewBoardService(storyRepo, storyRepo, gitRepo, repoPath, projectConfig.Workflow)
+		backlogPath := workspace.ResolveBacklogPath(repoPath, structure)
+		var committer core.GitCommitter
+		if commit {
+			committer = gitRepo
+		}
+		events := loadEventBus(repoPath, projectConfig)
+		transactions := filesystem.NewTransactionLog(repoPath, "")
+
+		server := mcp.NewServer(mcp.Config{
+			RepoPath: repoPath,
+			Workflow: projectConfig.Workflow,
+			Fields:   projectConfig.Fields,
+			Stories:  storyRepo,
+			Board:    board,
+			Edit: services.NewStoryEditServiceWithGit(
+				parser, storyRepo, gitRepo, board,
+				services.NewCreateServiceWithTransactions(filesystem.NewIDCounter(repoPath), parser, storyRepo, backlogPath, events, transactions),
+				committer, repoPath, backlogPath, projectConfig.Workflow, events, transactions,
+			),
+			Start:    services.NewStartServiceWithTransactions(storyRepo, gitRepo, parser, events, transactions),
+			Burndown: services.NewSprintBurndownServiceWithCalendar(git.NewHistoryAnalyzer(parser), storyRepo, storyRepo, repoPath, projectConfig.Workflow, projectConfig.Calendar),
+			Version:  buildVersion,
+		})
+		return server.Serve(ctx, os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	mcpCmd.Flags().Bool("commit", false, "Commit every change made through the tools")
+	rootCmd.AddCommand(mcpCmd)
+}
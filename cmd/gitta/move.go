@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
@@ -37,15 +36,18 @@ var moveCmd = &cobra.Command{
 		}
 
 		// Get repository path
-		repoPath, err := os.Getwd()
+		repoPath, err := findRepoRoot()
 		if err != nil {
-			return fmt.Errorf("failed to determine working directory: %w", err)
+			return fmt.Errorf("not a git repository: %w", err)
 		}
 
 		// Create service dependencies
-		parser := filesystem.NewMarkdownParser()
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
 		storyRepo := filesystem.NewRepository(parser)
-		moveService := services.NewMoveService(parser, storyRepo, repoPath)
+		moveService := services.NewMoveServiceWithTransactions(parser, storyRepo, repoPath, loadEventBus(repoPath, projectConfig), loadTransactions(repoPath))
 
 		// Find story to get source path
 		story, sourcePath, err := storyRepo.FindStoryByID(ctx, repoPath, storyID)
@@ -53,14 +55,23 @@ var moveCmd = &cobra.Command{
 			return fmt.Errorf("story not found: %s", storyID)
 		}
 
+		// Check sprint capacity before the story leaves its current directory
+		targetDir, err := services.ResolveMoveTarget(ctx, repoPath, moveTo)
+		if err != nil {
+			return fmt.Errorf("failed to move story: %w", err)
+		}
+		capacityService := services.NewSprintCapacityService(storyRepo, storyRepo)
+		warnings, capacityErr := capacityService.CheckAddition(ctx, targetDir, story)
+
 		// Move story
 		err = moveService.MoveStory(ctx, storyID, moveTo, moveForce)
 		if err != nil {
 			return fmt.Errorf("failed to move story: %w", err)
 		}
+		printCapacityWarnings(warnings, capacityErr)
 
 		// Build target path for output
-		targetPath := filepath.Join(repoPath, moveTo, filepath.Base(sourcePath))
+		targetPath := filepath.Join(targetDir, filepath.Base(sourcePath))
 
 		// Output result
 		if jsonOutput {
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/services"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage external subcommand plugins",
+	Long: `Plugins add commands to gitta without changing it: 'gitta foo' runs an
+executable named gitta-foo when foo is not a built-in command, like git does.
+
+Plugins are looked up in the project's .gitta/plugins directory, then on
+$PATH. Arguments are passed unchanged. The plugin receives the workspace in
+GITTA_* environment variables (GITTA_REPO_ROOT, GITTA_STRUCTURE,
+GITTA_BACKLOG_DIR, GITTA_SPRINTS_DIR, GITTA_CURRENT_SPRINT, GITTA_CONFIG,
+GITTA_VERSION, GITTA_BIN) and as a JSON document on the first line of its
+standard input; gitta's own standard input follows when it is not a terminal.
+gitta exits with the plugin's exit code.`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins found in .gitta/plugins and on $PATH",
+	Long: `List the plugins found in .gitta/plugins and on $PATH with their source
+and path. A plugin with the name of a built-in command, or of a plugin found
+earlier, never runs and is marked as shadowed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, _ := findRepoRoot()
+		plugins, err := services.NewPluginService(repoPath, os.Getenv("PATH")).List(ctx)
+		if err != nil {
+			return err
+		}
+		for i := range plugins {
+			if plugins[i].ShadowedBy == "" && isBuiltinCommand(plugins[i].Name) {
+				plugins[i].ShadowedBy = "gitta " + plugins[i].Name
+			}
+		}
+
+		if jsonOutput {
+			if plugins == nil {
+				plugins = []services.Plugin{}
+			}
+			return encodeIndented(map[string]interface{}{"plugins": plugins})
+		}
+		if len(plugins) == 0 {
+			fmt.Println("No plugins found. Add gitta-<name> executables to .gitta/plugins or $PATH.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSOURCE\tPATH")
+		for _, plugin := range plugins {
+			path := plugin.Path
+			if repoPath != "" && plugin.Source == services.PluginSourceProject {
+				path = relPath(repoPath, path)
+			}
+			if plugin.ShadowedBy != "" {
+				path += fmt.Sprintf(" (shadowed by %s)", plugin.ShadowedBy)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", plugin.Name, plugin.Source, path)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginListCmd)
+	rootCmd.AddCommand(pluginCmd)
+}
+
+// isBuiltinCommand reports whether name is a built-in command or alias,
+// including the help and completion commands Cobra adds on execution.
+func isBuiltinCommand(name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitGlobalFlags splits args into the root's persistent flags given before
+// the command name and the rest, starting with the name. It reports false when
+// an argument before the name is not such a flag, or no name follows.
+func splitGlobalFlags(args []string) ([]string, []string, bool) {
+	flags := rootCmd.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return args[:i], args[i:], true
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		flag := flags.Lookup(name)
+		if !strings.HasPrefix(arg, "--") {
+			if len(name) != 1 {
+				return nil, nil, false
+			}
+			flag = flags.ShorthandLookup(name)
+		}
+		if flag == nil {
+			return nil, nil, false
+		}
+		// The value of a flag that needs one is the next argument
+		if !hasValue && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return nil, nil, false
+}
+
+// dispatchPlugin runs the plugin named by the first argument after the
+// global flags when it is not a built-in command, passing it the global
+// flags and then its own arguments. It reports whether a plugin was found,
+// and its exit code.
+func dispatchPlugin(args []string) (int, bool) {
+	globals, rest, ok := splitGlobalFlags(args)
+	if !ok || isBuiltinCommand(rest[0]) {
+		return 0, false
+	}
+	ctx := context.Background()
+	name := rest[0]
+
+	repoPath, _ := findRepoRoot()
+	pluginService := services.NewPluginService(repoPath, os.Getenv("PATH"))
+	plugin, err := pluginService.Find(ctx, name)
+	if err != nil || plugin == nil {
+		// Unknown commands are reported by Cobra
+		return 0, false
+	}
+
+	pluginArgs := append(append([]string{}, globals...), rest[1:]...)
+	code, err := runPlugin(ctx, pluginService, plugin, repoPath, pluginArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: plugin %s: %v\n", name, err)
+		return 1, true
+	}
+	return code, true
+}
+
+// runPlugin runs a plugin with the plugin context and returns its exit code.
+func runPlugin(ctx context.Context, pluginService services.PluginService, plugin *services.Plugin, repoPath string, args []string) (int, error) {
+	pluginContext, err := pluginService.Context(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pluginContext.Version = buildVersion
+	pluginContext.Command = plugin.Name
+	pluginContext.Args = append(pluginContext.Args, args...)
+	if repoPath != "" {
+		if current, _, err := filesystem.ReadCurrentSprintLink(pluginContext.SprintsDir); err == nil && current != "" {
+			pluginContext.CurrentSprint = current
+		}
+	}
+	contextJSON, err := json.Marshal(pluginContext)
+	if err != nil {
+		return 0, err
+	}
+
+	proc := exec.CommandContext(ctx, plugin.Path, args...)
+	proc.Stdout = os.Stdout
+	proc.Stderr = os.Stderr
+	proc.Env = append(os.Environ(), pluginContext.Env()...)
+	if self, err := os.Executable(); err == nil {
+		proc.Env = append(proc.Env, "GITTA_BIN="+self)
+	}
+	stdin := io.Reader(strings.NewReader(string(contextJSON) + "\n"))
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+		stdin = io.MultiReader(stdin, os.Stdin)
+	}
+	proc.Stdin = stdin
+	// Stop waiting for the rest of standard input once the plugin exits
+	proc.WaitDelay = time.Second
+
+	// The plugin handles interrupts; gitta waits for it to exit
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	err = proc.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		if code := exitErr.ExitCode(); code > 0 {
+			return code, nil
+		}
+		return 1, nil
+	case errors.Is(err, exec.ErrWaitDelay):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	return 0, nil
+}
@@ -14,6 +14,10 @@ var rootCmd = &cobra.Command{
 in your Git repository. It uses branch state to track task progress automatically.
 
 For more information, see: https://github.com/GavinWu1991/gitta/docs/cli/`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Roll back file changes an interrupted command left half done
+		recoverTransactions(cmd.Context())
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Show help if no subcommand provided
 		cmd.Help()
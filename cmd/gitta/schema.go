@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/internal/services"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [name]",
+	Short: "Print JSON Schemas for stories, config and --json outputs",
+	Long: `Print the JSON Schema for story frontmatter, .gitta/config.yaml, or the
+--json output of a command. Without a name, the available schemas are listed.
+
+Schemas:
+  story          story frontmatter
+  config         .gitta/config.yaml
+  list           gitta list --json
+  sprint-start   gitta sprint start --json
+  burndown       gitta sprint burndown --format json
+  burndown-forecast
+                 gitta sprint burndown --format json --forecast
+  doctor         gitta doctor --json
+  lint           gitta lint --json
+
+Examples:
+  gitta schema
+  gitta schema story > story.schema.json
+  gitta list --json | check-jsonschema --schemafile <(gitta schema list) -`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		schemaService := services.NewSchemaService(repoPath)
+
+		if len(args) == 0 {
+			names := schemaService.Names()
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(map[string]interface{}{"schemas": names})
+			}
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		schema, err := schemaService.Schema(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(schema)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/core/workspace"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/ui/web"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the REST API and a web board",
+	Long: `Serve a JSON REST API and a web board for the workspace.
+
+The API lists, reads, creates, updates and moves stories, lists sprints and
+reports burndowns, using the same services as the CLI. Its OpenAPI document is
+served at /api/openapi.json, and the board at /.
+
+Changes are written atomically through the story parser and applied one at a
+time. With --commit, each change is committed with the author from the Git
+configuration.
+
+The server listens on localhost by default. It has no authentication: only
+listen on other interfaces (e.g., --addr :7788) on trusted networks.
+
+Examples:
+  gitta serve
+  gitta serve --addr :7788 --commit`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		addr, _ := cmd.Flags().GetString("addr")
+		commit, _ := cmd.Flags().GetBool("commit")
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		structure, err := workspace.DetectStructure(ctx, repoPath)
+		if err != nil {
+			return fmt.Errorf("failed to detect workspace structure: %w", err)
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		gitRepo := git.NewRepository()
+		board := services.NewBoardService(storyRepo, storyRepo, gitRepo, repoPath, projectConfig.Workflow)
+		backlogPath := workspace.ResolveBacklogPath(repoPath, structure)
+		var committer core.GitCommitter
+		if commit {
+			committer = gitRepo
+		}
+		events := loadEventBus(repoPath, projectConfig)
+		transactions := filesystem.NewTransactionLog(repoPath, "")
+		cfg := web.Config{
+			RepoPath: repoPath,
+			Workflow: projectConfig.Workflow,
+			Fields:   projectConfig.Fields,
+			Stories:  storyRepo,
+			Board:    board,
+			Edit: services.NewStoryEditServiceWithGit(
+				parser, storyRepo, gitRepo, board,
+				services.NewCreateServiceWithTransactions(filesystem.NewIDCounter(repoPath), parser, storyRepo, backlogPath, events, transactions),
+				committer, repoPath, backlogPath, projectConfig.Workflow, events, transactions,
+			),
+			Burndown: services.NewSprintBurndownServiceWithCalendar(git.NewHistoryAnalyzer(parser), storyRepo, storyRepo, repoPath, projectConfig.Workflow, projectConfig.Calendar),
+		}
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		server := &http.Server{Handler: web.NewServer(cfg), ReadHeaderTimeout: 10 * time.Second}
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("Serving gitta on http://%s (Ctrl+C to stop)\n", listener.Addr())
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "localhost:7788", "Address to listen on (host:port)")
+	serveCmd.Flags().Bool("commit", false, "Commit every change made through the API")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <story-id>",
+	Short: "Show a story",
+	Long: `Show a story with its status (derived from Git unless set explicitly), its
+location, its custom fields, its body and its activity log (see gitta log).
+
+--json prints the story as the REST API does (id, title, status, priority,
+assignee, tags, fields, derived_status, location, created_at, updated_at,
+file, body) with its activity, newest first; --format renders the same
+fields with a Go template.
+
+Examples:
+  gitta story show US-004
+  gitta story show US-004 --format '{{.id}}: {{.title}} [{{.status}}] {{.file}}'
+  gitta story show US-004 --format '{{.fields.points}}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		tmpl, err := formatOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		board := services.NewBoardService(storyRepo, storyRepo, git.NewRepository(), repoPath, projectConfig.Workflow)
+
+		id := args[0]
+		_, path, err := storyRepo.FindStoryByID(ctx, repoPath, id)
+		if err != nil {
+			if errors.Is(err, core.ErrStoryNotFound) {
+				return fmt.Errorf("%w: %s", core.ErrStoryNotFound, id)
+			}
+			return err
+		}
+		snapshot, err := board.Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+		var story *services.StoryWithStatus
+		for _, s := range snapshot.Stories() {
+			if s.Story.ID == id {
+				story = s
+				break
+			}
+		}
+		if story == nil {
+			return fmt.Errorf("%w: %s", core.ErrStoryNotFound, id)
+		}
+
+		activities, err := services.NewActivityService(repoPath).List(ctx, services.ActivityFilter{ID: story.Story.ID})
+		if err != nil {
+			return err
+		}
+
+		out := storyShowJSON{StoryJSON: ui.NewStoryJSON(story.Story, story.Status, story.Derived, story.Source), Activity: activities}
+		out.File = relPath(repoPath, path)
+		out.Body = &story.Story.Body
+		if tmpl != nil {
+			return writeTemplate(tmpl, out)
+		}
+		if jsonOutput {
+			return encodeIndented(out)
+		}
+
+		printStory(out)
+		return nil
+	},
+}
+
+// storyShowJSON is the --json output of gitta story show.
+type storyShowJSON struct {
+	ui.StoryJSON
+	Activity []services.Activity `json:"activity"`
+}
+
+// printStory prints a story in human-readable form.
+func printStory(story storyShowJSON) {
+	fmt.Printf("%s  %s\n", story.ID, story.Title)
+	status := story.Status
+	if story.DerivedStatus != "" {
+		status += fmt.Sprintf(" (Git: %s)", story.DerivedStatus)
+	}
+	fmt.Printf("Status:    %s\n", status)
+	fmt.Printf("Priority:  %s\n", story.Priority)
+	if story.Assignee != nil {
+		fmt.Printf("Assignee:  %s\n", *story.Assignee)
+	}
+	if len(story.Tags) > 0 {
+		fmt.Printf("Tags:      %s\n", strings.Join(story.Tags, ", "))
+	}
+	fmt.Printf("Location:  %s\n", story.Location)
+	fmt.Printf("File:      %s\n", story.File)
+
+	names := make([]string, 0, len(story.Fields))
+	for name := range story.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%-10s %s\n", name+":", services.FormatFieldValue(story.Fields[name]))
+	}
+
+	if story.Body != nil && strings.TrimSpace(*story.Body) != "" {
+		fmt.Printf("\n%s", *story.Body)
+		if !strings.HasSuffix(*story.Body, "\n") {
+			fmt.Println()
+		}
+	}
+
+	if len(story.Activity) > 0 {
+		fmt.Println("\nActivity:")
+		printActivities(story.Activity)
+	}
+}
+
+func init() {
+	addTemplateFlags(showCmd)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Generate a static HTML site for the workspace",
+	Long: `Generate a static HTML site for the workspace.
+
+Use 'gitta site build' to write the site to a directory that can be published
+with any static host, such as GitHub Pages.`,
+}
+
+var siteBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build a self-contained static site",
+	Long: `Build a self-contained static site from the backlog and sprints.
+
+The site contains:
+  index.html                 sprint overview
+  backlog.html               backlog stories
+  epics.html                 stories grouped by their epic field
+  sprints/<sprint>.html      board per sprint, with its burndown chart
+  stories/<ID>.html          story page rendered from the Markdown body
+  search.html                search over search-index.json
+
+All links are relative, so the site works from any base path. Search loads
+search-index.json and needs the site to be served over HTTP.
+
+Examples:
+  gitta site build
+  gitta site build --out public/ --title "Checkout team"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		outDir, _ := cmd.Flags().GetString("out")
+		title, _ := cmd.Flags().GetString("title")
+		if !filepath.IsAbs(outDir) {
+			outDir = filepath.Join(repoPath, outDir)
+		}
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		gitRepo := git.NewRepository()
+		burndownService := services.NewSprintBurndownServiceWithCalendar(git.NewHistoryAnalyzer(parser), storyRepo, storyRepo, repoPath, projectConfig.Workflow, projectConfig.Calendar)
+		siteService := services.NewSiteService(storyRepo, storyRepo, gitRepo, burndownService, repoPath, projectConfig.Workflow)
+
+		result, err := siteService.Build(ctx, services.SiteRequest{OutDir: outDir, Title: title})
+		if err != nil {
+			return fmt.Errorf("site build: %w", err)
+		}
+
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		rel, err := filepath.Rel(repoPath, outDir)
+		if err != nil {
+			rel = outDir
+		}
+		fmt.Printf("✓ Built %d pages (%d stories, %d sprints, %d burndown charts) in %s\n",
+			result.Pages, result.Stories, result.Sprints, result.Charts, rel)
+		return nil
+	},
+}
+
+func init() {
+	siteBuildCmd.Flags().String("out", "public", "Output directory, relative to the repository root")
+	siteBuildCmd.Flags().String("title", "", "Site title (default: repository folder name)")
+	siteCmd.AddCommand(siteBuildCmd)
+	rootCmd.AddCommand(siteCmd)
+}
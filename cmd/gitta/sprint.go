@@ -3,14 +3,17 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gavin/gitta/infra/filesystem"
 	"github.com/gavin/gitta/infra/git"
 	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/core/workspace"
 	"github.com/gavin/gitta/internal/services"
 	"github.com/gavin/gitta/pkg/ui"
 	"github.com/gavin/gitta/ui/tui"
@@ -24,7 +27,7 @@ var sprintCmd = &cobra.Command{
 
 Sprints are time-bounded work periods that help organize tasks. Use 'sprint start' to
 create a new sprint, 'sprint close' to close a sprint and rollover unfinished tasks,
-and 'sprint burndown' to view sprint progress over time.`,
+and 'sprint burndown' or 'sprint chart' to view sprint progress over time.`,
 }
 
 var sprintStartCmd = &cobra.Command{
@@ -45,6 +48,7 @@ Examples:
   gitta sprint start 24                # Activate existing sprint (partial match)
   gitta sprint start Sprint_24         # Activate existing sprint (full match)
   gitta sprint start --duration 3w    # Create sprint with 3-week duration
+  gitta sprint start --duration 10wd  # Create sprint lasting 10 working days
   gitta sprint start --start-date 2025-02-01  # Create sprint starting on specific date
   gitta sprint start --dry-run         # Show what would be done without making changes`,
 	Args: cobra.MaximumNArgs(1),
@@ -65,12 +69,17 @@ Examples:
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 
+		projectConfig, err := services.LoadProjectConfig(repoPath)
+		if err != nil {
+			return err
+		}
+
 		sprintRepo := filesystem.NewDefaultRepository()
 		sprintsDir := filepath.Join(repoPath, "sprints")
 
 		// If sprint ID provided, try to activate existing sprint
 		if sprintID != "" {
-			statusService := services.NewSprintStatusService(sprintRepo, repoPath)
+			statusService := services.NewSprintStatusServiceWithEvents(sprintRepo, repoPath, loadEventBus(repoPath, projectConfig))
 
 			if dryRun {
 				// Check if sprint exists and can be activated
@@ -148,7 +157,7 @@ Examples:
 
 		var startDate *time.Time
 		if startDateStr != "" {
-			parsed, err := time.Parse("2006-01-02", startDateStr)
+			parsed, err := time.ParseInLocation("2006-01-02", startDateStr, projectConfig.Calendar.Zone())
 			if err != nil {
 				return fmt.Errorf("invalid start-date format (use YYYY-MM-DD): %w", err)
 			}
@@ -162,7 +171,7 @@ Examples:
 		}
 
 		// Create service
-		startService := services.NewSprintStartService(sprintRepo, repoPath)
+		startService := services.NewSprintStartServiceWithCalendar(sprintRepo, repoPath, projectConfig.Calendar)
 
 		if dryRun {
 			// For dry run, just show what would be created
@@ -287,10 +296,13 @@ Examples:
 		}
 
 		// Create services
-		storyRepo := filesystem.NewDefaultRepository()
-		sprintRepo := filesystem.NewDefaultRepository()
-		parser := filesystem.NewMarkdownParser()
-		closeService := services.NewSprintCloseService(storyRepo, sprintRepo, parser, repoPath)
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		sprintRepo := storyRepo
+		closeService := services.NewSprintCloseServiceWithTransactions(storyRepo, sprintRepo, parser, repoPath, projectConfig.Workflow, loadEventBus(repoPath, projectConfig), loadTransactions(repoPath))
 
 		// Find current sprint
 		sprintsDir := filepath.Join(repoPath, "sprints")
@@ -415,10 +427,16 @@ var sprintPlanCmd = &cobra.Command{
 The sprint will be created with the @ prefix and appear in the middle section
 of the sprint list. You can later activate it using 'sprint start <id>'.
 
+With --story, the stories are moved into the new sprint. The sprint's capacity
+is copied from the most recent sprint that defines one (see 'gitta sprint
+capacity'), and a warning is printed when a story pushes its assignee or the
+team over capacity.
+
 Examples:
   gitta sprint plan "Dashboard Redesign"     # Create planning sprint with auto-generated ID
   gitta sprint plan "Payment" --id Sprint_25  # Create planning sprint with specific ID
-  gitta sprint plan "Login Feature" --json    # Output result as JSON`,
+  gitta sprint plan "Login Feature" --json    # Output result as JSON
+  gitta sprint plan "Search" --story US-012 --story US-015  # Pull in stories`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
@@ -432,6 +450,7 @@ Examples:
 
 		description := args[0]
 		sprintID, _ := cmd.Flags().GetString("id")
+		storyIDs, _ := cmd.Flags().GetStringArray("story")
 
 		sprintRepo := filesystem.NewDefaultRepository()
 		planService := services.NewSprintPlanService(sprintRepo, repoPath)
@@ -446,6 +465,35 @@ Examples:
 			return err
 		}
 
+		// Carry the team's capacity over and pull in the requested stories,
+		// warning when a story pushes someone over capacity
+		var inheritedFrom string
+		var moved []string
+		if len(storyIDs) > 0 {
+			parser, projectConfig, err := loadStoryParser(repoPath)
+			if err != nil {
+				return err
+			}
+			storyRepo := filesystem.NewRepository(parser)
+			capacityService := services.NewSprintCapacityService(storyRepo, storyRepo)
+			moveService := services.NewMoveServiceWithTransactions(parser, storyRepo, repoPath, loadEventBus(repoPath, projectConfig), loadTransactions(repoPath))
+
+			if inheritedFrom, err = capacityService.InheritCapacity(ctx, sprint.DirectoryPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: cannot copy sprint capacity: %v\n", err)
+			}
+			for _, id := range storyIDs {
+				story, _, err := storyRepo.FindStoryByID(ctx, repoPath, id)
+				if err != nil {
+					return fmt.Errorf("story not found: %s", id)
+				}
+				printCapacityWarnings(capacityService.CheckAddition(ctx, sprint.DirectoryPath, story))
+				if err := moveService.MoveStory(ctx, id, sprint.DirectoryPath, false); err != nil {
+					return fmt.Errorf("failed to move story %s: %w", id, err)
+				}
+				moved = append(moved, id)
+			}
+		}
+
 		if jsonOutput {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
@@ -457,24 +505,30 @@ Examples:
 					"status":      "planning",
 					"description": description,
 				},
+				"stories": append([]string{}, moved...),
 			})
 		}
 
 		fmt.Printf("Created planning sprint: %s\n", filepath.Base(sprint.DirectoryPath))
 		fmt.Printf("Sprint will appear in the Planning section.\n")
+		if inheritedFrom != "" {
+			fmt.Printf("Capacity copied from %s\n", inheritedFrom)
+		}
+		if len(moved) > 0 {
+			fmt.Printf("Added %d stories: %s\n", len(moved), strings.Join(moved, ", "))
+		}
 		return nil
 	},
 }
 
 var sprintBoardCmd = &cobra.Command{
 	Use:   "board",
-	Short: "Display interactive kanban board (spike prototype)",
-	Long: `Display an interactive three-column kanban board TUI for evaluating Bubble Tea capabilities.
+	Short: "Display interactive kanban board for the current sprint",
+	Long: `Display an interactive three-column kanban board TUI for the current sprint.
 
-This is a research spike prototype that demonstrates:
-- Three-column layout rendering
-- Hardcoded task data display
-- Cursor navigation with arrow keys
+Columns follow the workflow categories (to do, in progress, done); each column
+lists the workflow states it contains, so configured states such as ready, qa
+or blocked appear in the matching column.
 
 Use arrow keys to navigate between columns (←/→) and tasks (↑/↓).
 Press 'q' or Esc to quit.
@@ -487,8 +541,28 @@ Examples:
 			ctx = context.Background()
 		}
 
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+
+		listService := services.NewListServiceWithWorkflow(filesystem.NewRepository(parser), git.NewRepository(), projectConfig.Workflow)
+		stories, err := listService.ListSprintTasks(ctx, repoPath)
+		if err != nil {
+			return fmt.Errorf("board: %w", err)
+		}
+
+		tasks := make([]tui.Task, 0, len(stories))
+		for _, s := range stories {
+			tasks = append(tasks, tui.Task{ID: s.Story.ID, Title: s.Story.Title, Status: string(s.Status)})
+		}
+
 		// Launch the board TUI
-		if err := tui.ShowBoard(ctx); err != nil {
+		if err := tui.RunBoard(ctx, tui.NewWorkflowBoard(projectConfig.Workflow, tasks)); err != nil {
 			return fmt.Errorf("board display failed: %w", err)
 		}
 
@@ -502,11 +576,23 @@ var sprintBurndownCmd = &cobra.Command{
 	Long: `Generate a burndown chart showing sprint progress over time by analyzing
 Git commit history and reconstructing daily remaining work.
 
+The chart includes the ideal guideline from sprint start to end, a
+linear-regression projection of the completion date with an "at risk"
+indicator when it misses the sprint end, and the days where scope was added.
+
 Examples:
   gitta sprint burndown                  # Burndown for current sprint
   gitta sprint burndown Sprint-01        # Burndown for specific sprint
   gitta sprint burndown --format json    # Output as JSON
-  gitta sprint burndown --format csv     # Output as CSV`,
+  gitta sprint burndown --format json --forecast
+  gitta sprint burndown --format csv     # Output as CSV
+  gitta sprint burndown --format png --output docs/burndown.png
+  gitta sprint burndown --format '{{.end}}: {{(index .points 0).remaining_points}} points left{{if .at_risk}} (at risk){{end}}'
+
+JSON output is an array of the daily values (see 'gitta schema burndown').
+With --forecast it is an object that adds the ideal line, the projection and
+the scope changes (see 'gitta schema burndown-forecast'). A --format
+containing "{{", or --template, renders that object with a Go template.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
@@ -524,43 +610,48 @@ Examples:
 		format, _ := cmd.Flags().GetString("format")
 		pointsOnly, _ := cmd.Flags().GetBool("points-only")
 		tasksOnly, _ := cmd.Flags().GetBool("tasks-only")
+		output, _ := cmd.Flags().GetString("output")
+		forecast, _ := cmd.Flags().GetBool("forecast")
 
 		// If sprint name provided as argument, use it
 		if len(args) > 0 && sprintName == "" {
 			sprintName = args[0]
 		}
 
+		tmpl, err := outputTemplate(cmd)
+		if err != nil {
+			return err
+		}
+		if tmpl != nil {
+			format = "template"
+		}
+		switch format {
+		case "ascii", "", "json", "csv", "svg", "png", "template":
+		default:
+			return fmt.Errorf("invalid format: %s (supported: ascii, json, csv, svg, png, or a Go template)", format)
+		}
+		if err := validateChartOutput(format, output); err != nil {
+			return err
+		}
+
 		// Create services
-		storyRepo := filesystem.NewDefaultRepository()
-		sprintRepo := filesystem.NewDefaultRepository()
-		parser := filesystem.NewMarkdownParser()
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		sprintRepo := storyRepo
 		gitAnalyzer := git.NewHistoryAnalyzer(parser)
-		burndownService := services.NewSprintBurndownService(gitAnalyzer, sprintRepo, storyRepo, repoPath)
+		burndownService := services.NewSprintBurndownServiceWithCalendar(gitAnalyzer, sprintRepo, storyRepo, repoPath, projectConfig.Workflow, projectConfig.Calendar)
 
 		// Find sprint path
-		sprintsDir := filepath.Join(repoPath, "sprints")
-		var sprintPath string
-
-		if sprintName == "" {
-			// Use current sprint
-			currentSprintPath, err := storyRepo.FindCurrentSprint(ctx, sprintsDir)
-			if err != nil {
-				return fmt.Errorf("no current sprint found: %w", err)
-			}
-			sprintPath = currentSprintPath
-		} else {
-			sprintPath = filepath.Join(sprintsDir, sprintName)
-			exists, err := sprintRepo.SprintExists(ctx, sprintPath)
-			if err != nil {
-				return fmt.Errorf("failed to check sprint: %w", err)
-			}
-			if !exists {
-				return fmt.Errorf("sprint %q does not exist", sprintName)
-			}
+		sprintPath, err := resolveSprintPath(ctx, storyRepo, repoPath, sprintName)
+		if err != nil {
+			return err
 		}
 
 		// Generate burndown data
-		dataPoints, err := burndownService.GenerateBurndown(ctx, sprintPath)
+		chart, err := burndownService.GenerateBurndownChart(ctx, sprintPath)
 		if err != nil {
 			if err == core.ErrInsufficientHistory {
 				return fmt.Errorf("insufficient Git history for burndown analysis: %w", err)
@@ -571,30 +662,215 @@ Examples:
 		// Output based on format
 		switch format {
 		case "json":
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(dataPoints)
+			if forecast {
+				return encodeIndented(ui.NewBurndownJSON(chart))
+			}
+			return encodeIndented(ui.NewBurndownDaysJSON(chart))
+
+		case "template":
+			return writeTemplate(tmpl, ui.NewBurndownJSON(chart))
 
 		case "csv":
-			fmt.Println(ui.FormatBurndownCSV(dataPoints))
+			fmt.Println(ui.FormatBurndownCSV(*chart))
 			return nil
 
-		case "ascii", "":
+		case "svg", "png":
+			return writeChart(ui.NewBurndownChart(*chart), format, output)
+
+		default:
 			showPoints := pointsOnly || (!pointsOnly && !tasksOnly)
 			showTasks := tasksOnly || (!pointsOnly && !tasksOnly)
-			chart := ui.RenderBurndownChart(dataPoints, showPoints, showTasks)
-			fmt.Println(chart)
+			fmt.Println(ui.RenderBurndownChart(*chart, showPoints, showTasks))
+			return nil
+		}
+	},
+}
+
+var sprintChartCmd = &cobra.Command{
+	Use:   "chart [sprint-name]",
+	Short: "Generate burnup or cumulative flow charts from Git history",
+	Long: `Generate sprint charts by analyzing Git commit history and reconstructing
+the sprint's stories day by day.
+
+Chart types:
+  burnup  Scope and completed tasks per day; a rising scope line shows scope creep
+  cfd     Cumulative flow diagram: number of tasks in each workflow state per day
+
+Examples:
+  gitta sprint chart --type burnup                 # ASCII burnup for current sprint
+  gitta sprint chart Sprint-01 --type cfd          # ASCII CFD for a specific sprint
+  gitta sprint chart --type cfd --format csv       # One column per workflow state
+  gitta sprint chart --type burnup --format svg > burnup.svg
+  gitta sprint chart --type cfd --format png --output docs/cfd.png`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		sprintName, _ := cmd.Flags().GetString("sprint")
+		chartType, _ := cmd.Flags().GetString("type")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if len(args) > 0 && sprintName == "" {
+			sprintName = args[0]
+		}
+		if jsonOutput {
+			format = "json"
+		}
+
+		switch chartType {
+		case "burnup", "cfd":
+		default:
+			return fmt.Errorf("invalid chart type: %s (supported: burnup, cfd)", chartType)
+		}
+		switch format {
+		case "ascii", "", "csv", "json", "svg", "png":
+		default:
+			return fmt.Errorf("invalid format: %s (supported: ascii, csv, json, svg, png)", format)
+		}
+		if err := validateChartOutput(format, output); err != nil {
+			return err
+		}
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		chartService := services.NewSprintBurndownServiceWithCalendar(git.NewHistoryAnalyzer(parser), storyRepo, storyRepo, repoPath, projectConfig.Workflow, projectConfig.Calendar)
+
+		sprintPath, err := resolveSprintPath(ctx, storyRepo, repoPath, sprintName)
+		if err != nil {
+			return err
+		}
+
+		if chartType == "burnup" {
+			dataPoints, err := chartService.GenerateBurnup(ctx, sprintPath)
+			if err != nil {
+				return chartError(err)
+			}
+			switch format {
+			case "json":
+				return encodeIndented(dataPoints)
+			case "csv":
+				fmt.Println(ui.FormatBurnupCSV(dataPoints))
+			case "svg", "png":
+				return writeChart(ui.NewBurnupChart(dataPoints), format, output)
+			default:
+				fmt.Println(ui.RenderBurnupChart(dataPoints))
+			}
 			return nil
+		}
 
+		flow, err := chartService.GenerateCumulativeFlow(ctx, sprintPath)
+		if err != nil {
+			return chartError(err)
+		}
+		switch format {
+		case "json":
+			return encodeIndented(flow)
+		case "csv":
+			fmt.Println(ui.FormatCumulativeFlowCSV(flow))
+		case "svg", "png":
+			return writeChart(ui.NewCumulativeFlowChart(flow), format, output)
 		default:
-			return fmt.Errorf("invalid format: %s (supported: ascii, json, csv)", format)
+			fmt.Println(ui.RenderCumulativeFlowChart(flow))
 		}
+		return nil
 	},
 }
 
+// resolveSprintPath returns the directory of the named sprint, or of the
+// current sprint when sprintName is empty.
+func resolveSprintPath(ctx context.Context, storyRepo *filesystem.Repository, repoPath, sprintName string) (string, error) {
+	structure, err := workspace.DetectStructure(ctx, repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace structure: %w", err)
+	}
+	sprintsDir := workspace.ResolveSprintsPath(repoPath, structure)
+
+	if sprintName == "" {
+		// Use current sprint
+		currentSprintPath, err := storyRepo.FindCurrentSprint(ctx, sprintsDir)
+		if err != nil {
+			return "", fmt.Errorf("no current sprint found: %w", err)
+		}
+		return currentSprintPath, nil
+	}
+
+	sprintPath := filepath.Join(sprintsDir, sprintName)
+	exists, err := storyRepo.SprintExists(ctx, sprintPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to check sprint: %w", err)
+	}
+	if !exists {
+		return "", fmt.Errorf("sprint %q does not exist", sprintName)
+	}
+	return sprintPath, nil
+}
+
+// chartError wraps a chart generation error for display.
+func chartError(err error) error {
+	if errors.Is(err, core.ErrInsufficientHistory) {
+		return fmt.Errorf("insufficient Git history for chart analysis: %w", err)
+	}
+	return fmt.Errorf("failed to generate chart: %w", err)
+}
+
+// encodeIndented writes v to stdout as indented JSON.
+func encodeIndented(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// validateChartOutput checks that --output is only used with image formats and
+// that PNG, which is binary, is written to a file.
+func validateChartOutput(format, output string) error {
+	switch {
+	case output != "" && format != "svg" && format != "png":
+		return fmt.Errorf("--output requires --format svg or png")
+	case output == "" && format == "png":
+		return fmt.Errorf("--format png requires --output <file>")
+	}
+	return nil
+}
+
+// writeChart writes chart as an SVG or PNG image to output, or SVG to stdout
+// when output is empty.
+func writeChart(chart *ui.Chart, format, output string) error {
+	var data []byte
+	if format == "png" {
+		png, err := chart.PNG()
+		if err != nil {
+			return fmt.Errorf("failed to render PNG: %w", err)
+		}
+		data = png
+	} else {
+		data = []byte(chart.SVG())
+	}
+
+	if output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write chart: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", output)
+	return nil
+}
+
 func init() {
 	// Sprint start flags
-	sprintStartCmd.Flags().StringP("duration", "d", "2w", "Sprint duration (e.g., '2w', '14d')")
+	sprintStartCmd.Flags().StringP("duration", "d", "2w", "Sprint duration (e.g., '2w', '14d', or '10wd' for working days)")
 	sprintStartCmd.Flags().String("start-date", "", "Sprint start date (YYYY-MM-DD format, defaults to today)")
 	sprintStartCmd.Flags().Bool("dry-run", false, "Show what would be done without making changes")
 
@@ -605,77 +881,29 @@ func init() {
 
 	// Sprint plan flags
 	sprintPlanCmd.Flags().String("id", "", "Specify sprint ID manually (default: auto-generate next sequential number)")
+	sprintPlanCmd.Flags().StringArray("story", []string{}, "Move a story into the new sprint (can specify multiple)")
 
 	// Sprint burndown flags
 	sprintBurndownCmd.Flags().StringP("sprint", "s", "", "Sprint name to analyze (alternative to positional argument)")
-	sprintBurndownCmd.Flags().String("format", "ascii", "Output format (ascii, json, csv)")
+	sprintBurndownCmd.Flags().String("format", "ascii", "Output format (ascii, json, csv, svg, png) or a Go template")
+	addTemplateFileFlag(sprintBurndownCmd)
+	sprintBurndownCmd.Flags().StringP("output", "o", "", "Write the svg or png chart to this file")
 	sprintBurndownCmd.Flags().Bool("points-only", false, "Show only story points (hide task count)")
 	sprintBurndownCmd.Flags().Bool("tasks-only", false, "Show only task count (hide story points)")
+	sprintBurndownCmd.Flags().Bool("forecast", false, "Include the ideal line, projection and scope changes in JSON output")
 
-	var sprintPlanCmd = &cobra.Command{
-		Use:   "plan <name>",
-		Short: "Create a new planning sprint",
-		Long: `Create a new sprint in Planning status for future work.
-
-The sprint will be created with the @ prefix and appear in the middle section
-of the sprint list. You can later activate it using 'sprint start <id>'.
-
-Examples:
-  gitta sprint plan "Dashboard Redesign"     # Create planning sprint with auto-generated ID
-  gitta sprint plan "Payment" --id Sprint_25  # Create planning sprint with specific ID
-  gitta sprint plan "Login Feature" --json    # Output result as JSON`,
-		Args: cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := cmd.Context()
-			if ctx == nil {
-				ctx = context.Background()
-			}
-			repoPath, err := findRepoRoot()
-			if err != nil {
-				return fmt.Errorf("not a git repository: %w", err)
-			}
-
-			description := args[0]
-			sprintID, _ := cmd.Flags().GetString("id")
-
-			sprintRepo := filesystem.NewDefaultRepository()
-			planService := services.NewSprintPlanService(sprintRepo, repoPath)
-
-			req := services.CreatePlanningSprintRequest{
-				ID:          sprintID,
-				Description: description,
-			}
-
-			sprint, err := planService.CreatePlanningSprint(ctx, req)
-			if err != nil {
-				return err
-			}
-
-			if jsonOutput {
-				enc := json.NewEncoder(os.Stdout)
-				enc.SetIndent("", "  ")
-				return enc.Encode(map[string]interface{}{
-					"sprint": map[string]interface{}{
-						"id":          sprint.Name,
-						"name":        filepath.Base(sprint.DirectoryPath),
-						"path":        sprint.DirectoryPath,
-						"status":      "planning",
-						"description": description,
-					},
-				})
-			}
-
-			fmt.Printf("Created planning sprint: %s\n", filepath.Base(sprint.DirectoryPath))
-			fmt.Printf("Sprint will appear in the Planning section.\n")
-			return nil
-		},
-	}
+	// Sprint chart flags
+	sprintChartCmd.Flags().StringP("sprint", "s", "", "Sprint name to analyze (alternative to positional argument)")
+	sprintChartCmd.Flags().String("type", "burnup", "Chart type (burnup, cfd)")
+	sprintChartCmd.Flags().String("format", "ascii", "Output format (ascii, csv, json, svg, png)")
+	sprintChartCmd.Flags().StringP("output", "o", "", "Write the svg or png chart to this file")
 
 	// Register subcommands
 	sprintCmd.AddCommand(sprintStartCmd)
 	sprintCmd.AddCommand(sprintPlanCmd)
 	sprintCmd.AddCommand(sprintCloseCmd)
 	sprintCmd.AddCommand(sprintBurndownCmd)
+	sprintCmd.AddCommand(sprintChartCmd)
 	sprintCmd.AddCommand(sprintBoardCmd)
 }
 
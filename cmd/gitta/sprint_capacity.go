@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var sprintCapacityCmd = &cobra.Command{
+	Use:   "capacity [sprint-name]",
+	Short: "Compare committed points per assignee against sprint capacity",
+	Long: `Compare the points committed per assignee with the sprint's capacity and flag
+overcommitment.
+
+Capacity is read from <sprint>/.gitta/capacity.yaml:
+
+  days: 10              # working days in the sprint
+  focus_factor: 0.8     # share of time spent on sprint stories (default 1)
+  points_per_day: 1     # points per focused person-day (default 1)
+  points_field: points  # numeric story field holding points (default points)
+  holidays: [2025-05-01]
+  members:
+    - name: alice
+    - name: bob
+      days: 6           # availability override (part time, vacation)
+      focus_factor: 0.5
+
+A member's capacity is (days - holidays) x focus factor x points per day.
+Committed points are summed from the stories in the sprint directory by their
+assignee field.
+
+Use --init to create the file, copied from the most recent sprint with
+capacity (without holidays) or listing the sprint's assignees.
+
+Examples:
+  gitta sprint capacity                  # Capacity of the current sprint
+  gitta sprint capacity @Sprint_05_Payments
+  gitta sprint capacity --init           # Create .gitta/capacity.yaml
+  gitta sprint capacity --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		sprintName := ""
+		if len(args) > 0 {
+			sprintName = args[0]
+		}
+		initCapacity, _ := cmd.Flags().GetBool("init")
+		format, _ := cmd.Flags().GetString("format")
+		if jsonOutput {
+			format = "json"
+		}
+		switch format {
+		case "table", "", "json":
+		default:
+			return fmt.Errorf("invalid format: %s (supported: table, json)", format)
+		}
+
+		parser, _, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		capacityService := services.NewSprintCapacityService(storyRepo, storyRepo)
+
+		sprintPath, err := resolveSprintPath(ctx, storyRepo, repoPath, sprintName)
+		if err != nil {
+			return err
+		}
+
+		if initCapacity {
+			capacity, from, err := capacityService.InitCapacity(ctx, sprintPath)
+			if err != nil {
+				return fmt.Errorf("sprint capacity: %w", err)
+			}
+			capacityPath := filepath.Join(sprintPath, ".gitta", "capacity.yaml")
+			if from != "" {
+				fmt.Fprintf(os.Stderr, "Created %s (copied from %s)\n", capacityPath, from)
+			} else {
+				fmt.Fprintf(os.Stderr, "Created %s with %d members\n", capacityPath, len(capacity.Members))
+			}
+		}
+
+		report, err := capacityService.Report(ctx, sprintPath)
+		if errors.Is(err, core.ErrNoCapacity) {
+			return fmt.Errorf("no capacity defined for %s (run 'gitta sprint capacity --init' or create .gitta/capacity.yaml)", filepath.Base(sprintPath))
+		}
+		if err != nil {
+			return fmt.Errorf("sprint capacity: %w", err)
+		}
+
+		if format == "json" {
+			return encodeIndented(toCapacityReportJSON(report))
+		}
+		fmt.Println(ui.RenderCapacityReport(*report))
+		return nil
+	},
+}
+
+// printCapacityWarnings prints the result of SprintCapacityService.CheckAddition
+// to stderr. Capacity problems never block the caller.
+func printCapacityWarnings(warnings []services.CapacityWarning, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot check sprint capacity: %v\n", err)
+		return
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+}
+
+type assigneeLoadJSON struct {
+	Assignee      string  `json:"assignee"`
+	Member        bool    `json:"member"`
+	Stories       int     `json:"stories"`
+	Committed     float64 `json:"committed"`
+	Capacity      float64 `json:"capacity"`
+	Overcommitted bool    `json:"overcommitted"`
+}
+
+type capacityReportJSON struct {
+	Sprint            string             `json:"sprint"`
+	Days              float64            `json:"days"`
+	FocusFactor       float64            `json:"focus_factor"`
+	PointsPerDay      float64            `json:"points_per_day"`
+	PointsField       string             `json:"points_field"`
+	Holidays          []string           `json:"holidays"`
+	Assignees         []assigneeLoadJSON `json:"assignees"`
+	UnassignedStories int                `json:"unassigned_stories"`
+	UnassignedPoints  float64            `json:"unassigned_points"`
+	TeamCapacity      float64            `json:"team_capacity"`
+	TeamCommitted     float64            `json:"team_committed"`
+	Overcommitted     bool               `json:"overcommitted"`
+}
+
+func toCapacityReportJSON(report *core.CapacityReport) capacityReportJSON {
+	c := report.Capacity
+	out := capacityReportJSON{
+		Sprint:            report.Sprint,
+		Days:              c.Days,
+		FocusFactor:       c.FocusFactor,
+		PointsPerDay:      c.PointsPerDay,
+		PointsField:       c.PointsFieldOrDefault(),
+		Holidays:          c.Holidays,
+		Assignees:         make([]assigneeLoadJSON, 0, len(report.Loads)),
+		UnassignedStories: report.UnassignedStories,
+		UnassignedPoints:  report.UnassignedPoints,
+		TeamCapacity:      report.TeamCapacity,
+		TeamCommitted:     report.TeamCommitted,
+		Overcommitted:     report.Overcommitted(),
+	}
+	if out.FocusFactor == 0 {
+		out.FocusFactor = 1
+	}
+	if out.PointsPerDay == 0 {
+		out.PointsPerDay = 1
+	}
+	if out.Holidays == nil {
+		out.Holidays = []string{}
+	}
+	for _, l := range report.Loads {
+		out.Assignees = append(out.Assignees, assigneeLoadJSON{
+			Assignee:      l.Assignee,
+			Member:        l.Member,
+			Stories:       l.Stories,
+			Committed:     l.Committed,
+			Capacity:      l.Capacity,
+			Overcommitted: l.Overcommitted(),
+		})
+	}
+	return out
+}
+
+func init() {
+	sprintCapacityCmd.Flags().Bool("init", false, "Create the sprint's capacity file before reporting")
+	sprintCapacityCmd.Flags().String("format", "table", "Output format (table, json)")
+
+	sprintCmd.AddCommand(sprintCapacityCmd)
+}
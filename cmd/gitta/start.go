@@ -28,15 +28,18 @@ var startCmd = &cobra.Command{
 			ctx = context.Background()
 		}
 
-		repoPath, err := os.Getwd()
+		repoPath, err := findRepoRoot()
 		if err != nil {
-			return fmt.Errorf("failed to determine working directory: %w", err)
+			return fmt.Errorf("not a git repository: %w", err)
 		}
 
-		storyRepo := filesystem.NewDefaultRepository()
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
 		gitRepo := git.NewRepository()
-		parser := filesystem.NewMarkdownParser()
-		startService := services.NewStartService(storyRepo, gitRepo, parser)
+		startService := services.NewStartServiceWithTransactions(storyRepo, gitRepo, parser, loadEventBus(repoPath, projectConfig), loadTransactions(repoPath))
 
 		story, branchName, startErr := startService.Start(ctx, repoPath, args[0], valuePtr(startAssignee))
 		var assigneeUpdateErr *services.AssigneeUpdateError
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report delivery metrics reconstructed from Git history",
+	Long: `Report delivery metrics reconstructed from Git history.
+
+Use 'gitta stats flow' for lead time, cycle time and time-in-status, and
+'gitta stats velocity' for the work completed per archived sprint.`,
+}
+
+var statsFlowCmd = &cobra.Command{
+	Use:   "flow",
+	Short: "Show lead time, cycle time and time-in-status distributions",
+	Long: `Show lead time, cycle time and time-in-status distributions for merged stories.
+
+Each story's timeline is reconstructed from Git rather than frontmatter
+timestamps:
+  created         first commit adding <ID>.md
+  branch created  first commit on the story branch (e.g., feat/<ID>)
+  first push      first entry of the origin/<branch> reflog
+  merged          commit on the target branch that brought the branch in
+
+Lead time runs from created to merged, cycle time from branch created to
+merged. Time-in-status attributes the spans between milestones to the
+workflow's initial, branch-derived and push-derived states.
+
+Only stories merged on or after --since are included; stories with an
+unmerged branch are counted as in progress.
+
+Examples:
+  gitta stats flow                       # Summary table, histograms and scatterplot
+  gitta stats flow --since 30d           # Stories merged in the last 30 days
+  gitta stats flow --since 2025-01-01 --tag api
+  gitta stats flow --format csv          # One row per story
+  gitta stats flow --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		sinceValue, _ := cmd.Flags().GetString("since")
+		tags, _ := cmd.Flags().GetStringArray("tag")
+		format, _ := cmd.Flags().GetString("format")
+		if jsonOutput {
+			format = "json"
+		}
+
+		switch format {
+		case "table", "", "csv", "json":
+		default:
+			return fmt.Errorf("invalid format: %s (supported: table, csv, json)", format)
+		}
+		since, err := parseSince(sinceValue, time.Now())
+		if err != nil {
+			return err
+		}
+		tagPattern := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+		for _, t := range tags {
+			if !tagPattern.MatchString(t) {
+				return fmt.Errorf("invalid tag: %s (must be alphanumeric with hyphens/underscores)", t)
+			}
+		}
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		flowService := services.NewFlowStatsServiceWithCalendar(storyRepo, storyRepo, git.NewHistoryAnalyzer(parser), repoPath, projectConfig.Workflow, projectConfig.Calendar)
+
+		report, err := flowService.FlowStats(ctx, services.FlowStatsOptions{Since: since, Tags: tags})
+		if err != nil {
+			return fmt.Errorf("stats flow: %w", err)
+		}
+
+		switch format {
+		case "json":
+			return encodeIndented(toFlowJSON(report))
+		case "csv":
+			fmt.Println(ui.FormatFlowCSV(*report))
+		default:
+			fmt.Println(ui.RenderFlowReport(*report))
+		}
+		return nil
+	},
+}
+
+var statsVelocityCmd = &cobra.Command{
+	Use:   "velocity",
+	Short: "Show completed tasks and points per archived sprint",
+	Long: `Show completed tasks and points for each archived sprint (prefix ~).
+
+A story counts as completed when its derived status is in the workflow's done
+category. Points are read from a numeric story field (default: points).
+Sprint start and end dates come from Git: the first commit touching the
+sprint directory and the commit that archived it.
+
+Examples:
+  gitta stats velocity                   # All archived sprints
+  gitta stats velocity --last 5          # Five most recent sprints
+  gitta stats velocity --points-field estimate
+  gitta stats velocity --format csv
+  gitta stats velocity --format svg --output docs/velocity.svg`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		pointsField, _ := cmd.Flags().GetString("points-field")
+		last, _ := cmd.Flags().GetInt("last")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if jsonOutput {
+			format = "json"
+		}
+
+		switch format {
+		case "table", "", "csv", "json", "svg", "png":
+		default:
+			return fmt.Errorf("invalid format: %s (supported: table, csv, json, svg, png)", format)
+		}
+		if err := validateChartOutput(format, output); err != nil {
+			return err
+		}
+		if last < 0 {
+			return fmt.Errorf("invalid --last: %d (must be zero or positive)", last)
+		}
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		velocityService := services.NewVelocityServiceWithWorkflow(storyRepo, storyRepo, git.NewRepository(), git.NewHistoryAnalyzer(parser), repoPath, projectConfig.Workflow)
+
+		velocities, err := velocityService.Velocity(ctx, services.VelocityOptions{PointsField: pointsField, Last: last})
+		if err != nil {
+			return fmt.Errorf("stats velocity: %w", err)
+		}
+
+		switch format {
+		case "json":
+			return encodeIndented(toVelocityJSON(velocities))
+		case "csv":
+			fmt.Println(ui.FormatVelocityCSV(velocities))
+		case "svg", "png":
+			return writeChart(ui.NewVelocityChart(velocities), format, output)
+		default:
+			fmt.Println(ui.RenderVelocityTable(velocities))
+		}
+		return nil
+	},
+}
+
+// parseSince parses --since as a date (YYYY-MM-DD, local time) or a period
+// before now ("30d", "6w"). An empty value means no lower bound.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	days, err := core.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since: %s (use YYYY-MM-DD or a period like 30d or 6w)", value)
+	}
+	return now.AddDate(0, 0, -days), nil
+}
+
+type flowStatsJSON struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean_hours"`
+	P50   float64 `json:"p50_hours"`
+	P85   float64 `json:"p85_hours"`
+	P95   float64 `json:"p95_hours"`
+	Max   float64 `json:"max_hours"`
+}
+
+type flowStatusJSON struct {
+	Status string `json:"status"`
+	flowStatsJSON
+}
+
+type flowStoryJSON struct {
+	ID            string             `json:"id"`
+	Title         string             `json:"title"`
+	Branch        string             `json:"branch"`
+	Created       *time.Time         `json:"created,omitempty"`
+	BranchCreated *time.Time         `json:"branch_created,omitempty"`
+	FirstPush     *time.Time         `json:"first_push,omitempty"`
+	Merged        *time.Time         `json:"merged"`
+	LeadTime      *float64           `json:"lead_time_hours,omitempty"`
+	CycleTime     *float64           `json:"cycle_time_hours,omitempty"`
+	TimeInStatus  map[string]float64 `json:"time_in_status_hours"`
+}
+
+type flowReportJSON struct {
+	Since        *time.Time       `json:"since,omitempty"`
+	Merged       int              `json:"merged"`
+	InProgress   int              `json:"in_progress"`
+	LeadTime     flowStatsJSON    `json:"lead_time"`
+	CycleTime    flowStatsJSON    `json:"cycle_time"`
+	TimeInStatus []flowStatusJSON `json:"time_in_status"`
+	Stories      []flowStoryJSON  `json:"stories"`
+}
+
+func toFlowJSON(report *core.FlowReport) flowReportJSON {
+	out := flowReportJSON{
+		Merged:       len(report.Stories),
+		InProgress:   report.InProgress,
+		LeadTime:     toFlowStatsJSON(report.LeadTime),
+		CycleTime:    toFlowStatsJSON(report.CycleTime),
+		TimeInStatus: make([]flowStatusJSON, 0, len(report.TimeInStatus)),
+		Stories:      make([]flowStoryJSON, 0, len(report.Stories)),
+	}
+	if !report.Since.IsZero() {
+		out.Since = &report.Since
+	}
+	for _, s := range report.TimeInStatus {
+		out.TimeInStatus = append(out.TimeInStatus, flowStatusJSON{Status: string(s.Status), flowStatsJSON: toFlowStatsJSON(s.Stats)})
+	}
+	for _, s := range report.Stories {
+		story := flowStoryJSON{
+			ID:            s.ID,
+			Title:         s.Title,
+			Branch:        s.Timeline.Branch,
+			Created:       s.Timeline.Created,
+			BranchCreated: s.Timeline.BranchCreated,
+			FirstPush:     s.Timeline.FirstPush,
+			Merged:        s.Timeline.Merged,
+			LeadTime:      hoursOrNil(s.LeadTime),
+			CycleTime:     hoursOrNil(s.CycleTime),
+			TimeInStatus:  make(map[string]float64, len(s.InStatus)),
+		}
+		for status, d := range s.InStatus {
+			story.TimeInStatus[string(status)] = d.Hours()
+		}
+		out.Stories = append(out.Stories, story)
+	}
+	return out
+}
+
+func toFlowStatsJSON(stats core.DurationStats) flowStatsJSON {
+	return flowStatsJSON{
+		Count: stats.Count,
+		Mean:  stats.Mean.Hours(),
+		P50:   stats.P50.Hours(),
+		P85:   stats.P85.Hours(),
+		P95:   stats.P95.Hours(),
+		Max:   stats.Max.Hours(),
+	}
+}
+
+func hoursOrNil(d *time.Duration) *float64 {
+	if d == nil {
+		return nil
+	}
+	h := d.Hours()
+	return &h
+}
+
+type sprintVelocityJSON struct {
+	Name            string     `json:"name"`
+	Start           *time.Time `json:"start,omitempty"`
+	End             *time.Time `json:"end,omitempty"`
+	Tasks           int        `json:"tasks"`
+	CompletedTasks  int        `json:"completed_tasks"`
+	Points          float64    `json:"points"`
+	CompletedPoints float64    `json:"completed_points"`
+}
+
+type velocityJSON struct {
+	Sprints []sprintVelocityJSON `json:"sprints"`
+}
+
+func toVelocityJSON(velocities []core.SprintVelocity) velocityJSON {
+	out := velocityJSON{Sprints: make([]sprintVelocityJSON, 0, len(velocities))}
+	for _, v := range velocities {
+		out.Sprints = append(out.Sprints, sprintVelocityJSON{
+			Name:            v.Name,
+			Start:           v.Start,
+			End:             v.End,
+			Tasks:           v.Tasks,
+			CompletedTasks:  v.CompletedTasks,
+			Points:          v.Points,
+			CompletedPoints: v.CompletedPoints,
+		})
+	}
+	return out
+}
+
+func init() {
+	statsFlowCmd.Flags().String("since", "", "Only include stories merged since a date (YYYY-MM-DD) or period (e.g., 30d, 6w)")
+	statsFlowCmd.Flags().StringArray("tag", []string{}, "Filter by tags (story must have any tag)")
+	statsFlowCmd.Flags().String("format", "table", "Output format (table, csv, json)")
+
+	statsVelocityCmd.Flags().String("points-field", "points", "Numeric story field summed as points")
+	statsVelocityCmd.Flags().Int("last", 0, "Only show the most recent N sprints (0 = all)")
+	statsVelocityCmd.Flags().String("format", "table", "Output format (table, csv, json, svg, png)")
+	statsVelocityCmd.Flags().StringP("output", "o", "", "Write the svg or png chart to this file")
+
+	statsCmd.AddCommand(statsFlowCmd)
+	statsCmd.AddCommand(statsVelocityCmd)
+	rootCmd.AddCommand(statsCmd)
+}
@@ -4,28 +4,61 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
 	"github.com/gavin/gitta/internal/core"
 	"github.com/gavin/gitta/internal/services"
 )
 
-var statusStatus string
+var (
+	statusStatus string
+	statusForce  bool
+	statusSync   bool
+)
 
 var statusCmd = &cobra.Command{
-	Use:   "status <story-id>",
+	Use:   "status [story-id]",
 	Short: "Update story status",
-	Long:  "Update a story's status atomically with data corruption prevention.",
-	Args:  cobra.ExactArgs(1),
+	Long: `Update a story's status atomically with data corruption prevention.
+
+Status changes must follow the workflow's transitions (default: todo → doing,
+doing → todo/review/done, review → doing/done, done → doing). The current
+status of a story without an explicit one is derived from Git. Use --force to
+set a status the workflow does not allow from the current one.
+
+Use --sync to clear explicit statuses that disagree with Git (see
+'gitta list --drift') so they are derived from Git again. Without a story ID,
+every drifted story is synced.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		if ctx == nil {
 			ctx = context.Background()
 		}
 
+		// Get repository path
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		if statusSync {
+			if statusStatus != "" || statusForce {
+				return fmt.Errorf("--sync cannot be combined with --status or --force")
+			}
+			storyID := ""
+			if len(args) == 1 {
+				storyID = args[0]
+			}
+			return runStatusSync(ctx, repoPath, storyID)
+		}
+
+		if len(args) != 1 {
+			return fmt.Errorf("story ID is required")
+		}
 		storyID := args[0]
 
 		// Validate status
@@ -33,22 +66,23 @@ var statusCmd = &cobra.Command{
 			return fmt.Errorf("--status is required")
 		}
 
-		// Get repository path
-		repoPath, err := os.Getwd()
+		// Create service dependencies
+		parser, projectConfig, err := loadStoryParser(repoPath)
 		if err != nil {
-			return fmt.Errorf("failed to determine working directory: %w", err)
+			return err
 		}
-
-		// Create service dependencies
-		parser := filesystem.NewMarkdownParser()
 		storyRepo := filesystem.NewRepository(parser)
-		updateService := services.NewUpdateService(parser, storyRepo, repoPath)
+		updateService := services.NewUpdateServiceWithGit(parser, storyRepo, git.NewRepository(), repoPath, projectConfig.Workflow, loadEventBus(repoPath, projectConfig), loadTransactions(repoPath))
 
 		// Parse status
 		newStatus := core.Status(statusStatus)
 
 		// Update status
-		err = updateService.UpdateStatus(ctx, storyID, newStatus)
+		if statusForce {
+			err = updateService.ForceStatus(ctx, storyID, newStatus)
+		} else {
+			err = updateService.UpdateStatus(ctx, storyID, newStatus)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to update status: %w", err)
 		}
@@ -88,7 +122,70 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// runStatusSync clears explicit statuses that disagree with Git, for one story
+// or for every drifted story when storyID is empty.
+func runStatusSync(ctx context.Context, repoPath, storyID string) error {
+	parser, projectConfig, err := loadStoryParser(repoPath)
+	if err != nil {
+		return err
+	}
+	storyRepo := filesystem.NewRepository(parser)
+	gitRepo := git.NewRepository()
+	listService := services.NewListServiceWithWorkflow(storyRepo, gitRepo, projectConfig.Workflow)
+	updateService := services.NewUpdateServiceWithGit(parser, storyRepo, gitRepo, repoPath, projectConfig.Workflow, loadEventBus(repoPath, projectConfig), loadTransactions(repoPath))
+
+	if storyID != "" {
+		if _, _, err := storyRepo.FindStoryByID(ctx, repoPath, storyID); err != nil {
+			return fmt.Errorf("story not found: %s", storyID)
+		}
+	}
+
+	drifted, err := listService.ListDrift(ctx, repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to detect status drift: %w", err)
+	}
+
+	type syncedJSON struct {
+		ID       string `json:"id"`
+		Explicit string `json:"explicit"`
+		Derived  string `json:"derived"`
+	}
+	synced := make([]syncedJSON, 0, len(drifted))
+	for _, s := range drifted {
+		if storyID != "" && s.Story.ID != storyID {
+			continue
+		}
+		if err := updateService.ClearStatus(ctx, s.Story.ID); err != nil {
+			return fmt.Errorf("failed to sync status of %s: %w", s.Story.ID, err)
+		}
+		synced = append(synced, syncedJSON{ID: s.Story.ID, Explicit: string(s.Status), Derived: string(s.Derived)})
+	}
+
+	if jsonOutput {
+		jsonBytes, err := json.Marshal(map[string]interface{}{"synced": synced, "total": len(synced)})
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	if len(synced) == 0 {
+		if storyID != "" {
+			fmt.Printf("%s: status already matches Git\n", storyID)
+		} else {
+			fmt.Println("No status drift found.")
+		}
+		return nil
+	}
+	for _, s := range synced {
+		fmt.Printf("Synced %s: status %s -> %s (derived from Git)\n", s.ID, s.Explicit, s.Derived)
+	}
+	return nil
+}
+
 func init() {
-	statusCmd.Flags().StringVar(&statusStatus, "status", "", "New status value (todo, doing, review, done) (required)")
-	statusCmd.MarkFlagRequired("status")
+	statusCmd.Flags().StringVar(&statusStatus, "status", "", "New status value from the workflow (default: todo, doing, review, done) (required unless --sync)")
+	statusCmd.Flags().BoolVar(&statusForce, "force", false, "Set the status even if the workflow does not allow the transition")
+	statusCmd.Flags().BoolVar(&statusSync, "sync", false, "Clear explicit statuses that disagree with Git")
 }
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/infra/github"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/core/workspace"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd is the parent command for external tracker syncs.
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync stories with external issue trackers",
+	Long:  "Commands for synchronizing stories with external issue trackers.",
+}
+
+var syncGitHubCmd = &cobra.Command{
+	Use:   "github",
+	Short: "Two-way sync of stories with GitHub Issues",
+	Long: `Synchronize stories with the issues of a GitHub repository in both directions.
+
+Titles, bodies, labels (tags), the first assignee and the open/closed state
+(done/not done) are synced. Stories without an issue get one, issues without a
+story become backlog stories, and the link is stored in the story frontmatter
+(external: {github: 123}).
+
+A pair changed on both sides since the last sync is reported as a conflict and
+left alone unless --prefer picks a side. Sync baselines are kept in
+.gitta/sync/github.json.
+
+The repository and API URL come from the github section of .gitta/config.yaml
+or the flags; use --base-url https://<host>/api/v3 for GitHub Enterprise
+Server. The token is read from $GITHUB_TOKEN.
+
+Examples:
+  gitta sync github --dry-run
+  gitta sync github --repo acme/shop --prefer remote --commit`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+
+		repo, _ := cmd.Flags().GetString("repo")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		prefer, _ := cmd.Flags().GetString("prefer")
+		commit, _ := cmd.Flags().GetBool("commit")
+
+		parser, projectConfig, err := loadStoryParser(repoPath)
+		if err != nil {
+			return err
+		}
+		if repo == "" {
+			repo = projectConfig.GitHub.Repository
+		}
+		if repo == "" {
+			return fmt.Errorf("no GitHub repository: use --repo owner/name or set github.repository in %s", services.ProjectConfigFile)
+		}
+		if baseURL == "" {
+			baseURL = projectConfig.GitHub.BaseURL
+		}
+		token := os.Getenv(github.TokenEnv)
+		if token == "" {
+			return fmt.Errorf("%s is not set", github.TokenEnv)
+		}
+
+		structure, err := workspace.DetectStructure(ctx, repoPath)
+		if err != nil {
+			return fmt.Errorf("failed to detect workspace structure: %w", err)
+		}
+		storyRepo := filesystem.NewRepository(parser)
+		gitRepo := git.NewRepository()
+		board := services.NewBoardService(storyRepo, storyRepo, gitRepo, repoPath, projectConfig.Workflow)
+		backlogPath := workspace.ResolveBacklogPath(repoPath, structure)
+		var committer core.GitCommitter
+		if commit {
+			committer = gitRepo
+		}
+		events := loadEventBus(repoPath, projectConfig)
+		transactions := loadTransactions(repoPath)
+		edit := services.NewStoryEditServiceWithGit(
+			parser, storyRepo, gitRepo, board,
+			services.NewCreateServiceWithTransactions(filesystem.NewIDCounter(repoPath), parser, storyRepo, backlogPath, events, transactions),
+			committer, repoPath, backlogPath, projectConfig.Workflow, events, transactions,
+		)
+		syncService := services.NewIssueSyncService(
+			github.NewClient(baseURL, repo, token),
+			board, edit, projectConfig.Fields, repoPath, projectConfig.Workflow,
+		)
+
+		report, err := syncService.Sync(ctx, services.IssueSyncOptions{DryRun: dryRun, Prefer: prefer})
+		if report != nil {
+			printSyncReport(cmd, report)
+		}
+		if err != nil {
+			return err
+		}
+		if conflicts := report.Count(services.SyncConflict); conflicts > 0 {
+			return fmt.Errorf("%d conflict(s) left unresolved (use --prefer local or --prefer remote)", conflicts)
+		}
+		return nil
+	},
+}
+
+// printSyncReport prints one line per change and a summary.
+func printSyncReport(cmd *cobra.Command, report *services.IssueSyncReport) {
+	out := cmd.OutOrStdout()
+	for _, change := range report.Changes {
+		story, issue := change.StoryID, fmt.Sprintf("#%d", change.Issue)
+		if story == "" {
+			story = "new story"
+		}
+		if change.Issue == 0 {
+			issue = "new issue"
+		}
+		line := fmt.Sprintf("%-12s %-10s %-10s %s", change.Action, story, issue, change.Title)
+		if len(change.Fields) > 0 {
+			line += fmt.Sprintf(" [%s]", strings.Join(change.Fields, ", "))
+		}
+		if change.Detail != "" {
+			line += ": " + change.Detail
+		}
+		fmt.Fprintln(out, line)
+	}
+
+	summary := fmt.Sprintf("%d issue(s) created, %d story(ies) created, %d pushed, %d pulled, %d conflict(s), %d unchanged",
+		report.Count(services.SyncCreateIssue), report.Count(services.SyncCreateStory),
+		report.Count(services.SyncPush), report.Count(services.SyncPull),
+		report.Count(services.SyncConflict), report.Unchanged)
+	if missing := report.Count(services.SyncMissing); missing > 0 {
+		summary += fmt.Sprintf(", %d missing", missing)
+	}
+	if report.DryRun {
+		summary = "Dry run: " + summary + " (nothing changed)"
+	}
+	fmt.Fprintln(out, summary)
+}
+
+func init() {
+	syncGitHubCmd.Flags().String("repo", "", "GitHub repository as owner/name (default: github.repository from the config)")
+	syncGitHubCmd.Flags().String("base-url", "", "API root, e.g. https://github.example.com/api/v3 (default: github.base_url or https://api.github.com)")
+	syncGitHubCmd.Flags().Bool("dry-run", false, "Show what would be done without making changes")
+	syncGitHubCmd.Flags().String("prefer", "", "Resolve conflicts by keeping the local story or the remote issue (local|remote)")
+	syncGitHubCmd.Flags().Bool("commit", false, "Commit every story change")
+	syncCmd.AddCommand(syncGitHubCmd)
+	rootCmd.AddCommand(syncCmd)
+}
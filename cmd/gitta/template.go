@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+// addTemplateFlags registers --format and --template on a command whose
+// --json output can also be rendered with a Go template.
+func addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", "", `Output format: json, or a Go template such as '{{.id}} {{.title}}'`)
+	addTemplateFileFlag(cmd)
+}
+
+// addTemplateFileFlag registers --template on a command with its own --format.
+func addTemplateFileFlag(cmd *cobra.Command) {
+	cmd.Flags().String("template", "", "Render the output with the Go template in this file")
+}
+
+// outputTemplate parses the template of --template, or of --format when its
+// value contains "{{". It returns nil when no template is given.
+func outputTemplate(cmd *cobra.Command) (*template.Template, error) {
+	format, _ := cmd.Flags().GetString("format")
+	file, _ := cmd.Flags().GetString("template")
+	isTemplate := strings.Contains(format, "{{")
+
+	switch {
+	case file != "":
+		if cmd.Flags().Changed("format") {
+			return nil, fmt.Errorf("--format and --template cannot be combined")
+		}
+		text, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		return ui.ParseTemplate(string(text))
+	case isTemplate:
+		return ui.ParseTemplate(format)
+	}
+	return nil, nil
+}
+
+// formatOutput resolves the flags of addTemplateFlags: it returns the
+// template to render, or nil, and --format json turns on JSON output.
+func formatOutput(cmd *cobra.Command) (*template.Template, error) {
+	tmpl, err := outputTemplate(cmd)
+	if err != nil || tmpl != nil {
+		return tmpl, err
+	}
+	switch format, _ := cmd.Flags().GetString("format"); format {
+	case "":
+	case "json":
+		jsonOutput = true
+	default:
+		return nil, fmt.Errorf("invalid format: %s (supported: json or a Go template)", format)
+	}
+	return nil, nil
+}
+
+// writeTemplate renders v, in its JSON form, to standard output.
+func writeTemplate(tmpl *template.Template, v interface{}) error {
+	return ui.ExecuteTemplate(os.Stdout, tmpl, v)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [count]",
+	Short: "Revert the story file changes of the last gitta commands",
+	Long: `Revert the story files changed by the last count gitta commands (default 1),
+newest first: created stories are removed, and edited, moved and rolled over
+stories are restored as they were.
+
+gitta keeps a copy of every file it changes in .gitta/transactions/ (ignored
+by Git) for the last 50 commands. A command whose files changed since, by
+hand or by an operation that cannot be undone, is not reverted unless
+--force is given. Git branches, commits and the sprint folders renamed by
+'gitta sprint start' are left as they are. Each command undone is recorded
+in the activity log and fires the operation.undone hooks.
+
+Examples:
+  gitta undo
+  gitta undo 3
+  gitta undo --list`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		repoPath, err := findRepoRoot()
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		count := 1
+		if len(args) > 0 {
+			if count, err = strconv.Atoi(args[0]); err != nil || count < 1 {
+				return fmt.Errorf("invalid count %q (expected a positive number)", args[0])
+			}
+		}
+		list, _ := cmd.Flags().GetBool("list")
+		force, _ := cmd.Flags().GetBool("force")
+		cmd.SilenceUsage = true
+
+		transactions := filesystem.NewTransactionLog(repoPath, commandLine())
+		if list {
+			operations, err := transactions.Operations(ctx)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				return encodeIndented(map[string]interface{}{"operations": operations})
+			}
+			if len(operations) == 0 {
+				fmt.Println("Nothing to undo.")
+				return nil
+			}
+			printOperations(operations)
+			return nil
+		}
+
+		cfg, err := services.LoadProjectConfig(repoPath)
+		if err != nil {
+			return err
+		}
+		undoService := services.NewUndoService(transactions, loadEventBus(repoPath, cfg))
+		undone, err := undoService.Undo(ctx, count, force)
+		if jsonOutput && err == nil {
+			return encodeIndented(map[string]interface{}{"undone": undone})
+		}
+		for _, operation := range undone {
+			fmt.Printf("Undid %s\n", operation.Command)
+			for _, file := range operation.Files {
+				fmt.Printf("  %s\n", file)
+			}
+		}
+		return err
+	},
+}
+
+// printOperations prints the operations that can be undone as a table.
+func printOperations(operations []core.Operation) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTIME\tCOMMAND\tCHANGES")
+	for i, operation := range operations {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+			i+1, operation.Time.Local().Format("2006-01-02 15:04"), operation.Command, strings.Join(operation.Changes, "; "))
+	}
+	w.Flush()
+}
+
+// recoverTransactions rolls back file changes left half done by a gitta
+// command that was interrupted, before any command runs.
+func recoverTransactions(ctx context.Context) {
+	repoPath, err := findRepoRoot()
+	if err != nil {
+		return
+	}
+	recovered, err := filesystem.NewTransactionLog(repoPath, "").Recover(ctx)
+	for _, operation := range recovered {
+		fmt.Fprintf(os.Stderr, "Warning: rolled back interrupted operation %q\n", operation)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot recover interrupted operations: %v\n", err)
+	}
+}
+
+func init() {
+	undoCmd.Flags().Bool("list", false, "List the commands that can be undone, newest first")
+	undoCmd.Flags().Bool("force", false, "Undo even if the files changed since")
+	rootCmd.AddCommand(undoCmd)
+}
@@ -19,8 +19,18 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
 	Long: `Print version information including semantic version, commit SHA,
-build date, and Go runtime version. Use --json for machine-readable output.`,
+build date, and Go runtime version. Use --json for machine-readable output,
+or --format with a Go template over the JSON fields (version, commit, buildDate,
+goVersion).
+
+Examples:
+  gitta version --format '{{.version}}'
+  gitta version --format '{{.commit | truncate 8}} built {{.buildDate | date "2006-01-02"}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		tmpl, err := formatOutput(cmd)
+		if err != nil {
+			return err
+		}
 		info := version.NewInfo()
 
 		// Override with build-time values if available
@@ -37,6 +47,9 @@ build date, and Go runtime version. Use --json for machine-readable output.`,
 			}
 		}
 
+		if tmpl != nil {
+			return writeTemplate(tmpl, info)
+		}
 		if jsonOutput {
 			jsonStr, err := info.FormatJSON()
 			if err != nil {
@@ -50,6 +63,10 @@ build date, and Go runtime version. Use --json for machine-readable output.`,
 	},
 }
 
+func init() {
+	addTemplateFlags(versionCmd)
+}
+
 func parseBuildDate(dateStr string) (time.Time, error) {
 	// Parse RFC3339 format (e.g., "2024-01-15T10:30:00Z")
 	return time.Parse(time.RFC3339, dateStr)
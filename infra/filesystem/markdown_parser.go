@@ -3,10 +3,14 @@ package filesystem
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/yuin/goldmark"
 	meta "github.com/yuin/goldmark-meta"
@@ -23,7 +27,8 @@ const maxFileSize = 10 * 1024 * 1024 // 10MB
 // It uses Goldmark with the meta extension to parse YAML frontmatter and extract
 // Markdown body content. MarkdownParser is thread-safe for concurrent read operations.
 type MarkdownParser struct {
-	md goldmark.Markdown
+	md    goldmark.Markdown
+	rules services.ValidationRules
 }
 
 // NewMarkdownParser creates a new MarkdownParser instance.
@@ -38,6 +43,14 @@ func NewMarkdownParser() *MarkdownParser {
 	return &MarkdownParser{md: md}
 }
 
+// NewMarkdownParserWithRules creates a MarkdownParser that also enforces the
+// given project validation rules (e.g., custom fields from .gitta/config.yaml).
+func NewMarkdownParserWithRules(rules services.ValidationRules) *MarkdownParser {
+	p := NewMarkdownParser()
+	p.rules = rules
+	return p
+}
+
 // ReadStory reads a Markdown file and parses it into a Story struct.
 // It extracts YAML frontmatter metadata and Markdown body content.
 // Missing optional fields are set to default values (Priority: Medium, Status: Todo).
@@ -68,6 +81,16 @@ func (p *MarkdownParser) ReadStory(ctx context.Context, filePath string) (*core.
 		}
 	}
 
+	return p.ParseStory(ctx, filePath, data)
+}
+
+// ParseStory parses story content that has already been read, e.g. an unsaved
+// editor buffer. filePath is only used in errors.
+func (p *MarkdownParser) ParseStory(ctx context.Context, filePath string, data []byte) (*core.Story, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+
 	// Parse with Goldmark to extract frontmatter
 	var buf bytes.Buffer
 	context := parser.NewContext()
@@ -88,6 +111,15 @@ func (p *MarkdownParser) ReadStory(ctx context.Context, filePath string) (*core.
 	// Unmarshal frontmatter into Story struct
 	var story core.Story
 	if len(metaData) > 0 {
+		if field, err := normalizeTimestamps(metaData); err != nil {
+			return nil, &core.ParseError{
+				FilePath: filePath,
+				Line:     frontmatterLine(data, field),
+				Message:  err.Error(),
+				Cause:    err,
+			}
+		}
+
 		// Convert metaData map to YAML bytes for unmarshaling
 		yamlData, err := yaml.Marshal(metaData)
 		if err != nil {
@@ -101,6 +133,7 @@ func (p *MarkdownParser) ReadStory(ctx context.Context, filePath string) (*core.
 		if err := yaml.Unmarshal(yamlData, &story); err != nil {
 			return nil, &core.ParseError{
 				FilePath: filePath,
+				Line:     unmarshalErrorLine(data, yamlData, err),
 				Message:  fmt.Sprintf("failed to unmarshal YAML frontmatter: %v", err),
 				Cause:    err,
 			}
@@ -111,11 +144,85 @@ func (p *MarkdownParser) ReadStory(ctx context.Context, filePath string) (*core.
 	story.Body = body
 
 	// Apply default values for missing optional fields
-	applyDefaults(&story)
+	applyDefaults(&story, p.rules.WorkflowOrDefault().Initial)
 
 	return &story, nil
 }
 
+// timestampFields are the frontmatter keys holding timestamps.
+var timestampFields = []string{"created_at", "updated_at"}
+
+// normalizeTimestamps converts date-only timestamps (YYYY-MM-DD) in the
+// frontmatter to midnight UTC, so they are read and written back as RFC 3339.
+// It returns the field and error of the first value that is neither.
+func normalizeTimestamps(metaData map[string]interface{}) (string, error) {
+	for _, field := range timestampFields {
+		value, ok := metaData[field].(string)
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err == nil {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return field, fmt.Errorf("%s: invalid timestamp %q (expected RFC 3339 or YYYY-MM-DD)", field, value)
+		}
+		metaData[field] = date
+	}
+	return "", nil
+}
+
+// yamlErrorLinePattern extracts the line number of a yaml.v3 type error.
+var yamlErrorLinePattern = regexp.MustCompile(`^line (\d+): `)
+
+// unmarshalErrorLine returns the file line of the frontmatter key whose value
+// failed to decode from yamlData, the re-encoded frontmatter of data, or 0
+// when unknown.
+func unmarshalErrorLine(data, yamlData []byte, err error) int {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) || len(typeErr.Errors) == 0 {
+		return 0
+	}
+	m := yamlErrorLinePattern.FindStringSubmatch(typeErr.Errors[0])
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+
+	// The re-encoded keys are in another order: find the key at that line
+	var doc yaml.Node
+	if yaml.Unmarshal(yamlData, &doc) != nil || len(doc.Content) == 0 {
+		return 0
+	}
+	field := ""
+	mapping := doc.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Line <= line {
+			field = mapping.Content[i].Value
+		}
+	}
+	return frontmatterLine(data, field)
+}
+
+// frontmatterLine returns the file line of a top-level frontmatter key, or 0
+// when it is not found.
+func frontmatterLine(data []byte, field string) int {
+	if field == "" {
+		return 0
+	}
+	lines := strings.Split(string(data), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines) && strings.TrimSpace(lines[i]) != "---"; i++ {
+		if strings.HasPrefix(lines[i], field+":") {
+			return i + 1
+		}
+	}
+	return 0
+}
+
 // extractBody extracts the Markdown body content after the frontmatter delimiter.
 func extractBody(content string) string {
 	// Look for frontmatter delimiter
@@ -154,13 +261,15 @@ func extractBody(content string) string {
 	return body
 }
 
-// applyDefaults sets default values for missing optional fields.
-func applyDefaults(story *core.Story) {
+// applyDefaults sets default values for missing optional fields. Stories
+// without a status get the workflow's initial status.
+func applyDefaults(story *core.Story, initial core.Status) {
 	if story.Priority == "" {
 		story.Priority = core.PriorityMedium
 	}
 	if story.Status == "" {
-		story.Status = core.StatusTodo
+		story.Status = initial
+		story.StatusDefaulted = true
 	}
 	if story.Tags == nil {
 		story.Tags = []string{}
@@ -215,8 +324,15 @@ func (p *MarkdownParser) WriteStory(ctx context.Context, filePath string, story
 		lineEnding = detectLineEnding(string(existingData))
 	}
 
-	// Marshal frontmatter to YAML
-	frontmatterData, err := yaml.Marshal(story)
+	// Marshal frontmatter to YAML. Defaulted statuses stay implicit so they
+	// keep being derived from Git.
+	frontmatter := story
+	if story.StatusDefaulted {
+		implicit := *story
+		implicit.Status = ""
+		frontmatter = &implicit
+	}
+	frontmatterData, err := yaml.Marshal(frontmatter)
 	if err != nil {
 		return &core.ParseError{
 			FilePath: filePath,
@@ -232,8 +348,11 @@ func (p *MarkdownParser) WriteStory(ctx context.Context, filePath string, story
 	content.Write(frontmatterData)
 	content.WriteString("---")
 	content.WriteString(lineEnding)
-	content.WriteString(lineEnding)
-	content.WriteString(story.Body)
+	// A blank line separates the body; stories without one end at the delimiter
+	if story.Body != "" {
+		content.WriteString(lineEnding)
+		content.WriteString(story.Body)
+	}
 
 	// Normalize line endings in content
 	contentStr := normalizeLineEndings(content.String(), lineEnding)
@@ -282,7 +401,8 @@ func normalizeLineEndings(content, lineEnding string) string {
 }
 
 // ValidateStory validates a Story struct against business rules.
-// It delegates to the service layer validator to check all field constraints.
+// It delegates to the service layer validator to check all field constraints,
+// including any custom fields the parser was configured with.
 // Returns a slice of ValidationErrors describing any violations. An empty slice
 // indicates the story is valid.
 //
@@ -290,5 +410,5 @@ func normalizeLineEndings(content, lineEnding string) string {
 // trade-off. In a production system, validation could be injected as a dependency
 // to maintain strict hexagonal boundaries.
 func (p *MarkdownParser) ValidateStory(story *core.Story) []core.ValidationError {
-	return services.ValidateStory(story)
+	return services.ValidateStoryWithRules(story, p.rules)
 }
@@ -193,7 +193,7 @@ func (r *Repository) FindStoryByID(ctx context.Context, repoPath, storyID string
 				continue
 			}
 			name := entry.Name()
-			if !strings.HasPrefix(strings.ToLower(name), "sprint") {
+			if !strings.HasPrefix(strings.ToLower(strings.TrimLeft(name, "!+@~")), "sprint") {
 				continue
 			}
 			sprintPath := filepath.Join(sprintsDir, name)
@@ -557,6 +557,26 @@ func (r *Repository) WriteSprintStatus(ctx context.Context, sprintPath string, s
 	return WriteSprintStatus(ctx, sprintPath, status)
 }
 
+// ReadSprintCapacity reads the sprint capacity from .gitta/capacity.yaml.
+func (r *Repository) ReadSprintCapacity(ctx context.Context, sprintPath string) (*core.SprintCapacity, error) {
+	return ReadSprintCapacity(ctx, sprintPath)
+}
+
+// WriteSprintCapacity writes the sprint capacity to .gitta/capacity.yaml.
+func (r *Repository) WriteSprintCapacity(ctx context.Context, sprintPath string, capacity *core.SprintCapacity) error {
+	return WriteSprintCapacity(ctx, sprintPath, capacity)
+}
+
+// ReadSprintSchedule reads the sprint dates from .gitta/schedule.yaml.
+func (r *Repository) ReadSprintSchedule(ctx context.Context, sprintPath string) (*core.SprintSchedule, error) {
+	return ReadSprintSchedule(ctx, sprintPath)
+}
+
+// WriteSprintSchedule writes the sprint dates to .gitta/schedule.yaml.
+func (r *Repository) WriteSprintSchedule(ctx context.Context, sprintPath string, schedule *core.SprintSchedule) error {
+	return WriteSprintSchedule(ctx, sprintPath, schedule)
+}
+
 // RenameSprintWithPrefix renames a sprint folder with a new status prefix atomically.
 // Includes retry logic for Windows file locks and improved error messages.
 func (r *Repository) RenameSprintWithPrefix(ctx context.Context, oldPath string, newPrefix core.SprintStatus, id string, desc string) error {
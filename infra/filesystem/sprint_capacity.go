@@ -0,0 +1,70 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gavin/gitta/internal/core"
+	"gopkg.in/yaml.v3"
+)
+
+// capacityFileName is the sprint capacity file below the sprint's .gitta directory.
+const capacityFileName = "capacity.yaml"
+
+// ReadSprintCapacity reads and validates the sprint's .gitta/capacity.yaml file.
+// Returns core.ErrNoCapacity if the file doesn't exist.
+func ReadSprintCapacity(ctx context.Context, sprintDir string) (*core.SprintCapacity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	capacityPath := filepath.Join(sprintDir, ".gitta", capacityFileName)
+	data, err := os.ReadFile(capacityPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, core.ErrNoCapacity
+		}
+		return nil, &core.IOError{Operation: "read", FilePath: capacityPath, Cause: err}
+	}
+
+	var capacity core.SprintCapacity
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&capacity); err != nil {
+		return nil, fmt.Errorf("invalid capacity in %s: %w", capacityPath, err)
+	}
+	if err := capacity.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid capacity in %s: %w", capacityPath, err)
+	}
+	return &capacity, nil
+}
+
+// WriteSprintCapacity writes the sprint's .gitta/capacity.yaml file.
+// Creates the .gitta directory if it doesn't exist.
+func WriteSprintCapacity(ctx context.Context, sprintDir string, capacity *core.SprintCapacity) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := capacity.Validate(); err != nil {
+		return fmt.Errorf("invalid capacity: %w", err)
+	}
+
+	metaDir := filepath.Join(sprintDir, ".gitta")
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: metaDir, Cause: err}
+	}
+
+	data, err := yaml.Marshal(capacity)
+	if err != nil {
+		return fmt.Errorf("failed to encode capacity: %w", err)
+	}
+	capacityPath := filepath.Join(metaDir, capacityFileName)
+	if err := os.WriteFile(capacityPath, data, 0644); err != nil {
+		return &core.IOError{Operation: "write", FilePath: capacityPath, Cause: err}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gavin/gitta/internal/core"
+	"gopkg.in/yaml.v3"
+)
+
+// scheduleFileName is the sprint schedule file below the sprint's .gitta directory.
+const scheduleFileName = "schedule.yaml"
+
+// ReadSprintSchedule reads the sprint's .gitta/schedule.yaml file.
+// Returns core.ErrNoSchedule if the file doesn't exist.
+func ReadSprintSchedule(ctx context.Context, sprintDir string) (*core.SprintSchedule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	schedulePath := filepath.Join(sprintDir, ".gitta", scheduleFileName)
+	data, err := os.ReadFile(schedulePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, core.ErrNoSchedule
+		}
+		return nil, &core.IOError{Operation: "read", FilePath: schedulePath, Cause: err}
+	}
+
+	var schedule core.SprintSchedule
+	if err := yaml.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("invalid schedule in %s: %w", schedulePath, err)
+	}
+	if schedule.Start.IsZero() || !schedule.End.After(schedule.Start) {
+		return nil, fmt.Errorf("invalid schedule in %s: end must be after start", schedulePath)
+	}
+	return &schedule, nil
+}
+
+// WriteSprintSchedule writes the sprint's .gitta/schedule.yaml file.
+// Creates the .gitta directory if it doesn't exist.
+func WriteSprintSchedule(ctx context.Context, sprintDir string, schedule *core.SprintSchedule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	metaDir := filepath.Join(sprintDir, ".gitta")
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: metaDir, Cause: err}
+	}
+
+	data, err := yaml.Marshal(schedule)
+	if err != nil {
+		return fmt.Errorf("failed to encode schedule: %w", err)
+	}
+	schedulePath := filepath.Join(metaDir, scheduleFileName)
+	if err := os.WriteFile(schedulePath, data, 0644); err != nil {
+		return &core.IOError{Operation: "write", FilePath: schedulePath, Cause: err}
+	}
+	return nil
+}
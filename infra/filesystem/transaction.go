@@ -0,0 +1,729 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// TransactionDir holds the journals of file transactions, relative to the
+// repository root.
+const TransactionDir = ".gitta/transactions"
+
+const (
+	// undoHistory is how many operations are kept to be undone.
+	undoHistory = 50
+	// recoverAfter is how old an unfinished transaction must be before
+	// Recover treats it as interrupted rather than being committed by
+	// another process.
+	recoverAfter = lockTimeout
+)
+
+// Journal states of a transaction.
+const (
+	txPending   = "pending"
+	txCommitted = "committed"
+	txUndoing   = "undoing"
+	txUndone    = "undone"
+)
+
+var errTransactionDone = errors.New("transaction already committed or rolled back")
+
+// TransactionLog implements core.FileTransactions and core.OperationLog with
+// journals under .gitta/transactions. Before a transaction is applied, the
+// files it changes are copied to its journal, so an interrupted commit is
+// rolled back by Recover and committed transactions can be undone.
+//
+// The transactions begun through one TransactionLog form one operation, the
+// unit of Undo, so commands create one per run. Without a command line, as
+// for servers, every transaction is an operation of its own.
+type TransactionLog struct {
+	root    string
+	command string
+	session string
+}
+
+// txJournal is the journal.json of a transaction.
+type txJournal struct {
+	Session   string    `json:"session"`
+	Command   string    `json:"command,omitempty"`
+	Operation string    `json:"operation"`
+	Time      time.Time `json:"time"`
+	State     string    `json:"state"`
+	Files     []txFile  `json:"files"`
+	// Dirs lists the directories the commit created, outermost first.
+	Dirs []string `json:"dirs,omitempty"`
+}
+
+// txFile is a file changed by a transaction.
+type txFile struct {
+	// Path is relative to the repository root, with forward slashes.
+	Path string `json:"path"`
+	// Backup names the copy of the file in backup/; empty when the file
+	// did not exist.
+	Backup string `json:"backup,omitempty"`
+	// Hash is the SHA-256 of the file once committed; empty when removed.
+	Hash string `json:"hash,omitempty"`
+}
+
+// NewTransactionLog creates a TransactionLog for the repository at
+// repoPath. command is the command line recorded for its operation, or ""
+// to record each transaction as an operation.
+func NewTransactionLog(repoPath, command string) *TransactionLog {
+	if abs, err := filepath.Abs(repoPath); err == nil {
+		repoPath = abs
+	}
+	return &TransactionLog{root: repoPath, command: command, session: newTransactionID()}
+}
+
+// Begin implements core.FileTransactions.Begin.
+func (l *TransactionLog) Begin(ctx context.Context, operation string) (core.FileTransaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+	if err := l.ensureDir(); err != nil {
+		return nil, err
+	}
+	id := newTransactionID()
+	session := l.session
+	if l.command == "" {
+		session = id
+	}
+	dir := filepath.Join(l.dir(), id)
+	for _, sub := range []string{"staged", "backup"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, &core.IOError{Operation: "create", FilePath: dir, Cause: err}
+		}
+	}
+	return &fileTransaction{
+		log:     l,
+		dir:     dir,
+		journal: txJournal{Session: session, Command: l.command, Operation: operation},
+	}, nil
+}
+
+// Recover rolls back transactions interrupted while being committed and
+// finishes interrupted undos. It returns the operations rolled back.
+func (l *TransactionLog) Recover(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(l.dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, &core.IOError{Operation: "read", FilePath: l.dir(), Cause: err}
+	}
+
+	var recovered []string
+	var errs []error
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return recovered, fmt.Errorf("context cancelled: %w", err)
+		}
+		info, err := entry.Info()
+		if !entry.IsDir() || err != nil || time.Since(info.ModTime()) < recoverAfter {
+			continue
+		}
+		dir := filepath.Join(l.dir(), entry.Name())
+		journal, err := readJournal(dir)
+		switch {
+		case os.IsNotExist(err):
+			// Never committed: only staged files to discard
+			if err := os.RemoveAll(dir); err != nil {
+				errs = append(errs, err)
+			}
+		case err != nil:
+			errs = append(errs, err)
+		case journal.State == txPending:
+			if err := l.restore(dir, journal); err != nil {
+				errs = append(errs, fmt.Errorf("failed to roll back %q: %w", journal.Operation, err))
+				continue
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				errs = append(errs, err)
+			}
+			recovered = append(recovered, journal.Operation)
+		case journal.State == txUndoing:
+			if err := l.undoTransaction(dir, journal); err != nil {
+				errs = append(errs, fmt.Errorf("failed to undo %q: %w", journal.Operation, err))
+				continue
+			}
+			recovered = append(recovered, journal.Operation)
+		}
+	}
+	return recovered, errors.Join(errs...)
+}
+
+// Operations implements core.OperationLog.Operations.
+func (l *TransactionLog) Operations(ctx context.Context) ([]core.Operation, error) {
+	ops, err := l.operations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]core.Operation, len(ops))
+	for i, op := range ops {
+		out[i] = op.Operation
+	}
+	return out, nil
+}
+
+// Undo implements core.OperationLog.Undo.
+func (l *TransactionLog) Undo(ctx context.Context, n int, force bool) ([]core.Operation, error) {
+	ops, err := l.operations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, core.ErrNothingToUndo
+	}
+	if n > len(ops) {
+		return nil, fmt.Errorf("%w: only %d operations can be undone", core.ErrNothingToUndo, len(ops))
+	}
+
+	var undone []core.Operation
+	for _, op := range ops[:n] {
+		if !force {
+			if changed := l.changedFiles(op); len(changed) > 0 {
+				return undone, fmt.Errorf("%w %q: %s (use --force to undo anyway)",
+					core.ErrUndoConflict, op.Command, strings.Join(changed, ", "))
+			}
+		}
+		for i := len(op.transactions) - 1; i >= 0; i-- {
+			tx := op.transactions[i]
+			if err := l.undoTransaction(tx.dir, tx.journal); err != nil {
+				return undone, fmt.Errorf("failed to undo %q: %w", op.Command, err)
+			}
+		}
+		undone = append(undone, op.Operation)
+	}
+	return undone, nil
+}
+
+// loggedTransaction is a transaction read from the log.
+type loggedTransaction struct {
+	dir     string
+	journal *txJournal
+}
+
+// loggedOperation is an operation with its committed transactions, oldest
+// first.
+type loggedOperation struct {
+	core.Operation
+	transactions []loggedTransaction
+}
+
+// transactions returns the transactions of the log, oldest first.
+func (l *TransactionLog) transactions(ctx context.Context) ([]loggedTransaction, error) {
+	entries, err := os.ReadDir(l.dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, &core.IOError{Operation: "read", FilePath: l.dir(), Cause: err}
+	}
+	var out []loggedTransaction
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("context cancelled: %w", err)
+		}
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(l.dir(), entry.Name())
+		journal, err := readJournal(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, loggedTransaction{dir: dir, journal: journal})
+	}
+	return out, nil
+}
+
+// operations groups the committed transactions by operation, newest first.
+func (l *TransactionLog) operations(ctx context.Context) ([]*loggedOperation, error) {
+	txs, err := l.transactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bySession := make(map[string]*loggedOperation)
+	var ops []*loggedOperation
+	for _, tx := range txs {
+		if tx.journal.State != txCommitted {
+			continue
+		}
+		op := bySession[tx.journal.Session]
+		if op == nil {
+			op = &loggedOperation{Operation: core.Operation{
+				ID:      tx.journal.Session,
+				Command: tx.journal.Command,
+				Time:    tx.journal.Time,
+			}}
+			if op.Command == "" {
+				op.Command = tx.journal.Operation
+			}
+			bySession[tx.journal.Session] = op
+			ops = append(ops, op)
+		}
+		op.transactions = append(op.transactions, tx)
+		op.Changes = append(op.Changes, tx.journal.Operation)
+		for _, file := range tx.journal.Files {
+			if !containsString(op.Files, file.Path) {
+				op.Files = append(op.Files, file.Path)
+			}
+		}
+	}
+	// Newest first, by the last transaction of each operation
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].last().dir > ops[j].last().dir
+	})
+	return ops, nil
+}
+
+func (op *loggedOperation) last() loggedTransaction {
+	return op.transactions[len(op.transactions)-1]
+}
+
+// changedFiles returns the files of op that no longer hold what it wrote.
+func (l *TransactionLog) changedFiles(op *loggedOperation) []string {
+	expected := make(map[string]string)
+	for _, tx := range op.transactions {
+		for _, file := range tx.journal.Files {
+			expected[file.Path] = file.Hash
+		}
+	}
+	var changed []string
+	for _, path := range op.Files {
+		if hash, err := hashFile(l.abs(path)); err != nil || hash != expected[path] {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// undoTransaction restores the files of a committed transaction.
+func (l *TransactionLog) undoTransaction(dir string, journal *txJournal) error {
+	journal.State = txUndoing
+	if err := writeJournal(dir, journal); err != nil {
+		return err
+	}
+	if err := l.restore(dir, journal); err != nil {
+		return err
+	}
+	journal.State = txUndone
+	return writeJournal(dir, journal)
+}
+
+// restore puts back the files of a transaction as they were before it and
+// removes the directories it created.
+func (l *TransactionLog) restore(dir string, journal *txJournal) error {
+	var errs []error
+	for i := len(journal.Files) - 1; i >= 0; i-- {
+		file := journal.Files[i]
+		path := l.abs(file.Path)
+		if file.Backup == "" {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := copyFile(filepath.Join(dir, "backup", file.Backup), path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := len(journal.Dirs) - 1; i >= 0; i-- {
+		// Only empty directories are removed
+		os.Remove(l.abs(journal.Dirs[i]))
+	}
+	return errors.Join(errs...)
+}
+
+// prune removes the oldest finished operations beyond undoHistory.
+func (l *TransactionLog) prune(ctx context.Context) error {
+	txs, err := l.transactions(ctx)
+	if err != nil {
+		return err
+	}
+	var sessions []string
+	seen := make(map[string]bool)
+	for i := len(txs) - 1; i >= 0; i-- {
+		if session := txs[i].journal.Session; !seen[session] {
+			seen[session] = true
+			sessions = append(sessions, session)
+		}
+	}
+	if len(sessions) <= undoHistory {
+		return nil
+	}
+	stale := make(map[string]bool)
+	for _, session := range sessions[undoHistory:] {
+		stale[session] = true
+	}
+	for _, tx := range txs {
+		state := tx.journal.State
+		if stale[tx.journal.Session] && (state == txCommitted || state == txUndone) {
+			if err := os.RemoveAll(tx.dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *TransactionLog) dir() string {
+	return filepath.Join(l.root, filepath.FromSlash(TransactionDir))
+}
+
+// ensureDir creates the log directory, which keeps itself out of Git.
+func (l *TransactionLog) ensureDir() error {
+	if err := os.MkdirAll(l.dir(), 0o755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: l.dir(), Cause: err}
+	}
+	ignore := filepath.Join(l.dir(), ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0o644); err != nil {
+			return &core.IOError{Operation: "write", FilePath: ignore, Cause: err}
+		}
+	}
+	return nil
+}
+
+// rel returns path as recorded in journals: relative to the repository root
+// when inside it.
+func (l *TransactionLog) rel(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if rel, err := filepath.Rel(l.root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// abs returns the path of a journal path.
+func (l *TransactionLog) abs(path string) string {
+	path = filepath.FromSlash(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(l.root, path)
+}
+
+// txOp is a staged change.
+type txOp struct {
+	kind   string // "write", "rename" or "remove"
+	path   string
+	from   string // source of a rename
+	staged string // staging file of a write
+}
+
+type fileTransaction struct {
+	log     *TransactionLog
+	dir     string
+	journal txJournal
+	ops     []txOp
+	done    bool
+}
+
+// Write implements core.FileTransaction.Write.
+func (t *fileTransaction) Write(path string, write func(staged string) error) error {
+	if t.done {
+		return errTransactionDone
+	}
+	path = absPath(path)
+	staged := filepath.Join(t.dir, "staged", strconv.Itoa(len(t.ops)))
+
+	// Start from the content the file will have when this write applies
+	current := path
+	for _, op := range t.ops {
+		switch {
+		case op.kind == "write" && op.path == path:
+			current = op.staged
+		case op.kind != "write" && op.path == path:
+			current = ""
+		}
+	}
+	if current != "" {
+		if err := copyFile(current, staged); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := write(staged); err != nil {
+		return err
+	}
+	t.ops = append(t.ops, txOp{kind: "write", path: path, staged: staged})
+	return nil
+}
+
+// Rename implements core.FileTransaction.Rename.
+func (t *fileTransaction) Rename(oldPath, newPath string) error {
+	if t.done {
+		return errTransactionDone
+	}
+	t.ops = append(t.ops, txOp{kind: "rename", path: absPath(newPath), from: absPath(oldPath)})
+	return nil
+}
+
+// Remove implements core.FileTransaction.Remove.
+func (t *fileTransaction) Remove(path string) error {
+	if t.done {
+		return errTransactionDone
+	}
+	t.ops = append(t.ops, txOp{kind: "remove", path: absPath(path)})
+	return nil
+}
+
+// Rollback implements core.FileTransaction.Rollback.
+func (t *fileTransaction) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	return os.RemoveAll(t.dir)
+}
+
+// Commit implements core.FileTransaction.Commit. The files are backed up and
+// the journal is written as pending before anything changes, so a commit
+// interrupted halfway is rolled back by Recover.
+func (t *fileTransaction) Commit(ctx context.Context) error {
+	if t.done {
+		return errTransactionDone
+	}
+	t.done = true
+	if err := ctx.Err(); err != nil {
+		os.RemoveAll(t.dir)
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	if err := t.backup(); err != nil {
+		os.RemoveAll(t.dir)
+		return err
+	}
+	t.journal.Time = time.Now().UTC()
+	t.journal.State = txPending
+	if err := writeJournal(t.dir, &t.journal); err != nil {
+		os.RemoveAll(t.dir)
+		return err
+	}
+
+	err := t.apply()
+	if err == nil {
+		for i := range t.journal.Files {
+			t.journal.Files[i].Hash, _ = hashFile(t.log.abs(t.journal.Files[i].Path))
+		}
+		t.journal.State = txCommitted
+		err = writeJournal(t.dir, &t.journal)
+	}
+	if err != nil {
+		if restoreErr := t.log.restore(t.dir, &t.journal); restoreErr != nil {
+			return fmt.Errorf("%w (rollback failed, the next gitta command retries it: %v)", err, restoreErr)
+		}
+		os.RemoveAll(t.dir)
+		return err
+	}
+
+	os.RemoveAll(filepath.Join(t.dir, "staged"))
+	return t.log.prune(ctx)
+}
+
+// backup records the files the transaction changes and copies those that
+// exist to backup/.
+func (t *fileTransaction) backup() error {
+	for _, op := range t.ops {
+		for _, path := range []string{op.from, op.path} {
+			if path == "" || t.hasFile(path) {
+				continue
+			}
+			file := txFile{Path: t.log.rel(path)}
+			info, err := os.Lstat(path)
+			switch {
+			case err == nil && !info.Mode().IsRegular():
+				return &core.IOError{Operation: "write", FilePath: path, Cause: fmt.Errorf("not a regular file")}
+			case err == nil:
+				file.Backup = strconv.Itoa(len(t.journal.Files))
+				if err := copyFile(path, filepath.Join(t.dir, "backup", file.Backup)); err != nil {
+					return err
+				}
+			case !os.IsNotExist(err):
+				return &core.IOError{Operation: "read", FilePath: path, Cause: err}
+			}
+			t.journal.Files = append(t.journal.Files, file)
+		}
+	}
+	return nil
+}
+
+func (t *fileTransaction) hasFile(path string) bool {
+	rel := t.log.rel(path)
+	for _, file := range t.journal.Files {
+		if file.Path == rel {
+			return true
+		}
+	}
+	return false
+}
+
+// apply makes the staged changes.
+func (t *fileTransaction) apply() error {
+	for _, op := range t.ops {
+		if op.kind != "remove" {
+			if err := t.mkdirParents(op.path); err != nil {
+				return err
+			}
+		}
+		var err error
+		switch op.kind {
+		case "write":
+			err = os.Rename(op.staged, op.path)
+		case "rename":
+			err = os.Rename(op.from, op.path)
+		case "remove":
+			err = os.Remove(op.path)
+		}
+		if err != nil {
+			return &core.IOError{Operation: op.kind, FilePath: op.path, Cause: err}
+		}
+	}
+	return nil
+}
+
+// mkdirParents creates the missing parent directories of path and records
+// them in the journal.
+func (t *fileTransaction) mkdirParents(path string) error {
+	var missing []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		missing = append([]string{dir}, missing...)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: filepath.Dir(path), Cause: err}
+	}
+	for _, dir := range missing {
+		t.journal.Dirs = append(t.journal.Dirs, t.log.rel(dir))
+	}
+	return writeJournal(t.dir, &t.journal)
+}
+
+// newTransactionID returns a unique ID that sorts by creation time.
+func newTransactionID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return time.Now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix)
+}
+
+func readJournal(dir string) (*txJournal, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "journal.json"))
+	if err != nil {
+		return nil, err
+	}
+	var journal txJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, &core.ParseError{FilePath: filepath.Join(dir, "journal.json"), Message: "invalid transaction journal", Cause: err}
+	}
+	return &journal, nil
+}
+
+// writeJournal atomically replaces the journal of a transaction.
+func writeJournal(dir string, journal *txJournal) error {
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction journal: %w", err)
+	}
+	path := filepath.Join(dir, "journal.json")
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return &core.IOError{Operation: "write", FilePath: path, Cause: err}
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		os.Remove(path + ".tmp")
+		return &core.IOError{Operation: "write", FilePath: path, Cause: err}
+	}
+	return nil
+}
+
+// copyFile atomically replaces dst with a copy of src.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return &core.IOError{Operation: "write", FilePath: dst, Cause: err}
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return &core.IOError{Operation: "write", FilePath: dst, Cause: err}
+	}
+	return nil
+}
+
+// hashFile returns the SHA-256 of a file, or "" when it does not exist.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// CommitFiles stages the given paths and commits them. Paths already staged by
+// the user are committed along with them, as with 'git commit'.
+func (r *Repository) CommitFiles(ctx context.Context, repoPath, message string, paths []string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", ErrNotGitRepository
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	root := wt.Filesystem.Root()
+
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(repoPath, path)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("path outside the repository: %s", path)
+		}
+		rel = filepath.ToSlash(rel)
+
+		if _, err := os.Stat(path); err == nil {
+			if _, err := wt.Add(rel); err != nil {
+				return "", fmt.Errorf("failed to stage %s: %w", rel, err)
+			}
+			continue
+		}
+		// Deleted files that were never committed have nothing to record
+		if _, err := wt.Remove(rel); err != nil && !errors.Is(err, index.ErrEntryNotFound) {
+			return "", fmt.Errorf("failed to stage removal of %s: %w", rel, err)
+		}
+	}
+
+	hash, err := wt.Commit(message, &git.CommitOptions{})
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to commit: %w", err)
+	}
+	return hash.String(), nil
+}
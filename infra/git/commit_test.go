@@ -0,0 +1,61 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepository_CommitFiles(t *testing.T) {
+	repo, repoPath := createTempRepo(t)
+	repoImpl := NewRepository()
+	commitFile(t, repo, repoPath, "main")
+	cfg, _ := repo.Config()
+	cfg.User.Name, cfg.User.Email = "Test User", "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	story := filepath.Join(repoPath, "tasks", "US-001.md")
+	if err := os.MkdirAll(filepath.Dir(story), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(story, []byte("---\nid: US-001\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repoImpl.CommitFiles(ctx, repoPath, "Add US-001", []string{story})
+	if err != nil || hash == "" {
+		t.Fatalf("CommitFiles() = %q, %v", hash, err)
+	}
+	head, _ := repo.Head()
+	if head.Hash().String() != hash {
+		t.Errorf("HEAD = %s, want %s", head.Hash(), hash)
+	}
+
+	// Moves commit the new file and the removal of the old one
+	moved := filepath.Join(repoPath, "tasks", "sprint", "US-001.md")
+	if err := os.MkdirAll(filepath.Dir(moved), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(story, moved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repoImpl.CommitFiles(ctx, repoPath, "Move US-001", []string{story, moved}); err != nil {
+		t.Fatalf("CommitFiles() error = %v", err)
+	}
+	wt, _ := repo.Worktree()
+	status, _ := wt.Status()
+	if !status.IsClean() {
+		t.Errorf("worktree must be clean after the move commit:\n%s", status)
+	}
+
+	// Nothing changed: no commit
+	if hash, err := repoImpl.CommitFiles(ctx, repoPath, "No-op", []string{moved}); err != nil || hash != "" {
+		t.Errorf("CommitFiles() without changes = %q, %v, want no commit", hash, err)
+	}
+	if _, err := repoImpl.CommitFiles(ctx, repoPath, "Outside", []string{filepath.Join(repoPath, "..", "x.md")}); err == nil {
+		t.Error("expected an error for paths outside the repository")
+	}
+}
@@ -13,4 +13,6 @@ var (
 	ErrUncommittedChanges = errors.New("uncommitted changes detected")
 	// ErrEmptyRepository indicates the repository has no commits.
 	ErrEmptyRepository = errors.New("empty repository")
+	// ErrBranchCheckedOut indicates the branch is currently checked out and cannot be modified.
+	ErrBranchCheckedOut = errors.New("branch is currently checked out")
 )
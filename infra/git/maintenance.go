@@ -0,0 +1,99 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// LastCommitTime returns the committer timestamp of the local branch tip.
+func (r *Repository) LastCommitTime(ctx context.Context, repoPath, branchName string) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return time.Time{}, ErrNotGitRepository
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err != nil {
+		return time.Time{}, ErrBranchNotFound
+	}
+
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return time.Time{}, ErrEmptyRepository
+		}
+		return time.Time{}, err
+	}
+
+	return commit.Committer.When, nil
+}
+
+// DeleteBranch removes a local branch reference. The checked-out branch is never deleted.
+func (r *Repository) DeleteBranch(ctx context.Context, repoPath, branchName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return ErrNotGitRepository
+	}
+
+	refName := plumbing.NewBranchReferenceName(branchName)
+	if _, err := repo.Reference(refName, true); err != nil {
+		return ErrBranchNotFound
+	}
+	if isCheckedOut(repo, refName) {
+		return ErrBranchCheckedOut
+	}
+
+	return repo.Storer.RemoveReference(refName)
+}
+
+// RenameBranch moves a local branch reference to newName, keeping the tip commit.
+func (r *Repository) RenameBranch(ctx context.Context, repoPath, oldName, newName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return ErrNotGitRepository
+	}
+
+	oldRef := plumbing.NewBranchReferenceName(oldName)
+	newRef := plumbing.NewBranchReferenceName(newName)
+
+	ref, err := repo.Reference(oldRef, true)
+	if err != nil {
+		return ErrBranchNotFound
+	}
+	if isCheckedOut(repo, oldRef) {
+		return ErrBranchCheckedOut
+	}
+	if _, err := repo.Reference(newRef, true); err == nil {
+		return ErrBranchExists
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(newRef, ref.Hash())); err != nil {
+		return err
+	}
+	return repo.Storer.RemoveReference(oldRef)
+}
+
+// isCheckedOut reports whether HEAD currently points at the given branch reference.
+func isCheckedOut(repo *git.Repository, refName plumbing.ReferenceName) bool {
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return false
+	}
+	return head.Type() == plumbing.SymbolicReference && head.Target() == refName
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestRepository_LastCommitTime(t *testing.T) {
+	repo, repoPath := createTempRepo(t)
+	repoImpl := NewRepository()
+	hash := commitFile(t, repo, repoPath, "main")
+
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(hash)
+
+	got, err := repoImpl.LastCommitTime(context.Background(), repoPath, head.Name().Short())
+	if err != nil {
+		t.Fatalf("LastCommitTime() error = %v", err)
+	}
+	if !got.Equal(commit.Committer.When) {
+		t.Errorf("LastCommitTime() = %v, want %v", got, commit.Committer.When)
+	}
+
+	if _, err := repoImpl.LastCommitTime(context.Background(), repoPath, "missing"); !errors.Is(err, ErrBranchNotFound) {
+		t.Errorf("expected ErrBranchNotFound, got %v", err)
+	}
+}
+
+func TestRepository_DeleteBranch(t *testing.T) {
+	repo, repoPath := createTempRepo(t)
+	repoImpl := NewRepository()
+	commitFile(t, repo, repoPath, "main")
+	ctx := context.Background()
+
+	if err := repoImpl.CreateBranch(ctx, repoPath, "feat/US-001"); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if err := repoImpl.DeleteBranch(ctx, repoPath, "feat/US-001"); err != nil {
+		t.Fatalf("DeleteBranch() error = %v", err)
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName("feat/US-001"), true); err == nil {
+		t.Error("branch should be deleted")
+	}
+
+	head, _ := repo.Head()
+	if err := repoImpl.DeleteBranch(ctx, repoPath, head.Name().Short()); !errors.Is(err, ErrBranchCheckedOut) {
+		t.Errorf("expected ErrBranchCheckedOut, got %v", err)
+	}
+}
+
+func TestRepository_RenameBranch(t *testing.T) {
+	repo, repoPath := createTempRepo(t)
+	repoImpl := NewRepository()
+	hash := commitFile(t, repo, repoPath, "main")
+	ctx := context.Background()
+
+	if err := repoImpl.CreateBranch(ctx, repoPath, "feat/US-002"); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if err := repoImpl.RenameBranch(ctx, repoPath, "feat/US-002", "archive/feat/US-002"); err != nil {
+		t.Fatalf("RenameBranch() error = %v", err)
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("archive/feat/US-002"), true)
+	if err != nil {
+		t.Fatalf("renamed branch missing: %v", err)
+	}
+	if ref.Hash() != hash {
+		t.Errorf("renamed branch hash = %s, want %s", ref.Hash(), hash)
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName("feat/US-002"), true); err == nil {
+		t.Error("old branch name should be removed")
+	}
+}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// sprintStatusPrefixes are the folder name prefixes of sprint statuses.
+const sprintStatusPrefixes = "!+@~"
+
+// SprintPeriods implements core.GitSprintHistory.SprintPeriods.
+//
+// It walks the commits reachable from HEAD and records, per sprint, the first
+// and last commit changing a file below the sprint directory and the first
+// commit adding a file below its archived ("~") folder.
+func (h *HistoryAnalyzer) SprintPeriods(ctx context.Context, req core.SprintPeriodRequest) ([]core.SprintPeriod, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpen(req.RepoPath)
+	if err != nil {
+		return nil, ErrNotGitRepository
+	}
+
+	type activity struct {
+		first, last, archived *time.Time
+	}
+	found := make(map[string]*activity, len(req.SprintIDs))
+	for _, id := range req.SprintIDs {
+		found[id] = &activity{}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return sprintPeriods(req.SprintIDs, nil), nil // Empty repository
+		}
+		return nil, err
+	}
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit log: %w", err)
+	}
+	defer iter.Close()
+
+	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		changes, err := commitChanges(ctx, c)
+		if err != nil {
+			return err
+		}
+		when := c.Author.When
+		for _, change := range changes {
+			for _, name := range []string{change.From.Name, change.To.Name} {
+				folder, ok := sprintFolder(name)
+				if !ok {
+					continue
+				}
+				a, ok := found[sprintID(folder)]
+				if !ok {
+					continue
+				}
+				if a.first == nil || when.Before(*a.first) {
+					a.first = &when
+				}
+				if a.last == nil || when.After(*a.last) {
+					a.last = &when
+				}
+				if name == change.To.Name && strings.HasPrefix(folder, "~") && (a.archived == nil || when.Before(*a.archived)) {
+					a.archived = &when
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history: %w", err)
+	}
+
+	periods := make(map[string]core.SprintPeriod, len(found))
+	for id, a := range found {
+		end := a.last
+		if a.archived != nil {
+			end = a.archived
+		}
+		periods[id] = core.SprintPeriod{ID: id, Start: a.first, End: end}
+	}
+	return sprintPeriods(req.SprintIDs, periods), nil
+}
+
+// sprintPeriods orders periods by the requested IDs; missing IDs get empty periods.
+func sprintPeriods(ids []string, periods map[string]core.SprintPeriod) []core.SprintPeriod {
+	out := make([]core.SprintPeriod, 0, len(ids))
+	for _, id := range ids {
+		period, ok := periods[id]
+		if !ok {
+			period = core.SprintPeriod{ID: id}
+		}
+		out = append(out, period)
+	}
+	return out
+}
+
+// commitChanges returns the changes a commit made relative to its first parent
+// (or to an empty tree for root commits).
+func commitChanges(ctx context.Context, c *object.Commit) (object.Changes, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	return object.DiffTreeContext(ctx, parentTree, tree)
+}
+
+// sprintFolder returns the sprint folder name of a file path below a
+// "sprints" directory (e.g., "tasks/sprints/~Sprint-01/US-001.md" yields
+// "~Sprint-01").
+func sprintFolder(path string) (string, bool) {
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts)-1; i++ {
+		if parts[i-1] == "sprints" && parts[i] != "" {
+			return parts[i], true
+		}
+	}
+	return "", false
+}
+
+// sprintID strips the status prefix from a sprint folder name.
+func sprintID(folder string) string {
+	if folder != "" && strings.ContainsRune(sprintStatusPrefixes, rune(folder[0])) {
+		return folder[1:]
+	}
+	return folder
+}
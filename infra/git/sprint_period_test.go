@@ -0,0 +1,64 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/go-git/go-git/v5"
+)
+
+// renameDir renames a directory in the worktree and stages the result; the
+// next commitAt commits the rename.
+func renameDir(t *testing.T, repo *git.Repository, root, from, to string) {
+	t.Helper()
+	if err := os.Rename(filepath.Join(root, from), filepath.Join(root, to)); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := wt.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+}
+
+func TestHistoryAnalyzer_SprintPeriods(t *testing.T) {
+	repo, root := createTempRepo(t)
+	commitAt(t, repo, root, "tasks/sprints/!Sprint-01/US-001.md", 0, "plan sprint 1")
+	commitAt(t, repo, root, "tasks/sprints/!Sprint-02/US-003.md", 1, "plan sprint 2")
+
+	// Activate, work on, then archive Sprint-01 by renaming its folder
+	renameDir(t, repo, root, "tasks/sprints/!Sprint-01", "tasks/sprints/@Sprint-01")
+	commitAt(t, repo, root, "tasks/sprints/@Sprint-01/US-002.md", 2, "start sprint 1")
+	commitAt(t, repo, root, "tasks/sprints/@Sprint-01/US-002.md", 9, "update US-002")
+	renameDir(t, repo, root, "tasks/sprints/@Sprint-01", "tasks/sprints/~Sprint-01")
+	commitAt(t, repo, root, "notes.txt", 14, "close sprint 1")
+	commitAt(t, repo, root, "tasks/sprints/~Sprint-01/US-001.md", 20, "fix archived story")
+
+	analyzer := NewHistoryAnalyzer(nil)
+	periods, err := analyzer.SprintPeriods(context.Background(), core.SprintPeriodRequest{
+		RepoPath:  root,
+		SprintIDs: []string{"Sprint-01", "Sprint-02", "Sprint-09"},
+	})
+	if err != nil {
+		t.Fatalf("SprintPeriods() error = %v", err)
+	}
+	if len(periods) != 3 {
+		t.Fatalf("expected 3 periods, got %d", len(periods))
+	}
+
+	if periods[0].ID != "Sprint-01" {
+		t.Errorf("periods must follow request order, got %s", periods[0].ID)
+	}
+	assertDay(t, "Sprint-01 start", periods[0].Start, 0)
+	assertDay(t, "Sprint-01 end", periods[0].End, 14) // Archive, not the later fix
+	assertDay(t, "Sprint-02 start", periods[1].Start, 1)
+	assertDay(t, "Sprint-02 end", periods[1].End, 1)
+	if periods[2].Start != nil || periods[2].End != nil {
+		t.Errorf("unknown sprint must have no period, got %+v", periods[2])
+	}
+}
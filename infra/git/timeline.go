@@ -0,0 +1,323 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// StoryTimelines implements core.GitTimelineAnalyzer.StoryTimelines.
+//
+// Milestones are reconstructed as follows:
+//   - Created: earliest commit on any ref that adds <ID>.md
+//   - Merged: the first-parent commit of the target branch from which the
+//     branch tip became reachable; deleted branches are found through merge
+//     commit messages naming the branch
+//   - BranchCreated: oldest commit on the branch that the merge brought in
+//     (or that is not yet on the target)
+//   - FirstPush: first entry of the refs/remotes/origin/<branch> reflog
+//
+// A fast-forward merge leaves no branch-only commits, so the branch tip is
+// used as its first commit.
+func (h *HistoryAnalyzer) StoryTimelines(ctx context.Context, req core.TimelineRequest) ([]core.StoryTimeline, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpen(req.RepoPath)
+	if err != nil {
+		return nil, ErrNotGitRepository
+	}
+
+	created, err := storyFileCreations(ctx, repo, req.StoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []*object.Commit
+	introduced := map[plumbing.Hash]*object.Commit{}
+	if target := resolveTargetCommit(repo, req.TargetBranches); target != nil {
+		chain, introduced, err = introductionIndex(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timelines := make([]core.StoryTimeline, 0, len(req.StoryIDs))
+	for _, id := range req.StoryIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		tl := core.StoryTimeline{ID: id, Branch: req.BranchPrefix + id}
+		if t, ok := created[id]; ok {
+			tl.Created = &t
+		}
+
+		tip := branchTip(repo, tl.Branch)
+		var mergedBy *object.Commit
+		if tip != nil {
+			mergedBy = introduced[tip.Hash]
+		} else {
+			tip, mergedBy = findMergeByMessage(repo, chain, tl.Branch)
+		}
+
+		if tip != nil {
+			first, err := firstBranchCommit(ctx, tip, mergedBy, introduced)
+			if err != nil {
+				return nil, err
+			}
+			t := first.Author.When
+			tl.BranchCreated = &t
+		}
+		if mergedBy != nil {
+			t := mergedBy.Committer.When
+			tl.Merged = &t
+		}
+		if t, ok := firstPush(req.RepoPath, tl.Branch); ok {
+			tl.FirstPush = &t
+		}
+
+		timelines = append(timelines, tl)
+	}
+	return timelines, nil
+}
+
+// storyFileCreations walks every commit reachable from any ref and returns the
+// earliest author time at which each story file (<ID>.md) was added. Moving a
+// story between directories adds it again, so the earliest addition wins.
+func storyFileCreations(ctx context.Context, repo *git.Repository, ids []string) (map[string]time.Time, error) {
+	wanted := make(map[string]string, len(ids))
+	for _, id := range ids {
+		wanted[id+".md"] = id
+	}
+	created := make(map[string]time.Time, len(ids))
+	if len(wanted) == 0 {
+		return created, nil
+	}
+
+	iter, err := repo.Log(&git.LogOptions{All: true})
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return created, nil // Empty repository
+		}
+		return nil, fmt.Errorf("failed to get commit log: %w", err)
+	}
+	defer iter.Close()
+
+	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		changes, err := commitChanges(ctx, c)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if change.From.Name != "" {
+				continue // Not an addition
+			}
+			id, ok := wanted[path.Base(change.To.Name)]
+			if !ok {
+				continue
+			}
+			if prev, seen := created[id]; !seen || c.Author.When.Before(prev) {
+				created[id] = c.Author.When
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history: %w", err)
+	}
+	return created, nil
+}
+
+// resolveTargetCommit returns the tip of the first existing target branch,
+// preferring origin/<name> over the local branch. Returns nil if none exists.
+func resolveTargetCommit(repo *git.Repository, targets []string) *object.Commit {
+	for _, name := range targets {
+		for _, refName := range []plumbing.ReferenceName{
+			plumbing.NewRemoteReferenceName("origin", name),
+			plumbing.NewBranchReferenceName(name),
+		} {
+			ref, err := repo.Reference(refName, true)
+			if err != nil {
+				continue
+			}
+			if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+				return commit
+			}
+		}
+	}
+	return nil
+}
+
+// introductionIndex walks the first-parent chain of target and maps every
+// reachable commit to the chain commit that first made it reachable. The
+// chain is returned oldest first.
+func introductionIndex(ctx context.Context, target *object.Commit) ([]*object.Commit, map[plumbing.Hash]*object.Commit, error) {
+	var chain []*object.Commit
+	for c := target; c != nil; {
+		chain = append(chain, c)
+		if c.NumParents() == 0 {
+			break
+		}
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, nil, err
+		}
+		c = parent
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	introduced := make(map[plumbing.Hash]*object.Commit)
+	for _, step := range chain {
+		queue := []*object.Commit{step}
+		for len(queue) > 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			c := queue[0]
+			queue = queue[1:]
+			if _, seen := introduced[c.Hash]; seen {
+				continue
+			}
+			introduced[c.Hash] = step
+
+			parents := c.Parents()
+			for {
+				parent, err := parents.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return nil, nil, err
+				}
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return chain, introduced, nil
+}
+
+// branchTip returns the tip commit of a local branch, falling back to
+// origin/<branch>. Returns nil if neither exists.
+func branchTip(repo *git.Repository, branch string) *object.Commit {
+	for _, refName := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(branch),
+		plumbing.NewRemoteReferenceName("origin", branch),
+	} {
+		ref, err := repo.Reference(refName, true)
+		if err != nil {
+			continue
+		}
+		if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+			return commit
+		}
+	}
+	return nil
+}
+
+// findMergeByMessage locates the latest merge commit on the target chain whose
+// message names the branch, as left behind by `git merge` and pull request
+// merges after the branch was deleted. It returns the merged tip (the second
+// parent) and the merge commit.
+func findMergeByMessage(repo *git.Repository, chain []*object.Commit, branch string) (*object.Commit, *object.Commit) {
+	// The branch name must not be followed by more ID characters, so that
+	// feat/US-1 does not match feat/US-10.
+	pattern := regexp.MustCompile(`(^|[\s'"/:])` + regexp.QuoteMeta(branch) + `($|[^\w-])`)
+	for i := len(chain) - 1; i >= 0; i-- {
+		c := chain[i]
+		if c.NumParents() < 2 || !pattern.MatchString(c.Message) {
+			continue
+		}
+		tip, err := repo.CommitObject(c.ParentHashes[1])
+		if err != nil {
+			continue
+		}
+		return tip, c
+	}
+	return nil, nil
+}
+
+// firstBranchCommit returns the oldest commit that belongs to the branch:
+// following first parents from the tip, the commits brought in by the same
+// merge (mergedBy), or the commits not yet on the target when unmerged.
+func firstBranchCommit(ctx context.Context, tip, mergedBy *object.Commit, introduced map[plumbing.Hash]*object.Commit) (*object.Commit, error) {
+	if mergedBy != nil && mergedBy.Hash == tip.Hash {
+		return tip, nil // Fast-forward merge
+	}
+
+	first := tip
+	for c := tip; ; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		by, onTarget := introduced[c.Hash]
+		if mergedBy == nil && onTarget {
+			break
+		}
+		if mergedBy != nil && (by != mergedBy || c.Hash == mergedBy.Hash) {
+			break
+		}
+		first = c
+		if c.NumParents() == 0 {
+			break
+		}
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		c = parent
+	}
+	return first, nil
+}
+
+// firstPush reads the first entry of the origin remote-tracking reflog for the
+// branch. Reflog lines have the form
+// "<old> <new> <name> <<email>> <unix-seconds> <tz>\t<message>".
+func firstPush(repoPath, branch string) (time.Time, bool) {
+	logPath := filepath.Join(repoPath, ".git", "logs", "refs", "remotes", "origin", filepath.FromSlash(branch))
+	f, err := os.Open(logPath)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return time.Time{}, false
+	}
+	line, _, _ := strings.Cut(scanner.Text(), "\t")
+	idx := strings.LastIndex(line, "> ")
+	if idx < 0 {
+		return time.Time{}, false
+	}
+	fields := strings.Fields(line[idx+2:])
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
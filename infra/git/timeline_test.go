@@ -0,0 +1,168 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var timelineBase = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// commitAt writes path and commits it with author and committer time set to
+// timelineBase plus the given number of days.
+func commitAt(t *testing.T, repo *git.Repository, root, path string, days int, message string, parents ...plumbing.Hash) plumbing.Hash {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	full := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(full, []byte(message), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := wt.Add(path); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	sig := &object.Signature{Name: "Test User", Email: "test@example.com", When: timelineBase.AddDate(0, 0, days)}
+	hash, err := wt.Commit(message, &git.CommitOptions{Author: sig, Committer: sig, Parents: parents})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	return hash
+}
+
+func checkout(t *testing.T, repo *git.Repository, branch string, create bool) {
+	t.Helper()
+	wt, _ := repo.Worktree()
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: create}); err != nil {
+		t.Fatalf("checkout %s: %v", branch, err)
+	}
+}
+
+func assertDay(t *testing.T, name string, got *time.Time, wantDays int) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("%s = nil, want day %d", name, wantDays)
+		return
+	}
+	if want := timelineBase.AddDate(0, 0, wantDays); !got.Equal(want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestHistoryAnalyzer_StoryTimelines(t *testing.T) {
+	repo, root := createTempRepo(t)
+	commitAt(t, repo, root, "tasks/backlog/US-001.md", 0, "add US-001")
+	commitAt(t, repo, root, "tasks/backlog/US-002.md", 1, "add US-002")
+	commitAt(t, repo, root, "tasks/backlog/US-003.md", 1, "add US-003")
+	head, _ := repo.Head()
+	mainBranch := head.Name().Short()
+
+	// US-001: merged with a merge commit, branch kept
+	checkout(t, repo, "feat/US-001", true)
+	commitAt(t, repo, root, "a.txt", 2, "work 1")
+	tip1 := commitAt(t, repo, root, "a.txt", 3, "work 2")
+	checkout(t, repo, mainBranch, false)
+	mainHead, _ := repo.Head()
+	commitAt(t, repo, root, "merge1.txt", 5, "Merge branch 'feat/US-001'", mainHead.Hash(), tip1)
+
+	// US-002: merged through a pull request, branch deleted
+	checkout(t, repo, "feat/US-002", true)
+	tip2 := commitAt(t, repo, root, "b.txt", 6, "work")
+	checkout(t, repo, mainBranch, false)
+	mainHead, _ = repo.Head()
+	commitAt(t, repo, root, "merge2.txt", 8, "Merge pull request #2 from me/feat/US-002", mainHead.Hash(), tip2)
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName("feat/US-002")); err != nil {
+		t.Fatalf("delete branch: %v", err)
+	}
+
+	// US-003: work in progress, pushed once
+	checkout(t, repo, "feat/US-003", true)
+	commitAt(t, repo, root, "c.txt", 9, "work")
+	checkout(t, repo, mainBranch, false)
+	reflog := filepath.Join(root, ".git", "logs", "refs", "remotes", "origin", "feat", "US-003")
+	if err := os.MkdirAll(filepath.Dir(reflog), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	pushed := timelineBase.AddDate(0, 0, 10)
+	line := "0000000000000000000000000000000000000000 1111111111111111111111111111111111111111 Test User <test@example.com> " +
+		strconv.FormatInt(pushed.Unix(), 10) + " +0000\tupdate by push\n"
+	if err := os.WriteFile(reflog, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := NewHistoryAnalyzer(nil)
+	timelines, err := analyzer.StoryTimelines(context.Background(), core.TimelineRequest{
+		RepoPath:       root,
+		StoryIDs:       []string{"US-001", "US-002", "US-003", "US-004"},
+		BranchPrefix:   "feat/",
+		TargetBranches: []string{mainBranch},
+	})
+	if err != nil {
+		t.Fatalf("StoryTimelines() error = %v", err)
+	}
+	if len(timelines) != 4 {
+		t.Fatalf("expected 4 timelines, got %d", len(timelines))
+	}
+
+	us1 := timelines[0]
+	assertDay(t, "US-001 created", us1.Created, 0)
+	assertDay(t, "US-001 branch created", us1.BranchCreated, 2)
+	assertDay(t, "US-001 merged", us1.Merged, 5)
+
+	us2 := timelines[1]
+	assertDay(t, "US-002 created", us2.Created, 1)
+	assertDay(t, "US-002 branch created", us2.BranchCreated, 6)
+	assertDay(t, "US-002 merged", us2.Merged, 8)
+
+	us3 := timelines[2]
+	assertDay(t, "US-003 branch created", us3.BranchCreated, 9)
+	if us3.Merged != nil {
+		t.Errorf("US-003 must not be merged, got %v", us3.Merged)
+	}
+	if us3.FirstPush == nil || !us3.FirstPush.Equal(pushed) {
+		t.Errorf("US-003 first push = %v, want %v", us3.FirstPush, pushed)
+	}
+
+	us4 := timelines[3]
+	if us4.Created != nil || us4.BranchCreated != nil || us4.Merged != nil {
+		t.Errorf("US-004 has no history, got %+v", us4)
+	}
+}
+
+func TestFindMergeByMessage_MatchesWholeBranchName(t *testing.T) {
+	repo, root := createTempRepo(t)
+	base := commitAt(t, repo, root, "base.txt", 0, "base")
+	head, _ := repo.Head()
+	mainBranch := head.Name().Short()
+
+	checkout(t, repo, "feat/US-10", true)
+	tip := commitAt(t, repo, root, "a.txt", 1, "work")
+	checkout(t, repo, mainBranch, false)
+	commitAt(t, repo, root, "m.txt", 2, "Merge branch 'feat/US-10'", base, tip)
+
+	target, _ := repo.Head()
+	commit, _ := repo.CommitObject(target.Hash())
+	chain, _, err := introductionIndex(context.Background(), commit)
+	if err != nil {
+		t.Fatalf("introductionIndex() error = %v", err)
+	}
+
+	if got, _ := findMergeByMessage(repo, chain, "feat/US-1"); got != nil {
+		t.Error("feat/US-1 must not match a merge of feat/US-10")
+	}
+	if got, _ := findMergeByMessage(repo, chain, "feat/US-10"); got == nil || got.Hash != tip {
+		t.Errorf("merge of feat/US-10 not found, got %v", got)
+	}
+}
@@ -0,0 +1,224 @@
+// Package github implements core.IssueTracker with the GitHub REST API.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// DefaultBaseURL is the API root of github.com. GitHub Enterprise Server
+// serves the API at https://<host>/api/v3.
+const DefaultBaseURL = "https://api.github.com"
+
+// TokenEnv is the environment variable holding the API token.
+const TokenEnv = "GITHUB_TOKEN"
+
+// ErrUnauthorized indicates the token is missing, invalid or lacks access to
+// the repository.
+var ErrUnauthorized = errors.New("github: unauthorized")
+
+// nextLinkPattern extracts the next page URL from a Link header.
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Client is a GitHub Issues client for one repository.
+type Client struct {
+	baseURL    string
+	repository string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client for repository ("owner/name"). An empty baseURL
+// uses DefaultBaseURL; an empty token sends unauthenticated requests.
+func NewClient(baseURL, repository, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		repository: repository,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name implements core.IssueTracker.Name.
+func (c *Client) Name() string {
+	return "github"
+}
+
+// apiIssue is an issue as returned by the API.
+type apiIssue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      *string   `json:"body"`
+	State     string    `json:"state"`
+	HTMLURL   string    `json:"html_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignees []struct {
+		Login string `json:"login"`
+	} `json:"assignees"`
+	// PullRequest is set for pull requests, which the issues API also lists.
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+// apiIssueRequest is the body of issue create and update requests.
+type apiIssueRequest struct {
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Labels    []string `json:"labels"`
+	Assignees []string `json:"assignees"`
+	State     string   `json:"state,omitempty"`
+}
+
+// ListIssues implements core.IssueTracker.ListIssues.
+func (c *Client) ListIssues(ctx context.Context) ([]core.Issue, error) {
+	var issues []core.Issue
+	url := fmt.Sprintf("%s/repos/%s/issues?state=all&per_page=100", c.baseURL, c.repository)
+	for url != "" {
+		var page []apiIssue
+		header, err := c.do(ctx, http.MethodGet, url, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range page {
+			if len(issue.PullRequest) > 0 {
+				continue
+			}
+			issues = append(issues, issue.toCore())
+		}
+		url = ""
+		if m := nextLinkPattern.FindStringSubmatch(header.Get("Link")); m != nil {
+			url = m[1]
+		}
+	}
+	return issues, nil
+}
+
+// CreateIssue implements core.IssueTracker.CreateIssue.
+func (c *Client) CreateIssue(ctx context.Context, issue core.Issue) (*core.Issue, error) {
+	var created apiIssue
+	url := fmt.Sprintf("%s/repos/%s/issues", c.baseURL, c.repository)
+	if _, err := c.do(ctx, http.MethodPost, url, newIssueRequest(issue, false), &created); err != nil {
+		return nil, err
+	}
+	// Issues are always created open
+	if issue.Closed {
+		issue.Number = created.Number
+		return c.UpdateIssue(ctx, issue)
+	}
+	out := created.toCore()
+	return &out, nil
+}
+
+// UpdateIssue implements core.IssueTracker.UpdateIssue.
+func (c *Client) UpdateIssue(ctx context.Context, issue core.Issue) (*core.Issue, error) {
+	var updated apiIssue
+	url := fmt.Sprintf("%s/repos/%s/issues/%d", c.baseURL, c.repository, issue.Number)
+	if _, err := c.do(ctx, http.MethodPatch, url, newIssueRequest(issue, true), &updated); err != nil {
+		return nil, err
+	}
+	out := updated.toCore()
+	return &out, nil
+}
+
+func newIssueRequest(issue core.Issue, withState bool) apiIssueRequest {
+	req := apiIssueRequest{
+		Title:     issue.Title,
+		Body:      issue.Body,
+		Labels:    append([]string{}, issue.Labels...),
+		Assignees: []string{},
+	}
+	if issue.Assignee != nil {
+		req.Assignees = append(req.Assignees, *issue.Assignee)
+	}
+	if withState {
+		req.State = "open"
+		if issue.Closed {
+			req.State = "closed"
+		}
+	}
+	return req
+}
+
+func (i apiIssue) toCore() core.Issue {
+	out := core.Issue{
+		Number:    i.Number,
+		Title:     i.Title,
+		Closed:    i.State == "closed",
+		UpdatedAt: i.UpdatedAt,
+		URL:       i.HTMLURL,
+	}
+	if i.Body != nil {
+		out.Body = *i.Body
+	}
+	for _, label := range i.Labels {
+		out.Labels = append(out.Labels, label.Name)
+	}
+	if len(i.Assignees) > 0 {
+		login := i.Assignees[0].Login
+		out.Assignee = &login
+	}
+	return out
+}
+
+// do sends a JSON request and decodes the JSON response into out.
+func (c *Client) do(ctx context.Context, method, url string, body, out interface{}) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: %s %s: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("%w: %s (set %s to a token with access to %s)", ErrUnauthorized, apiErr.Message, TokenEnv, c.repository)
+		}
+		return nil, fmt.Errorf("github: %s %s: %s", method, url, apiErr.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("github: invalid response to %s %s: %w", method, url, err)
+	}
+	return resp.Header, nil
+}
@@ -0,0 +1,64 @@
+package core
+
+import "time"
+
+// IdealPoint is the guideline value of a burndown chart on one working day.
+type IdealPoint struct {
+	Date time.Time
+	// Remaining is the points that should remain at the end of the day.
+	Remaining float64
+}
+
+// ScopeChange is a day on which the sprint's total points increased.
+type ScopeChange struct {
+	Date time.Time
+	// Added is the increase over the previous day.
+	Added int
+}
+
+// BurndownChart is a burndown with its ideal guideline and the projection of
+// the actual trend.
+type BurndownChart struct {
+	// Start and End delimit the sprint; End is the first day after it.
+	Start time.Time
+	End   time.Time
+	// Scheduled reports whether Start and End come from the sprint schedule
+	// rather than being inferred from Git history.
+	Scheduled bool
+	// Points are the actual values, one per working day.
+	Points []BurndownDataPoint
+	// Ideal declines linearly from the points remaining at Start to zero at
+	// End, one value per working day.
+	Ideal []IdealPoint
+	// Slope is the least-squares trend of the remaining points, in points per
+	// working day (negative while burning down).
+	Slope float64
+	// Intercept is the trend's value on the first working day.
+	Intercept float64
+	// ProjectedCompletion is the working day the trend reaches zero, or the
+	// day the work was finished. Nil if the trend never reaches zero.
+	ProjectedCompletion *time.Time
+	// AtRisk reports that the projection misses End.
+	AtRisk bool
+	// ScopeChanges lists the days the sprint's total points grew.
+	ScopeChanges []ScopeChange
+}
+
+// Projected returns the trend's remaining points on the given working day of
+// the sprint (0 is Start), floored at zero.
+func (c BurndownChart) Projected(day int) float64 {
+	v := c.Intercept + c.Slope*float64(day)
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// Days returns the number of working days the chart spans: the sprint's
+// working days, or more when actual values run past End.
+func (c BurndownChart) Days() int {
+	if len(c.Points) > len(c.Ideal) {
+		return len(c.Points)
+	}
+	return len(c.Ideal)
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar decides which days are working days. The zero value treats every
+// day as a working day in the local time zone.
+type Calendar struct {
+	// Location is the time zone days are evaluated in. Nil means time.Local.
+	Location *time.Location
+	// WorkingDays lists the working weekdays. Empty means every weekday.
+	WorkingDays []time.Weekday
+	// Holidays holds non-working dates (YYYY-MM-DD) mapped to their name.
+	Holidays map[string]string
+}
+
+// DefaultCalendar returns a Monday to Friday calendar in the local time zone
+// without holidays.
+func DefaultCalendar() Calendar {
+	return Calendar{
+		WorkingDays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	}
+}
+
+// ParseWeekday parses a weekday name such as "mon" or "Monday".
+func ParseWeekday(name string) (time.Weekday, error) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if lower == full || (len(lower) == 3 && lower == full[:3]) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q (use mon, tue, wed, thu, fri, sat, sun)", name)
+}
+
+// Zone returns the calendar's time zone.
+func (c Calendar) Zone() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+// StartOfDay returns midnight of t's day in the calendar's time zone.
+func (c Calendar) StartOfDay(t time.Time) time.Time {
+	t = t.In(c.Zone())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// IsWorkingDay reports whether t falls on a working weekday that is not a
+// holiday, in the calendar's time zone.
+func (c Calendar) IsWorkingDay(t time.Time) bool {
+	t = t.In(c.Zone())
+	if _, ok := c.Holidays[t.Format("2006-01-02")]; ok {
+		return false
+	}
+	if len(c.WorkingDays) == 0 {
+		return true
+	}
+	for _, d := range c.WorkingDays {
+		if d == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// AddWorkingDays returns the start of the first working day after n working
+// days counted from start's day (included when it is a working day). Two
+// weeks of Monday to Friday starting on a Monday end on the Monday after.
+func (c Calendar) AddWorkingDays(start time.Time, n int) time.Time {
+	day := c.StartOfDay(start)
+	// Guard against calendars without any working day
+	for limit := 0; limit < 366*10; limit++ {
+		if c.IsWorkingDay(day) {
+			if n == 0 {
+				return day
+			}
+			n--
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// WorkingDaysBetween returns the number of working days from start's day up
+// to, but excluding, end's day. It is negative when end is before start.
+func (c Calendar) WorkingDaysBetween(start, end time.Time) int {
+	from, to, sign := c.StartOfDay(start), c.StartOfDay(end), 1
+	if to.Before(from) {
+		from, to, sign = to, from, -1
+	}
+	n := 0
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if c.IsWorkingDay(day) {
+			n++
+		}
+	}
+	return sign * n
+}
+
+// WorkingDuration returns the part of [start, end) that falls on working
+// days. A span from Friday 17:00 to Monday 09:00 counts 16 hours with a
+// Monday to Friday calendar.
+func (c Calendar) WorkingDuration(start, end time.Time) time.Duration {
+	var total time.Duration
+	for current := start; current.Before(end); {
+		next := c.StartOfDay(current).AddDate(0, 0, 1)
+		if next.After(end) {
+			next = end
+		}
+		if c.IsWorkingDay(current) {
+			total += next.Sub(current)
+		}
+		current = next
+	}
+	return total
+}
+
+// EndDate returns the sprint end date for a duration string: "2w" and "14d"
+// add calendar days, "10wd" adds working days.
+func (c Calendar) EndDate(start time.Time, duration string) (time.Time, error) {
+	days, workingDays, err := parseSprintDuration(duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if workingDays {
+		day := c.AddWorkingDays(start, days)
+		// Keep the start's time of day, as calendar-day durations do
+		s := start.In(c.Zone())
+		return time.Date(day.Year(), day.Month(), day.Day(), s.Hour(), s.Minute(), s.Second(), s.Nanosecond(), day.Location()), nil
+	}
+	return start.AddDate(0, 0, days), nil
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrNoCapacity indicates a sprint has no capacity metadata.
+var ErrNoCapacity = errors.New("sprint capacity not defined")
+
+// SprintCapacity is the team capacity of a sprint, stored in the sprint's
+// .gitta/capacity.yaml file.
+type SprintCapacity struct {
+	// Days is the number of working days in the sprint.
+	Days float64 `yaml:"days"`
+	// FocusFactor is the share of available time spent on sprint stories
+	// (0 < f <= 1). Default: 1.
+	FocusFactor float64 `yaml:"focus_factor,omitempty"`
+	// PointsPerDay converts one focused person-day into points. Default: 1.
+	PointsPerDay float64 `yaml:"points_per_day,omitempty"`
+	// PointsField is the numeric story field holding points. Default: "points".
+	PointsField string `yaml:"points_field,omitempty"`
+	// Holidays lists team-wide non-working days (YYYY-MM-DD); each one reduces
+	// every member's available days by one.
+	Holidays []string `yaml:"holidays,omitempty"`
+	// Members lists the team members and their availability.
+	Members []MemberCapacity `yaml:"members"`
+}
+
+// MemberCapacity is one team member's availability in a sprint.
+type MemberCapacity struct {
+	// Name matches the assignee field of stories.
+	Name string `yaml:"name"`
+	// Days overrides the sprint's working days for this member (e.g., part time
+	// or vacation).
+	Days *float64 `yaml:"days,omitempty"`
+	// FocusFactor overrides the sprint's focus factor for this member.
+	FocusFactor *float64 `yaml:"focus_factor,omitempty"`
+}
+
+// Validate checks the capacity values.
+func (c SprintCapacity) Validate() error {
+	if c.Days < 0 {
+		return fmt.Errorf("days must be zero or positive, got %g", c.Days)
+	}
+	if err := validateFocusFactor(c.FocusFactor, true); err != nil {
+		return err
+	}
+	if c.PointsPerDay < 0 {
+		return fmt.Errorf("points_per_day must be positive, got %g", c.PointsPerDay)
+	}
+	for _, day := range c.Holidays {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return fmt.Errorf("invalid holiday %q (use YYYY-MM-DD)", day)
+		}
+	}
+	seen := make(map[string]bool, len(c.Members))
+	for _, m := range c.Members {
+		if m.Name == "" {
+			return errors.New("member name cannot be empty")
+		}
+		if seen[m.Name] {
+			return fmt.Errorf("duplicate member %q", m.Name)
+		}
+		seen[m.Name] = true
+		if m.Days != nil && *m.Days < 0 {
+			return fmt.Errorf("member %s: days must be zero or positive, got %g", m.Name, *m.Days)
+		}
+		if m.FocusFactor != nil {
+			if err := validateFocusFactor(*m.FocusFactor, false); err != nil {
+				return fmt.Errorf("member %s: %w", m.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateFocusFactor(f float64, zeroIsDefault bool) error {
+	if (f == 0 && zeroIsDefault) || (f > 0 && f <= 1) {
+		return nil
+	}
+	return fmt.Errorf("focus_factor must be greater than 0 and at most 1, got %g", f)
+}
+
+// AvailableDays returns the member's working days minus holidays (at least 0).
+func (c SprintCapacity) AvailableDays(m MemberCapacity) float64 {
+	days := c.Days
+	if m.Days != nil {
+		days = *m.Days
+	}
+	days -= float64(len(c.Holidays))
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// MemberPoints returns the member's capacity in points, rounded to
+// hundredths: available days × focus factor × points per day.
+func (c SprintCapacity) MemberPoints(m MemberCapacity) float64 {
+	focus := c.FocusFactor
+	if focus == 0 {
+		focus = 1
+	}
+	if m.FocusFactor != nil {
+		focus = *m.FocusFactor
+	}
+	perDay := c.PointsPerDay
+	if perDay == 0 {
+		perDay = 1
+	}
+	return roundPoints(c.AvailableDays(m) * focus * perDay)
+}
+
+// roundPoints rounds to hundredths to hide floating-point noise.
+func roundPoints(points float64) float64 {
+	return math.Round(points*100) / 100
+}
+
+// PointsFieldOrDefault returns the points field name, defaulting to "points".
+func (c SprintCapacity) PointsFieldOrDefault() string {
+	if c.PointsField == "" {
+		return "points"
+	}
+	return c.PointsField
+}
+
+// AssigneeLoad compares one assignee's committed points with their capacity.
+type AssigneeLoad struct {
+	// Assignee is the story assignee.
+	Assignee string
+	// Member reports whether the assignee is listed in the sprint capacity.
+	Member bool
+	// Capacity is the assignee's capacity in points (0 if not a member).
+	Capacity float64
+	// Committed is the sum of points of the assignee's stories in the sprint.
+	Committed float64
+	// Stories is the number of the assignee's stories in the sprint.
+	Stories int
+}
+
+// Overcommitted reports whether committed points exceed capacity.
+func (l AssigneeLoad) Overcommitted() bool {
+	return l.Committed > l.Capacity
+}
+
+// CapacityReport compares the work committed to a sprint with its capacity.
+type CapacityReport struct {
+	// Sprint is the sprint folder name.
+	Sprint string
+	// Capacity is the sprint's capacity metadata.
+	Capacity SprintCapacity
+	// Loads holds one entry per member and per assignee with stories, in
+	// member order followed by non-member assignees in name order.
+	Loads []AssigneeLoad
+	// UnassignedPoints and UnassignedStories cover stories without assignee.
+	UnassignedPoints  float64
+	UnassignedStories int
+	// TeamCapacity is the sum of the members' capacity.
+	TeamCapacity float64
+	// TeamCommitted is the sum of points of all stories in the sprint.
+	TeamCommitted float64
+}
+
+// Load returns the entry for assignee.
+func (r CapacityReport) Load(assignee string) (AssigneeLoad, bool) {
+	for _, l := range r.Loads {
+		if l.Assignee == assignee {
+			return l, true
+		}
+	}
+	return AssigneeLoad{}, false
+}
+
+// Overcommitted reports whether the team or any assignee is over capacity.
+func (r CapacityReport) Overcommitted() bool {
+	if r.TeamCommitted > r.TeamCapacity {
+		return true
+	}
+	for _, l := range r.Loads {
+		if l.Overcommitted() {
+			return true
+		}
+	}
+	return false
+}
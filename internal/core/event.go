@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// EventType names a domain event.
+type EventType string
+
+const (
+	// EventStoryCreated fires when a story file is created.
+	EventStoryCreated EventType = "story.created"
+	// EventStoryStarted fires when work on a story starts on its branch.
+	EventStoryStarted EventType = "story.started"
+	// EventStatusChanged fires when a story's explicit status changes.
+	EventStatusChanged EventType = "status.changed"
+	// EventStoryMoved fires when a story file moves to another directory.
+	EventStoryMoved EventType = "story.moved"
+	// EventSprintActivated fires when a sprint becomes the active sprint.
+	EventSprintActivated EventType = "sprint.activated"
+	// EventSprintClosed fires when the current sprint is closed.
+	EventSprintClosed EventType = "sprint.closed"
+	// EventRollover fires when unfinished stories roll over to another sprint.
+	EventRollover EventType = "rollover"
+	// EventDoctorFixed fires when doctor repairs a sprint folder or a branch.
+	EventDoctorFixed EventType = "doctor.fixed"
+	// EventOperationUndone fires when gitta undo reverts a command's changes.
+	EventOperationUndone EventType = "operation.undone"
+)
+
+// EventTypes lists every domain event.
+var EventTypes = []EventType{
+	EventStoryCreated, EventStoryStarted, EventStatusChanged, EventStoryMoved,
+	EventSprintActivated, EventSprintClosed, EventRollover, EventDoctorFixed,
+	EventOperationUndone,
+}
+
+// ErrEventVetoed indicates an operation was cancelled by a Before subscriber.
+var ErrEventVetoed = errors.New("operation vetoed")
+
+// Event is a domain event. Data is its payload: plain values, maps and
+// slices that encode as JSON. Once an operation is done, Data["changes"]
+// holds what it changed as {"field": {"before": v, "after": v}}.
+type Event struct {
+	Type EventType
+	Data map[string]interface{}
+}
+
+// EventBus delivers domain events to subscribers such as hooks.
+type EventBus interface {
+	// Before is called ahead of an operation. An error wrapping
+	// ErrEventVetoed cancels the operation and is returned by it.
+	Before(ctx context.Context, event Event) error
+	// After is called once the operation is done. Subscriber failures are
+	// reported by the bus; they cannot undo the operation.
+	After(ctx context.Context, event Event)
+}
+
+// NopEventBus is an EventBus without subscribers.
+type NopEventBus struct{}
+
+// Before implements EventBus.Before.
+func (NopEventBus) Before(context.Context, Event) error { return nil }
+
+// After implements EventBus.After.
+func (NopEventBus) After(context.Context, Event) {}
+
+// EventBuses is an EventBus delivering events to several buses in order.
+type EventBuses []EventBus
+
+// Before implements EventBus.Before: buses are called until one vetoes.
+func (buses EventBuses) Before(ctx context.Context, event Event) error {
+	for _, bus := range buses {
+		if err := bus.Before(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// After implements EventBus.After.
+func (buses EventBuses) After(ctx context.Context, event Event) {
+	for _, bus := range buses {
+		bus.After(ctx, event)
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+// FieldType identifies the value type of a custom story field.
+type FieldType string
+
+const (
+	FieldTypeString FieldType = "string" // Free-form text
+	FieldTypeInt    FieldType = "int"    // Whole number, optionally bounded by Min/Max
+	FieldTypeFloat  FieldType = "float"  // Decimal number, optionally bounded by Min/Max
+	FieldTypeBool   FieldType = "bool"   // true or false
+	FieldTypeDate   FieldType = "date"   // Calendar date (YYYY-MM-DD) or RFC 3339 timestamp
+	FieldTypeEnum   FieldType = "enum"   // One of Values
+	FieldTypeList   FieldType = "list"   // List of strings, restricted to Values when set
+)
+
+// FieldDefinition declares a custom story frontmatter field configured per
+// repository. Values of custom fields are stored in Story.Extra under Name.
+type FieldDefinition struct {
+	// Name is the frontmatter key (e.g., "component").
+	Name string
+	// Type is the value type used for validation, parsing, and sorting.
+	Type FieldType
+	// Values lists the allowed values for enum fields (and list items, if set).
+	Values []string
+	// Min and Max bound numeric fields (nil when unbounded).
+	Min *float64
+	Max *float64
+	// Required reports whether every story must set the field.
+	Required bool
+	// Default is applied to newly created stories when no value is given (nil if none).
+	Default interface{}
+	// Description is shown in documentation and schemas.
+	Description string
+}
+
+// FieldValue returns the value of a custom field from the story frontmatter.
+func (s *Story) FieldValue(name string) (interface{}, bool) {
+	if s == nil || s.Extra == nil {
+		return nil, false
+	}
+	v, ok := s.Extra[name]
+	return v, ok
+}
+
+// SetFieldValue stores a custom field value in the story frontmatter.
+func (s *Story) SetFieldValue(name string, value interface{}) {
+	if s.Extra == nil {
+		s.Extra = make(map[string]interface{})
+	}
+	s.Extra[name] = value
+}
@@ -0,0 +1,58 @@
+package core
+
+import "time"
+
+// DurationStats summarizes a distribution of durations.
+type DurationStats struct {
+	// Count is the number of samples.
+	Count int
+	// Mean is the arithmetic mean.
+	Mean time.Duration
+	// P50, P85 and P95 are nearest-rank percentiles.
+	P50 time.Duration
+	P85 time.Duration
+	P95 time.Duration
+	// Max is the largest sample.
+	Max time.Duration
+}
+
+// StoryFlow holds the flow metrics of a single story, computed from its
+// Git timeline. Nil durations mean the milestones needed were not observed.
+type StoryFlow struct {
+	// ID is the story ID.
+	ID string
+	// Title is the story title.
+	Title string
+	// Timeline holds the Git milestones the metrics are computed from.
+	Timeline StoryTimeline
+	// LeadTime runs from story file creation to merge.
+	LeadTime *time.Duration
+	// CycleTime runs from the first branch commit to merge.
+	CycleTime *time.Duration
+	// InStatus is the time spent in each workflow state between milestones.
+	InStatus map[Status]time.Duration
+}
+
+// StatusDurationStats is the time-in-status distribution of one workflow state.
+type StatusDurationStats struct {
+	Status Status
+	Stats  DurationStats
+}
+
+// FlowReport aggregates lead time, cycle time and time-in-status over the
+// stories completed (merged) in a period.
+type FlowReport struct {
+	// Since is the start of the period; zero means all history.
+	Since time.Time
+	// Stories are the completed stories, ordered by merge time.
+	Stories []StoryFlow
+	// InProgress counts stories with a branch that is not merged yet.
+	InProgress int
+	// LeadTime is the lead time distribution.
+	LeadTime DurationStats
+	// CycleTime is the cycle time distribution.
+	CycleTime DurationStats
+	// TimeInStatus lists distributions in workflow order for the states that
+	// Git milestones delimit: initial, branch-derived and push-derived.
+	TimeInStatus []StatusDurationStats
+}
@@ -0,0 +1,65 @@
+package core
+
+import "time"
+
+// SprintVelocity is the work completed in one sprint.
+type SprintVelocity struct {
+	// Name is the sprint folder name (e.g., "~Sprint-03_Checkout").
+	Name string
+	// Start and End delimit the sprint's activity in Git (nil if unknown).
+	Start *time.Time
+	End   *time.Time
+	// Tasks is the number of stories in the sprint directory.
+	Tasks int
+	// CompletedTasks is the number of those stories in a done-category state.
+	CompletedTasks int
+	// Points is the sum of the stories' points field.
+	Points float64
+	// CompletedPoints is the sum of the completed stories' points field.
+	CompletedPoints float64
+}
+
+// ForecastConfidences are the confidence levels reported by forecasts, in percent.
+var ForecastConfidences = []int{50, 85, 95}
+
+// CompletionOutcome is the number of sprints needed to finish the items with
+// the given confidence.
+type CompletionOutcome struct {
+	Confidence int
+	Sprints    int
+	// Date is the estimated completion date (now + Sprints × sprint length).
+	Date time.Time
+}
+
+// CompletionForecast answers "when will these items be done".
+type CompletionForecast struct {
+	// Items is the number of items to complete.
+	Items int
+	// Throughput holds the historical completed tasks per sprint sampled by the simulation.
+	Throughput []int
+	// Trials is the number of simulation runs.
+	Trials int
+	// SprintLength is the sprint length used to turn sprints into dates.
+	SprintLength time.Duration
+	// Outcomes lists one result per confidence level.
+	Outcomes []CompletionOutcome
+}
+
+// CapacityOutcome is the number of items that will be completed with the given
+// confidence.
+type CapacityOutcome struct {
+	Confidence int
+	Items      int
+}
+
+// CapacityForecast answers "how many items fit in the next sprints".
+type CapacityForecast struct {
+	// Sprints is the number of sprints simulated.
+	Sprints int
+	// Throughput holds the historical completed tasks per sprint sampled by the simulation.
+	Throughput []int
+	// Trials is the number of simulation runs.
+	Trials int
+	// Outcomes lists one result per confidence level.
+	Outcomes []CapacityOutcome
+}
@@ -52,6 +52,33 @@ type GitRepository interface {
 	CheckoutBranch(ctx context.Context, repoPath, branchName string, force bool) error
 }
 
+// GitBranchMaintainer defines branch inspection and cleanup operations used by
+// diagnostics such as `gitta doctor`. It is kept separate from GitRepository so
+// read-only consumers do not need to implement mutating operations.
+type GitBranchMaintainer interface {
+	// LastCommitTime returns the committer timestamp of the branch tip commit.
+	// Returns an error if the branch does not exist locally.
+	LastCommitTime(ctx context.Context, repoPath, branchName string) (time.Time, error)
+
+	// DeleteBranch removes a local branch reference. Implementations must refuse
+	// to delete the currently checked-out branch.
+	DeleteBranch(ctx context.Context, repoPath, branchName string) error
+
+	// RenameBranch moves a local branch reference to a new name, keeping its commits.
+	// Returns an error if the new name already exists or the branch is checked out.
+	RenameBranch(ctx context.Context, repoPath, oldName, newName string) error
+}
+
+// GitCommitter records file changes as commits. It is kept separate from
+// GitRepository so read-only consumers do not need to implement it.
+type GitCommitter interface {
+	// CommitFiles stages the given paths (absolute or relative to repoPath) and
+	// commits them with the author from the Git configuration. Deleted paths
+	// are removed from the index. Returns the commit hash, or an empty hash
+	// without error when the paths have no changes.
+	CommitFiles(ctx context.Context, repoPath, message string, paths []string) (string, error)
+}
+
 var (
 	// ErrInvalidCommit indicates the commit hash is invalid or doesn't exist.
 	ErrInvalidCommit = errors.New("invalid commit hash")
@@ -97,3 +124,69 @@ type GitHistoryAnalyzer interface {
 	// ReconstructFileState reconstructs the file tree state at a specific commit.
 	ReconstructFileState(ctx context.Context, repoPath string, commitHash string, dirPath string) (map[string]*Story, error)
 }
+
+// StoryTimeline records when a story reached each Git milestone. A nil time
+// means the milestone was not observed in the repository.
+type StoryTimeline struct {
+	// ID is the story ID.
+	ID string
+	// Branch is the story branch name (branch prefix + ID).
+	Branch string
+	// Created is the author time of the first commit that added the story file.
+	Created *time.Time
+	// BranchCreated is the author time of the first commit on the story branch.
+	BranchCreated *time.Time
+	// FirstPush is when the branch first appeared on the origin remote, read from
+	// the remote-tracking reflog. Clones that never fetched the branch lack it.
+	FirstPush *time.Time
+	// Merged is the committer time of the target branch commit that brought the
+	// story branch in.
+	Merged *time.Time
+}
+
+// TimelineRequest contains parameters for reconstructing story timelines.
+type TimelineRequest struct {
+	// RepoPath is the filesystem path to the Git repository root.
+	RepoPath string
+	// StoryIDs lists the stories to reconstruct. Story files are matched by
+	// their base name (<ID>.md) anywhere in the tree.
+	StoryIDs []string
+	// BranchPrefix is prepended to story IDs to form branch names (e.g., "feat/").
+	BranchPrefix string
+	// TargetBranches are the merge targets, tried in order on origin first and
+	// then locally (e.g., ["main", "master"]). The first existing one is used.
+	TargetBranches []string
+}
+
+// GitTimelineAnalyzer reconstructs per-story timelines by walking Git history.
+type GitTimelineAnalyzer interface {
+	// StoryTimelines returns one timeline per requested story, in request order.
+	StoryTimelines(ctx context.Context, req TimelineRequest) ([]StoryTimeline, error)
+}
+
+// SprintPeriod is the span of Git activity in a sprint directory.
+type SprintPeriod struct {
+	// ID is the sprint folder name without its status prefix.
+	ID string
+	// Start is the time of the first commit touching the sprint directory.
+	Start *time.Time
+	// End is the time of the commit that archived the sprint (renamed it with
+	// the "~" prefix), or of the last commit touching it if never archived.
+	End *time.Time
+}
+
+// SprintPeriodRequest contains parameters for reading sprint periods.
+type SprintPeriodRequest struct {
+	// RepoPath is the filesystem path to the Git repository root.
+	RepoPath string
+	// SprintIDs lists sprint folder names without status prefix. A sprint is
+	// matched under any "sprints" directory whatever its prefix, so renames
+	// between statuses and layouts are followed.
+	SprintIDs []string
+}
+
+// GitSprintHistory reads sprint activity from Git history.
+type GitSprintHistory interface {
+	// SprintPeriods returns one period per requested sprint, in request order.
+	SprintPeriods(ctx context.Context, req SprintPeriodRequest) ([]SprintPeriod, error)
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Issue is an issue in an external tracker such as GitHub Issues.
+type Issue struct {
+	// Number identifies the issue within its project.
+	Number int
+	Title  string
+	Body   string
+	Labels []string
+	// Assignee is the first assignee (nil if unassigned).
+	Assignee *string
+	Closed   bool
+	// UpdatedAt is the tracker's last modification time of the issue.
+	UpdatedAt time.Time
+	// URL is the web page of the issue.
+	URL string
+}
+
+// IssueTracker reads and writes the issues of one project in an external
+// tracker. Implementations live in infra/ (e.g., infra/github).
+type IssueTracker interface {
+	// Name is the tracker's key in a story's external frontmatter map
+	// (e.g., "github").
+	Name() string
+	// ListIssues returns every issue of the project, open and closed.
+	ListIssues(ctx context.Context) ([]Issue, error)
+	// CreateIssue creates an issue and returns it as stored by the tracker.
+	CreateIssue(ctx context.Context, issue Issue) (*Issue, error)
+	// UpdateIssue replaces the title, body, labels, assignee and state of
+	// issue.Number and returns the issue as stored by the tracker.
+	UpdateIssue(ctx context.Context, issue Issue) (*Issue, error)
+}
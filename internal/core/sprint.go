@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,6 +14,8 @@ var (
 	ErrSprintExists = errors.New("sprint already exists")
 	// ErrSprintNotFound indicates the requested sprint could not be found.
 	ErrSprintNotFound = errors.New("sprint not found")
+	// ErrNoSchedule indicates a sprint has no recorded start and end dates.
+	ErrNoSchedule = errors.New("sprint schedule not recorded")
 )
 
 // Sprint represents a time-bounded work period with associated directory containing task files.
@@ -32,6 +36,17 @@ type Sprint struct {
 	UpdatedAt time.Time
 }
 
+// SprintSchedule holds a sprint's planned dates, stored in the sprint's
+// .gitta/schedule.yaml file when the sprint is started.
+type SprintSchedule struct {
+	// Start is the sprint start date.
+	Start time.Time `yaml:"start"`
+	// End is the sprint end date: the first day after the sprint.
+	End time.Time `yaml:"end"`
+	// Duration is the duration the end date was calculated from (e.g., "2w").
+	Duration string `yaml:"duration,omitempty"`
+}
+
 // BurndownDataPoint represents a snapshot of remaining work at a specific point in time,
 // reconstructed from Git history.
 type BurndownDataPoint struct {
@@ -45,6 +60,41 @@ type BurndownDataPoint struct {
 	TotalPoints *int
 	// TotalTasks is the total tasks at sprint start (optional).
 	TotalTasks *int
+	// ScopePoints is the total points in the sprint on that day; it grows
+	// when work is added mid-sprint.
+	ScopePoints int
+}
+
+// BurnupDataPoint represents sprint scope and completed work on a single day,
+// reconstructed from Git history. A rising scope line shows scope creep.
+type BurnupDataPoint struct {
+	// Date is the date of the snapshot (time component ignored).
+	Date time.Time `json:"date"`
+	// ScopeTasks is the number of tasks in the sprint on that day.
+	ScopeTasks int `json:"scope_tasks"`
+	// CompletedTasks is the number of tasks in a done-category state.
+	CompletedTasks int `json:"completed_tasks"`
+	// ScopePoints is the total points of the tasks in the sprint on that day.
+	ScopePoints int `json:"scope_points"`
+	// CompletedPoints is the total points of the tasks in a done-category state.
+	CompletedPoints int `json:"completed_points"`
+}
+
+// FlowDataPoint holds the number of tasks in each status on a single day.
+type FlowDataPoint struct {
+	// Date is the date of the snapshot (time component ignored).
+	Date time.Time `json:"date"`
+	// Counts maps each status to its task count (statuses without tasks are 0).
+	Counts map[Status]int `json:"counts"`
+}
+
+// CumulativeFlow is the data for a cumulative flow diagram.
+type CumulativeFlow struct {
+	// States lists the statuses in workflow order; statuses found in history
+	// but not in the workflow follow in name order.
+	States []Status `json:"states"`
+	// Points holds one data point per day.
+	Points []FlowDataPoint `json:"points"`
 }
 
 // StartSprintRequest contains parameters for creating a new sprint.
@@ -89,6 +139,16 @@ type SprintRepository interface {
 	ReadSprintStatus(ctx context.Context, sprintPath string) (SprintStatus, error)
 	// WriteSprintStatus writes the sprint status to .gitta/status file.
 	WriteSprintStatus(ctx context.Context, sprintPath string, status SprintStatus) error
+	// ReadSprintCapacity reads the sprint capacity from .gitta/capacity.yaml.
+	// Returns ErrNoCapacity if the sprint has no capacity file.
+	ReadSprintCapacity(ctx context.Context, sprintPath string) (*SprintCapacity, error)
+	// WriteSprintCapacity writes the sprint capacity to .gitta/capacity.yaml.
+	WriteSprintCapacity(ctx context.Context, sprintPath string, capacity *SprintCapacity) error
+	// ReadSprintSchedule reads the sprint dates from .gitta/schedule.yaml.
+	// Returns ErrNoSchedule if the sprint has no schedule file.
+	ReadSprintSchedule(ctx context.Context, sprintPath string) (*SprintSchedule, error)
+	// WriteSprintSchedule writes the sprint dates to .gitta/schedule.yaml.
+	WriteSprintSchedule(ctx context.Context, sprintPath string, schedule *SprintSchedule) error
 	// RenameSprintWithPrefix renames a sprint folder with a new status prefix atomically.
 	RenameSprintWithPrefix(ctx context.Context, oldPath string, newPrefix SprintStatus, id string, desc string) error
 	// FindActiveSprint locates the currently active sprint.
@@ -124,49 +184,63 @@ func ValidateSprintName(name string) error {
 }
 
 // ParseDuration parses a duration string (e.g., "2w", "14d") and returns the number of days.
-// Returns an error if the format is invalid.
+// Returns an error if the format is invalid. Working-day durations ("10wd")
+// depend on a calendar and are rejected; use Calendar.EndDate for them.
 func ParseDuration(duration string) (int, error) {
-	if duration == "" {
-		return 14, nil // Default: 2 weeks
+	days, workingDays, err := parseSprintDuration(duration)
+	if err != nil {
+		return 0, err
+	}
+	if workingDays {
+		return 0, fmt.Errorf("working-day duration %q is not supported here (use 'w' or 'd')", duration)
 	}
+	return days, nil
+}
 
-	var days int
-	var unit string
+// parseSprintDuration parses "<number>w", "<number>d" or "<number>wd" and
+// returns the number of days and whether they are working days.
+func parseSprintDuration(duration string) (int, bool, error) {
+	if duration == "" {
+		return 14, false, nil // Default: 2 weeks
+	}
 
 	// Parse number and unit
 	if len(duration) < 2 {
-		return 0, errors.New("invalid duration format: must be <number><unit>")
+		return 0, false, errors.New("invalid duration format: must be <number><unit>")
+	}
+
+	// Extract unit (trailing letters) and number (everything before)
+	split := len(duration)
+	for split > 0 && (duration[split-1] < '0' || duration[split-1] > '9') {
+		split--
 	}
+	number, unit := duration[:split], strings.ToLower(duration[split:])
 
-	// Extract unit (last character)
-	unit = duration[len(duration)-1:]
-	// Extract number (everything except last character)
-	_, err := fmt.Sscanf(duration[:len(duration)-1], "%d", &days)
+	days, err := strconv.Atoi(number)
 	if err != nil {
-		return 0, fmt.Errorf("invalid duration format: %w", err)
+		return 0, false, fmt.Errorf("invalid duration format: %q", duration)
 	}
 
 	if days <= 0 {
-		return 0, errors.New("duration must be positive")
+		return 0, false, errors.New("duration must be positive")
 	}
 
 	switch unit {
-	case "w", "W":
-		return days * 7, nil
-	case "d", "D":
-		return days, nil
+	case "w":
+		return days * 7, false, nil
+	case "d":
+		return days, false, nil
+	case "wd":
+		return days, true, nil
 	default:
-		return 0, fmt.Errorf("invalid duration unit: %s (must be 'w' or 'd')", unit)
+		return 0, false, fmt.Errorf("invalid duration unit: %s (must be 'w', 'd' or 'wd')", unit)
 	}
 }
 
-// CalculateEndDate calculates the sprint end date from start date and duration.
+// CalculateEndDate calculates the sprint end date from start date and
+// duration, counting working days ("10wd") with DefaultCalendar.
 func CalculateEndDate(startDate time.Time, duration string) (time.Time, error) {
-	days, err := ParseDuration(duration)
-	if err != nil {
-		return time.Time{}, err
-	}
-	return startDate.AddDate(0, 0, days), nil
+	return DefaultCalendar().EndDate(startDate, duration)
 }
 
 // containsAny checks if the string contains any of the given substrings.
@@ -24,8 +24,17 @@ type Story struct {
 	UpdatedAt *time.Time `yaml:"updated_at,omitempty"` // Last update timestamp (nil if unset)
 	Tags      []string   `yaml:"tags,omitempty"`       // Tags for categorization
 
+	// Extra holds frontmatter keys that are not modelled above so they survive
+	// a read/write round trip (nil when the file has no additional keys).
+	Extra map[string]interface{} `yaml:",inline"`
+
 	// Content
 	Body string `yaml:"-"` // Markdown body content (not in frontmatter)
+
+	// StatusDefaulted reports that Status was absent from the frontmatter and
+	// filled in with the workflow's initial state. Such statuses are derived
+	// from Git by StatusEngine and are not written back to the file.
+	StatusDefaulted bool `yaml:"-"`
 }
 
 // Priority represents the priority level of a story.
@@ -121,6 +130,10 @@ type StoryParser interface {
 	// Returns an error if the file cannot be read or parsed.
 	ReadStory(ctx context.Context, filePath string) (*Story, error)
 
+	// ParseStory parses story content that has already been read (e.g., an
+	// unsaved editor buffer). filePath is only used to report errors.
+	ParseStory(ctx context.Context, filePath string, content []byte) (*Story, error)
+
 	// WriteStory writes a Story struct to a Markdown file.
 	// It marshals the story metadata to YAML frontmatter and writes the body content.
 	// Uses atomic writes to prevent corruption. Returns an error if validation fails
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNothingToUndo indicates there are not as many operations to undo as
+	// requested.
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrUndoConflict indicates files changed after the operation to undo.
+	ErrUndoConflict = errors.New("files changed since the operation")
+)
+
+// FileTransaction stages changes to files and applies them together on
+// Commit: if Commit fails, or gitta stops while applying them, none of the
+// changes remain.
+type FileTransaction interface {
+	// Write stages writing path. write is called with a staging file to
+	// fill, which replaces path on commit. The staging file starts as a copy
+	// of path when it exists, so writers see its current content.
+	Write(path string, write func(staged string) error) error
+	// Rename stages moving the file oldPath to newPath.
+	Rename(oldPath, newPath string) error
+	// Remove stages deleting the file at path.
+	Remove(path string) error
+	// Commit applies the staged changes in the order they were staged,
+	// creating missing parent directories.
+	Commit(ctx context.Context) error
+	// Rollback discards the staged changes. It does nothing after Commit,
+	// so it can be deferred.
+	Rollback() error
+}
+
+// FileTransactions begins file transactions.
+type FileTransactions interface {
+	// Begin starts a transaction. operation describes it for the user, for
+	// example "move US-001 to tasks/sprints/Sprint-02".
+	Begin(ctx context.Context, operation string) (FileTransaction, error)
+}
+
+// Operation is a gitta command whose file changes can be undone.
+type Operation struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	// Changes describes the command's transactions, oldest first.
+	Changes []string `json:"changes"`
+	// Files lists the files it changed, relative to the repository root.
+	Files []string `json:"files"`
+}
+
+// OperationLog lists and undoes the operations recorded by file
+// transactions.
+type OperationLog interface {
+	// Operations returns the operations that can be undone, newest first.
+	Operations(ctx context.Context) ([]Operation, error)
+	// Undo restores the files changed by the last n operations, newest
+	// first, and returns the operations undone. Unless force is set, an
+	// operation whose files changed since is not undone and an error
+	// wrapping ErrUndoConflict is returned.
+	Undo(ctx context.Context, n int, force bool) ([]Operation, error)
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StatusCategory groups workflow states into the three buckets used by boards
+// and progress reporting.
+type StatusCategory string
+
+const (
+	CategoryTodo       StatusCategory = "todo"        // Not started
+	CategoryInProgress StatusCategory = "in_progress" // Started but not finished
+	CategoryDone       StatusCategory = "done"        // Finished (counts as complete in burndown)
+)
+
+// DerivationRule describes the Git state that selects a workflow state when a
+// story has no explicit status.
+type DerivationRule string
+
+const (
+	DeriveExplicit DerivationRule = "explicit" // Only set explicitly in frontmatter
+	DeriveBranch   DerivationRule = "branch"   // Story branch exists locally
+	DerivePushed   DerivationRule = "pushed"   // Story branch exists on a remote
+	DeriveMerged   DerivationRule = "merged"   // Story branch merged into a target branch
+	DeriveLabel    DerivationRule = "label"    // Story carries the state's Label tag
+)
+
+// WorkflowState is a single story status in a workflow.
+type WorkflowState struct {
+	// Name is the status value written to frontmatter (e.g., "qa").
+	Name Status
+	// Category buckets the state for boards and completion rules.
+	Category StatusCategory
+	// Derive selects the state from Git (or tags) when no status is set.
+	Derive DerivationRule
+	// Label is the story tag that selects the state when Derive is DeriveLabel.
+	Label string
+	// Transitions lists the states reachable from this one. Empty allows any.
+	Transitions []Status
+}
+
+// Workflow is the ordered set of story states for a repository.
+type Workflow struct {
+	// States are listed in board order.
+	States []WorkflowState
+	// Initial is the status of new stories and of stories without a branch.
+	Initial Status
+}
+
+// DefaultWorkflow returns the built-in todo → doing → review → done workflow.
+// Stories move forward one step at a time, may skip review, may step back from
+// doing or review, and done stories can only be reopened to doing.
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		Initial: StatusTodo,
+		States: []WorkflowState{
+			{Name: StatusTodo, Category: CategoryTodo, Derive: DeriveExplicit,
+				Transitions: []Status{StatusDoing}},
+			{Name: StatusDoing, Category: CategoryInProgress, Derive: DeriveBranch,
+				Transitions: []Status{StatusTodo, StatusReview, StatusDone}},
+			{Name: StatusReview, Category: CategoryInProgress, Derive: DerivePushed,
+				Transitions: []Status{StatusDoing, StatusDone}},
+			{Name: StatusDone, Category: CategoryDone, Derive: DeriveMerged,
+				Transitions: []Status{StatusDoing}},
+		},
+	}
+}
+
+// State returns the workflow state with the given name.
+func (w Workflow) State(status Status) (WorkflowState, bool) {
+	for _, s := range w.States {
+		if s.Name == status {
+			return s, true
+		}
+	}
+	return WorkflowState{}, false
+}
+
+// Has reports whether status is a state of the workflow.
+func (w Workflow) Has(status Status) bool {
+	_, ok := w.State(status)
+	return ok
+}
+
+// IsDone reports whether status belongs to the done category.
+func (w Workflow) IsDone(status Status) bool {
+	s, ok := w.State(status)
+	return ok && s.Category == CategoryDone
+}
+
+// CanTransition reports whether a story may move from one state to another.
+// Unknown source states and states without declared transitions allow any move.
+func (w Workflow) CanTransition(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	s, ok := w.State(from)
+	if !ok || len(s.Transitions) == 0 {
+		return true
+	}
+	for _, t := range s.Transitions {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition validates whether a story may move from one state to
+// another, describing the allowed targets when it may not.
+func (w Workflow) ValidateTransition(from, to Status) error {
+	if !w.Has(to) {
+		return fmt.Errorf("invalid status: %s (valid: %s)", to, w)
+	}
+	if w.CanTransition(from, to) {
+		return nil
+	}
+	s, _ := w.State(from)
+	allowed := make([]string, 0, len(s.Transitions))
+	for _, t := range s.Transitions {
+		allowed = append(allowed, string(t))
+	}
+	return fmt.Errorf("invalid transition from %s to %s (allowed: %s)", from, to, strings.Join(allowed, ", "))
+}
+
+// StateFor returns the first state derived by rule.
+func (w Workflow) StateFor(rule DerivationRule) (WorkflowState, bool) {
+	for _, s := range w.States {
+		if s.Derive == rule {
+			return s, true
+		}
+	}
+	return WorkflowState{}, false
+}
+
+// StateForTags returns the first label-derived state whose label is in tags.
+func (w Workflow) StateForTags(tags []string) (WorkflowState, bool) {
+	for _, s := range w.States {
+		if s.Derive != DeriveLabel || s.Label == "" {
+			continue
+		}
+		for _, tag := range tags {
+			if tag == s.Label {
+				return s, true
+			}
+		}
+	}
+	return WorkflowState{}, false
+}
+
+// StatesIn returns the states of a category in workflow order.
+func (w Workflow) StatesIn(category StatusCategory) []WorkflowState {
+	var states []WorkflowState
+	for _, s := range w.States {
+		if s.Category == category {
+			states = append(states, s)
+		}
+	}
+	return states
+}
+
+// Names returns the state names in workflow order.
+func (w Workflow) Names() []string {
+	names := make([]string, 0, len(w.States))
+	for _, s := range w.States {
+		names = append(names, string(s.Name))
+	}
+	return names
+}
+
+// String returns the state names joined with ", " for messages.
+func (w Workflow) String() string {
+	return strings.Join(w.Names(), ", ")
+}
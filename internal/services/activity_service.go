@@ -0,0 +1,349 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// ActivityDir is the directory of the activity journal, relative to the
+// repository root. Each Git user appends to their own <email>.jsonl file, so
+// journals of different users never conflict when merged.
+const ActivityDir = ".gitta/activity"
+
+// FieldChange is the value of a field before and after an operation; nil
+// means no value.
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Activity is one entry of the activity journal: an operation that changed a
+// story or a sprint.
+type Activity struct {
+	Time  time.Time `json:"time"`
+	Actor string    `json:"actor"`
+	Email string    `json:"email,omitempty"`
+	// Command is the command line that made the change.
+	Command string         `json:"command,omitempty"`
+	Event   core.EventType `json:"event"`
+	Story   string         `json:"story,omitempty"`
+	// Sprint is the sprint ID, without status prefix and description.
+	Sprint  string                 `json:"sprint,omitempty"`
+	Changes map[string]FieldChange `json:"changes"`
+}
+
+// Summary describes the changes of an entry, sorted by field, as
+// "status: todo → doing, assignee: alice". A value set where there was none
+// shows only the new value.
+func (a Activity) Summary() string {
+	names := make([]string, 0, len(a.Changes))
+	for name := range a.Changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		c := a.Changes[name]
+		before, after := activityValue(c.Before), activityValue(c.After)
+		if c.Before == nil {
+			parts = append(parts, fmt.Sprintf("%s: %s", name, after))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s: %s → %s", name, before, after))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// activityValue formats a changed value; "-" stands for no value.
+func activityValue(v interface{}) string {
+	if emptyValue(v) {
+		return "-"
+	}
+	return FormatFieldValue(v)
+}
+
+// ActivityFilter selects journal entries.
+type ActivityFilter struct {
+	// ID selects the entries of a story, or of a sprint by ID or folder name.
+	ID string
+	// Since excludes entries before it; zero includes all.
+	Since time.Time
+}
+
+// ActivityService reads and appends to the activity journal.
+type ActivityService interface {
+	// Record appends an entry to the journal of its actor. A zero time
+	// defaults to now and an empty actor to the Git user.
+	Record(ctx context.Context, activity Activity) error
+	// List returns the entries of all journals matching filter, newest first.
+	List(ctx context.Context, filter ActivityFilter) ([]Activity, error)
+}
+
+type activityService struct {
+	repoPath string
+
+	identityOnce sync.Once
+	name, email  string
+}
+
+// NewActivityService creates an ActivityService for the journal of the
+// repository at repoPath.
+func NewActivityService(repoPath string) ActivityService {
+	return &activityService{repoPath: repoPath}
+}
+
+// Record implements ActivityService.Record.
+func (s *activityService) Record(ctx context.Context, activity Activity) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if activity.Time.IsZero() {
+		activity.Time = time.Now().UTC()
+	}
+	if activity.Actor == "" {
+		s.identityOnce.Do(func() { s.name, s.email = gitIdentity(s.repoPath) })
+		activity.Actor, activity.Email = s.name, s.email
+	}
+	if activity.Changes == nil {
+		activity.Changes = map[string]FieldChange{}
+	}
+	line, err := json.Marshal(activity)
+	if err != nil {
+		return fmt.Errorf("failed to encode activity: %w", err)
+	}
+
+	dir := filepath.Join(s.repoPath, ActivityDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: dir, Cause: err}
+	}
+	path := filepath.Join(dir, activityFileName(activity))
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return &core.IOError{Operation: "open", FilePath: path, Cause: err}
+	}
+	// One write per entry, so concurrent appends never interleave
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return &core.IOError{Operation: "write", FilePath: path, Cause: err}
+	}
+	if err := file.Close(); err != nil {
+		return &core.IOError{Operation: "write", FilePath: path, Cause: err}
+	}
+	return nil
+}
+
+// List implements ActivityService.List. Lines that are not valid entries,
+// such as leftovers of a botched merge, are skipped.
+func (s *activityService) List(ctx context.Context, filter ActivityFilter) ([]Activity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(s.repoPath, ActivityDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []Activity{}, nil
+	}
+	if err != nil {
+		return nil, &core.IOError{Operation: "read", FilePath: dir, Cause: err}
+	}
+
+	sprintID := sprintName(filter.ID)
+	activities := []Activity{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		journal, err := readActivityFile(path)
+		if err != nil {
+			return nil, err
+		}
+		// Newest first within a journal too, so ties keep their order
+		for i := len(journal) - 1; i >= 0; i-- {
+			activity := journal[i]
+			if activity.Time.Before(filter.Since) {
+				continue
+			}
+			if filter.ID != "" && !strings.EqualFold(activity.Story, filter.ID) && !strings.EqualFold(activity.Sprint, sprintID) {
+				continue
+			}
+			activities = append(activities, activity)
+		}
+	}
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].Time.After(activities[j].Time)
+	})
+	return activities, nil
+}
+
+// readActivityFile reads the valid entries of a journal file.
+func readActivityFile(path string) ([]Activity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &core.IOError{Operation: "read", FilePath: path, Cause: err}
+	}
+	defer file.Close()
+
+	var activities []Activity
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var activity Activity
+		if err := json.Unmarshal(scanner.Bytes(), &activity); err != nil || activity.Event == "" {
+			continue
+		}
+		activities = append(activities, activity)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &core.IOError{Operation: "read", FilePath: path, Cause: err}
+	}
+	return activities, nil
+}
+
+// activityFileName returns the journal file of an entry's actor.
+func activityFileName(activity Activity) string {
+	key := activity.Email
+	if key == "" {
+		key = activity.Actor
+	}
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '@', r == '_', r == '-':
+			return r
+		}
+		return '-'
+	}, strings.ToLower(strings.TrimSpace(key)))
+	if strings.Trim(key, ".-") == "" {
+		key = "unknown"
+	}
+	return key + ".jsonl"
+}
+
+// gitIdentity returns the Git user name and email of the repository at
+// repoPath, falling back to the global configuration.
+func gitIdentity(repoPath string) (string, string) {
+	var user struct{ Name, Email string }
+	if repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true}); err == nil {
+		if cfg, err := repo.Config(); err == nil && cfg != nil {
+			user.Name, user.Email = cfg.User.Name, cfg.User.Email
+		}
+	}
+	if user.Name == "" || user.Email == "" {
+		if cfg, err := config.LoadConfig(config.GlobalScope); err == nil {
+			if user.Name == "" {
+				user.Name = cfg.User.Name
+			}
+			if user.Email == "" {
+				user.Email = cfg.User.Email
+			}
+		}
+	}
+	if user.Name == "" {
+		user.Name = "unknown"
+	}
+	return user.Name, user.Email
+}
+
+// sprintName returns the ID of a sprint folder name, or name itself when it
+// has no status prefix.
+func sprintName(name string) string {
+	if _, id, _, err := parseSprintFolderName(name); err == nil && id != "" {
+		return id
+	}
+	return name
+}
+
+type activityJournal struct {
+	activity ActivityService
+	command  string
+	output   io.Writer
+}
+
+// NewActivityJournal creates an EventBus recording what operations changed
+// to activity, with the command line that ran them. Events without changes
+// are not recorded. Failures to record are written to output as warnings;
+// they never fail the operation.
+func NewActivityJournal(activity ActivityService, command string, output io.Writer) core.EventBus {
+	return &activityJournal{activity: activity, command: command, output: output}
+}
+
+// Before implements core.EventBus.Before.
+func (j *activityJournal) Before(context.Context, core.Event) error { return nil }
+
+// After implements core.EventBus.After.
+func (j *activityJournal) After(ctx context.Context, event core.Event) {
+	for _, activity := range eventActivities(event) {
+		activity.Command = j.command
+		if err := j.activity.Record(ctx, activity); err != nil {
+			fmt.Fprintf(j.output, "Warning: failed to record activity: %v\n", err)
+		}
+	}
+}
+
+// eventActivities returns the journal entries of an event.
+func eventActivities(event core.Event) []Activity {
+	changes, ok := event.Data["changes"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	entry := Activity{Event: event.Type, Changes: make(map[string]FieldChange, len(changes))}
+	for name, value := range changes {
+		c, _ := value.(map[string]interface{})
+		entry.Changes[name] = FieldChange{Before: c["before"], After: c["after"]}
+	}
+	if story, ok := event.Data["story"].(map[string]interface{}); ok {
+		entry.Story, _ = story["id"].(string)
+	}
+	if sprint, ok := event.Data["sprint"].(string); ok {
+		entry.Sprint = sprintName(sprint)
+	}
+
+	switch event.Type {
+	case core.EventOperationUndone:
+		// One entry per story, or one for the command without stories
+		stories, _ := event.Data["stories"].([]string)
+		activities := make([]Activity, 0, len(stories))
+		for _, id := range stories {
+			story := entry
+			story.Story = id
+			activities = append(activities, story)
+		}
+		if len(activities) > 0 {
+			return activities
+		}
+	case core.EventRollover:
+		// One entry per story, so that the story's log shows it
+		stories, _ := event.Data["stories"].([]string)
+		target, _ := event.Data["target"].(string)
+		activities := make([]Activity, 0, len(stories))
+		for _, id := range stories {
+			story := entry
+			story.Story, story.Sprint = id, sprintName(target)
+			activities = append(activities, story)
+		}
+		return activities
+	case core.EventSprintActivated:
+		if archived, ok := event.Data["archived"].(string); ok {
+			return []Activity{entry, {
+				Event:   event.Type,
+				Sprint:  sprintName(archived),
+				Changes: map[string]FieldChange{"status": {Before: core.StatusActive.String(), After: core.StatusArchived.String()}},
+			}}
+		}
+	}
+	return []Activity{entry}
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// BoardService reads the whole workspace, the backlog and every sprint, with
+// derived story statuses.
+type BoardService interface {
+	// Snapshot returns the backlog and all sprints in folder order. Stories are
+	// sorted by ID within each group.
+	Snapshot(ctx context.Context) (*WorkspaceSnapshot, error)
+}
+
+// WorkspaceSnapshot is the backlog and sprints at one point in time.
+type WorkspaceSnapshot struct {
+	// Backlog holds the backlog stories (Source "Backlog").
+	Backlog []*StoryWithStatus
+	// Sprints holds every sprint, whatever its status.
+	Sprints []*SprintSnapshot
+}
+
+// SprintSnapshot is a sprint with its stories (Source is the sprint folder name).
+type SprintSnapshot struct {
+	// Name is the sprint folder name, including its status prefix.
+	Name    string
+	Path    string
+	Status  core.SprintStatus
+	Stories []*StoryWithStatus
+}
+
+// Stories returns the backlog and sprint stories in one slice.
+func (w *WorkspaceSnapshot) Stories() []*StoryWithStatus {
+	stories := append([]*StoryWithStatus{}, w.Backlog...)
+	for _, sprint := range w.Sprints {
+		stories = append(stories, sprint.Stories...)
+	}
+	return stories
+}
+
+// Sprint returns the sprint with the given folder name, or with that name once
+// its status prefix is removed; "current" is the active sprint. Returns nil if
+// there is none.
+func (w *WorkspaceSnapshot) Sprint(name string) *SprintSnapshot {
+	if name == "current" {
+		for _, sprint := range w.Sprints {
+			if sprint.Status == core.StatusActive {
+				return sprint
+			}
+		}
+	}
+	for _, sprint := range w.Sprints {
+		if sprint.Name == name || SprintTitle(sprint.Name) == name {
+			return sprint
+		}
+	}
+	return nil
+}
+
+// StoryFilter selects stories of a snapshot. Empty fields match every story;
+// values within a field are alternatives and are compared case-insensitively.
+type StoryFilter struct {
+	// Sprint is "backlog" or a sprint name as accepted by Sprint.
+	Sprint     string
+	Statuses   []string
+	Priorities []string
+	Assignees  []string
+	// Tags matches stories carrying any of the tags.
+	Tags []string
+	// Text matches stories whose ID, title or body contains it.
+	Text string
+	// Fields matches custom field values keyed by field name, as MatchFieldValue.
+	Fields map[string][]string
+}
+
+// Filter returns the stories matching filter sorted by ID, or
+// core.ErrSprintNotFound when filter.Sprint names no sprint.
+func (w *WorkspaceSnapshot) Filter(filter StoryFilter) ([]*StoryWithStatus, error) {
+	stories := w.Stories()
+	if filter.Sprint != "" {
+		if strings.EqualFold(filter.Sprint, "backlog") {
+			stories = w.Backlog
+		} else if sprint := w.Sprint(filter.Sprint); sprint != nil {
+			stories = sprint.Stories
+		} else {
+			return nil, fmt.Errorf("%w: %s", core.ErrSprintNotFound, filter.Sprint)
+		}
+	}
+
+	text := strings.ToLower(filter.Text)
+	matched := make([]*StoryWithStatus, 0, len(stories))
+	for _, s := range stories {
+		assignee := ""
+		if s.Story.Assignee != nil {
+			assignee = *s.Story.Assignee
+		}
+		if !matchFilter(filter.Statuses, string(s.Status)) ||
+			!matchFilter(filter.Priorities, string(s.Story.Priority)) ||
+			!matchFilter(filter.Assignees, assignee) ||
+			!matchAnyFilter(filter.Tags, s.Story.Tags) ||
+			!matchFieldFilters(filter.Fields, s.Story) {
+			continue
+		}
+		if text != "" &&
+			!strings.Contains(strings.ToLower(s.Story.ID), text) &&
+			!strings.Contains(strings.ToLower(s.Story.Title), text) &&
+			!strings.Contains(strings.ToLower(s.Story.Body), text) {
+			continue
+		}
+		matched = append(matched, s)
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Story.ID < matched[j].Story.ID })
+	return matched, nil
+}
+
+// matchFilter reports whether value is one of want (any value when want is empty).
+func matchFilter(want []string, value string) bool {
+	if len(want) == 0 {
+		return true
+	}
+	for _, w := range want {
+		if strings.EqualFold(w, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyFilter reports whether any of values is one of want.
+func matchAnyFilter(want, values []string) bool {
+	if len(want) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if matchFilter(want, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFieldFilters reports whether the story matches every field filter.
+func matchFieldFilters(filters map[string][]string, story *core.Story) bool {
+	for name, wants := range filters {
+		value, _ := story.FieldValue(name)
+		matched := false
+		for _, want := range wants {
+			if MatchFieldValue(value, want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseStoryQuery parses a query of space-separated terms into a filter.
+// Terms of the form key:value set sprint, status, priority, assignee, tag or
+// a custom field; comma-separated values are alternatives and repeated keys
+// add alternatives. Other terms are joined into the text search. Values with
+// spaces can be double-quoted, e.g. `status:doing,review tag:payments "credit card"`.
+func ParseStoryQuery(query string) (StoryFilter, error) {
+	var filter StoryFilter
+	terms, err := splitQuery(query)
+	if err != nil {
+		return filter, err
+	}
+
+	var text []string
+	for _, term := range terms {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || key == "" || strings.ContainsAny(key, " \"") {
+			text = append(text, term)
+			continue
+		}
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return filter, fmt.Errorf("%w: query term %q has no value", ErrInvalidInput, term)
+		}
+
+		switch strings.ToLower(key) {
+		case "sprint":
+			if filter.Sprint != "" || len(values) > 1 {
+				return filter, fmt.Errorf("%w: query can name one sprint only", ErrInvalidInput)
+			}
+			filter.Sprint = values[0]
+		case "status":
+			filter.Statuses = append(filter.Statuses, values...)
+		case "priority":
+			filter.Priorities = append(filter.Priorities, values...)
+		case "assignee":
+			filter.Assignees = append(filter.Assignees, values...)
+		case "tag", "tags":
+			filter.Tags = append(filter.Tags, values...)
+		default:
+			if filter.Fields == nil {
+				filter.Fields = make(map[string][]string)
+			}
+			filter.Fields[key] = append(filter.Fields[key], values...)
+		}
+	}
+	filter.Text = strings.Join(text, " ")
+	return filter, nil
+}
+
+// splitQuery splits a query at spaces outside double quotes and removes the
+// quotes.
+func splitQuery(query string) ([]string, error) {
+	var terms []string
+	var term strings.Builder
+	quoted, started := false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case !quoted && (r == ' ' || r == '\t'):
+			if started {
+				terms = append(terms, term.String())
+				term.Reset()
+				started = false
+			}
+		default:
+			term.WriteRune(r)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated quote in query %q", ErrInvalidInput, query)
+	}
+	if started {
+		terms = append(terms, term.String())
+	}
+	return terms, nil
+}
+
+// SprintTitle returns a sprint folder name without its status prefix.
+func SprintTitle(name string) string {
+	return strings.TrimLeft(name, "!+@~")
+}
+
+type boardService struct {
+	list       *listService
+	sprintRepo core.SprintRepository
+	repoPath   string
+}
+
+// NewBoardService creates a BoardService deriving statuses of the given workflow.
+func NewBoardService(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	repoPath string,
+	workflow core.Workflow,
+) BoardService {
+	return &boardService{
+		list:       NewListServiceWithWorkflow(storyRepo, gitRepo, workflow).(*listService),
+		sprintRepo: sprintRepo,
+		repoPath:   repoPath,
+	}
+}
+
+// Snapshot implements BoardService.Snapshot.
+func (s *boardService) Snapshot(ctx context.Context) (*WorkspaceSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	paths, err := resolveWorkspacePaths(ctx, s.repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	backlog, err := s.list.storyRepo.ListStories(ctx, paths.BacklogPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backlog stories: %w", err)
+	}
+	all := append([]*core.Story{}, backlog...)
+
+	names, err := s.sprintRepo.ListSprints(ctx, paths.SprintsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sprints: %w", err)
+	}
+	sprints := make([]*SprintSnapshot, 0, len(names))
+	sprintStories := make([][]*core.Story, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(paths.SprintsPath, name)
+		status, err := s.sprintRepo.ReadSprintStatus(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sprint status of %s: %w", name, err)
+		}
+		stories, err := s.list.storyRepo.ListStories(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stories in %s: %w", name, err)
+		}
+		sprints = append(sprints, &SprintSnapshot{Name: name, Path: path, Status: status})
+		sprintStories = append(sprintStories, stories)
+		all = append(all, stories...)
+	}
+
+	derived, err := s.list.deriveStatuses(ctx, s.repoPath, all)
+	if err != nil {
+		return nil, err
+	}
+
+	sortStories(backlog)
+	snapshot := &WorkspaceSnapshot{Backlog: toStoryWithStatus(backlog, "Backlog", derived), Sprints: sprints}
+	for i, sprint := range sprints {
+		sortStories(sprintStories[i])
+		sprint.Stories = toStoryWithStatus(sprintStories[i], sprint.Name, derived)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// BulkUpdateOptions controls a bulk update.
+type BulkUpdateOptions struct {
+	// DryRun validates the table and reports the changes without writing.
+	DryRun bool
+	// Force allows status changes the workflow does not declare.
+	Force bool
+}
+
+// BulkChange is the change of one story.
+type BulkChange struct {
+	ID   string
+	Path string
+	// Changed lists the changed attributes, as StoryUpdate.Changed.
+	Changed []string
+	// Before and After are the story file before and after the change.
+	Before string
+	After  string
+	// Commit is the hash of the commit recording the change, if any.
+	Commit string
+
+	update StoryUpdate
+}
+
+// BulkRowError is a row that cannot be applied.
+type BulkRowError struct {
+	// Row is the 1-based position of the row in the table.
+	Row     int
+	ID      string
+	Message string
+}
+
+// BulkUpdateReport describes a bulk update.
+type BulkUpdateReport struct {
+	DryRun  bool
+	Changes []BulkChange
+	// Unchanged counts the rows matching their story.
+	Unchanged int
+	// Ignored lists the read-only columns of the table.
+	Ignored []string
+	Errors  []BulkRowError
+}
+
+// BulkUpdateService applies a table of story values, such as an edited
+// export, to the stories it names.
+type BulkUpdateService interface {
+	// Apply updates every story whose row differs from it. All rows are
+	// validated first; if any is invalid, nothing is written and the error
+	// wraps ErrValidationFailed. If writing a story fails, the report lists
+	// the changes written before.
+	Apply(ctx context.Context, table *StoryTable, opts BulkUpdateOptions) (*BulkUpdateReport, error)
+}
+
+type bulkUpdateService struct {
+	parser    core.StoryParser
+	storyRepo core.StoryRepository
+	board     BoardService
+	edit      StoryEditService
+	fields    []core.FieldDefinition
+	repoPath  string
+	workflow  core.Workflow
+}
+
+// NewBulkUpdateService creates a BulkUpdateService writing changes through
+// edit.
+func NewBulkUpdateService(
+	parser core.StoryParser,
+	storyRepo core.StoryRepository,
+	board BoardService,
+	edit StoryEditService,
+	fields []core.FieldDefinition,
+	repoPath string,
+	workflow core.Workflow,
+) BulkUpdateService {
+	return &bulkUpdateService{
+		parser:    parser,
+		storyRepo: storyRepo,
+		board:     board,
+		edit:      edit,
+		fields:    fields,
+		repoPath:  repoPath,
+		workflow:  workflow,
+	}
+}
+
+// Apply implements BulkUpdateService.Apply.
+func (s *bulkUpdateService) Apply(ctx context.Context, table *StoryTable, opts BulkUpdateOptions) (*BulkUpdateReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+	report := &BulkUpdateReport{DryRun: opts.DryRun}
+	for _, column := range table.Columns {
+		if readOnlyColumns[column] {
+			report.Ignored = append(report.Ignored, column)
+		}
+	}
+
+	snapshot, err := s.board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]core.Status)
+	for _, story := range snapshot.Stories() {
+		statuses[story.Story.ID] = story.Status
+	}
+
+	// Stories are rendered into a scratch directory to validate them and
+	// show the changes
+	scratch, err := os.MkdirTemp("", "gitta-bulk-")
+	if err != nil {
+		return nil, &core.IOError{Operation: "create", FilePath: os.TempDir(), Cause: err}
+	}
+	defer os.RemoveAll(scratch)
+
+	seen := make(map[string]int)
+	for i, row := range table.Rows {
+		n := i + 1
+		id := strings.TrimSpace(tableCell(row[ColumnID]))
+		if id == "" {
+			report.Errors = append(report.Errors, BulkRowError{Row: n, Message: "id is required"})
+			continue
+		}
+		if first, ok := seen[id]; ok {
+			report.Errors = append(report.Errors, BulkRowError{Row: n, ID: id, Message: fmt.Sprintf("duplicate of row %d", first)})
+			continue
+		}
+		seen[id] = n
+
+		change, err := s.plan(ctx, table.Columns, row, id, statuses[id], opts, filepath.Join(scratch, fmt.Sprintf("%d.md", n)))
+		if err != nil {
+			report.Errors = append(report.Errors, BulkRowError{Row: n, ID: id, Message: err.Error()})
+			continue
+		}
+		if change == nil {
+			report.Unchanged++
+			continue
+		}
+		report.Changes = append(report.Changes, *change)
+	}
+
+	if len(report.Errors) > 0 {
+		return report, fmt.Errorf("%w: %d invalid row(s), nothing was changed", ErrValidationFailed, len(report.Errors))
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	for i := range report.Changes {
+		change := &report.Changes[i]
+		applied, err := s.edit.UpdateStory(ctx, change.ID, change.update)
+		if applied != nil {
+			change.Path, change.Commit = applied.Path, applied.Commit
+		}
+		if err != nil {
+			// Report the changes written so far
+			if applied != nil {
+				i++
+			}
+			report.Changes = report.Changes[:i]
+			return report, fmt.Errorf("%s: %w", change.ID, err)
+		}
+	}
+	return report, nil
+}
+
+// plan compares a row with its story and returns the change, or nil when the
+// row matches the story. The changed story is rendered to preview.
+func (s *bulkUpdateService) plan(ctx context.Context, columns []string, row map[string]interface{}, id string, status core.Status, opts BulkUpdateOptions, preview string) (*BulkChange, error) {
+	story, path, err := s.storyRepo.FindStoryByID(ctx, s.repoPath, id)
+	if err != nil {
+		if errors.Is(err, core.ErrStoryNotFound) {
+			return nil, fmt.Errorf("story not found")
+		}
+		return nil, err
+	}
+	if status == "" {
+		status = story.Status
+	}
+
+	update := StoryUpdate{Force: opts.Force, Fields: make(map[string]interface{})}
+	for _, column := range columns {
+		value, ok := row[column]
+		if !ok || column == ColumnID || readOnlyColumns[column] {
+			continue
+		}
+		text := strings.TrimSpace(tableCell(value))
+
+		switch column {
+		case ColumnTitle:
+			if text != story.Title {
+				update.Title = &text
+			}
+		case ColumnStatus:
+			// An empty status leaves the story alone
+			if text != "" && core.Status(text) != status {
+				next := core.Status(text)
+				if !s.workflow.Has(next) {
+					return nil, fmt.Errorf("invalid status %s (valid: %s)", text, s.workflow)
+				}
+				update.Status = &next
+			}
+		case ColumnPriority:
+			if text != "" && core.Priority(text) != story.Priority {
+				priority := core.Priority(text)
+				update.Priority = &priority
+			}
+		case ColumnAssignee:
+			current := ""
+			if story.Assignee != nil {
+				current = *story.Assignee
+			}
+			if text != current {
+				update.Assignee = &text
+			}
+		case ColumnTags:
+			tags := []string{}
+			for _, tag := range strings.Split(text, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+			if strings.Join(tags, ",") != strings.Join(story.Tags, ",") {
+				update.Tags = &tags
+			}
+		case ColumnBody:
+			body := tableCell(value)
+			if strings.TrimRight(body, "\n") != strings.TrimRight(story.Body, "\n") {
+				if body != "" && !strings.HasSuffix(body, "\n") {
+					body += "\n"
+				}
+				update.Body = &body
+			}
+		default:
+			next, err := s.fieldValue(column, value)
+			if err != nil {
+				return nil, err
+			}
+			current, exists := story.Extra[column]
+			if next == nil {
+				if exists {
+					update.Fields[column] = nil
+				}
+			} else if !exists || tableCell(exportValue(current)) != tableCell(next) {
+				update.Fields[column] = next
+			}
+		}
+	}
+	if len(update.Changed()) == 0 {
+		return nil, nil
+	}
+
+	if update.Status != nil && !update.Force && !story.StatusDefaulted {
+		if err := s.workflow.ValidateTransition(story.Status, *update.Status); err != nil {
+			return nil, err
+		}
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &core.IOError{Operation: "read", FilePath: path, Cause: err}
+	}
+	applyStoryUpdate(story, update)
+	if errs := s.parser.ValidateStory(story); len(errs) > 0 {
+		return nil, errors.New(errs[0].Message)
+	}
+	if err := s.parser.WriteStory(ctx, preview, story); err != nil {
+		return nil, err
+	}
+	after, err := os.ReadFile(preview)
+	if err != nil {
+		return nil, &core.IOError{Operation: "read", FilePath: preview, Cause: err}
+	}
+
+	return &BulkChange{
+		ID:      id,
+		Path:    path,
+		Changed: update.Changed(),
+		Before:  string(before),
+		After:   string(after),
+		update:  update,
+	}, nil
+}
+
+// fieldValue converts a custom column value: configured fields are parsed and
+// validated as with --set, other CSV text is decoded as YAML (so JSON maps
+// survive a round trip). Empty values remove the field.
+func (s *bulkUpdateService) fieldValue(column string, value interface{}) (interface{}, error) {
+	if reservedFieldNames[column] {
+		return nil, fmt.Errorf("column %q cannot be updated", column)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	for _, def := range s.fields {
+		if def.Name == column {
+			text := tableCell(value)
+			if strings.TrimSpace(text) == "" {
+				return nil, nil
+			}
+			return ParseFieldValue(def, text)
+		}
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	var decoded interface{}
+	if err := yaml.Unmarshal([]byte(text), &decoded); err != nil || decoded == nil {
+		return text, nil
+	}
+	switch decoded.(type) {
+	case map[string]interface{}, []interface{}, int, float64, bool:
+		return decoded, nil
+	}
+	return text, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// rawCalendar mirrors the calendar section of .gitta/config.yaml.
+type rawCalendar struct {
+	Timezone     string   `yaml:"timezone"`
+	WorkingDays  []string `yaml:"working_days"`
+	Holidays     []string `yaml:"holidays"`
+	HolidayFiles []string `yaml:"holiday_files"`
+}
+
+// buildCalendar validates a raw calendar and loads its holiday files, which
+// are resolved relative to the repository root.
+func buildCalendar(repoPath string, raw rawCalendar) (core.Calendar, error) {
+	cal := core.DefaultCalendar()
+
+	if raw.Timezone != "" {
+		loc, err := time.LoadLocation(raw.Timezone)
+		if err != nil {
+			return core.Calendar{}, fmt.Errorf("calendar: invalid timezone %q: %v", raw.Timezone, err)
+		}
+		cal.Location = loc
+	}
+
+	if len(raw.WorkingDays) > 0 {
+		cal.WorkingDays = nil
+		for _, name := range raw.WorkingDays {
+			day, err := core.ParseWeekday(name)
+			if err != nil {
+				return core.Calendar{}, fmt.Errorf("calendar: %v", err)
+			}
+			cal.WorkingDays = append(cal.WorkingDays, day)
+		}
+	}
+
+	cal.Holidays = make(map[string]string)
+	for _, date := range raw.Holidays {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return core.Calendar{}, fmt.Errorf("calendar: invalid holiday %q (use YYYY-MM-DD)", date)
+		}
+		cal.Holidays[date] = ""
+	}
+	for _, file := range raw.HolidayFiles {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(repoPath, path)
+		}
+		holidays, err := LoadHolidayFile(path)
+		if err != nil {
+			return core.Calendar{}, fmt.Errorf("calendar: %w", err)
+		}
+		for date, name := range holidays {
+			cal.Holidays[date] = name
+		}
+	}
+	return cal, nil
+}
+
+// LoadHolidayFile reads holidays from an iCalendar (.ics) or YAML file and
+// returns them as date (YYYY-MM-DD) → name.
+func LoadHolidayFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &core.IOError{Operation: "read", FilePath: path, Cause: err}
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics", ".ical":
+		holidays, err := ParseICalHolidays(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return holidays, nil
+	case ".yaml", ".yml":
+		holidays, err := ParseYAMLHolidays(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return holidays, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported holiday file (use .ics or .yaml)", path)
+	}
+}
+
+// yamlHoliday is a holiday entry: either a date or {date, name}.
+type yamlHoliday struct {
+	Date string `yaml:"date"`
+	Name string `yaml:"name"`
+}
+
+// UnmarshalYAML accepts a plain date as well as a mapping.
+func (h *yamlHoliday) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		h.Date = node.Value
+		return nil
+	}
+	type plain yamlHoliday
+	return node.Decode((*plain)(h))
+}
+
+// ParseYAMLHolidays reads a YAML holiday file:
+//
+//	holidays:
+//	  - 2025-12-24
+//	  - date: 2025-12-25
+//	    name: Christmas Day
+func ParseYAMLHolidays(r io.Reader) (map[string]string, error) {
+	var doc struct {
+		Holidays []yamlHoliday `yaml:"holidays"`
+	}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid YAML: %v", err)
+	}
+	holidays := make(map[string]string, len(doc.Holidays))
+	for _, h := range doc.Holidays {
+		if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q (use YYYY-MM-DD)", h.Date)
+		}
+		holidays[h.Date] = h.Name
+	}
+	return holidays, nil
+}
+
+// icalUnescaper decodes escaped text values (RFC 5545 section 3.3.11).
+var icalUnescaper = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`)
+
+// ParseICalHolidays reads the all-day events of an iCalendar file. Multi-day
+// events cover every day from DTSTART up to (excluding) DTEND; events with a
+// time of day count for their start date. Recurrence rules are not expanded.
+func ParseICalHolidays(r io.Reader) (map[string]string, error) {
+	holidays := make(map[string]string)
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Folded lines continue the previous one after a space or tab
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	inEvent := false
+	var start, end time.Time
+	var summary string
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Drop parameters such as ;VALUE=DATE or ;TZID=Europe/Berlin
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+		switch name {
+		case "BEGIN":
+			if value == "VEVENT" {
+				inEvent, start, end, summary = true, time.Time{}, time.Time{}, ""
+			}
+		case "DTSTART", "DTEND":
+			if !inEvent {
+				continue
+			}
+			if len(value) < 8 {
+				return nil, fmt.Errorf("invalid %s %q", name, value)
+			}
+			date, err := time.Parse("20060102", value[:8])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", name, value)
+			}
+			if name == "DTSTART" {
+				start = date
+			} else if len(value) == 8 {
+				end = date // Exclusive end of an all-day event
+			}
+		case "SUMMARY":
+			summary = icalUnescaper.Replace(value)
+		case "END":
+			if value != "VEVENT" || !inEvent {
+				continue
+			}
+			inEvent = false
+			if start.IsZero() {
+				return nil, errors.New("event without DTSTART")
+			}
+			holidays[start.Format("2006-01-02")] = summary
+			for day := start.AddDate(0, 0, 1); day.Before(end); day = day.AddDate(0, 0, 1) {
+				holidays[day.Format("2006-01-02")] = summary
+			}
+		}
+	}
+	return holidays, nil
+}
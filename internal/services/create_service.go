@@ -8,10 +8,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/gavin/gitta/internal/core"
 )
 
@@ -31,17 +34,32 @@ type CreateStoryRequest struct {
 	Prefix   string // ID prefix (default: "STORY")
 	Template string // Template path (optional, uses default if empty)
 	Editor   string // Editor command (optional, uses $EDITOR env var)
+	NoEditor bool   // Never launch an editor (e.g., for non-interactive callers)
 	Status   core.Status
 	Priority core.Priority
 	Assignee *string
 	Tags     []string
+	// Fields holds custom field values keyed by field name (optional).
+	Fields map[string]interface{}
+	// FieldDefinitions are the project's custom fields. Defaults are applied for
+	// fields missing from Fields, and required fields are enforced before writing.
+	FieldDefinitions []core.FieldDefinition
+}
+
+// TemplateField is a custom field exposed to story templates. Value is rendered
+// as inline YAML so it can be written straight into the frontmatter.
+type TemplateField struct {
+	Name  string
+	Value string
 }
 
 type createService struct {
-	idGenerator core.IDGenerator
-	parser      core.StoryParser
-	storyRepo   core.StoryRepository
-	storyDir    string
+	idGenerator  core.IDGenerator
+	parser       core.StoryParser
+	storyRepo    core.StoryRepository
+	storyDir     string
+	events       core.EventBus
+	transactions core.FileTransactions
 }
 
 // NewCreateService creates a new CreateService instance.
@@ -50,12 +68,39 @@ func NewCreateService(
 	parser core.StoryParser,
 	storyRepo core.StoryRepository,
 	storyDir string,
+) CreateService {
+	return NewCreateServiceWithEvents(idGenerator, parser, storyRepo, storyDir, nil)
+}
+
+// NewCreateServiceWithEvents creates a CreateService publishing
+// story.created to events (nil for none).
+func NewCreateServiceWithEvents(
+	idGenerator core.IDGenerator,
+	parser core.StoryParser,
+	storyRepo core.StoryRepository,
+	storyDir string,
+	events core.EventBus,
+) CreateService {
+	return NewCreateServiceWithTransactions(idGenerator, parser, storyRepo, storyDir, events, nil)
+}
+
+// NewCreateServiceWithTransactions creates a CreateService writing story
+// files in transactions of transactions (nil to write them directly).
+func NewCreateServiceWithTransactions(
+	idGenerator core.IDGenerator,
+	parser core.StoryParser,
+	storyRepo core.StoryRepository,
+	storyDir string,
+	events core.EventBus,
+	transactions core.FileTransactions,
 ) CreateService {
 	return &createService{
-		idGenerator: idGenerator,
-		parser:      parser,
-		storyRepo:   storyRepo,
-		storyDir:    storyDir,
+		idGenerator:  idGenerator,
+		parser:       parser,
+		storyRepo:    storyRepo,
+		storyDir:     storyDir,
+		events:       eventsOrNop(events),
+		transactions: transactionsOrDirect(transactions),
 	}
 }
 
@@ -82,6 +127,11 @@ func (s *createService) CreateStory(ctx context.Context, req CreateStoryRequest)
 		req.Priority = core.PriorityMedium
 	}
 
+	fields, err := resolveCreateFields(req)
+	if err != nil {
+		return nil, "", err
+	}
+
 	// Generate unique ID
 	id, err := s.idGenerator.GenerateNextID(ctx, req.Prefix)
 	if err != nil {
@@ -122,6 +172,8 @@ func (s *createService) CreateStory(ctx context.Context, req CreateStoryRequest)
 		Assignee  string
 		CreatedAt string
 		Tags      []string
+		Fields    []TemplateField
+		Custom    map[string]interface{}
 	}{
 		ID:        id,
 		Title:     req.Title,
@@ -129,6 +181,11 @@ func (s *createService) CreateStory(ctx context.Context, req CreateStoryRequest)
 		Priority:  string(req.Priority),
 		CreatedAt: now.Format(time.RFC3339),
 		Tags:      req.Tags,
+		Custom:    fields,
+	}
+	templateData.Fields, err = templateFields(fields)
+	if err != nil {
+		return nil, "", err
 	}
 	if req.Assignee != nil {
 		templateData.Assignee = *req.Assignee
@@ -144,32 +201,28 @@ func (s *createService) CreateStory(ctx context.Context, req CreateStoryRequest)
 	fileName := fmt.Sprintf("%s.md", id)
 	filePath := filepath.Join(s.storyDir, fileName)
 
-	// Ensure story directory exists
-	if err := os.MkdirAll(s.storyDir, 0755); err != nil {
-		return nil, "", &core.IOError{
-			Operation: "create",
-			FilePath:  s.storyDir,
-			Cause:     err,
-		}
+	draft := &core.Story{ID: id, Title: req.Title, Status: req.Status, Priority: req.Priority, Assignee: req.Assignee, Tags: req.Tags, Extra: fields}
+	if err := s.events.Before(ctx, core.Event{Type: core.EventStoryCreated, Data: map[string]interface{}{"story": eventStory(draft), "path": filePath}}); err != nil {
+		return nil, "", err
 	}
 
-	// Write story file atomically (temp file + rename)
-	tmpFile := filePath + ".tmp"
-	if err := os.WriteFile(tmpFile, buf.Bytes(), 0644); err != nil {
-		return nil, "", &core.IOError{
-			Operation: "write",
-			FilePath:  filePath,
-			Cause:     err,
-		}
+	tx, err := s.transactions.Begin(ctx, "create "+id)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin create: %w", err)
 	}
-	if err := os.Rename(tmpFile, filePath); err != nil {
-		os.Remove(tmpFile)
+	defer tx.Rollback()
+	if err := tx.Write(filePath, func(staged string) error {
+		return os.WriteFile(staged, buf.Bytes(), 0644)
+	}); err != nil {
 		return nil, "", &core.IOError{
 			Operation: "write",
 			FilePath:  filePath,
 			Cause:     err,
 		}
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, "", err
+	}
 
 	// Parse the written file to get the Story struct
 	story, err := s.parser.ReadStory(ctx, filePath)
@@ -178,7 +231,7 @@ func (s *createService) CreateStory(ctx context.Context, req CreateStoryRequest)
 	}
 
 	// Launch editor if specified
-	if req.Editor != "" || os.Getenv("EDITOR") != "" {
+	if !req.NoEditor && (req.Editor != "" || os.Getenv("EDITOR") != "") {
 		editor := req.Editor
 		if editor == "" {
 			editor = os.Getenv("EDITOR")
@@ -225,5 +278,62 @@ func (s *createService) CreateStory(ctx context.Context, req CreateStoryRequest)
 		return nil, "", fmt.Errorf("story validation failed: %s", validationErrors[0].Message)
 	}
 
+	created := eventStory(story)
+	s.events.After(ctx, core.Event{Type: core.EventStoryCreated, Data: map[string]interface{}{"story": created, "path": filePath, "changes": storyChanges(nil, created)}})
 	return story, filePath, nil
 }
+
+// resolveCreateFields merges requested custom field values with configured
+// defaults and checks them against their definitions before anything is written.
+func resolveCreateFields(req CreateStoryRequest) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(req.Fields))
+	for name, value := range req.Fields {
+		fields[name] = value
+	}
+
+	for _, def := range req.FieldDefinitions {
+		value, ok := fields[def.Name]
+		if !ok && def.Default != nil {
+			value, ok = def.Default, true
+			fields[def.Name] = value
+		}
+		if !ok {
+			if def.Required {
+				return nil, fmt.Errorf("%w: %s is required (use --set %s=<value>)", ErrInvalidInput, def.Name, def.Name)
+			}
+			continue
+		}
+		if verr := validateFieldValue(def, value); verr != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidInput, verr.Message)
+		}
+	}
+
+	return fields, nil
+}
+
+// templateFields renders custom field values as sorted name/value pairs with
+// inline (flow style) YAML values.
+func templateFields(fields map[string]interface{}) ([]TemplateField, error) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]TemplateField, 0, len(names))
+	for _, name := range names {
+		node := &yaml.Node{}
+		if err := node.Encode(fields[name]); err != nil {
+			return nil, fmt.Errorf("failed to encode field %s: %w", name, err)
+		}
+		if node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
+			node.Style = yaml.FlowStyle
+		}
+		out, err := yaml.Marshal(node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %s: %w", name, err)
+		}
+		result = append(result, TemplateField{Name: name, Value: strings.TrimSpace(string(out))})
+	}
+	return result, nil
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// dateLayout is the canonical format of date custom fields.
+const dateLayout = "2006-01-02"
+
+// ValidationRules carries project-specific rules applied on top of the built-in
+// story checks performed by ValidateStory.
+type ValidationRules struct {
+	// Fields are the custom field definitions to enforce.
+	Fields []core.FieldDefinition
+	// Workflow restricts story statuses (core.DefaultWorkflow when nil).
+	Workflow *core.Workflow
+}
+
+// WorkflowOrDefault returns the rules' workflow, or core.DefaultWorkflow when unset.
+func (r ValidationRules) WorkflowOrDefault() core.Workflow {
+	if r.Workflow == nil || len(r.Workflow.States) == 0 {
+		return core.DefaultWorkflow()
+	}
+	return *r.Workflow
+}
+
+// ValidateStoryWithRules validates a story against the built-in rules and the
+// given project rules. Custom fields that are not defined are left unchecked.
+func ValidateStoryWithRules(story *core.Story, rules ValidationRules) []core.ValidationError {
+	errors := validateStory(story, rules.WorkflowOrDefault())
+	if story == nil {
+		return errors
+	}
+	return append(errors, validateCustomFields(story, rules.Fields)...)
+}
+
+// validateCustomFields checks the story's custom field values against their definitions.
+func validateCustomFields(story *core.Story, defs []core.FieldDefinition) []core.ValidationError {
+	var errors []core.ValidationError
+	for _, def := range defs {
+		value, ok := story.FieldValue(def.Name)
+		if !ok || value == nil {
+			if def.Required {
+				errors = append(errors, core.ValidationError{
+					Field:   def.Name,
+					Rule:    "required",
+					Message: fmt.Sprintf("%s is required", def.Name),
+				})
+			}
+			continue
+		}
+		if verr := validateFieldValue(def, value); verr != nil {
+			errors = append(errors, *verr)
+		}
+	}
+	return errors
+}
+
+// validateFieldValue checks a single value against its field definition.
+func validateFieldValue(def core.FieldDefinition, value interface{}) *core.ValidationError {
+	fail := func(rule, format string, args ...interface{}) *core.ValidationError {
+		return &core.ValidationError{Field: def.Name, Rule: rule, Message: fmt.Sprintf(format, args...)}
+	}
+
+	switch def.Type {
+	case core.FieldTypeString:
+		if _, ok := value.(string); !ok {
+			return fail("type", "%s must be a string", def.Name)
+		}
+	case core.FieldTypeInt, core.FieldTypeFloat:
+		n, ok := numberValue(value)
+		if !ok {
+			return fail("type", "%s must be a number", def.Name)
+		}
+		if def.Type == core.FieldTypeInt && n != math.Trunc(n) {
+			return fail("type", "%s must be a whole number", def.Name)
+		}
+		if def.Min != nil && n < *def.Min {
+			return fail("range", "%s must be at least %v", def.Name, *def.Min)
+		}
+		if def.Max != nil && n > *def.Max {
+			return fail("range", "%s must be at most %v", def.Name, *def.Max)
+		}
+	case core.FieldTypeBool:
+		if _, ok := value.(bool); !ok {
+			return fail("type", "%s must be true or false", def.Name)
+		}
+	case core.FieldTypeDate:
+		if _, ok := dateValue(value); !ok {
+			return fail("format", "%s must be a date (YYYY-MM-DD)", def.Name)
+		}
+	case core.FieldTypeEnum:
+		s, ok := value.(string)
+		if !ok || !containsString(def.Values, s) {
+			return fail("enum", "%s must be one of: %s", def.Name, strings.Join(def.Values, ", "))
+		}
+	case core.FieldTypeList:
+		items, ok := value.([]interface{})
+		if !ok {
+			return fail("type", "%s must be a list", def.Name)
+		}
+		for i, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return fail("type", "%s item at index %d must be a string", def.Name, i)
+			}
+			if len(def.Values) > 0 && !containsString(def.Values, s) {
+				return fail("enum", "%s item %q must be one of: %s", def.Name, s, strings.Join(def.Values, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// ParseFieldValue converts a command-line string (e.g., from --set) into a typed
+// field value. List values are comma separated.
+func ParseFieldValue(def core.FieldDefinition, raw string) (interface{}, error) {
+	raw = strings.TrimSpace(raw)
+
+	var value interface{}
+	switch def.Type {
+	case core.FieldTypeInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number", def.Name)
+		}
+		value = n
+	case core.FieldTypeFloat:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", def.Name)
+		}
+		value = f
+	case core.FieldTypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", def.Name)
+		}
+		value = b
+	case core.FieldTypeDate:
+		t, ok := dateValue(raw)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD)", def.Name)
+		}
+		value = t.Format(dateLayout)
+	case core.FieldTypeList:
+		items := []interface{}{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value = items
+	default:
+		value = raw
+	}
+
+	if verr := validateFieldValue(def, value); verr != nil {
+		return nil, fmt.Errorf("%s", verr.Message)
+	}
+	return value, nil
+}
+
+// FormatFieldValue renders a field value as plain text for display and matching.
+// Lists are joined with commas; missing values render as an empty string.
+func FormatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, FormatFieldValue(item))
+		}
+		return strings.Join(parts, ",")
+	case time.Time:
+		return v.Format(dateLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// MatchFieldValue reports whether a story's field value matches want. List
+// fields match when any item equals want.
+func MatchFieldValue(value interface{}, want string) bool {
+	if items, ok := value.([]interface{}); ok {
+		for _, item := range items {
+			if FormatFieldValue(item) == want {
+				return true
+			}
+		}
+		return false
+	}
+	return value != nil && FormatFieldValue(value) == want
+}
+
+// CompareFieldValues orders two values of the given field: numbers numerically,
+// dates chronologically, everything else as text. Missing values sort last.
+// Returns -1, 0, or 1.
+func CompareFieldValues(def core.FieldDefinition, a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	switch def.Type {
+	case core.FieldTypeInt, core.FieldTypeFloat:
+		x, okA := numberValue(a)
+		y, okB := numberValue(b)
+		if okA && okB {
+			return compareOrdered(x, y)
+		}
+	case core.FieldTypeDate:
+		x, okA := dateValue(a)
+		y, okB := dateValue(b)
+		if okA && okB {
+			return x.Compare(y)
+		}
+	case core.FieldTypeEnum:
+		// Enum values sort in declaration order.
+		x, y := indexOf(def.Values, FormatFieldValue(a)), indexOf(def.Values, FormatFieldValue(b))
+		if x >= 0 && y >= 0 {
+			return compareOrdered(x, y)
+		}
+	}
+	return strings.Compare(FormatFieldValue(a), FormatFieldValue(b))
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func dateValue(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		if t, err := time.Parse(dateLayout, v); err == nil {
+			return t, true
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func compareOrdered[T int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func containsString(values []string, s string) bool {
+	return indexOf(values, s) >= 0
+}
+
+func indexOf(values []string, s string) int {
+	for i, v := range values {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
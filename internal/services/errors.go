@@ -29,6 +29,21 @@ var (
 
 	// ErrMigrationConflict indicates migration targets already exist without --force.
 	ErrMigrationConflict = errors.New("migration target directories already exist")
+
+	// ErrInvalidTransition indicates a story status change not allowed by the workflow.
+	ErrInvalidTransition = errors.New("status transition not allowed")
+
+	// ErrValidationFailed indicates a story would not pass validation after a change.
+	ErrValidationFailed = errors.New("story validation failed")
+
+	// ErrNotCommitted indicates a story change was written but could not be committed.
+	ErrNotCommitted = errors.New("change saved but not committed")
+
+	// ErrInvalidConfig indicates .gitta/config.yaml is malformed or declares invalid settings.
+	ErrInvalidConfig = errors.New("invalid configuration")
+
+	// ErrNoThroughput indicates there is no historical throughput to forecast from.
+	ErrNoThroughput = errors.New("no historical throughput")
 )
 
 // AssigneeUpdateError wraps an assignee update failure with file context.
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// ExportOptions selects the stories and columns of an export.
+type ExportOptions struct {
+	// Columns lists the columns in order. Empty selects every column except
+	// body; "all" selects every column.
+	Columns []string
+	Filter  StoryFilter
+}
+
+// ExportService exports stories as tables for spreadsheets and data
+// pipelines.
+type ExportService interface {
+	// Export returns the stories matching opts.Filter, sorted by ID, with all
+	// built-in columns and custom frontmatter keys available.
+	Export(ctx context.Context, opts ExportOptions) (*StoryTable, error)
+}
+
+type exportService struct {
+	board    BoardService
+	gitRepo  core.GitRepository
+	fields   []core.FieldDefinition
+	repoPath string
+	config   StatusEngineConfig
+}
+
+// NewExportService creates an ExportService. Branches are matched to stories
+// as the status engine does.
+func NewExportService(board BoardService, gitRepo core.GitRepository, fields []core.FieldDefinition, repoPath string) ExportService {
+	return &exportService{
+		board:    board,
+		gitRepo:  gitRepo,
+		fields:   fields,
+		repoPath: repoPath,
+		config:   loadConfig(),
+	}
+}
+
+// Export implements ExportService.Export.
+func (s *exportService) Export(ctx context.Context, opts ExportOptions) (*StoryTable, error) {
+	snapshot, err := s.board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := snapshot.Filter(opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := s.gitRepo.GetBranchList(ctx, s.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	// Configured fields come first, in configuration order, then other
+	// frontmatter keys by name
+	custom := make([]string, 0, len(s.fields))
+	known := make(map[string]bool)
+	for _, def := range s.fields {
+		custom = append(custom, def.Name)
+		known[def.Name] = true
+	}
+	var extra []string
+	for _, story := range stories {
+		for name := range story.Story.Extra {
+			if !known[name] {
+				known[name] = true
+				extra = append(extra, name)
+			}
+		}
+	}
+	sort.Strings(extra)
+	custom = append(custom, extra...)
+
+	columns, err := exportColumns(opts.Columns, custom)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &StoryTable{Columns: columns}
+	for _, story := range stories {
+		table.Rows = append(table.Rows, s.row(story, columns, branches))
+	}
+	return table, nil
+}
+
+// exportColumns resolves the requested columns against the built-in and
+// custom columns.
+func exportColumns(requested, custom []string) ([]string, error) {
+	available := append(append(append([]string{}, storyColumns...), custom...), ColumnBody)
+	all := len(requested) == 1 && requested[0] == "all"
+	if len(requested) == 0 || all {
+		columns := make([]string, 0, len(available))
+		for _, column := range available {
+			if column != ColumnBody || all {
+				columns = append(columns, column)
+			}
+		}
+		return columns, nil
+	}
+
+	columns := make([]string, 0, len(requested))
+	seen := make(map[string]bool)
+	for _, column := range requested {
+		column = strings.TrimSpace(column)
+		if !containsString(available, column) {
+			return nil, fmt.Errorf("%w: unknown field %q (valid: %s)", ErrInvalidInput, column, strings.Join(available, ", "))
+		}
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+// row returns the values of one story.
+func (s *exportService) row(story *StoryWithStatus, columns []string, branches []core.Branch) map[string]interface{} {
+	row := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		var value interface{}
+		switch column {
+		case ColumnID:
+			value = story.Story.ID
+		case ColumnTitle:
+			value = story.Story.Title
+		case ColumnStatus:
+			value = string(story.Status)
+		case ColumnDerivedStatus:
+			if story.Derived != "" {
+				value = string(story.Derived)
+			}
+		case ColumnPriority:
+			value = string(story.Story.Priority)
+		case ColumnAssignee:
+			if story.Story.Assignee != nil {
+				value = *story.Story.Assignee
+			}
+		case ColumnTags:
+			value = append([]string{}, story.Story.Tags...)
+		case ColumnLocation:
+			value = story.Source
+		case ColumnBranch:
+			if branch := branchMatcher(story.Story.ID, branches, s.config); branch != nil {
+				value = branch.Name
+			}
+		case ColumnCreatedAt:
+			value = exportTime(story.Story.CreatedAt)
+		case ColumnUpdatedAt:
+			value = exportTime(story.Story.UpdatedAt)
+		case ColumnBody:
+			value = story.Story.Body
+		default:
+			value = exportValue(story.Story.Extra[column])
+		}
+		row[column] = value
+	}
+	return row
+}
+
+func exportTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// exportValue converts dates decoded from frontmatter back to their text.
+func exportValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return FormatFieldValue(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = exportValue(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = exportValue(item)
+		}
+		return out
+	}
+	return value
+}
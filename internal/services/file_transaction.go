@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// directTransactions applies file changes as soon as they are staged, for
+// services created without a transaction log. A failure leaves the changes
+// made before it.
+type directTransactions struct{}
+
+// Begin implements core.FileTransactions.Begin.
+func (directTransactions) Begin(context.Context, string) (core.FileTransaction, error) {
+	return directTransaction{}, nil
+}
+
+type directTransaction struct{}
+
+// Write implements core.FileTransaction.Write: the file is written in place.
+func (directTransaction) Write(path string, write func(staged string) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: filepath.Dir(path), Cause: err}
+	}
+	return write(path)
+}
+
+// Rename implements core.FileTransaction.Rename.
+func (directTransaction) Rename(oldPath, newPath string) error {
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: filepath.Dir(newPath), Cause: err}
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return &core.IOError{Operation: "rename", FilePath: newPath, Cause: err}
+	}
+	return nil
+}
+
+// Remove implements core.FileTransaction.Remove.
+func (directTransaction) Remove(path string) error {
+	if err := os.Remove(path); err != nil {
+		return &core.IOError{Operation: "remove", FilePath: path, Cause: err}
+	}
+	return nil
+}
+
+// Commit implements core.FileTransaction.Commit.
+func (directTransaction) Commit(context.Context) error { return nil }
+
+// Rollback implements core.FileTransaction.Rollback.
+func (directTransaction) Rollback() error { return nil }
+
+// transactionsOrDirect returns transactions, or direct file changes for nil.
+func transactionsOrDirect(transactions core.FileTransactions) core.FileTransactions {
+	if transactions == nil {
+		return directTransactions{}
+	}
+	return transactions
+}
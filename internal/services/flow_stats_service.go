@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// FlowStatsService computes lead time, cycle time and time-in-status metrics
+// from story timelines reconstructed from Git history.
+type FlowStatsService interface {
+	// FlowStats reports flow metrics for stories merged within the period.
+	FlowStats(ctx context.Context, opts FlowStatsOptions) (*core.FlowReport, error)
+}
+
+// FlowStatsOptions selects the stories included in a flow report.
+type FlowStatsOptions struct {
+	// Since keeps stories merged on or after this time. Zero keeps all.
+	Since time.Time
+	// Tags keeps stories carrying any of these tags. Empty keeps all.
+	Tags []string
+}
+
+type flowStatsService struct {
+	storyRepo  core.StoryRepository
+	sprintRepo core.SprintRepository
+	timelines  core.GitTimelineAnalyzer
+	repoPath   string
+	workflow   core.Workflow
+	calendar   core.Calendar
+	config     StatusEngineConfig
+}
+
+// NewFlowStatsService creates a FlowStatsService for the default workflow.
+func NewFlowStatsService(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	timelines core.GitTimelineAnalyzer,
+	repoPath string,
+) FlowStatsService {
+	return NewFlowStatsServiceWithWorkflow(storyRepo, sprintRepo, timelines, repoPath, core.DefaultWorkflow())
+}
+
+// NewFlowStatsServiceWithWorkflow creates a FlowStatsService that attributes
+// time between Git milestones to the workflow's initial, branch-derived and
+// push-derived states.
+func NewFlowStatsServiceWithWorkflow(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	timelines core.GitTimelineAnalyzer,
+	repoPath string,
+	workflow core.Workflow,
+) FlowStatsService {
+	return NewFlowStatsServiceWithCalendar(storyRepo, sprintRepo, timelines, repoPath, workflow, core.Calendar{})
+}
+
+// NewFlowStatsServiceWithCalendar creates a FlowStatsService that counts
+// only time spent on the calendar's working days.
+func NewFlowStatsServiceWithCalendar(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	timelines core.GitTimelineAnalyzer,
+	repoPath string,
+	workflow core.Workflow,
+	calendar core.Calendar,
+) FlowStatsService {
+	return &flowStatsService{
+		storyRepo:  storyRepo,
+		sprintRepo: sprintRepo,
+		timelines:  timelines,
+		repoPath:   repoPath,
+		workflow:   workflow,
+		calendar:   calendar,
+		config:     loadConfig(),
+	}
+}
+
+// FlowStats implements FlowStatsService.FlowStats.
+func (s *flowStatsService) FlowStats(ctx context.Context, opts FlowStatsOptions) (*core.FlowReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stories, err := s.collectStories(ctx, opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(stories))
+	for i, story := range stories {
+		ids[i] = story.ID
+	}
+	timelines, err := s.timelines.StoryTimelines(ctx, core.TimelineRequest{
+		RepoPath:       s.repoPath,
+		StoryIDs:       ids,
+		BranchPrefix:   s.config.BranchPrefix,
+		TargetBranches: s.config.TargetBranches,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconstruct story timelines: %w", err)
+	}
+
+	report := &core.FlowReport{Since: opts.Since}
+	for i, tl := range timelines {
+		if tl.Merged == nil {
+			if tl.BranchCreated != nil {
+				report.InProgress++
+			}
+			continue
+		}
+		if !opts.Since.IsZero() && tl.Merged.Before(opts.Since) {
+			continue
+		}
+		report.Stories = append(report.Stories, s.storyFlow(stories[i], tl))
+	}
+	sort.SliceStable(report.Stories, func(i, j int) bool {
+		return report.Stories[i].Timeline.Merged.Before(*report.Stories[j].Timeline.Merged)
+	})
+
+	var lead, cycle []time.Duration
+	inStatus := make(map[core.Status][]time.Duration)
+	for _, flow := range report.Stories {
+		if flow.LeadTime != nil {
+			lead = append(lead, *flow.LeadTime)
+		}
+		if flow.CycleTime != nil {
+			cycle = append(cycle, *flow.CycleTime)
+		}
+		for status, d := range flow.InStatus {
+			inStatus[status] = append(inStatus[status], d)
+		}
+	}
+	report.LeadTime = SummarizeDurations(lead)
+	report.CycleTime = SummarizeDurations(cycle)
+	for _, status := range s.phaseStates() {
+		report.TimeInStatus = append(report.TimeInStatus, core.StatusDurationStats{
+			Status: status,
+			Stats:  SummarizeDurations(inStatus[status]),
+		})
+	}
+	return report, nil
+}
+
+// collectStories lists stories from the backlog and every sprint directory,
+// keeping the first story seen for each ID.
+func (s *flowStatsService) collectStories(ctx context.Context, tags []string) ([]*core.Story, error) {
+	paths, err := resolveWorkspacePaths(ctx, s.repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := []string{paths.BacklogPath}
+	sprintNames, err := s.sprintRepo.ListSprints(ctx, paths.SprintsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sprints: %w", err)
+	}
+	for _, name := range sprintNames {
+		dirs = append(dirs, filepath.Join(paths.SprintsPath, name))
+	}
+
+	filter := Filter{Tags: tags}
+	seen := make(map[string]bool)
+	var stories []*core.Story
+	for _, dir := range dirs {
+		listed, err := s.storyRepo.ListStories(ctx, dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stories in %s: %w", dir, err)
+		}
+		for _, story := range listed {
+			if seen[story.ID] || !matchesFilter(story, filter) {
+				continue
+			}
+			seen[story.ID] = true
+			stories = append(stories, story)
+		}
+	}
+	sortStories(stories)
+	return stories, nil
+}
+
+// storyFlow computes the metrics of a merged story. Durations whose start
+// milestone is missing, or lies after its end, are left out.
+func (s *flowStatsService) storyFlow(story *core.Story, tl core.StoryTimeline) core.StoryFlow {
+	flow := core.StoryFlow{
+		ID:       story.ID,
+		Title:    story.Title,
+		Timeline: tl,
+		LeadTime: s.between(tl.Created, tl.Merged),
+		InStatus: make(map[core.Status]time.Duration),
+	}
+	flow.CycleTime = s.between(tl.BranchCreated, tl.Merged)
+
+	// Time between milestones belongs to the state Git derivation reports
+	// during that span: initial until the branch exists, then the branch
+	// state until pushed, then the pushed state until merged.
+	branchEnd := tl.Merged
+	if tl.FirstPush != nil && !tl.FirstPush.After(*tl.Merged) {
+		branchEnd = tl.FirstPush
+		if state, ok := s.workflow.StateFor(core.DerivePushed); ok {
+			if d := s.between(tl.FirstPush, tl.Merged); d != nil {
+				flow.InStatus[state.Name] += *d
+			}
+		}
+	}
+	if state, ok := s.workflow.StateFor(core.DeriveBranch); ok {
+		if d := s.between(tl.BranchCreated, branchEnd); d != nil {
+			flow.InStatus[state.Name] += *d
+		}
+	}
+	if d := s.between(tl.Created, tl.BranchCreated); d != nil {
+		flow.InStatus[s.workflow.Initial] += *d
+	}
+	return flow
+}
+
+// phaseStates returns the states time-in-status is reported for, in workflow
+// order.
+func (s *flowStatsService) phaseStates() []core.Status {
+	phases := map[core.Status]bool{s.workflow.Initial: true}
+	for _, rule := range []core.DerivationRule{core.DeriveBranch, core.DerivePushed} {
+		if state, ok := s.workflow.StateFor(rule); ok {
+			phases[state.Name] = true
+		}
+	}
+
+	var states []core.Status
+	for _, state := range s.workflow.States {
+		if phases[state.Name] {
+			states = append(states, state.Name)
+		}
+	}
+	return states
+}
+
+// between returns the working time from start to end, or nil if either is
+// missing or the span is negative.
+func (s *flowStatsService) between(start, end *time.Time) *time.Duration {
+	if start == nil || end == nil || end.Before(*start) {
+		return nil
+	}
+	d := s.calendar.WorkingDuration(*start, *end)
+	return &d
+}
+
+// SummarizeDurations computes the count, mean, nearest-rank percentiles and
+// maximum of the samples. An empty input yields zero stats.
+func SummarizeDurations(samples []time.Duration) core.DurationStats {
+	if len(samples) == 0 {
+		return core.DurationStats{}
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return core.DurationStats{
+		Count: len(sorted),
+		Mean:  total / time.Duration(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P85:   percentile(sorted, 85),
+		P95:   percentile(sorted, 95),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+const (
+	// defaultForecastTrials is the number of Monte Carlo runs per forecast.
+	defaultForecastTrials = 10000
+	// maxForecastSprints bounds a single completion run so that low
+	// throughput cannot loop forever.
+	maxForecastSprints = 1000
+	// defaultSprintLength is used when no sprint period is known from Git.
+	defaultSprintLength = 14 * 24 * time.Hour
+)
+
+// ForecastService runs Monte Carlo simulations over the completed tasks per
+// archived sprint.
+type ForecastService interface {
+	// ForecastCompletion estimates how many sprints the items need.
+	ForecastCompletion(ctx context.Context, req ForecastRequest) (*core.CompletionForecast, error)
+	// ForecastCapacity estimates how many items the next sprints complete.
+	ForecastCapacity(ctx context.Context, req ForecastRequest) (*core.CapacityForecast, error)
+}
+
+// ForecastRequest configures a forecast.
+type ForecastRequest struct {
+	// Items is the number of items to complete. When zero, ForecastCompletion
+	// counts the unfinished backlog and sprint stories matching Filter.
+	Items int
+	// Filter selects the stories counted when Items is zero (e.g., an epic's tag).
+	Filter Filter
+	// Sprints is the number of sprints ForecastCapacity simulates. Default: 1.
+	Sprints int
+	// History uses only the most recent archived sprints. Zero uses all.
+	History int
+	// Trials is the number of simulation runs. Default: 10000.
+	Trials int
+	// Seed makes the simulation deterministic when non-zero.
+	Seed int64
+	// SprintLength converts sprints to dates. Zero uses the median archived
+	// sprint period from Git, or two weeks.
+	SprintLength time.Duration
+}
+
+type forecastService struct {
+	velocity     VelocityService
+	storyRepo    core.StoryRepository
+	sprintRepo   core.SprintRepository
+	gitRepo      core.GitRepository
+	statusEngine StatusEngine
+	repoPath     string
+	workflow     core.Workflow
+	now          func() time.Time
+}
+
+// NewForecastService creates a ForecastService for the default workflow.
+func NewForecastService(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	history core.GitSprintHistory,
+	repoPath string,
+) ForecastService {
+	return NewForecastServiceWithWorkflow(storyRepo, sprintRepo, gitRepo, history, repoPath, core.DefaultWorkflow())
+}
+
+// NewForecastServiceWithWorkflow creates a ForecastService that treats the
+// workflow's done category as completed.
+func NewForecastServiceWithWorkflow(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	history core.GitSprintHistory,
+	repoPath string,
+	workflow core.Workflow,
+) ForecastService {
+	return &forecastService{
+		velocity:     NewVelocityServiceWithWorkflow(storyRepo, sprintRepo, gitRepo, history, repoPath, workflow),
+		storyRepo:    storyRepo,
+		sprintRepo:   sprintRepo,
+		gitRepo:      gitRepo,
+		statusEngine: NewStatusEngineWithWorkflow(gitRepo, workflow),
+		repoPath:     repoPath,
+		workflow:     workflow,
+		now:          time.Now,
+	}
+}
+
+// ForecastCompletion implements ForecastService.ForecastCompletion.
+func (s *forecastService) ForecastCompletion(ctx context.Context, req ForecastRequest) (*core.CompletionForecast, error) {
+	throughput, sprintLength, err := s.throughput(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	items := req.Items
+	if items == 0 {
+		if items, err = s.remainingItems(ctx, req.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	trials := trialsOrDefault(req.Trials)
+	sprints := SimulateCompletion(throughput, items, trials, newForecastRand(req.Seed))
+	now := s.now()
+	forecast := &core.CompletionForecast{
+		Items:        items,
+		Throughput:   throughput,
+		Trials:       trials,
+		SprintLength: sprintLength,
+	}
+	for _, confidence := range core.ForecastConfidences {
+		n := forecastPercentile(sprints, confidence)
+		forecast.Outcomes = append(forecast.Outcomes, core.CompletionOutcome{
+			Confidence: confidence,
+			Sprints:    n,
+			Date:       now.Add(time.Duration(n) * sprintLength),
+		})
+	}
+	return forecast, nil
+}
+
+// ForecastCapacity implements ForecastService.ForecastCapacity.
+func (s *forecastService) ForecastCapacity(ctx context.Context, req ForecastRequest) (*core.CapacityForecast, error) {
+	throughput, _, err := s.throughput(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	sprints := req.Sprints
+	if sprints <= 0 {
+		sprints = 1
+	}
+	trials := trialsOrDefault(req.Trials)
+	totals := SimulateCapacity(throughput, sprints, trials, newForecastRand(req.Seed))
+	forecast := &core.CapacityForecast{Sprints: sprints, Throughput: throughput, Trials: trials}
+	for _, confidence := range core.ForecastConfidences {
+		// With c% confidence at least the (100-c)th percentile gets done
+		forecast.Outcomes = append(forecast.Outcomes, core.CapacityOutcome{
+			Confidence: confidence,
+			Items:      forecastPercentile(totals, 100-confidence),
+		})
+	}
+	return forecast, nil
+}
+
+// throughput returns the completed tasks per archived sprint and the sprint
+// length to convert sprints into dates.
+func (s *forecastService) throughput(ctx context.Context, req ForecastRequest) ([]int, time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	velocities, err := s.velocity.Velocity(ctx, VelocityOptions{Last: req.History})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	throughput := make([]int, 0, len(velocities))
+	var lengths []time.Duration
+	total := 0
+	for _, v := range velocities {
+		throughput = append(throughput, v.CompletedTasks)
+		total += v.CompletedTasks
+		if v.Start != nil && v.End != nil && v.End.After(*v.Start) {
+			lengths = append(lengths, v.End.Sub(*v.Start))
+		}
+	}
+	if total == 0 {
+		return nil, 0, fmt.Errorf("%w: no completed tasks in archived sprints", ErrNoThroughput)
+	}
+
+	sprintLength := req.SprintLength
+	if sprintLength <= 0 {
+		sprintLength = defaultSprintLength
+		if len(lengths) > 0 {
+			sprintLength = SummarizeDurations(lengths).P50
+		}
+	}
+	return throughput, sprintLength, nil
+}
+
+// remainingItems counts unfinished stories in the backlog and non-archived
+// sprints that match the filter.
+func (s *forecastService) remainingItems(ctx context.Context, filter Filter) (int, error) {
+	paths, err := resolveWorkspacePaths(ctx, s.repoPath)
+	if err != nil {
+		return 0, err
+	}
+
+	dirs := []string{paths.BacklogPath}
+	names, err := s.sprintRepo.ListSprints(ctx, paths.SprintsPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sprints: %w", err)
+	}
+	for _, name := range names {
+		dir := filepath.Join(paths.SprintsPath, name)
+		if status, err := s.sprintRepo.ReadSprintStatus(ctx, dir); err == nil && status == core.StatusArchived {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+
+	var stories []*core.Story
+	for _, dir := range dirs {
+		listed, err := s.storyRepo.ListStories(ctx, dir)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list stories in %s: %w", dir, err)
+		}
+		stories = append(stories, listed...)
+	}
+	if len(stories) == 0 {
+		return 0, nil
+	}
+
+	branchList, err := s.gitRepo.GetBranchList(ctx, s.repoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to derive task status: %w", err)
+	}
+	statuses, err := s.statusEngine.DeriveStatusBatch(ctx, stories, branchList, s.repoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to derive task status: %w", err)
+	}
+
+	count := 0
+	for i, story := range stories {
+		story.Status = statuses[i]
+		if !s.workflow.IsDone(story.Status) && matchesFilter(story, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// SimulateCompletion runs trials that draw a random historical sprint
+// throughput per sprint until items are done, and returns the sorted number
+// of sprints each trial needed. Runs are capped at 1000 sprints.
+func SimulateCompletion(throughput []int, items, trials int, rng *rand.Rand) []int {
+	results := make([]int, trials)
+	for t := range results {
+		done, sprints := 0, 0
+		for done < items && sprints < maxForecastSprints {
+			done += throughput[rng.Intn(len(throughput))]
+			sprints++
+		}
+		results[t] = sprints
+	}
+	sort.Ints(results)
+	return results
+}
+
+// SimulateCapacity runs trials that draw a random historical sprint
+// throughput for each of the sprints, and returns the sorted totals.
+func SimulateCapacity(throughput []int, sprints, trials int, rng *rand.Rand) []int {
+	results := make([]int, trials)
+	for t := range results {
+		for i := 0; i < sprints; i++ {
+			results[t] += throughput[rng.Intn(len(throughput))]
+		}
+	}
+	sort.Ints(results)
+	return results
+}
+
+// forecastPercentile returns the nearest-rank percentile p of sorted results.
+func forecastPercentile(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func trialsOrDefault(trials int) int {
+	if trials <= 0 {
+		return defaultForecastTrials
+	}
+	return trials
+}
+
+// newForecastRand returns a deterministic source for a non-zero seed and a
+// time-seeded one otherwise.
+func newForecastRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
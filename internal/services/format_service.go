@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// FormatResult describes the outcome of formatting a single story file.
+type FormatResult struct {
+	// File is the story path relative to the repository root (slash separated).
+	File string `json:"file"`
+	// Changed reports whether the canonical form differs from the file on disk.
+	Changed bool `json:"changed"`
+	// Original and Formatted hold the file contents before and after formatting.
+	// Formatted is empty when Err is set.
+	Original  string `json:"-"`
+	Formatted string `json:"-"`
+	// Err is set when the file could not be parsed or formatted.
+	Err error `json:"-"`
+}
+
+// FormatService rewrites story files into their canonical form.
+type FormatService interface {
+	// FormatStories formats the given files or directories (the whole workspace
+	// when paths is empty). When write is false, files are left untouched and
+	// the results only report what would change.
+	FormatStories(ctx context.Context, paths []string, write bool) ([]FormatResult, error)
+}
+
+type formatService struct {
+	parser       core.StoryParser
+	repoPath     string
+	transactions core.FileTransactions
+}
+
+// NewFormatService creates a new FormatService instance.
+func NewFormatService(parser core.StoryParser, repoPath string) FormatService {
+	return NewFormatServiceWithTransactions(parser, repoPath, nil)
+}
+
+// NewFormatServiceWithTransactions creates a FormatService that rewrites
+// each file in a transaction of transactions (nil to change files
+// directly), so formatting can be undone.
+func NewFormatServiceWithTransactions(parser core.StoryParser, repoPath string, transactions core.FileTransactions) FormatService {
+	return &formatService{
+		parser:       parser,
+		repoPath:     repoPath,
+		transactions: transactionsOrDirect(transactions),
+	}
+}
+
+// FormatStories implements FormatService.FormatStories.
+func (s *formatService) FormatStories(ctx context.Context, paths []string, write bool) ([]FormatResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	files, err := resolveStoryFiles(ctx, s.repoPath, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]FormatResult, 0, len(files))
+	for _, file := range files {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		default:
+		}
+		results = append(results, s.formatFile(ctx, file, write))
+	}
+	return results, nil
+}
+
+// formatFile renders the canonical form of one file through the parser's
+// WriteStory, then compares it with the original and optionally replaces it.
+func (s *formatService) formatFile(ctx context.Context, file string, write bool) FormatResult {
+	result := FormatResult{File: relativeTo(s.repoPath, file)}
+
+	original, err := os.ReadFile(file)
+	if err != nil {
+		result.Err = &core.IOError{Operation: "read", FilePath: file, Cause: err}
+		return result
+	}
+	result.Original = string(original)
+
+	if _, ok := splitFrontmatter(result.Original); !ok {
+		result.Err = &core.ParseError{FilePath: file, Message: "missing YAML frontmatter"}
+		return result
+	}
+
+	story, err := s.parser.ReadStory(ctx, file)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	CanonicalizeStory(story)
+	if errs := s.parser.ValidateStory(story); len(errs) > 0 {
+		result.Err = &core.ParseError{
+			FilePath: file,
+			Message:  fmt.Sprintf("invalid story, run gitta lint: %s", errs[0].Message),
+		}
+		return result
+	}
+
+	// Render into a sibling scratch file: a new file always gets LF line endings
+	// and the final rename stays on the same filesystem.
+	scratch := filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".fmt")
+	os.Remove(scratch)
+	defer os.Remove(scratch)
+	if err := s.parser.WriteStory(ctx, scratch, story); err != nil {
+		result.Err = err
+		return result
+	}
+	formatted, err := os.ReadFile(scratch)
+	if err != nil {
+		result.Err = &core.IOError{Operation: "read", FilePath: scratch, Cause: err}
+		return result
+	}
+	result.Formatted = string(formatted)
+	result.Changed = result.Formatted != result.Original
+
+	if write && result.Changed {
+		result.Err = s.writeFile(ctx, file, result)
+	}
+	return result
+}
+
+// writeFile replaces file with its formatted content in one transaction.
+func (s *formatService) writeFile(ctx context.Context, file string, result FormatResult) error {
+	tx, err := s.transactions.Begin(ctx, "format "+result.File)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := tx.Write(file, func(staged string) error {
+		if err := os.WriteFile(staged, []byte(result.Formatted), 0644); err != nil {
+			return &core.IOError{Operation: "write", FilePath: file, Cause: err}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// CanonicalizeStory normalises story metadata in place: tags are trimmed,
+// lower-cased and de-duplicated, timestamps are converted to UTC, and the body
+// has LF line endings with no leading blank lines and exactly one trailing newline.
+func CanonicalizeStory(story *core.Story) {
+	if story == nil {
+		return
+	}
+
+	if len(story.Tags) > 0 {
+		seen := make(map[string]bool, len(story.Tags))
+		tags := make([]string, 0, len(story.Tags))
+		for _, tag := range story.Tags {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		story.Tags = tags
+	}
+
+	if story.CreatedAt != nil {
+		t := story.CreatedAt.UTC()
+		story.CreatedAt = &t
+	}
+	if story.UpdatedAt != nil {
+		t := story.UpdatedAt.UTC()
+		story.UpdatedAt = &t
+	}
+
+	body := strings.ReplaceAll(story.Body, "\r\n", "\n")
+	body = strings.Trim(body, "\n")
+	if body != "" {
+		body += "\n"
+	}
+	story.Body = body
+}
+
+// relativeTo returns path relative to root (slash separated) for display,
+// falling back to the path itself when it lies outside root.
+func relativeTo(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// HookPayload is the JSON document a hook reads from its standard input.
+type HookPayload struct {
+	Event core.EventType `json:"event"`
+	// Phase is "pre" for hooks run before the operation, "post" after it.
+	Phase    string                 `json:"phase"`
+	Time     string                 `json:"time"`
+	RepoRoot string                 `json:"repo_root"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+type hookBus struct {
+	hooks    []Hook
+	repoPath string
+	plugins  PluginService
+	output   io.Writer
+}
+
+// NewHookBus creates an EventBus running hooks in the repository at
+// repoPath. Plugin hooks are found as 'gitta <plugin>' finds plugins, with
+// searchPath as $PATH. Hook output, and failures of hooks run after an
+// operation, are written to output so they never mix with command output.
+func NewHookBus(repoPath string, hooks []Hook, searchPath string, output io.Writer) core.EventBus {
+	if len(hooks) == 0 {
+		return core.NopEventBus{}
+	}
+	return &hookBus{
+		hooks:    hooks,
+		repoPath: repoPath,
+		plugins:  NewPluginService(repoPath, searchPath),
+		output:   output,
+	}
+}
+
+// Before implements core.EventBus.Before: pre hooks run in order until one
+// fails.
+func (b *hookBus) Before(ctx context.Context, event core.Event) error {
+	for _, hook := range b.hooks {
+		if hook.Event != event.Type || !hook.Pre {
+			continue
+		}
+		if err := b.run(ctx, hook, event, "pre"); err != nil {
+			return fmt.Errorf("%w by %s hook %q: %v", core.ErrEventVetoed, event.Type, hook.Name(), err)
+		}
+	}
+	return nil
+}
+
+// After implements core.EventBus.After: every post hook runs, and failures
+// are reported as warnings.
+func (b *hookBus) After(ctx context.Context, event core.Event) {
+	for _, hook := range b.hooks {
+		if hook.Event != event.Type || hook.Pre {
+			continue
+		}
+		if err := b.run(ctx, hook, event, "post"); err != nil {
+			fmt.Fprintf(b.output, "Warning: %s hook %q failed: %v\n", event.Type, hook.Name(), err)
+		}
+	}
+}
+
+// run runs a hook with the event payload on its standard input.
+func (b *hookBus) run(ctx context.Context, hook Hook, event core.Event, phase string) error {
+	payload, err := json.Marshal(HookPayload{
+		Event:    event.Type,
+		Phase:    phase,
+		Time:     time.Now().UTC().Format(time.RFC3339),
+		RepoRoot: b.repoPath,
+		Data:     event.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout)
+	defer cancel()
+
+	env := []string{
+		"GITTA_EVENT=" + string(event.Type),
+		"GITTA_HOOK_PHASE=" + phase,
+		"GITTA_REPO_ROOT=" + b.repoPath,
+	}
+	if story, ok := event.Data["story"].(map[string]interface{}); ok {
+		env = append(env, fmt.Sprintf("GITTA_STORY_ID=%v", story["id"]))
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case hook.Plugin != "":
+		plugin, err := b.plugins.Find(ctx, hook.Plugin)
+		if err != nil {
+			return err
+		}
+		if plugin == nil {
+			return fmt.Errorf("plugin %s not found", hook.Plugin)
+		}
+		pluginContext, err := b.plugins.Context(ctx)
+		if err != nil {
+			return err
+		}
+		env = append(pluginContext.Env(), env...)
+		cmd = exec.CommandContext(ctx, plugin.Path, "hook", string(event.Type))
+	case runtime.GOOS == "windows":
+		cmd = exec.CommandContext(ctx, "cmd", "/C", hook.Run)
+	default:
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook.Run)
+	}
+	cmd.Dir = b.repoPath
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(append(payload, '\n'))
+	cmd.Stdout = b.output
+	cmd.Stderr = b.output
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", hook.Timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// eventStory returns the payload of a story in events.
+func eventStory(story *core.Story) map[string]interface{} {
+	out := map[string]interface{}{
+		"id":       story.ID,
+		"title":    story.Title,
+		"status":   string(story.Status),
+		"priority": string(story.Priority),
+		"assignee": nil,
+		"tags":     append([]string{}, story.Tags...),
+	}
+	if story.Assignee != nil {
+		out["assignee"] = *story.Assignee
+	}
+	if len(story.Extra) > 0 {
+		out["fields"] = exportValue(story.Extra)
+	}
+	return out
+}
+
+// storyChanges returns the changes between two event stories, with custom
+// fields compared one by one. before is nil for a new story.
+func storyChanges(before, after map[string]interface{}) map[string]interface{} {
+	flatten := func(story map[string]interface{}) map[string]interface{} {
+		out := make(map[string]interface{}, len(story))
+		for key, value := range story {
+			switch key {
+			case "id":
+			case "fields":
+				fields, _ := value.(map[string]interface{})
+				for name, v := range fields {
+					out[name] = v
+				}
+			default:
+				out[key] = value
+			}
+		}
+		return out
+	}
+	old, updated := flatten(before), flatten(after)
+	changes := make(map[string]interface{})
+	for key, value := range updated {
+		if prev := old[key]; !reflect.DeepEqual(prev, value) && !(emptyValue(prev) && emptyValue(value)) {
+			changes[key] = change(prev, value)
+		}
+	}
+	for key, prev := range old {
+		if _, ok := updated[key]; !ok && !emptyValue(prev) {
+			changes[key] = change(prev, nil)
+		}
+	}
+	return changes
+}
+
+// emptyValue reports whether v is nil, an empty string or an empty list.
+func emptyValue(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []string:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// change returns the event payload of a changed value.
+func change(before, after interface{}) map[string]interface{} {
+	return map[string]interface{}{"before": before, "after": after}
+}
+
+// eventPath returns path relative to the repository root with forward
+// slashes, as recorded in event changes.
+func eventPath(repoPath, path string) string {
+	if rel, err := filepath.Rel(repoPath, path); err == nil {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
+
+// eventsOrNop returns events, or a bus without subscribers for nil.
+func eventsOrNop(events core.EventBus) core.EventBus {
+	if events == nil {
+		return core.NopEventBus{}
+	}
+	return events
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// ParseJiraCSV reads a Jira issue export ("Export CSV (all fields)"). Columns
+// that repeat for multi-value fields, such as Labels and Sprint, are
+// collected; descriptions are converted from Jira wiki markup to Markdown.
+func ParseJiraCSV(r io.Reader) (*ImportBatch, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid Jira CSV: %v", ErrInvalidInput, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: empty Jira CSV", ErrInvalidInput)
+	}
+
+	header := records[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\uFEFF") // Excel adds a BOM
+	}
+	columns := make(map[string]bool)
+	for _, name := range header {
+		columns[strings.TrimSpace(name)] = true
+	}
+	if !columns["Summary"] || !columns["Issue key"] {
+		return nil, fmt.Errorf("%w: not a Jira export: the Summary and Issue key columns are required", ErrInvalidInput)
+	}
+
+	batch := &ImportBatch{Source: "jira"}
+	for _, record := range records[1:] {
+		values := make(map[string][]string)
+		var order []string
+		for i, value := range record {
+			if i >= len(header) || strings.TrimSpace(value) == "" {
+				continue
+			}
+			name := strings.TrimSpace(header[i])
+			if _, seen := values[name]; !seen {
+				order = append(order, name)
+			}
+			values[name] = append(values[name], value)
+		}
+		if len(values) == 0 {
+			continue // blank line
+		}
+
+		first := func(name string) string {
+			if v := values[name]; len(v) > 0 {
+				return strings.TrimSpace(v[0])
+			}
+			return ""
+		}
+		item := ImportItem{
+			Key:            first("Issue key"),
+			Title:          first("Summary"),
+			Body:           jiraToMarkdown(first("Description")),
+			Status:         first("Status"),
+			StatusCategory: jiraStatusCategory(first("Status Category")),
+			Priority:       first("Priority"),
+			Assignee:       first("Assignee"),
+			Labels:         values["Labels"],
+			Fields:         make(map[string][]string),
+		}
+		// The last sprint is the one the issue is, or was last, planned in
+		if sprints := values["Sprint"]; len(sprints) > 0 {
+			item.Sprint = strings.TrimSpace(sprints[len(sprints)-1])
+		}
+		if t, ok := parseImportTime(first("Created")); ok {
+			item.Created = &t
+		}
+		if t, ok := parseImportTime(first("Updated")); ok {
+			item.Updated = &t
+		}
+		if item.Key == "" {
+			batch.Skipped = append(batch.Skipped, ImportSkip{Title: item.Title, Reason: "no issue key"})
+			continue
+		}
+
+		for _, name := range order {
+			if jiraMappedColumns[name] {
+				continue
+			}
+			field := name
+			if m := jiraCustomFieldPattern.FindStringSubmatch(name); m != nil {
+				field = m[1]
+			}
+			item.Fields[field] = append(item.Fields[field], values[name]...)
+		}
+		batch.Items = append(batch.Items, item)
+	}
+	return batch, nil
+}
+
+// jiraMappedColumns are the columns mapped onto story attributes, and
+// identifiers that duplicate the issue key.
+var jiraMappedColumns = map[string]bool{
+	"Issue key": true, "Issue id": true, "Summary": true, "Description": true,
+	"Status": true, "Status Category": true, "Priority": true, "Assignee": true,
+	"Assignee Id": true, "Labels": true, "Sprint": true, "Created": true, "Updated": true,
+}
+
+// jiraCustomFieldPattern extracts the name of a custom field column.
+var jiraCustomFieldPattern = regexp.MustCompile(`^Custom field \((.+)\)$`)
+
+// jiraStatusCategory maps Jira's status categories.
+func jiraStatusCategory(category string) core.StatusCategory {
+	switch strings.ToLower(category) {
+	case "to do", "new":
+		return core.CategoryTodo
+	case "in progress", "indeterminate":
+		return core.CategoryInProgress
+	case "done", "complete":
+		return core.CategoryDone
+	}
+	return ""
+}
+
+var (
+	jiraHeadingPattern = regexp.MustCompile(`^h([1-6])\.\s+(.*)$`)
+	jiraListPattern    = regexp.MustCompile(`^([*#-]+)\s+(.*)$`)
+	jiraCodePattern    = regexp.MustCompile(`^\{(code|noformat)(?::([^}|]*))?[^}]*\}(.*)$`)
+	jiraMonoPattern    = regexp.MustCompile(`\{\{(.+?)\}\}`)
+	jiraLinkPattern    = regexp.MustCompile(`\[([^\]|]+)\|([^\]]+)\]`)
+	jiraBareLink       = regexp.MustCompile(`\[((?:https?|mailto):[^\]]+)\]`)
+	jiraImagePattern   = regexp.MustCompile(`!([^!\s|]+)(?:\|[^!]*)?!`)
+	jiraBoldPattern    = regexp.MustCompile(`(^|[\s(\[])\*([^*\s](?:[^*]*[^*\s])?)\*($|[\s).,:;!?\]])`)
+	jiraItalicPattern  = regexp.MustCompile(`(^|[\s(\[])_([^_\s](?:[^_]*[^_\s])?)_($|[\s).,:;!?\]])`)
+)
+
+// jiraToMarkdown converts the common subset of Jira wiki markup: headings,
+// lists, code blocks, quotes, tables, links, images and inline styles.
+func jiraToMarkdown(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n")
+	if text == "" {
+		return ""
+	}
+
+	var out []string
+	var fence string // closing tag of the open code block
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if i := strings.Index(line, "{"+fence+"}"); i >= 0 {
+				if before := line[:i]; strings.TrimSpace(before) != "" {
+					out = append(out, before)
+				}
+				out = append(out, "```")
+				fence = ""
+				continue
+			}
+			out = append(out, line)
+			continue
+		}
+		if m := jiraCodePattern.FindStringSubmatch(trimmed); m != nil {
+			out = append(out, "```"+strings.TrimSpace(m[2]))
+			rest := m[3]
+			if i := strings.Index(rest, "{"+m[1]+"}"); i >= 0 {
+				out = append(out, rest[:i], "```")
+				continue
+			}
+			if rest != "" {
+				out = append(out, rest)
+			}
+			fence = m[1]
+			continue
+		}
+
+		switch {
+		case jiraHeadingPattern.MatchString(trimmed):
+			m := jiraHeadingPattern.FindStringSubmatch(trimmed)
+			out = append(out, strings.Repeat("#", int(m[1][0]-'0'))+" "+jiraInline(m[2]))
+		case strings.HasPrefix(trimmed, "bq. "):
+			out = append(out, "> "+jiraInline(strings.TrimPrefix(trimmed, "bq. ")))
+		case strings.HasPrefix(trimmed, "||"):
+			cells := strings.Split(strings.Trim(trimmed, "|"), "||")
+			out = append(out, jiraTableRow(cells), "|"+strings.Repeat(" --- |", len(cells)))
+		case strings.HasPrefix(trimmed, "|"):
+			out = append(out, jiraTableRow(strings.Split(strings.Trim(trimmed, "|"), "|")))
+		case trimmed == "----":
+			out = append(out, "---")
+		case jiraListPattern.MatchString(trimmed):
+			m := jiraListPattern.FindStringSubmatch(trimmed)
+			depth := len(m[1]) - 1
+			marker := "-"
+			if strings.HasSuffix(m[1], "#") {
+				marker = "1."
+			}
+			out = append(out, strings.Repeat("  ", depth)+marker+" "+jiraInline(m[2]))
+		default:
+			out = append(out, jiraInline(line))
+		}
+	}
+	if fence != "" {
+		out = append(out, "```")
+	}
+	return strings.Join(out, "\n")
+}
+
+func jiraTableRow(cells []string) string {
+	for i, cell := range cells {
+		cells[i] = jiraInline(strings.TrimSpace(cell))
+	}
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+// jiraInline converts inline markup. Monospace text is set aside first so
+// its content is left alone.
+func jiraInline(text string) string {
+	var code []string
+	text = jiraMonoPattern.ReplaceAllStringFunc(text, func(m string) string {
+		code = append(code, jiraMonoPattern.FindStringSubmatch(m)[1])
+		return fmt.Sprintf("\x00%d\x00", len(code)-1)
+	})
+
+	text = jiraImagePattern.ReplaceAllString(text, "![]($1)")
+	text = jiraLinkPattern.ReplaceAllString(text, "[$1]($2)")
+	text = jiraBareLink.ReplaceAllString(text, "<$1>")
+	// Adjacent matches share a delimiter, so each pattern runs twice; bold
+	// goes first so converted italics are not read as bold
+	for _, style := range []struct {
+		pattern *regexp.Regexp
+		repl    string
+	}{{jiraBoldPattern, "$1**$2**$3"}, {jiraItalicPattern, "$1*$2*$3"}} {
+		text = style.pattern.ReplaceAllString(text, style.repl)
+		text = style.pattern.ReplaceAllString(text, style.repl)
+	}
+
+	for i, c := range code {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), "`"+c+"`", 1)
+	}
+	return text
+}
@@ -0,0 +1,676 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// ImportMappingDir holds the original key to story ID tables of each import
+// source, relative to the repository root.
+const ImportMappingDir = ".gitta/import"
+
+// ImportBatch is the content of an export from another tool, read by
+// ParseJiraCSV or ParseTrelloBoard.
+type ImportBatch struct {
+	// Source names the tool (e.g., "jira"); it keys the story's external map
+	// and the mapping table.
+	Source string
+	Items  []ImportItem
+	// Skipped lists items the reader left out (e.g., archived cards).
+	Skipped []ImportSkip
+}
+
+// ImportItem is one work item of an export.
+type ImportItem struct {
+	// Key is the item's identifier in the source (e.g., "SHOP-12").
+	Key   string
+	Title string
+	// Body is the description converted to Markdown.
+	Body string
+	// Status is the source status or column name.
+	Status string
+	// StatusCategory is the source's own category of Status, when it has one.
+	StatusCategory core.StatusCategory
+	// Sprint is the source sprint name (empty when unplanned).
+	Sprint   string
+	Priority string
+	Assignee string
+	Labels   []string
+	Created  *time.Time
+	Updated  *time.Time
+	// Fields holds every other non-empty source field by its source name.
+	// Fields matching a custom field are imported; the rest are reported.
+	Fields map[string][]string
+}
+
+// ImportSkip is an item that was not imported.
+type ImportSkip struct {
+	Key    string
+	Title  string
+	Reason string
+}
+
+// ImportOptions controls an import.
+type ImportOptions struct {
+	// Prefix is the ID prefix of new stories (default "US").
+	Prefix string
+	// Sprint places started and finished items that have no sprint of their
+	// own: a sprint name, "current" (default) or "backlog".
+	Sprint string
+	// StatusMap maps source statuses (case-insensitive) to workflow states,
+	// overriding the automatic mapping.
+	StatusMap map[string]core.Status
+	// UserMap maps source user names to assignees.
+	UserMap map[string]string
+	// DryRun reports the import without writing anything.
+	DryRun bool
+}
+
+// ImportedStory is a story created, or previously created, for an item.
+type ImportedStory struct {
+	Key string
+	// ID is empty for stories a dry run would create.
+	ID     string
+	Title  string
+	Status core.Status
+	// Location is "Backlog" or the sprint folder name.
+	Location string
+	Path     string
+}
+
+// ImportReport describes an import.
+type ImportReport struct {
+	Source string
+	DryRun bool
+	// Imported lists the new stories in export order.
+	Imported []ImportedStory
+	// Existing lists items imported before, found in the mapping table.
+	Existing []ImportedStory
+	Skipped  []ImportSkip
+	// SkippedFields counts, per source field, the items whose value was not
+	// imported.
+	SkippedFields map[string]int
+	// Sprints lists the planning sprints created for source sprints.
+	Sprints []string
+	// Warnings describes lossy mappings (statuses, assignees, tags).
+	Warnings []string
+	// MappingFile is the mapping table, relative to the repository root.
+	MappingFile string
+}
+
+// ImportService creates stories from exports of other tools.
+type ImportService interface {
+	// Import creates a story for each item not imported before.
+	Import(ctx context.Context, batch *ImportBatch, opts ImportOptions) (*ImportReport, error)
+}
+
+type importService struct {
+	idGenerator core.IDGenerator
+	parser      core.StoryParser
+	board       BoardService
+	sprintPlan  SprintPlanService
+	fields      []core.FieldDefinition
+	repoPath    string
+	backlogPath string
+	workflow    core.Workflow
+}
+
+// NewImportService creates an ImportService. Source sprints without a
+// matching sprint folder are created through sprintPlan.
+func NewImportService(
+	idGenerator core.IDGenerator,
+	parser core.StoryParser,
+	board BoardService,
+	sprintPlan SprintPlanService,
+	fields []core.FieldDefinition,
+	repoPath string,
+	backlogPath string,
+	workflow core.Workflow,
+) ImportService {
+	return &importService{
+		idGenerator: idGenerator,
+		parser:      parser,
+		board:       board,
+		sprintPlan:  sprintPlan,
+		fields:      fields,
+		repoPath:    repoPath,
+		backlogPath: backlogPath,
+		workflow:    workflow,
+	}
+}
+
+// importRun holds the state of one import.
+type importRun struct {
+	*importService
+	opts     ImportOptions
+	report   *ImportReport
+	snapshot *WorkspaceSnapshot
+	// sprints caches resolved source sprints by name: folder name and path.
+	sprints map[string][2]string
+	// warned deduplicates warnings.
+	warned map[string]bool
+	// existing holds the stories of the workspace by ID.
+	existing map[string]*StoryWithStatus
+}
+
+// Import implements ImportService.Import.
+func (s *importService) Import(ctx context.Context, batch *ImportBatch, opts ImportOptions) (*ImportReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "US"
+	}
+	if opts.Sprint == "" {
+		opts.Sprint = "current"
+	}
+	for name, status := range opts.StatusMap {
+		if !s.workflow.Has(status) {
+			return nil, fmt.Errorf("%w: status map %q: invalid status %s (valid: %s)", ErrInvalidInput, name, status, s.workflow)
+		}
+	}
+
+	snapshot, err := s.board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mappingPath := filepath.Join(s.repoPath, ImportMappingDir, batch.Source+".csv")
+	mapping, err := readImportMapping(mappingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &importRun{
+		importService: s,
+		opts:          opts,
+		snapshot:      snapshot,
+		sprints:       make(map[string][2]string),
+		warned:        make(map[string]bool),
+		report: &ImportReport{
+			Source:        batch.Source,
+			DryRun:        opts.DryRun,
+			Skipped:       append([]ImportSkip{}, batch.Skipped...),
+			SkippedFields: make(map[string]int),
+			MappingFile:   filepath.ToSlash(filepath.Join(ImportMappingDir, batch.Source+".csv")),
+		},
+	}
+
+	run.existing = make(map[string]*StoryWithStatus)
+	for _, story := range snapshot.Stories() {
+		run.existing[story.Story.ID] = story
+	}
+
+	var added [][2]string
+	for _, item := range batch.Items {
+		if id, ok := mapping[item.Key]; ok {
+			imported := ImportedStory{Key: item.Key, ID: id, Title: item.Title}
+			if story, found := run.existing[id]; found {
+				imported.Status, imported.Location = story.Status, story.Source
+			}
+			run.report.Existing = append(run.report.Existing, imported)
+			continue
+		}
+
+		imported, err := run.importItem(ctx, batch.Source, item)
+		if err != nil {
+			var skip *importSkipError
+			if errors.As(err, &skip) {
+				run.report.Skipped = append(run.report.Skipped, ImportSkip{Key: item.Key, Title: item.Title, Reason: skip.reason})
+				continue
+			}
+			// Record what was written before failing
+			if writeErr := appendImportMapping(mappingPath, added); writeErr != nil {
+				return run.report, writeErr
+			}
+			return run.report, err
+		}
+		run.report.Imported = append(run.report.Imported, *imported)
+		if !opts.DryRun {
+			added = append(added, [2]string{item.Key, imported.ID})
+		}
+	}
+
+	if err := appendImportMapping(mappingPath, added); err != nil {
+		return run.report, err
+	}
+	return run.report, nil
+}
+
+// importSkipError marks an item that cannot be imported; the import goes on.
+type importSkipError struct {
+	reason string
+}
+
+func (e *importSkipError) Error() string {
+	return e.reason
+}
+
+// importItem creates the story of one item.
+func (r *importRun) importItem(ctx context.Context, source string, item ImportItem) (*ImportedStory, error) {
+	if strings.TrimSpace(item.Title) == "" {
+		return nil, &importSkipError{reason: "no title"}
+	}
+
+	status := r.mapStatus(item)
+	story := &core.Story{
+		Title:     strings.TrimSpace(item.Title),
+		Status:    status,
+		Priority:  r.mapPriority(item),
+		Tags:      r.mapTags(item),
+		CreatedAt: item.Created,
+		UpdatedAt: item.Updated,
+		Body:      item.Body,
+		Extra:     map[string]interface{}{externalKey: map[string]interface{}{source: item.Key}},
+	}
+	if story.Body != "" && !strings.HasSuffix(story.Body, "\n") {
+		story.Body += "\n"
+	}
+	if len(story.Title) > 200 {
+		story.Title = story.Title[:200]
+		r.warn(fmt.Sprintf("%s: title truncated to 200 characters", item.Key))
+	}
+	if story.CreatedAt != nil && story.UpdatedAt != nil && story.UpdatedAt.Before(*story.CreatedAt) {
+		story.UpdatedAt = nil
+	}
+	if assignee := r.mapUser(item.Assignee); assignee != "" {
+		story.Assignee = &assignee
+	}
+	r.mapFields(item, story)
+
+	// Validate before allocating an ID so skipped items do not use one up
+	story.ID = r.opts.Prefix + "-1"
+	if errs := r.parser.ValidateStory(story); len(errs) > 0 {
+		return nil, &importSkipError{reason: errs[0].Message}
+	}
+
+	location, dir, err := r.place(ctx, item, status)
+	if err != nil {
+		return nil, err
+	}
+	imported := &ImportedStory{Key: item.Key, Title: story.Title, Status: status, Location: location}
+	if r.opts.DryRun {
+		return imported, nil
+	}
+
+	// The counter does not know stories created by hand; skip their IDs
+	var id string
+	for id == "" || r.existing[id] != nil {
+		if id, err = r.idGenerator.GenerateNextID(ctx, r.opts.Prefix); err != nil {
+			return nil, fmt.Errorf("failed to generate ID: %w", err)
+		}
+	}
+	story.ID = id
+	path := filepath.Join(dir, id+".md")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, &core.IOError{Operation: "create", FilePath: dir, Cause: err}
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%w: %s already exists", ErrInvalidInput, path)
+	}
+	if err := r.parser.WriteStory(ctx, path, story); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", id, err)
+	}
+	imported.ID, imported.Path = id, path
+	return imported, nil
+}
+
+// place returns the location and directory of an item: its own sprint, the
+// backlog for items not started, or opts.Sprint.
+func (r *importRun) place(ctx context.Context, item ImportItem, status core.Status) (string, string, error) {
+	if item.Sprint != "" {
+		return r.sourceSprint(ctx, item.Sprint)
+	}
+	if state, ok := r.workflow.State(status); ok && state.Category == core.CategoryTodo {
+		return "Backlog", r.backlogPath, nil
+	}
+	if r.opts.Sprint == "backlog" {
+		return "Backlog", r.backlogPath, nil
+	}
+	if sprint := r.snapshot.Sprint(r.opts.Sprint); sprint != nil {
+		return sprint.Name, sprint.Path, nil
+	}
+	r.warn(fmt.Sprintf("sprint %q not found: started and finished items without a sprint go to the backlog", r.opts.Sprint))
+	return "Backlog", r.backlogPath, nil
+}
+
+// sprintDescPattern matches characters that cannot appear in sprint folder
+// descriptions.
+var sprintDescPattern = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// sourceSprint resolves a source sprint to a sprint folder, matching folder
+// names and titles case-insensitively and creating a planning sprint when
+// none matches.
+func (r *importRun) sourceSprint(ctx context.Context, name string) (string, string, error) {
+	if cached, ok := r.sprints[name]; ok {
+		return cached[0], cached[1], nil
+	}
+	desc := strings.Trim(sprintDescPattern.ReplaceAllString(name, "-"), "-")
+	for _, sprint := range r.snapshot.Sprints {
+		title := SprintTitle(sprint.Name)
+		if strings.EqualFold(sprint.Name, name) || strings.EqualFold(title, name) || strings.EqualFold(title, desc) ||
+			strings.HasSuffix(strings.ToLower(title), "_"+strings.ToLower(desc)) {
+			r.sprints[name] = [2]string{sprint.Name, sprint.Path}
+			return sprint.Name, sprint.Path, nil
+		}
+	}
+	if desc == "" {
+		return "", "", &importSkipError{reason: fmt.Sprintf("sprint name %q cannot be a folder name", name)}
+	}
+
+	if r.opts.DryRun {
+		location := "new sprint " + desc
+		r.sprints[name] = [2]string{location, ""}
+		r.report.Sprints = append(r.report.Sprints, desc)
+		return location, "", nil
+	}
+	sprint, err := r.sprintPlan.CreatePlanningSprint(ctx, CreatePlanningSprintRequest{Description: desc})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create sprint for %q: %w", name, err)
+	}
+	folder := filepath.Base(sprint.DirectoryPath)
+	r.sprints[name] = [2]string{folder, sprint.DirectoryPath}
+	r.report.Sprints = append(r.report.Sprints, folder)
+	return folder, sprint.DirectoryPath, nil
+}
+
+// statusKeyPattern matches characters ignored when comparing status names.
+var statusKeyPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// mapStatus maps a source status onto the workflow: an explicit mapping, a
+// state with the same name or label, a state named in the status (e.g.,
+// "Code Review"), or the first state of the status category.
+func (r *importRun) mapStatus(item ImportItem) core.Status {
+	name := strings.TrimSpace(item.Status)
+	for source, status := range r.opts.StatusMap {
+		if strings.EqualFold(source, name) {
+			return status
+		}
+	}
+	if name == "" {
+		return r.workflow.Initial
+	}
+
+	key := statusKeyPattern.ReplaceAllString(strings.ToLower(name), "")
+	words := statusKeyPattern.Split(strings.ToLower(name), -1)
+	for _, state := range r.workflow.States {
+		if key == statusKeyPattern.ReplaceAllString(string(state.Name), "") ||
+			(state.Label != "" && key == statusKeyPattern.ReplaceAllString(strings.ToLower(state.Label), "")) {
+			return state.Name
+		}
+	}
+	for _, state := range r.workflow.States {
+		for _, word := range words {
+			if word == string(state.Name) {
+				return state.Name
+			}
+		}
+	}
+
+	category := item.StatusCategory
+	if category == "" {
+		category = statusCategoryOf(words)
+	}
+	if states := r.workflow.StatesIn(category); category != "" && len(states) > 0 {
+		return states[0].Name
+	}
+	r.warn(fmt.Sprintf("status %q mapped to %s (use --status-map to choose)", name, r.workflow.Initial))
+	return r.workflow.Initial
+}
+
+// statusCategoryOf guesses the category of a status from common names.
+func statusCategoryOf(words []string) core.StatusCategory {
+	for _, word := range words {
+		switch word {
+		case "done", "closed", "resolved", "complete", "completed", "finished", "released", "shipped":
+			return core.CategoryDone
+		case "progress", "doing", "started", "review", "testing", "qa", "verify", "verification", "blocked", "wip":
+			return core.CategoryInProgress
+		case "todo", "backlog", "open", "new", "selected", "ready", "planned", "ideas":
+			return core.CategoryTodo
+		}
+	}
+	return ""
+}
+
+// mapPriority maps Jira-style priority names onto gitta priorities.
+func (r *importRun) mapPriority(item ImportItem) core.Priority {
+	switch strings.ToLower(strings.TrimSpace(item.Priority)) {
+	case "":
+		return core.PriorityMedium
+	case "highest", "blocker", "critical", "urgent":
+		return core.PriorityCritical
+	case "high", "major":
+		return core.PriorityHigh
+	case "medium", "normal":
+		return core.PriorityMedium
+	case "low", "lowest", "minor", "trivial":
+		return core.PriorityLow
+	}
+	r.warn(fmt.Sprintf("priority %q mapped to medium", item.Priority))
+	return core.PriorityMedium
+}
+
+// tagInvalidPattern matches characters not allowed in tags.
+var tagInvalidPattern = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// mapTags converts labels to valid, unique tags, keeping at most 20.
+func (r *importRun) mapTags(item ImportItem) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, label := range item.Labels {
+		tag := strings.Trim(tagInvalidPattern.ReplaceAllString(strings.TrimSpace(label), "-"), "-")
+		if len(tag) > 30 {
+			tag = strings.Trim(tag[:30], "-")
+		}
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		if tag != label {
+			r.warn(fmt.Sprintf("label %q imported as tag %q", label, tag))
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > 20 {
+		r.warn(fmt.Sprintf("%s: only the first 20 of %d labels were imported", item.Key, len(tags)))
+		tags = tags[:20]
+	}
+	return tags
+}
+
+// mapUser maps a source user to an assignee: through opts.UserMap, or by
+// turning the name into a user name ("Alice Smith" becomes "alice-smith").
+func (r *importRun) mapUser(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ""
+	}
+	if user, ok := r.opts.UserMap[name]; ok {
+		return user
+	}
+	if usernamePattern.MatchString(name) && len(name) <= 50 {
+		return name
+	}
+	user := strings.Trim(tagInvalidPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(user) > 50 {
+		user = user[:50]
+	}
+	if user != "" {
+		r.warn(fmt.Sprintf("user %q imported as %q (use --user-map to choose)", name, user))
+	}
+	return user
+}
+
+// fieldKeyPattern matches characters ignored when matching source fields to
+// custom fields.
+var fieldKeyPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// mapFields imports source fields matching custom field names (ignoring case
+// and punctuation, so "Story Points" fills story_points) and counts the rest
+// as skipped.
+func (r *importRun) mapFields(item ImportItem, story *core.Story) {
+	names := make([]string, 0, len(item.Fields))
+	for name := range item.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		values := item.Fields[name]
+		key := fieldKeyPattern.ReplaceAllString(strings.ToLower(name), "")
+		imported := false
+		for _, def := range r.fields {
+			if fieldKeyPattern.ReplaceAllString(strings.ToLower(def.Name), "") != key {
+				continue
+			}
+			value, err := convertFieldValue(def, values)
+			if err == nil {
+				if verr := validateFieldValue(def, value); verr != nil {
+					err = errors.New(verr.Message)
+				}
+			}
+			if err != nil {
+				r.warn(fmt.Sprintf("%s: %s not imported: %v", item.Key, name, err))
+				break
+			}
+			story.Extra[def.Name] = value
+			imported = true
+			break
+		}
+		if !imported {
+			r.report.SkippedFields[name]++
+		}
+	}
+}
+
+// convertFieldValue converts source text to a custom field value.
+func convertFieldValue(def core.FieldDefinition, values []string) (interface{}, error) {
+	if def.Type == core.FieldTypeList {
+		return append([]string{}, values...), nil
+	}
+	value := strings.TrimSpace(strings.Join(values, ", "))
+	switch def.Type {
+	case core.FieldTypeInt:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f != float64(int(f)) {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return int(f), nil
+	case core.FieldTypeFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return f, nil
+	case core.FieldTypeBool:
+		b, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	case core.FieldTypeDate:
+		t, ok := parseImportTime(value)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a date", value)
+		}
+		return t.Format("2006-01-02"), nil
+	}
+	return value, nil
+}
+
+// importTimeLayouts are the date formats of Jira and Trello exports.
+var importTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/Jan/06 3:04 PM",
+	"02/Jan/2006 3:04 PM",
+	"2/Jan/06 3:04 PM",
+	"02/Jan/06 15:04",
+	"02/Jan/06",
+}
+
+// parseImportTime parses a date in any of importTimeLayouts.
+func parseImportTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *importRun) warn(message string) {
+	if !r.warned[message] {
+		r.warned[message] = true
+		r.report.Warnings = append(r.report.Warnings, message)
+	}
+}
+
+// readImportMapping reads a key,id mapping table; a missing file is empty.
+func readImportMapping(path string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return mapping, nil
+		}
+		return nil, &core.IOError{Operation: "read", FilePath: path, Cause: err}
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid mapping table %s: %w", path, err)
+	}
+	for i, record := range records {
+		if i == 0 || len(record) < 2 {
+			continue // header
+		}
+		mapping[record[0]] = record[1]
+	}
+	return mapping, nil
+}
+
+// appendImportMapping appends key,id rows to a mapping table, writing the
+// header when the file is new.
+func appendImportMapping(path string, rows [][2]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: filepath.Dir(path), Cause: err}
+	}
+	_, statErr := os.Stat(path)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return &core.IOError{Operation: "write", FilePath: path, Cause: err}
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if errors.Is(statErr, os.ErrNotExist) {
+		w.Write([]string{"key", "id"})
+	}
+	for _, row := range rows {
+		w.Write(row[:])
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return &core.IOError{Operation: "write", FilePath: path, Cause: err}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// trelloBoard is the subset of a Trello board export ("Print and export" >
+// "Export as JSON") read by ParseTrelloBoard.
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID               string   `json:"id"`
+		ShortLink        string   `json:"shortLink"`
+		Name             string   `json:"name"`
+		Desc             string   `json:"desc"`
+		IDList           string   `json:"idList"`
+		IDMembers        []string `json:"idMembers"`
+		Closed           bool     `json:"closed"`
+		Due              *string  `json:"due"`
+		DateLastActivity string   `json:"dateLastActivity"`
+		Labels           []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+		Attachments      []json.RawMessage `json:"attachments"`
+		CustomFieldItems []struct {
+			IDCustomField string            `json:"idCustomField"`
+			IDValue       string            `json:"idValue"`
+			Value         map[string]string `json:"value"`
+		} `json:"customFieldItems"`
+	} `json:"cards"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+	Checklists []struct {
+		IDCard     string  `json:"idCard"`
+		Name       string  `json:"name"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+	CustomFields []struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Options []struct {
+			ID    string            `json:"id"`
+			Value map[string]string `json:"value"`
+		} `json:"options"`
+	} `json:"customFields"`
+	Actions []struct {
+		Type string `json:"type"`
+		Data struct {
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+	} `json:"actions"`
+}
+
+// ParseTrelloBoard reads a Trello board export. Lists become statuses, the
+// first member becomes the assignee, and checklists are appended to the
+// description as Markdown task lists. Archived cards and cards in archived
+// lists are skipped.
+func ParseTrelloBoard(r io.Reader) (*ImportBatch, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, fmt.Errorf("%w: invalid Trello board JSON: %v", ErrInvalidInput, err)
+	}
+	if board.Lists == nil || board.Cards == nil {
+		return nil, fmt.Errorf("%w: not a Trello board export: lists and cards are required", ErrInvalidInput)
+	}
+
+	lists := make(map[string]string)
+	closedLists := make(map[string]bool)
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+		closedLists[list.ID] = list.Closed
+	}
+	members := make(map[string]string)
+	for _, member := range board.Members {
+		members[member.ID] = member.Username
+	}
+	customFields := make(map[string]string)
+	options := make(map[string]string)
+	for _, field := range board.CustomFields {
+		customFields[field.ID] = field.Name
+		for _, option := range field.Options {
+			options[option.ID] = option.Value["text"]
+		}
+	}
+	comments := make(map[string]int)
+	for _, action := range board.Actions {
+		if action.Type == "commentCard" {
+			comments[action.Data.Card.ID]++
+		}
+	}
+
+	batch := &ImportBatch{Source: "trello"}
+	for _, card := range board.Cards {
+		if card.Closed || closedLists[card.IDList] {
+			batch.Skipped = append(batch.Skipped, ImportSkip{Key: card.ShortLink, Title: card.Name, Reason: "archived"})
+			continue
+		}
+
+		item := ImportItem{
+			Key:    card.ShortLink,
+			Title:  card.Name,
+			Status: lists[card.IDList],
+			Fields: make(map[string][]string),
+		}
+		if item.Key == "" {
+			item.Key = card.ID
+		}
+		// Trello IDs start with the creation time in hex seconds
+		if len(card.ID) >= 8 {
+			if secs, err := strconv.ParseInt(card.ID[:8], 16, 64); err == nil {
+				created := time.Unix(secs, 0)
+				item.Created = &created
+			}
+		}
+		if t, ok := parseImportTime(card.DateLastActivity); ok {
+			item.Updated = &t
+		}
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				item.Labels = append(item.Labels, label.Name)
+			} else if label.Color != "" {
+				item.Labels = append(item.Labels, label.Color)
+			}
+		}
+		for i, id := range card.IDMembers {
+			name := members[id]
+			if name == "" {
+				name = id
+			}
+			if i == 0 {
+				item.Assignee = name
+			} else {
+				item.Fields["Members"] = append(item.Fields["Members"], name)
+			}
+		}
+
+		body := strings.TrimSpace(card.Desc)
+		checklists := board.Checklists[:0:0]
+		for _, checklist := range board.Checklists {
+			if checklist.IDCard == card.ID {
+				checklists = append(checklists, checklist)
+			}
+		}
+		sort.SliceStable(checklists, func(i, j int) bool { return checklists[i].Pos < checklists[j].Pos })
+		for _, checklist := range checklists {
+			sort.SliceStable(checklist.CheckItems, func(i, j int) bool { return checklist.CheckItems[i].Pos < checklist.CheckItems[j].Pos })
+			var lines []string
+			for _, check := range checklist.CheckItems {
+				box := "[ ]"
+				if check.State == "complete" {
+					box = "[x]"
+				}
+				lines = append(lines, fmt.Sprintf("- %s %s", box, check.Name))
+			}
+			section := "## " + checklist.Name + "\n\n" + strings.Join(lines, "\n")
+			if body != "" {
+				body += "\n\n"
+			}
+			body += section
+		}
+		item.Body = body
+
+		if card.Due != nil && *card.Due != "" {
+			item.Fields["Due"] = []string{*card.Due}
+		}
+		for _, cf := range card.CustomFieldItems {
+			name := customFields[cf.IDCustomField]
+			if name == "" {
+				continue
+			}
+			value := options[cf.IDValue]
+			for _, kind := range []string{"text", "number", "date", "checked"} {
+				if v, ok := cf.Value[kind]; ok && value == "" {
+					value = v
+				}
+			}
+			if value != "" {
+				item.Fields[name] = []string{value}
+			}
+		}
+		if len(card.Attachments) > 0 {
+			item.Fields["Attachments"] = []string{strconv.Itoa(len(card.Attachments))}
+		}
+		if n := comments[card.ID]; n > 0 {
+			item.Fields["Comments"] = []string{strconv.Itoa(n)}
+		}
+		batch.Items = append(batch.Items, item)
+	}
+	return batch, nil
+}
@@ -0,0 +1,570 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// IssueSyncStateDir holds the sync baselines of each tracker, relative to the
+// repository root.
+const IssueSyncStateDir = ".gitta/sync"
+
+// externalKey is the frontmatter map linking a story to tracker issues, e.g.
+// external: {github: 123}.
+const externalKey = "external"
+
+// IssueSyncService synchronizes stories with the issues of an external tracker
+// in both directions.
+type IssueSyncService interface {
+	// Sync links every story to an issue, creating issues and stories as
+	// needed, and copies changes made on either side to the other.
+	Sync(ctx context.Context, opts IssueSyncOptions) (*IssueSyncReport, error)
+}
+
+// IssueSyncOptions controls a sync.
+type IssueSyncOptions struct {
+	// DryRun reports the changes without applying them.
+	DryRun bool
+	// Prefer resolves conflicts: "local" keeps the story, "remote" keeps the
+	// issue. Empty leaves conflicts unresolved.
+	Prefer string
+}
+
+// IssueSyncAction is what a sync does to one story/issue pair.
+type IssueSyncAction string
+
+const (
+	// SyncCreateIssue creates an issue for a story that has none.
+	SyncCreateIssue IssueSyncAction = "create-issue"
+	// SyncCreateStory creates a backlog story for an issue that has none.
+	SyncCreateStory IssueSyncAction = "create-story"
+	// SyncPush copies story changes to the issue.
+	SyncPush IssueSyncAction = "push"
+	// SyncPull copies issue changes to the story.
+	SyncPull IssueSyncAction = "pull"
+	// SyncConflict marks a pair changed on both sides; nothing is copied.
+	SyncConflict IssueSyncAction = "conflict"
+	// SyncMissing marks a story linked to an issue the tracker does not list.
+	SyncMissing IssueSyncAction = "missing"
+)
+
+// IssueSyncChange is one action of a sync.
+type IssueSyncChange struct {
+	Action IssueSyncAction
+	// StoryID is empty for a story a dry run would create.
+	StoryID string
+	// Issue is 0 for an issue a dry run would create.
+	Issue int
+	Title string
+	// Fields lists the differing attributes (title, body, labels, assignee,
+	// state) for pushes, pulls and conflicts.
+	Fields []string
+	// Detail explains conflicts and missing issues.
+	Detail string
+}
+
+// IssueSyncReport lists the changes of a sync in story ID, then issue number,
+// order.
+type IssueSyncReport struct {
+	Tracker string
+	DryRun  bool
+	Changes []IssueSyncChange
+	// Unchanged counts linked pairs that were already in sync.
+	Unchanged int
+}
+
+// Count returns the number of changes with the given action.
+func (r *IssueSyncReport) Count(action IssueSyncAction) int {
+	n := 0
+	for _, change := range r.Changes {
+		if change.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// syncView holds the attributes shared by a story and its issue.
+type syncView struct {
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Labels   []string `json:"labels"`
+	Assignee string   `json:"assignee"`
+	Closed   bool     `json:"closed"`
+}
+
+// hash fingerprints the view for the sync baseline.
+func (v syncView) hash() string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// diff lists the attributes that differ between two views.
+func (v syncView) diff(other syncView) []string {
+	var fields []string
+	add := func(differ bool, name string) {
+		if differ {
+			fields = append(fields, name)
+		}
+	}
+	add(v.Title != other.Title, "title")
+	add(v.Body != other.Body, "body")
+	add(strings.Join(v.Labels, "\x00") != strings.Join(other.Labels, "\x00"), "labels")
+	add(v.Assignee != other.Assignee, "assignee")
+	add(v.Closed != other.Closed, "state")
+	return fields
+}
+
+// issueSyncState is the file recording each linked pair as of its last sync.
+type issueSyncState struct {
+	Issues map[string]issueSyncBaseline `json:"issues"`
+}
+
+// issueSyncBaseline records a pair as of its last sync: the issue's
+// updated_at and a hash of the shared attributes.
+type issueSyncBaseline struct {
+	Story          string    `json:"story"`
+	IssueUpdatedAt time.Time `json:"issue_updated_at"`
+	Hash           string    `json:"hash"`
+}
+
+type issueSyncService struct {
+	tracker  core.IssueTracker
+	board    BoardService
+	edit     StoryEditService
+	fields   []core.FieldDefinition
+	repoPath string
+	workflow core.Workflow
+}
+
+// NewIssueSyncService creates an IssueSyncService. Stories are changed through
+// edit, so they are committed when edit commits.
+func NewIssueSyncService(
+	tracker core.IssueTracker,
+	board BoardService,
+	edit StoryEditService,
+	fields []core.FieldDefinition,
+	repoPath string,
+	workflow core.Workflow,
+) IssueSyncService {
+	return &issueSyncService{
+		tracker:  tracker,
+		board:    board,
+		edit:     edit,
+		fields:   fields,
+		repoPath: repoPath,
+		workflow: workflow,
+	}
+}
+
+// Sync implements IssueSyncService.Sync.
+//
+// A linked pair that differs is pushed when only the story changed since the
+// last sync and pulled when only the issue changed. The story side is compared
+// with the recorded hash, since story files are often edited by hand without
+// touching updated_at; the issue side must also have a newer updated_at, so
+// comments and labels that are not tags do not count as changes. Pairs
+// changed on both sides, or never synced before, are conflicts unless
+// opts.Prefer picks a side.
+func (s *issueSyncService) Sync(ctx context.Context, opts IssueSyncOptions) (report *IssueSyncReport, err error) {
+	if opts.Prefer != "" && opts.Prefer != "local" && opts.Prefer != "remote" {
+		return nil, fmt.Errorf("%w: prefer must be local or remote, got %q", ErrInvalidInput, opts.Prefer)
+	}
+
+	issues, err := s.tracker.ListIssues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Number < issues[j].Number })
+	byNumber := make(map[int]core.Issue, len(issues))
+	for _, issue := range issues {
+		byNumber[issue.Number] = issue
+	}
+
+	snapshot, err := s.board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := snapshot.Filter(StoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+	if !opts.DryRun {
+		// Keep the baselines of the changes applied before a failure
+		defer func() {
+			if saveErr := s.saveState(state); saveErr != nil && err == nil {
+				err = saveErr
+			}
+		}()
+	}
+
+	report = &IssueSyncReport{Tracker: s.tracker.Name(), DryRun: opts.DryRun}
+	linked := make(map[int]string)
+	for _, story := range stories {
+		number, ok := externalRef(story.Story, s.tracker.Name())
+		if !ok {
+			if err := s.createIssue(ctx, story, opts, state, report); err != nil {
+				return report, err
+			}
+			continue
+		}
+		if other, dup := linked[number]; dup {
+			report.Changes = append(report.Changes, IssueSyncChange{
+				Action: SyncConflict, StoryID: story.Story.ID, Issue: number, Title: story.Story.Title,
+				Detail: fmt.Sprintf("issue #%d is already linked to %s", number, other),
+			})
+			continue
+		}
+		linked[number] = story.Story.ID
+
+		issue, found := byNumber[number]
+		if !found {
+			report.Changes = append(report.Changes, IssueSyncChange{
+				Action: SyncMissing, StoryID: story.Story.ID, Issue: number, Title: story.Story.Title,
+				Detail: fmt.Sprintf("issue #%d not found", number),
+			})
+			continue
+		}
+		if err := s.syncPair(ctx, story, issue, opts, state, report); err != nil {
+			return report, err
+		}
+	}
+
+	for _, issue := range issues {
+		if _, ok := linked[issue.Number]; ok {
+			continue
+		}
+		if err := s.createStory(ctx, issue, opts, state, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// syncPair reconciles a story with its linked issue.
+func (s *issueSyncService) syncPair(ctx context.Context, story *StoryWithStatus, issue core.Issue, opts IssueSyncOptions, state *issueSyncState, report *IssueSyncReport) error {
+	local, remote := s.storyView(story), issueView(issue)
+	key := strconv.Itoa(issue.Number)
+	fields := local.diff(remote)
+	if len(fields) == 0 {
+		report.Unchanged++
+		if !opts.DryRun {
+			state.Issues[key] = issueSyncBaseline{Story: story.Story.ID, IssueUpdatedAt: issue.UpdatedAt, Hash: local.hash()}
+		}
+		return nil
+	}
+
+	change := IssueSyncChange{StoryID: story.Story.ID, Issue: issue.Number, Title: story.Story.Title, Fields: fields}
+	baseline, synced := state.Issues[key]
+	localChanged := !synced || local.hash() != baseline.Hash
+	remoteChanged := !synced || (issue.UpdatedAt.After(baseline.IssueUpdatedAt) && remote.hash() != baseline.Hash)
+	switch {
+	case localChanged && remoteChanged && opts.Prefer == "":
+		change.Action = SyncConflict
+		change.Detail = conflictDetail(story.Story, issue, synced)
+		report.Changes = append(report.Changes, change)
+		return nil
+	case localChanged && (!remoteChanged || opts.Prefer == "local"):
+		change.Action = SyncPush
+	default:
+		change.Action = SyncPull
+	}
+	report.Changes = append(report.Changes, change)
+	if opts.DryRun {
+		return nil
+	}
+
+	if change.Action == SyncPush {
+		pushed := s.storyIssue(story, local, issue.Labels)
+		pushed.Number = issue.Number
+		updated, err := s.tracker.UpdateIssue(ctx, pushed)
+		if err != nil {
+			return fmt.Errorf("failed to update issue #%d: %w", issue.Number, err)
+		}
+		state.Issues[key] = issueSyncBaseline{Story: story.Story.ID, IssueUpdatedAt: updated.UpdatedAt, Hash: local.hash()}
+		return nil
+	}
+
+	if _, err := s.edit.UpdateStory(ctx, story.Story.ID, s.pullUpdate(story, local, remote)); err != nil {
+		return fmt.Errorf("failed to update %s from issue #%d: %w", story.Story.ID, issue.Number, err)
+	}
+	state.Issues[key] = issueSyncBaseline{Story: story.Story.ID, IssueUpdatedAt: issue.UpdatedAt, Hash: remote.hash()}
+	return nil
+}
+
+// createIssue creates the issue of an unlinked story and links it.
+func (s *issueSyncService) createIssue(ctx context.Context, story *StoryWithStatus, opts IssueSyncOptions, state *issueSyncState, report *IssueSyncReport) error {
+	change := IssueSyncChange{Action: SyncCreateIssue, StoryID: story.Story.ID, Title: story.Story.Title}
+	if opts.DryRun {
+		report.Changes = append(report.Changes, change)
+		return nil
+	}
+
+	local := s.storyView(story)
+	created, err := s.tracker.CreateIssue(ctx, s.storyIssue(story, local, nil))
+	if err != nil {
+		return fmt.Errorf("failed to create issue for %s: %w", story.Story.ID, err)
+	}
+	change.Issue = created.Number
+	report.Changes = append(report.Changes, change)
+	state.Issues[strconv.Itoa(created.Number)] = issueSyncBaseline{Story: story.Story.ID, IssueUpdatedAt: created.UpdatedAt, Hash: local.hash()}
+
+	update := StoryUpdate{Fields: map[string]interface{}{externalKey: s.externalRefs(story.Story, created.Number)}}
+	if _, err := s.edit.UpdateStory(ctx, story.Story.ID, update); err != nil {
+		return fmt.Errorf("failed to link %s to issue #%d: %w", story.Story.ID, created.Number, err)
+	}
+	return nil
+}
+
+// createStory creates a backlog story for an unlinked issue.
+func (s *issueSyncService) createStory(ctx context.Context, issue core.Issue, opts IssueSyncOptions, state *issueSyncState, report *IssueSyncReport) error {
+	change := IssueSyncChange{Action: SyncCreateStory, Issue: issue.Number, Title: issue.Title}
+	if opts.DryRun {
+		report.Changes = append(report.Changes, change)
+		return nil
+	}
+
+	remote := issueView(issue)
+	req := CreateStoryRequest{
+		Title:            issue.Title,
+		Tags:             remote.Labels,
+		Assignee:         issue.Assignee,
+		Fields:           map[string]interface{}{externalKey: map[string]interface{}{s.tracker.Name(): issue.Number}},
+		FieldDefinitions: s.fields,
+	}
+	if issue.Closed {
+		req.Status = s.doneStatus()
+	}
+	body := syncBody(remote.Body)
+	created, err := s.edit.CreateStory(ctx, req, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create story for issue #%d: %w", issue.Number, err)
+	}
+	change.StoryID = created.ID
+	report.Changes = append(report.Changes, change)
+	state.Issues[strconv.Itoa(issue.Number)] = issueSyncBaseline{Story: created.ID, IssueUpdatedAt: issue.UpdatedAt, Hash: remote.hash()}
+	return nil
+}
+
+// storyView returns the shared attributes of a story; its state follows the
+// effective (possibly Git-derived) status.
+func (s *issueSyncService) storyView(story *StoryWithStatus) syncView {
+	view := syncView{
+		Title:  story.Story.Title,
+		Body:   normalizeSyncBody(story.Story.Body),
+		Labels: sortedCopy(story.Story.Tags),
+		Closed: s.workflow.IsDone(story.Status),
+	}
+	if story.Story.Assignee != nil {
+		view.Assignee = *story.Story.Assignee
+	}
+	return view
+}
+
+// issueView returns the shared attributes of an issue. Labels that are not
+// valid story tags (e.g., "good first issue") are not synced.
+func issueView(issue core.Issue) syncView {
+	view := syncView{
+		Title:  issue.Title,
+		Body:   normalizeSyncBody(issue.Body),
+		Labels: []string{},
+		Closed: issue.Closed,
+	}
+	for _, label := range issue.Labels {
+		if isSyncTag(label) {
+			view.Labels = append(view.Labels, label)
+		}
+	}
+	sort.Strings(view.Labels)
+	if issue.Assignee != nil {
+		view.Assignee = *issue.Assignee
+	}
+	return view
+}
+
+// storyIssue builds the issue pushed for a story, keeping the existing
+// labels that cannot be story tags.
+func (s *issueSyncService) storyIssue(story *StoryWithStatus, local syncView, existingLabels []string) core.Issue {
+	issue := core.Issue{
+		Title:  local.Title,
+		Body:   local.Body,
+		Labels: append([]string{}, local.Labels...),
+		Closed: local.Closed,
+	}
+	for _, label := range existingLabels {
+		if !isSyncTag(label) {
+			issue.Labels = append(issue.Labels, label)
+		}
+	}
+	if local.Assignee != "" {
+		assignee := local.Assignee
+		issue.Assignee = &assignee
+	}
+	return issue
+}
+
+// pullUpdate builds the story update copying the differing issue attributes.
+// Closing an issue moves the story to the first done state; reopening it moves
+// the story to the workflow's initial state.
+func (s *issueSyncService) pullUpdate(story *StoryWithStatus, local, remote syncView) StoryUpdate {
+	update := StoryUpdate{Force: true}
+	if local.Title != remote.Title {
+		update.Title = &remote.Title
+	}
+	if local.Body != remote.Body {
+		body := syncBody(remote.Body)
+		update.Body = &body
+	}
+	if strings.Join(local.Labels, "\x00") != strings.Join(remote.Labels, "\x00") {
+		tags := append([]string{}, remote.Labels...)
+		update.Tags = &tags
+	}
+	if local.Assignee != remote.Assignee {
+		update.Assignee = &remote.Assignee
+	}
+	if local.Closed != remote.Closed {
+		status := s.workflow.Initial
+		if remote.Closed {
+			status = s.doneStatus()
+		}
+		update.Status = &status
+	}
+	return update
+}
+
+// doneStatus returns the first done state of the workflow.
+func (s *issueSyncService) doneStatus() core.Status {
+	if done := s.workflow.StatesIn(core.CategoryDone); len(done) > 0 {
+		return done[0].Name
+	}
+	return core.StatusDone
+}
+
+// externalRefs returns the story's external map with the tracker's issue set.
+func (s *issueSyncService) externalRefs(story *core.Story, number int) map[string]interface{} {
+	refs := make(map[string]interface{})
+	if existing, ok := story.Extra[externalKey].(map[string]interface{}); ok {
+		for name, value := range existing {
+			refs[name] = value
+		}
+	}
+	refs[s.tracker.Name()] = number
+	return refs
+}
+
+func (s *issueSyncService) statePath() string {
+	return filepath.Join(s.repoPath, IssueSyncStateDir, s.tracker.Name()+".json")
+}
+
+// loadState reads the sync baselines; a missing file yields an empty state.
+func (s *issueSyncService) loadState() (*issueSyncState, error) {
+	state := &issueSyncState{Issues: make(map[string]issueSyncBaseline)}
+	data, err := os.ReadFile(s.statePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, &core.IOError{Operation: "read", FilePath: s.statePath(), Cause: err}
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", s.statePath(), err)
+	}
+	if state.Issues == nil {
+		state.Issues = make(map[string]issueSyncBaseline)
+	}
+	return state, nil
+}
+
+// saveState atomically writes the sync baselines.
+func (s *issueSyncService) saveState(state *issueSyncState) error {
+	path := s.statePath()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &core.IOError{Operation: "create", FilePath: filepath.Dir(path), Cause: err}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return &core.IOError{Operation: "write", FilePath: path, Cause: err}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return &core.IOError{Operation: "write", FilePath: path, Cause: err}
+	}
+	return nil
+}
+
+// externalRef returns the issue number linked to a story for a tracker.
+func externalRef(story *core.Story, tracker string) (int, bool) {
+	refs, ok := story.Extra[externalKey].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	switch n := refs[tracker].(type) {
+	case int:
+		return n, n > 0
+	case int64:
+		return int(n), n > 0
+	case uint64:
+		return int(n), n > 0
+	case float64:
+		return int(n), n > 0 && n == float64(int(n))
+	}
+	return 0, false
+}
+
+func conflictDetail(story *core.Story, issue core.Issue, synced bool) string {
+	if !synced {
+		return "never synced; both sides differ"
+	}
+	storyUpdated := "unknown"
+	if story.UpdatedAt != nil {
+		storyUpdated = story.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("changed on both sides (story updated %s, issue updated %s)", storyUpdated, issue.UpdatedAt.UTC().Format(time.RFC3339))
+}
+
+// normalizeSyncBody trims a body and normalizes line endings; the issue
+// editor stores CRLF.
+func normalizeSyncBody(body string) string {
+	return strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n"))
+}
+
+// syncBody returns a normalized body as written to story files.
+func syncBody(body string) string {
+	if body == "" {
+		return ""
+	}
+	return body + "\n"
+}
+
+// isSyncTag reports whether an issue label can be a story tag.
+func isSyncTag(label string) bool {
+	return len(label) <= 30 && tagPattern.MatchString(label)
+}
+
+func sortedCopy(values []string) []string {
+	out := append([]string{}, values...)
+	sort.Strings(out)
+	return out
+}
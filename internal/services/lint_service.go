@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// LintSeverity is the severity level of a lint diagnostic.
+type LintSeverity string
+
+const (
+	// SeverityError marks a diagnostic that fails the lint run.
+	SeverityError LintSeverity = "error"
+	// SeverityWarning marks a diagnostic that is reported but does not fail the run.
+	SeverityWarning LintSeverity = "warning"
+)
+
+// Lint rule identifiers that are not derived from story validation rules.
+const (
+	LintRuleIO          = "io"
+	LintRuleFrontmatter = "frontmatter"
+	LintRuleYAML        = "yaml"
+	LintRuleParse       = "parse"
+	LintRuleDuplicateID = "duplicate-id"
+)
+
+// LintDiagnostic is a single problem found in a story file, located by file and line.
+type LintDiagnostic struct {
+	// File is the story path relative to the repository root (slash separated).
+	File string `json:"file"`
+	// Line is the 1-based line number; 0 when the problem has no specific line.
+	Line     int          `json:"line"`
+	Severity LintSeverity `json:"severity"`
+	// Rule identifies the check, e.g. "id/format" or "yaml".
+	Rule    string `json:"rule"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// LintReport is the result of linting a set of story files.
+type LintReport struct {
+	FilesChecked int              `json:"files_checked"`
+	Diagnostics  []LintDiagnostic `json:"diagnostics"`
+}
+
+// ErrorCount returns the number of error-level diagnostics in the report.
+func (r *LintReport) ErrorCount() int {
+	count := 0
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+// LintService validates story files and reports located diagnostics.
+type LintService interface {
+	// LintStories checks the given files or directories. When paths is empty,
+	// the backlog and every sprint directory of the workspace are checked.
+	LintStories(ctx context.Context, paths []string) (*LintReport, error)
+	// LintContent checks the content of a single story file, e.g. an unsaved
+	// editor buffer. Cross-file checks such as duplicate IDs are not run.
+	LintContent(ctx context.Context, file string, content []byte) []LintDiagnostic
+}
+
+type lintService struct {
+	parser   core.StoryParser
+	repoPath string
+}
+
+// NewLintService creates a new LintService instance.
+func NewLintService(parser core.StoryParser, repoPath string) LintService {
+	return &lintService{
+		parser:   parser,
+		repoPath: repoPath,
+	}
+}
+
+// yamlLinePattern extracts the line number prefix from yaml.v3 error messages.
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// LintStories implements LintService.LintStories.
+func (s *lintService) LintStories(ctx context.Context, paths []string) (*LintReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	files, err := resolveStoryFiles(ctx, s.repoPath, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LintReport{Diagnostics: []LintDiagnostic{}}
+	filesByID := make(map[string][]string)
+
+	for _, file := range files {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		report.FilesChecked++
+		diags, id := s.lintFile(ctx, file)
+		report.Diagnostics = append(report.Diagnostics, diags...)
+		if id != "" {
+			filesByID[id] = append(filesByID[id], file)
+		}
+	}
+
+	for id, dupes := range filesByID {
+		if len(dupes) < 2 {
+			continue
+		}
+		for _, file := range dupes {
+			report.Diagnostics = append(report.Diagnostics, LintDiagnostic{
+				File:     s.relPath(file),
+				Line:     s.fieldLine(file, "id"),
+				Severity: SeverityError,
+				Rule:     LintRuleDuplicateID,
+				Field:    "id",
+				Message:  fmt.Sprintf("story ID %s is used by %d files", id, len(dupes)),
+			})
+		}
+	}
+
+	sort.SliceStable(report.Diagnostics, func(i, j int) bool {
+		a, b := report.Diagnostics[i], report.Diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	return report, nil
+}
+
+// LintContent implements LintService.LintContent.
+func (s *lintService) LintContent(ctx context.Context, file string, content []byte) []LintDiagnostic {
+	diags, _ := s.lintContent(ctx, file, content)
+	if diags == nil {
+		diags = []LintDiagnostic{}
+	}
+	return diags
+}
+
+// lintFile checks one story file and returns its diagnostics together with the
+// story ID when the file parsed far enough to have one.
+func (s *lintService) lintFile(ctx context.Context, file string) ([]LintDiagnostic, string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return []LintDiagnostic{{
+			File: s.relPath(file), Severity: SeverityError, Rule: LintRuleIO,
+			Message: fmt.Sprintf("cannot read file: %v", err),
+		}}, ""
+	}
+	return s.lintContent(ctx, file, data)
+}
+
+// lintContent checks the content of one story file; see lintFile.
+func (s *lintService) lintContent(ctx context.Context, file string, data []byte) ([]LintDiagnostic, string) {
+	rel := s.relPath(file)
+	diag := func(line int, rule, field, message string) LintDiagnostic {
+		return LintDiagnostic{File: rel, Line: line, Severity: SeverityError, Rule: rule, Field: field, Message: message}
+	}
+
+	frontmatter, ok := splitFrontmatter(string(data))
+	if !ok {
+		return []LintDiagnostic{diag(1, LintRuleFrontmatter, "", "file must start with a YAML frontmatter block delimited by ---")}, ""
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		line, message := 1, strings.TrimPrefix(err.Error(), "yaml: ")
+		if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			line = n + 1 // frontmatter starts after the opening delimiter
+			message = err.Error()[len(m[0]):]
+		}
+		return []LintDiagnostic{diag(line, LintRuleYAML, "", message)}, ""
+	}
+	keyLines := frontmatterKeyLines(&doc)
+
+	story, err := s.parser.ParseStory(ctx, file, data)
+	if err != nil {
+		var parseErr *core.ParseError
+		if errors.As(err, &parseErr) {
+			return []LintDiagnostic{diag(parseErr.Line, LintRuleParse, "", parseErr.Message)}, ""
+		}
+		return []LintDiagnostic{diag(0, LintRuleParse, "", err.Error())}, ""
+	}
+
+	var diags []LintDiagnostic
+	for _, v := range s.parser.ValidateStory(story) {
+		line, ok := keyLines[v.Field]
+		if !ok {
+			line = 1
+		}
+		diags = append(diags, diag(line, v.Field+"/"+v.Rule, v.Field, v.Message))
+	}
+
+	return diags, story.ID
+}
+
+// relPath returns file relative to the repository root for display.
+func (s *lintService) relPath(file string) string {
+	return relativeTo(s.repoPath, file)
+}
+
+// fieldLine returns the line of a top-level frontmatter key, or 1 when unknown.
+func (s *lintService) fieldLine(file, field string) int {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 1
+	}
+	frontmatter, ok := splitFrontmatter(string(data))
+	if !ok {
+		return 1
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		return 1
+	}
+	if line, ok := frontmatterKeyLines(&doc)[field]; ok {
+		return line
+	}
+	return 1
+}
+
+// splitFrontmatter returns the YAML between the opening and closing --- delimiters.
+// The second result is false when the content has no complete frontmatter block.
+func splitFrontmatter(content string) (string, bool) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "", false
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[1:i], "\n"), true
+		}
+	}
+	return "", false
+}
+
+// frontmatterKeyLines maps each top-level frontmatter key to its file line number.
+func frontmatterKeyLines(doc *yaml.Node) map[string]int {
+	lines := make(map[string]int)
+	if doc == nil || len(doc.Content) == 0 {
+		return lines
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return lines
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		lines[key.Value] = key.Line + 1 // offset for the opening delimiter
+	}
+	return lines
+}
+
+// resolveStoryFiles expands paths into a sorted list of Markdown story files.
+// Directories are walked recursively, skipping hidden entries. With no paths,
+// the workspace backlog and sprint directories are used.
+func resolveStoryFiles(ctx context.Context, repoPath string, paths []string) ([]string, error) {
+	explicit := len(paths) > 0
+	if !explicit {
+		ws, err := resolveWorkspacePaths(ctx, repoPath)
+		if err != nil {
+			return nil, err
+		}
+		paths = []string{ws.BacklogPath, ws.SprintsPath}
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(repoPath, p)
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && !explicit {
+				// Optional workspace directories may be missing.
+				continue
+			}
+			return nil, &core.IOError{Operation: "read", FilePath: p, Cause: err}
+		}
+		if !info.IsDir() {
+			add(p)
+			continue
+		}
+
+		err = filepath.WalkDir(p, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if path != p && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".md") {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, &core.IOError{Operation: "read", FilePath: p, Cause: err}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
@@ -16,6 +16,9 @@ type ListService interface {
 	ListAllTasks(ctx context.Context, repoPath string) ([]*StoryWithStatus, []*StoryWithStatus, error)
 	// ListStories lists stories matching the given filter criteria.
 	ListStories(ctx context.Context, repoPath string, filter Filter) ([]*StoryWithStatus, error)
+	// ListDrift returns Sprint and backlog stories whose explicit status
+	// disagrees with the status derived from Git.
+	ListDrift(ctx context.Context, repoPath string) ([]*StoryWithStatus, error)
 }
 
 // Filter represents filtering criteria for story lists.
@@ -24,6 +27,9 @@ type Filter struct {
 	Priorities []core.Priority
 	Assignees  []string
 	Tags       []string
+	// Fields filters on custom field values keyed by field name. Values are
+	// compared in their text form (see FormatFieldValue).
+	Fields map[string][]string
 }
 
 type listService struct {
@@ -37,13 +43,27 @@ type StoryWithStatus struct {
 	Story  *core.Story
 	Status core.Status
 	Source string
+	// Derived is the status derived from Git alone. It differs from Status
+	// when an explicit Frontmatter status overrides Git (see Drifted).
+	Derived core.Status
+}
+
+// Drifted reports whether the story's explicit status disagrees with Git.
+func (s *StoryWithStatus) Drifted() bool {
+	return s.Derived != "" && s.Derived != s.Status
 }
 
 // NewListService constructs a ListService with the provided dependencies.
 func NewListService(storyRepo core.StoryRepository, gitRepo core.GitRepository) ListService {
+	return NewListServiceWithWorkflow(storyRepo, gitRepo, core.DefaultWorkflow())
+}
+
+// NewListServiceWithWorkflow constructs a ListService that derives statuses of
+// the given workflow.
+func NewListServiceWithWorkflow(storyRepo core.StoryRepository, gitRepo core.GitRepository, workflow core.Workflow) ListService {
 	return &listService{
 		storyRepo:    storyRepo,
-		statusEngine: NewStatusEngineWithRepository(gitRepo),
+		statusEngine: NewStatusEngineWithWorkflow(gitRepo, workflow),
 		gitRepo:      gitRepo,
 	}
 }
@@ -68,12 +88,13 @@ func (s *listService) ListSprintTasks(ctx context.Context, repoPath string) ([]*
 		return nil, fmt.Errorf("failed to list Sprint stories: %w", err)
 	}
 
-	if err := s.deriveStatuses(ctx, repoPath, stories); err != nil {
+	derived, err := s.deriveStatuses(ctx, repoPath, stories)
+	if err != nil {
 		return nil, err
 	}
 
 	sortStories(stories)
-	return toStoryWithStatus(stories, "Sprint"), nil
+	return toStoryWithStatus(stories, "Sprint", derived), nil
 }
 
 func (s *listService) ListAllTasks(ctx context.Context, repoPath string) ([]*StoryWithStatus, []*StoryWithStatus, error) {
@@ -109,10 +130,11 @@ func (s *listService) ListAllTasks(ctx context.Context, repoPath string) ([]*Sto
 	stories = append(stories, backlogStories...)
 
 	if len(stories) == 0 {
-		return toStoryWithStatus(sprintStories, "Sprint"), toStoryWithStatus(backlogStories, "Backlog"), nil
+		return toStoryWithStatus(sprintStories, "Sprint", nil), toStoryWithStatus(backlogStories, "Backlog", nil), nil
 	}
 
-	if err := s.deriveStatuses(ctx, repoPath, stories); err != nil {
+	derived, err := s.deriveStatuses(ctx, repoPath, stories)
+	if err != nil {
 		return nil, nil, err
 	}
 
@@ -120,28 +142,57 @@ func (s *listService) ListAllTasks(ctx context.Context, repoPath string) ([]*Sto
 	sortStories(sprintStories)
 	sortStories(backlogStories)
 
-	return toStoryWithStatus(sprintStories, "Sprint"), toStoryWithStatus(backlogStories, "Backlog"), nil
+	return toStoryWithStatus(sprintStories, "Sprint", derived), toStoryWithStatus(backlogStories, "Backlog", derived), nil
+}
+
+// ListDrift implements ListService.ListDrift.
+func (s *listService) ListDrift(ctx context.Context, repoPath string) ([]*StoryWithStatus, error) {
+	sprintStories, backlogStories, err := s.ListAllTasks(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	drifted := make([]*StoryWithStatus, 0)
+	for _, story := range append(sprintStories, backlogStories...) {
+		if story.Drifted() {
+			drifted = append(drifted, story)
+		}
+	}
+	return drifted, nil
 }
 
-func (s *listService) deriveStatuses(ctx context.Context, repoPath string, stories []*core.Story) error {
+// deriveStatuses sets each story's effective status and returns the statuses
+// derived from Git alone, keyed by story.
+func (s *listService) deriveStatuses(ctx context.Context, repoPath string, stories []*core.Story) (map[*core.Story]core.Status, error) {
 	if len(stories) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	branchList, err := s.gitRepo.GetBranchList(ctx, repoPath)
 	if err != nil {
-		return fmt.Errorf("failed to derive task status: %w", err)
+		return nil, fmt.Errorf("failed to derive task status: %w", err)
 	}
 
 	statuses, err := s.statusEngine.DeriveStatusBatch(ctx, stories, branchList, repoPath)
 	if err != nil {
-		return fmt.Errorf("failed to derive task status: %w", err)
+		return nil, fmt.Errorf("failed to derive task status: %w", err)
 	}
 
+	derived := make(map[*core.Story]core.Status, len(stories))
 	for i, status := range statuses {
-		stories[i].Status = status
+		story := stories[i]
+		derived[story] = status
+		// The explicit status won; derive again without it to detect drift
+		if !story.StatusDefaulted && story.Status == status {
+			gitStatus, err := s.statusEngine.DeriveGitStatus(ctx, story, branchList, repoPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to derive task status: %w", err)
+			}
+			derived[story] = gitStatus
+		}
+		story.Status = status
 	}
-	return nil
+	return derived, nil
 }
 
 func sortStories(stories []*core.Story) {
@@ -150,13 +201,14 @@ func sortStories(stories []*core.Story) {
 	})
 }
 
-func toStoryWithStatus(stories []*core.Story, source string) []*StoryWithStatus {
+func toStoryWithStatus(stories []*core.Story, source string, derived map[*core.Story]core.Status) []*StoryWithStatus {
 	withStatus := make([]*StoryWithStatus, 0, len(stories))
 	for _, story := range stories {
 		withStatus = append(withStatus, &StoryWithStatus{
-			Story:  story,
-			Status: story.Status,
-			Source: source,
+			Story:   story,
+			Status:  story.Status,
+			Source:  source,
+			Derived: derived[story],
 		})
 	}
 	return withStatus
@@ -193,7 +245,8 @@ func (s *listService) ListStories(ctx context.Context, repoPath string, filter F
 	}
 
 	// Derive statuses
-	if err := s.deriveStatuses(ctx, repoPath, allStories); err != nil {
+	derived, err := s.deriveStatuses(ctx, repoPath, allStories)
+	if err != nil {
 		return nil, err
 	}
 
@@ -209,9 +262,10 @@ func (s *listService) ListStories(ctx context.Context, repoPath string, filter F
 		// Determine source (simplified - in production, track source during collection)
 		source := "Sprint" // Default, could be enhanced to track actual source
 		result = append(result, &StoryWithStatus{
-			Story:  story,
-			Status: story.Status,
-			Source: source,
+			Story:   story,
+			Status:  story.Status,
+			Source:  source,
+			Derived: derived[story],
 		})
 	}
 
@@ -297,6 +351,21 @@ func matchesFilter(story *core.Story, filter Filter) bool {
 		}
 	}
 
+	// Custom field filters (OR logic within a field, AND across fields)
+	for name, wants := range filter.Fields {
+		value, _ := story.FieldValue(name)
+		matched := false
+		for _, want := range wants {
+			if MatchFieldValue(value, want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -305,5 +374,6 @@ func isEmptyFilter(filter Filter) bool {
 	return len(filter.Statuses) == 0 &&
 		len(filter.Priorities) == 0 &&
 		len(filter.Assignees) == 0 &&
-		len(filter.Tags) == 0
+		len(filter.Tags) == 0 &&
+		len(filter.Fields) == 0
 }
@@ -214,3 +214,34 @@ func TestListAllTasks_ConsolidatedStructure(t *testing.T) {
 		t.Fatalf("expected sprint and backlog stories, got %d and %d", len(sprintStories), len(backlogStories))
 	}
 }
+
+func TestListDrift_ReportsExplicitStatusesThatDisagreeWithGit(t *testing.T) {
+	repo := &fakeStoryRepo{
+		sprintPath: "sprints/Sprint-01",
+		storyLists: map[string][]*core.Story{
+			"sprints/Sprint-01": {
+				{ID: "US-001", Title: "Stale todo", Status: core.StatusTodo},
+				{ID: "US-002", Title: "In sync", Status: core.StatusDoing},
+				{ID: "US-003", Title: "Derived", Status: core.StatusTodo, StatusDefaulted: true},
+			},
+		},
+		listErrPerPath: map[string]error{},
+	}
+	gitRepo := &fakeGitRepo{branches: []core.Branch{
+		{Name: "feat/US-001", Type: core.BranchTypeLocal},
+		{Name: "feat/US-002", Type: core.BranchTypeLocal},
+		{Name: "feat/US-003", Type: core.BranchTypeLocal},
+	}}
+
+	service := NewListService(repo, gitRepo)
+	drifted, err := service.ListDrift(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(drifted) != 1 {
+		t.Fatalf("expected 1 drifted story, got %d", len(drifted))
+	}
+	if drifted[0].Story.ID != "US-001" || drifted[0].Status != core.StatusTodo || drifted[0].Derived != core.StatusDoing {
+		t.Fatalf("unexpected drift: %s %s vs %s", drifted[0].Story.ID, drifted[0].Status, drifted[0].Derived)
+	}
+}
@@ -17,17 +17,34 @@ type MoveService interface {
 }
 
 type moveService struct {
-	parser    core.StoryParser
-	storyRepo core.StoryRepository
-	repoPath  string
+	parser       core.StoryParser
+	storyRepo    core.StoryRepository
+	repoPath     string
+	events       core.EventBus
+	transactions core.FileTransactions
 }
 
 // NewMoveService creates a new MoveService instance.
 func NewMoveService(parser core.StoryParser, storyRepo core.StoryRepository, repoPath string) MoveService {
+	return NewMoveServiceWithEvents(parser, storyRepo, repoPath, nil)
+}
+
+// NewMoveServiceWithEvents creates a MoveService publishing story.moved to
+// events (nil for none).
+func NewMoveServiceWithEvents(parser core.StoryParser, storyRepo core.StoryRepository, repoPath string, events core.EventBus) MoveService {
+	return NewMoveServiceWithTransactions(parser, storyRepo, repoPath, events, nil)
+}
+
+// NewMoveServiceWithTransactions creates a MoveService that writes the
+// target and removes the source in one transaction of transactions (nil to
+// change files directly).
+func NewMoveServiceWithTransactions(parser core.StoryParser, storyRepo core.StoryRepository, repoPath string, events core.EventBus, transactions core.FileTransactions) MoveService {
 	return &moveService{
-		parser:    parser,
-		storyRepo: storyRepo,
-		repoPath:  repoPath,
+		parser:       parser,
+		storyRepo:    storyRepo,
+		repoPath:     repoPath,
+		events:       eventsOrNop(events),
+		transactions: transactionsOrDirect(transactions),
 	}
 }
 
@@ -43,43 +60,18 @@ func (s *moveService) MoveStory(ctx context.Context, storyID string, targetDir s
 		return fmt.Errorf("invalid target path: %w", err)
 	}
 
-	paths, err := resolveWorkspacePaths(ctx, s.repoPath)
-	if err != nil {
-		return fmt.Errorf("failed to resolve workspace paths: %w", err)
-	}
-
 	// Find story by ID
 	story, sourcePath, err := s.storyRepo.FindStoryByID(ctx, s.repoPath, storyID)
 	if err != nil {
 		if err == core.ErrStoryNotFound {
-			return fmt.Errorf("story not found: %s", storyID)
+			return fmt.Errorf("%w: %s", core.ErrStoryNotFound, storyID)
 		}
 		return fmt.Errorf("failed to find story: %w", err)
 	}
 
-	// Resolve target directory (relative to repo root)
-	targetDirPath := targetDir
-	if !filepath.IsAbs(targetDirPath) {
-		targetDirPath = filepath.Join(s.repoPath, targetDirPath)
-	}
-
-	// Map well-known targets to workspace paths to avoid mixing structures.
-	if targetDir == "backlog" || targetDir == filepath.Clean("tasks/backlog") {
-		targetDirPath = paths.BacklogPath
-	}
-	if strings.HasPrefix(targetDir, "sprints") || strings.HasPrefix(targetDir, "tasks/sprints") {
-		// Preserve sprint subpath if provided.
-		trimmed := strings.TrimPrefix(targetDir, "tasks/")
-		targetDirPath = filepath.Join(paths.SprintsPath, strings.TrimPrefix(trimmed, "sprints/"))
-	}
-
-	// Create target directory if needed
-	if err := os.MkdirAll(targetDirPath, 0755); err != nil {
-		return &core.IOError{
-			Operation: "create",
-			FilePath:  targetDirPath,
-			Cause:     err,
-		}
+	targetDirPath, err := ResolveMoveTarget(ctx, s.repoPath, targetDir)
+	if err != nil {
+		return err
 	}
 
 	// Build target file path
@@ -93,31 +85,40 @@ func (s *moveService) MoveStory(ctx context.Context, storyID string, targetDir s
 		}
 	}
 
-	// Read story content (already have story from FindStoryByID)
-	// Write to temp file at destination
-	tmpTargetPath := targetPath + ".tmp"
-	if err := s.parser.WriteStory(ctx, tmpTargetPath, story); err != nil {
-		return fmt.Errorf("failed to write to target: %w", err)
+	event := core.Event{Type: core.EventStoryMoved, Data: map[string]interface{}{
+		"story": eventStory(story),
+		"from":  sourcePath,
+		"to":    targetPath,
+	}}
+	if err := s.events.Before(ctx, event); err != nil {
+		return err
 	}
 
-	// Rename temp file to final name (atomic)
-	if err := os.Rename(tmpTargetPath, targetPath); err != nil {
-		// Clean up temp file on error
-		os.Remove(tmpTargetPath)
-		return &core.IOError{
-			Operation: "rename",
-			FilePath:  targetPath,
-			Cause:     err,
+	// Write the target and remove the source together, so a failure never
+	// leaves the story in both places
+	tx, err := s.transactions.Begin(ctx, fmt.Sprintf("move %s to %s", storyID, eventPath(s.repoPath, targetDirPath)))
+	if err != nil {
+		return fmt.Errorf("failed to begin move: %w", err)
+	}
+	defer tx.Rollback()
+	if err := tx.Write(targetPath, func(staged string) error {
+		return s.parser.WriteStory(ctx, staged, story)
+	}); err != nil {
+		return fmt.Errorf("failed to write to target: %w", err)
+	}
+	if targetPath != sourcePath {
+		if err := tx.Remove(sourcePath); err != nil {
+			return fmt.Errorf("failed to remove source file: %w", err)
 		}
 	}
-
-	// Remove source file only after successful write
-	if err := os.Remove(sourcePath); err != nil {
-		// If removal fails, we still have the file at target, which is acceptable
-		// Log warning but don't fail the operation
-		return fmt.Errorf("failed to remove source file (target file created): %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to move story: %w", err)
 	}
 
+	event.Data["changes"] = map[string]interface{}{
+		"path": change(eventPath(s.repoPath, sourcePath), eventPath(s.repoPath, targetPath)),
+	}
+	s.events.After(ctx, event)
 	return nil
 }
 
@@ -136,3 +137,30 @@ func validatePath(path string) error {
 
 	return nil
 }
+
+// ResolveMoveTarget returns the directory a story moved to targetDir ends up
+// in. Relative targets are resolved against the repository root, and
+// "backlog" and "sprints/..." map to the workspace's backlog and sprints
+// directories.
+func ResolveMoveTarget(ctx context.Context, repoPath, targetDir string) (string, error) {
+	paths, err := resolveWorkspacePaths(ctx, repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace paths: %w", err)
+	}
+
+	targetDirPath := targetDir
+	if !filepath.IsAbs(targetDirPath) {
+		targetDirPath = filepath.Join(repoPath, targetDirPath)
+	}
+
+	// Map well-known targets to workspace paths to avoid mixing structures.
+	if targetDir == "backlog" || targetDir == filepath.Clean("tasks/backlog") {
+		targetDirPath = paths.BacklogPath
+	}
+	if strings.HasPrefix(targetDir, "sprints") || strings.HasPrefix(targetDir, "tasks/sprints") {
+		// Preserve sprint subpath if provided.
+		trimmed := strings.TrimPrefix(targetDir, "tasks/")
+		targetDirPath = filepath.Join(paths.SprintsPath, strings.TrimPrefix(trimmed, "sprints/"))
+	}
+	return targetDirPath, nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/core/workspace"
+)
+
+const (
+	// PluginPrefix is the file name prefix of plugin executables.
+	PluginPrefix = "gitta-"
+	// PluginDir holds the plugins of a project, relative to the repository root.
+	PluginDir = ".gitta/plugins"
+
+	// PluginSourceProject marks plugins found in PluginDir.
+	PluginSourceProject = "project"
+	// PluginSourcePath marks plugins found on $PATH.
+	PluginSourcePath = "path"
+)
+
+// Plugin is an external subcommand: 'gitta <name>' runs the executable
+// gitta-<name>.
+type Plugin struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Source string `json:"source"`
+	// ShadowedBy is the path of the plugin with the same name that runs
+	// instead of this one, if any.
+	ShadowedBy string `json:"shadowed_by,omitempty"`
+}
+
+// PluginContext describes the workspace to a plugin. It is written as JSON
+// on the first line of the plugin's standard input and as GITTA_*
+// environment variables. Workspace fields are empty outside a repository.
+type PluginContext struct {
+	// Version is the gitta version running the plugin.
+	Version string   `json:"version"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+
+	RepoRoot string `json:"repo_root"`
+	// Structure is "consolidated" (tasks/backlog, tasks/sprints) or "legacy".
+	Structure     string `json:"structure"`
+	BacklogDir    string `json:"backlog_dir"`
+	SprintsDir    string `json:"sprints_dir"`
+	CurrentSprint string `json:"current_sprint"`
+	ConfigFile    string `json:"config_file"`
+	// Config is .gitta/config.yaml as written, or empty.
+	Config map[string]interface{} `json:"config"`
+}
+
+// Env returns the context as environment variables.
+func (c PluginContext) Env() []string {
+	return []string{
+		"GITTA_VERSION=" + c.Version,
+		"GITTA_REPO_ROOT=" + c.RepoRoot,
+		"GITTA_STRUCTURE=" + c.Structure,
+		"GITTA_BACKLOG_DIR=" + c.BacklogDir,
+		"GITTA_SPRINTS_DIR=" + c.SprintsDir,
+		"GITTA_CURRENT_SPRINT=" + c.CurrentSprint,
+		"GITTA_CONFIG=" + c.ConfigFile,
+	}
+}
+
+// PluginService discovers plugins in the project's PluginDir and on $PATH.
+// Project plugins come first, then $PATH in order; the first plugin found
+// for a name runs.
+type PluginService interface {
+	// List returns the plugins sorted by name. Plugins shadowed by an
+	// earlier one with the same name follow it, with ShadowedBy set.
+	List(ctx context.Context) ([]Plugin, error)
+	// Find returns the plugin that runs for name, or nil.
+	Find(ctx context.Context, name string) (*Plugin, error)
+	// Context returns the workspace part of the plugin context.
+	Context(ctx context.Context) (*PluginContext, error)
+}
+
+type pluginService struct {
+	repoPath   string
+	searchPath string
+}
+
+// NewPluginService creates a PluginService for the repository at repoPath
+// (empty outside a repository) searching the directories of searchPath, a
+// $PATH value.
+func NewPluginService(repoPath, searchPath string) PluginService {
+	return &pluginService{repoPath: repoPath, searchPath: searchPath}
+}
+
+// List implements PluginService.List.
+func (s *pluginService) List(ctx context.Context) ([]Plugin, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+
+	type source struct{ dir, name string }
+	var dirs []source
+	if s.repoPath != "" {
+		dirs = append(dirs, source{filepath.Join(s.repoPath, filepath.FromSlash(PluginDir)), PluginSourceProject})
+	}
+	seenDirs := make(map[string]bool)
+	for _, dir := range filepath.SplitList(s.searchPath) {
+		if dir == "" || seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+		dirs = append(dirs, source{dir, PluginSourcePath})
+	}
+
+	var plugins []Plugin
+	first := make(map[string]string)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir.dir)
+		if err != nil {
+			// Missing and unreadable $PATH entries are skipped, as shells do
+			continue
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir.dir, entry.Name())
+			name, ok := pluginName(path, entry.Name())
+			if !ok {
+				continue
+			}
+			plugin := Plugin{Name: name, Path: path, Source: dir.name}
+			if runs, ok := first[name]; ok {
+				plugin.ShadowedBy = runs
+			} else {
+				first[name] = path
+			}
+			plugins = append(plugins, plugin)
+		}
+	}
+	// Stable, so shadowed plugins keep following the one that runs
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// Find implements PluginService.Find.
+func (s *pluginService) Find(ctx context.Context, name string) (*Plugin, error) {
+	plugins, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plugins {
+		if plugins[i].Name == name && plugins[i].ShadowedBy == "" {
+			return &plugins[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Context implements PluginService.Context.
+func (s *pluginService) Context(ctx context.Context) (*PluginContext, error) {
+	out := &PluginContext{Args: []string{}, Config: map[string]interface{}{}}
+	if s.repoPath == "" {
+		return out, nil
+	}
+
+	paths, err := resolveWorkspacePaths(ctx, s.repoPath)
+	if err != nil {
+		return nil, err
+	}
+	out.RepoRoot = s.repoPath
+	out.Structure = "consolidated"
+	if paths.Structure == workspace.Legacy {
+		out.Structure = "legacy"
+	}
+	out.BacklogDir = paths.BacklogPath
+	out.SprintsDir = paths.SprintsPath
+	out.ConfigFile = filepath.Join(s.repoPath, ProjectConfigFile)
+
+	data, err := os.ReadFile(out.ConfigFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return out, nil
+		}
+		return nil, &core.IOError{Operation: "read", FilePath: out.ConfigFile, Cause: err}
+	}
+	if err := yaml.Unmarshal(data, &out.Config); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, ProjectConfigFile, err)
+	}
+	if out.Config == nil {
+		out.Config = map[string]interface{}{}
+	}
+	return out, nil
+}
+
+// pluginName returns the command name of a plugin file, and whether the file
+// is an executable plugin. On Windows the extensions of $PATHEXT mark
+// executables and are not part of the name.
+func pluginName(path, file string) (string, bool) {
+	if !strings.HasPrefix(file, PluginPrefix) {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(file, PluginPrefix)
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		exts := os.Getenv("PATHEXT")
+		if exts == "" {
+			exts = ".com;.exe;.bat;.cmd"
+		}
+		if ext == "" || !containsString(strings.Split(strings.ToLower(exts), ";"), ext) {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	} else if info.Mode().Perm()&0o111 == 0 {
+		return "", false
+	}
+	return name, name != ""
+}
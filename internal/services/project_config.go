@@ -0,0 +1,382 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// ProjectConfigFile is the repository configuration file, relative to the repository root.
+const ProjectConfigFile = ".gitta/config.yaml"
+
+// fieldNamePattern restricts custom field names to simple YAML keys.
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// stateNamePattern restricts workflow state names to lowercase identifiers.
+var stateNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// githubRepositoryPattern matches GitHub "owner/name" repository names.
+var githubRepositoryPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// reservedFieldNames are frontmatter keys modelled by core.Story.
+var reservedFieldNames = map[string]bool{
+	"id": true, "title": true, "assignee": true, "priority": true,
+	"status": true, "created_at": true, "updated_at": true, "tags": true,
+}
+
+// ProjectConfig holds repository-level settings from .gitta/config.yaml.
+type ProjectConfig struct {
+	// Fields are the custom story field definitions, sorted by name.
+	Fields []core.FieldDefinition
+	// Workflow is the story workflow (core.DefaultWorkflow when not configured).
+	Workflow core.Workflow
+	// Calendar decides the working days used by sprint end dates, burndown
+	// charts and flow metrics (core.DefaultCalendar when not configured).
+	Calendar core.Calendar
+	// GitHub configures 'gitta sync github' (zero when not configured).
+	GitHub GitHubConfig
+	// Hooks run commands on domain events, in configuration order.
+	Hooks []Hook
+}
+
+// DefaultHookTimeout bounds a hook without a timeout.
+const DefaultHookTimeout = time.Minute
+
+// Hook is an entry of the hooks section of .gitta/config.yaml: a shell
+// command or plugin run on a domain event.
+type Hook struct {
+	Event core.EventType
+	// Run is a shell command, run from the repository root.
+	Run string
+	// Plugin names a plugin run instead of a command.
+	Plugin string
+	// Pre runs the hook before the operation; a non-zero exit vetoes it.
+	Pre     bool
+	Timeout time.Duration
+}
+
+// Name returns the command or plugin the hook runs, for messages.
+func (h Hook) Name() string {
+	if h.Plugin != "" {
+		return "plugin " + h.Plugin
+	}
+	return h.Run
+}
+
+// GitHubConfig is the github section of .gitta/config.yaml.
+type GitHubConfig struct {
+	// Repository is the "owner/name" of the repository whose issues are synced.
+	Repository string
+	// BaseURL is the API root; empty means github.com. GitHub Enterprise
+	// Server uses https://<host>/api/v3.
+	BaseURL string
+}
+
+// rawProjectConfig mirrors the YAML layout of .gitta/config.yaml.
+type rawProjectConfig struct {
+	Fields   map[string]rawFieldDefinition `yaml:"fields"`
+	Workflow *rawWorkflow                  `yaml:"workflow"`
+	Calendar *rawCalendar                  `yaml:"calendar"`
+	GitHub   *rawGitHub                    `yaml:"github"`
+	Hooks    []rawHook                     `yaml:"hooks"`
+}
+
+type rawHook struct {
+	Event   string `yaml:"event"`
+	Run     string `yaml:"run"`
+	Plugin  string `yaml:"plugin"`
+	Pre     bool   `yaml:"pre"`
+	Timeout string `yaml:"timeout"`
+}
+
+type rawGitHub struct {
+	Repository string `yaml:"repository"`
+	BaseURL    string `yaml:"base_url"`
+}
+
+type rawWorkflow struct {
+	Initial string             `yaml:"initial"`
+	States  []rawWorkflowState `yaml:"states"`
+}
+
+type rawWorkflowState struct {
+	Name        string   `yaml:"name"`
+	Category    string   `yaml:"category"`
+	Derive      string   `yaml:"derive"`
+	Label       string   `yaml:"label"`
+	Transitions []string `yaml:"transitions"`
+}
+
+type rawFieldDefinition struct {
+	Type        string      `yaml:"type"`
+	Values      []string    `yaml:"values"`
+	Min         *float64    `yaml:"min"`
+	Max         *float64    `yaml:"max"`
+	Required    bool        `yaml:"required"`
+	Default     interface{} `yaml:"default"`
+	Description string      `yaml:"description"`
+}
+
+// LoadProjectConfig reads .gitta/config.yaml from repoPath. A missing file yields
+// an empty configuration. The file is decoded with yaml.v3 rather than viper so
+// that custom field names keep their case.
+func LoadProjectConfig(repoPath string) (*ProjectConfig, error) {
+	path := filepath.Join(repoPath, ProjectConfigFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &ProjectConfig{Workflow: core.DefaultWorkflow(), Calendar: core.DefaultCalendar()}, nil
+		}
+		return nil, &core.IOError{Operation: "read", FilePath: path, Cause: err}
+	}
+
+	var raw rawProjectConfig
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, ProjectConfigFile, err)
+	}
+
+	cfg := &ProjectConfig{Workflow: core.DefaultWorkflow(), Calendar: core.DefaultCalendar()}
+	if raw.Calendar != nil {
+		calendar, err := buildCalendar(repoPath, *raw.Calendar)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, ProjectConfigFile, err)
+		}
+		cfg.Calendar = calendar
+	}
+	if raw.Workflow != nil {
+		workflow, err := buildWorkflow(*raw.Workflow)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, ProjectConfigFile, err)
+		}
+		cfg.Workflow = workflow
+	}
+
+	if raw.GitHub != nil {
+		if raw.GitHub.Repository != "" && !githubRepositoryPattern.MatchString(raw.GitHub.Repository) {
+			return nil, fmt.Errorf("%w: %s: github: repository must be owner/name, got %q", ErrInvalidConfig, ProjectConfigFile, raw.GitHub.Repository)
+		}
+		cfg.GitHub = GitHubConfig{Repository: raw.GitHub.Repository, BaseURL: raw.GitHub.BaseURL}
+	}
+
+	for i, raw := range raw.Hooks {
+		hook, err := buildHook(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: hooks[%d]: %v", ErrInvalidConfig, ProjectConfigFile, i, err)
+		}
+		cfg.Hooks = append(cfg.Hooks, hook)
+	}
+
+	for name, def := range raw.Fields {
+		field, err := buildFieldDefinition(name, def)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, ProjectConfigFile, err)
+		}
+		cfg.Fields = append(cfg.Fields, field)
+	}
+	sort.Slice(cfg.Fields, func(i, j int) bool { return cfg.Fields[i].Name < cfg.Fields[j].Name })
+
+	return cfg, nil
+}
+
+// Field returns the custom field definition with the given name.
+func (c *ProjectConfig) Field(name string) (core.FieldDefinition, bool) {
+	if c == nil {
+		return core.FieldDefinition{}, false
+	}
+	for _, f := range c.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return core.FieldDefinition{}, false
+}
+
+// ValidationRules returns the story validation rules derived from the configuration.
+func (c *ProjectConfig) ValidationRules() ValidationRules {
+	if c == nil {
+		return ValidationRules{}
+	}
+	workflow := c.Workflow
+	return ValidationRules{Fields: c.Fields, Workflow: &workflow}
+}
+
+// WorkflowOrDefault returns the configured workflow, or core.DefaultWorkflow
+// for a nil or zero configuration.
+func (c *ProjectConfig) WorkflowOrDefault() core.Workflow {
+	if c == nil || len(c.Workflow.States) == 0 {
+		return core.DefaultWorkflow()
+	}
+	return c.Workflow
+}
+
+// buildHook validates a raw hook and converts it.
+func buildHook(raw rawHook) (Hook, error) {
+	hook := Hook{Event: core.EventType(raw.Event), Run: raw.Run, Plugin: raw.Plugin, Pre: raw.Pre, Timeout: DefaultHookTimeout}
+	known := false
+	for _, event := range core.EventTypes {
+		known = known || event == hook.Event
+	}
+	if !known {
+		names := make([]string, len(core.EventTypes))
+		for i, event := range core.EventTypes {
+			names[i] = string(event)
+		}
+		return Hook{}, fmt.Errorf("unknown event %q (valid: %s)", raw.Event, strings.Join(names, ", "))
+	}
+	if (raw.Run == "") == (raw.Plugin == "") {
+		return Hook{}, fmt.Errorf("%s: exactly one of run and plugin is required", raw.Event)
+	}
+	if raw.Timeout != "" {
+		timeout, err := time.ParseDuration(raw.Timeout)
+		if err != nil || timeout <= 0 {
+			return Hook{}, fmt.Errorf("%s: invalid timeout %q (expected a duration such as 30s)", raw.Event, raw.Timeout)
+		}
+		hook.Timeout = timeout
+	}
+	return hook, nil
+}
+
+// buildFieldDefinition validates a raw field definition and converts it.
+func buildFieldDefinition(name string, raw rawFieldDefinition) (core.FieldDefinition, error) {
+	if !fieldNamePattern.MatchString(name) {
+		return core.FieldDefinition{}, fmt.Errorf("field %q: name must match %s", name, fieldNamePattern.String())
+	}
+	if reservedFieldNames[name] {
+		return core.FieldDefinition{}, fmt.Errorf("field %q: name is reserved for a built-in story field", name)
+	}
+
+	def := core.FieldDefinition{
+		Name:        name,
+		Type:        core.FieldType(raw.Type),
+		Values:      raw.Values,
+		Min:         raw.Min,
+		Max:         raw.Max,
+		Required:    raw.Required,
+		Default:     raw.Default,
+		Description: raw.Description,
+	}
+
+	switch def.Type {
+	case core.FieldTypeString, core.FieldTypeInt, core.FieldTypeFloat,
+		core.FieldTypeBool, core.FieldTypeDate, core.FieldTypeList:
+	case core.FieldTypeEnum:
+		if len(def.Values) == 0 {
+			return def, fmt.Errorf("field %q: enum fields require values", name)
+		}
+	case "":
+		return def, fmt.Errorf("field %q: type is required", name)
+	default:
+		return def, fmt.Errorf("field %q: unknown type %q (valid: string, int, float, bool, date, enum, list)", name, raw.Type)
+	}
+
+	if def.Min != nil && def.Max != nil && *def.Min > *def.Max {
+		return def, fmt.Errorf("field %q: min must not exceed max", name)
+	}
+	if def.Default != nil {
+		if verr := validateFieldValue(def, def.Default); verr != nil {
+			return def, fmt.Errorf("field %q: invalid default: %s", name, verr.Message)
+		}
+	}
+
+	return def, nil
+}
+
+// buildWorkflow validates a raw workflow definition and converts it.
+func buildWorkflow(raw rawWorkflow) (core.Workflow, error) {
+	if len(raw.States) == 0 {
+		return core.Workflow{}, fmt.Errorf("workflow: at least one state is required")
+	}
+
+	workflow := core.Workflow{Initial: core.Status(raw.Initial)}
+	if workflow.Initial == "" {
+		workflow.Initial = core.Status(raw.States[0].Name)
+	}
+
+	seen := make(map[string]bool, len(raw.States))
+	derived := make(map[core.DerivationRule]string)
+	for _, rs := range raw.States {
+		if !stateNamePattern.MatchString(rs.Name) {
+			return core.Workflow{}, fmt.Errorf("workflow state %q: name must match %s", rs.Name, stateNamePattern.String())
+		}
+		if seen[rs.Name] {
+			return core.Workflow{}, fmt.Errorf("workflow state %q: declared more than once", rs.Name)
+		}
+		seen[rs.Name] = true
+
+		state := core.WorkflowState{
+			Name:     core.Status(rs.Name),
+			Category: core.StatusCategory(rs.Category),
+			Derive:   core.DerivationRule(rs.Derive),
+			Label:    rs.Label,
+		}
+		if state.Derive == "" {
+			state.Derive = core.DeriveExplicit
+		}
+
+		switch state.Derive {
+		case core.DeriveExplicit:
+		case core.DeriveBranch, core.DerivePushed, core.DeriveMerged:
+			if other, ok := derived[state.Derive]; ok {
+				return core.Workflow{}, fmt.Errorf("workflow state %q: derive %s is already used by %q", rs.Name, state.Derive, other)
+			}
+			derived[state.Derive] = rs.Name
+		case core.DeriveLabel:
+			if state.Label == "" {
+				return core.Workflow{}, fmt.Errorf("workflow state %q: derive label requires label", rs.Name)
+			}
+		default:
+			return core.Workflow{}, fmt.Errorf("workflow state %q: unknown derive %q (valid: explicit, branch, pushed, merged, label)", rs.Name, rs.Derive)
+		}
+
+		switch state.Category {
+		case core.CategoryTodo, core.CategoryInProgress, core.CategoryDone:
+		case "":
+			state.Category = defaultCategory(state, workflow.Initial)
+		default:
+			return core.Workflow{}, fmt.Errorf("workflow state %q: unknown category %q (valid: todo, in_progress, done)", rs.Name, rs.Category)
+		}
+
+		for _, t := range rs.Transitions {
+			state.Transitions = append(state.Transitions, core.Status(t))
+		}
+		workflow.States = append(workflow.States, state)
+	}
+
+	if !workflow.Has(workflow.Initial) {
+		return core.Workflow{}, fmt.Errorf("workflow: initial state %q is not declared", workflow.Initial)
+	}
+	for _, state := range workflow.States {
+		for _, t := range state.Transitions {
+			if !workflow.Has(t) {
+				return core.Workflow{}, fmt.Errorf("workflow state %q: transition to undeclared state %q", state.Name, t)
+			}
+		}
+	}
+	if len(workflow.StatesIn(core.CategoryDone)) == 0 {
+		return core.Workflow{}, fmt.Errorf("workflow: at least one state must have category done")
+	}
+
+	return workflow, nil
+}
+
+// defaultCategory infers a category for states that do not declare one: the
+// initial state is todo, merged states are done, everything else is in progress.
+func defaultCategory(state core.WorkflowState, initial core.Status) core.StatusCategory {
+	switch {
+	case state.Name == initial:
+		return core.CategoryTodo
+	case state.Derive == core.DeriveMerged:
+		return core.CategoryDone
+	default:
+		return core.CategoryInProgress
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+//go:embed schema_templates/*.schema.json
+var schemaTemplateFS embed.FS
+
+// StorySchemaName is the name of the generated story frontmatter schema.
+const StorySchemaName = "story"
+
+// schemaBaseURI is the $id prefix shared by all gitta schemas.
+const schemaBaseURI = "https://github.com/GavinWu1991/gitta/schemas/"
+
+// SchemaService provides JSON Schemas for story frontmatter, configuration and
+// the --json output of commands, so scripted consumers have a stable contract.
+type SchemaService interface {
+	// Names returns the available schema names in sorted order.
+	Names() []string
+	// Schema returns the JSON Schema document for name.
+	// Returns ErrInvalidInput for unknown names.
+	Schema(ctx context.Context, name string) (map[string]interface{}, error)
+}
+
+type schemaService struct {
+	repoPath string
+}
+
+// NewSchemaService creates a new SchemaService instance.
+func NewSchemaService(repoPath string) SchemaService {
+	return &schemaService{repoPath: repoPath}
+}
+
+// Names implements SchemaService.Names.
+func (s *schemaService) Names() []string {
+	names := []string{StorySchemaName}
+	entries, err := schemaTemplateFS.ReadDir("schema_templates")
+	if err == nil {
+		for _, entry := range entries {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".schema.json"))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Schema implements SchemaService.Schema.
+func (s *schemaService) Schema(ctx context.Context, name string) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if name == StorySchemaName {
+		cfg, err := LoadProjectConfig(s.repoPath)
+		if err != nil {
+			return nil, err
+		}
+		schema := StoryFrontmatterSchema()
+		addCustomFieldSchemas(schema, cfg.Fields)
+		schema["properties"].(map[string]interface{})["status"] = map[string]interface{}{
+			"type": "string",
+			"enum": cfg.WorkflowOrDefault().Names(),
+		}
+		return schema, nil
+	}
+
+	data, err := schemaTemplateFS.ReadFile(path.Join("schema_templates", name+".schema.json"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown schema %q (available: %s)", ErrInvalidInput, name, strings.Join(s.Names(), ", "))
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to decode embedded schema %s: %w", name, err)
+	}
+	return schema, nil
+}
+
+// StoryFrontmatterSchema generates the JSON Schema for story frontmatter from
+// the yaml tags of core.Story, adding the constraints enforced by ValidateStory.
+// Keys not modelled by core.Story are allowed and preserved.
+func StoryFrontmatterSchema() map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	storyType := reflect.TypeOf(core.Story{})
+	for i := 0; i < storyType.NumField(); i++ {
+		field := storyType.Field(i)
+		tag := field.Tag.Get("yaml")
+		name, opts, _ := strings.Cut(tag, ",")
+		if tag == "" || name == "-" || name == "" {
+			// Untagged, excluded or inline (Extra) fields are not frontmatter keys.
+			continue
+		}
+
+		prop := schemaForType(field.Type)
+		for k, v := range storyFieldConstraints[name] {
+			prop[k] = v
+		}
+		properties[name] = prop
+
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"$id":                  schemaBaseURI + StorySchemaName + ".schema.json",
+		"title":                "gitta story frontmatter",
+		"type":                 "object",
+		"required":             required,
+		"properties":           properties,
+		"additionalProperties": true,
+	}
+}
+
+// addCustomFieldSchemas adds the configured custom fields to a story schema.
+func addCustomFieldSchemas(schema map[string]interface{}, defs []core.FieldDefinition) {
+	properties := schema["properties"].(map[string]interface{})
+	required := schema["required"].([]string)
+	for _, def := range defs {
+		properties[def.Name] = schemaForField(def)
+		if def.Required {
+			required = append(required, def.Name)
+		}
+	}
+	schema["required"] = required
+}
+
+// schemaForField maps a custom field definition to a JSON Schema fragment.
+func schemaForField(def core.FieldDefinition) map[string]interface{} {
+	var prop map[string]interface{}
+	switch def.Type {
+	case core.FieldTypeInt:
+		prop = map[string]interface{}{"type": "integer"}
+	case core.FieldTypeFloat:
+		prop = map[string]interface{}{"type": "number"}
+	case core.FieldTypeBool:
+		prop = map[string]interface{}{"type": "boolean"}
+	case core.FieldTypeDate:
+		// Dates may be plain calendar dates or RFC 3339 timestamps.
+		prop = map[string]interface{}{"type": "string", "pattern": `^\d{4}-\d{2}-\d{2}(T.*)?$`}
+	case core.FieldTypeEnum:
+		prop = map[string]interface{}{"enum": def.Values}
+	case core.FieldTypeList:
+		items := map[string]interface{}{"type": "string"}
+		if len(def.Values) > 0 {
+			items = map[string]interface{}{"enum": def.Values}
+		}
+		prop = map[string]interface{}{"type": "array", "items": items}
+	default:
+		prop = map[string]interface{}{"type": "string"}
+	}
+	if def.Min != nil {
+		prop["minimum"] = *def.Min
+	}
+	if def.Max != nil {
+		prop["maximum"] = *def.Max
+	}
+	if def.Description != "" {
+		prop["description"] = def.Description
+	}
+	return prop
+}
+
+// storyFieldConstraints mirrors the rules applied by ValidateStory.
+var storyFieldConstraints = map[string]map[string]interface{}{
+	"id": {
+		"pattern":   idPattern.String(),
+		"minLength": 3,
+		"maxLength": 20,
+	},
+	"title": {
+		"minLength": 1,
+		"maxLength": 200,
+	},
+	"assignee": {
+		// Empty assignees are allowed and treated as unassigned.
+		"pattern":   "^[a-zA-Z0-9_-]*$",
+		"maxLength": 50,
+	},
+	"priority": {
+		"enum": []string{
+			string(core.PriorityLow), string(core.PriorityMedium),
+			string(core.PriorityHigh), string(core.PriorityCritical),
+		},
+	},
+	"status": {
+		"enum": []string{
+			string(core.StatusTodo), string(core.StatusDoing),
+			string(core.StatusReview), string(core.StatusDone),
+		},
+	},
+	"tags": {
+		"maxItems":    20,
+		"uniqueItems": true,
+		"items": map[string]interface{}{
+			"type":      "string",
+			"pattern":   tagPattern.String(),
+			"minLength": 1,
+			"maxLength": 30,
+		},
+	},
+}
+
+// schemaForType maps a Go type to a JSON Schema fragment.
+func schemaForType(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaForType(t.Elem())}
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,454 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/pkg/ui"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+//go:embed site_templates/*.html site_templates/assets/*
+var siteTemplateFS embed.FS
+
+// SiteService generates a static HTML site for the task workspace.
+type SiteService interface {
+	// Build writes the site to req.OutDir, overwriting files of a previous build.
+	Build(ctx context.Context, req SiteRequest) (*SiteResult, error)
+}
+
+// SiteRequest configures a site build.
+type SiteRequest struct {
+	// OutDir is the output directory (e.g., "public").
+	OutDir string
+	// Title is the site title. Default: the repository folder name.
+	Title string
+}
+
+// SiteResult summarizes a site build.
+type SiteResult struct {
+	// Pages is the number of HTML pages written.
+	Pages int
+	// Stories is the number of story pages.
+	Stories int
+	// Sprints is the number of sprint boards.
+	Sprints int
+	// Charts is the number of burndown charts.
+	Charts int
+	// Warnings lists problems that did not stop the build.
+	Warnings []string
+}
+
+type siteService struct {
+	board    BoardService
+	activity ActivityService
+	burndown SprintBurndownService
+	repoPath string
+	workflow core.Workflow
+	markdown goldmark.Markdown
+}
+
+// NewSiteService creates a SiteService. Burndown charts are generated for
+// non-planning sprints when burndown is non-nil.
+func NewSiteService(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	burndown SprintBurndownService,
+	repoPath string,
+	workflow core.Workflow,
+) SiteService {
+	return &siteService{
+		board:    NewBoardService(storyRepo, sprintRepo, gitRepo, repoPath, workflow),
+		activity: NewActivityService(repoPath),
+		burndown: burndown,
+		repoPath: repoPath,
+		workflow: workflow,
+		// Raw HTML in story bodies is not rendered
+		markdown: goldmark.New(goldmark.WithExtensions(extension.GFM)),
+	}
+}
+
+// siteStory is a story with its derived status and page location.
+type siteStory struct {
+	*core.Story
+	Status   core.Status
+	Epic     string
+	URL      string // page path relative to the site root
+	Location string // "Backlog" or the sprint title
+	LocURL   string // page of the backlog or sprint
+	Done     bool
+	Activity []*siteActivity
+}
+
+// siteActivity is an activity log entry with the page of its story, if any.
+type siteActivity struct {
+	Activity
+	URL string
+}
+
+// siteColumn is a board column: one workflow state.
+type siteColumn struct {
+	Status  core.Status
+	Stories []*siteStory
+}
+
+// siteSprint is a sprint board.
+type siteSprint struct {
+	Name    string // folder name
+	Title   string // folder name without status prefix
+	Dir     string // sprint directory
+	Status  core.SprintStatus
+	URL     string
+	Chart   string // burndown SVG path relative to the site root, if any
+	Stories []*siteStory
+	Columns []siteColumn
+	Done    int
+}
+
+// siteEpic groups the stories sharing an epic field value.
+type siteEpic struct {
+	Name    string
+	Anchor  string
+	Stories []*siteStory
+	Done    int
+}
+
+// siteData is the model of the whole site.
+type siteData struct {
+	Title   string
+	Sprints []*siteSprint
+	Backlog []*siteStory
+	Stories []*siteStory
+	Epics   []*siteEpic
+	// Activity is the activity log, newest first.
+	Activity []*siteActivity
+}
+
+// sitePage is the data passed to page templates.
+type sitePage struct {
+	Site  *siteData
+	Title string
+	// Root is the relative path from the page to the site root ("" or "../").
+	Root   string
+	Sprint *siteSprint
+	Story  *siteStory
+	Body   template.HTML
+}
+
+// siteTable is the data passed to the storyTable template.
+type siteTable struct {
+	Root    string
+	Stories []*siteStory
+}
+
+// siteActivityTable is the data passed to the activityTable template.
+type siteActivityTable struct {
+	Root     string
+	Activity []*siteActivity
+}
+
+// searchEntry is one record of search-index.json.
+type searchEntry struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Status   string   `json:"status"`
+	Assignee string   `json:"assignee,omitempty"`
+	Epic     string   `json:"epic,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Location string   `json:"location"`
+	URL      string   `json:"url"`
+	Text     string   `json:"text"`
+}
+
+// Build implements SiteService.Build.
+func (s *siteService) Build(ctx context.Context, req SiteRequest) (*SiteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.OutDir == "" {
+		return nil, fmt.Errorf("output directory is required")
+	}
+
+	site, err := s.collect(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("site").Funcs(template.FuncMap{
+		"slug":        siteSlug,
+		"statusClass": func(status core.Status) string { return siteSlug(string(status)) },
+		"table":       func(root string, stories []*siteStory) siteTable { return siteTable{Root: root, Stories: stories} },
+		"activity": func(root string, activity []*siteActivity) siteActivityTable {
+			return siteActivityTable{Root: root, Activity: activity}
+		},
+		"sprintState": func(status core.SprintStatus) string { return status.String() },
+		"datetime":    func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+		"percent": func(done, total int) int {
+			if total == 0 {
+				return 0
+			}
+			return done * 100 / total
+		},
+	}).ParseFS(siteTemplateFS, "site_templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse site templates: %w", err)
+	}
+
+	result := &SiteResult{}
+	w := &siteWriter{outDir: req.OutDir, tmpl: tmpl}
+
+	for _, sprint := range site.Sprints {
+		if s.burndown == nil || sprint.Status == core.StatusPlanning || len(sprint.Stories) == 0 {
+			continue
+		}
+		chart, err := s.burndown.GenerateBurndownChart(ctx, sprint.Dir)
+		if errors.Is(err, core.ErrInsufficientHistory) {
+			continue
+		}
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("no burndown for %s: %v", sprint.Title, err))
+			continue
+		}
+		sprint.Chart = strings.TrimSuffix(sprint.URL, ".html") + "-burndown.svg"
+		w.file(sprint.Chart, []byte(ui.NewBurndownChart(*chart).SVG()))
+		result.Charts++
+	}
+
+	w.page("index.html", "index.html", sitePage{Site: site, Title: site.Title})
+	w.page("backlog.html", "backlog.html", sitePage{Site: site, Title: "Backlog"})
+	w.page("epics.html", "epics.html", sitePage{Site: site, Title: "Epics"})
+	w.page("search.html", "search.html", sitePage{Site: site, Title: "Search"})
+	w.page("activity.html", "activity.html", sitePage{Site: site, Title: "Activity"})
+	for _, sprint := range site.Sprints {
+		w.page(sprint.URL, "sprint.html", sitePage{Site: site, Title: sprint.Title, Root: "../", Sprint: sprint})
+	}
+	for _, story := range site.Stories {
+		var body bytes.Buffer
+		if err := s.markdown.Convert([]byte(story.Body), &body); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("cannot render %s: %v", story.ID, err))
+		}
+		w.page(story.URL, "story.html", sitePage{
+			Site: site, Title: story.ID + " " + story.Title, Root: "../", Story: story,
+			Body: template.HTML(body.String()), // goldmark omits raw HTML without WithUnsafe
+		})
+	}
+
+	index := make([]searchEntry, 0, len(site.Stories))
+	for _, story := range site.Stories {
+		entry := searchEntry{
+			ID: story.ID, Title: story.Title, Status: string(story.Status), Epic: story.Epic,
+			Tags: story.Tags, Location: story.Location, URL: story.URL, Text: strings.Join(strings.Fields(story.Body), " "),
+		}
+		if story.Assignee != nil {
+			entry.Assignee = *story.Assignee
+		}
+		index = append(index, entry)
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search index: %w", err)
+	}
+	w.file("search-index.json", data)
+
+	assets, err := siteTemplateFS.ReadDir("site_templates/assets")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site assets: %w", err)
+	}
+	for _, asset := range assets {
+		data, err := siteTemplateFS.ReadFile("site_templates/assets/" + asset.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read site assets: %w", err)
+		}
+		w.file("assets/"+asset.Name(), data)
+	}
+	// GitHub Pages serves the files as they are
+	w.file(".nojekyll", nil)
+
+	if w.err != nil {
+		return nil, w.err
+	}
+	result.Pages = w.pages
+	result.Stories = len(site.Stories)
+	result.Sprints = len(site.Sprints)
+	return result, nil
+}
+
+// collect reads the backlog and sprints and groups stories by epic.
+func (s *siteService) collect(ctx context.Context, req SiteRequest) (*siteData, error) {
+	snapshot, err := s.board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	title := req.Title
+	if title == "" {
+		title = filepath.Base(s.repoPath)
+	}
+	site := &siteData{Title: title}
+
+	used := make(map[string]bool)
+	epics := make(map[string]*siteEpic)
+	add := func(story *StoryWithStatus, sprint *siteSprint) {
+		page := siteSlug(story.Story.ID)
+		for n := 2; used[page]; n++ {
+			page = fmt.Sprintf("%s-%d", siteSlug(story.Story.ID), n)
+		}
+		used[page] = true
+
+		item := &siteStory{
+			Story:    story.Story,
+			Status:   story.Status,
+			URL:      "stories/" + page + ".html",
+			Location: "Backlog",
+			LocURL:   "backlog.html",
+			Done:     s.workflow.IsDone(story.Status),
+		}
+		if value, ok := story.Story.Extra["epic"]; ok {
+			item.Epic = FormatFieldValue(value)
+		}
+		site.Stories = append(site.Stories, item)
+
+		if sprint == nil {
+			site.Backlog = append(site.Backlog, item)
+		} else {
+			item.Location, item.LocURL = sprint.Title, sprint.URL
+			sprint.Stories = append(sprint.Stories, item)
+			if item.Done {
+				sprint.Done++
+			}
+		}
+
+		if item.Epic != "" {
+			epic, ok := epics[item.Epic]
+			if !ok {
+				epic = &siteEpic{Name: item.Epic, Anchor: "epic-" + siteSlug(item.Epic)}
+				epics[item.Epic] = epic
+				site.Epics = append(site.Epics, epic)
+			}
+			epic.Stories = append(epic.Stories, item)
+			if item.Done {
+				epic.Done++
+			}
+		}
+	}
+
+	for _, story := range snapshot.Backlog {
+		add(story, nil)
+	}
+	for _, sp := range snapshot.Sprints {
+		title := SprintTitle(sp.Name)
+		sprint := &siteSprint{Name: sp.Name, Title: title, Dir: sp.Path, Status: sp.Status, URL: "sprints/" + siteSlug(title) + ".html"}
+		site.Sprints = append(site.Sprints, sprint)
+		for _, story := range sp.Stories {
+			add(story, sprint)
+		}
+	}
+	sort.Slice(site.Epics, func(i, j int) bool { return site.Epics[i].Name < site.Epics[j].Name })
+
+	for _, sprint := range site.Sprints {
+		sprint.Columns = s.boardColumns(sprint.Stories)
+	}
+
+	activities, err := s.activity.List(ctx, ActivityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	stories := make(map[string]*siteStory, len(site.Stories))
+	for _, story := range site.Stories {
+		stories[story.ID] = story
+	}
+	for _, activity := range activities {
+		entry := &siteActivity{Activity: activity}
+		if story, ok := stories[activity.Story]; ok {
+			entry.URL = story.URL
+			story.Activity = append(story.Activity, entry)
+		}
+		site.Activity = append(site.Activity, entry)
+	}
+	return site, nil
+}
+
+// boardColumns returns one column per workflow state, followed by columns for
+// statuses outside the workflow.
+func (s *siteService) boardColumns(stories []*siteStory) []siteColumn {
+	columns := make([]siteColumn, 0, len(s.workflow.States))
+	index := make(map[core.Status]int)
+	for _, state := range s.workflow.States {
+		index[state.Name] = len(columns)
+		columns = append(columns, siteColumn{Status: state.Name})
+	}
+	for _, story := range stories {
+		i, ok := index[story.Status]
+		if !ok {
+			i = len(columns)
+			index[story.Status] = i
+			columns = append(columns, siteColumn{Status: story.Status})
+		}
+		columns[i].Stories = append(columns[i].Stories, story)
+	}
+	return columns
+}
+
+// siteSlug converts a name to a file name: letters, digits, '-', '_' and '.'
+// are kept and everything else becomes '-'.
+func siteSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '-'
+	}, name)
+	if strings.Trim(slug, ".") == "" {
+		return "-"
+	}
+	return slug
+}
+
+// siteWriter writes site files, keeping the first error.
+type siteWriter struct {
+	outDir string
+	tmpl   *template.Template
+	pages  int
+	err    error
+}
+
+func (w *siteWriter) page(path, name string, data sitePage) {
+	if w.err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err := w.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		w.err = fmt.Errorf("failed to render %s: %w", path, err)
+		return
+	}
+	w.file(path, buf.Bytes())
+	w.pages++
+}
+
+func (w *siteWriter) file(path string, data []byte) {
+	if w.err != nil {
+		return
+	}
+	full := filepath.Join(w.outDir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		w.err = &core.IOError{Operation: "create", FilePath: filepath.Dir(full), Cause: err}
+		return
+	}
+	if err := os.WriteFile(full, data, 0644); err != nil {
+		w.err = &core.IOError{Operation: "write", FilePath: full, Cause: err}
+	}
+}
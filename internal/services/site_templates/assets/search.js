@@ -0,0 +1,55 @@
+// Filters the prebuilt search index; every word of the query must match.
+(function () {
+  var script = document.currentScript;
+  var root = script.getAttribute("data-index").replace(/search-index\.json$/, "");
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var status = document.getElementById("search-status");
+  var index = [];
+
+  function cell(row, text, href) {
+    var td = row.insertCell();
+    if (href) {
+      var a = document.createElement("a");
+      a.href = root + href;
+      a.textContent = text;
+      td.appendChild(a);
+    } else {
+      td.textContent = text;
+    }
+  }
+
+  function haystack(entry) {
+    return [entry.id, entry.title, entry.status, entry.assignee, entry.epic, (entry.tags || []).join(" "), entry.location, entry.text]
+      .join(" ").toLowerCase();
+  }
+
+  function search() {
+    var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    var matches = index.filter(function (entry) {
+      return words.every(function (word) { return entry.haystack.indexOf(word) >= 0; });
+    });
+    matches.forEach(function (entry) {
+      var row = results.insertRow();
+      cell(row, entry.id, entry.url);
+      cell(row, entry.title);
+      cell(row, entry.status);
+      cell(row, entry.location);
+    });
+    status.textContent = matches.length + " of " + index.length + " stories";
+  }
+
+  fetch(script.getAttribute("data-index"))
+    .then(function (response) { return response.json(); })
+    .then(function (entries) {
+      index = entries.map(function (entry) { entry.haystack = haystack(entry); return entry; });
+      var query = new URLSearchParams(location.search).get("q");
+      if (query) input.value = query;
+      input.addEventListener("input", search);
+      search();
+    })
+    .catch(function () {
+      status.textContent = "The search index could not be loaded. Serve the site over HTTP to use search.";
+    });
+})();
@@ -2,17 +2,29 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/gavin/gitta/internal/core"
 )
 
-// SprintBurndownService handles burndown chart generation from Git history.
+// SprintBurndownService handles sprint chart generation from Git history.
 type SprintBurndownService interface {
 	// GenerateBurndown analyzes Git history and generates burndown data points.
 	GenerateBurndown(ctx context.Context, sprintPath string) ([]core.BurndownDataPoint, error)
+	// GenerateBurnup analyzes Git history and generates daily scope and
+	// completed work.
+	GenerateBurnup(ctx context.Context, sprintPath string) ([]core.BurnupDataPoint, error)
+	// GenerateCumulativeFlow analyzes Git history and counts tasks per status
+	// per day.
+	GenerateCumulativeFlow(ctx context.Context, sprintPath string) (core.CumulativeFlow, error)
+	// GenerateBurndownChart generates the burndown with its ideal guideline,
+	// projected completion and scope changes.
+	GenerateBurndownChart(ctx context.Context, sprintPath string) (*core.BurndownChart, error)
 }
 
 type sprintBurndownService struct {
@@ -20,6 +32,8 @@ type sprintBurndownService struct {
 	sprintRepo  core.SprintRepository
 	storyRepo   core.StoryRepository
 	repoPath    string
+	workflow    core.Workflow
+	calendar    core.Calendar
 }
 
 // NewSprintBurndownService creates a new SprintBurndownService instance.
@@ -28,35 +42,203 @@ func NewSprintBurndownService(
 	sprintRepo core.SprintRepository,
 	storyRepo core.StoryRepository,
 	repoPath string,
+) SprintBurndownService {
+	return NewSprintBurndownServiceWithWorkflow(gitAnalyzer, sprintRepo, storyRepo, repoPath, core.DefaultWorkflow())
+}
+
+// NewSprintBurndownServiceWithWorkflow creates a SprintBurndownService that
+// counts stories in the workflow's done category as complete.
+func NewSprintBurndownServiceWithWorkflow(
+	gitAnalyzer core.GitHistoryAnalyzer,
+	sprintRepo core.SprintRepository,
+	storyRepo core.StoryRepository,
+	repoPath string,
+	workflow core.Workflow,
+) SprintBurndownService {
+	return NewSprintBurndownServiceWithCalendar(gitAnalyzer, sprintRepo, storyRepo, repoPath, workflow, core.Calendar{})
+}
+
+// NewSprintBurndownServiceWithCalendar creates a SprintBurndownService that
+// plots only the calendar's working days. Changes made on other days show on
+// the next working day.
+func NewSprintBurndownServiceWithCalendar(
+	gitAnalyzer core.GitHistoryAnalyzer,
+	sprintRepo core.SprintRepository,
+	storyRepo core.StoryRepository,
+	repoPath string,
+	workflow core.Workflow,
+	calendar core.Calendar,
 ) SprintBurndownService {
 	return &sprintBurndownService{
 		gitAnalyzer: gitAnalyzer,
 		sprintRepo:  sprintRepo,
 		storyRepo:   storyRepo,
 		repoPath:    repoPath,
+		workflow:    workflow,
+		calendar:    calendar,
 	}
 }
 
 // GenerateBurndown implements SprintBurndownService.GenerateBurndown.
 func (s *sprintBurndownService) GenerateBurndown(ctx context.Context, sprintPath string) ([]core.BurndownDataPoint, error) {
-	if err := ctx.Err(); err != nil {
+	snapshots, startDate, endDate, err := s.analyzeHistory(ctx, sprintPath)
+	if err != nil {
 		return nil, err
 	}
+	return s.burndownPoints(snapshots, startDate, endDate), nil
+}
+
+// burndownPoints converts snapshots to one data point per working day.
+func (s *sprintBurndownService) burndownPoints(snapshots []core.CommitSnapshot, startDate, endDate time.Time) []core.BurndownDataPoint {
+
+	// Calculate initial totals from first snapshot
+	firstSnapshot := snapshots[0]
+	initialTasks := len(firstSnapshot.Files)
+	initialPoints := s.calculateTotalPoints(firstSnapshot.Files)
+
+	// Generate burndown data points
+	var dataPoints []core.BurndownDataPoint
+
+	for _, snapshot := range snapshots {
+		// Calculate remaining work
+		remainingTasks := s.countIncompleteTasks(snapshot.Files)
+		remainingPoints := s.calculateRemainingPoints(snapshot.Files)
+
+		dataPoint := core.BurndownDataPoint{
+			Date:            s.calendar.StartOfDay(snapshot.CommitDate),
+			RemainingPoints: remainingPoints,
+			RemainingTasks:  remainingTasks,
+			TotalPoints:     &initialPoints,
+			TotalTasks:      &initialTasks,
+			ScopePoints:     s.calculateTotalPoints(snapshot.Files),
+		}
+
+		dataPoints = append(dataPoints, dataPoint)
+	}
+
+	// Fill in missing days with previous day's values
+	return s.fillMissingDays(dataPoints, startDate, endDate)
+}
+
+// GenerateBurndownChart implements SprintBurndownService.GenerateBurndownChart.
+func (s *sprintBurndownService) GenerateBurndownChart(ctx context.Context, sprintPath string) (*core.BurndownChart, error) {
+	snapshots, startDate, endDate, err := s.analyzeHistory(ctx, sprintPath)
+	if err != nil {
+		return nil, err
+	}
+	points := s.burndownPoints(snapshots, startDate, endDate)
+
+	schedule, err := s.readSchedule(ctx, sprintPath)
+	if err != nil {
+		return nil, err
+	}
+	scheduled := schedule != nil
+	if !scheduled {
+		// Assume a default-length sprint starting with the first commit
+		start := s.calendar.StartOfDay(snapshots[0].CommitDate)
+		end, err := s.calendar.EndDate(start, "")
+		if err != nil {
+			return nil, err
+		}
+		schedule = &core.SprintSchedule{Start: start, End: end}
+	}
+
+	chart := ProjectBurndown(points, schedule.Start, schedule.End, s.calendar)
+	chart.Scheduled = scheduled
+	return &chart, nil
+}
+
+// GenerateBurnup implements SprintBurndownService.GenerateBurnup.
+func (s *sprintBurndownService) GenerateBurnup(ctx context.Context, sprintPath string) ([]core.BurnupDataPoint, error) {
+	snapshots, startDate, endDate, err := s.analyzeHistory(ctx, sprintPath)
+	if err != nil {
+		return nil, err
+	}
+
+	days := dailySnapshots(snapshots, startDate, endDate, s.calendar)
+	points := make([]core.BurnupDataPoint, 0, len(days))
+	for _, day := range days {
+		scopePoints := s.calculateTotalPoints(day.files)
+		points = append(points, core.BurnupDataPoint{
+			Date:            day.date,
+			ScopeTasks:      len(day.files),
+			CompletedTasks:  len(day.files) - s.countIncompleteTasks(day.files),
+			ScopePoints:     scopePoints,
+			CompletedPoints: scopePoints - s.calculateRemainingPoints(day.files),
+		})
+	}
+	return points, nil
+}
+
+// GenerateCumulativeFlow implements SprintBurndownService.GenerateCumulativeFlow.
+func (s *sprintBurndownService) GenerateCumulativeFlow(ctx context.Context, sprintPath string) (core.CumulativeFlow, error) {
+	snapshots, startDate, endDate, err := s.analyzeHistory(ctx, sprintPath)
+	if err != nil {
+		return core.CumulativeFlow{}, err
+	}
+
+	// Workflow states first, then any other status seen in history
+	states := make([]core.Status, 0, len(s.workflow.States))
+	known := make(map[core.Status]bool)
+	for _, state := range s.workflow.States {
+		states = append(states, state.Name)
+		known[state.Name] = true
+	}
+	var extra []core.Status
+	for _, snapshot := range snapshots {
+		for _, story := range snapshot.Files {
+			if !known[story.Status] {
+				known[story.Status] = true
+				extra = append(extra, story.Status)
+			}
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	states = append(states, extra...)
+
+	days := dailySnapshots(snapshots, startDate, endDate, s.calendar)
+	flow := core.CumulativeFlow{States: states, Points: make([]core.FlowDataPoint, 0, len(days))}
+	for _, day := range days {
+		counts := make(map[core.Status]int, len(states))
+		for _, state := range states {
+			counts[state] = 0
+		}
+		for _, story := range day.files {
+			counts[story.Status]++
+		}
+		flow.Points = append(flow.Points, core.FlowDataPoint{Date: day.date, Counts: counts})
+	}
+	return flow, nil
+}
+
+// analyzeHistory returns the sprint's daily snapshots (oldest first) and the
+// analysis window.
+func (s *sprintBurndownService) analyzeHistory(ctx context.Context, sprintPath string) ([]core.CommitSnapshot, time.Time, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
 
-	// Get sprint information (we need start/end dates)
-	// For now, we'll need to read sprint metadata or infer from directory structure
-	// Let's get the sprint name from path
 	sprintName := filepath.Base(sprintPath)
 
-	// Get sprint start date - we'll need to read it from sprint metadata or infer
-	// For MVP, let's try to get it from the sprint directory creation or use a default
-	startDate := time.Now().AddDate(0, 0, -14) // Default: 2 weeks ago
+	// Analyze the scheduled sprint up to today, or the last two weeks for
+	// sprints started without a schedule
+	startDate := time.Now().AddDate(0, 0, -14)
 	endDate := time.Now()
+	schedule, err := s.readSchedule(ctx, sprintPath)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+	if schedule != nil {
+		startDate = schedule.Start
+		if last := schedule.End.Add(-time.Nanosecond); last.Before(endDate) {
+			endDate = last
+		}
+	}
 
 	// Calculate relative sprint directory path from repo root
 	sprintDir, err := filepath.Rel(s.repoPath, sprintPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate relative sprint path: %w", err)
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("failed to calculate relative sprint path: %w", err)
 	}
 
 	// Analyze Git history for the sprint
@@ -70,50 +252,155 @@ func (s *sprintBurndownService) GenerateBurndown(ctx context.Context, sprintPath
 
 	snapshots, err := s.gitAnalyzer.AnalyzeSprintHistory(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze sprint history: %w", err)
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("failed to analyze sprint history: %w", err)
 	}
 
 	if len(snapshots) == 0 {
-		return nil, fmt.Errorf("%w: no commits found for sprint %s", core.ErrInsufficientHistory, sprintName)
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("%w: no commits found for sprint %s", core.ErrInsufficientHistory, sprintName)
 	}
 
-	// Calculate initial totals from first snapshot
-	firstSnapshot := snapshots[0]
-	initialTasks := len(firstSnapshot.Files)
-	initialPoints := s.calculateTotalPoints(firstSnapshot.Files)
+	return snapshots, startDate, endDate, nil
+}
 
-	// Generate burndown data points
-	var dataPoints []core.BurndownDataPoint
+// readSchedule returns the sprint's schedule, or nil if none is recorded.
+func (s *sprintBurndownService) readSchedule(ctx context.Context, sprintPath string) (*core.SprintSchedule, error) {
+	if s.sprintRepo == nil {
+		return nil, nil
+	}
+	schedule, err := s.sprintRepo.ReadSprintSchedule(ctx, sprintPath)
+	if errors.Is(err, core.ErrNoSchedule) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sprint schedule: %w", err)
+	}
+	return schedule, nil
+}
 
-	for _, snapshot := range snapshots {
-		// Calculate remaining work
-		remainingTasks := s.countIncompleteTasks(snapshot.Files)
-		remainingPoints := s.calculateRemainingPoints(snapshot.Files)
+// ProjectBurndown adds the ideal guideline, the least-squares projection of
+// the remaining points and the scope changes to burndown data points for a
+// sprint from start to end (the first day after the sprint).
+//
+// The ideal line declines linearly over the sprint's working days, from the
+// points remaining on the first day to zero on the last. The projection fits
+// the remaining points against working days and reports the working day the
+// trend reaches zero; the sprint is at risk when that day is not before end,
+// or when the trend is flat or rising with work remaining. Points before start
+// are dropped.
+func ProjectBurndown(points []core.BurndownDataPoint, start, end time.Time, calendar core.Calendar) core.BurndownChart {
+	start, end = calendar.StartOfDay(start), calendar.StartOfDay(end)
+	chart := core.BurndownChart{Start: start, End: end}
+	for _, p := range points {
+		if !p.Date.Before(start) {
+			chart.Points = append(chart.Points, p)
+		}
+	}
+	if len(chart.Points) == 0 {
+		return chart
+	}
 
-		// Normalize date to midnight for grouping
-		date := time.Date(
-			snapshot.CommitDate.Year(),
-			snapshot.CommitDate.Month(),
-			snapshot.CommitDate.Day(),
-			0, 0, 0, 0,
-			snapshot.CommitDate.Location(),
-		)
+	// Ideal guideline
+	var days []time.Time
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if calendar.IsWorkingDay(day) {
+			days = append(days, day)
+		}
+	}
+	total := float64(chart.Points[0].RemainingPoints)
+	for i, day := range days {
+		remaining := total
+		if len(days) > 1 {
+			remaining = total * (1 - float64(i)/float64(len(days)-1))
+		}
+		chart.Ideal = append(chart.Ideal, core.IdealPoint{Date: day, Remaining: remaining})
+	}
 
-		dataPoint := core.BurndownDataPoint{
-			Date:            date,
-			RemainingPoints: remainingPoints,
-			RemainingTasks:  remainingTasks,
-			TotalPoints:     &initialPoints,
-			TotalTasks:      &initialTasks,
+	// Scope changes
+	for i := 1; i < len(chart.Points); i++ {
+		if added := chart.Points[i].ScopePoints - chart.Points[i-1].ScopePoints; added > 0 {
+			chart.ScopeChanges = append(chart.ScopeChanges, core.ScopeChange{Date: chart.Points[i].Date, Added: added})
 		}
+	}
 
-		dataPoints = append(dataPoints, dataPoint)
+	// Least-squares trend of remaining points per working day
+	n := float64(len(chart.Points))
+	var sumX, sumY float64
+	xs := make([]float64, len(chart.Points))
+	for i, p := range chart.Points {
+		xs[i] = float64(calendar.WorkingDaysBetween(start, p.Date))
+		sumX += xs[i]
+		sumY += float64(p.RemainingPoints)
 	}
+	meanX, meanY := sumX/n, sumY/n
+	var sxy, sxx float64
+	for i, p := range chart.Points {
+		sxy += (xs[i] - meanX) * (float64(p.RemainingPoints) - meanY)
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if sxx > 0 {
+		chart.Slope = sxy / sxx
+	}
+	chart.Intercept = meanY - chart.Slope*meanX
 
-	// Fill in missing days with previous day's values
-	dataPoints = s.fillMissingDays(dataPoints, startDate, endDate)
+	last := chart.Points[len(chart.Points)-1]
+	switch {
+	case last.RemainingPoints == 0:
+		// Finished: completion is the first day of the final run of zeros
+		done := last.Date
+		for i := len(chart.Points) - 1; i >= 0 && chart.Points[i].RemainingPoints == 0; i-- {
+			done = chart.Points[i].Date
+		}
+		chart.ProjectedCompletion = &done
+		chart.AtRisk = !done.Before(end)
+	case len(chart.Points) < 2:
+		// Not enough data for a trend
+	case chart.Slope < 0:
+		day := int(math.Ceil(-chart.Intercept / chart.Slope))
+		// Work remains, so it cannot finish before the next working day
+		if lastX := int(xs[len(xs)-1]); day <= lastX {
+			day = lastX + 1
+		}
+		completion := calendar.AddWorkingDays(start, day)
+		chart.ProjectedCompletion = &completion
+		chart.AtRisk = !completion.Before(end)
+	default:
+		chart.AtRisk = true
+	}
+	return chart
+}
+
+// daySnapshot is the sprint's file state at the end of a day.
+type daySnapshot struct {
+	date  time.Time
+	files map[string]*core.Story
+}
+
+// dailySnapshots returns one file state per working day from startDate to
+// endDate. Days without commits repeat the previous state, so changes made
+// on non-working days show on the next working day; days before the first
+// commit use the first state, as in burndown charts.
+func dailySnapshots(snapshots []core.CommitSnapshot, startDate, endDate time.Time, calendar core.Calendar) []daySnapshot {
+	if len(snapshots) == 0 {
+		return nil
+	}
 
-	return dataPoints, nil
+	byDate := make(map[string]map[string]*core.Story, len(snapshots))
+	for _, snapshot := range snapshots {
+		// Later snapshots of the same day win
+		byDate[calendar.StartOfDay(snapshot.CommitDate).Format("2006-01-02")] = snapshot.Files
+	}
+
+	var days []daySnapshot
+	files := snapshots[0].Files
+	for current := calendar.StartOfDay(startDate); !current.After(endDate); current = current.AddDate(0, 0, 1) {
+		if dayFiles, ok := byDate[current.Format("2006-01-02")]; ok {
+			files = dayFiles
+		}
+		if calendar.IsWorkingDay(current) {
+			days = append(days, daySnapshot{date: current, files: files})
+		}
+	}
+	return days
 }
 
 // calculateTotalPoints calculates total story points from files.
@@ -126,7 +413,7 @@ func (s *sprintBurndownService) calculateTotalPoints(files map[string]*core.Stor
 func (s *sprintBurndownService) calculateRemainingPoints(files map[string]*core.Story) int {
 	count := 0
 	for _, story := range files {
-		if story.Status != core.StatusDone {
+		if !s.workflow.IsDone(story.Status) {
 			count++
 		}
 	}
@@ -137,14 +424,16 @@ func (s *sprintBurndownService) calculateRemainingPoints(files map[string]*core.
 func (s *sprintBurndownService) countIncompleteTasks(files map[string]*core.Story) int {
 	count := 0
 	for _, story := range files {
-		if story.Status == "" || story.Status != core.StatusDone {
+		if !s.workflow.IsDone(story.Status) {
 			count++
 		}
 	}
 	return count
 }
 
-// fillMissingDays fills in missing days with the previous day's values.
+// fillMissingDays returns one data point per working day, filling days
+// without commits with the previous day's values. Commits on non-working days
+// show on the next working day.
 func (s *sprintBurndownService) fillMissingDays(
 	dataPoints []core.BurndownDataPoint,
 	startDate, endDate time.Time,
@@ -162,15 +451,15 @@ func (s *sprintBurndownService) fillMissingDays(
 
 	// Fill in missing days
 	var filled []core.BurndownDataPoint
-	currentDate := startDate
+	currentDate := s.calendar.StartOfDay(startDate)
 	lastValue := dataPoints[0]
 
 	for !currentDate.After(endDate) {
 		dateKey := currentDate.Format("2006-01-02")
 		if dp, exists := dateMap[dateKey]; exists {
-			filled = append(filled, dp)
 			lastValue = dp
-		} else {
+		}
+		if s.calendar.IsWorkingDay(currentDate) {
 			// Use last known value
 			dp := lastValue
 			dp.Date = currentDate
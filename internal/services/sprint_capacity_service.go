@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// defaultSprintDays is the number of working days in a new capacity template.
+const defaultSprintDays = 10
+
+// SprintCapacityService compares the points committed to a sprint with the
+// team's capacity.
+type SprintCapacityService interface {
+	// Report returns committed points per assignee against capacity.
+	// Returns core.ErrNoCapacity if the sprint has no capacity metadata.
+	Report(ctx context.Context, sprintPath string) (*core.CapacityReport, error)
+	// CheckAddition returns the overcommitments that adding story to the sprint
+	// would cause. Sprints without capacity metadata never warn.
+	CheckAddition(ctx context.Context, sprintPath string, story *core.Story) ([]CapacityWarning, error)
+	// InitCapacity writes a capacity file for the sprint, copied from the most
+	// recent sprint with capacity (holidays excluded) or listing the sprint's
+	// assignees. It returns the capacity and the sprint it was copied from.
+	InitCapacity(ctx context.Context, sprintPath string) (*core.SprintCapacity, string, error)
+	// InheritCapacity copies the most recent sprint capacity (holidays
+	// excluded) to a sprint without one, and returns the source sprint name,
+	// or "" when there is nothing to copy.
+	InheritCapacity(ctx context.Context, sprintPath string) (string, error)
+}
+
+// CapacityWarning reports an assignee, or the whole team, over capacity.
+type CapacityWarning struct {
+	// Sprint is the sprint folder name.
+	Sprint string
+	// Assignee is empty for the team total.
+	Assignee  string
+	Capacity  float64
+	Committed float64
+}
+
+// String renders the warning for display.
+func (w CapacityWarning) String() string {
+	who := "team"
+	if w.Assignee != "" {
+		who = w.Assignee
+	}
+	return fmt.Sprintf("%s is over capacity in %s: %g of %g points committed", who, w.Sprint, w.Committed, w.Capacity)
+}
+
+type sprintCapacityService struct {
+	storyRepo  core.StoryRepository
+	sprintRepo core.SprintRepository
+}
+
+// NewSprintCapacityService creates a new SprintCapacityService instance.
+func NewSprintCapacityService(storyRepo core.StoryRepository, sprintRepo core.SprintRepository) SprintCapacityService {
+	return &sprintCapacityService{
+		storyRepo:  storyRepo,
+		sprintRepo: sprintRepo,
+	}
+}
+
+// Report implements SprintCapacityService.Report.
+func (s *sprintCapacityService) Report(ctx context.Context, sprintPath string) (*core.CapacityReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	capacity, err := s.sprintRepo.ReadSprintCapacity(ctx, sprintPath)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := s.storyRepo.ListStories(ctx, sprintPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stories: %w", err)
+	}
+	return buildCapacityReport(filepath.Base(sprintPath), *capacity, stories), nil
+}
+
+// CheckAddition implements SprintCapacityService.CheckAddition.
+func (s *sprintCapacityService) CheckAddition(ctx context.Context, sprintPath string, story *core.Story) ([]CapacityWarning, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	capacity, err := s.sprintRepo.ReadSprintCapacity(ctx, sprintPath)
+	if errors.Is(err, core.ErrNoCapacity) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if storyPoints(story, capacity.PointsFieldOrDefault()) <= 0 {
+		return nil, nil // Adds no load
+	}
+
+	stories, err := s.storyRepo.ListStories(ctx, sprintPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stories: %w", err)
+	}
+	for _, existing := range stories {
+		if existing.ID == story.ID {
+			return nil, nil // Already committed
+		}
+	}
+
+	name := filepath.Base(sprintPath)
+	report := buildCapacityReport(name, *capacity, append(stories, story))
+	var warnings []CapacityWarning
+	if assignee := storyAssignee(story); assignee != "" {
+		if load, ok := report.Load(assignee); ok && load.Overcommitted() {
+			warnings = append(warnings, CapacityWarning{Sprint: name, Assignee: assignee, Capacity: load.Capacity, Committed: load.Committed})
+		}
+	}
+	if report.TeamCommitted > report.TeamCapacity {
+		warnings = append(warnings, CapacityWarning{Sprint: name, Capacity: report.TeamCapacity, Committed: report.TeamCommitted})
+	}
+	return warnings, nil
+}
+
+// InitCapacity implements SprintCapacityService.InitCapacity.
+func (s *sprintCapacityService) InitCapacity(ctx context.Context, sprintPath string) (*core.SprintCapacity, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if _, err := s.sprintRepo.ReadSprintCapacity(ctx, sprintPath); !errors.Is(err, core.ErrNoCapacity) {
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, "", fmt.Errorf("capacity already defined for %s", filepath.Base(sprintPath))
+	}
+
+	capacity, from, err := s.latestCapacity(ctx, sprintPath)
+	if err != nil {
+		return nil, "", err
+	}
+	if capacity == nil {
+		stories, err := s.storyRepo.ListStories(ctx, sprintPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list stories: %w", err)
+		}
+		capacity = &core.SprintCapacity{Days: defaultSprintDays, Members: []core.MemberCapacity{}}
+		seen := make(map[string]bool)
+		for _, story := range stories {
+			if assignee := storyAssignee(story); assignee != "" && !seen[assignee] {
+				seen[assignee] = true
+				capacity.Members = append(capacity.Members, core.MemberCapacity{Name: assignee})
+			}
+		}
+		sort.Slice(capacity.Members, func(i, j int) bool { return capacity.Members[i].Name < capacity.Members[j].Name })
+	}
+
+	if err := s.sprintRepo.WriteSprintCapacity(ctx, sprintPath, capacity); err != nil {
+		return nil, "", err
+	}
+	return capacity, from, nil
+}
+
+// InheritCapacity implements SprintCapacityService.InheritCapacity.
+func (s *sprintCapacityService) InheritCapacity(ctx context.Context, sprintPath string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if _, err := s.sprintRepo.ReadSprintCapacity(ctx, sprintPath); !errors.Is(err, core.ErrNoCapacity) {
+		return "", err // Already defined or unreadable
+	}
+	capacity, from, err := s.latestCapacity(ctx, sprintPath)
+	if err != nil || capacity == nil {
+		return "", err
+	}
+	if err := s.sprintRepo.WriteSprintCapacity(ctx, sprintPath, capacity); err != nil {
+		return "", err
+	}
+	return from, nil
+}
+
+// latestCapacity returns the capacity of the other sprint with the highest ID
+// that defines one, without holidays. Returns nil if no sprint has capacity.
+func (s *sprintCapacityService) latestCapacity(ctx context.Context, sprintPath string) (*core.SprintCapacity, string, error) {
+	sprintsDir := filepath.Dir(sprintPath)
+	names, err := s.sprintRepo.ListSprints(ctx, sprintsDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list sprints: %w", err)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return strings.TrimLeft(names[i], "!+@~") > strings.TrimLeft(names[j], "!+@~")
+	})
+
+	for _, name := range names {
+		if name == filepath.Base(sprintPath) {
+			continue
+		}
+		capacity, err := s.sprintRepo.ReadSprintCapacity(ctx, filepath.Join(sprintsDir, name))
+		if errors.Is(err, core.ErrNoCapacity) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		capacity.Holidays = nil
+		return capacity, name, nil
+	}
+	return nil, "", nil
+}
+
+// buildCapacityReport sums story points per assignee and compares them with
+// the members' capacity.
+func buildCapacityReport(sprint string, capacity core.SprintCapacity, stories []*core.Story) *core.CapacityReport {
+	report := &core.CapacityReport{Sprint: sprint, Capacity: capacity}
+	members := make(map[string]int, len(capacity.Members))
+	for i, m := range capacity.Members {
+		members[m.Name] = i
+		points := capacity.MemberPoints(m)
+		report.Loads = append(report.Loads, core.AssigneeLoad{Assignee: m.Name, Member: true, Capacity: points})
+		report.TeamCapacity += points
+	}
+
+	field := capacity.PointsFieldOrDefault()
+	others := make(map[string]*core.AssigneeLoad)
+	for _, story := range stories {
+		points := storyPoints(story, field)
+		report.TeamCommitted += points
+
+		assignee := storyAssignee(story)
+		var load *core.AssigneeLoad
+		switch i, ok := members[assignee]; {
+		case assignee == "":
+			report.UnassignedPoints += points
+			report.UnassignedStories++
+			continue
+		case ok:
+			load = &report.Loads[i]
+		default:
+			if others[assignee] == nil {
+				others[assignee] = &core.AssigneeLoad{Assignee: assignee}
+			}
+			load = others[assignee]
+		}
+		load.Committed += points
+		load.Stories++
+	}
+
+	names := make([]string, 0, len(others))
+	for name := range others {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		report.Loads = append(report.Loads, *others[name])
+	}
+	report.TeamCapacity = math.Round(report.TeamCapacity*100) / 100
+	return report
+}
+
+func storyAssignee(story *core.Story) string {
+	if story.Assignee == nil {
+		return ""
+	}
+	return strings.TrimSpace(*story.Assignee)
+}
@@ -3,8 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gavin/gitta/internal/core"
@@ -19,19 +19,45 @@ type SprintCloseService interface {
 }
 
 type sprintCloseService struct {
-	storyRepo  core.StoryRepository
-	sprintRepo core.SprintRepository
-	parser     core.StoryParser
-	repoPath   string
+	storyRepo    core.StoryRepository
+	sprintRepo   core.SprintRepository
+	parser       core.StoryParser
+	repoPath     string
+	workflow     core.Workflow
+	events       core.EventBus
+	transactions core.FileTransactions
 }
 
 // NewSprintCloseService creates a new SprintCloseService instance.
 func NewSprintCloseService(storyRepo core.StoryRepository, sprintRepo core.SprintRepository, parser core.StoryParser, repoPath string) SprintCloseService {
+	return NewSprintCloseServiceWithWorkflow(storyRepo, sprintRepo, parser, repoPath, core.DefaultWorkflow())
+}
+
+// NewSprintCloseServiceWithWorkflow creates a SprintCloseService that rolls
+// over the stories outside the done category of workflow, resetting them to
+// its initial status.
+func NewSprintCloseServiceWithWorkflow(storyRepo core.StoryRepository, sprintRepo core.SprintRepository, parser core.StoryParser, repoPath string, workflow core.Workflow) SprintCloseService {
+	return NewSprintCloseServiceWithEvents(storyRepo, sprintRepo, parser, repoPath, workflow, nil)
+}
+
+// NewSprintCloseServiceWithEvents creates a SprintCloseService for the
+// workflow publishing sprint.closed and rollover to events (nil for none).
+func NewSprintCloseServiceWithEvents(storyRepo core.StoryRepository, sprintRepo core.SprintRepository, parser core.StoryParser, repoPath string, workflow core.Workflow, events core.EventBus) SprintCloseService {
+	return NewSprintCloseServiceWithTransactions(storyRepo, sprintRepo, parser, repoPath, workflow, events, nil)
+}
+
+// NewSprintCloseServiceWithTransactions creates a SprintCloseService that
+// rolls tasks over in one transaction of transactions (nil to change files
+// directly).
+func NewSprintCloseServiceWithTransactions(storyRepo core.StoryRepository, sprintRepo core.SprintRepository, parser core.StoryParser, repoPath string, workflow core.Workflow, events core.EventBus, transactions core.FileTransactions) SprintCloseService {
 	return &sprintCloseService{
-		storyRepo:  storyRepo,
-		sprintRepo: sprintRepo,
-		parser:     parser,
-		repoPath:   repoPath,
+		storyRepo:    storyRepo,
+		sprintRepo:   sprintRepo,
+		parser:       parser,
+		repoPath:     repoPath,
+		workflow:     workflow,
+		events:       eventsOrNop(events),
+		transactions: transactionsOrDirect(transactions),
 	}
 }
 
@@ -41,14 +67,29 @@ func (s *sprintCloseService) CloseSprint(ctx context.Context, sprintPath string)
 		return nil, err
 	}
 
+	event := core.Event{Type: core.EventSprintClosed, Data: map[string]interface{}{
+		"sprint": filepath.Base(sprintPath),
+		"path":   sprintPath,
+	}}
+	if err := s.events.Before(ctx, event); err != nil {
+		return nil, err
+	}
+
 	// List all stories in the sprint
 	stories, err := s.storyRepo.ListStories(ctx, sprintPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list stories in sprint: %w", err)
 	}
 
-	// Identify unfinished tasks (status != "done")
-	unfinished := identifyUnfinishedTasks(stories)
+	// Identify unfinished tasks (status outside the done category)
+	unfinished := identifyUnfinishedTasks(stories, s.workflow)
+
+	ids := make([]string, 0, len(unfinished))
+	for _, story := range unfinished {
+		ids = append(ids, story.ID)
+	}
+	event.Data["unfinished"] = ids
+	s.events.After(ctx, event)
 	return unfinished, nil
 }
 
@@ -124,22 +165,46 @@ func (s *sprintCloseService) RolloverTasks(ctx context.Context, req core.Rollove
 		}
 	}
 
-	// Rollover each task atomically
+	event := core.Event{Type: core.EventRollover, Data: map[string]interface{}{
+		"source":  filepath.Base(req.SourceSprintPath),
+		"target":  targetSprintName,
+		"stories": append([]string{}, req.SelectedTaskIDs...),
+	}}
+	if err := s.events.Before(ctx, event); err != nil {
+		return err
+	}
+
+	// Roll all tasks over in one transaction: either every task moves or
+	// none does
+	tx, err := s.transactions.Begin(ctx, fmt.Sprintf("rollover %s to %s", strings.Join(req.SelectedTaskIDs, ", "), targetSprintName))
+	if err != nil {
+		return fmt.Errorf("failed to begin rollover: %w", err)
+	}
+	defer tx.Rollback()
 	rolloverTime := time.Now()
 	for _, story := range selectedStories {
-		if err := s.rolloverTask(ctx, story, req.SourceSprintPath, req.TargetSprintPath, targetSprintName, rolloverTime); err != nil {
+		if err := s.rolloverTask(ctx, tx, story, req.TargetSprintPath, rolloverTime); err != nil {
 			return fmt.Errorf("failed to rollover task %s: %w", story.ID, err)
 		}
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to rollover tasks: %w", err)
+	}
 
+	event.Data["changes"] = map[string]interface{}{
+		"sprint": change(event.Data["source"], targetSprintName),
+	}
+	s.events.After(ctx, event)
 	return nil
 }
 
-// rolloverTask moves a single task from source to target sprint with metadata updates.
+// rolloverTask stages moving a single task to the target sprint with
+// metadata updates. The sprint is the directory the story is in.
 func (s *sprintCloseService) rolloverTask(
 	ctx context.Context,
+	tx core.FileTransaction,
 	story *core.Story,
-	sourcePath, targetPath, targetSprintName string,
+	targetPath string,
 	rolloverTime time.Time,
 ) error {
 	if err := ctx.Err(); err != nil {
@@ -156,69 +221,30 @@ func (s *sprintCloseService) rolloverTask(
 	updatedStory := *story // Copy
 	updatedStory.UpdatedAt = &rolloverTime
 
-	// Reset status to todo if not done
-	if story.Status != core.StatusDone {
-		updatedStory.Status = core.StatusTodo
-	}
-
-	// Sprint field will be added to frontmatter during write
-	// For now, we'll handle it in the YAML marshaling
-
-	// Build target file path
-	fileName := filepath.Base(sourceFilePath)
-	targetFilePath := filepath.Join(targetPath, fileName)
-
-	// Create target directory if needed
-	if err := os.MkdirAll(targetPath, 0755); err != nil {
-		return &core.IOError{
-			Operation: "create",
-			FilePath:  targetPath,
-			Cause:     err,
-		}
+	// Reset status to the initial state if not done
+	if !s.workflow.IsDone(story.Status) {
+		updatedStory.Status = s.workflow.Initial
 	}
 
-	// Write updated story to target (atomic write via parser)
-	if err := s.parser.WriteStory(ctx, targetFilePath, &updatedStory); err != nil {
+	targetFilePath := filepath.Join(targetPath, filepath.Base(sourceFilePath))
+	if err := tx.Write(targetFilePath, func(staged string) error {
+		return s.parser.WriteStory(ctx, staged, &updatedStory)
+	}); err != nil {
 		return fmt.Errorf("failed to write updated story: %w", err)
 	}
-
-	// After successful write, add sprint field to frontmatter
-	// We need to read the file, add sprint field, and write back
-	// For now, we'll do a simple approach: read, modify YAML, write
-	if err := s.addSprintFieldToFile(ctx, targetFilePath, targetSprintName); err != nil {
-		// If adding sprint field fails, the file is already moved, so we'll continue
-		// In a production system, we might want to rollback or log this
-	}
-
-	// Remove source file only after successful target write
-	if err := os.Remove(sourceFilePath); err != nil {
-		// If removal fails, we still have the file at target, which is acceptable
-		// Log warning but don't fail the operation
-		return fmt.Errorf("failed to remove source file (target file created): %w", err)
+	if err := tx.Remove(sourceFilePath); err != nil {
+		return fmt.Errorf("failed to remove source file: %w", err)
 	}
-
-	return nil
-}
-
-// addSprintFieldToFile adds the sprint field to a story file's frontmatter.
-// This is a placeholder - full implementation would require YAML manipulation.
-func (s *sprintCloseService) addSprintFieldToFile(ctx context.Context, filePath, sprintName string) error {
-	// For MVP, we'll skip adding the sprint field to frontmatter
-	// This can be added in a follow-up if needed
-	// Adding it would require reading the raw YAML, modifying it, and writing back
-	_ = ctx
-	_ = filePath
-	_ = sprintName
 	return nil
 }
 
-// identifyUnfinishedTasks identifies tasks that are not completed.
-func identifyUnfinishedTasks(stories []*core.Story) []*core.Story {
+// identifyUnfinishedTasks identifies tasks that are not completed in workflow.
+func identifyUnfinishedTasks(stories []*core.Story, workflow core.Workflow) []*core.Story {
 	var unfinished []*core.Story
 	for _, story := range stories {
-		// Unfinished = status is not "done"
-		// Also handle empty status (defaults to todo, which is unfinished)
-		if story.Status == "" || story.Status != core.StatusDone {
+		// Unfinished = status outside the done category
+		// Also handle empty status (defaults to the initial state, which is unfinished)
+		if !workflow.IsDone(story.Status) {
 			unfinished = append(unfinished, story)
 		}
 	}
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 
 	"github.com/gavin/gitta/internal/core"
@@ -34,15 +36,33 @@ type SprintDoctorService interface {
 }
 
 type sprintDoctorService struct {
-	sprintRepo core.SprintRepository
-	repoPath   string
+	sprintRepo   core.SprintRepository
+	repoPath     string
+	events       core.EventBus
+	transactions core.FileTransactions
 }
 
 // NewSprintDoctorService creates a new SprintDoctorService instance.
 func NewSprintDoctorService(sprintRepo core.SprintRepository, repoPath string) SprintDoctorService {
+	return NewSprintDoctorServiceWithEvents(sprintRepo, repoPath, nil)
+}
+
+// NewSprintDoctorServiceWithEvents creates a SprintDoctorService publishing
+// doctor.fixed for each repair to events (nil for none).
+func NewSprintDoctorServiceWithEvents(sprintRepo core.SprintRepository, repoPath string, events core.EventBus) SprintDoctorService {
+	return NewSprintDoctorServiceWithTransactions(sprintRepo, repoPath, events, nil)
+}
+
+// NewSprintDoctorServiceWithTransactions creates a SprintDoctorService that
+// renames a sprint folder by moving its files in one transaction of
+// transactions, so the repair can be undone. With nil transactions the
+// folder is renamed in place.
+func NewSprintDoctorServiceWithTransactions(sprintRepo core.SprintRepository, repoPath string, events core.EventBus, transactions core.FileTransactions) SprintDoctorService {
 	return &sprintDoctorService{
-		sprintRepo: sprintRepo,
-		repoPath:   repoPath,
+		sprintRepo:   sprintRepo,
+		repoPath:     repoPath,
+		events:       eventsOrNop(events),
+		transactions: transactions,
 	}
 }
 
@@ -141,18 +161,85 @@ func (s *sprintDoctorService) RepairInconsistencies(ctx context.Context, inconsi
 		// Rename folder to match status file
 		oldPath := inc.SprintPath
 
-		if err := s.sprintRepo.RenameSprintWithPrefix(ctx, oldPath, inc.StatusFile, id, desc); err != nil {
+		event := core.Event{Type: core.EventDoctorFixed, Data: map[string]interface{}{
+			"issue":  "sprint_folder",
+			"sprint": inc.ExpectedName,
+			"path":   oldPath,
+		}}
+		if err := s.events.Before(ctx, event); err != nil {
+			result.FailedCount++
+			result.Errors = append(result.Errors, fmt.Errorf("failed to rename %q to %q: %w", inc.FolderName, inc.ExpectedName, err))
+			continue
+		}
+		if err := s.renameSprint(ctx, inc, id, desc); err != nil {
 			result.FailedCount++
 			result.Errors = append(result.Errors, fmt.Errorf("failed to rename %q to %q: %w", inc.FolderName, inc.ExpectedName, err))
 			continue
 		}
 
 		result.RepairedCount++
+		event.Data["path"] = filepath.Join(filepath.Dir(oldPath), inc.ExpectedName)
+		event.Data["changes"] = map[string]interface{}{"folder": change(inc.FolderName, inc.ExpectedName)}
+		s.events.After(ctx, event)
 	}
 
 	return result, nil
 }
 
+// renameSprint renames the folder of inc to its expected name.
+func (s *sprintDoctorService) renameSprint(ctx context.Context, inc Inconsistency, id, desc string) error {
+	if s.transactions == nil {
+		return s.sprintRepo.RenameSprintWithPrefix(ctx, inc.SprintPath, inc.StatusFile, id, desc)
+	}
+
+	newPath := filepath.Join(filepath.Dir(inc.SprintPath), inc.ExpectedName)
+	if _, err := os.Stat(newPath); err == nil {
+		return &core.IOError{Operation: "rename", FilePath: inc.SprintPath, Cause: fmt.Errorf("target path %q already exists", newPath)}
+	}
+	var files []string
+	err := filepath.WalkDir(inc.SprintPath, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		return &core.IOError{Operation: "read", FilePath: inc.SprintPath, Cause: err}
+	}
+
+	tx, err := s.transactions.Begin(ctx, fmt.Sprintf("rename %s to %s", inc.FolderName, inc.ExpectedName))
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, file := range files {
+		rel, err := filepath.Rel(inc.SprintPath, file)
+		if err != nil {
+			return err
+		}
+		if err := tx.Rename(file, filepath.Join(newPath, rel)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	removeEmptyDirs(inc.SprintPath)
+	return nil
+}
+
+// removeEmptyDirs removes dir and its subdirectories, keeping those that
+// still hold files.
+func removeEmptyDirs(dir string) {
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			removeEmptyDirs(filepath.Join(dir, entry.Name()))
+		}
+	}
+	os.Remove(dir)
+}
+
 // extractStatusFromName extracts sprint status from folder name prefix.
 func extractStatusFromName(name string) core.SprintStatus {
 	if len(name) == 0 {
@@ -18,13 +18,21 @@ type SprintStartService interface {
 type sprintStartService struct {
 	sprintRepo core.SprintRepository
 	repoPath   string
+	calendar   core.Calendar
 }
 
 // NewSprintStartService creates a new SprintStartService instance.
 func NewSprintStartService(sprintRepo core.SprintRepository, repoPath string) SprintStartService {
+	return NewSprintStartServiceWithCalendar(sprintRepo, repoPath, core.DefaultCalendar())
+}
+
+// NewSprintStartServiceWithCalendar creates a SprintStartService that counts
+// working-day durations ("10wd") with calendar.
+func NewSprintStartServiceWithCalendar(sprintRepo core.SprintRepository, repoPath string, calendar core.Calendar) SprintStartService {
 	return &sprintStartService{
 		sprintRepo: sprintRepo,
 		repoPath:   repoPath,
+		calendar:   calendar,
 	}
 }
 
@@ -66,6 +74,10 @@ func (s *sprintStartService) StartSprint(ctx context.Context, req core.StartSpri
 	if duration == "" {
 		duration = "2w" // Default: 2 weeks
 	}
+	endDate, err := s.calendar.EndDate(startDate, duration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %w", err)
+	}
 
 	// Build sprint directory path
 	sprintsDir := paths.SprintsPath
@@ -85,6 +97,17 @@ func (s *sprintStartService) StartSprint(ctx context.Context, req core.StartSpri
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sprint: %w", err)
 	}
+	sprint.EndDate = endDate
+
+	// Record the dates for burndown guidelines and projections
+	schedule := &core.SprintSchedule{
+		Start:    s.calendar.StartOfDay(startDate),
+		End:      s.calendar.StartOfDay(endDate),
+		Duration: duration,
+	}
+	if err := s.sprintRepo.WriteSprintSchedule(ctx, sprintDir, schedule); err != nil {
+		return nil, fmt.Errorf("failed to record sprint schedule: %w", err)
+	}
 
 	// Set current sprint link
 	if err := s.sprintRepo.SetCurrentSprint(ctx, sprintsDir, sprintDir); err != nil {
@@ -26,13 +26,21 @@ type SprintStatusService interface {
 type sprintStatusService struct {
 	sprintRepo core.SprintRepository
 	repoPath   string
+	events     core.EventBus
 }
 
 // NewSprintStatusService creates a new SprintStatusService instance.
 func NewSprintStatusService(sprintRepo core.SprintRepository, repoPath string) SprintStatusService {
+	return NewSprintStatusServiceWithEvents(sprintRepo, repoPath, nil)
+}
+
+// NewSprintStatusServiceWithEvents creates a SprintStatusService publishing
+// sprint.activated to events (nil for none).
+func NewSprintStatusServiceWithEvents(sprintRepo core.SprintRepository, repoPath string, events core.EventBus) SprintStatusService {
 	return &sprintStatusService{
 		sprintRepo: sprintRepo,
 		repoPath:   repoPath,
+		events:     eventsOrNop(events),
 	}
 }
 
@@ -65,6 +73,15 @@ func (s *sprintStatusService) ActivateSprint(ctx context.Context, sprintID strin
 		return nil, fmt.Errorf("cannot activate sprint: %w", err)
 	}
 
+	event := core.Event{Type: core.EventSprintActivated, Data: map[string]interface{}{
+		"sprint": filepath.Base(targetSprint.DirectoryPath),
+		"path":   targetSprint.DirectoryPath,
+		"from":   currentStatus.String(),
+	}}
+	if err := s.events.Before(ctx, event); err != nil {
+		return nil, err
+	}
+
 	// Find current active sprint (if any)
 	var archivedSprint *core.Sprint
 	activeSprint, err := s.sprintRepo.FindActiveSprint(ctx, sprintsDir)
@@ -132,6 +149,17 @@ func (s *sprintStatusService) ActivateSprint(ctx context.Context, sprintID strin
 		archivedSprint.DirectoryPath = filepath.Join(filepath.Dir(archivedSprint.DirectoryPath), core.StatusArchived.Prefix()+archivedID+getDescSuffix(archivedDesc))
 	}
 
+	event.Data["sprint"] = filepath.Base(newActivePath)
+	event.Data["path"] = newActivePath
+	event.Data["archived"] = nil
+	if archivedSprint != nil {
+		event.Data["archived"] = filepath.Base(archivedSprint.DirectoryPath)
+	}
+	event.Data["changes"] = map[string]interface{}{
+		"status": change(currentStatus.String(), core.StatusActive.String()),
+	}
+	s.events.After(ctx, event)
+
 	return &SprintActivationResult{
 		Activated: &core.Sprint{
 			Name:          id,
@@ -23,21 +23,37 @@ type StartService interface {
 }
 
 type startService struct {
-	storyRepo core.StoryRepository
-	gitRepo   core.GitRepository
-	parser    core.StoryParser
-	config    StatusEngineConfig
+	storyRepo    core.StoryRepository
+	gitRepo      core.GitRepository
+	parser       core.StoryParser
+	config       StatusEngineConfig
+	events       core.EventBus
+	transactions core.FileTransactions
 }
 
 var assigneePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,50}$`)
 
 // NewStartService constructs a StartService with the provided dependencies.
 func NewStartService(storyRepo core.StoryRepository, gitRepo core.GitRepository, parser core.StoryParser) StartService {
+	return NewStartServiceWithEvents(storyRepo, gitRepo, parser, nil)
+}
+
+// NewStartServiceWithEvents constructs a StartService publishing
+// story.started to events (nil for none).
+func NewStartServiceWithEvents(storyRepo core.StoryRepository, gitRepo core.GitRepository, parser core.StoryParser, events core.EventBus) StartService {
+	return NewStartServiceWithTransactions(storyRepo, gitRepo, parser, events, nil)
+}
+
+// NewStartServiceWithTransactions constructs a StartService writing the
+// assignee in transactions of transactions (nil to write it directly).
+func NewStartServiceWithTransactions(storyRepo core.StoryRepository, gitRepo core.GitRepository, parser core.StoryParser, events core.EventBus, transactions core.FileTransactions) StartService {
 	return &startService{
-		storyRepo: storyRepo,
-		gitRepo:   gitRepo,
-		parser:    parser,
-		config:    loadConfig(),
+		storyRepo:    storyRepo,
+		gitRepo:      gitRepo,
+		parser:       parser,
+		config:       loadConfig(),
+		events:       eventsOrNop(events),
+		transactions: transactionsOrDirect(transactions),
 	}
 }
 
@@ -57,6 +73,10 @@ func (s *startService) Start(ctx context.Context, repoPath, taskIdentifier strin
 	}
 
 	branchName := s.config.BranchPrefix + story.ID
+	before := eventStory(story)
+	if err := s.events.Before(ctx, core.Event{Type: core.EventStoryStarted, Data: map[string]interface{}{"story": before, "path": storyPath, "branch": branchName}}); err != nil {
+		return nil, "", err
+	}
 
 	// Checkout (and create if needed) the branch.
 	if err := s.gitRepo.CheckoutBranch(ctx, repoPath, branchName, false); err != nil {
@@ -78,7 +98,7 @@ func (s *startService) Start(ctx context.Context, repoPath, taskIdentifier strin
 			return story, branchName, fmt.Errorf("%w: %s", ErrInvalidInput, validationErrors[0].Message)
 		}
 
-		if err := s.parser.WriteStory(ctx, storyPath, story); err != nil {
+		if err := s.writeStory(ctx, storyPath, story); err != nil {
 			return story, branchName, &AssigneeUpdateError{
 				FilePath: storyPath,
 				Cause:    err,
@@ -86,9 +106,28 @@ func (s *startService) Start(ctx context.Context, repoPath, taskIdentifier strin
 		}
 	}
 
+	after := eventStory(story)
+	changes := storyChanges(before, after)
+	changes["branch"] = change(nil, branchName)
+	s.events.After(ctx, core.Event{Type: core.EventStoryStarted, Data: map[string]interface{}{"story": after, "path": storyPath, "branch": branchName, "changes": changes}})
 	return story, branchName, nil
 }
 
+// writeStory writes the story with its new assignee in a transaction.
+func (s *startService) writeStory(ctx context.Context, storyPath string, story *core.Story) error {
+	tx, err := s.transactions.Begin(ctx, "assign "+story.ID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := tx.Write(storyPath, func(staged string) error {
+		return s.parser.WriteStory(ctx, staged, story)
+	}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 func (s *startService) resolveStory(ctx context.Context, repoPath, identifier string) (*core.Story, string, error) {
 	// Detect file path (absolute or relative to repoPath).
 	candidatePath := identifier
@@ -33,11 +33,13 @@ type StatusEngine interface {
 	// DeriveStatus derives the status for a single story based on Git branch state.
 	// Returns the derived Status enum value, or an error if derivation fails.
 	//
-	// The derivation follows this priority order:
-	//  1. Explicit Frontmatter status (if set, takes precedence)
-	//  2. Branch existence (no branch → Todo)
-	//  3. Merge status (merged → Done)
-	//  4. Remote branch existence (on remote → Review, local only → Doing)
+	// The derivation follows this priority order (state names shown for the
+	// default workflow; configured workflows use their own derivation rules):
+	//  1. Explicit Frontmatter status (if set and part of the workflow, takes precedence)
+	//  2. Label rules (story tag matches a label-derived state)
+	//  3. Branch existence (no branch → initial state, Todo)
+	//  4. Merge status (merged → Done)
+	//  5. Remote branch existence (on remote → Review, local only → Doing)
 	DeriveStatus(
 		ctx context.Context,
 		story *core.Story,
@@ -45,6 +47,16 @@ type StatusEngine interface {
 		repoPath string,
 	) (core.Status, error)
 
+	// DeriveGitStatus derives the status a story would have without an explicit
+	// Frontmatter status (priorities 2-5 of DeriveStatus). Comparing it with the
+	// explicit status reveals stale overrides.
+	DeriveGitStatus(
+		ctx context.Context,
+		story *core.Story,
+		branchList []core.Branch,
+		repoPath string,
+	) (core.Status, error)
+
 	// DeriveStatusBatch derives status for multiple stories in a single operation.
 	// Uses the same branch list for all stories (more efficient than multiple calls).
 	//
@@ -60,25 +72,34 @@ type StatusEngine interface {
 
 // statusEngine is the implementation of StatusEngine.
 type statusEngine struct {
-	gitRepo core.GitRepository
-	config  StatusEngineConfig
+	gitRepo  core.GitRepository
+	config   StatusEngineConfig
+	workflow core.Workflow
 }
 
 // NewStatusEngine creates a new StatusEngine instance.
 // Uses default configuration (can be overridden via Viper).
 func NewStatusEngine() StatusEngine {
 	return &statusEngine{
-		gitRepo: nil, // Will be set via SetGitRepository or constructor parameter
-		config:  loadConfig(),
+		gitRepo:  nil, // Will be set via SetGitRepository or constructor parameter
+		config:   loadConfig(),
+		workflow: core.DefaultWorkflow(),
 	}
 }
 
 // NewStatusEngineWithRepository creates a StatusEngine with a GitRepository.
 // Useful for dependency injection in tests.
 func NewStatusEngineWithRepository(repo core.GitRepository) StatusEngine {
+	return NewStatusEngineWithWorkflow(repo, core.DefaultWorkflow())
+}
+
+// NewStatusEngineWithWorkflow creates a StatusEngine that derives states of the
+// given workflow using each state's derivation rule.
+func NewStatusEngineWithWorkflow(repo core.GitRepository, workflow core.Workflow) StatusEngine {
 	return &statusEngine{
-		gitRepo: repo,
-		config:  loadConfig(),
+		gitRepo:  repo,
+		config:   loadConfig(),
+		workflow: workflow,
 	}
 }
 
@@ -107,46 +128,74 @@ func (e *statusEngine) DeriveStatus(
 		return "", fmt.Errorf("%w: repository path cannot be empty", ErrInvalidInput)
 	}
 
-	// Priority 1: Check explicit Frontmatter status
-	if story.Status != "" {
-		// Validate status is a valid enum value
-		validStatuses := map[core.Status]bool{
-			core.StatusTodo:   true,
-			core.StatusDoing:  true,
-			core.StatusReview: true,
-			core.StatusDone:   true,
-		}
-		if validStatuses[story.Status] {
-			return story.Status, nil
-		}
-		// Invalid status in Frontmatter - continue with derivation
+	// Priority 1: Check explicit Frontmatter status (defaulted statuses are derived)
+	if story.Status != "" && !story.StatusDefaulted && e.workflow.Has(story.Status) {
+		return story.Status, nil
+	}
+	// Invalid status in Frontmatter - continue with derivation
+
+	return e.DeriveGitStatus(ctx, story, branchList, repoPath)
+}
+
+// DeriveGitStatus derives the status for a single story ignoring its explicit
+// Frontmatter status.
+func (e *statusEngine) DeriveGitStatus(
+	ctx context.Context,
+	story *core.Story,
+	branchList []core.Branch,
+	repoPath string,
+) (core.Status, error) {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrContextCancelled, err)
+	}
+
+	// Input validation
+	if story == nil {
+		return "", fmt.Errorf("%w: story cannot be nil", ErrInvalidStory)
 	}
 
-	// Priority 2: Check branch existence
+	if story.ID == "" {
+		return "", fmt.Errorf("%w: story ID is required", ErrInvalidInput)
+	}
+
+	if repoPath == "" {
+		return "", fmt.Errorf("%w: repository path cannot be empty", ErrInvalidInput)
+	}
+
+	// Priority 2: Check label-derived states
+	if state, ok := e.workflow.StateForTags(story.Tags); ok {
+		return state.Name, nil
+	}
+
+	// Priority 3: Check branch existence
 	matchingBranch := branchMatcher(story.ID, branchList, e.config)
 	if matchingBranch == nil {
-		return core.StatusTodo, nil
+		return e.workflow.Initial, nil
 	}
 
-	// Priority 3: Check merge status (merged branches are always Done)
-	if e.gitRepo != nil {
+	// Priority 4: Check merge status (merged branches are always done)
+	if state, ok := e.workflow.StateFor(core.DeriveMerged); ok && e.gitRepo != nil {
 		merged, err := e.gitRepo.CheckBranchMerged(ctx, repoPath, matchingBranch.Name)
 		if err != nil {
 			// If merge check fails, continue with other checks
 			// (branch might not exist in remote, or repo might be in unusual state)
 			// Error is logged but doesn't block status derivation
 		} else if merged {
-			return core.StatusDone, nil
+			return state.Name, nil
 		}
 	}
 
-	// Priority 4: Check remote branch existence
-	if checkRemoteBranchExists(matchingBranch.Name, branchList) {
-		return core.StatusReview, nil
+	// Priority 5: Check remote branch existence
+	if state, ok := e.workflow.StateFor(core.DerivePushed); ok && checkRemoteBranchExists(matchingBranch.Name, branchList) {
+		return state.Name, nil
 	}
 
 	// Branch exists locally only
-	return core.StatusDoing, nil
+	if state, ok := e.workflow.StateFor(core.DeriveBranch); ok {
+		return state.Name, nil
+	}
+	return e.workflow.Initial, nil
 }
 
 // DeriveStatusBatch derives status for multiple stories in a single operation.
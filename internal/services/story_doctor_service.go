@@ -0,0 +1,422 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// StoryIssueKind identifies the category of a story/branch problem found by the doctor.
+type StoryIssueKind string
+
+const (
+	// IssueOrphanBranch is a story branch whose story file no longer exists.
+	IssueOrphanBranch StoryIssueKind = "orphan_branch"
+	// IssueDoneUnmerged is a story in a done state whose branch is not merged.
+	IssueDoneUnmerged StoryIssueKind = "done_unmerged"
+	// IssueMergedBranch is a merged story branch that has not been deleted.
+	IssueMergedBranch StoryIssueKind = "merged_branch"
+	// IssueStaleDoing is a story in an in-progress state with no commits for longer than the stale threshold.
+	IssueStaleDoing StoryIssueKind = "stale_doing"
+	// IssueDuplicateID is a story ID that appears in more than one file.
+	IssueDuplicateID StoryIssueKind = "duplicate_id"
+)
+
+// defaultStaleAfter is the inactivity threshold used when StoryDoctorOptions.StaleAfter is zero.
+const defaultStaleAfter = 14 * 24 * time.Hour
+
+// archiveBranchPrefix is prepended to orphan branch names when they are archived by --fix.
+const archiveBranchPrefix = "archive/"
+
+// StoryIssue describes a single story or branch problem and whether it can be repaired automatically.
+type StoryIssue struct {
+	Kind    StoryIssueKind `json:"kind"`
+	StoryID string         `json:"story_id"`
+	Branch  string         `json:"branch,omitempty"`
+	Paths   []string       `json:"paths,omitempty"`
+	Message string         `json:"message"`
+	Fixable bool           `json:"fixable"`
+}
+
+// StoryRepairResult contains the results of a story/branch repair operation.
+type StoryRepairResult struct {
+	RepairResult
+	Repaired []StoryIssue // Issues repaired, in the order given
+}
+
+// StoryDoctorOptions configures story issue detection.
+type StoryDoctorOptions struct {
+	// StaleAfter is how long an in-progress story may go without commits before it is reported.
+	// Zero uses the default of 14 days.
+	StaleAfter time.Duration
+}
+
+// StoryDoctorService detects and repairs problems between story files and Git branches.
+type StoryDoctorService interface {
+	// DetectStoryIssues scans all stories and story branches and reports problems.
+	DetectStoryIssues(ctx context.Context, opts StoryDoctorOptions) ([]StoryIssue, error)
+	// RepairStoryIssues applies safe fixes: merged branches are deleted and orphan
+	// branches are archived under archive/. Issues that are not fixable are ignored.
+	RepairStoryIssues(ctx context.Context, issues []StoryIssue) (*StoryRepairResult, error)
+}
+
+type storyDoctorService struct {
+	storyRepo  core.StoryRepository
+	sprintRepo core.SprintRepository
+	gitRepo    core.GitRepository
+	maintainer core.GitBranchMaintainer
+	repoPath   string
+	workflow   core.Workflow
+	config     StatusEngineConfig
+	now        func() time.Time
+	events     core.EventBus
+}
+
+// NewStoryDoctorService creates a new StoryDoctorService instance.
+func NewStoryDoctorService(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	maintainer core.GitBranchMaintainer,
+	repoPath string,
+) StoryDoctorService {
+	return NewStoryDoctorServiceWithWorkflow(storyRepo, sprintRepo, gitRepo, maintainer, repoPath, core.DefaultWorkflow())
+}
+
+// NewStoryDoctorServiceWithWorkflow creates a StoryDoctorService that derives
+// story states of workflow and checks them by category.
+func NewStoryDoctorServiceWithWorkflow(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	maintainer core.GitBranchMaintainer,
+	repoPath string,
+	workflow core.Workflow,
+) StoryDoctorService {
+	return NewStoryDoctorServiceWithEvents(storyRepo, sprintRepo, gitRepo, maintainer, repoPath, workflow, nil)
+}
+
+// NewStoryDoctorServiceWithEvents creates a StoryDoctorService for the
+// workflow publishing doctor.fixed for each repair to events (nil for none).
+func NewStoryDoctorServiceWithEvents(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	maintainer core.GitBranchMaintainer,
+	repoPath string,
+	workflow core.Workflow,
+	events core.EventBus,
+) StoryDoctorService {
+	return &storyDoctorService{
+		storyRepo:  storyRepo,
+		sprintRepo: sprintRepo,
+		gitRepo:    gitRepo,
+		maintainer: maintainer,
+		repoPath:   repoPath,
+		workflow:   workflow,
+		config:     loadConfig(),
+		now:        time.Now,
+		events:     eventsOrNop(events),
+	}
+}
+
+// DetectStoryIssues implements StoryDoctorService.DetectStoryIssues.
+func (s *storyDoctorService) DetectStoryIssues(ctx context.Context, opts StoryDoctorOptions) ([]StoryIssue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	staleAfter := opts.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = defaultStaleAfter
+	}
+
+	located, complete, err := s.collectStories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := s.gitRepo.GetBranchList(ctx, s.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var issues []StoryIssue
+
+	// Duplicate IDs across (or within) directories.
+	storiesByID := make(map[string]*core.Story)
+	filesByID := make(map[string][]string)
+	for _, ls := range located {
+		if _, seen := storiesByID[ls.story.ID]; !seen {
+			storiesByID[ls.story.ID] = ls.story
+		}
+		filesByID[ls.story.ID] = append(filesByID[ls.story.ID], ls.file)
+	}
+	for id, files := range filesByID {
+		if len(files) < 2 {
+			continue
+		}
+		issues = append(issues, StoryIssue{
+			Kind:    IssueDuplicateID,
+			StoryID: id,
+			Paths:   s.relPaths(files),
+			Message: fmt.Sprintf("story ID %s appears in %d files", id, len(files)),
+		})
+	}
+
+	// Branch-based checks.
+	branchByID := make(map[string]*core.Branch)
+	for i := range branches {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		branch := &branches[i]
+		if branch.Type != core.BranchTypeLocal {
+			continue
+		}
+		id, ok := s.storyIDFromBranch(branch.Name)
+		if !ok {
+			continue
+		}
+		branchByID[id] = branch
+
+		story, exists := storiesByID[id]
+		if !exists {
+			issues = append(issues, StoryIssue{
+				Kind:    IssueOrphanBranch,
+				StoryID: id,
+				Branch:  branch.Name,
+				Message: fmt.Sprintf("branch %s has no matching story file", branch.Name),
+				// Only archive when every story file was readable; an unparsable file
+				// may still own this branch.
+				Fixable: complete && !branch.IsCurrent,
+			})
+			continue
+		}
+
+		merged, err := s.gitRepo.CheckBranchMerged(ctx, s.repoPath, branch.Name)
+		if err != nil {
+			// Unknown merge state: skip merge-dependent checks for this branch.
+			continue
+		}
+		if merged {
+			issues = append(issues, StoryIssue{
+				Kind:    IssueMergedBranch,
+				StoryID: id,
+				Branch:  branch.Name,
+				Message: fmt.Sprintf("branch %s is merged but not deleted", branch.Name),
+				Fixable: !branch.IsCurrent,
+			})
+		} else if s.workflow.IsDone(story.Status) {
+			issues = append(issues, StoryIssue{
+				Kind:    IssueDoneUnmerged,
+				StoryID: id,
+				Branch:  branch.Name,
+				Message: fmt.Sprintf("story %s is marked %s but branch %s is not merged", id, story.Status, branch.Name),
+			})
+		}
+	}
+
+	// Stale in-progress stories.
+	engine := NewStatusEngineWithWorkflow(s.gitRepo, s.workflow)
+	now := s.now()
+	for id, story := range storiesByID {
+		status, err := engine.DeriveStatus(ctx, story, branches, s.repoPath)
+		if err != nil {
+			continue
+		}
+		if state, ok := s.workflow.State(status); !ok || state.Category != core.CategoryInProgress {
+			continue
+		}
+
+		var lastActivity time.Time
+		branchName := ""
+		if branch, ok := branchByID[id]; ok && s.maintainer != nil {
+			branchName = branch.Name
+			if t, err := s.maintainer.LastCommitTime(ctx, s.repoPath, branch.Name); err == nil {
+				lastActivity = t
+			}
+		}
+		if lastActivity.IsZero() && story.UpdatedAt != nil {
+			lastActivity = *story.UpdatedAt
+		}
+		if lastActivity.IsZero() || now.Sub(lastActivity) <= staleAfter {
+			continue
+		}
+
+		days := int(now.Sub(lastActivity).Hours() / 24)
+		issues = append(issues, StoryIssue{
+			Kind:    IssueStaleDoing,
+			StoryID: id,
+			Branch:  branchName,
+			Message: fmt.Sprintf("story %s has been in %s with no commits for %d days", id, status, days),
+		})
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind < issues[j].Kind
+		}
+		return issues[i].StoryID < issues[j].StoryID
+	})
+
+	return issues, nil
+}
+
+// RepairStoryIssues implements StoryDoctorService.RepairStoryIssues.
+func (s *storyDoctorService) RepairStoryIssues(ctx context.Context, issues []StoryIssue) (*StoryRepairResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.maintainer == nil {
+		return nil, fmt.Errorf("%w: branch maintainer is required for repairs", ErrInvalidInput)
+	}
+
+	result := &StoryRepairResult{
+		RepairResult: RepairResult{Errors: []error{}},
+		Repaired:     []StoryIssue{},
+	}
+
+	for _, issue := range issues {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+
+		if !issue.Fixable {
+			continue
+		}
+
+		// after is the branch once repaired: deleted (nil) or archived
+		var after interface{}
+		switch issue.Kind {
+		case IssueMergedBranch:
+		case IssueOrphanBranch:
+			after = archiveBranchPrefix + issue.Branch
+		default:
+			continue
+		}
+
+		event := core.Event{Type: core.EventDoctorFixed, Data: map[string]interface{}{
+			"issue":  string(issue.Kind),
+			"branch": issue.Branch,
+		}}
+		if issue.StoryID != "" {
+			event.Data["story"] = map[string]interface{}{"id": issue.StoryID}
+		}
+		err := s.events.Before(ctx, event)
+		if err == nil && issue.Kind == IssueMergedBranch {
+			err = s.maintainer.DeleteBranch(ctx, s.repoPath, issue.Branch)
+		} else if err == nil {
+			err = s.maintainer.RenameBranch(ctx, s.repoPath, issue.Branch, archiveBranchPrefix+issue.Branch)
+		}
+
+		if err != nil {
+			result.FailedCount++
+			result.Errors = append(result.Errors, fmt.Errorf("failed to repair %s (%s): %w", issue.Branch, issue.Kind, err))
+			continue
+		}
+		result.RepairedCount++
+		result.Repaired = append(result.Repaired, issue)
+		event.Data["changes"] = map[string]interface{}{"branch": change(issue.Branch, after)}
+		s.events.After(ctx, event)
+	}
+
+	return result, nil
+}
+
+// locatedStory pairs a story with the file it was read from.
+type locatedStory struct {
+	story *core.Story
+	file  string
+}
+
+// collectStories lists stories from the backlog and every sprint directory.
+// Files that fail to parse are skipped; doctor reports on what it can read.
+// The returned flag is false when at least one file could not be read.
+func (s *storyDoctorService) collectStories(ctx context.Context) ([]locatedStory, bool, error) {
+	paths, err := resolveWorkspacePaths(ctx, s.repoPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	dirs := []string{paths.BacklogPath}
+	sprintNames, err := s.sprintRepo.ListSprints(ctx, paths.SprintsPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list sprints: %w", err)
+	}
+	for _, name := range sprintNames {
+		dirs = append(dirs, filepath.Join(paths.SprintsPath, name))
+	}
+
+	var located []locatedStory
+	complete := true
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			// A missing backlog has no stories
+			if !os.IsNotExist(err) {
+				complete = false
+			}
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".md") {
+				continue
+			}
+			file := filepath.Join(dir, entry.Name())
+			story, err := s.storyRepo.FindStoryByPath(ctx, file)
+			if err != nil {
+				complete = false
+				continue
+			}
+			located = append(located, locatedStory{story: story, file: file})
+		}
+	}
+	return located, complete, nil
+}
+
+// storyIDFromBranch extracts a story ID from a branch name using the configured prefix.
+func (s *storyDoctorService) storyIDFromBranch(branchName string) (string, bool) {
+	prefix := s.config.BranchPrefix
+	var id string
+	if s.config.CaseSensitive {
+		if !strings.HasPrefix(branchName, prefix) {
+			return "", false
+		}
+		id = branchName[len(prefix):]
+	} else {
+		if !strings.HasPrefix(strings.ToLower(branchName), strings.ToLower(prefix)) {
+			return "", false
+		}
+		id = branchName[len(prefix):]
+	}
+	if !idPattern.MatchString(id) {
+		return "", false
+	}
+	return id, true
+}
+
+// relPaths converts absolute paths to repository-relative paths for display.
+func (s *storyDoctorService) relPaths(paths []string) []string {
+	rel := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if r, err := filepath.Rel(s.repoPath, path); err == nil {
+			rel = append(rel, filepath.ToSlash(r))
+		} else {
+			rel = append(rel, path)
+		}
+	}
+	sort.Strings(rel)
+	return rel
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// StoryEditService applies story changes for long-running adapters such as
+// the REST API and the MCP server. Changes are applied one at a time and, when
+// a committer is configured, each is committed with only the files it touched.
+type StoryEditService interface {
+	// CreateStory creates a story in the backlog without opening an editor,
+	// optionally with a Markdown body.
+	CreateStory(ctx context.Context, req CreateStoryRequest, body *string) (*StoryChange, error)
+	// UpdateStory applies a partial edit to a story.
+	UpdateStory(ctx context.Context, storyID string, update StoryUpdate) (*StoryChange, error)
+	// MoveStory moves a story to "backlog", a sprint (by name, title or
+	// "current"), or a directory relative to the repository root.
+	MoveStory(ctx context.Context, storyID, to string, force bool) (*StoryChange, error)
+}
+
+// StoryChange describes an applied change. When committing fails, the change
+// is still returned together with an error wrapping ErrNotCommitted.
+type StoryChange struct {
+	ID string
+	// Path is the story file after the change.
+	Path string
+	// Commit is the hash of the commit recording the change; empty when
+	// changes are not committed or the file did not change.
+	Commit string
+}
+
+type storyEditService struct {
+	parser      core.StoryParser
+	storyRepo   core.StoryRepository
+	board       BoardService
+	create      CreateService
+	update      UpdateService
+	move        MoveService
+	committer   core.GitCommitter
+	repoPath    string
+	backlogPath string
+	workflow    core.Workflow
+
+	// mu serializes changes so a story is never edited by two callers at once
+	// and each commit only holds its own change. Files are still written
+	// atomically, so readers never see partial files.
+	mu sync.Mutex
+}
+
+// NewStoryEditService creates a StoryEditService. committer may be nil to
+// leave changes uncommitted.
+func NewStoryEditService(
+	parser core.StoryParser,
+	storyRepo core.StoryRepository,
+	board BoardService,
+	create CreateService,
+	committer core.GitCommitter,
+	repoPath string,
+	backlogPath string,
+	workflow core.Workflow,
+) StoryEditService {
+	return NewStoryEditServiceWithEvents(parser, storyRepo, board, create, committer, repoPath, backlogPath, workflow, nil)
+}
+
+// NewStoryEditServiceWithEvents creates a StoryEditService whose updates and
+// moves publish their events to events (nil for none). Stories are created
+// by create, which publishes its own events.
+func NewStoryEditServiceWithEvents(
+	parser core.StoryParser,
+	storyRepo core.StoryRepository,
+	board BoardService,
+	create CreateService,
+	committer core.GitCommitter,
+	repoPath string,
+	backlogPath string,
+	workflow core.Workflow,
+	events core.EventBus,
+) StoryEditService {
+	return NewStoryEditServiceWithTransactions(parser, storyRepo, board, create, committer, repoPath, backlogPath, workflow, events, nil)
+}
+
+// NewStoryEditServiceWithTransactions creates a StoryEditService whose
+// updates and moves change files in transactions of transactions (nil to
+// change them directly).
+func NewStoryEditServiceWithTransactions(
+	parser core.StoryParser,
+	storyRepo core.StoryRepository,
+	board BoardService,
+	create CreateService,
+	committer core.GitCommitter,
+	repoPath string,
+	backlogPath string,
+	workflow core.Workflow,
+	events core.EventBus,
+	transactions core.FileTransactions,
+) StoryEditService {
+	return NewStoryEditServiceWithGit(parser, storyRepo, nil, board, create, committer, repoPath, backlogPath, workflow, events, transactions)
+}
+
+// NewStoryEditServiceWithGit creates a StoryEditService whose status updates
+// check the transitions of stories without an explicit status from the
+// status derived from the branches of gitRepo (nil to derive it without
+// branches).
+func NewStoryEditServiceWithGit(
+	parser core.StoryParser,
+	storyRepo core.StoryRepository,
+	gitRepo core.GitRepository,
+	board BoardService,
+	create CreateService,
+	committer core.GitCommitter,
+	repoPath string,
+	backlogPath string,
+	workflow core.Workflow,
+	events core.EventBus,
+	transactions core.FileTransactions,
+) StoryEditService {
+	return &storyEditService{
+		parser:      parser,
+		storyRepo:   storyRepo,
+		board:       board,
+		create:      create,
+		update:      NewUpdateServiceWithGit(parser, storyRepo, gitRepo, repoPath, workflow, events, transactions),
+		move:        NewMoveServiceWithTransactions(parser, storyRepo, repoPath, events, transactions),
+		committer:   committer,
+		repoPath:    repoPath,
+		backlogPath: backlogPath,
+		workflow:    workflow,
+	}
+}
+
+// CreateStory implements StoryEditService.CreateStory.
+func (s *storyEditService) CreateStory(ctx context.Context, req CreateStoryRequest, body *string) (*StoryChange, error) {
+	req.NoEditor = true
+	if req.Status == "" {
+		req.Status = s.workflow.Initial
+	}
+	if req.Priority == "" {
+		req.Priority = core.PriorityMedium
+	}
+
+	// The create service validates the story after writing it; check the
+	// metadata first so invalid requests leave no file behind.
+	draft := &core.Story{ID: "US-001", Title: req.Title, Status: req.Status, Priority: req.Priority, Assignee: req.Assignee, Tags: req.Tags}
+	if errs := s.parser.ValidateStory(draft); len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrValidationFailed, errs[0].Message)
+	}
+
+	return s.apply(ctx, func() (*StoryChange, string, []string, error) {
+		story, path, err := s.create.CreateStory(ctx, req)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if body != nil {
+			if _, path, err = s.update.UpdateStory(ctx, story.ID, StoryUpdate{Body: body}); err != nil {
+				return nil, "", nil, err
+			}
+		}
+		change := &StoryChange{ID: story.ID, Path: path}
+		return change, fmt.Sprintf("Create %s: %s", story.ID, story.Title), []string{path}, nil
+	})
+}
+
+// UpdateStory implements StoryEditService.UpdateStory.
+func (s *storyEditService) UpdateStory(ctx context.Context, storyID string, update StoryUpdate) (*StoryChange, error) {
+	changed := update.Changed()
+	if len(changed) == 0 {
+		return nil, fmt.Errorf("%w: nothing to update", ErrInvalidInput)
+	}
+	if update.Status != nil && !s.workflow.Has(*update.Status) {
+		return nil, fmt.Errorf("%w: invalid status: %s (valid: %s)", ErrInvalidInput, *update.Status, s.workflow)
+	}
+
+	return s.apply(ctx, func() (*StoryChange, string, []string, error) {
+		_, path, err := s.update.UpdateStory(ctx, storyID, update)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		message := fmt.Sprintf("Update %s %s", storyID, strings.Join(changed, ", "))
+		if update.Status != nil && len(changed) == 1 {
+			message = fmt.Sprintf("Set %s status to %s", storyID, *update.Status)
+		}
+		return &StoryChange{ID: storyID, Path: path}, message, []string{path}, nil
+	})
+}
+
+// MoveStory implements StoryEditService.MoveStory.
+func (s *storyEditService) MoveStory(ctx context.Context, storyID, to string, force bool) (*StoryChange, error) {
+	if to == "" || filepath.IsAbs(to) {
+		return nil, fmt.Errorf("%w: target must be \"backlog\", a sprint or a directory relative to the repository", ErrInvalidInput)
+	}
+
+	target := to
+	if strings.EqualFold(to, "backlog") && s.backlogPath != "" {
+		target = s.backlogPath
+	} else if snapshot, err := s.board.Snapshot(ctx); err == nil {
+		if sprint := snapshot.Sprint(to); sprint != nil {
+			target = sprint.Path
+		}
+	}
+	if rel, err := filepath.Rel(s.repoPath, target); err == nil && filepath.IsAbs(target) {
+		target = rel
+	}
+
+	return s.apply(ctx, func() (*StoryChange, string, []string, error) {
+		_, from, err := s.storyRepo.FindStoryByID(ctx, s.repoPath, storyID)
+		if err != nil {
+			if errors.Is(err, core.ErrStoryNotFound) {
+				return nil, "", nil, fmt.Errorf("%w: %s", core.ErrStoryNotFound, storyID)
+			}
+			return nil, "", nil, err
+		}
+		if err := s.move.MoveStory(ctx, storyID, target, force); err != nil {
+			return nil, "", nil, err
+		}
+		_, path, err := s.storyRepo.FindStoryByID(ctx, s.repoPath, storyID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return &StoryChange{ID: storyID, Path: path}, fmt.Sprintf("Move %s to %s", storyID, to), []string{from, path}, nil
+	})
+}
+
+// apply runs fn with the write lock held and commits the paths it returns
+// with its message when a committer is configured.
+func (s *storyEditService) apply(ctx context.Context, fn func() (change *StoryChange, message string, paths []string, err error)) (*StoryChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change, message, paths, err := fn()
+	if err != nil || s.committer == nil {
+		return change, err
+	}
+	hash, err := s.committer.CommitFiles(ctx, s.repoPath, message, paths)
+	if err != nil {
+		return change, fmt.Errorf("%w: %v", ErrNotCommitted, err)
+	}
+	change.Commit = hash
+	return change, nil
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TableFormat is a file format of story tables.
+type TableFormat string
+
+const (
+	TableCSV      TableFormat = "csv"
+	TableJSONL    TableFormat = "jsonl"
+	TableMarkdown TableFormat = "md"
+	TableYAML     TableFormat = "yaml"
+)
+
+// ParseTableFormat returns the format with the given name.
+func ParseTableFormat(name string) (TableFormat, error) {
+	switch format := TableFormat(strings.ToLower(name)); format {
+	case TableCSV, TableJSONL, TableMarkdown, TableYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("%w: unknown format %q (valid: csv, jsonl, md, yaml)", ErrInvalidInput, name)
+}
+
+// Story table columns. Custom frontmatter keys come before body under their
+// own names.
+const (
+	ColumnID            = "id"
+	ColumnTitle         = "title"
+	ColumnStatus        = "status"
+	ColumnDerivedStatus = "derived_status"
+	ColumnPriority      = "priority"
+	ColumnAssignee      = "assignee"
+	ColumnTags          = "tags"
+	ColumnLocation      = "location"
+	ColumnBranch        = "branch"
+	ColumnCreatedAt     = "created_at"
+	ColumnUpdatedAt     = "updated_at"
+	ColumnBody          = "body"
+)
+
+// storyColumns are the built-in columns in export order, except body.
+var storyColumns = []string{
+	ColumnID, ColumnTitle, ColumnStatus, ColumnDerivedStatus, ColumnPriority, ColumnAssignee, ColumnTags,
+	ColumnLocation, ColumnBranch, ColumnCreatedAt, ColumnUpdatedAt,
+}
+
+// readOnlyColumns are derived from Git or the story location, or record its
+// history; bulk updates ignore them.
+var readOnlyColumns = map[string]bool{
+	ColumnDerivedStatus: true, ColumnLocation: true, ColumnBranch: true,
+	ColumnCreatedAt: true, ColumnUpdatedAt: true,
+}
+
+// StoryTable is a list of stories as rows of named columns. Values are strings,
+// numbers, booleans, lists and maps as in YAML frontmatter; nil is an empty
+// cell.
+type StoryTable struct {
+	Columns []string
+	Rows    []map[string]interface{}
+}
+
+// Write writes the table in the given format. CSV and Markdown cells hold
+// lists as comma-separated text and maps as JSON; JSON Lines and YAML keep
+// the values' types.
+func (t *StoryTable) Write(w io.Writer, format TableFormat) error {
+	switch format {
+	case TableCSV:
+		out := csv.NewWriter(w)
+		out.Write(t.Columns)
+		for _, row := range t.Rows {
+			record := make([]string, len(t.Columns))
+			for i, column := range t.Columns {
+				record[i] = tableCell(row[column])
+			}
+			out.Write(record)
+		}
+		out.Flush()
+		return out.Error()
+	case TableJSONL:
+		for _, row := range t.Rows {
+			var line bytes.Buffer
+			line.WriteByte('{')
+			for i, column := range t.Columns {
+				if i > 0 {
+					line.WriteByte(',')
+				}
+				key, _ := json.Marshal(column)
+				value, err := json.Marshal(row[column])
+				if err != nil {
+					return fmt.Errorf("failed to encode %s of %v: %w", column, row[ColumnID], err)
+				}
+				line.Write(key)
+				line.WriteByte(':')
+				line.Write(value)
+			}
+			line.WriteString("}\n")
+			if _, err := w.Write(line.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case TableMarkdown:
+		cells := make([]string, len(t.Columns))
+		for i, column := range t.Columns {
+			cells[i] = markdownCell(column)
+		}
+		fmt.Fprintf(w, "| %s |\n|%s\n", strings.Join(cells, " | "), strings.Repeat(" --- |", len(cells)))
+		for _, row := range t.Rows {
+			for i, column := range t.Columns {
+				cells[i] = markdownCell(tableCell(row[column]))
+			}
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case TableYAML:
+		doc := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, row := range t.Rows {
+			item := &yaml.Node{Kind: yaml.MappingNode}
+			for _, column := range t.Columns {
+				value := &yaml.Node{}
+				if err := value.Encode(row[column]); err != nil {
+					return fmt.Errorf("failed to encode %s of %v: %w", column, row[ColumnID], err)
+				}
+				item.Content = append(item.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: column}, value)
+			}
+			doc.Content = append(doc.Content, item)
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("%w: unknown format %q", ErrInvalidInput, format)
+}
+
+// ReadStoryTable reads a table written by StoryTable.Write in CSV or JSON
+// Lines. CSV cells are text: empty cells are nil. Columns absent from a JSON
+// line are absent from its row.
+func ReadStoryTable(r io.Reader, format TableFormat) (*StoryTable, error) {
+	table := &StoryTable{}
+	switch format {
+	case TableCSV:
+		reader := csv.NewReader(r)
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid CSV: %v", ErrInvalidInput, err)
+		}
+		if len(records) == 0 {
+			return nil, fmt.Errorf("%w: empty CSV", ErrInvalidInput)
+		}
+		table.Columns = records[0]
+		if len(table.Columns) > 0 {
+			table.Columns[0] = strings.TrimPrefix(table.Columns[0], "\uFEFF")
+		}
+		for i := range table.Columns {
+			table.Columns[i] = strings.TrimSpace(table.Columns[i])
+		}
+		for _, record := range records[1:] {
+			row := make(map[string]interface{}, len(record))
+			for i, cell := range record {
+				if cell != "" {
+					row[table.Columns[i]] = cell
+				} else {
+					row[table.Columns[i]] = nil
+				}
+			}
+			table.Rows = append(table.Rows, row)
+		}
+	case TableJSONL:
+		seen := make(map[string]bool)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var row map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &row); err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid JSON: %v", ErrInvalidInput, n, err)
+			}
+			for column := range row {
+				if !seen[column] {
+					seen[column] = true
+					table.Columns = append(table.Columns, column)
+				}
+			}
+			table.Rows = append(table.Rows, jsonToYAMLValues(row))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		sort.Strings(table.Columns)
+	default:
+		return nil, fmt.Errorf("%w: %s cannot be read back (use csv or jsonl)", ErrInvalidInput, format)
+	}
+	if !containsString(table.Columns, ColumnID) {
+		return nil, fmt.Errorf("%w: the %s column is required", ErrInvalidInput, ColumnID)
+	}
+	return table, nil
+}
+
+// jsonToYAMLValues converts JSON numbers that are whole to int, as YAML
+// decodes them.
+func jsonToYAMLValues(row map[string]interface{}) map[string]interface{} {
+	var convert func(v interface{}) interface{}
+	convert = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case float64:
+			if v == float64(int(v)) {
+				return int(v)
+			}
+		case []interface{}:
+			for i := range v {
+				v[i] = convert(v[i])
+			}
+		case map[string]interface{}:
+			for k := range v {
+				v[k] = convert(v[k])
+			}
+		}
+		return v
+	}
+	for k := range row {
+		row[k] = convert(row[k])
+	}
+	return row
+}
+
+// tableCell renders a value as the text of a CSV or Markdown cell.
+func tableCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			if _, ok := item.(map[string]interface{}); ok {
+				data, _ := json.Marshal(v)
+				return string(data)
+			}
+		}
+		return FormatFieldValue(v)
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return FormatFieldValue(value)
+}
+
+// markdownCell escapes a cell of a Markdown table.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "<br>")
+}
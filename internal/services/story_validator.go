@@ -8,12 +8,22 @@ import (
 	"github.com/gavin/gitta/internal/core"
 )
 
+// storyIDExpr is the story ID format: 2 uppercase letters, dash, digits.
+const storyIDExpr = `[A-Z]{2}-[0-9]+`
+
 var (
-	idPattern       = regexp.MustCompile(`^[A-Z]{2}-[0-9]+$`) // Story ID pattern: 2 uppercase letters, dash, digits
-	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)  // Username pattern: alphanumeric, hyphens, underscores
-	tagPattern      = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)  // Tag pattern: alphanumeric, hyphens, underscores
+	idPattern       = regexp.MustCompile(`^` + storyIDExpr + `$`)   // Story ID pattern
+	storyRefPattern = regexp.MustCompile(`\b` + storyIDExpr + `\b`) // Story IDs within text
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)        // Username pattern: alphanumeric, hyphens, underscores
+	tagPattern      = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)        // Tag pattern: alphanumeric, hyphens, underscores
 )
 
+// FindStoryIDs returns the byte ranges of the story IDs mentioned in text,
+// as pairs of start and end offsets.
+func FindStoryIDs(text string) [][]int {
+	return storyRefPattern.FindAllStringIndex(text, -1)
+}
+
 // ValidateStory validates a Story struct against business rules and returns validation errors.
 // It checks all required fields, format constraints, enum values, and business rules.
 // Returns a slice of ValidationErrors describing any violations. An empty slice
@@ -27,7 +37,14 @@ var (
 //   - Status: Optional, if set: must be valid enum (todo, doing, review, done)
 //   - Dates: CreatedAt <= UpdatedAt if both set
 //   - Tags: 0-20 tags, each 1-30 characters, valid format, no duplicates
+//
+// Use ValidateStoryWithRules to validate against a configured workflow.
 func ValidateStory(story *core.Story) []core.ValidationError {
+	return validateStory(story, core.DefaultWorkflow())
+}
+
+// validateStory implements ValidateStory with status checked against workflow.
+func validateStory(story *core.Story, workflow core.Workflow) []core.ValidationError {
 	var errors []core.ValidationError
 
 	if story == nil {
@@ -117,20 +134,12 @@ func ValidateStory(story *core.Story) []core.ValidationError {
 	}
 
 	// Validate Status (empty is valid, will get default)
-	if story.Status != "" {
-		validStatuses := map[core.Status]bool{
-			core.StatusTodo:   true,
-			core.StatusDoing:  true,
-			core.StatusReview: true,
-			core.StatusDone:   true,
-		}
-		if !validStatuses[story.Status] {
-			errors = append(errors, core.ValidationError{
-				Field:   "status",
-				Rule:    "enum",
-				Message: "status must be one of: todo, doing, review, done",
-			})
-		}
+	if story.Status != "" && !workflow.Has(story.Status) {
+		errors = append(errors, core.ValidationError{
+			Field:   "status",
+			Rule:    "enum",
+			Message: "status must be one of: " + workflow.String(),
+		})
 	}
 
 	// Validate dates
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// UndoService reverts the file changes of the last gitta commands.
+type UndoService interface {
+	// Undo reverts the last count operations, newest first, and returns the
+	// operations undone, also when it fails part way.
+	Undo(ctx context.Context, count int, force bool) ([]core.Operation, error)
+}
+
+type undoService struct {
+	log    core.OperationLog
+	events core.EventBus
+}
+
+// NewUndoService creates an UndoService reverting the operations of log and
+// publishing operation.undone to events (nil for none), once per operation.
+func NewUndoService(log core.OperationLog, events core.EventBus) UndoService {
+	return &undoService{log: log, events: eventsOrNop(events)}
+}
+
+// Undo implements UndoService.Undo.
+func (s *undoService) Undo(ctx context.Context, count int, force bool) ([]core.Operation, error) {
+	operations, err := s.log.Operations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Too few operations is reported by the log, before undoing anything
+	if count <= len(operations) {
+		for _, operation := range operations[:count] {
+			if err := s.events.Before(ctx, undoEvent(operation)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	undone, err := s.log.Undo(ctx, count, force)
+	for _, operation := range undone {
+		event := undoEvent(operation)
+		event.Data["changes"] = map[string]interface{}{"undone": change(nil, operation.Command)}
+		s.events.After(ctx, event)
+	}
+	return undone, err
+}
+
+// undoEvent returns the operation.undone event of an operation, without
+// changes.
+func undoEvent(operation core.Operation) core.Event {
+	return core.Event{Type: core.EventOperationUndone, Data: map[string]interface{}{
+		"operation": operation.ID,
+		"command":   operation.Command,
+		"files":     operation.Files,
+		"stories":   operationStories(operation),
+	}}
+}
+
+// operationStories returns the IDs of the story files an operation changed,
+// in order and without duplicates.
+func operationStories(operation core.Operation) []string {
+	stories := []string{}
+	seen := make(map[string]bool)
+	for _, file := range operation.Files {
+		if path.Ext(file) != ".md" {
+			continue
+		}
+		id := strings.TrimSuffix(path.Base(file), ".md")
+		if !seen[id] {
+			seen[id] = true
+			stories = append(stories, id)
+		}
+	}
+	return stories
+}
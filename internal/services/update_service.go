@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gavin/gitta/internal/core"
@@ -10,67 +11,292 @@ import (
 
 // UpdateService handles atomic story status updates.
 type UpdateService interface {
-	// UpdateStatus updates a story's status atomically.
+	// UpdateStatus updates a story's status atomically, enforcing workflow transitions.
 	UpdateStatus(ctx context.Context, storyID string, newStatus core.Status) error
+	// ForceStatus updates a story's status without enforcing workflow transitions.
+	ForceStatus(ctx context.Context, storyID string, newStatus core.Status) error
+	// ClearStatus removes a story's explicit status so it is derived from Git again.
+	ClearStatus(ctx context.Context, storyID string) error
+	// UpdateStory applies a partial edit to a story's status, metadata and body
+	// in a single write. Returns the updated story and its file path.
+	UpdateStory(ctx context.Context, storyID string, update StoryUpdate) (*core.Story, string, error)
+}
+
+// StoryUpdate is a partial story edit; nil fields are left unchanged.
+type StoryUpdate struct {
+	// Status sets an explicit status, enforcing workflow transitions unless
+	// Force is set.
+	Status   *core.Status
+	Force    bool
+	Title    *string
+	Priority *core.Priority
+	// Assignee replaces the assignee; an empty string clears it.
+	Assignee *string
+	Tags     *[]string
+	// Fields sets custom field values keyed by field name; a nil value removes
+	// the field.
+	Fields map[string]interface{}
+	// Body replaces the Markdown body.
+	Body *string
+}
+
+// Changed lists the attributes the update changes, custom fields by name.
+func (u StoryUpdate) Changed() []string {
+	var names []string
+	add := func(set bool, name string) {
+		if set {
+			names = append(names, name)
+		}
+	}
+	add(u.Status != nil, "status")
+	add(u.Title != nil, "title")
+	add(u.Priority != nil, "priority")
+	add(u.Assignee != nil, "assignee")
+	add(u.Tags != nil, "tags")
+	fields := make([]string, 0, len(u.Fields))
+	for name := range u.Fields {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	names = append(names, fields...)
+	add(u.Body != nil, "body")
+	return names
 }
 
 type updateService struct {
-	parser    core.StoryParser
-	storyRepo core.StoryRepository
-	repoPath  string
+	parser       core.StoryParser
+	storyRepo    core.StoryRepository
+	gitRepo      core.GitRepository
+	repoPath     string
+	workflow     core.Workflow
+	events       core.EventBus
+	transactions core.FileTransactions
 }
 
 // NewUpdateService creates a new UpdateService instance.
 func NewUpdateService(parser core.StoryParser, storyRepo core.StoryRepository, repoPath string) UpdateService {
+	return NewUpdateServiceWithWorkflow(parser, storyRepo, repoPath, core.DefaultWorkflow())
+}
+
+// NewUpdateServiceWithWorkflow creates an UpdateService that only accepts states
+// of the given workflow and enforces its declared transitions.
+func NewUpdateServiceWithWorkflow(parser core.StoryParser, storyRepo core.StoryRepository, repoPath string, workflow core.Workflow) UpdateService {
+	return NewUpdateServiceWithEvents(parser, storyRepo, repoPath, workflow, nil)
+}
+
+// NewUpdateServiceWithEvents creates an UpdateService for the workflow
+// publishing status.changed to events (nil for none).
+func NewUpdateServiceWithEvents(parser core.StoryParser, storyRepo core.StoryRepository, repoPath string, workflow core.Workflow, events core.EventBus) UpdateService {
+	return NewUpdateServiceWithTransactions(parser, storyRepo, repoPath, workflow, events, nil)
+}
+
+// NewUpdateServiceWithTransactions creates an UpdateService writing stories
+// in transactions of transactions (nil to write them directly).
+func NewUpdateServiceWithTransactions(parser core.StoryParser, storyRepo core.StoryRepository, repoPath string, workflow core.Workflow, events core.EventBus, transactions core.FileTransactions) UpdateService {
+	return NewUpdateServiceWithGit(parser, storyRepo, nil, repoPath, workflow, events, transactions)
+}
+
+// NewUpdateServiceWithGit creates an UpdateService that checks the
+// transitions of stories without an explicit status from the status derived
+// from the branches of gitRepo (nil to derive it without branches).
+func NewUpdateServiceWithGit(parser core.StoryParser, storyRepo core.StoryRepository, gitRepo core.GitRepository, repoPath string, workflow core.Workflow, events core.EventBus, transactions core.FileTransactions) UpdateService {
 	return &updateService{
-		parser:    parser,
-		storyRepo: storyRepo,
-		repoPath:  repoPath,
+		parser:       parser,
+		storyRepo:    storyRepo,
+		gitRepo:      gitRepo,
+		repoPath:     repoPath,
+		workflow:     workflow,
+		events:       eventsOrNop(events),
+		transactions: transactionsOrDirect(transactions),
 	}
 }
 
 // UpdateStatus implements UpdateService.UpdateStatus.
 func (s *updateService) UpdateStatus(ctx context.Context, storyID string, newStatus core.Status) error {
-	// Validate context
+	return s.setStatus(ctx, storyID, newStatus, true)
+}
+
+// ForceStatus implements UpdateService.ForceStatus.
+func (s *updateService) ForceStatus(ctx context.Context, storyID string, newStatus core.Status) error {
+	return s.setStatus(ctx, storyID, newStatus, false)
+}
+
+// ClearStatus implements UpdateService.ClearStatus.
+func (s *updateService) ClearStatus(ctx context.Context, storyID string) error {
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("context cancelled: %w", err)
 	}
 
-	// Validate status
-	validStatuses := map[core.Status]bool{
-		core.StatusTodo:   true,
-		core.StatusDoing:  true,
-		core.StatusReview: true,
-		core.StatusDone:   true,
+	story, filePath, err := s.findStory(ctx, storyID)
+	if err != nil {
+		return err
 	}
-	if !validStatuses[newStatus] {
-		return fmt.Errorf("invalid status: %s (valid: todo, doing, review, done)", newStatus)
+	if story.StatusDefaulted {
+		return nil // Already derived from Git
 	}
 
-	// Find story by ID
+	// A defaulted status is omitted when the story is written back
+	story.Status = s.workflow.Initial
+	story.StatusDefaulted = true
+	return s.writeStory(ctx, filePath, story)
+}
+
+// UpdateStory implements UpdateService.UpdateStory.
+func (s *updateService) UpdateStory(ctx context.Context, storyID string, update StoryUpdate) (*core.Story, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", fmt.Errorf("context cancelled: %w", err)
+	}
+	if update.Status != nil && !s.workflow.Has(*update.Status) {
+		return nil, "", fmt.Errorf("invalid status: %s (valid: %s)", *update.Status, s.workflow)
+	}
+	for name := range update.Fields {
+		if reservedFieldNames[name] {
+			return nil, "", fmt.Errorf("%w: field %q is a built-in story field", ErrInvalidInput, name)
+		}
+	}
+
+	story, filePath, err := s.findStory(ctx, storyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	current := story.Status
+	if update.Status != nil {
+		if current, err = s.currentStatus(ctx, story); err != nil {
+			return nil, "", err
+		}
+	}
+	if update.Status != nil && !update.Force {
+		// Enforce workflow transitions from the status the story is in
+		if err := s.workflow.ValidateTransition(current, *update.Status); err != nil {
+			return nil, "", fmt.Errorf("%w: %s: %v", ErrInvalidTransition, storyID, err)
+		}
+	}
+
+	// A status change is an event, also when it only makes a defaulted
+	// status explicit
+	var event *core.Event
+	if update.Status != nil && (*update.Status != story.Status || story.StatusDefaulted) {
+		event = &core.Event{Type: core.EventStatusChanged, Data: map[string]interface{}{
+			"story":  eventStory(story),
+			"path":   filePath,
+			"from":   string(current),
+			"to":     string(*update.Status),
+			"forced": update.Force,
+		}}
+		if err := s.events.Before(ctx, *event); err != nil {
+			return nil, "", err
+		}
+	}
+	applyStoryUpdate(story, update)
+
+	if err := s.writeStory(ctx, filePath, story); err != nil {
+		return nil, "", err
+	}
+	if event != nil {
+		after := eventStory(story)
+		event.Data["changes"] = storyChanges(event.Data["story"].(map[string]interface{}), after)
+		event.Data["story"] = after
+		s.events.After(ctx, *event)
+	}
+	return story, filePath, nil
+}
+
+// currentStatus returns the status a story is in: its explicit status, or
+// the status derived from Git when it has none.
+func (s *updateService) currentStatus(ctx context.Context, story *core.Story) (core.Status, error) {
+	if !story.StatusDefaulted {
+		return story.Status, nil
+	}
+	var branches []core.Branch
+	if s.gitRepo != nil {
+		var err error
+		if branches, err = s.gitRepo.GetBranchList(ctx, s.repoPath); err != nil {
+			return "", fmt.Errorf("failed to list branches: %w", err)
+		}
+	}
+	return NewStatusEngineWithWorkflow(s.gitRepo, s.workflow).DeriveStatus(ctx, story, branches, s.repoPath)
+}
+
+// applyStoryUpdate applies an update to a story in memory, without checking
+// workflow transitions.
+func applyStoryUpdate(story *core.Story, update StoryUpdate) {
+	if update.Status != nil {
+		story.Status = *update.Status
+		story.StatusDefaulted = false
+	}
+	if update.Title != nil {
+		story.Title = *update.Title
+	}
+	if update.Priority != nil {
+		story.Priority = *update.Priority
+	}
+	if update.Assignee != nil {
+		story.Assignee = nil
+		if *update.Assignee != "" {
+			assignee := *update.Assignee
+			story.Assignee = &assignee
+		}
+	}
+	if update.Tags != nil {
+		story.Tags = append([]string(nil), (*update.Tags)...)
+	}
+	for name, value := range update.Fields {
+		if value == nil {
+			delete(story.Extra, name)
+			continue
+		}
+		if story.Extra == nil {
+			story.Extra = make(map[string]interface{})
+		}
+		story.Extra[name] = value
+	}
+	if update.Body != nil {
+		story.Body = *update.Body
+	}
+}
+
+// setStatus updates a story's status, optionally enforcing workflow transitions.
+func (s *updateService) setStatus(ctx context.Context, storyID string, newStatus core.Status, enforce bool) error {
+	_, _, err := s.UpdateStory(ctx, storyID, StoryUpdate{Status: &newStatus, Force: !enforce})
+	return err
+}
+
+// findStory locates a story by ID in the repository.
+func (s *updateService) findStory(ctx context.Context, storyID string) (*core.Story, string, error) {
 	story, filePath, err := s.storyRepo.FindStoryByID(ctx, s.repoPath, storyID)
 	if err != nil {
 		if err == core.ErrStoryNotFound {
-			return fmt.Errorf("story not found: %s", storyID)
+			return nil, "", fmt.Errorf("%w: %s", core.ErrStoryNotFound, storyID)
 		}
-		return fmt.Errorf("failed to find story: %w", err)
+		return nil, "", fmt.Errorf("failed to find story: %w", err)
 	}
+	return story, filePath, nil
+}
 
-	// Update status and timestamp
-	story.Status = newStatus
+// writeStory stamps, validates and atomically writes a story.
+func (s *updateService) writeStory(ctx context.Context, filePath string, story *core.Story) error {
 	now := time.Now()
 	story.UpdatedAt = &now
 
 	// Validate story
 	validationErrors := s.parser.ValidateStory(story)
 	if len(validationErrors) > 0 {
-		return fmt.Errorf("story validation failed: %s", validationErrors[0].Message)
+		return fmt.Errorf("%w: %s", ErrValidationFailed, validationErrors[0].Message)
 	}
 
-	// Write atomically (using parser's WriteStory which handles atomic writes)
-	if err := s.parser.WriteStory(ctx, filePath, story); err != nil {
+	tx, err := s.transactions.Begin(ctx, "update "+story.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update story: %w", err)
+	}
+	defer tx.Rollback()
+	if err := tx.Write(filePath, func(staged string) error {
+		return s.parser.WriteStory(ctx, staged, story)
+	}); err != nil {
+		return fmt.Errorf("failed to update story: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to update story: %w", err)
 	}
-
 	return nil
 }
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// VelocityService computes the work completed in archived sprints.
+type VelocityService interface {
+	// Velocity returns completed tasks and points for each archived sprint,
+	// oldest first.
+	Velocity(ctx context.Context, opts VelocityOptions) ([]core.SprintVelocity, error)
+}
+
+// VelocityOptions configures velocity computation.
+type VelocityOptions struct {
+	// PointsField is the numeric story field summed as points. Default: "points".
+	PointsField string
+	// Last keeps only the most recent sprints. Zero keeps all.
+	Last int
+}
+
+type velocityService struct {
+	storyRepo    core.StoryRepository
+	sprintRepo   core.SprintRepository
+	gitRepo      core.GitRepository
+	history      core.GitSprintHistory
+	statusEngine StatusEngine
+	repoPath     string
+	workflow     core.Workflow
+}
+
+// NewVelocityService creates a VelocityService for the default workflow.
+func NewVelocityService(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	history core.GitSprintHistory,
+	repoPath string,
+) VelocityService {
+	return NewVelocityServiceWithWorkflow(storyRepo, sprintRepo, gitRepo, history, repoPath, core.DefaultWorkflow())
+}
+
+// NewVelocityServiceWithWorkflow creates a VelocityService that counts stories
+// in the workflow's done category as completed.
+func NewVelocityServiceWithWorkflow(
+	storyRepo core.StoryRepository,
+	sprintRepo core.SprintRepository,
+	gitRepo core.GitRepository,
+	history core.GitSprintHistory,
+	repoPath string,
+	workflow core.Workflow,
+) VelocityService {
+	return &velocityService{
+		storyRepo:    storyRepo,
+		sprintRepo:   sprintRepo,
+		gitRepo:      gitRepo,
+		history:      history,
+		statusEngine: NewStatusEngineWithWorkflow(gitRepo, workflow),
+		repoPath:     repoPath,
+		workflow:     workflow,
+	}
+}
+
+// Velocity implements VelocityService.Velocity.
+func (s *velocityService) Velocity(ctx context.Context, opts VelocityOptions) ([]core.SprintVelocity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.PointsField == "" {
+		opts.PointsField = "points"
+	}
+
+	paths, err := resolveWorkspacePaths(ctx, s.repoPath)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.sprintRepo.ListSprints(ctx, paths.SprintsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sprints: %w", err)
+	}
+
+	var archived, ids []string
+	for _, name := range names {
+		// Sprints whose status cannot be read are not archived
+		status, err := s.sprintRepo.ReadSprintStatus(ctx, filepath.Join(paths.SprintsPath, name))
+		if err != nil || status != core.StatusArchived {
+			continue
+		}
+		archived = append(archived, name)
+		ids = append(ids, strings.TrimPrefix(name, core.StatusArchived.Prefix()))
+	}
+	if len(archived) == 0 {
+		return []core.SprintVelocity{}, nil
+	}
+
+	periods, err := s.history.SprintPeriods(ctx, core.SprintPeriodRequest{RepoPath: s.repoPath, SprintIDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sprint history: %w", err)
+	}
+
+	branchList, err := s.gitRepo.GetBranchList(ctx, s.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive task status: %w", err)
+	}
+
+	velocities := make([]core.SprintVelocity, 0, len(archived))
+	for i, name := range archived {
+		stories, err := s.storyRepo.ListStories(ctx, filepath.Join(paths.SprintsPath, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stories in %s: %w", name, err)
+		}
+		statuses, err := s.statusEngine.DeriveStatusBatch(ctx, stories, branchList, s.repoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive task status: %w", err)
+		}
+
+		v := core.SprintVelocity{Name: name, Start: periods[i].Start, End: periods[i].End, Tasks: len(stories)}
+		for j, story := range stories {
+			points := storyPoints(story, opts.PointsField)
+			v.Points += points
+			if s.workflow.IsDone(statuses[j]) {
+				v.CompletedTasks++
+				v.CompletedPoints += points
+			}
+		}
+		velocities = append(velocities, v)
+	}
+
+	// Order by when the sprint ran; sprints without Git history go last
+	sort.SliceStable(velocities, func(i, j int) bool {
+		a, b := velocities[i].Start, velocities[j].Start
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.Before(*b)
+	})
+	if opts.Last > 0 && len(velocities) > opts.Last {
+		velocities = velocities[len(velocities)-opts.Last:]
+	}
+	return velocities, nil
+}
+
+// storyPoints returns the numeric value of the story's points field, or 0.
+func storyPoints(story *core.Story, field string) float64 {
+	value, ok := story.FieldValue(field)
+	if !ok {
+		return 0
+	}
+	points, _ := numberValue(value)
+	return points
+}
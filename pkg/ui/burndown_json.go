@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// BurndownDayJSON is one day of 'gitta sprint burndown --format json'
+// (schema: burndown). Its PascalCase keys are the original contract and are
+// kept for existing consumers; the forecast fields are only in BurndownJSON.
+type BurndownDayJSON struct {
+	Date            time.Time
+	RemainingPoints int
+	RemainingTasks  int
+	TotalPoints     *int
+	TotalTasks      *int
+}
+
+// NewBurndownDaysJSON converts the actual values of a burndown chart to the
+// JSON array of 'gitta sprint burndown --format json'.
+func NewBurndownDaysJSON(chart *core.BurndownChart) []BurndownDayJSON {
+	out := make([]BurndownDayJSON, 0, len(chart.Points))
+	for _, dp := range chart.Points {
+		out = append(out, BurndownDayJSON{
+			Date:            dp.Date,
+			RemainingPoints: dp.RemainingPoints,
+			RemainingTasks:  dp.RemainingTasks,
+			TotalPoints:     dp.TotalPoints,
+			TotalTasks:      dp.TotalTasks,
+		})
+	}
+	return out
+}
+
+// BurndownPointJSON is one day of a burndown in JSON output.
+type BurndownPointJSON struct {
+	Date            string   `json:"date"`
+	RemainingPoints int      `json:"remaining_points"`
+	RemainingTasks  int      `json:"remaining_tasks"`
+	TotalPoints     *int     `json:"total_points,omitempty"`
+	TotalTasks      *int     `json:"total_tasks,omitempty"`
+	ScopePoints     int      `json:"scope_points"`
+	IdealPoints     *float64 `json:"ideal_points,omitempty"`
+	ProjectedPoints float64  `json:"projected_points"`
+}
+
+// IdealPointJSON is one day of the ideal burndown line in JSON output.
+type IdealPointJSON struct {
+	Date      string  `json:"date"`
+	Remaining float64 `json:"remaining"`
+}
+
+// ScopeChangeJSON is a scope increase in JSON output.
+type ScopeChangeJSON struct {
+	Date  string `json:"date"`
+	Added int    `json:"added"`
+}
+
+// BurndownJSON is the JSON form of a burndown chart with its forecast, shared
+// by 'gitta sprint burndown --format json --forecast', its templates, the
+// REST API and the MCP server (schema: burndown-forecast).
+type BurndownJSON struct {
+	Start               string              `json:"start"`
+	End                 string              `json:"end"`
+	Scheduled           bool                `json:"scheduled"`
+	Points              []BurndownPointJSON `json:"points"`
+	Ideal               []IdealPointJSON    `json:"ideal"`
+	Slope               float64             `json:"slope"`
+	Intercept           float64             `json:"intercept"`
+	ProjectedCompletion *string             `json:"projected_completion"`
+	AtRisk              bool                `json:"at_risk"`
+	ScopeChanges        []ScopeChangeJSON   `json:"scope_changes"`
+}
+
+// NewBurndownJSON converts a burndown chart to its JSON form with forecast.
+func NewBurndownJSON(chart *core.BurndownChart) BurndownJSON {
+	out := BurndownJSON{
+		Start:        chart.Start.Format("2006-01-02"),
+		End:          chart.End.Format("2006-01-02"),
+		Scheduled:    chart.Scheduled,
+		Points:       make([]BurndownPointJSON, 0, len(chart.Points)),
+		Ideal:        make([]IdealPointJSON, 0, len(chart.Ideal)),
+		Slope:        chart.Slope,
+		Intercept:    chart.Intercept,
+		AtRisk:       chart.AtRisk,
+		ScopeChanges: make([]ScopeChangeJSON, 0, len(chart.ScopeChanges)),
+	}
+	for i, dp := range chart.Points {
+		point := BurndownPointJSON{
+			Date:            dp.Date.Format("2006-01-02"),
+			RemainingPoints: dp.RemainingPoints,
+			RemainingTasks:  dp.RemainingTasks,
+			TotalPoints:     dp.TotalPoints,
+			TotalTasks:      dp.TotalTasks,
+			ScopePoints:     dp.ScopePoints,
+			ProjectedPoints: chart.Projected(i),
+		}
+		if i < len(chart.Ideal) {
+			point.IdealPoints = &chart.Ideal[i].Remaining
+		}
+		out.Points = append(out.Points, point)
+	}
+	for _, ip := range chart.Ideal {
+		out.Ideal = append(out.Ideal, IdealPointJSON{Date: ip.Date.Format("2006-01-02"), Remaining: ip.Remaining})
+	}
+	if chart.ProjectedCompletion != nil {
+		date := chart.ProjectedCompletion.Format("2006-01-02")
+		out.ProjectedCompletion = &date
+	}
+	for _, sc := range chart.ScopeChanges {
+		out.ScopeChanges = append(out.ScopeChanges, ScopeChangeJSON{Date: sc.Date.Format("2006-01-02"), Added: sc.Added})
+	}
+	return out
+}
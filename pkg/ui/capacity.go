@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gavin/gitta/internal/core"
+)
+
+// RenderCapacityReport renders committed points against capacity per
+// assignee and for the team, flagging overcommitment.
+func RenderCapacityReport(report core.CapacityReport) string {
+	tableStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("99")).
+		Padding(0, 1)
+	overStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+
+	const nameWidth = 20
+	row := func(name string, stories int, committed, capacity float64, note string) string {
+		line := fmt.Sprintf("%-*s %7d %9s %9s %6s  %s",
+			nameWidth, truncate(name, nameWidth), stories, formatPoints(committed), formatPoints(capacity), utilization(committed, capacity), note)
+		return strings.TrimRight(line, " ")
+	}
+
+	rows := []string{fmt.Sprintf("%-*s %7s %9s %9s %6s", nameWidth, "Assignee", "Stories", "Committed", "Capacity", "Load")}
+	for _, l := range report.Loads {
+		note := ""
+		switch {
+		case l.Overcommitted() && !l.Member:
+			note = overStyle.Render("OVER (not in capacity)")
+		case l.Overcommitted():
+			note = overStyle.Render(fmt.Sprintf("OVER by %s", formatPoints(l.Committed-l.Capacity)))
+		}
+		rows = append(rows, row(l.Assignee, l.Stories, l.Committed, l.Capacity, note))
+	}
+	if report.UnassignedStories > 0 {
+		rows = append(rows, fmt.Sprintf("%-*s %7d %9s", nameWidth, "(unassigned)", report.UnassignedStories, formatPoints(report.UnassignedPoints)))
+	}
+	teamNote := ""
+	if report.TeamCommitted > report.TeamCapacity {
+		teamNote = overStyle.Render(fmt.Sprintf("OVER by %s", formatPoints(report.TeamCommitted-report.TeamCapacity)))
+	}
+	stories := report.UnassignedStories
+	for _, l := range report.Loads {
+		stories += l.Stories
+	}
+	rows = append(rows, row("Team", stories, report.TeamCommitted, report.TeamCapacity, teamNote))
+
+	c := report.Capacity
+	focus := c.FocusFactor
+	if focus == 0 {
+		focus = 1
+	}
+	summary := fmt.Sprintf("%s: %s working days, %d holidays, focus factor %s, points field %q",
+		report.Sprint, formatPoints(c.Days), len(c.Holidays), formatPoints(focus), c.PointsFieldOrDefault())
+	return summary + "\n" + tableStyle.Render(strings.Join(rows, "\n"))
+}
+
+// utilization renders committed/capacity as a percentage.
+func utilization(committed, capacity float64) string {
+	if capacity <= 0 {
+		if committed > 0 {
+			return "∞"
+		}
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", committed/capacity*100)
+}
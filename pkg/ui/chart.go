@@ -12,15 +12,19 @@ const (
 	chartHeight = 15
 )
 
-// RenderBurndownChart renders an ASCII burndown chart from burndown data points.
-// The chart displays remaining work over time, with optional filtering for points or tasks.
+// RenderBurndownChart renders an ASCII burndown chart from a burndown chart.
+// The chart displays remaining work over the sprint's working days, with optional
+// filtering for points or tasks. When points are shown, the ideal guideline is
+// drawn with '\' and scope-change days are marked with '^' on the time axis.
 // Parameters:
-//   - dataPoints: Array of burndown data points (one per day)
+//   - chart: Burndown data points (one per working day) with ideal line and projection
 //   - showPoints: If true, displays story points trend
 //   - showTasks: If true, displays task count trend
 //
-// Returns a multi-line string containing the ASCII chart with axes, data points, and legend.
-func RenderBurndownChart(dataPoints []core.BurndownDataPoint, showPoints bool, showTasks bool) string {
+// Returns a multi-line string containing the ASCII chart with axes, data points,
+// legend, projected completion and scope changes.
+func RenderBurndownChart(chart core.BurndownChart, showPoints bool, showTasks bool) string {
+	dataPoints := chart.Points
 	if len(dataPoints) == 0 {
 		return "No data available for burndown chart"
 	}
@@ -42,6 +46,9 @@ func RenderBurndownChart(dataPoints []core.BurndownDataPoint, showPoints bool, s
 			maxValue = dp.RemainingTasks
 		}
 	}
+	if showPoints && len(chart.Ideal) > 0 && int(chart.Ideal[0].Remaining) > maxValue {
+		maxValue = int(chart.Ideal[0].Remaining)
+	}
 
 	if maxValue == 0 {
 		maxValue = 1 // Avoid division by zero
@@ -50,105 +57,134 @@ func RenderBurndownChart(dataPoints []core.BurndownDataPoint, showPoints bool, s
 	// Normalize to chart height
 	scale := float64(chartHeight) / float64(maxValue)
 
-	// Create chart grid
-	grid := make([][]rune, chartHeight+1)
-	for i := range grid {
-		grid[i] = make([]rune, chartWidth+1)
-		for j := range grid[i] {
-			grid[i][j] = ' '
-		}
-	}
+	grid := newChartGrid()
 
-	// Draw axes
-	for i := 0; i <= chartHeight; i++ {
-		grid[i][0] = '|'
+	// The x axis spans the whole sprint, one column step per working day
+	step := 0.0
+	if days := chart.Days(); days > 1 {
+		step = float64(chartWidth) / float64(days-1)
 	}
-	for j := 0; j <= chartWidth; j++ {
-		grid[chartHeight][j] = '-'
+
+	// Ideal guideline and scope changes are drawn for points only
+	if showPoints {
+		for idx, ip := range chart.Ideal {
+			x := int(float64(idx) * step)
+			y := chartHeight - int(ip.Remaining*scale)
+			if y >= 0 && y < chartHeight {
+				grid[y][x] = '\\'
+			}
+		}
+		changed := make(map[string]bool, len(chart.ScopeChanges))
+		for _, sc := range chart.ScopeChanges {
+			changed[sc.Date.Format("2006-01-02")] = true
+		}
+		for idx, dp := range dataPoints {
+			if changed[dp.Date.Format("2006-01-02")] {
+				grid[chartHeight][int(float64(idx)*step)] = '^'
+			}
+		}
 	}
 
 	// Plot data points
-	if len(dataPoints) > 0 {
-		step := float64(chartWidth) / float64(len(dataPoints)-1)
+	for idx, dp := range dataPoints {
+		x := int(float64(idx) * step)
+		if x > chartWidth {
+			x = chartWidth
+		}
 
-		for idx, dp := range dataPoints {
-			x := int(float64(idx) * step)
-			if x > chartWidth {
-				x = chartWidth
+		if showPoints {
+			y := chartHeight - int(float64(dp.RemainingPoints)*scale)
+			if y < 0 {
+				y = 0
 			}
-
-			if showPoints {
-				y := chartHeight - int(float64(dp.RemainingPoints)*scale)
-				if y < 0 {
-					y = 0
-				}
-				if y <= chartHeight {
-					grid[y][x] = '*'
-				}
+			if y <= chartHeight {
+				grid[y][x] = '*'
 			}
+		}
 
-			if showTasks {
-				y := chartHeight - int(float64(dp.RemainingTasks)*scale)
-				if y < 0 {
-					y = 0
-				}
-				if y <= chartHeight {
-					if grid[y][x] == '*' {
-						grid[y][x] = '+' // Both overlap
-					} else {
-						grid[y][x] = '#'
-					}
+		if showTasks {
+			y := chartHeight - int(float64(dp.RemainingTasks)*scale)
+			if y < 0 {
+				y = 0
+			}
+			if y <= chartHeight {
+				if grid[y][x] == '*' {
+					grid[y][x] = '+' // Both overlap
+				} else {
+					grid[y][x] = '#'
 				}
 			}
 		}
+	}
 
-		// Draw lines between points
-		for idx := 0; idx < len(dataPoints)-1; idx++ {
-			x1 := int(float64(idx) * step)
-			x2 := int(float64(idx+1) * step)
-
-			var y1, y2 int
-			if showPoints {
-				y1 = chartHeight - int(float64(dataPoints[idx].RemainingPoints)*scale)
-				y2 = chartHeight - int(float64(dataPoints[idx+1].RemainingPoints)*scale)
-			} else {
-				y1 = chartHeight - int(float64(dataPoints[idx].RemainingTasks)*scale)
-				y2 = chartHeight - int(float64(dataPoints[idx+1].RemainingTasks)*scale)
-			}
-
-			drawLine(grid, x1, y1, x2, y2)
+	// Draw lines between points
+	for idx := 0; idx < len(dataPoints)-1; idx++ {
+		x1 := int(float64(idx) * step)
+		x2 := int(float64(idx+1) * step)
+
+		var y1, y2 int
+		if showPoints {
+			y1 = chartHeight - int(float64(dataPoints[idx].RemainingPoints)*scale)
+			y2 = chartHeight - int(float64(dataPoints[idx+1].RemainingPoints)*scale)
+		} else {
+			y1 = chartHeight - int(float64(dataPoints[idx].RemainingTasks)*scale)
+			y2 = chartHeight - int(float64(dataPoints[idx+1].RemainingTasks)*scale)
 		}
-	}
 
-	// Convert grid to strings
-	for i := 0; i <= chartHeight; i++ {
-		line := string(grid[i])
-		lines = append(lines, line)
+		drawLine(grid, x1, y1, x2, y2)
 	}
 
+	lines = gridLines(grid)
+
 	// Add labels
 	lines = append(lines, "")
 	lines = append(lines, fmt.Sprintf("Max: %d", maxValue))
 
 	// Add legend
 	if showPoints && showTasks {
-		lines = append(lines, "Legend: * = Points, # = Tasks, + = Both")
+		lines = append(lines, "Legend: * = Points, # = Tasks, + = Both, \\ = Ideal, ^ = Scope added")
 	} else if showPoints {
-		lines = append(lines, "Legend: * = Story Points")
+		lines = append(lines, "Legend: * = Story Points, \\ = Ideal, ^ = Scope added")
 	} else {
 		lines = append(lines, "Legend: # = Tasks")
 	}
 
 	// Add date range
-	if len(dataPoints) > 0 {
-		startDate := dataPoints[0].Date.Format("2006-01-02")
-		endDate := dataPoints[len(dataPoints)-1].Date.Format("2006-01-02")
-		lines = append(lines, fmt.Sprintf("Date range: %s to %s", startDate, endDate))
+	startDate := dataPoints[0].Date.Format("2006-01-02")
+	endDate := dataPoints[len(dataPoints)-1].Date.Format("2006-01-02")
+	if len(chart.Ideal) > 0 {
+		endDate = chart.Ideal[len(chart.Ideal)-1].Date.Format("2006-01-02")
+	}
+	lines = append(lines, fmt.Sprintf("Date range: %s to %s", startDate, endDate))
+	if !chart.Scheduled && !chart.End.IsZero() {
+		lines = append(lines, "Sprint dates not recorded; assuming a default-length sprint from the first commit")
+	}
+
+	lines = append(lines, FormatBurndownProjection(chart))
+	for _, sc := range chart.ScopeChanges {
+		lines = append(lines, fmt.Sprintf("Scope added: %s (+%d points)", sc.Date.Format("2006-01-02"), sc.Added))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
+// FormatBurndownProjection describes the projected completion date of a
+// burndown chart and whether the sprint is at risk.
+func FormatBurndownProjection(chart core.BurndownChart) string {
+	status := "on track"
+	if chart.AtRisk {
+		status = "AT RISK"
+	}
+	if chart.ProjectedCompletion == nil {
+		if len(chart.Points) < 2 {
+			return "Projected completion: not enough data"
+		}
+		return fmt.Sprintf("Projected completion: never at current pace (%s)", status)
+	}
+	return fmt.Sprintf("Projected completion: %s (%s, trend %.1f points/day)",
+		chart.ProjectedCompletion.Format("2006-01-02"), status, chart.Slope)
+}
+
 // drawLine draws a line between two points using simple characters.
 func drawLine(grid [][]rune, x1, y1, x2, y2 int) {
 	if x1 < 0 {
@@ -210,32 +246,255 @@ func abs(x int) int {
 	return x
 }
 
-// FormatBurndownCSV formats burndown data points as CSV output.
-// The CSV includes columns: Date, RemainingPoints, RemainingTasks, TotalPoints, TotalTasks.
+// FormatBurndownCSV formats a burndown chart as CSV output with one row per
+// working day of the sprint. The CSV includes columns: Date, RemainingPoints,
+// RemainingTasks, TotalPoints, TotalTasks, ScopePoints, IdealPoints,
+// ProjectedPoints, ScopeAdded, AtRisk. Actual values are empty for days not yet
+// reached; AtRisk repeats the sprint-level indicator on every row.
 // Returns a multi-line string with header row followed by data rows.
-func FormatBurndownCSV(dataPoints []core.BurndownDataPoint) string {
+func FormatBurndownCSV(chart core.BurndownChart) string {
 	var lines []string
-	lines = append(lines, "Date,RemainingPoints,RemainingTasks,TotalPoints,TotalTasks")
+	lines = append(lines, "Date,RemainingPoints,RemainingTasks,TotalPoints,TotalTasks,ScopePoints,IdealPoints,ProjectedPoints,ScopeAdded,AtRisk")
 
-	for _, dp := range dataPoints {
-		totalPoints := ""
-		if dp.TotalPoints != nil {
-			totalPoints = fmt.Sprintf("%d", *dp.TotalPoints)
+	added := make(map[string]int, len(chart.ScopeChanges))
+	for _, sc := range chart.ScopeChanges {
+		added[sc.Date.Format("2006-01-02")] = sc.Added
+	}
+
+	for day := 0; day < chart.Days(); day++ {
+		var date, remainingPoints, remainingTasks, totalPoints, totalTasks, scopePoints, ideal, scopeAdded string
+		if day < len(chart.Ideal) {
+			date = chart.Ideal[day].Date.Format("2006-01-02")
+			ideal = fmt.Sprintf("%.2f", chart.Ideal[day].Remaining)
 		}
-		totalTasks := ""
-		if dp.TotalTasks != nil {
-			totalTasks = fmt.Sprintf("%d", *dp.TotalTasks)
+		if day < len(chart.Points) {
+			dp := chart.Points[day]
+			date = dp.Date.Format("2006-01-02")
+			remainingPoints = fmt.Sprintf("%d", dp.RemainingPoints)
+			remainingTasks = fmt.Sprintf("%d", dp.RemainingTasks)
+			if dp.TotalPoints != nil {
+				totalPoints = fmt.Sprintf("%d", *dp.TotalPoints)
+			}
+			if dp.TotalTasks != nil {
+				totalTasks = fmt.Sprintf("%d", *dp.TotalTasks)
+			}
+			scopePoints = fmt.Sprintf("%d", dp.ScopePoints)
+			if n, ok := added[date]; ok {
+				scopeAdded = fmt.Sprintf("%d", n)
+			}
 		}
 
-		line := fmt.Sprintf("%s,%d,%d,%s,%s",
-			dp.Date.Format("2006-01-02"),
-			dp.RemainingPoints,
-			dp.RemainingTasks,
+		line := fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%.2f,%s,%t",
+			date,
+			remainingPoints,
+			remainingTasks,
 			totalPoints,
 			totalTasks,
+			scopePoints,
+			ideal,
+			chart.Projected(day),
+			scopeAdded,
+			chart.AtRisk,
 		)
 		lines = append(lines, line)
 	}
 
 	return strings.Join(lines, "\n")
 }
+
+// RenderBurnupChart renders an ASCII burnup chart: sprint scope ('=') and
+// completed tasks ('#') over time. A rising scope line shows scope creep.
+func RenderBurnupChart(dataPoints []core.BurnupDataPoint) string {
+	if len(dataPoints) == 0 {
+		return "No data available for burnup chart"
+	}
+
+	maxValue := 0
+	scope := make([]int, len(dataPoints))
+	completed := make([]int, len(dataPoints))
+	for i, dp := range dataPoints {
+		scope[i] = dp.ScopeTasks
+		completed[i] = dp.CompletedTasks
+		if dp.ScopeTasks > maxValue {
+			maxValue = dp.ScopeTasks
+		}
+	}
+	if maxValue == 0 {
+		maxValue = 1 // Avoid division by zero
+	}
+
+	grid := newChartGrid()
+	plotSeries(grid, scope, maxValue, '=')
+	plotSeries(grid, completed, maxValue, '#')
+
+	first, last := dataPoints[0], dataPoints[len(dataPoints)-1]
+	lines := gridLines(grid)
+	lines = append(lines, "")
+	lines = append(lines, fmt.Sprintf("Max: %d", maxValue))
+	lines = append(lines, "Legend: = = Scope, # = Completed")
+	lines = append(lines, fmt.Sprintf("Scope: %d → %d tasks (%+d), %d → %d points (%+d)",
+		first.ScopeTasks, last.ScopeTasks, last.ScopeTasks-first.ScopeTasks,
+		first.ScopePoints, last.ScopePoints, last.ScopePoints-first.ScopePoints))
+	lines = append(lines, fmt.Sprintf("Completed: %d/%d tasks, %d/%d points",
+		last.CompletedTasks, last.ScopeTasks, last.CompletedPoints, last.ScopePoints))
+	lines = append(lines, fmt.Sprintf("Date range: %s to %s", first.Date.Format("2006-01-02"), last.Date.Format("2006-01-02")))
+
+	return strings.Join(lines, "\n")
+}
+
+// flowMarks are the fill characters of cumulative flow bands, bottom first.
+var flowMarks = []rune{'#', '=', '+', ':', '%', 'o', '~', '@', '*', '.'}
+
+// RenderCumulativeFlowChart renders an ASCII cumulative flow diagram. Bands are
+// stacked with the last workflow state (usually done) at the bottom.
+func RenderCumulativeFlowChart(flow core.CumulativeFlow) string {
+	if len(flow.Points) == 0 {
+		return "No data available for cumulative flow diagram"
+	}
+
+	maxValue := 0
+	for _, dp := range flow.Points {
+		total := 0
+		for _, state := range flow.States {
+			total += dp.Counts[state]
+		}
+		if total > maxValue {
+			maxValue = total
+		}
+	}
+	if maxValue == 0 {
+		maxValue = 1 // Avoid division by zero
+	}
+	scale := float64(chartHeight) / float64(maxValue)
+
+	grid := newChartGrid()
+	stack := stackOrder(flow.States)
+	for x := 1; x <= chartWidth; x++ {
+		dp := flow.Points[pointIndex(x, len(flow.Points))]
+		base := 0
+		for i, state := range stack {
+			top := base + dp.Counts[state]
+			from := int(float64(base)*scale + 0.5)
+			to := int(float64(top)*scale + 0.5)
+			for h := from; h < to && h < chartHeight; h++ {
+				grid[chartHeight-1-h][x] = flowMarks[i%len(flowMarks)]
+			}
+			base = top
+		}
+	}
+
+	lines := gridLines(grid)
+	lines = append(lines, "")
+	lines = append(lines, fmt.Sprintf("Max: %d", maxValue))
+	legend := make([]string, 0, len(stack))
+	for i, state := range stack {
+		legend = append(legend, fmt.Sprintf("%c = %s", flowMarks[i%len(flowMarks)], state))
+	}
+	lines = append(lines, "Legend: "+strings.Join(legend, ", "))
+	lines = append(lines, fmt.Sprintf("Date range: %s to %s",
+		flow.Points[0].Date.Format("2006-01-02"),
+		flow.Points[len(flow.Points)-1].Date.Format("2006-01-02")))
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatBurnupCSV formats burnup data points as CSV output with columns
+// Date, ScopeTasks, CompletedTasks, ScopePoints, CompletedPoints.
+func FormatBurnupCSV(dataPoints []core.BurnupDataPoint) string {
+	lines := []string{"Date,ScopeTasks,CompletedTasks,ScopePoints,CompletedPoints"}
+	for _, dp := range dataPoints {
+		lines = append(lines, fmt.Sprintf("%s,%d,%d,%d,%d",
+			dp.Date.Format("2006-01-02"), dp.ScopeTasks, dp.CompletedTasks, dp.ScopePoints, dp.CompletedPoints))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatCumulativeFlowCSV formats a cumulative flow as CSV output with a Date
+// column followed by one column per status in workflow order.
+func FormatCumulativeFlowCSV(flow core.CumulativeFlow) string {
+	header := []string{"Date"}
+	for _, state := range flow.States {
+		header = append(header, string(state))
+	}
+	lines := []string{strings.Join(header, ",")}
+	for _, dp := range flow.Points {
+		row := []string{dp.Date.Format("2006-01-02")}
+		for _, state := range flow.States {
+			row = append(row, fmt.Sprintf("%d", dp.Counts[state]))
+		}
+		lines = append(lines, strings.Join(row, ","))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newChartGrid returns an empty chart grid with axes drawn.
+func newChartGrid() [][]rune {
+	grid := make([][]rune, chartHeight+1)
+	for i := range grid {
+		grid[i] = make([]rune, chartWidth+1)
+		for j := range grid[i] {
+			grid[i][j] = ' '
+		}
+	}
+	for i := 0; i <= chartHeight; i++ {
+		grid[i][0] = '|'
+	}
+	for j := 0; j <= chartWidth; j++ {
+		grid[chartHeight][j] = '-'
+	}
+	return grid
+}
+
+// plotSeries draws values as marks joined by dotted lines, scaled to maxValue.
+func plotSeries(grid [][]rune, values []int, maxValue int, mark rune) {
+	scale := float64(chartHeight) / float64(maxValue)
+	step := 0.0
+	if len(values) > 1 {
+		step = float64(chartWidth) / float64(len(values)-1)
+	}
+
+	xs := make([]int, len(values))
+	ys := make([]int, len(values))
+	for i, v := range values {
+		xs[i] = int(float64(i) * step)
+		ys[i] = chartHeight - int(float64(v)*scale)
+		if ys[i] < 0 {
+			ys[i] = 0
+		}
+		grid[ys[i]][xs[i]] = mark
+	}
+	for i := 0; i < len(values)-1; i++ {
+		drawLine(grid, xs[i], ys[i], xs[i+1], ys[i+1])
+	}
+}
+
+// gridLines converts a chart grid to text lines.
+func gridLines(grid [][]rune) []string {
+	lines := make([]string, 0, len(grid))
+	for _, row := range grid {
+		lines = append(lines, string(row))
+	}
+	return lines
+}
+
+// pointIndex maps chart column x (1..chartWidth) to a data point index.
+func pointIndex(x, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	idx := int(float64(x-1)/float64(chartWidth-1)*float64(n-1) + 0.5)
+	if idx >= n {
+		idx = n - 1
+	}
+	return idx
+}
+
+// stackOrder returns states bottom-first: the last workflow state (usually
+// done) sits at the bottom of a cumulative flow diagram.
+func stackOrder(states []core.Status) []core.Status {
+	stack := make([]core.Status, len(states))
+	for i, state := range states {
+		stack[len(states)-1-i] = state
+	}
+	return stack
+}
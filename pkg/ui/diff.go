@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' (kept), '-' (removed) or '+' (added).
+// oldIdx and newIdx are the 0-based positions in each input before the op applies.
+type diffOp struct {
+	kind   byte
+	text   string
+	oldIdx int
+	newIdx int
+}
+
+// UnifiedDiff renders a line-based unified diff between oldText and newText.
+// It returns an empty string when the inputs are identical. Intended for
+// small files such as stories; it uses an O(n*m) LCS.
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	i := 0
+	for i < len(ops) {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i >= len(ops) {
+			break
+		}
+
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for {
+			for end < len(ops) && ops[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next < len(ops) && next-end <= 2*diffContext {
+				end = next
+				continue
+			}
+			end += diffContext
+			if end > len(ops) {
+				end = len(ops)
+			}
+			break
+		}
+
+		writeHunk(&out, ops[start:end])
+		i = end
+	}
+
+	return out.String()
+}
+
+// writeHunk writes a single @@ hunk for the given slice of ops.
+func writeHunk(out *strings.Builder, hunk []diffOp) {
+	oldCount, newCount := 0, 0
+	for _, op := range hunk {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	oldStart, newStart := hunk[0].oldIdx, hunk[0].newIdx
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, op := range hunk {
+		out.WriteByte(op.kind)
+		out.WriteString(op.text)
+		out.WriteByte('\n')
+	}
+}
+
+// diffLines computes a minimal edit script from a to b using a longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', text: a[i], oldIdx: i, newIdx: j})
+			i++
+			j++
+		case j >= m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{kind: '-', text: a[i], oldIdx: i, newIdx: j})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: b[j], oldIdx: i, newIdx: j})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines splits text into lines without their trailing newline. Carriage
+// returns are kept so that line-ending changes show up in the diff.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "identical",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "single change",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "insert into empty",
+			old:  "",
+			new:  "x\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("old", "new", tt.old, tt.new)
+			if got != tt.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_SplitsDistantHunks(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a' + i))
+		oldLines = append(oldLines, line)
+		if i == 1 || i == 18 {
+			line = strings.ToUpper(line)
+		}
+		newLines = append(newLines, line)
+	}
+
+	got := UnifiedDiff("old", "new", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Fatalf("expected 2 hunks, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@") || !strings.Contains(got, "@@ -16,5 +16,5 @@") {
+		t.Errorf("unexpected hunk headers:\n%s", got)
+	}
+}
@@ -0,0 +1,260 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gavin/gitta/internal/core"
+)
+
+const (
+	day            = 24 * time.Hour
+	histogramWidth = 40
+	metricWidth    = 16
+	durationWidth  = 8
+)
+
+// histogramBuckets are the upper bounds of duration histogram buckets; the
+// last bucket is open-ended.
+var histogramBuckets = []time.Duration{1 * day, 2 * day, 3 * day, 5 * day, 8 * day, 13 * day, 21 * day}
+
+// FormatDuration renders a duration in days and hours ("3d 4h"), hours and
+// minutes below a day ("5h 12m"), or minutes below an hour ("12m").
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d >= day:
+		days := d / day
+		return fmt.Sprintf("%dd %dh", days, (d-days*day)/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", d/time.Hour, (d%time.Hour)/time.Minute)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// RenderFlowReport renders the flow summary table, lead and cycle time
+// histograms and a cycle time scatterplot.
+func RenderFlowReport(report core.FlowReport) string {
+	if len(report.Stories) == 0 {
+		return fmt.Sprintf("No merged stories found (%d in progress)", report.InProgress)
+	}
+
+	sections := []string{
+		renderFlowSummary(report),
+		fmt.Sprintf("%d merged, %d in progress", len(report.Stories), report.InProgress),
+		"Lead time (created → merged)\n" + RenderDurationHistogram(flowSamples(report.Stories, leadTime)),
+		"Cycle time (first branch commit → merged)\n" + RenderDurationHistogram(flowSamples(report.Stories, cycleTime)),
+		"Cycle time by merge date\n" + RenderCycleTimeScatter(report.Stories, report.CycleTime.P85),
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// renderFlowSummary renders one row per distribution.
+func renderFlowSummary(report core.FlowReport) string {
+	tableStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("99")).
+		Padding(0, 1)
+
+	row := func(name string, stats core.DurationStats) string {
+		cells := []string{fmt.Sprintf("%-*s %5d", metricWidth, truncate(name, metricWidth), stats.Count)}
+		for _, d := range []time.Duration{stats.Mean, stats.P50, stats.P85, stats.P95, stats.Max} {
+			value := "-"
+			if stats.Count > 0 {
+				value = FormatDuration(d)
+			}
+			cells = append(cells, fmt.Sprintf("%*s", durationWidth, value))
+		}
+		return strings.Join(cells, " ")
+	}
+
+	header := fmt.Sprintf("%-*s %5s", metricWidth, "Metric", "Count")
+	for _, name := range []string{"Mean", "P50", "P85", "P95", "Max"} {
+		header += fmt.Sprintf(" %*s", durationWidth, name)
+	}
+
+	rows := []string{header, row("Lead time", report.LeadTime), row("Cycle time", report.CycleTime)}
+	for _, status := range report.TimeInStatus {
+		rows = append(rows, row("In "+string(status.Status), status.Stats))
+	}
+	return tableStyle.Render(strings.Join(rows, "\n"))
+}
+
+// RenderDurationHistogram renders samples as horizontal bars over day-based
+// buckets.
+func RenderDurationHistogram(samples []time.Duration) string {
+	if len(samples) == 0 {
+		return "No data available for histogram"
+	}
+
+	counts := make([]int, len(histogramBuckets)+1)
+	for _, d := range samples {
+		i := 0
+		for i < len(histogramBuckets) && d >= histogramBuckets[i] {
+			i++
+		}
+		counts[i]++
+	}
+	maxCount := 0
+	for _, c := range counts {
+		if c > maxCount {
+			maxCount = c
+		}
+	}
+
+	lines := make([]string, 0, len(counts))
+	lower := time.Duration(0)
+	for i, c := range counts {
+		var label string
+		if i < len(histogramBuckets) {
+			label = fmt.Sprintf("%d-%dd", lower/day, histogramBuckets[i]/day)
+			lower = histogramBuckets[i]
+		} else {
+			label = fmt.Sprintf("%dd+", lower/day)
+		}
+		bar := strings.Repeat("#", c*histogramWidth/maxCount)
+		if c > 0 && bar == "" {
+			bar = "#"
+		}
+		lines = append(lines, fmt.Sprintf("%7s | %-*s %d", label, histogramWidth, bar, c))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderCycleTimeScatter plots each story's cycle time ('*') against its merge
+// date, with the 85th percentile drawn as a dotted line. Overlapping stories
+// are shown as a count.
+func RenderCycleTimeScatter(stories []core.StoryFlow, p85 time.Duration) string {
+	type point struct {
+		merged time.Time
+		cycle  time.Duration
+	}
+	var points []point
+	for _, s := range stories {
+		if s.CycleTime != nil && s.Timeline.Merged != nil {
+			points = append(points, point{*s.Timeline.Merged, *s.CycleTime})
+		}
+	}
+	if len(points) == 0 {
+		return "No data available for scatterplot"
+	}
+
+	first, last := points[0].merged, points[0].merged
+	maxCycle := p85
+	for _, p := range points {
+		if p.merged.Before(first) {
+			first = p.merged
+		}
+		if p.merged.After(last) {
+			last = p.merged
+		}
+		if p.cycle > maxCycle {
+			maxCycle = p.cycle
+		}
+	}
+	if maxCycle <= 0 {
+		maxCycle = 1 // Avoid division by zero
+	}
+	span := last.Sub(first)
+
+	row := func(d time.Duration) int {
+		return chartHeight - 1 - int(float64(d)/float64(maxCycle)*float64(chartHeight-1)+0.5)
+	}
+
+	grid := newChartGrid()
+	if p85 > 0 {
+		for x := 1; x <= chartWidth; x++ {
+			grid[row(p85)][x] = '.'
+		}
+	}
+	counts := make(map[[2]int]int)
+	for _, p := range points {
+		x := 1
+		if span > 0 {
+			x = 1 + int(float64(p.merged.Sub(first))/float64(span)*float64(chartWidth-1)+0.5)
+		}
+		y := row(p.cycle)
+		counts[[2]int{y, x}]++
+		switch n := counts[[2]int{y, x}]; {
+		case n == 1:
+			grid[y][x] = '*'
+		case n < 10:
+			grid[y][x] = rune('0' + n)
+		default:
+			grid[y][x] = '+'
+		}
+	}
+
+	lines := gridLines(grid)
+	lines = append(lines, "")
+	lines = append(lines, fmt.Sprintf("Max: %s", FormatDuration(maxCycle)))
+	lines = append(lines, "Legend: * = story (2-9 = overlapping stories), . = 85th percentile")
+	lines = append(lines, fmt.Sprintf("Date range: %s to %s", first.Format("2006-01-02"), last.Format("2006-01-02")))
+	return strings.Join(lines, "\n")
+}
+
+// FormatFlowCSV formats per-story flow metrics as CSV. Timestamps are RFC 3339
+// and durations are in hours; missing values are left empty.
+func FormatFlowCSV(report core.FlowReport) string {
+	header := []string{"ID", "Title", "Created", "BranchCreated", "FirstPush", "Merged", "LeadTimeHours", "CycleTimeHours"}
+	for _, status := range report.TimeInStatus {
+		header = append(header, "In_"+string(status.Status)+"_Hours")
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	_ = w.Write(header)
+	for _, s := range report.Stories {
+		record := []string{
+			s.ID,
+			s.Title,
+			csvTime(s.Timeline.Created),
+			csvTime(s.Timeline.BranchCreated),
+			csvTime(s.Timeline.FirstPush),
+			csvTime(s.Timeline.Merged),
+			csvHours(s.LeadTime),
+			csvHours(s.CycleTime),
+		}
+		for _, status := range report.TimeInStatus {
+			if d, ok := s.InStatus[status.Status]; ok {
+				record = append(record, csvHours(&d))
+			} else {
+				record = append(record, "")
+			}
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func csvHours(d *time.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", d.Hours())
+}
+
+func leadTime(s core.StoryFlow) *time.Duration  { return s.LeadTime }
+func cycleTime(s core.StoryFlow) *time.Duration { return s.CycleTime }
+
+// flowSamples collects the non-nil durations selected by metric.
+func flowSamples(stories []core.StoryFlow, metric func(core.StoryFlow) *time.Duration) []time.Duration {
+	var samples []time.Duration
+	for _, s := range stories {
+		if d := metric(s); d != nil {
+			samples = append(samples, *d)
+		}
+	}
+	return samples
+}
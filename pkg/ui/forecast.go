@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gavin/gitta/internal/core"
+)
+
+// RenderVelocityTable renders one row per sprint with completed and planned
+// tasks and points, followed by the averages.
+func RenderVelocityTable(velocities []core.SprintVelocity) string {
+	if len(velocities) == 0 {
+		return "No archived sprints found"
+	}
+
+	tableStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("99")).
+		Padding(0, 1)
+
+	const nameWidth = 24
+	rows := []string{fmt.Sprintf("%-*s %-10s %-10s %9s %13s", nameWidth, "Sprint", "Start", "End", "Tasks", "Points")}
+	var tasks, points float64
+	for _, v := range velocities {
+		rows = append(rows, fmt.Sprintf("%-*s %-10s %-10s %9s %13s",
+			nameWidth, truncate(v.Name, nameWidth),
+			formatDate(v.Start), formatDate(v.End),
+			fmt.Sprintf("%d/%d", v.CompletedTasks, v.Tasks),
+			formatPoints(v.CompletedPoints)+"/"+formatPoints(v.Points)))
+		tasks += float64(v.CompletedTasks)
+		points += v.CompletedPoints
+	}
+
+	n := float64(len(velocities))
+	summary := fmt.Sprintf("Average: %.1f tasks, %.1f points per sprint", tasks/n, points/n)
+	return tableStyle.Render(strings.Join(rows, "\n")) + "\n" + summary
+}
+
+// FormatVelocityCSV formats velocities as CSV, one row per sprint.
+func FormatVelocityCSV(velocities []core.SprintVelocity) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	_ = w.Write([]string{"Sprint", "Start", "End", "Tasks", "CompletedTasks", "Points", "CompletedPoints"})
+	for _, v := range velocities {
+		_ = w.Write([]string{
+			v.Name,
+			csvTime(v.Start),
+			csvTime(v.End),
+			strconv.Itoa(v.Tasks),
+			strconv.Itoa(v.CompletedTasks),
+			formatPoints(v.Points),
+			formatPoints(v.CompletedPoints),
+		})
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// RenderCompletionForecast renders the sprints and dates by which the items
+// are done at each confidence level.
+func RenderCompletionForecast(forecast core.CompletionForecast) string {
+	if forecast.Items == 0 {
+		return "No remaining items to forecast"
+	}
+
+	lines := []string{
+		fmt.Sprintf("Forecast for %d items (%d trials over %d sprints of history, %s per sprint)",
+			forecast.Items, forecast.Trials, len(forecast.Throughput), FormatDuration(forecast.SprintLength)),
+		"",
+		fmt.Sprintf("%-10s %8s  %s", "Confidence", "Sprints", "Done by"),
+	}
+	for _, o := range forecast.Outcomes {
+		lines = append(lines, fmt.Sprintf("%9d%% %8d  %s", o.Confidence, o.Sprints, o.Date.Format("2006-01-02")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderCapacityForecast renders the number of items completed in the next
+// sprints at each confidence level.
+func RenderCapacityForecast(forecast core.CapacityForecast) string {
+	noun := "sprint"
+	if forecast.Sprints != 1 {
+		noun = fmt.Sprintf("%d sprints", forecast.Sprints)
+	}
+	lines := []string{
+		fmt.Sprintf("Capacity of the next %s (%d trials over %d sprints of history)",
+			noun, forecast.Trials, len(forecast.Throughput)),
+		"",
+		fmt.Sprintf("%-10s %8s", "Confidence", "Items"),
+	}
+	for _, o := range forecast.Outcomes {
+		lines = append(lines, fmt.Sprintf("%9d%% %7d+", o.Confidence, o.Items))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+// formatPoints renders points with up to two decimals and none for whole points.
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(math.Round(points*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// pngDash is the on and off length of dashed strokes, matching the SVG
+// stroke-dasharray.
+var pngDash = [2]float64{6, 4}
+
+// PNG rasterises the chart to a PNG image of the same size as the SVG
+// document. Text is drawn with a built-in bitmap font, so the result needs no
+// fonts or external tools.
+func (c *Chart) PNG() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, svgWidth, svgHeight))
+	for _, e := range c.elements {
+		col := parseHexColor(e.color)
+		switch e.kind {
+		case rectElement:
+			fillRect(img, e.points[0].X, e.points[0].Y, e.points[1].X, e.points[1].Y, col)
+		case lineElement, polylineElement:
+			strokePath(img, e.points, e.width, e.dashed, col)
+		case polygonElement:
+			fill := col
+			if e.opacity > 0 {
+				fill.A = uint8(e.opacity*255 + 0.5)
+			}
+			fillPolygon(img, e.points, fill)
+			outline := append(append([]chartPoint{}, e.points...), e.points[0])
+			strokePath(img, outline, 1, false, col)
+		case textElement:
+			if e.color == "" {
+				col = color.RGBA{A: 0xff}
+			}
+			drawText(img, e, col)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseHexColor parses a "#rrggbb" color; anything else is black.
+func parseHexColor(s string) color.RGBA {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(s) != 7 {
+		return color.RGBA{A: 0xff}
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
+
+// blend draws col over the pixel at (x, y), honouring its alpha.
+func blend(img *image.RGBA, x, y int, col color.RGBA) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	if col.A == 0xff {
+		img.SetRGBA(x, y, col)
+		return
+	}
+	dst := img.RGBAAt(x, y)
+	a := uint32(col.A)
+	mix := func(s, d uint8) uint8 { return uint8((uint32(s)*a + uint32(d)*(255-a)) / 255) }
+	img.SetRGBA(x, y, color.RGBA{R: mix(col.R, dst.R), G: mix(col.G, dst.G), B: mix(col.B, dst.B), A: 0xff})
+}
+
+func fillRect(img *image.RGBA, x, y, width, height float64, col color.RGBA) {
+	x0, y0 := int(math.Round(x)), int(math.Round(y))
+	x1, y1 := int(math.Round(x+width)), int(math.Round(y+height))
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			blend(img, px, py, col)
+		}
+	}
+}
+
+// strokePath draws the segments joining points with square pens of the given
+// width, skipping the off parts of the dash pattern when dashed.
+func strokePath(img *image.RGBA, points []chartPoint, width float64, dashed bool, col color.RGBA) {
+	pen := int(math.Max(1, math.Round(width)))
+	offset := -pen / 2
+	travelled := 0.0
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		length := math.Hypot(b.X-a.X, b.Y-a.Y)
+		steps := int(math.Ceil(length * 2))
+		for s := 0; s <= steps; s++ {
+			t := 0.0
+			if steps > 0 {
+				t = float64(s) / float64(steps)
+			}
+			if dashed && math.Mod(travelled+t*length, pngDash[0]+pngDash[1]) >= pngDash[0] {
+				continue
+			}
+			x := int(math.Round(a.X+t*(b.X-a.X))) + offset
+			y := int(math.Round(a.Y+t*(b.Y-a.Y))) + offset
+			for dy := 0; dy < pen; dy++ {
+				for dx := 0; dx < pen; dx++ {
+					img.SetRGBA(x+dx, y+dy, col)
+				}
+			}
+		}
+		travelled += length
+	}
+}
+
+// fillPolygon fills a polygon with the even-odd rule, sampling pixel centers.
+func fillPolygon(img *image.RGBA, points []chartPoint, col color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+	for py := img.Rect.Min.Y; py < img.Rect.Max.Y; py++ {
+		y := float64(py) + 0.5
+		var xs []float64
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			if (a.Y <= y) == (b.Y <= y) {
+				continue
+			}
+			xs = append(xs, a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			for px := int(math.Ceil(xs[i] - 0.5)); float64(px)+0.5 < xs[i+1]; px++ {
+				blend(img, px, py, col)
+			}
+		}
+	}
+}
+
+// drawText draws text with the 5x7 bitmap font, scaled up for large sizes,
+// with its baseline at the element's position.
+func drawText(img *image.RGBA, e chartElement, col color.RGBA) {
+	scale := 1
+	if e.size >= 16 {
+		scale = 2
+	}
+	advance := (glyphWidth + 1) * scale
+	width := utf8.RuneCountInString(e.text) * advance
+	x := int(math.Round(e.points[0].X))
+	switch e.anchor {
+	case "end":
+		x -= width
+	case "middle":
+		x -= width / 2
+	}
+	top := int(math.Round(e.points[0].Y)) - glyphHeight*scale
+
+	for _, r := range e.text {
+		glyph := glyphFor(r)
+		for col0 := 0; col0 < glyphWidth; col0++ {
+			for row := 0; row < glyphHeight; row++ {
+				if glyph[col0]&(1<<row) == 0 {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						// Bold text doubles each column
+						px, py := x+col0*scale+dx, top+row*scale+dy
+						img.SetRGBA(px, py, col)
+						if e.bold {
+							img.SetRGBA(px+1, py, col)
+						}
+					}
+				}
+			}
+		}
+		x += advance
+	}
+}
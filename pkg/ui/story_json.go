@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+// StoryJSON is a story in the REST API and MCP tool results. File and Body are
+// only set for a single story.
+type StoryJSON struct {
+	ID       string                 `json:"id"`
+	Title    string                 `json:"title"`
+	Status   string                 `json:"status"`
+	Priority string                 `json:"priority"`
+	Assignee *string                `json:"assignee"`
+	Tags     []string               `json:"tags"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	// DerivedStatus is set when an explicit status disagrees with Git.
+	DerivedStatus string  `json:"derived_status,omitempty"`
+	Location      string  `json:"location"`
+	CreatedAt     *string `json:"created_at,omitempty"`
+	UpdatedAt     *string `json:"updated_at,omitempty"`
+	File          string  `json:"file,omitempty"`
+	Body          *string `json:"body,omitempty"`
+	// Commit is the hash of the commit recording a change, if any.
+	Commit string `json:"commit,omitempty"`
+}
+
+// NewStoryJSON converts a story with its effective status, its Git-derived
+// status and its location ("Backlog" or the sprint folder name).
+func NewStoryJSON(story *core.Story, status, derived core.Status, location string) StoryJSON {
+	out := StoryJSON{
+		ID:       story.ID,
+		Title:    story.Title,
+		Status:   string(status),
+		Priority: string(story.Priority),
+		Assignee: story.Assignee,
+		Tags:     story.Tags,
+		Location: location,
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if len(story.Extra) > 0 {
+		out.Fields = story.Extra
+	}
+	if derived != "" && derived != status {
+		out.DerivedStatus = string(derived)
+	}
+	if story.CreatedAt != nil {
+		created := story.CreatedAt.UTC().Format(time.RFC3339)
+		out.CreatedAt = &created
+	}
+	if story.UpdatedAt != nil {
+		updated := story.UpdatedAt.UTC().Format(time.RFC3339)
+		out.UpdatedAt = &updated
+	}
+	return out
+}
@@ -0,0 +1,481 @@
+package ui
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+)
+
+const (
+	svgWidth   = 720
+	svgHeight  = 400
+	svgLeft    = 50
+	svgRight   = 160
+	svgTop     = 40
+	svgBottom  = 50
+	svgYTicks  = 5
+	svgFont    = "font-family=\"sans-serif\" font-size=\"12\""
+	svgBgColor = "#ffffff"
+)
+
+// svgPalette holds series colors, used in order.
+var svgPalette = []string{"#2e7d32", "#1565c0", "#f9a825", "#6a1b9a", "#c62828", "#00838f", "#ef6c00", "#5d4037", "#757575", "#ad1457"}
+
+// Chart colors with a fixed meaning across charts.
+const (
+	chartGridColor  = "#e0e0e0"
+	chartAxisColor  = "#424242"
+	chartIdealColor = "#9e9e9e"
+	chartRiskColor  = "#c62828"
+	chartScopeColor = "#ef6c00"
+)
+
+// elementKind is the type of a chart drawing element.
+type elementKind int
+
+const (
+	rectElement elementKind = iota
+	lineElement
+	polylineElement
+	polygonElement
+	textElement
+)
+
+// chartPoint is a position in chart pixels, origin top left.
+type chartPoint struct {
+	X, Y float64
+}
+
+// chartElement is one drawing element of a chart. Rectangles use points[0] as
+// the origin and points[1] as the size; lines and shapes list their vertices;
+// text is anchored at points[0] on its baseline.
+type chartElement struct {
+	kind    elementKind
+	points  []chartPoint
+	color   string
+	width   float64 // stroke width
+	dashed  bool
+	opacity float64 // fill opacity; 0 means opaque
+	text    string
+	size    int    // font size
+	anchor  string // text anchor: "", "middle" or "end"
+	bold    bool
+}
+
+// Chart is a line, area or bar chart laid out as drawing elements, which can
+// be rendered as an SVG document or rasterised to PNG without external tools.
+type Chart struct {
+	elements []chartElement
+	n        int
+	max      float64
+	bars     bool
+	legend   int
+}
+
+func newChart(title string, n int, max float64) *Chart {
+	if max <= 0 {
+		max = 1 // Avoid division by zero
+	}
+	c := &Chart{n: n, max: max}
+	c.rect(0, 0, svgWidth, svgHeight, svgBgColor)
+	c.elements = append(c.elements, chartElement{
+		kind: textElement, points: []chartPoint{{svgLeft, svgTop - 16}}, text: title, size: 16, bold: true,
+	})
+	return c
+}
+
+// x returns the horizontal position of point i, or the center of bar i.
+func (c *Chart) x(i int) float64 {
+	plotWidth := float64(svgWidth - svgLeft - svgRight)
+	if c.bars {
+		return float64(svgLeft) + plotWidth*(float64(i)+0.5)/float64(c.n)
+	}
+	if c.n <= 1 {
+		return float64(svgLeft) + plotWidth/2
+	}
+	return float64(svgLeft) + plotWidth*float64(i)/float64(c.n-1)
+}
+
+// y returns the vertical position of value v.
+func (c *Chart) y(v float64) float64 {
+	plotHeight := float64(svgHeight - svgTop - svgBottom)
+	return float64(svgHeight-svgBottom) - plotHeight*v/c.max
+}
+
+// axes draws the axes, horizontal grid lines with value labels, and the first
+// and last dates.
+func (c *Chart) axes(first, last time.Time) {
+	c.valueAxis()
+	bottom := float64(svgHeight - svgBottom)
+	c.label(c.x(0), bottom+20, first.Format("2006-01-02"), "")
+	c.label(c.x(c.n-1), bottom+20, last.Format("2006-01-02"), "end")
+}
+
+// valueAxis draws both axes and the horizontal grid lines with value labels.
+func (c *Chart) valueAxis() {
+	max := int(math.Ceil(c.max))
+	ticks := svgYTicks
+	if max < ticks {
+		ticks = max // One label per whole unit
+	}
+	for t := 0; t <= ticks; t++ {
+		v := max * t / ticks
+		y := c.y(float64(v))
+		c.segment(svgLeft, y, svgWidth-svgRight, y, chartGridColor, 1, false)
+		c.label(svgLeft-6, y+4, fmt.Sprintf("%d", v), "end")
+	}
+	bottom := float64(svgHeight - svgBottom)
+	c.segment(svgLeft, svgTop, svgLeft, bottom, chartAxisColor, 1, false)
+	c.segment(svgLeft, bottom, svgWidth-svgRight, bottom, chartAxisColor, 1, false)
+}
+
+// line draws a series as a polyline.
+func (c *Chart) line(values []int, color string) {
+	c.curve(intValues(values), color, false)
+}
+
+// curve draws a series of values starting at point 0 as a polyline.
+func (c *Chart) curve(values []float64, color string, dashed bool) {
+	points := make([]chartPoint, 0, len(values))
+	for i, v := range values {
+		points = append(points, chartPoint{c.x(i), c.y(v)})
+	}
+	c.elements = append(c.elements, chartElement{kind: polylineElement, points: points, color: color, width: 2, dashed: dashed})
+}
+
+// area fills the band between the lower and upper series.
+func (c *Chart) area(lower, upper []int, color string) {
+	points := make([]chartPoint, 0, 2*len(upper))
+	for i, v := range upper {
+		points = append(points, chartPoint{c.x(i), c.y(float64(v))})
+	}
+	for i := len(lower) - 1; i >= 0; i-- {
+		points = append(points, chartPoint{c.x(i), c.y(float64(lower[i]))})
+	}
+	c.elements = append(c.elements, chartElement{kind: polygonElement, points: points, color: color, opacity: 0.85})
+}
+
+// bar draws a bar of value v at slot i, offset and sized as a fraction of the slot.
+func (c *Chart) bar(i int, v, offset, width float64, color string) {
+	slot := float64(svgWidth-svgLeft-svgRight) / float64(c.n)
+	left := c.x(i) - slot/2 + slot*offset
+	c.rect(left, c.y(v), slot*width, c.y(0)-c.y(v), color)
+}
+
+// segment draws a straight line.
+func (c *Chart) segment(x1, y1, x2, y2 float64, color string, width float64, dashed bool) {
+	c.elements = append(c.elements, chartElement{
+		kind: lineElement, points: []chartPoint{{x1, y1}, {x2, y2}}, color: color, width: width, dashed: dashed,
+	})
+}
+
+// rect fills a rectangle.
+func (c *Chart) rect(x, y, width, height float64, color string) {
+	c.elements = append(c.elements, chartElement{
+		kind: rectElement, points: []chartPoint{{x, y}, {width, height}}, color: color,
+	})
+}
+
+// label draws 12px text with its baseline at y.
+func (c *Chart) label(x, y float64, text, anchor string) {
+	c.elements = append(c.elements, chartElement{
+		kind: textElement, points: []chartPoint{{x, y}}, text: text, size: 12, anchor: anchor,
+	})
+}
+
+// legendEntry adds a labelled color swatch to the right of the plot.
+func (c *Chart) legendEntry(label, color string) {
+	x := float64(svgWidth - svgRight + 16)
+	y := float64(svgTop + c.legend*20)
+	c.rect(x, y, 12, 12, color)
+	c.label(x+18, y+11, label, "")
+	c.legend++
+}
+
+// note adds a line of text below the legend.
+func (c *Chart) note(text, color string) {
+	x := float64(svgWidth - svgRight + 16)
+	y := float64(svgTop + c.legend*20)
+	c.elements = append(c.elements, chartElement{
+		kind: textElement, points: []chartPoint{{x, y + 11}}, text: text, size: 12, color: color,
+	})
+	c.legend++
+}
+
+// SVG renders the chart as an SVG document.
+func (c *Chart) SVG() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		svgWidth, svgHeight, svgWidth, svgHeight)
+	for _, e := range c.elements {
+		dash := ""
+		if e.dashed {
+			dash = " stroke-dasharray=\"6 4\""
+		}
+		switch e.kind {
+		case rectElement:
+			if e.points[0].X == 0 && e.points[0].Y == 0 && e.points[1].X == svgWidth && e.points[1].Y == svgHeight {
+				fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", e.color)
+				continue
+			}
+			fmt.Fprintf(&b, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n",
+				e.points[0].X, e.points[0].Y, e.points[1].X, e.points[1].Y, e.color)
+		case lineElement:
+			fmt.Fprintf(&b, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"%s\"%s%s/>\n",
+				e.points[0].X, e.points[0].Y, e.points[1].X, e.points[1].Y, e.color, strokeWidth(e.width), dash)
+		case polylineElement:
+			fmt.Fprintf(&b, "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\"%s%s/>\n",
+				svgPoints(e.points), e.color, strokeWidth(e.width), dash)
+		case polygonElement:
+			fmt.Fprintf(&b, "<polygon points=\"%s\" fill=\"%s\" fill-opacity=\"%.2g\" stroke=\"%s\"/>\n",
+				svgPoints(e.points), e.color, e.opacity, e.color)
+		case textElement:
+			attrs := svgFont
+			if e.size != 12 {
+				attrs = fmt.Sprintf("font-family=\"sans-serif\" font-size=\"%d\"", e.size)
+			}
+			if e.bold {
+				attrs += " font-weight=\"bold\""
+			}
+			if e.anchor != "" {
+				attrs += fmt.Sprintf(" text-anchor=\"%s\"", e.anchor)
+			}
+			if e.color != "" {
+				attrs += fmt.Sprintf(" fill=\"%s\"", e.color)
+			}
+			fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%.1f\" %s>%s</text>\n", e.points[0].X, e.points[0].Y, attrs, html.EscapeString(e.text))
+		}
+	}
+	return b.String() + "</svg>\n"
+}
+
+// String renders the chart as an SVG document.
+func (c *Chart) String() string {
+	return c.SVG()
+}
+
+func strokeWidth(width float64) string {
+	if width == 1 {
+		return ""
+	}
+	return fmt.Sprintf(" stroke-width=\"%g\"", width)
+}
+
+func svgPoints(points []chartPoint) string {
+	parts := make([]string, 0, len(points))
+	for _, p := range points {
+		parts = append(parts, fmt.Sprintf("%.1f,%.1f", p.X, p.Y))
+	}
+	return strings.Join(parts, " ")
+}
+
+func intValues(values []int) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+// RenderBurnupSVG renders a burnup chart (scope and completed tasks) as an SVG
+// document.
+func RenderBurnupSVG(dataPoints []core.BurnupDataPoint) string {
+	return NewBurnupChart(dataPoints).SVG()
+}
+
+// NewBurnupChart lays out a burnup chart: scope and completed tasks per day.
+func NewBurnupChart(dataPoints []core.BurnupDataPoint) *Chart {
+	maxValue := 0
+	scope := make([]int, len(dataPoints))
+	completed := make([]int, len(dataPoints))
+	for i, dp := range dataPoints {
+		scope[i] = dp.ScopeTasks
+		completed[i] = dp.CompletedTasks
+		if dp.ScopeTasks > maxValue {
+			maxValue = dp.ScopeTasks
+		}
+	}
+
+	c := newChart("Sprint Burnup", len(dataPoints), float64(maxValue))
+	if len(dataPoints) > 0 {
+		c.axes(dataPoints[0].Date, dataPoints[len(dataPoints)-1].Date)
+		c.line(scope, svgPalette[1])
+		c.line(completed, svgPalette[0])
+	}
+	c.legendEntry("Scope", svgPalette[1])
+	c.legendEntry("Completed", svgPalette[0])
+	return c
+}
+
+// RenderCumulativeFlowSVG renders a cumulative flow diagram as an SVG document
+// with one stacked band per status.
+func RenderCumulativeFlowSVG(flow core.CumulativeFlow) string {
+	return NewCumulativeFlowChart(flow).SVG()
+}
+
+// NewCumulativeFlowChart lays out a cumulative flow diagram with one stacked
+// band per status.
+func NewCumulativeFlowChart(flow core.CumulativeFlow) *Chart {
+	stack := stackOrder(flow.States)
+	cumulative := make([][]int, len(stack)+1)
+	cumulative[0] = make([]int, len(flow.Points))
+	for i, state := range stack {
+		cumulative[i+1] = make([]int, len(flow.Points))
+		for j, dp := range flow.Points {
+			cumulative[i+1][j] = cumulative[i][j] + dp.Counts[state]
+		}
+	}
+
+	maxValue := 0
+	for _, total := range cumulative[len(stack)] {
+		if total > maxValue {
+			maxValue = total
+		}
+	}
+
+	c := newChart("Cumulative Flow", len(flow.Points), float64(maxValue))
+	if len(flow.Points) > 0 {
+		c.axes(flow.Points[0].Date, flow.Points[len(flow.Points)-1].Date)
+		for i := range stack {
+			c.area(cumulative[i], cumulative[i+1], svgPalette[i%len(svgPalette)])
+		}
+	}
+	// Legend reads top-down like the bands
+	for i := len(stack) - 1; i >= 0; i-- {
+		c.legendEntry(string(stack[i]), svgPalette[i%len(svgPalette)])
+	}
+	return c
+}
+
+// RenderBurndownSVG renders a burndown chart as an SVG document.
+func RenderBurndownSVG(chart core.BurndownChart) string {
+	return NewBurndownChart(chart).SVG()
+}
+
+// NewBurndownChart lays out a burndown chart over the sprint's working days:
+// remaining points, the dashed ideal guideline, the projected trend up to the
+// projected completion, and a marker on each day scope was added.
+func NewBurndownChart(chart core.BurndownChart) *Chart {
+	days := chart.Days()
+	maxValue := 0.0
+	for _, dp := range chart.Points {
+		maxValue = math.Max(maxValue, float64(dp.RemainingPoints))
+	}
+	if len(chart.Ideal) > 0 {
+		maxValue = math.Max(maxValue, chart.Ideal[0].Remaining)
+	}
+
+	c := newChart("Sprint Burndown", days, maxValue)
+	if len(chart.Points) > 0 {
+		last := chart.Points[len(chart.Points)-1].Date
+		if len(chart.Ideal) > len(chart.Points) {
+			last = chart.Ideal[len(chart.Ideal)-1].Date
+		}
+		c.axes(chart.Points[0].Date, last)
+
+		for _, sc := range chart.ScopeChanges {
+			for i, dp := range chart.Points {
+				if dp.Date.Equal(sc.Date) {
+					c.segment(c.x(i), svgTop, c.x(i), c.y(0), chartScopeColor, 1, true)
+					c.label(c.x(i), svgTop-2, fmt.Sprintf("+%d", sc.Added), "middle")
+				}
+			}
+		}
+
+		ideal := make([]float64, len(chart.Ideal))
+		for i, ip := range chart.Ideal {
+			ideal[i] = ip.Remaining
+		}
+		c.curve(ideal, chartIdealColor, true)
+
+		// Projection from the last actual value towards zero, within the chart
+		lastDay := len(chart.Points) - 1
+		if len(chart.Points) > 1 && chart.Slope < 0 && chart.Points[lastDay].RemainingPoints > 0 {
+			end := days - 1
+			endValue := chart.Projected(end)
+			if zero := -chart.Intercept / chart.Slope; zero < float64(end) {
+				end, endValue = int(math.Ceil(zero)), 0
+			}
+			if end > lastDay {
+				c.segment(c.x(lastDay), c.y(chart.Projected(lastDay)), c.x(end), c.y(endValue), chartRiskColor, 2, true)
+			}
+		}
+
+		remaining := make([]float64, len(chart.Points))
+		for i, dp := range chart.Points {
+			remaining[i] = float64(dp.RemainingPoints)
+		}
+		c.curve(remaining, svgPalette[1], false)
+	}
+
+	c.legendEntry("Remaining", svgPalette[1])
+	c.legendEntry("Ideal", chartIdealColor)
+	c.legendEntry("Projection", chartRiskColor)
+	if len(chart.ScopeChanges) > 0 {
+		c.legendEntry("Scope added", chartScopeColor)
+	}
+	if chart.ProjectedCompletion != nil {
+		c.note("Done by "+chart.ProjectedCompletion.Format("2006-01-02"), "")
+	}
+	if chart.AtRisk {
+		c.note("At risk", chartRiskColor)
+	}
+	return c
+}
+
+// RenderVelocitySVG renders a velocity bar chart as an SVG document.
+func RenderVelocitySVG(velocities []core.SprintVelocity) string {
+	return NewVelocityChart(velocities).SVG()
+}
+
+// NewVelocityChart lays out committed and completed work per sprint as bars,
+// with the average completed work as a dashed line. Points are charted when
+// any sprint has points, tasks otherwise.
+func NewVelocityChart(velocities []core.SprintVelocity) *Chart {
+	usePoints := false
+	for _, v := range velocities {
+		if v.Points > 0 {
+			usePoints = true
+		}
+	}
+	committed := make([]float64, len(velocities))
+	completed := make([]float64, len(velocities))
+	maxValue, total := 0.0, 0.0
+	for i, v := range velocities {
+		committed[i], completed[i] = float64(v.Tasks), float64(v.CompletedTasks)
+		if usePoints {
+			committed[i], completed[i] = v.Points, v.CompletedPoints
+		}
+		maxValue = math.Max(maxValue, math.Max(committed[i], completed[i]))
+		total += completed[i]
+	}
+
+	unit := "tasks"
+	if usePoints {
+		unit = "points"
+	}
+	c := newChart("Velocity ("+unit+")", len(velocities), maxValue)
+	c.bars = true
+	if len(velocities) > 0 {
+		c.valueAxis()
+		slot := float64(svgWidth-svgLeft-svgRight) / float64(len(velocities))
+		maxChars := int(slot / 7)
+		for i, v := range velocities {
+			c.bar(i, committed[i], 0.1, 0.4, svgPalette[8])
+			c.bar(i, completed[i], 0.5, 0.4, svgPalette[0])
+			if maxChars > 0 {
+				c.label(c.x(i), svgHeight-svgBottom+20, truncate(strings.TrimPrefix(v.Name, "~"), maxChars), "middle")
+			}
+		}
+		average := total / float64(len(velocities))
+		c.segment(svgLeft, c.y(average), svgWidth-svgRight, c.y(average), chartRiskColor, 1, true)
+	}
+	c.legendEntry("Committed", svgPalette[8])
+	c.legendEntry("Completed", svgPalette[0])
+	c.legendEntry("Average", chartRiskColor)
+	return c
+}
@@ -15,6 +15,8 @@ type DisplayStory struct {
 	Story    *core.Story
 	Priority core.Priority
 	Status   core.Status
+	// Derived is the status derived from Git, shown by RenderDriftTable.
+	Derived core.Status
 }
 
 // RenderStorySections renders one or more sections (e.g., Sprint vs Backlog) with
@@ -85,6 +87,38 @@ func renderTable(stories []DisplayStory) string {
 	return strings.Join(append([]string{header}, rows...), "\n")
 }
 
+// RenderDriftTable renders stories whose explicit status disagrees with the
+// status derived from Git, side by side.
+func RenderDriftTable(stories []DisplayStory) string {
+	if len(stories) == 0 {
+		return ""
+	}
+
+	tableStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("99")).
+		Padding(0, 1)
+
+	header := fmt.Sprintf("%-*s %-*s %-*s %-*s",
+		idWidth, "ID",
+		titleWidth, "Title",
+		statusWidth, "Explicit",
+		statusWidth, "Git",
+	)
+
+	rows := []string{header}
+	for _, item := range stories {
+		rows = append(rows, fmt.Sprintf("%-*s %-*s %-*s %-*s",
+			idWidth, truncate(item.Story.ID, idWidth),
+			titleWidth, truncate(item.Story.Title, titleWidth),
+			statusWidth, statusStyle(item.Status),
+			statusWidth, statusStyle(item.Derived),
+		))
+	}
+
+	return tableStyle.Render(strings.Join(rows, "\n"))
+}
+
 func truncate(value string, width int) string {
 	if lipgloss.Width(value) <= width {
 		return value
@@ -111,7 +145,19 @@ func statusStyle(status core.Status) string {
 		return base.Foreground(lipgloss.Color("75")).Render("Review")
 	case core.StatusDone:
 		return base.Foreground(lipgloss.Color("77")).Render("Done")
-	default:
+	case core.StatusTodo, "":
 		return base.Foreground(lipgloss.Color("246")).Render("Todo")
+	default:
+		// Configured workflow states are shown by name.
+		return base.Foreground(lipgloss.Color("246")).Render(statusLabel(status))
+	}
+}
+
+// statusLabel capitalizes a status name for display (e.g., "qa" → "Qa").
+func statusLabel(status core.Status) string {
+	name := string(status)
+	if name == "" {
+		return ""
 	}
+	return strings.ToUpper(name[:1]) + name[1:]
 }
@@ -46,3 +46,17 @@ func containsAll(haystack string, needles []string) bool {
 	}
 	return true
 }
+
+func TestRenderDriftTable_ShowsBothStatuses(t *testing.T) {
+	output := RenderDriftTable([]DisplayStory{{
+		Story:   &core.Story{ID: "US-002", Title: "Stale"},
+		Status:  core.StatusTodo,
+		Derived: core.StatusDone,
+	}})
+	if !containsAll(output, []string{"Explicit", "Git", "US-002", "Todo", "Done"}) {
+		t.Fatalf("output missing expected headers/values: %s", output)
+	}
+	if RenderDriftTable(nil) != "" {
+		t.Fatal("expected empty output for no stories")
+	}
+}
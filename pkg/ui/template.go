@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// templateColors maps color names to ANSI colors; other values are passed to
+// lipgloss (ANSI 256 numbers or #rrggbb).
+var templateColors = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+	"gray":    "8",
+	"grey":    "8",
+}
+
+// TemplateFuncs returns the helper functions available to --format
+// templates:
+//
+//	json v           v encoded as compact JSON
+//	upper s, lower s s in upper or lower case
+//	date layout v    a date or RFC 3339 timestamp in a Go time layout
+//	truncate n s     s cut to n characters, ending with "…" when cut
+//	color name s     s in a color (red, green, ..., an ANSI number or #rrggbb)
+//	join sep list    list items joined with sep
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			return string(data), nil
+		},
+		"upper": func(v interface{}) string { return strings.ToUpper(templateText(v)) },
+		"lower": func(v interface{}) string { return strings.ToLower(templateText(v)) },
+		"date":  templateDate,
+		"truncate": func(n int, v interface{}) string {
+			runes := []rune(templateText(v))
+			if n < 1 || len(runes) <= n {
+				return string(runes)
+			}
+			return string(runes[:n-1]) + "…"
+		},
+		"color": func(name string, v interface{}) string {
+			if code, ok := templateColors[strings.ToLower(name)]; ok {
+				name = code
+			}
+			return lipgloss.NewStyle().Foreground(lipgloss.Color(name)).Render(templateText(v))
+		},
+		"join": func(sep string, v interface{}) string {
+			var items []string
+			switch list := v.(type) {
+			case nil:
+			case []string:
+				items = list
+			case []interface{}:
+				for _, item := range list {
+					items = append(items, templateText(item))
+				}
+			default:
+				items = []string{templateText(v)}
+			}
+			return strings.Join(items, sep)
+		},
+	}
+}
+
+// ParseTemplate parses a --format template with the TemplateFuncs helpers.
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ExecuteTemplate renders v with tmpl, ending the output with a newline.
+// v is converted to its JSON form first, so templates address fields by the
+// names of the --json output ({{.id}}, {{.derived_status}}).
+func ExecuteTemplate(w io.Writer, tmpl *template.Template, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, value); err != nil {
+		return fmt.Errorf("template: %w", err)
+	}
+	if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// templateText returns the text of a template value; nil is empty.
+func templateText(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// templateDate formats a time, or a text date or RFC 3339 timestamp. Other
+// text is returned unchanged and nil is empty.
+func templateDate(layout string, v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return t.Format(layout)
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return t.Format(layout)
+	}
+	text := templateText(v)
+	for _, parse := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(parse, text); err == nil {
+			return t.Format(layout)
+		}
+	}
+	return text
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	assignee := "alice"
+	created := "2025-03-04T10:00:00Z"
+	story := StoryJSON{
+		ID:        "US-004",
+		Title:     "Checkout with credit card",
+		Status:    "doing",
+		Assignee:  &assignee,
+		Tags:      []string{"payments", "api"},
+		Fields:    map[string]interface{}{"points": 8},
+		CreatedAt: &created,
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "fields by JSON name", text: "{{.id}} {{.fields.points}}", want: "US-004 8\n"},
+		{name: "upper", text: "{{.status | upper}}", want: "DOING\n"},
+		{name: "truncate", text: "{{.title | truncate 10}}|{{.id | truncate 10}}", want: "Checkout …|US-004\n"},
+		{name: "date", text: `{{.created_at | date "Jan 2, 2006"}}`, want: "Mar 4, 2025\n"},
+		{name: "date of a missing value", text: `[{{.updated_at | date "2006"}}]`, want: "[]\n"},
+		{name: "join", text: `{{join ", " .tags}}`, want: "payments, api\n"},
+		{name: "json", text: "{{json .tags}}", want: "[\"payments\",\"api\"]\n"},
+		{name: "or a default", text: `{{or .derived_status "-"}}`, want: "-\n"},
+		{name: "keeps a final newline", text: "{{.id}}\n", want: "US-004\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := ExecuteTemplate(&out, tmpl, story); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseTemplate("{{.id"); err == nil || !strings.Contains(err.Error(), "invalid template") {
+		t.Errorf("error = %v, want an invalid template", err)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	color := TemplateFuncs()["color"].(func(string, interface{}) string)
+	// Output is only colored on a terminal; the text is always kept
+	if got := color("red", "done"); !strings.Contains(got, "done") {
+		t.Errorf("color = %q", got)
+	}
+	date := TemplateFuncs()["date"].(func(string, interface{}) string)
+	if got := date("2006-01-02", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)); got != "2025-01-02" {
+		t.Errorf("date = %q", got)
+	}
+}
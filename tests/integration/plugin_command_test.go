@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPluginCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin is a shell script")
+	}
+
+	binPath := filepath.Join(t.TempDir(), "gitta")
+	if out, err := exec.Command("go", "build", "-o", binPath, "../../cmd/gitta").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build binary: %v\n%s", err, out)
+	}
+
+	repoPath := t.TempDir()
+	for _, dir := range []string{".git", filepath.Join("tasks", "backlog"), filepath.Join("tasks", "sprints"), filepath.Join(".gitta", "plugins")} {
+		if err := os.MkdirAll(filepath.Join(repoPath, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The plugin echoes its context, arguments and the rest of its input
+	script := "#!/bin/sh\nread context\necho \"$context\"\necho \"args=$*\"\necho \"root=$GITTA_REPO_ROOT\"\ncat\nexit 7\n"
+	if err := os.WriteFile(filepath.Join(repoPath, ".gitta", "plugins", "gitta-hello"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(binPath, "hello", "--json", "US-1")
+	cmd.Dir = filepath.Join(repoPath, "tasks")
+	cmd.Stdin = strings.NewReader("piped input\n")
+	output, err := cmd.CombinedOutput()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 7 {
+		t.Fatalf("expected the plugin's exit code 7, got %v\n%s", err, output)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+	var context struct {
+		Command    string   `json:"command"`
+		Args       []string `json:"args"`
+		RepoRoot   string   `json:"repo_root"`
+		BacklogDir string   `json:"backlog_dir"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &context); err != nil {
+		t.Fatalf("first line of stdin is not the JSON context: %v\n%s", err, lines[0])
+	}
+	if context.Command != "hello" || strings.Join(context.Args, " ") != "--json US-1" ||
+		context.RepoRoot != repoPath || context.BacklogDir != filepath.Join(repoPath, "tasks", "backlog") {
+		t.Errorf("unexpected context: %+v", context)
+	}
+	if lines[1] != "args=--json US-1" || lines[2] != "root="+repoPath || lines[3] != "piped input" {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	// Global flags before the plugin name are passed first
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--json", "hello", "US-1"}, "args=--json US-1"},
+		{[]string{"--log-level", "debug", "hello", "US-1"}, "args=--log-level debug US-1"},
+		{[]string{"--log-level=warn", "--json", "hello"}, "args=--log-level=warn --json"},
+	} {
+		cmd = exec.Command(binPath, tt.args...)
+		cmd.Dir = repoPath
+		output, err := cmd.CombinedOutput()
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 7 {
+			t.Errorf("gitta %s: expected the plugin's exit code 7, got %v\n%s", strings.Join(tt.args, " "), err, output)
+			continue
+		}
+		if lines := strings.Split(string(output), "\n"); len(lines) < 2 || lines[1] != tt.want {
+			t.Errorf("gitta %s: want %q in output:\n%s", strings.Join(tt.args, " "), tt.want, output)
+		}
+	}
+
+	// Plugins are listed, and unknown commands still fail
+	cmd = exec.Command(binPath, "plugin", "list")
+	cmd.Dir = repoPath
+	if list, err := cmd.CombinedOutput(); err != nil || !strings.Contains(string(list), "hello  project  .gitta/plugins/gitta-hello") {
+		t.Errorf("unexpected plugin list: %v\n%s", err, list)
+	}
+	for _, args := range [][]string{{"goodbye"}, {"--json", "goodbye"}} {
+		cmd = exec.Command(binPath, args...)
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), `unknown command "goodbye"`) {
+			t.Errorf("unknown command %v: %v\n%s", args, err, out)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package integration
+
+import (
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	ggit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"gopkg.in/yaml.v3"
+)
+
+// buildGitta compiles the CLI into a temporary directory and returns its path.
+func buildGitta(t *testing.T) string {
+	t.Helper()
+	binPath := filepath.Join(t.TempDir(), "gitta")
+	if runtime.GOOS == "windows" {
+		binPath += ".exe"
+	}
+	buildCmd := exec.Command("go", "build", "-o", binPath, "../../cmd/gitta")
+	if out, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build binary: %v\n%s", err, out)
+	}
+	return binPath
+}
+
+// runGitta runs the CLI in dir and returns stdout. Non-zero exits are allowed
+// so that commands reporting problems can still be checked.
+func runGitta(t *testing.T, bin, dir string, args ...string) []byte {
+	t.Helper()
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			t.Fatalf("gitta %s: %v", strings.Join(args, " "), err)
+		}
+	}
+	return out
+}
+
+// loadSchema fetches a schema through `gitta schema <name>`.
+func loadSchema(t *testing.T, bin, dir, name string) map[string]interface{} {
+	t.Helper()
+	var schema map[string]interface{}
+	if err := json.Unmarshal(runGitta(t, bin, dir, "schema", name), &schema); err != nil {
+		t.Fatalf("schema %s is not valid JSON: %v", name, err)
+	}
+	return schema
+}
+
+func assertMatchesSchema(t *testing.T, schema map[string]interface{}, output []byte) {
+	t.Helper()
+	doc, err := decodeJSON(output)
+	if err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, output)
+	}
+	if errs := validateJSONSchema(schema, doc); len(errs) > 0 {
+		t.Errorf("output does not match schema:\n  %s\noutput:\n%s", strings.Join(errs, "\n  "), output)
+	}
+}
+
+func TestJSONOutputsMatchSchemas(t *testing.T) {
+	bin := buildGitta(t)
+
+	repoPath := setupRepo(t)
+	runGitta(t, bin, repoPath, "init")
+	writeStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-010.md"), "US-010", "Backlog story")
+
+	tests := []struct {
+		name   string
+		schema string
+		args   []string
+	}{
+		{"list", "list", []string{"list", "--all", "--json"}},
+		{"list filtered", "list", []string{"list", "--all", "--status", "todo", "--json"}},
+		{"list drift", "list", []string{"list", "--drift", "--json"}},
+		{"doctor", "doctor", []string{"doctor", "--json"}},
+		{"lint", "lint", []string{"lint", "--json"}},
+		{"sprint start dry run", "sprint-start", []string{"sprint", "start", "--dry-run", "--json"}},
+		{"sprint start", "sprint-start", []string{"sprint", "start", "--json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := loadSchema(t, bin, repoPath, tt.schema)
+			assertMatchesSchema(t, schema, runGitta(t, bin, repoPath, tt.args...))
+		})
+	}
+}
+
+func TestSprintChartJSONMatchesSchemas(t *testing.T) {
+	bin := buildGitta(t)
+
+	repoPath := setupRepo(t)
+	storyPath := filepath.Join(repoPath, "sprints", "Sprint-01", "US-001.md")
+	writeStory(t, storyPath, "US-001", "Sprint story")
+	commitFileToRepo(t, repoPath, storyPath, "add sprint story")
+
+	schema := loadSchema(t, bin, repoPath, "burndown")
+	assertMatchesSchema(t, schema, runGitta(t, bin, repoPath, "sprint", "burndown", "Sprint-01", "--format", "json"))
+
+	schema = loadSchema(t, bin, repoPath, "burndown-forecast")
+	assertMatchesSchema(t, schema, runGitta(t, bin, repoPath, "sprint", "burndown", "Sprint-01", "--format", "json", "--forecast"))
+
+	schema = loadSchema(t, bin, repoPath, "burnup")
+	assertMatchesSchema(t, schema, runGitta(t, bin, repoPath, "sprint", "chart", "Sprint-01", "--type", "burnup", "--format", "json"))
+
+	schema = loadSchema(t, bin, repoPath, "cfd")
+	assertMatchesSchema(t, schema, runGitta(t, bin, repoPath, "sprint", "chart", "Sprint-01", "--type", "cfd", "--format", "json"))
+}
+
+func TestStatsFlowJSONMatchesSchema(t *testing.T) {
+	bin := buildGitta(t)
+
+	repoPath := setupRepo(t)
+	runGitta(t, bin, repoPath, "init")
+	storyPath := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	writeStory(t, storyPath, "US-001", "Merged story")
+	commitFileToRepo(t, repoPath, storyPath, "add story")
+
+	// A story branch at the target's tip counts as fast-forward merged.
+	repo, err := ggit.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("feat/US-001"), head.Hash())); err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+
+	schema := loadSchema(t, bin, repoPath, "stats-flow")
+	assertMatchesSchema(t, schema, runGitta(t, bin, repoPath, "stats", "flow", "--json"))
+	assertMatchesSchema(t, schema, runGitta(t, bin, repoPath, "stats", "flow", "--since", "30d", "--format", "json"))
+	if out := runGitta(t, bin, repoPath, "stats", "flow", "--json"); !strings.Contains(string(out), `"merged": 1`) {
+		t.Errorf("expected one merged story:\n%s", out)
+	}
+}
+
+func TestVelocityAndForecastJSONMatchSchemas(t *testing.T) {
+	bin := buildGitta(t)
+
+	repoPath := setupRepo(t)
+	runGitta(t, bin, repoPath, "init")
+	for _, id := range []string{"US-101", "US-102"} {
+		storyPath := filepath.Join(repoPath, "tasks", "sprints", "~Sprint-01", id+".md")
+		writeStoryWithAttrs(t, storyPath, id, "Done story", "done", "medium", "", nil)
+		commitFileToRepo(t, repoPath, storyPath, "add "+id)
+	}
+
+	velocity := runGitta(t, bin, repoPath, "stats", "velocity", "--json")
+	assertMatchesSchema(t, loadSchema(t, bin, repoPath, "velocity"), velocity)
+	if !strings.Contains(string(velocity), `"completed_tasks": 2`) {
+		t.Errorf("expected two completed tasks:\n%s", velocity)
+	}
+
+	schema := loadSchema(t, bin, repoPath, "forecast")
+	capacity := runGitta(t, bin, repoPath, "forecast", "--sprints", "2", "--seed", "1", "--json")
+	assertMatchesSchema(t, schema, capacity)
+	if !strings.Contains(string(capacity), `"items": 4`) {
+		t.Errorf("constant throughput of 2 over 2 sprints must forecast 4 items:\n%s", capacity)
+	}
+	assertMatchesSchema(t, schema, runGitta(t, bin, repoPath, "forecast", "--items", "5", "--seed", "1", "--json"))
+}
+
+func TestSprintCapacityJSONMatchesSchema(t *testing.T) {
+	bin := buildGitta(t)
+
+	repoPath := setupRepo(t)
+	runGitta(t, bin, repoPath, "init")
+
+	out := runGitta(t, bin, repoPath, "sprint", "capacity", "--init", "--json")
+	assertMatchesSchema(t, loadSchema(t, bin, repoPath, "sprint-capacity"), out)
+	if !strings.Contains(string(out), `"days": 10`) {
+		t.Errorf("expected the 10-day capacity template:\n%s", out)
+	}
+}
+
+func TestStoryAndConfigSchemas(t *testing.T) {
+	bin := buildGitta(t)
+	repoPath := setupRepo(t)
+
+	tests := []struct {
+		name    string
+		schema  string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name:   "valid story",
+			schema: "story",
+			yaml:   "id: US-001\ntitle: Valid\nstatus: doing\npriority: high\ntags: [api]\ncreated_at: 2025-01-02T10:00:00Z\ncomponent: web\n",
+		},
+		{name: "invalid story id", schema: "story", yaml: "id: bad\ntitle: Broken\n", wantErr: true},
+		{name: "invalid story status", schema: "story", yaml: "id: US-001\ntitle: T\nstatus: blocked\n", wantErr: true},
+		{
+			name:   "valid config",
+			schema: "config",
+			yaml:   "log_level: debug\nbranch:\n  prefix: feature/\n  target_branches: [main]\n",
+		},
+		{name: "invalid config", schema: "config", yaml: "branch:\n  prefix: 5\n", wantErr: true},
+		{
+			name:   "valid config with fields",
+			schema: "config",
+			yaml:   "fields:\n  component: {type: enum, values: [api, web]}\n  points: {type: int, min: 0}\n",
+		},
+		{name: "invalid field type", schema: "config", yaml: "fields:\n  points: {type: bigint}\n", wantErr: true},
+		{
+			name:   "valid config with hooks",
+			schema: "config",
+			yaml:   "hooks:\n  - {event: story.created, run: ./notify.sh}\n  - {event: status.changed, plugin: jira, pre: true, timeout: 10s}\n",
+		},
+		{name: "hook with run and plugin", schema: "config", yaml: "hooks:\n  - {event: rollover, run: echo, plugin: jira}\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc map[string]interface{}
+			if err := yaml.Unmarshal([]byte(tt.yaml), &doc); err != nil {
+				t.Fatalf("yaml: %v", err)
+			}
+			data, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatalf("json: %v", err)
+			}
+			generic, _ := decodeJSON(data)
+
+			errs := validateJSONSchema(loadSchema(t, bin, repoPath, tt.schema), generic)
+			if tt.wantErr && len(errs) == 0 {
+				t.Error("expected schema violations, got none")
+			}
+			if !tt.wantErr && len(errs) > 0 {
+				t.Errorf("unexpected violations: %v", errs)
+			}
+		})
+	}
+}
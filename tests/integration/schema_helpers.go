@@ -0,0 +1,226 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// validateJSONSchema checks doc against a JSON Schema (the subset used by gitta's
+// schemas: type, enum, const, required, properties, additionalProperties, items,
+// oneOf, anyOf, $ref to #/$defs, string/number/array bounds, pattern and the
+// date-time format). It returns every violation found.
+func validateJSONSchema(schema map[string]interface{}, doc interface{}) []string {
+	v := &schemaValidator{root: schema}
+	v.validate(schema, doc, "$")
+	return v.errs
+}
+
+type schemaValidator struct {
+	root map[string]interface{}
+	errs []string
+}
+
+func (v *schemaValidator) fail(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *schemaValidator) validate(schema map[string]interface{}, doc interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return
+		}
+		v.validate(target, doc, path)
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, doc) {
+		v.fail(path, "type %s does not match %v", jsonType(doc), types)
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, doc) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "value %v not in enum %v", doc, enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, doc) {
+		v.fail(path, "value %v does not equal const %v", doc, c)
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if matches := v.countMatches(oneOf, doc); matches != 1 {
+			v.fail(path, "matches %d oneOf branches, want exactly 1", matches)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		if v.countMatches(anyOf, doc) == 0 {
+			v.fail(path, "matches no anyOf branch")
+		}
+	}
+
+	switch value := doc.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, value, path)
+	case []interface{}:
+		v.validateArray(schema, value, path)
+	case string:
+		v.validateString(schema, value, path)
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && value < min {
+			v.fail(path, "%v is below minimum %v", value, min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && value > max {
+			v.fail(path, "%v is above maximum %v", value, max)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema, obj map[string]interface{}, path string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			if _, present := obj[r.(string)]; !present {
+				v.fail(path, "missing required property %q", r)
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+	for key, value := range obj {
+		if propSchema, ok := props[key].(map[string]interface{}); ok {
+			v.validate(propSchema, value, path+"."+key)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.fail(path, "unexpected property %q", key)
+			}
+		case map[string]interface{}:
+			v.validate(extra, value, path+"."+key)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(schema map[string]interface{}, arr []interface{}, path string) {
+	if max, ok := schema["maxItems"].(float64); ok && float64(len(arr)) > max {
+		v.fail(path, "%d items exceed maxItems %v", len(arr), max)
+	}
+	if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if reflect.DeepEqual(arr[i], arr[j]) {
+					v.fail(path, "items %d and %d are not unique", i, j)
+				}
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range arr {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(schema map[string]interface{}, s, path string) {
+	if min, ok := schema["minLength"].(float64); ok && float64(len(s)) < min {
+		v.fail(path, "length %d below minLength %v", len(s), min)
+	}
+	if max, ok := schema["maxLength"].(float64); ok && float64(len(s)) > max {
+		v.fail(path, "length %d above maxLength %v", len(s), max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err != nil || !re.MatchString(s) {
+			v.fail(path, "%q does not match pattern %s", s, pattern)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && format == "date-time" {
+		if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+			v.fail(path, "%q is not a date-time", s)
+		}
+	}
+}
+
+func (v *schemaValidator) countMatches(branches []interface{}, doc interface{}) int {
+	matches := 0
+	for _, b := range branches {
+		sub := &schemaValidator{root: v.root}
+		sub.validate(b.(map[string]interface{}), doc, "")
+		if len(sub.errs) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func (v *schemaValidator) resolve(ref string) (map[string]interface{}, error) {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %s", ref)
+	}
+	defs, _ := v.root["$defs"].(map[string]interface{})
+	target, ok := defs[name].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unresolved $ref %s", ref)
+	}
+	return target, nil
+}
+
+func matchesType(types interface{}, doc interface{}) bool {
+	actual := jsonType(doc)
+	check := func(t string) bool {
+		return t == actual || (t == "number" && actual == "integer")
+	}
+	switch t := types.(type) {
+	case string:
+		return check(t)
+	case []interface{}:
+		for _, item := range t {
+			if check(item.(string)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonType(doc interface{}) string {
+	switch value := doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", doc)
+	}
+}
+
+// decodeJSON unmarshals raw JSON into generic values for schema validation.
+func decodeJSON(data []byte) (interface{}, error) {
+	var doc interface{}
+	err := json.Unmarshal(data, &doc)
+	return doc, err
+}
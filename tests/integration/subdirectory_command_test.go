@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// subdirectoryRepo builds gitta and creates a repository with story US-3 in
+// the backlog and the given .gitta/config.yaml. It returns the binary and
+// the repository root.
+func subdirectoryRepo(t *testing.T, config string) (string, string) {
+	t.Helper()
+	binPath := filepath.Join(t.TempDir(), "gitta")
+	if out, err := exec.Command("go", "build", "-o", binPath, "../../cmd/gitta").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build binary: %v\n%s", err, out)
+	}
+
+	repoPath := t.TempDir()
+	for _, dir := range []string{".git", ".gitta", filepath.Join("tasks", "backlog"), filepath.Join("tasks", "sprints")} {
+		if err := os.MkdirAll(filepath.Join(repoPath, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	story := "---\nid: US-3\ntitle: Checkout\nstatus: todo\npriority: medium\n---\n\nBody\n"
+	if err := os.WriteFile(filepath.Join(repoPath, "tasks", "backlog", "US-3.md"), []byte(story), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, ".gitta", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return binPath, repoPath
+}
+
+// runGittaIn runs gitta in dir and returns its combined output.
+func runGittaIn(binPath, dir string, args ...string) (string, error) {
+	cmd := exec.Command(binPath, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestSubdirectory_LoadsProjectConfig(t *testing.T) {
+	binPath, repoPath := subdirectoryRepo(t, "fields:\n  component:\n    type: enum\n    values: [api, web]\n")
+
+	// The configuration of the repository root applies in its subdirectories
+	out, err := runGittaIn(binPath, filepath.Join(repoPath, "tasks"), "story", "create", "--title", "Search", "--set", "component=api")
+	if err != nil {
+		t.Fatalf("create from a subdirectory: %v\n%s", err, out)
+	}
+	out, err = runGittaIn(binPath, filepath.Join(repoPath, "tasks"), "story", "create", "--title", "Search", "--set", "component=cli")
+	if err == nil || !strings.Contains(out, "component") {
+		t.Errorf("invalid field value accepted from a subdirectory: %v\n%s", err, out)
+	}
+}
+
+func TestSubdirectory_SchemaUsesProjectConfig(t *testing.T) {
+	binPath, repoPath := subdirectoryRepo(t, "fields:\n  component:\n    type: enum\n    values: [api, web]\n"+
+		"workflow:\n  states:\n    - {name: todo}\n    - {name: doing, derive: branch}\n    - {name: qa}\n    - {name: done, derive: merged}\n")
+
+	// The story schema of a subdirectory describes the configured fields and statuses
+	out, err := runGittaIn(binPath, filepath.Join(repoPath, "tasks"), "schema", "story")
+	if err != nil {
+		t.Fatalf("schema from a subdirectory: %v\n%s", err, out)
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(out), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v\n%s", err, out)
+	}
+	if _, ok := schema.Properties["component"]; !ok {
+		t.Errorf("story schema misses the custom field component:\n%s", out)
+	}
+	if status := strings.Join(schema.Properties["status"].Enum, ","); status != "todo,doing,qa,done" {
+		t.Errorf("status enum = %s, want the configured workflow todo,doing,qa,done", status)
+	}
+}
+
+func TestSubdirectory_PreHookVetoes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hook is a shell script")
+	}
+	// The hook records the directory it runs in and vetoes every change
+	binPath, repoPath := subdirectoryRepo(t, "hooks:\n  - event: status.changed\n    run: pwd > \"$GITTA_HOOK_LOG\"; exit 1\n    pre: true\n")
+	hookLog := filepath.Join(t.TempDir(), "hook.log")
+	storyPath := filepath.Join(repoPath, "tasks", "backlog", "US-3.md")
+	before, err := os.ReadFile(storyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hooks guard changes made from any directory, and run from the root
+	for _, dir := range []string{repoPath, filepath.Join(repoPath, "tasks")} {
+		os.Remove(hookLog)
+		cmd := exec.Command(binPath, "story", "status", "US-3", "--status", "doing")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GITTA_HOOK_LOG="+hookLog)
+		out, err := cmd.CombinedOutput()
+		if err == nil || !strings.Contains(string(out), "vetoed") {
+			t.Errorf("status change from %s not vetoed: %v\n%s", dir, err, out)
+		}
+		if hookDir, err := os.ReadFile(hookLog); err != nil || strings.TrimSpace(string(hookDir)) != repoPath {
+			t.Errorf("hook run from %s ran in %q (%v), want %s", dir, hookDir, err, repoPath)
+		}
+	}
+	if after, err := os.ReadFile(storyPath); err != nil || string(after) != string(before) {
+		t.Errorf("vetoed change written: %v\n%s", err, after)
+	}
+}
+
+func TestSubdirectory_RecordsActivityAtRoot(t *testing.T) {
+	binPath, repoPath := subdirectoryRepo(t, "")
+	if out, err := runGittaIn(binPath, filepath.Join(repoPath, "tasks"), "story", "status", "US-3", "--status", "doing"); err != nil {
+		t.Fatalf("status change from a subdirectory: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "tasks", ".gitta")); !os.IsNotExist(err) {
+		t.Errorf("journal written in the subdirectory: %v", err)
+	}
+
+	// The change is in the journal read at the root
+	out, err := runGittaIn(binPath, repoPath, "log", "US-3")
+	if err != nil || !strings.Contains(out, "status: todo → doing") {
+		t.Errorf("gitta log at the root misses the change: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,179 @@
+package unit
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+func TestActivityJournal_StatusChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	backlogDir := filepath.Join(tmpDir, "backlog")
+	if err := os.MkdirAll(backlogDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	createTestStory(t, backlogDir, "US-001", "Story", core.StatusTodo)
+	parser := filesystem.NewMarkdownParser()
+	activity := services.NewActivityService(tmpDir)
+	journal := services.NewActivityJournal(activity, "gitta story status US-001 --status doing", io.Discard)
+	update := services.NewUpdateServiceWithEvents(parser, filesystem.NewRepository(parser), tmpDir, core.DefaultWorkflow(), journal)
+
+	ctx := context.Background()
+	if err := update.UpdateStatus(ctx, "US-001", core.StatusDoing); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(tmpDir, services.ActivityDir, "*.jsonl"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("journal files = %v, %v; want one", files, err)
+	}
+	entries, err := activity.List(ctx, services.ActivityFilter{ID: "US-001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	entry := entries[0]
+	if entry.Event != core.EventStatusChanged || entry.Story != "US-001" || entry.Actor == "" ||
+		entry.Command != "gitta story status US-001 --status doing" || entry.Time.IsZero() {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if got := entry.Summary(); got != "status: todo → doing" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestActivityJournal_Rollover(t *testing.T) {
+	repoPath := t.TempDir()
+	activity := services.NewActivityService(repoPath)
+	journal := services.NewActivityJournal(activity, "gitta sprint close", io.Discard)
+	ctx := context.Background()
+
+	// Rollovers are logged per story; events without changes are not logged
+	journal.After(ctx, core.Event{Type: core.EventRollover, Data: map[string]interface{}{
+		"source":  "~Sprint-01_Login",
+		"target":  "!Sprint-02_Checkout",
+		"stories": []string{"US-001", "US-002"},
+		"changes": map[string]interface{}{"sprint": map[string]interface{}{"before": "~Sprint-01_Login", "after": "!Sprint-02_Checkout"}},
+	}})
+	journal.After(ctx, core.Event{Type: core.EventSprintClosed, Data: map[string]interface{}{"sprint": "!Sprint-01_Login"}})
+
+	entries, err := activity.List(ctx, services.ActivityFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for _, entry := range entries {
+		if entry.Event != core.EventRollover || entry.Sprint != "Sprint-02" || entry.Summary() != "sprint: ~Sprint-01_Login → !Sprint-02_Checkout" {
+			t.Errorf("unexpected entry: %+v", entry)
+		}
+	}
+	if entries, _ := activity.List(ctx, services.ActivityFilter{ID: "US-002"}); len(entries) != 1 {
+		t.Errorf("US-002 entries = %+v", entries)
+	}
+}
+
+func TestActivityService_List(t *testing.T) {
+	repoPath := t.TempDir()
+	activity := services.NewActivityService(repoPath)
+	ctx := context.Background()
+	day := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	for _, entry := range []services.Activity{
+		{Time: day, Actor: "Alice", Email: "alice@example.com", Event: core.EventStoryCreated, Story: "US-001",
+			Changes: map[string]services.FieldChange{"title": {After: "Login"}}},
+		{Time: day.Add(time.Hour), Actor: "Bob", Email: "bob@example.com", Event: core.EventSprintActivated, Sprint: "Sprint-02",
+			Changes: map[string]services.FieldChange{"status": {Before: "ready", After: "active"}}},
+		{Time: day.Add(48 * time.Hour), Actor: "Alice", Email: "alice@example.com", Event: core.EventStoryMoved, Story: "US-001",
+			Changes: map[string]services.FieldChange{"path": {Before: "tasks/backlog/US-001.md", After: "tasks/sprints/!Sprint-02/US-001.md"}}},
+	} {
+		if err := activity.Record(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Each user has a journal; broken lines, e.g. from a bad merge, are skipped
+	alice := filepath.Join(repoPath, services.ActivityDir, "alice@example.com.jsonl")
+	file, err := os.OpenFile(alice, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("alice's journal: %v", err)
+	}
+	file.WriteString("<<<<<<< HEAD\n")
+	file.Close()
+	if _, err := os.Stat(filepath.Join(repoPath, services.ActivityDir, "bob@example.com.jsonl")); err != nil {
+		t.Errorf("bob's journal: %v", err)
+	}
+
+	all, err := activity.List(ctx, services.ActivityFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	for _, entry := range all {
+		events = append(events, string(entry.Event))
+	}
+	if got := strings.Join(events, " "); got != "story.moved sprint.activated story.created" {
+		t.Errorf("List() = %s, want newest first", got)
+	}
+
+	tests := []struct {
+		name   string
+		filter services.ActivityFilter
+		want   int
+	}{
+		{"story", services.ActivityFilter{ID: "us-001"}, 2},
+		{"sprint ID", services.ActivityFilter{ID: "Sprint-02"}, 1},
+		{"sprint folder", services.ActivityFilter{ID: "!Sprint-02_Checkout"}, 1},
+		{"since", services.ActivityFilter{Since: day.Add(time.Minute)}, 2},
+		{"story since", services.ActivityFilter{ID: "US-001", Since: day.Add(24 * time.Hour)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := activity.List(ctx, tt.filter)
+			if err != nil || len(entries) != tt.want {
+				t.Errorf("List(%+v) = %d entries, %v; want %d", tt.filter, len(entries), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSiteBuild_Activity(t *testing.T) {
+	repoPath := t.TempDir()
+	copyTree(t, filepath.Join("..", "..", "testdata", "site"), repoPath)
+	err := services.NewActivityService(repoPath).Record(context.Background(), services.Activity{
+		Time: time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC), Actor: "alice", Event: core.EventStatusChanged, Story: "US-004",
+		Changes: map[string]services.FieldChange{"status": {Before: "todo", After: "doing"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := t.TempDir()
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	svc := services.NewSiteService(repo, repo, noBranchesRepo{}, nil, repoPath, core.DefaultWorkflow())
+	if _, err := svc.Build(context.Background(), services.SiteRequest{OutDir: outDir, Title: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	page := readSiteFile(t, outDir, "stories/US-004.html")
+	if !strings.Contains(page, "<h2>Activity</h2>") || !strings.Contains(page, "<td>2025-03-04 09:30 UTC</td>") || !strings.Contains(page, "status: todo → doing") {
+		t.Errorf("story page misses its activity:\n%s", page)
+	}
+	log := readSiteFile(t, outDir, "activity.html")
+	if !strings.Contains(log, `<a href="stories/US-004.html">US-004</a>`) {
+		t.Errorf("activity.html must link the story:\n%s", log)
+	}
+	if other := readSiteFile(t, outDir, "stories/US-005.html"); strings.Contains(other, "<h2>Activity</h2>") {
+		t.Errorf("story without activity shows an activity section:\n%s", other)
+	}
+}
@@ -1,18 +1,179 @@
 package unit
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
 )
 
+// burndownPoints returns one data point per working day from start with the
+// given remaining points; scope defaults to the first remaining value.
+func burndownPoints(cal core.Calendar, start time.Time, remaining []int, scope []int) []core.BurndownDataPoint {
+	points := make([]core.BurndownDataPoint, len(remaining))
+	for i, r := range remaining {
+		points[i] = core.BurndownDataPoint{
+			Date:            cal.AddWorkingDays(start, i),
+			RemainingPoints: r,
+			RemainingTasks:  r / 2,
+			ScopePoints:     remaining[0],
+		}
+		if scope != nil {
+			points[i].ScopePoints = scope[i]
+		}
+	}
+	return points
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestProjectBurndown(t *testing.T) {
+	cal := weekdayCalendar()
+	monday := date("2025-01-06")
+	end := date("2025-01-20") // two weeks, 10 working days
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		remaining  []int
+		scope      []int
+		wantSlope  float64
+		wantDone   string // empty for no projection
+		wantAtRisk bool
+		wantScope  []core.ScopeChange
+	}{
+		{name: "on track", start: monday, end: end, remaining: []int{20, 16, 12, 8}, wantSlope: -4, wantDone: "2025-01-13"},
+		{name: "trend reaches zero on end date", start: monday, end: end, remaining: []int{20, 18, 16, 14}, wantSlope: -2, wantDone: "2025-01-20", wantAtRisk: true},
+		{name: "rising with scope change", start: monday, end: end, remaining: []int{20, 20, 25, 24}, scope: []int{20, 20, 25, 25},
+			wantSlope: 1.7, wantAtRisk: true, wantScope: []core.ScopeChange{{Date: date("2025-01-08"), Added: 5}}},
+		{name: "finished", start: monday, end: end, remaining: []int{10, 5, 0, 0}, wantSlope: -3.5, wantDone: "2025-01-08"},
+		{name: "single point", start: monday, end: end, remaining: []int{10}},
+		{name: "across a weekend", start: date("2025-01-09"), end: date("2025-01-16"), remaining: []int{10, 8, 6}, wantSlope: -2, wantDone: "2025-01-16", wantAtRisk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := burndownPoints(cal, tt.start, tt.remaining, tt.scope)
+			chart := services.ProjectBurndown(points, tt.start, tt.end, cal)
+
+			if math.Abs(chart.Slope-tt.wantSlope) > 1e-9 {
+				t.Errorf("Slope = %v, want %v", chart.Slope, tt.wantSlope)
+			}
+			gotDone := ""
+			if chart.ProjectedCompletion != nil {
+				gotDone = chart.ProjectedCompletion.Format("2006-01-02")
+			}
+			if gotDone != tt.wantDone {
+				t.Errorf("ProjectedCompletion = %q, want %q", gotDone, tt.wantDone)
+			}
+			if chart.AtRisk != tt.wantAtRisk {
+				t.Errorf("AtRisk = %v, want %v", chart.AtRisk, tt.wantAtRisk)
+			}
+			if len(chart.ScopeChanges) != len(tt.wantScope) {
+				t.Fatalf("ScopeChanges = %v, want %v", chart.ScopeChanges, tt.wantScope)
+			}
+			for i, sc := range tt.wantScope {
+				if !chart.ScopeChanges[i].Date.Equal(sc.Date) || chart.ScopeChanges[i].Added != sc.Added {
+					t.Errorf("ScopeChanges[%d] = %v, want %v", i, chart.ScopeChanges[i], sc)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectBurndown_IdealLine(t *testing.T) {
+	cal := weekdayCalendar()
+	start := date("2025-01-09") // Thursday
+	points := burndownPoints(cal, start, []int{18, 15}, nil)
+	chart := services.ProjectBurndown(points, start, date("2025-01-16"), cal)
+
+	// Thu, Fri, Mon, Tue, Wed: 18 points over 4 steps
+	want := []struct {
+		date      string
+		remaining float64
+	}{{"2025-01-09", 18}, {"2025-01-10", 13.5}, {"2025-01-13", 9}, {"2025-01-14", 4.5}, {"2025-01-15", 0}}
+	if len(chart.Ideal) != len(want) {
+		t.Fatalf("expected %d ideal points, got %d", len(want), len(chart.Ideal))
+	}
+	for i, w := range want {
+		if got := chart.Ideal[i]; got.Date.Format("2006-01-02") != w.date || math.Abs(got.Remaining-w.remaining) > 1e-9 {
+			t.Errorf("Ideal[%d] = %s %.2f, want %s %.2f", i, got.Date.Format("2006-01-02"), got.Remaining, w.date, w.remaining)
+		}
+	}
+	if got := chart.Projected(10); got != 0 {
+		t.Errorf("Projected() must floor at zero, got %v", got)
+	}
+}
+
+func TestProjectBurndown_DropsPointsBeforeStart(t *testing.T) {
+	cal := weekdayCalendar()
+	points := burndownPoints(cal, date("2025-01-02"), []int{30, 20, 20, 18}, nil)
+	chart := services.ProjectBurndown(points, date("2025-01-06"), date("2025-01-20"), cal)
+
+	if len(chart.Points) != 2 || !chart.Points[0].Date.Equal(date("2025-01-06")) {
+		t.Fatalf("expected points from 2025-01-06, got %v", chart.Points)
+	}
+	if chart.Ideal[0].Remaining != 20 {
+		t.Errorf("ideal line must start at the remaining points on the first day, got %v", chart.Ideal[0].Remaining)
+	}
+}
+
+func testBurndownChart() core.BurndownChart {
+	cal := weekdayCalendar()
+	start := date("2025-01-06")
+	points := burndownPoints(cal, start, []int{20, 20, 25, 24}, []int{20, 20, 25, 25})
+	return services.ProjectBurndown(points, start, date("2025-01-20"), cal)
+}
+
 func TestBurndownASCIIChart(t *testing.T) {
-	t.Skip("TODO: implement ASCII chart rendering tests (T059/T060)")
+	chart := testBurndownChart()
+	out := ui.RenderBurndownChart(chart, true, false)
+
+	for _, want := range []string{
+		"\\ = Ideal",
+		"Date range: 2025-01-06 to 2025-01-17",
+		"Projected completion: never at current pace (AT RISK)",
+		"Scope added: 2025-01-08 (+5 points)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("chart missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, "^") {
+		t.Errorf("chart must mark the scope change on the time axis:\n%s", out)
+	}
+
+	tasksOnly := ui.RenderBurndownChart(chart, false, true)
+	if strings.Contains(tasksOnly, "\\") {
+		t.Errorf("ideal line is in points and must not be drawn for tasks:\n%s", tasksOnly)
+	}
+	if got := ui.RenderBurndownChart(core.BurndownChart{}, true, true); got != "No data available for burndown chart" {
+		t.Errorf("unexpected empty chart output: %q", got)
+	}
+}
 
-	_ = []core.BurndownDataPoint{
-		{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), RemainingTasks: 5},
-		{Date: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), RemainingTasks: 3},
-		{Date: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), RemainingTasks: 1},
+func TestBurndownCSV(t *testing.T) {
+	lines := strings.Split(ui.FormatBurndownCSV(testBurndownChart()), "\n")
+
+	if lines[0] != "Date,RemainingPoints,RemainingTasks,TotalPoints,TotalTasks,ScopePoints,IdealPoints,ProjectedPoints,ScopeAdded,AtRisk" {
+		t.Errorf("unexpected header: %s", lines[0])
+	}
+	if len(lines) != 11 {
+		t.Fatalf("expected one row per sprint working day (10), got %d", len(lines)-1)
+	}
+	if lines[3] != "2025-01-08,25,12,,,25,15.56,23.10,5,true" {
+		t.Errorf("unexpected scope change row: %s", lines[3])
+	}
+	if lines[10] != "2025-01-17,,,,,,0.00,35.00,,true" {
+		t.Errorf("unexpected future row: %s", lines[10])
 	}
 }
@@ -0,0 +1,216 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+func weekdayCalendar(holidays ...string) core.Calendar {
+	cal := core.DefaultCalendar()
+	cal.Location = time.UTC
+	cal.Holidays = make(map[string]string)
+	for _, h := range holidays {
+		cal.Holidays[h] = ""
+	}
+	return cal
+}
+
+func TestCalendar_EndDate(t *testing.T) {
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		calendar core.Calendar
+		start    time.Time
+		duration string
+		want     string
+	}{
+		{name: "calendar weeks", calendar: weekdayCalendar(), start: monday, duration: "2w", want: "2025-01-20"},
+		{name: "working days", calendar: weekdayCalendar(), start: monday, duration: "10wd", want: "2025-01-20"},
+		{name: "working days skip holiday", calendar: weekdayCalendar("2025-01-08"), start: monday, duration: "10wd", want: "2025-01-21"},
+		{name: "start on weekend", calendar: weekdayCalendar(), start: monday.AddDate(0, 0, -2), duration: "5wd", want: "2025-01-13"},
+		{name: "zero calendar counts every day", calendar: core.Calendar{Location: time.UTC}, start: monday, duration: "10wd", want: "2025-01-16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.calendar.EndDate(tt.start, tt.duration)
+			if err != nil {
+				t.Fatalf("EndDate() error = %v", err)
+			}
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("EndDate(%s) = %s, want %s", tt.duration, got.Format("2006-01-02"), tt.want)
+			}
+		})
+	}
+
+	if _, err := core.ParseDuration("10wd"); err == nil {
+		t.Error("ParseDuration() must reject working-day durations")
+	}
+}
+
+func TestCalendar_WorkingDuration(t *testing.T) {
+	cal := weekdayCalendar("2025-01-13")
+	friday := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2025, 1, 14, 9, 0, 0, 0, time.UTC)
+
+	// Friday 17:00-24:00, weekend and Monday holiday skipped, Tuesday 00:00-09:00
+	if got := cal.WorkingDuration(friday, tuesday); got != 16*time.Hour {
+		t.Errorf("WorkingDuration() = %v, want 16h", got)
+	}
+	if got := (core.Calendar{}).WorkingDuration(friday, tuesday); got != tuesday.Sub(friday) {
+		t.Errorf("zero calendar must count every hour, got %v", got)
+	}
+}
+
+func TestParseHolidayFiles(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20251224\r\nDTEND;VALUE=DATE:20251227\r\nSUMMARY:Christmas\\, Boxing \r\n Day\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART:20250501T000000Z\r\nDTEND:20250502T000000Z\r\nSUMMARY:Labour Day\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	holidays, err := services.ParseICalHolidays(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("ParseICalHolidays() error = %v", err)
+	}
+	want := map[string]string{
+		"2025-12-24": "Christmas, Boxing Day",
+		"2025-12-25": "Christmas, Boxing Day",
+		"2025-12-26": "Christmas, Boxing Day",
+		"2025-05-01": "Labour Day",
+	}
+	if len(holidays) != len(want) {
+		t.Fatalf("holidays = %v, want %v", holidays, want)
+	}
+	for date, name := range want {
+		if holidays[date] != name {
+			t.Errorf("holidays[%s] = %q, want %q", date, holidays[date], name)
+		}
+	}
+
+	yamlHolidays, err := services.ParseYAMLHolidays(strings.NewReader("holidays:\n  - 2025-12-24\n  - date: 2025-12-25\n    name: Christmas Day\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLHolidays() error = %v", err)
+	}
+	if len(yamlHolidays) != 2 || yamlHolidays["2025-12-25"] != "Christmas Day" {
+		t.Errorf("yaml holidays = %v", yamlHolidays)
+	}
+	if _, err := services.ParseYAMLHolidays(strings.NewReader("holidays: [24.12.2025]\n")); err == nil {
+		t.Error("invalid dates must be rejected")
+	}
+}
+
+func TestLoadProjectConfig_Calendar(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoPath, ".gitta"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, ".gitta", "holidays.yaml"), []byte("holidays:\n  - date: 2025-10-03\n    name: Unity Day\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeProjectConfig(t, repoPath, `calendar:
+  timezone: Europe/Berlin
+  working_days: [sun, mon, tue, wed, thu]
+  holidays: [2025-01-01]
+  holiday_files: [.gitta/holidays.yaml]
+`)
+	cfg, err := services.LoadProjectConfig(repoPath)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	cal := cfg.Calendar
+	if cal.Zone().String() != "Europe/Berlin" {
+		t.Errorf("zone = %v", cal.Zone())
+	}
+	if len(cal.Holidays) != 2 || cal.Holidays["2025-10-03"] != "Unity Day" {
+		t.Errorf("holidays = %v", cal.Holidays)
+	}
+	// 2025-01-03 is a Friday, 2025-01-05 a Sunday
+	if cal.IsWorkingDay(time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC)) || !cal.IsWorkingDay(time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Error("working days must follow the configured weekdays")
+	}
+
+	if def := loadTestConfig(t, "").Calendar; def.IsWorkingDay(time.Date(2025, 1, 4, 12, 0, 0, 0, time.Local)) {
+		t.Error("default calendar must skip Saturdays")
+	}
+
+	for _, content := range []string{
+		"calendar:\n  timezone: Mars/Olympus\n",
+		"calendar:\n  working_days: [funday]\n",
+		"calendar:\n  holidays: [tomorrow]\n",
+		"calendar:\n  holiday_files: [missing.ics]\n",
+	} {
+		repoPath := t.TempDir()
+		writeProjectConfig(t, repoPath, content)
+		if _, err := services.LoadProjectConfig(repoPath); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+func TestGenerateBurndown_SkipsNonWorkingDays(t *testing.T) {
+	repoPath := t.TempDir()
+	analyzer := &fakeHistoryAnalyzer{snapshots: []core.CommitSnapshot{
+		chartSnapshot(10, map[string]core.Status{"US-001": "todo", "US-002": "todo"}),
+		chartSnapshot(1, map[string]core.Status{"US-001": "done", "US-002": "todo"}),
+	}}
+	cal := core.DefaultCalendar()
+	service := services.NewSprintBurndownServiceWithCalendar(analyzer, nil, nil, repoPath, core.DefaultWorkflow(), cal)
+
+	points, err := service.GenerateBurndown(context.Background(), filepath.Join(repoPath, "sprints", "Sprint-01"))
+	if err != nil {
+		t.Fatalf("GenerateBurndown() error = %v", err)
+	}
+
+	want := 0
+	for d := 14; d >= 0; d-- {
+		if cal.IsWorkingDay(time.Now().AddDate(0, 0, -d)) {
+			want++
+		}
+	}
+	if len(points) != want {
+		t.Fatalf("expected one point per working day (%d), got %d", want, len(points))
+	}
+	for _, p := range points {
+		if !cal.IsWorkingDay(p.Date) {
+			t.Errorf("non-working day %s plotted", p.Date.Format("2006-01-02 Mon"))
+		}
+	}
+	// The change made yesterday shows on the first working day since then
+	wantRemaining := 2
+	if cal.IsWorkingDay(time.Now()) || cal.IsWorkingDay(time.Now().AddDate(0, 0, -1)) {
+		wantRemaining = 1
+	}
+	if last := points[len(points)-1]; last.RemainingTasks != wantRemaining {
+		t.Errorf("last point = %+v, want %d remaining", last, wantRemaining)
+	}
+}
+
+func TestFlowStats_WorkingTime(t *testing.T) {
+	// flowBase (2025-03-01 09:00) is a Saturday, so US-001 waits in todo over
+	// the weekend and its branch is created on Monday.
+	svc := newFlowStatsServiceWithCalendar(t, flowTimelines(), weekdayCalendar())
+	report, err := svc.FlowStats(context.Background(), services.FlowStatsOptions{})
+	if err != nil {
+		t.Fatalf("FlowStats() error = %v", err)
+	}
+	flow := report.Stories[0]
+	if flow.ID != "US-001" {
+		t.Fatalf("first story = %s, want US-001", flow.ID)
+	}
+	// Monday 00:00 to Wednesday 09:00
+	if *flow.LeadTime != 57*time.Hour {
+		t.Errorf("lead time = %v, want 57h", *flow.LeadTime)
+	}
+	if *flow.CycleTime != 48*time.Hour {
+		t.Errorf("cycle time = %v, want 48h", *flow.CycleTime)
+	}
+	// Monday 00:00 to 09:00
+	if d := flow.InStatus[core.StatusTodo]; d != 9*time.Hour {
+		t.Errorf("time in todo = %v, want 9h", d)
+	}
+}
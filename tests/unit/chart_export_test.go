@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"bytes"
+	"flag"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata/charts")
+
+func testVelocities() []core.SprintVelocity {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 14)
+	return []core.SprintVelocity{
+		{Name: "~Sprint-01_Login", Start: &start, End: &end, Tasks: 6, CompletedTasks: 5, Points: 21, CompletedPoints: 18},
+		{Name: "~Sprint-02_Checkout", Tasks: 7, CompletedTasks: 7, Points: 24, CompletedPoints: 24},
+		{Name: "~Sprint-03_Search", Tasks: 5, CompletedTasks: 3, Points: 20, CompletedPoints: 13},
+	}
+}
+
+func testProjectedBurndown() core.BurndownChart {
+	cal := weekdayCalendar()
+	start := date("2025-01-06")
+	points := burndownPoints(cal, start, []int{20, 17, 18, 14, 10}, []int{20, 20, 23, 23, 23})
+	return services.ProjectBurndown(points, start, date("2025-01-20"), cal)
+}
+
+func testCharts() map[string]*ui.Chart {
+	return map[string]*ui.Chart{
+		"burndown": ui.NewBurndownChart(testProjectedBurndown()),
+		"burnup":   ui.NewBurnupChart(testBurnup()),
+		"cfd":      ui.NewCumulativeFlowChart(testFlow()),
+		"velocity": ui.NewVelocityChart(testVelocities()),
+	}
+}
+
+func TestChartSVG_Golden(t *testing.T) {
+	for name, chart := range testCharts() {
+		t.Run(name, func(t *testing.T) {
+			golden := filepath.Join("..", "..", "testdata", "charts", name+".svg")
+			got := chart.SVG()
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("SVG differs from %s (run with -update to accept):\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestChartPNG(t *testing.T) {
+	for name, chart := range testCharts() {
+		t.Run(name, func(t *testing.T) {
+			data, err := chart.PNG()
+			if err != nil {
+				t.Fatalf("PNG() error = %v", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("not a PNG image: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != 720 || b.Dy() != 400 {
+				t.Errorf("image size = %dx%d, want 720x400", b.Dx(), b.Dy())
+			}
+			if r, g, b, _ := img.At(2, 2).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+				t.Errorf("background must be white, got %v", img.At(2, 2))
+			}
+
+			// The first legend swatch is filled with its series color
+			if r, g, b, _ := img.At(582, 46).RGBA(); r == 0xffff && g == 0xffff && b == 0xffff {
+				t.Errorf("expected a legend swatch at (582, 46), got %v", img.At(582, 46))
+			}
+		})
+	}
+
+	// Rendering is deterministic, so committed images only change with the data
+	first, _ := ui.NewBurnupChart(testBurnup()).PNG()
+	second, _ := ui.NewBurnupChart(testBurnup()).PNG()
+	if !bytes.Equal(first, second) {
+		t.Error("PNG output must be deterministic")
+	}
+}
@@ -0,0 +1,257 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+const customFieldsConfig = `fields:
+  component: {type: enum, values: [api, web]}
+  due: {type: date}
+  points: {type: int, min: 0, default: 1}
+  labels: {type: list}
+`
+
+func writeProjectConfig(t *testing.T, repoPath, content string) {
+	t.Helper()
+	dir := filepath.Join(repoPath, ".gitta")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func loadTestConfig(t *testing.T, content string) *services.ProjectConfig {
+	t.Helper()
+	repoPath := t.TempDir()
+	writeProjectConfig(t, repoPath, content)
+	cfg, err := services.LoadProjectConfig(repoPath)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	return cfg
+}
+
+func TestLoadProjectConfig_Fields(t *testing.T) {
+	cfg := loadTestConfig(t, customFieldsConfig)
+
+	var names []string
+	for _, f := range cfg.Fields {
+		names = append(names, f.Name)
+	}
+	want := []string{"component", "due", "labels", "points"}
+	if len(names) != len(want) {
+		t.Fatalf("fields = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("fields = %v, want %v", names, want)
+		}
+	}
+
+	points, ok := cfg.Field("points")
+	if !ok || points.Type != core.FieldTypeInt || points.Min == nil || *points.Min != 0 || points.Default != 1 {
+		t.Errorf("points definition = %+v", points)
+	}
+}
+
+func TestLoadProjectConfig_MissingFile(t *testing.T) {
+	cfg, err := services.LoadProjectConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if len(cfg.Fields) != 0 {
+		t.Errorf("expected no fields, got %v", cfg.Fields)
+	}
+}
+
+func TestLoadProjectConfig_InvalidFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"unknown type", "fields:\n  size: {type: bigint}\n"},
+		{"missing type", "fields:\n  size: {min: 1}\n"},
+		{"enum without values", "fields:\n  component: {type: enum}\n"},
+		{"reserved name", "fields:\n  status: {type: string}\n"},
+		{"invalid name", "fields:\n  \"my field\": {type: string}\n"},
+		{"min above max", "fields:\n  points: {type: int, min: 5, max: 1}\n"},
+		{"invalid default", "fields:\n  component: {type: enum, values: [api], default: web}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			writeProjectConfig(t, repoPath, tt.config)
+			_, err := services.LoadProjectConfig(repoPath)
+			if !errors.Is(err, services.ErrInvalidConfig) {
+				t.Errorf("error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestValidateStoryWithRules_CustomFields(t *testing.T) {
+	cfg := loadTestConfig(t, customFieldsConfig+"  owner: {type: string, required: true}\n")
+
+	tests := []struct {
+		name      string
+		extra     map[string]interface{}
+		wantRules []string
+	}{
+		{
+			name:  "valid values",
+			extra: map[string]interface{}{"owner": "team-a", "component": "api", "due": "2025-03-01", "points": 3, "labels": []interface{}{"x"}},
+		},
+		{name: "missing required field", extra: map[string]interface{}{}, wantRules: []string{"required"}},
+		{name: "enum value not allowed", extra: map[string]interface{}{"owner": "a", "component": "cli"}, wantRules: []string{"enum"}},
+		{name: "invalid date", extra: map[string]interface{}{"owner": "a", "due": "next week"}, wantRules: []string{"format"}},
+		{name: "below minimum", extra: map[string]interface{}{"owner": "a", "points": -1}, wantRules: []string{"range"}},
+		{name: "not a whole number", extra: map[string]interface{}{"owner": "a", "points": 1.5}, wantRules: []string{"type"}},
+		{name: "list expected", extra: map[string]interface{}{"owner": "a", "labels": "x"}, wantRules: []string{"type"}},
+		{name: "undeclared keys are ignored", extra: map[string]interface{}{"owner": "a", "estimate": "big"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			story := &core.Story{ID: "US-001", Title: "Story", Priority: core.PriorityMedium, Status: core.StatusTodo, Extra: tt.extra}
+			errs := services.ValidateStoryWithRules(story, cfg.ValidationRules())
+			if len(errs) != len(tt.wantRules) {
+				t.Fatalf("errors = %v, want rules %v", errs, tt.wantRules)
+			}
+			for i, rule := range tt.wantRules {
+				if errs[i].Rule != rule {
+					t.Errorf("error %d rule = %q, want %q", i, errs[i].Rule, rule)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFieldValue(t *testing.T) {
+	cfg := loadTestConfig(t, customFieldsConfig)
+	field := func(name string) core.FieldDefinition {
+		def, _ := cfg.Field(name)
+		return def
+	}
+
+	tests := []struct {
+		name    string
+		def     core.FieldDefinition
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "enum", def: field("component"), raw: "web", want: "web"},
+		{name: "enum rejects unknown value", def: field("component"), raw: "cli", wantErr: true},
+		{name: "int", def: field("points"), raw: "8", want: "8"},
+		{name: "int rejects text", def: field("points"), raw: "eight", wantErr: true},
+		{name: "int respects min", def: field("points"), raw: "-2", wantErr: true},
+		{name: "date", def: field("due"), raw: "2025-03-01", want: "2025-03-01"},
+		{name: "date rejects other formats", def: field("due"), raw: "03/01/2025", wantErr: true},
+		{name: "list", def: field("labels"), raw: "a, b,,c", want: "a,b,c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := services.ParseFieldValue(tt.def, tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFieldValue() error = %v", err)
+			}
+			if s := services.FormatFieldValue(got); s != tt.want {
+				t.Errorf("ParseFieldValue() = %q, want %q", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareFieldValues(t *testing.T) {
+	cfg := loadTestConfig(t, customFieldsConfig)
+	points, _ := cfg.Field("points")
+	due, _ := cfg.Field("due")
+	component, _ := cfg.Field("component")
+
+	tests := []struct {
+		name string
+		def  core.FieldDefinition
+		a, b interface{}
+		want int
+	}{
+		{"numbers compare numerically", points, 2, 10, -1},
+		{"dates compare chronologically", due, "2025-03-01", "2025-01-15", 1},
+		{"enums follow declaration order", component, "web", "api", 1},
+		{"missing values sort last", points, nil, 1, 1},
+		{"equal values", points, 3, 3.0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := services.CompareFieldValues(tt.def, tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareFieldValues(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFieldValue(t *testing.T) {
+	if !services.MatchFieldValue("api", "api") {
+		t.Error("expected scalar match")
+	}
+	if !services.MatchFieldValue(5, "5") {
+		t.Error("expected number to match its text form")
+	}
+	if !services.MatchFieldValue([]interface{}{"x", "y"}, "y") {
+		t.Error("expected list item match")
+	}
+	if services.MatchFieldValue(nil, "") {
+		t.Error("missing values must not match")
+	}
+}
+
+func TestCreateService_CustomFields(t *testing.T) {
+	cfg := loadTestConfig(t, customFieldsConfig)
+
+	tmpDir := t.TempDir()
+	storyDir := filepath.Join(tmpDir, "stories")
+	parser := filesystem.NewMarkdownParserWithRules(cfg.ValidationRules())
+	createService := services.NewCreateService(filesystem.NewIDCounter(tmpDir), parser, filesystem.NewRepository(parser), storyDir)
+
+	story, _, err := createService.CreateStory(context.Background(), services.CreateStoryRequest{
+		Title:            "Custom fields",
+		Fields:           map[string]interface{}{"component": "api", "labels": []interface{}{"x", "y"}},
+		FieldDefinitions: cfg.Fields,
+	})
+	if err != nil {
+		t.Fatalf("CreateStory() error = %v", err)
+	}
+
+	for name, want := range map[string]string{"component": "api", "labels": "x,y", "points": "1"} {
+		got, ok := story.FieldValue(name)
+		if !ok || services.FormatFieldValue(got) != want {
+			t.Errorf("field %s = %v, want %s", name, got, want)
+		}
+	}
+
+	_, _, err = createService.CreateStory(context.Background(), services.CreateStoryRequest{
+		Title:            "Missing required",
+		FieldDefinitions: []core.FieldDefinition{{Name: "owner", Type: core.FieldTypeString, Required: true}},
+	})
+	if !errors.Is(err, services.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+}
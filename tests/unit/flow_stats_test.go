@@ -0,0 +1,204 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+// fakeTimelineAnalyzer returns fixed timelines keyed by story ID.
+type fakeTimelineAnalyzer struct {
+	timelines map[string]core.StoryTimeline
+	req       core.TimelineRequest
+}
+
+func (f *fakeTimelineAnalyzer) StoryTimelines(ctx context.Context, req core.TimelineRequest) ([]core.StoryTimeline, error) {
+	f.req = req
+	out := make([]core.StoryTimeline, 0, len(req.StoryIDs))
+	for _, id := range req.StoryIDs {
+		tl := f.timelines[id]
+		tl.ID = id
+		out = append(out, tl)
+	}
+	return out, nil
+}
+
+var flowBase = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func flowDay(days int) *time.Time {
+	t := flowBase.AddDate(0, 0, days)
+	return &t
+}
+
+func newFlowStatsService(t *testing.T, analyzer *fakeTimelineAnalyzer) services.FlowStatsService {
+	t.Helper()
+	return newFlowStatsServiceWithCalendar(t, analyzer, core.Calendar{})
+}
+
+func newFlowStatsServiceWithCalendar(t *testing.T, analyzer *fakeTimelineAnalyzer, calendar core.Calendar) services.FlowStatsService {
+	t.Helper()
+	repoPath := t.TempDir()
+	backlog := filepath.Join(repoPath, "tasks", "backlog")
+	sprint := filepath.Join(repoPath, "tasks", "sprints", "Sprint-01")
+	for _, dir := range []string{backlog, sprint} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parser := filesystem.NewMarkdownParser()
+	write := func(dir, id string, tags ...string) {
+		story := &core.Story{ID: id, Title: "Story " + id, Priority: core.PriorityMedium, Tags: tags}
+		if err := parser.WriteStory(context.Background(), filepath.Join(dir, id+".md"), story); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(sprint, "US-001", "api")
+	write(sprint, "US-002", "web")
+	write(backlog, "US-003", "api")
+	write(backlog, "US-004")
+
+	repo := filesystem.NewRepository(parser)
+	return services.NewFlowStatsServiceWithCalendar(repo, repo, analyzer, repoPath, core.DefaultWorkflow(), calendar)
+}
+
+func flowTimelines() *fakeTimelineAnalyzer {
+	return &fakeTimelineAnalyzer{timelines: map[string]core.StoryTimeline{
+		// created → branch 2d → pushed 1d → merged 1d
+		"US-001": {Created: flowDay(0), BranchCreated: flowDay(2), FirstPush: flowDay(3), Merged: flowDay(4)},
+		// never pushed: doing lasts until merge
+		"US-002": {Created: flowDay(0), BranchCreated: flowDay(5), Merged: flowDay(10)},
+		// in progress
+		"US-003": {Created: flowDay(1), BranchCreated: flowDay(6)},
+	}}
+}
+
+func TestFlowStats_ComputesMetrics(t *testing.T) {
+	analyzer := flowTimelines()
+	report, err := newFlowStatsService(t, analyzer).FlowStats(context.Background(), services.FlowStatsOptions{})
+	if err != nil {
+		t.Fatalf("FlowStats() error = %v", err)
+	}
+
+	if analyzer.req.BranchPrefix != "feat/" || len(analyzer.req.StoryIDs) != 4 {
+		t.Errorf("unexpected timeline request: %+v", analyzer.req)
+	}
+	if len(report.Stories) != 2 || report.InProgress != 1 {
+		t.Fatalf("stories = %d, in progress = %d; want 2 and 1", len(report.Stories), report.InProgress)
+	}
+	if report.Stories[0].ID != "US-001" {
+		t.Errorf("stories must be ordered by merge time, got %s first", report.Stories[0].ID)
+	}
+
+	us1 := report.Stories[0]
+	if *us1.LeadTime != 4*24*time.Hour || *us1.CycleTime != 2*24*time.Hour {
+		t.Errorf("US-001 lead/cycle = %v/%v", *us1.LeadTime, *us1.CycleTime)
+	}
+	want := map[core.Status]time.Duration{"todo": 48 * time.Hour, "doing": 24 * time.Hour, "review": 24 * time.Hour}
+	for status, d := range want {
+		if us1.InStatus[status] != d {
+			t.Errorf("US-001 in %s = %v, want %v", status, us1.InStatus[status], d)
+		}
+	}
+	if us2 := report.Stories[1]; us2.InStatus["doing"] != 5*24*time.Hour {
+		t.Errorf("unpushed story must spend branch-to-merge in doing, got %v", us2.InStatus)
+	}
+
+	var states []string
+	for _, s := range report.TimeInStatus {
+		states = append(states, string(s.Status))
+	}
+	if strings.Join(states, ",") != "todo,doing,review" {
+		t.Errorf("time-in-status states = %v", states)
+	}
+	if report.CycleTime.Count != 2 || report.CycleTime.Max != 5*24*time.Hour {
+		t.Errorf("cycle time stats = %+v", report.CycleTime)
+	}
+}
+
+func TestFlowStats_SinceAndTags(t *testing.T) {
+	service := newFlowStatsService(t, flowTimelines())
+
+	report, err := service.FlowStats(context.Background(), services.FlowStatsOptions{Since: *flowDay(5)})
+	if err != nil {
+		t.Fatalf("FlowStats() error = %v", err)
+	}
+	if len(report.Stories) != 1 || report.Stories[0].ID != "US-002" {
+		t.Errorf("--since must keep stories merged after it, got %+v", report.Stories)
+	}
+
+	report, err = service.FlowStats(context.Background(), services.FlowStatsOptions{Tags: []string{"api"}})
+	if err != nil {
+		t.Fatalf("FlowStats() error = %v", err)
+	}
+	if len(report.Stories) != 1 || report.Stories[0].ID != "US-001" || report.InProgress != 1 {
+		t.Errorf("--tag api must keep US-001 and in-progress US-003, got %+v (in progress %d)", report.Stories, report.InProgress)
+	}
+}
+
+func TestSummarizeDurations_Percentiles(t *testing.T) {
+	var samples []time.Duration
+	for i := 20; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Hour)
+	}
+	stats := services.SummarizeDurations(samples)
+	if stats.Count != 20 || stats.P50 != 10*time.Hour || stats.P85 != 17*time.Hour || stats.P95 != 19*time.Hour || stats.Max != 20*time.Hour {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.Mean != 10*time.Hour+30*time.Minute {
+		t.Errorf("mean = %v", stats.Mean)
+	}
+	if got := services.SummarizeDurations(nil); got.Count != 0 {
+		t.Errorf("empty stats = %+v", got)
+	}
+}
+
+func TestFlowRendering(t *testing.T) {
+	if got := ui.FormatDuration(50 * time.Hour); got != "2d 2h" {
+		t.Errorf("FormatDuration(50h) = %q", got)
+	}
+	if got := ui.FormatDuration(90 * time.Minute); got != "1h 30m" {
+		t.Errorf("FormatDuration(90m) = %q", got)
+	}
+
+	histogram := ui.RenderDurationHistogram([]time.Duration{12 * time.Hour, 4 * 24 * time.Hour, 30 * 24 * time.Hour})
+	for _, want := range []string{"0-1d |", "3-5d |", "21d+ |"} {
+		if !strings.Contains(histogram, want) {
+			t.Errorf("histogram missing %q:\n%s", want, histogram)
+		}
+	}
+
+	lead, cycle := 96*time.Hour, 48*time.Hour
+	todo := 48 * time.Hour
+	report := core.FlowReport{
+		Stories: []core.StoryFlow{{
+			ID: "US-001", Title: "Login, with SSO",
+			Timeline:  core.StoryTimeline{Created: flowDay(0), BranchCreated: flowDay(2), Merged: flowDay(4)},
+			LeadTime:  &lead,
+			CycleTime: &cycle,
+			InStatus:  map[core.Status]time.Duration{"todo": todo},
+		}},
+		TimeInStatus: []core.StatusDurationStats{{Status: "todo"}, {Status: "review"}},
+	}
+	wantCSV := "ID,Title,Created,BranchCreated,FirstPush,Merged,LeadTimeHours,CycleTimeHours,In_todo_Hours,In_review_Hours\n" +
+		`US-001,"Login, with SSO",2025-03-01T09:00:00Z,2025-03-03T09:00:00Z,,2025-03-05T09:00:00Z,96.0,48.0,48.0,`
+	if got := ui.FormatFlowCSV(report); got != wantCSV {
+		t.Errorf("FormatFlowCSV() =\n%s\nwant\n%s", got, wantCSV)
+	}
+
+	scatter := ui.RenderCycleTimeScatter(report.Stories, cycle)
+	if !strings.Contains(scatter, "*") || !strings.Contains(scatter, "Date range: 2025-03-05 to 2025-03-05") {
+		t.Errorf("scatterplot missing story or date range:\n%s", scatter)
+	}
+	if got := ui.RenderFlowReport(core.FlowReport{InProgress: 2}); got != "No merged stories found (2 in progress)" {
+		t.Errorf("empty report = %q", got)
+	}
+}
@@ -0,0 +1,265 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// fakeSprintHistory returns fixed sprint periods keyed by sprint ID.
+type fakeSprintHistory struct {
+	periods map[string]core.SprintPeriod
+	req     core.SprintPeriodRequest
+}
+
+func (f *fakeSprintHistory) SprintPeriods(ctx context.Context, req core.SprintPeriodRequest) ([]core.SprintPeriod, error) {
+	f.req = req
+	out := make([]core.SprintPeriod, 0, len(req.SprintIDs))
+	for _, id := range req.SprintIDs {
+		p := f.periods[id]
+		p.ID = id
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// noBranchesRepo is a GitRepository without branches, so stories keep their
+// explicit or initial status.
+type noBranchesRepo struct{}
+
+func (noBranchesRepo) GetBranchList(ctx context.Context, repoPath string) ([]core.Branch, error) {
+	return nil, nil
+}
+
+func (noBranchesRepo) CheckBranchMerged(ctx context.Context, repoPath, branchName string) (bool, error) {
+	return false, nil
+}
+
+func (noBranchesRepo) CreateBranch(ctx context.Context, repoPath, branchName string) error {
+	return nil
+}
+
+func (noBranchesRepo) CheckoutBranch(ctx context.Context, repoPath, branchName string, force bool) error {
+	return nil
+}
+
+type velocityStory struct {
+	id     string
+	status core.Status
+	points int
+	tags   []string
+}
+
+// velocityWorkspace writes sprints (folder name → stories) and backlog stories
+// into a temporary workspace and returns its path.
+func velocityWorkspace(t *testing.T, sprints map[string][]velocityStory, backlog []velocityStory) string {
+	t.Helper()
+	repoPath := t.TempDir()
+	parser := filesystem.NewMarkdownParser()
+	write := func(dir string, stories []velocityStory) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range stories {
+			story := &core.Story{ID: s.id, Title: "Story " + s.id, Status: s.status, Priority: core.PriorityMedium, Tags: s.tags}
+			if s.points > 0 {
+				story.Extra = map[string]interface{}{"points": s.points}
+			}
+			if err := parser.WriteStory(context.Background(), filepath.Join(dir, s.id+".md"), story); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	write(filepath.Join(repoPath, "tasks", "backlog"), backlog)
+	for name, stories := range sprints {
+		write(filepath.Join(repoPath, "tasks", "sprints", name), stories)
+	}
+	return repoPath
+}
+
+func velocityHistory() *fakeSprintHistory {
+	day := func(d int) *time.Time {
+		t := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC).AddDate(0, 0, d)
+		return &t
+	}
+	return &fakeSprintHistory{periods: map[string]core.SprintPeriod{
+		"Sprint-01": {Start: day(0), End: day(14)},
+		"Sprint-02": {Start: day(14), End: day(28)},
+		"Sprint-03": {Start: day(28), End: day(42)},
+	}}
+}
+
+func velocitySprints() map[string][]velocityStory {
+	return map[string][]velocityStory{
+		"~Sprint-02": {
+			{id: "US-004", status: core.StatusDone, points: 5},
+			{id: "US-005", status: core.StatusDone, points: 3},
+			{id: "US-006", status: core.StatusDone, points: 2},
+		},
+		"~Sprint-01": {
+			{id: "US-001", status: core.StatusDone, points: 3},
+			{id: "US-002", status: core.StatusDone},
+			{id: "US-003", status: core.StatusDoing, points: 8},
+		},
+		"~Sprint-03": {
+			{id: "US-007", status: core.StatusDone, points: 1},
+		},
+		"@Sprint-04": {
+			{id: "US-008", status: core.StatusDoing, tags: []string{"api"}},
+		},
+	}
+}
+
+func TestVelocity_ArchivedSprints(t *testing.T) {
+	repoPath := velocityWorkspace(t, velocitySprints(), nil)
+	history := velocityHistory()
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	svc := services.NewVelocityService(repo, repo, noBranchesRepo{}, history, repoPath)
+
+	velocities, err := svc.Velocity(context.Background(), services.VelocityOptions{})
+	if err != nil {
+		t.Fatalf("Velocity() error = %v", err)
+	}
+	if len(history.req.SprintIDs) != 3 {
+		t.Errorf("only archived sprints must be requested, got %v", history.req.SprintIDs)
+	}
+
+	var names []string
+	for _, v := range velocities {
+		names = append(names, v.Name)
+	}
+	if want := []string{"~Sprint-01", "~Sprint-02", "~Sprint-03"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("sprints = %v, want %v (ordered by start)", names, want)
+	}
+
+	first := velocities[0]
+	if first.Tasks != 3 || first.CompletedTasks != 2 || first.Points != 11 || first.CompletedPoints != 3 {
+		t.Errorf("~Sprint-01 = %+v", first)
+	}
+	if first.Start == nil || first.End == nil || first.End.Sub(*first.Start) != 14*24*time.Hour {
+		t.Errorf("~Sprint-01 period = %v..%v", first.Start, first.End)
+	}
+
+	last, err := svc.Velocity(context.Background(), services.VelocityOptions{Last: 2})
+	if err != nil {
+		t.Fatalf("Velocity(Last: 2) error = %v", err)
+	}
+	if len(last) != 2 || last[0].Name != "~Sprint-02" {
+		t.Errorf("Last: 2 must keep the most recent sprints, got %+v", last)
+	}
+}
+
+func TestSimulateCompletion_Deterministic(t *testing.T) {
+	throughput := []int{2, 3, 5}
+	a := services.SimulateCompletion(throughput, 20, 500, rand.New(rand.NewSource(7)))
+	b := services.SimulateCompletion(throughput, 20, 500, rand.New(rand.NewSource(7)))
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("same seed must give the same results")
+	}
+	// 20 items need between 4 (all 5s) and 10 (all 2s) sprints
+	if a[0] < 4 || a[len(a)-1] > 10 {
+		t.Errorf("results out of range: min %d, max %d", a[0], a[len(a)-1])
+	}
+	for i := 1; i < len(a); i++ {
+		if a[i] < a[i-1] {
+			t.Fatal("results must be sorted")
+		}
+	}
+}
+
+func TestSimulateCapacity_ConstantThroughput(t *testing.T) {
+	totals := services.SimulateCapacity([]int{4}, 3, 100, rand.New(rand.NewSource(1)))
+	for _, total := range totals {
+		if total != 12 {
+			t.Fatalf("constant throughput of 4 over 3 sprints must give 12, got %d", total)
+		}
+	}
+}
+
+func TestForecast_CompletionAndCapacity(t *testing.T) {
+	backlog := []velocityStory{
+		{id: "US-009", tags: []string{"api"}},
+		{id: "US-010", tags: []string{"api"}},
+		{id: "US-011", status: core.StatusDone, tags: []string{"api"}},
+		{id: "US-012"},
+	}
+	repoPath := velocityWorkspace(t, velocitySprints(), backlog)
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	svc := services.NewForecastService(repo, repo, noBranchesRepo{}, velocityHistory(), repoPath)
+	ctx := context.Background()
+
+	completion, err := svc.ForecastCompletion(ctx, services.ForecastRequest{Filter: services.Filter{Tags: []string{"api"}}, Trials: 1000, Seed: 42})
+	if err != nil {
+		t.Fatalf("ForecastCompletion() error = %v", err)
+	}
+	// US-008 (active sprint), US-009 and US-010; US-011 is done
+	if completion.Items != 3 {
+		t.Errorf("Items = %d, want 3", completion.Items)
+	}
+	if !reflect.DeepEqual(completion.Throughput, []int{2, 3, 1}) {
+		t.Errorf("Throughput = %v, want [2 3 1]", completion.Throughput)
+	}
+	if completion.SprintLength != 14*24*time.Hour {
+		t.Errorf("SprintLength = %v, want the median archived period", completion.SprintLength)
+	}
+	if len(completion.Outcomes) != 3 {
+		t.Fatalf("Outcomes = %+v", completion.Outcomes)
+	}
+	for i := 1; i < len(completion.Outcomes); i++ {
+		if completion.Outcomes[i].Sprints < completion.Outcomes[i-1].Sprints {
+			t.Errorf("higher confidence must not need fewer sprints: %+v", completion.Outcomes)
+		}
+	}
+	// 3 items need between 1 and 3 sprints
+	if o := completion.Outcomes[2]; o.Sprints < 1 || o.Sprints > 3 {
+		t.Errorf("95%% outcome = %+v", o)
+	}
+
+	again, err := svc.ForecastCompletion(ctx, services.ForecastRequest{Filter: services.Filter{Tags: []string{"api"}}, Trials: 1000, Seed: 42})
+	if err != nil {
+		t.Fatalf("ForecastCompletion() error = %v", err)
+	}
+	for i, o := range again.Outcomes {
+		if o.Sprints != completion.Outcomes[i].Sprints {
+			t.Errorf("seeded forecasts must be reproducible: %+v vs %+v", again.Outcomes, completion.Outcomes)
+		}
+	}
+
+	capacity, err := svc.ForecastCapacity(ctx, services.ForecastRequest{Sprints: 2, History: 2, Trials: 1000, Seed: 42})
+	if err != nil {
+		t.Fatalf("ForecastCapacity() error = %v", err)
+	}
+	if !reflect.DeepEqual(capacity.Throughput, []int{3, 1}) {
+		t.Errorf("History: 2 must sample the last two sprints, got %v", capacity.Throughput)
+	}
+	for i, o := range capacity.Outcomes {
+		if o.Items < 2 || o.Items > 6 {
+			t.Errorf("capacity outcome out of range: %+v", o)
+		}
+		if i > 0 && o.Items > capacity.Outcomes[i-1].Items {
+			t.Errorf("higher confidence must not promise more items: %+v", capacity.Outcomes)
+		}
+	}
+}
+
+func TestForecast_NoThroughput(t *testing.T) {
+	repoPath := velocityWorkspace(t, map[string][]velocityStory{
+		"~Sprint-01": {{id: "US-001", status: core.StatusDoing}},
+	}, nil)
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	svc := services.NewForecastService(repo, repo, noBranchesRepo{}, velocityHistory(), repoPath)
+
+	_, err := svc.ForecastCapacity(context.Background(), services.ForecastRequest{Seed: 1})
+	if !errors.Is(err, services.ErrNoThroughput) {
+		t.Errorf("expected ErrNoThroughput, got %v", err)
+	}
+}
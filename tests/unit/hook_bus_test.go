@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+func TestLoadProjectConfig_Hooks(t *testing.T) {
+	cfg := loadTestConfig(t, "hooks:\n  - {event: story.created, run: ./notify.sh}\n  - {event: status.changed, plugin: jira, pre: true, timeout: 10s}\n")
+	if len(cfg.Hooks) != 2 {
+		t.Fatalf("hooks = %+v", cfg.Hooks)
+	}
+	if h := cfg.Hooks[0]; h.Event != core.EventStoryCreated || h.Run != "./notify.sh" || h.Pre || h.Timeout != services.DefaultHookTimeout {
+		t.Errorf("hook 0 = %+v", h)
+	}
+	if h := cfg.Hooks[1]; h.Plugin != "jira" || !h.Pre || h.Timeout.String() != "10s" || h.Name() != "plugin jira" {
+		t.Errorf("hook 1 = %+v (%s)", h, h.Name())
+	}
+}
+
+func TestLoadProjectConfig_InvalidHooks(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"unknown event", "hooks:\n  - {event: story.deleted, run: echo}\n"},
+		{"no command", "hooks:\n  - {event: story.created}\n"},
+		{"run and plugin", "hooks:\n  - {event: story.created, run: echo, plugin: jira}\n"},
+		{"invalid timeout", "hooks:\n  - {event: story.created, run: echo, timeout: soon}\n"},
+		{"negative timeout", "hooks:\n  - {event: story.created, run: echo, timeout: -1s}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			writeProjectConfig(t, repoPath, tt.config)
+			if _, err := services.LoadProjectConfig(repoPath); !errors.Is(err, services.ErrInvalidConfig) {
+				t.Errorf("error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestHookBus_StatusChanged(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks are shell commands")
+	}
+	tmpDir := t.TempDir()
+	backlogDir := filepath.Join(tmpDir, "backlog")
+	if err := os.MkdirAll(backlogDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	filePath := createTestStory(t, backlogDir, "US-001", "Story", core.StatusTodo)
+	parser := filesystem.NewMarkdownParser()
+	ctx := context.Background()
+
+	// A failing pre hook vetoes the change and the post hook never runs
+	hooks := []services.Hook{
+		{Event: core.EventStatusChanged, Run: `test "$GITTA_STORY_ID" != US-001`, Pre: true, Timeout: services.DefaultHookTimeout},
+		{Event: core.EventStatusChanged, Run: "cat > payload.json", Timeout: services.DefaultHookTimeout},
+	}
+	bus := services.NewHookBus(tmpDir, hooks, "", io.Discard)
+	update := services.NewUpdateServiceWithEvents(parser, filesystem.NewRepository(parser), tmpDir, core.DefaultWorkflow(), bus)
+	if err := update.UpdateStatus(ctx, "US-001", core.StatusDoing); !errors.Is(err, core.ErrEventVetoed) {
+		t.Fatalf("error = %v, want ErrEventVetoed", err)
+	}
+	if data, _ := os.ReadFile(filePath); !strings.Contains(string(data), "status: todo") {
+		t.Errorf("vetoed change was written:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "payload.json")); !os.IsNotExist(err) {
+		t.Errorf("post hook ran after a veto: %v", err)
+	}
+
+	// Post hooks read the payload on stdin
+	bus = services.NewHookBus(tmpDir, hooks[1:], "", io.Discard)
+	update = services.NewUpdateServiceWithEvents(parser, filesystem.NewRepository(parser), tmpDir, core.DefaultWorkflow(), bus)
+	if err := update.UpdateStatus(ctx, "US-001", core.StatusDoing); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "payload.json"))
+	if err != nil {
+		t.Fatalf("post hook did not run: %v", err)
+	}
+	var payload services.HookPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v\n%s", err, data)
+	}
+	story, _ := payload.Data["story"].(map[string]interface{})
+	if payload.Event != core.EventStatusChanged || payload.Phase != "post" || payload.RepoRoot != tmpDir ||
+		payload.Data["from"] != "todo" || payload.Data["to"] != "doing" || story["id"] != "US-001" || story["status"] != "doing" {
+		t.Errorf("unexpected payload: %s", data)
+	}
+}
+
+func TestHookBus_FailingPostHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks are shell commands")
+	}
+	var output strings.Builder
+	hooks := []services.Hook{{Event: core.EventSprintClosed, Run: "exit 3", Timeout: services.DefaultHookTimeout}}
+	bus := services.NewHookBus(t.TempDir(), hooks, "", &output)
+
+	// Failures after an operation are warnings, and other events run no hooks
+	bus.After(context.Background(), core.Event{Type: core.EventSprintClosed, Data: map[string]interface{}{}})
+	if !strings.Contains(output.String(), `Warning: sprint.closed hook "exit 3" failed`) {
+		t.Errorf("output = %q", output.String())
+	}
+	if err := bus.Before(context.Background(), core.Event{Type: core.EventSprintClosed}); err != nil {
+		t.Errorf("Before() = %v; post hooks must not run before the operation", err)
+	}
+}
@@ -0,0 +1,246 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+const jiraExport = "\uFEFFSummary,Issue key,Issue id,Status,Status Category,Priority,Assignee,Labels,Labels,Sprint,Sprint,Created,Description,Custom field (Story Points),Environment\n" +
+	"Refund a payment,SHOP-1,10001,To Do,To Do,High,Alice Smith,payments,refunds,,,12/Mar/26 9:30 AM,\"h2. Goal\n*Refund* a {{payment_id}} with _care_.\n* one\n** nested\nSee [docs|https://example.com/refunds]\",3,\n" +
+	"Checkout coupons,SHOP-2,10002,In Review,In Progress,Medium,,,,Sprint 1,Checkout,2026-03-14T10:00:00Z,,5,staging\n" +
+	"Search orders,SHOP-3,10003,In Progress,In Progress,Low,,search,,Sprint 3 Search,,,,,\n" +
+	"Fix typo,SHOP-4,10004,Closed,Done,Lowest,,,,,,,,8,prod\n" +
+	",SHOP-5,10005,To Do,To Do,,,,,,,,,,\n"
+
+const trelloExport = `{
+  "name": "Shop",
+  "lists": [
+    {"id": "l1", "name": "Backlog"},
+    {"id": "l2", "name": "Doing"},
+    {"id": "l3", "name": "Old", "closed": true}
+  ],
+  "members": [{"id": "m1", "username": "bob"}, {"id": "m2", "username": "carol"}],
+  "cards": [
+    {"id": "5f5a1b2c0000000000000001", "shortLink": "abCD12", "name": "Gift cards", "desc": "Sell **gift** cards.",
+     "idList": "l1", "idMembers": ["m1", "m2"], "labels": [{"name": "Payments Team", "color": "green"}, {"name": "", "color": "red"}],
+     "due": "2026-04-01T12:00:00.000Z", "dateLastActivity": "2026-03-20T08:00:00.000Z"},
+    {"id": "5f5a1b2c0000000000000002", "shortLink": "efGH34", "name": "Wishlist", "desc": "",
+     "idList": "l2", "labels": [], "attachments": [{}]},
+    {"id": "5f5a1b2c0000000000000003", "shortLink": "ijKL56", "name": "Archived", "idList": "l1", "closed": true},
+    {"id": "5f5a1b2c0000000000000004", "shortLink": "mnOP78", "name": "In old list", "idList": "l3"}
+  ],
+  "checklists": [
+    {"idCard": "5f5a1b2c0000000000000001", "name": "Tasks", "pos": 1, "checkItems": [
+      {"name": "Design", "state": "complete", "pos": 1}, {"name": "Build", "state": "incomplete", "pos": 2}]}
+  ],
+  "actions": [
+    {"type": "commentCard", "data": {"card": {"id": "5f5a1b2c0000000000000002"}}},
+    {"type": "updateCard", "data": {"card": {"id": "5f5a1b2c0000000000000002"}}}
+  ]
+}`
+
+// newImportService imports into a copy of the testdata/site workspace with a
+// story_points custom field.
+func newImportService(t *testing.T) (services.ImportService, string) {
+	t.Helper()
+	repoPath := t.TempDir()
+	copyTree(t, filepath.Join("..", "..", "testdata", "site"), repoPath)
+	writeProjectConfig(t, repoPath, "fields:\n  story_points: {type: int, min: 0}\n")
+	cfg, err := services.LoadProjectConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := filesystem.NewMarkdownParserWithRules(cfg.ValidationRules())
+	repo := filesystem.NewRepository(parser)
+	board := services.NewBoardService(repo, repo, noBranchesRepo{}, repoPath, cfg.Workflow)
+	return services.NewImportService(filesystem.NewIDCounter(repoPath), parser, board,
+		services.NewSprintPlanService(repo, repoPath), cfg.Fields, repoPath,
+		filepath.Join(repoPath, "tasks", "backlog"), cfg.Workflow), repoPath
+}
+
+func importedByKey(report *services.ImportReport) map[string]services.ImportedStory {
+	stories := make(map[string]services.ImportedStory)
+	for _, story := range report.Imported {
+		stories[story.Key] = story
+	}
+	return stories
+}
+
+func readImportedStory(t *testing.T, path string) *core.Story {
+	t.Helper()
+	story, err := filesystem.NewMarkdownParser().ReadStory(context.Background(), path)
+	if err != nil {
+		t.Fatalf("parse %s: %v", path, err)
+	}
+	return story
+}
+
+func TestParseJiraCSV(t *testing.T) {
+	batch, err := services.ParseJiraCSV(strings.NewReader(jiraExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Source != "jira" || len(batch.Items) != 5 {
+		t.Fatalf("unexpected batch: %+v", batch)
+	}
+
+	refund := batch.Items[0]
+	if refund.Key != "SHOP-1" || strings.Join(refund.Labels, ",") != "payments,refunds" || refund.Created == nil {
+		t.Errorf("unexpected item: %+v", refund)
+	}
+	want := "## Goal\n**Refund** a `payment_id` with *care*.\n- one\n  - nested\nSee [docs](https://example.com/refunds)"
+	if refund.Body != want {
+		t.Errorf("description = %q, want %q", refund.Body, want)
+	}
+	if strings.Join(refund.Fields["Story Points"], ",") != "3" {
+		t.Errorf("custom field not collected: %v", refund.Fields)
+	}
+	if _, ok := refund.Fields["Issue id"]; ok {
+		t.Errorf("mapped column collected as a field: %v", refund.Fields)
+	}
+
+	// The last sprint is kept
+	if batch.Items[1].Sprint != "Checkout" || batch.Items[1].StatusCategory != core.CategoryInProgress {
+		t.Errorf("unexpected item: %+v", batch.Items[1])
+	}
+
+	_, err = services.ParseJiraCSV(strings.NewReader("Title,Key\nA,B\n"))
+	if !errors.Is(err, services.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestImportService_Jira(t *testing.T) {
+	importer, repoPath := newImportService(t)
+	ctx := context.Background()
+	batch, err := services.ParseJiraCSV(strings.NewReader(jiraExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run reports the import and writes nothing
+	report, err := importer.Import(ctx, batch, services.ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Imported) != 4 || report.Imported[0].ID != "" || len(report.Sprints) != 1 {
+		t.Fatalf("unexpected dry run: %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, services.ImportMappingDir)); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote the mapping table")
+	}
+
+	report, err = importer.Import(ctx, batch, services.ImportOptions{UserMap: map[string]string{"Alice Smith": "alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stories := importedByKey(report)
+	if len(stories) != 4 || len(report.Skipped) != 1 || report.Skipped[0].Key != "SHOP-5" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// Items are placed by status and sprint
+	placements := map[string][2]string{
+		"SHOP-1": {"todo", "Backlog"},
+		"SHOP-2": {"review", "!Sprint-02_Checkout"},
+		"SHOP-3": {"doing", "_Sprint-3-Search"},
+		"SHOP-4": {"done", "!Sprint-02_Checkout"},
+	}
+	for key, want := range placements {
+		got := stories[key]
+		if string(got.Status) != want[0] || !strings.HasSuffix(got.Location, want[1]) {
+			t.Errorf("%s: status %s in %s, want %s in %s", key, got.Status, got.Location, want[0], want[1])
+		}
+	}
+	if len(report.Sprints) != 1 || !strings.HasSuffix(report.Sprints[0], "Sprint-3-Search") {
+		t.Errorf("unexpected sprints: %v", report.Sprints)
+	}
+
+	refund := readImportedStory(t, stories["SHOP-1"].Path)
+	if !strings.HasPrefix(refund.ID, "US-") {
+		t.Errorf("unexpected ID %s", refund.ID)
+	}
+	if refund.Priority != core.PriorityHigh || refund.Assignee == nil || *refund.Assignee != "alice" {
+		t.Errorf("unexpected story: %+v", refund)
+	}
+	if refund.Extra["story_points"] != 3 {
+		t.Errorf("story_points = %v, want 3", refund.Extra["story_points"])
+	}
+	if refs, ok := refund.Extra["external"].(map[string]interface{}); !ok || refs["jira"] != "SHOP-1" {
+		t.Errorf("original key not kept: %v", refund.Extra)
+	}
+	if !strings.Contains(refund.Body, "**Refund** a `payment_id`") {
+		t.Errorf("description not converted: %q", refund.Body)
+	}
+	if report.SkippedFields["Environment"] != 2 || report.SkippedFields["Story Points"] != 0 {
+		t.Errorf("unexpected skipped fields: %v", report.SkippedFields)
+	}
+
+	// Importing again only reports the mapped items
+	report, err = importer.Import(ctx, batch, services.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Imported) != 0 || len(report.Existing) != 4 || report.Existing[0].ID != refund.ID {
+		t.Errorf("unexpected re-import: %+v", report)
+	}
+	data, err := os.ReadFile(filepath.Join(repoPath, ".gitta", "import", "jira.csv"))
+	if err != nil || !strings.Contains(string(data), "SHOP-1,"+refund.ID) {
+		t.Errorf("unexpected mapping table %q: %v", data, err)
+	}
+}
+
+func TestImportService_Trello(t *testing.T) {
+	importer, repoPath := newImportService(t)
+	ctx := context.Background()
+	batch, err := services.ParseTrelloBoard(strings.NewReader(trelloExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Items) != 2 || len(batch.Skipped) != 2 {
+		t.Fatalf("unexpected batch: %+v", batch)
+	}
+
+	report, err := importer.Import(ctx, batch, services.ImportOptions{Prefix: "TR", Sprint: "backlog"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stories := importedByKey(report)
+	gift, wishlist := stories["abCD12"], stories["efGH34"]
+	if gift.Status != "todo" || gift.Location != "Backlog" || !strings.HasPrefix(gift.ID, "TR-") {
+		t.Errorf("unexpected story: %+v", gift)
+	}
+	if wishlist.Status != "doing" || wishlist.Location != "Backlog" {
+		t.Errorf("unexpected story: %+v", wishlist)
+	}
+
+	story := readImportedStory(t, gift.Path)
+	if strings.Join(story.Tags, ",") != "Payments-Team,red" || story.Assignee == nil || *story.Assignee != "bob" {
+		t.Errorf("unexpected story: %+v", story)
+	}
+	if !strings.Contains(story.Body, "Sell **gift** cards.") || !strings.Contains(story.Body, "## Tasks\n\n- [x] Design\n- [ ] Build") {
+		t.Errorf("unexpected body: %q", story.Body)
+	}
+	for _, name := range []string{"Due", "Members", "Attachments", "Comments"} {
+		if report.SkippedFields[name] != 1 {
+			t.Errorf("skipped fields = %v, want %s reported", report.SkippedFields, name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, ".gitta", "import", "trello.csv")); err != nil {
+		t.Errorf("mapping table not written: %v", err)
+	}
+
+	_, err = services.ParseTrelloBoard(strings.NewReader(`{"name": "x"}`))
+	if !errors.Is(err, services.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+}
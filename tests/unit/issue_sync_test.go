@@ -0,0 +1,429 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/infra/github"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// fakeIssue is an issue held by fakeGitHub, in the API's JSON shape.
+type fakeIssue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	HTMLURL   string    `json:"html_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignees []struct {
+		Login string `json:"login"`
+	} `json:"assignees"`
+	PullRequest map[string]string `json:"pull_request,omitempty"`
+}
+
+// fakeGitHub serves the GitHub Issues endpoints for one repository, three
+// issues per page.
+type fakeGitHub struct {
+	t      *testing.T
+	mu     sync.Mutex
+	issues map[int]*fakeIssue
+	next   int
+	clock  time.Time
+	writes int
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *httptest.Server) {
+	f := &fakeGitHub{t: t, issues: make(map[int]*fakeIssue), next: 1, clock: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+// add stores an issue as if created on GitHub.
+func (f *fakeGitHub) add(title, body, state string, labels []string, assignee string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	issue := &fakeIssue{Number: f.next, Title: title, Body: body, State: state}
+	f.setLabels(issue, labels)
+	f.setAssignees(issue, assignee)
+	f.touch(issue)
+	f.issues[issue.Number] = issue
+	f.next++
+	return issue.Number
+}
+
+// edit changes an issue as if edited on GitHub.
+func (f *fakeGitHub) edit(number int, change func(*fakeIssue)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	change(f.issues[number])
+	f.touch(f.issues[number])
+}
+
+func (f *fakeGitHub) get(number int) fakeIssue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.issues[number]
+}
+
+func (f *fakeGitHub) touch(issue *fakeIssue) {
+	f.clock = f.clock.Add(time.Minute)
+	issue.UpdatedAt = f.clock
+	issue.HTMLURL = fmt.Sprintf("https://github.example.com/acme/shop/issues/%d", issue.Number)
+}
+
+func (f *fakeGitHub) setLabels(issue *fakeIssue, labels []string) {
+	issue.Labels = nil
+	for _, name := range labels {
+		issue.Labels = append(issue.Labels, struct {
+			Name string `json:"name"`
+		}{name})
+	}
+}
+
+func (f *fakeGitHub) setAssignees(issue *fakeIssue, logins ...string) {
+	issue.Assignees = nil
+	for _, login := range logins {
+		if login != "" {
+			issue.Assignees = append(issue.Assignees, struct {
+				Login string `json:"login"`
+			}{login})
+		}
+	}
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Bad credentials"}`))
+		return
+	}
+	const prefix = "/api/v3/repos/acme/shop/issues"
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && path == prefix:
+		if r.URL.Query().Get("state") != "all" {
+			f.t.Errorf("issues listed without state=all: %s", r.URL)
+		}
+		numbers := make([]int, 0, len(f.issues))
+		for n := range f.issues {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		start, end := (page-1)*3, page*3
+		if end < len(numbers) {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?state=all&page=%d>; rel="next"`, r.Host, prefix, page+1))
+		} else {
+			end = len(numbers)
+		}
+		out := []*fakeIssue{}
+		for _, n := range numbers[min(start, len(numbers)):end] {
+			out = append(out, f.issues[n])
+		}
+		json.NewEncoder(w).Encode(out)
+	case r.Method == http.MethodPost && path == prefix:
+		f.writes++
+		var req struct {
+			Title     string   `json:"title"`
+			Body      string   `json:"body"`
+			Labels    []string `json:"labels"`
+			Assignees []string `json:"assignees"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		issue := &fakeIssue{Number: f.next, Title: req.Title, Body: req.Body, State: "open"}
+		f.setLabels(issue, req.Labels)
+		f.setAssignees(issue, req.Assignees...)
+		f.touch(issue)
+		f.issues[issue.Number] = issue
+		f.next++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(issue)
+	case r.Method == http.MethodPatch && strings.HasPrefix(path, prefix+"/"):
+		f.writes++
+		n, _ := strconv.Atoi(strings.TrimPrefix(path, prefix+"/"))
+		issue, ok := f.issues[n]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		var req struct {
+			Title     string   `json:"title"`
+			Body      string   `json:"body"`
+			Labels    []string `json:"labels"`
+			Assignees []string `json:"assignees"`
+			State     string   `json:"state"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		issue.Title, issue.Body, issue.State = req.Title, req.Body, req.State
+		f.setLabels(issue, req.Labels)
+		f.setAssignees(issue, req.Assignees...)
+		f.touch(issue)
+		json.NewEncoder(w).Encode(issue)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	}
+}
+
+func (i fakeIssue) labelNames() []string {
+	var names []string
+	for _, l := range i.Labels {
+		names = append(names, l.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newIssueSync syncs a copy of the testdata/site workspace with the fake.
+func newIssueSync(t *testing.T, server *httptest.Server, token string) (services.IssueSyncService, string) {
+	t.Helper()
+	repoPath := t.TempDir()
+	copyTree(t, filepath.Join("..", "..", "testdata", "site"), repoPath)
+	return issueSyncFor(server, token, repoPath), repoPath
+}
+
+func issueSyncFor(server *httptest.Server, token, repoPath string) services.IssueSyncService {
+	parser := filesystem.NewMarkdownParser()
+	repo := filesystem.NewRepository(parser)
+	workflow := core.DefaultWorkflow()
+	board := services.NewBoardService(repo, repo, noBranchesRepo{}, repoPath, workflow)
+	backlogPath := filepath.Join(repoPath, "tasks", "backlog")
+	edit := services.NewStoryEditService(parser, repo, board,
+		services.NewCreateService(filesystem.NewIDCounter(repoPath), parser, repo, backlogPath),
+		nil, repoPath, backlogPath, workflow)
+	client := github.NewClient(server.URL+"/api/v3/", "acme/shop", token)
+	return services.NewIssueSyncService(client, board, edit, nil, repoPath, workflow)
+}
+
+func readSyncStory(t *testing.T, repoPath, id string) *core.Story {
+	t.Helper()
+	story, _, err := filesystem.NewRepository(filesystem.NewMarkdownParser()).FindStoryByID(context.Background(), repoPath, id)
+	if err != nil {
+		t.Fatalf("find %s: %v", id, err)
+	}
+	return story
+}
+
+func issueOf(t *testing.T, story *core.Story) int {
+	t.Helper()
+	refs, ok := story.Extra["external"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("%s has no external links: %v", story.ID, story.Extra)
+	}
+	n, ok := refs["github"].(int)
+	if !ok {
+		t.Fatalf("%s has no github link: %v", story.ID, refs)
+	}
+	return n
+}
+
+func TestIssueSync_InitialSync(t *testing.T) {
+	fake, server := newFakeGitHub(t)
+	bug := fake.add("Crash on empty cart", "Steps:\r\n1. Open the cart\r\n", "open", []string{"bug", "good first issue"}, "dana")
+	closed := fake.add("Old request", "", "closed", nil, "")
+	sync, repoPath := newIssueSync(t, server, "secret")
+	ctx := context.Background()
+
+	// A dry run reports every action and changes nothing
+	report, err := sync.Sync(ctx, services.IssueSyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(services.SyncCreateIssue) != 8 || report.Count(services.SyncCreateStory) != 2 || fake.writes != 0 {
+		t.Fatalf("unexpected dry run: %+v (writes %d)", report.Changes, fake.writes)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, ".gitta", "sync")); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote sync state")
+	}
+
+	report, err = sync.Sync(ctx, services.IssueSyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(services.SyncCreateIssue) != 8 || report.Count(services.SyncCreateStory) != 2 {
+		t.Fatalf("unexpected report: %+v", report.Changes)
+	}
+
+	// Stories are linked to their new issues, which carry tags and state
+	us004 := readSyncStory(t, repoPath, "US-004")
+	issue := fake.get(issueOf(t, us004))
+	if issue.Title != "Checkout with credit card" || issue.State != "open" || strings.Join(issue.labelNames(), ",") != "api,payments" {
+		t.Errorf("unexpected issue for US-004: %+v", issue)
+	}
+	if len(issue.Assignees) != 1 || issue.Assignees[0].Login != "alice" {
+		t.Errorf("assignee not pushed: %+v", issue.Assignees)
+	}
+	us001 := readSyncStory(t, repoPath, "US-001")
+	if fake.get(issueOf(t, us001)).State != "closed" {
+		t.Errorf("the issue of a done story must be closed")
+	}
+
+	// Issues become backlog stories; labels that are not valid tags are dropped
+	var bugStory, closedStory *core.Story
+	for _, change := range report.Changes {
+		if change.Action != services.SyncCreateStory {
+			continue
+		}
+		switch change.Issue {
+		case bug:
+			bugStory = readSyncStory(t, repoPath, change.StoryID)
+		case closed:
+			closedStory = readSyncStory(t, repoPath, change.StoryID)
+		}
+	}
+	if bugStory == nil || closedStory == nil {
+		t.Fatalf("stories not created for issues: %+v", report.Changes)
+	}
+	if issueOf(t, bugStory) != bug || bugStory.Title != "Crash on empty cart" || bugStory.Body != "Steps:\n1. Open the cart\n" ||
+		strings.Join(bugStory.Tags, ",") != "bug" || bugStory.Assignee == nil || *bugStory.Assignee != "dana" {
+		t.Errorf("unexpected story for the bug: %+v", bugStory)
+	}
+	if closedStory.Status != core.StatusDone {
+		t.Errorf("closed issues become done stories, got %s", closedStory.Status)
+	}
+
+	// Everything is in sync now
+	writes := fake.writes
+	report, err = sync.Sync(ctx, services.IssueSyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 0 || report.Unchanged != 10 || fake.writes != writes {
+		t.Errorf("second sync changed something: %+v", report.Changes)
+	}
+}
+
+func TestIssueSync_PushPullAndConflicts(t *testing.T) {
+	fake, server := newFakeGitHub(t)
+	sync, repoPath := newIssueSync(t, server, "secret")
+	ctx := context.Background()
+	if _, err := sync.Sync(ctx, services.IssueSyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	us004 := issueOf(t, readSyncStory(t, repoPath, "US-004"))
+	us010 := issueOf(t, readSyncStory(t, repoPath, "US-010"))
+	us011 := issueOf(t, readSyncStory(t, repoPath, "US-011"))
+
+	// Remote edit: pulled
+	fake.edit(us004, func(i *fakeIssue) {
+		i.Title = "Pay by credit card"
+		i.State = "closed"
+		fake.setLabels(i, []string{"payments", "wontfix forever"})
+	})
+	// Local edit by hand (updated_at untouched): pushed
+	path := filepath.Join(repoPath, "tasks", "backlog", "US-010.md")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), "\n---\n", "\n---\n\nMore context.\n", 1)), 0644)
+	fake.edit(us010, func(i *fakeIssue) { fake.setLabels(i, append(i.labelNames(), "good first issue")) }) // not a tag
+	// Both sides: conflict
+	parser := filesystem.NewMarkdownParser()
+	story := readSyncStory(t, repoPath, "US-011")
+	story.Title = "Local title"
+	storyPath := filepath.Join(repoPath, "tasks", "backlog", "US-011.md")
+	if err := parser.WriteStory(ctx, storyPath, story); err != nil {
+		t.Fatal(err)
+	}
+	fake.edit(us011, func(i *fakeIssue) { i.Title = "Remote title" })
+
+	report, err := sync.Sync(ctx, services.IssueSyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := map[string]services.IssueSyncChange{}
+	for _, change := range report.Changes {
+		actions[change.StoryID] = change
+	}
+	if c := actions["US-004"]; c.Action != services.SyncPull || strings.Join(c.Fields, ",") != "title,labels,state" {
+		t.Errorf("US-004: %+v", c)
+	}
+	if c := actions["US-010"]; c.Action != services.SyncPush || strings.Join(c.Fields, ",") != "body" {
+		t.Errorf("US-010: %+v", c)
+	}
+	if c := actions["US-011"]; c.Action != services.SyncConflict || !strings.Contains(c.Detail, "both sides") {
+		t.Errorf("US-011: %+v", c)
+	}
+
+	pulled := readSyncStory(t, repoPath, "US-004")
+	if pulled.Title != "Pay by credit card" || pulled.Status != core.StatusDone || strings.Join(pulled.Tags, ",") != "payments" {
+		t.Errorf("issue changes not pulled: %+v", pulled)
+	}
+	pushed := fake.get(us010)
+	if !strings.Contains(pushed.Body, "More context.") || strings.Join(pushed.labelNames(), ",") != "good first issue,payments" {
+		t.Errorf("story changes not pushed, or labels lost: %+v", pushed)
+	}
+	if readSyncStory(t, repoPath, "US-011").Title != "Local title" || fake.get(us011).Title != "Remote title" {
+		t.Errorf("conflicts must be left alone")
+	}
+
+	// Conflicts are resolved on request
+	report, err = sync.Sync(ctx, services.IssueSyncOptions{Prefer: "remote"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(services.SyncPull) != 1 || readSyncStory(t, repoPath, "US-011").Title != "Remote title" {
+		t.Errorf("conflict not resolved with the remote side: %+v", report.Changes)
+	}
+	if report, _ := sync.Sync(ctx, services.IssueSyncOptions{}); len(report.Changes) != 0 {
+		t.Errorf("sync not settled: %+v", report.Changes)
+	}
+}
+
+func TestIssueSync_Errors(t *testing.T) {
+	fake, server := newFakeGitHub(t)
+	ctx := context.Background()
+
+	sync, _ := newIssueSync(t, server, "wrong")
+	if _, err := sync.Sync(ctx, services.IssueSyncOptions{}); !errors.Is(err, github.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+
+	sync, repoPath := newIssueSync(t, server, "secret")
+	if _, err := sync.Sync(ctx, services.IssueSyncOptions{Prefer: "newest"}); !errors.Is(err, services.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an unknown preference, got %v", err)
+	}
+
+	// Links to issues the repository does not have are reported, not broken
+	if _, err := sync.Sync(ctx, services.IssueSyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	number := issueOf(t, readSyncStory(t, repoPath, "US-012"))
+	fake.mu.Lock()
+	delete(fake.issues, number)
+	fake.mu.Unlock()
+	report, err := sync.Sync(ctx, services.IssueSyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(services.SyncMissing) != 1 || report.Changes[0].StoryID != "US-012" {
+		t.Errorf("missing issue not reported: %+v", report.Changes)
+	}
+	if issueOf(t, readSyncStory(t, repoPath, "US-012")) != number {
+		t.Errorf("link to a missing issue must be kept")
+	}
+}
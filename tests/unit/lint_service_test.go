@@ -0,0 +1,222 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/services"
+)
+
+func writeLintFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestLintStories(t *testing.T) {
+	repoPath := t.TempDir()
+	backlog := filepath.Join(repoPath, "tasks", "backlog")
+	sprint := filepath.Join(repoPath, "tasks", "sprints", "Sprint-01")
+	if err := os.MkdirAll(filepath.Join(sprint, ".gitta"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	writeLintFile(t, filepath.Join(backlog, "US-001.md"), "---\nid: US-001\ntitle: Valid\n---\n\nBody\n")
+	writeLintFile(t, filepath.Join(backlog, "US-002.md"), "---\nid: US-002\ntitle: Bad priority\npriority: urgent\n---\n")
+	writeLintFile(t, filepath.Join(backlog, "US-003.md"), "---\nid: US-003\ntitle: Broken\n  bad: : yaml\n---\n")
+	writeLintFile(t, filepath.Join(backlog, "US-004.md"), "---\nid: US-004\ntitle: Bad date\ncreated_at: yesterday\n---\n")
+	writeLintFile(t, filepath.Join(backlog, "US-005.md"), "---\nid: US-005\ntitle: Bad tags\npriority: low\ntags: 5\n---\n")
+	writeLintFile(t, filepath.Join(backlog, "notes.md"), "No frontmatter here\n")
+	writeLintFile(t, filepath.Join(sprint, "US-001.md"), "---\nid: US-001\ntitle: Duplicate\n---\n")
+
+	lint := services.NewLintService(filesystem.NewMarkdownParser(), repoPath)
+	report, err := lint.LintStories(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("LintStories() error = %v", err)
+	}
+	if report.FilesChecked != 7 {
+		t.Errorf("FilesChecked = %d, want 7", report.FilesChecked)
+	}
+
+	tests := []struct {
+		file string
+		line int
+		rule string
+	}{
+		{"tasks/backlog/US-001.md", 2, services.LintRuleDuplicateID},
+		{"tasks/backlog/US-002.md", 4, "priority/enum"},
+		{"tasks/backlog/US-003.md", 4, services.LintRuleYAML},
+		{"tasks/backlog/US-004.md", 4, services.LintRuleParse},
+		{"tasks/backlog/US-005.md", 5, services.LintRuleParse},
+		{"tasks/backlog/notes.md", 1, services.LintRuleFrontmatter},
+		{"tasks/sprints/Sprint-01/US-001.md", 2, services.LintRuleDuplicateID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+tt.rule, func(t *testing.T) {
+			for _, d := range report.Diagnostics {
+				if d.File == tt.file && d.Rule == tt.rule {
+					if d.Line != tt.line {
+						t.Errorf("line = %d, want %d", d.Line, tt.line)
+					}
+					return
+				}
+			}
+			t.Errorf("missing %s diagnostic for %s in %+v", tt.rule, tt.file, report.Diagnostics)
+		})
+	}
+
+	if report.ErrorCount() != len(tests) {
+		t.Errorf("ErrorCount() = %d, want %d", report.ErrorCount(), len(tests))
+	}
+}
+
+func TestLintStories_ExplicitPaths(t *testing.T) {
+	repoPath := t.TempDir()
+	file := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	writeLintFile(t, file, "---\nid: US-001\ntitle: Valid\n---\n")
+
+	lint := services.NewLintService(filesystem.NewMarkdownParser(), repoPath)
+	report, err := lint.LintStories(context.Background(), []string{file})
+	if err != nil {
+		t.Fatalf("LintStories() error = %v", err)
+	}
+	if report.FilesChecked != 1 || len(report.Diagnostics) != 0 {
+		t.Errorf("report = %+v, want 1 clean file", report)
+	}
+
+	if _, err := lint.LintStories(context.Background(), []string{filepath.Join(repoPath, "missing.md")}); err == nil {
+		t.Error("expected error for missing explicit path")
+	}
+}
+
+func TestFormatStories(t *testing.T) {
+	repoPath := t.TempDir()
+	file := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	original := "---\r\ntitle: Messy\r\nid: US-001\r\ntags: [API, api, Backend]\r\ncreated_at: 2025-01-02T10:00:00+08:00\r\nestimate: 3\r\n---\r\n\r\nBody\r\n\r\n"
+	writeLintFile(t, file, original)
+
+	format := services.NewFormatService(filesystem.NewMarkdownParser(), repoPath)
+	ctx := context.Background()
+
+	results, err := format.FormatStories(ctx, nil, false)
+	if err != nil {
+		t.Fatalf("FormatStories() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Changed || results[0].Err != nil {
+		t.Fatalf("check results = %+v, want one changed file", results)
+	}
+	if data, _ := os.ReadFile(file); string(data) != original {
+		t.Fatal("check mode must not modify the file")
+	}
+
+	// The missing status stays implicit so it keeps being derived from Git.
+	want := "---\nid: US-001\ntitle: Messy\npriority: medium\ncreated_at: 2025-01-02T02:00:00Z\ntags:\n    - api\n    - backend\nestimate: 3\n---\n\nBody\n"
+	if results[0].Formatted != want {
+		t.Errorf("Formatted =\n%q\nwant\n%q", results[0].Formatted, want)
+	}
+
+	if _, err := format.FormatStories(ctx, nil, true); err != nil {
+		t.Fatalf("FormatStories(write) error = %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != want {
+		t.Errorf("written file =\n%q\nwant\n%q", string(data), want)
+	}
+
+	// Formatting is idempotent.
+	results, _ = format.FormatStories(ctx, nil, false)
+	if results[0].Changed {
+		t.Error("second format run should report no changes")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(file))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			t.Errorf("scratch file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestFormatStories_DateOnlyTimestamps(t *testing.T) {
+	repoPath := t.TempDir()
+	file := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	writeLintFile(t, file, "---\nid: US-001\ntitle: Dates\npriority: medium\ncreated_at: 2024-01-05\nupdated_at: \"2024-02-01\"\n---\n\nBody\n")
+
+	format := services.NewFormatService(filesystem.NewMarkdownParser(), repoPath)
+	results, err := format.FormatStories(context.Background(), nil, true)
+	if err != nil || len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("FormatStories() = %+v, %v", results, err)
+	}
+	want := "---\nid: US-001\ntitle: Dates\npriority: medium\ncreated_at: 2024-01-05T00:00:00Z\nupdated_at: 2024-02-01T00:00:00Z\n---\n\nBody\n"
+	if data, _ := os.ReadFile(file); string(data) != want {
+		t.Errorf("written file =\n%q\nwant\n%q", data, want)
+	}
+}
+
+func TestFormatStories_InitOutputIsCanonical(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoPath, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := services.NewInitService().Initialize(ctx, repoPath, services.InitOptions{ExampleSprint: "Sprint-01"}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	format := services.NewFormatService(filesystem.NewMarkdownParser(), repoPath)
+	results, err := format.FormatStories(ctx, nil, false)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("FormatStories() = %+v, %v", results, err)
+	}
+	for _, result := range results {
+		if result.Err != nil || result.Changed {
+			t.Errorf("%s is not canonical (%v):\n%s", result.File, result.Err, result.Formatted)
+		}
+	}
+}
+
+func TestFormatStories_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	file := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	writeLintFile(t, file, "---\nstatus: todo\ntitle: No body\nid: US-001\n---\n\n")
+
+	format := services.NewFormatService(filesystem.NewMarkdownParser(), repoPath)
+	if _, err := format.FormatStories(ctx, nil, true); err != nil {
+		t.Fatalf("FormatStories() error = %v", err)
+	}
+	first, _ := os.ReadFile(file)
+	if strings.HasSuffix(string(first), "---\n\n") {
+		t.Errorf("empty body written with a trailing blank line:\n%q", first)
+	}
+	results, err := format.FormatStories(ctx, nil, true)
+	if err != nil || len(results) != 1 || results[0].Err != nil || results[0].Changed {
+		t.Errorf("second FormatStories() = %+v, %v, want no change", results, err)
+	}
+	if second, _ := os.ReadFile(file); string(second) != string(first) {
+		t.Errorf("second run changed the file:\n%q\nthen\n%q", first, second)
+	}
+}
+
+func TestFormatStories_InvalidStory(t *testing.T) {
+	repoPath := t.TempDir()
+	file := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	writeLintFile(t, file, "---\nid: bad\ntitle: Invalid\n---\n")
+
+	format := services.NewFormatService(filesystem.NewMarkdownParser(), repoPath)
+	results, err := format.FormatStories(context.Background(), nil, true)
+	if err != nil {
+		t.Fatalf("FormatStories() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("results = %+v, want a per-file error", results)
+	}
+}
@@ -0,0 +1,374 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/ui/lsp"
+)
+
+// lspMessage is any JSON-RPC message received from the server.
+type lspMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// lspClient is an in-process JSON-RPC client connected to an lsp.Server.
+type lspClient struct {
+	t        *testing.T
+	out      io.WriteCloser
+	messages chan lspMessage
+	pending  []lspMessage // notifications received while waiting for responses
+	nextID   int
+	done     chan error
+}
+
+func newLSPClient(t *testing.T, cfg lsp.Config) *lspClient {
+	t.Helper()
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	c := &lspClient{t: t, out: clientW, messages: make(chan lspMessage, 100), done: make(chan error, 1)}
+
+	go func() {
+		err := lsp.NewServer(cfg).Serve(context.Background(), serverR, serverW)
+		serverW.Close()
+		c.done <- err
+	}()
+	go func() {
+		defer close(c.messages)
+		reader := textproto.NewReader(bufio.NewReader(clientR))
+		for {
+			header, err := reader.ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			length, _ := strconv.Atoi(header.Get("Content-Length"))
+			body := make([]byte, length)
+			if _, err := io.ReadFull(reader.R, body); err != nil {
+				return
+			}
+			var msg lspMessage
+			if err := json.Unmarshal(body, &msg); err != nil {
+				t.Errorf("invalid message from server: %s", body)
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	t.Cleanup(func() { clientW.Close() })
+	return c
+}
+
+func (c *lspClient) send(v interface{}) {
+	c.t.Helper()
+	body, _ := json.Marshal(v)
+	if _, err := fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatalf("failed to send message: %v", err)
+	}
+}
+
+func (c *lspClient) receive() lspMessage {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("server closed the connection")
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for the server")
+	}
+	return lspMessage{}
+}
+
+// call sends a request and decodes its result into result.
+func (c *lspClient) call(method string, params, result interface{}) {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	for {
+		msg := c.receive()
+		if msg.ID == nil {
+			c.pending = append(c.pending, msg)
+			continue
+		}
+		if *msg.ID != c.nextID {
+			c.t.Fatalf("response to request %d, want %d", *msg.ID, c.nextID)
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("%s failed: %s", method, msg.Error.Message)
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatalf("invalid %s result %s: %v", method, msg.Result, err)
+			}
+		}
+		return
+	}
+}
+
+func (c *lspClient) notify(method string, params interface{}) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// diagnostics waits for the next diagnostics published for uri.
+func (c *lspClient) diagnostics(uri string) []lsp.Diagnostic {
+	c.t.Helper()
+	for {
+		var msg lspMessage
+		if len(c.pending) > 0 {
+			msg, c.pending = c.pending[0], c.pending[1:]
+		} else {
+			msg = c.receive()
+		}
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params lsp.PublishDiagnosticsParams
+		json.Unmarshal(msg.Params, &params)
+		if params.URI == uri {
+			return params.Diagnostics
+		}
+	}
+}
+
+func (c *lspClient) open(uri, text string) {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "markdown", "version": 1, "text": text},
+	})
+}
+
+func (c *lspClient) position(uri string, line, char int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": line, "character": char},
+	}
+}
+
+// startLSP starts a language server on the testdata/site workspace.
+func startLSP(t *testing.T) (*lspClient, string) {
+	t.Helper()
+	repoPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "site"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := filesystem.NewMarkdownParser()
+	repo := filesystem.NewRepository(parser)
+	workflow := core.DefaultWorkflow()
+	client := newLSPClient(t, lsp.Config{
+		RepoPath:  repoPath,
+		StoryDirs: []string{filepath.Join(repoPath, "tasks", "backlog"), filepath.Join(repoPath, "tasks", "sprints")},
+		Workflow:  workflow,
+		Fields:    []core.FieldDefinition{{Name: "epic", Type: core.FieldTypeEnum, Values: []string{"checkout", "login"}}},
+		Lint:      services.NewLintService(parser, repoPath),
+		Stories:   repo,
+		Board:     services.NewBoardService(repo, repo, noBranchesRepo{}, repoPath, workflow),
+	})
+
+	var result lsp.InitializeResult
+	client.call("initialize", map[string]interface{}{"processId": nil, "rootUri": nil, "capabilities": map[string]interface{}{}}, &result)
+	if !result.Capabilities.HoverProvider || !result.Capabilities.DefinitionProvider || result.Capabilities.TextDocumentSync.Change != lsp.TextDocumentSyncKindFull {
+		t.Fatalf("unexpected capabilities: %+v", result.Capabilities)
+	}
+	client.notify("initialized", map[string]interface{}{})
+	return client, repoPath
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func TestLSP_ShutdownAndExit(t *testing.T) {
+	client, _ := startLSP(t)
+
+	client.call("shutdown", nil, nil)
+	client.notify("exit", nil)
+	select {
+	case err := <-client.done:
+		if err != nil {
+			t.Errorf("Serve returned %v after shutdown and exit", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not exit")
+	}
+
+	client, _ = startLSP(t)
+	client.notify("exit", nil)
+	if err := <-client.done; err != lsp.ErrExitWithoutShutdown {
+		t.Errorf("exit without shutdown returned %v", err)
+	}
+}
+
+func TestLSP_Diagnostics(t *testing.T) {
+	client, repoPath := startLSP(t)
+	uri := fileURI(filepath.Join(repoPath, "tasks", "backlog", "US-010.md"))
+
+	client.open(uri, "---\nid: US-010\ntitle: Save cards\npriority: urgent\nstatus: todo\n---\n\nBody\n")
+	diags := client.diagnostics(uri)
+	if len(diags) != 1 {
+		t.Fatalf("expected one diagnostic, got %+v", diags)
+	}
+	d := diags[0]
+	if d.Range.Start.Line != 3 || d.Range.End.Character != len("priority: urgent") || d.Severity != lsp.SeverityError || d.Source != "gitta" || d.Code != "priority/enum" {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+
+	// Parse errors are located by line
+	client.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]interface{}{{"text": "---\nid: US-010\ntitle: [Save\n---\n"}},
+	})
+	diags = client.diagnostics(uri)
+	if len(diags) != 1 || diags[0].Code != services.LintRuleYAML || diags[0].Range.Start.Line < 1 {
+		t.Errorf("expected a located YAML diagnostic, got %+v", diags)
+	}
+
+	client.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 3},
+		"contentChanges": []map[string]interface{}{{"text": "---\nid: US-010\ntitle: Save cards\n---\n"}},
+	})
+	if diags := client.diagnostics(uri); len(diags) != 0 {
+		t.Errorf("expected diagnostics to be cleared, got %+v", diags)
+	}
+
+	// Documents outside the story directories are not linted
+	readme := fileURI(filepath.Join(repoPath, "README.md"))
+	client.open(readme, "# Not a story\n")
+	client.call("textDocument/hover", client.position(readme, 0, 0), nil)
+	for _, msg := range client.pending {
+		if strings.Contains(string(msg.Params), "README.md") {
+			t.Errorf("README.md must not be linted: %s", msg.Params)
+		}
+	}
+}
+
+func TestLSP_Completion(t *testing.T) {
+	client, repoPath := startLSP(t)
+	uri := fileURI(filepath.Join(repoPath, "tasks", "backlog", "US-010.md"))
+	text := strings.Join([]string{
+		"---",
+		"id: US-010",
+		"title: Save cards",
+		"status: ",
+		"priority: h",
+		"assignee: ",
+		"tags: [payments, a",
+		"epic: ",
+		"depends_on:",
+		"  - US-00",
+		"---",
+		"status: ",
+	}, "\n")
+	client.open(uri, text)
+
+	complete := func(line, char int) lsp.CompletionList {
+		var list lsp.CompletionList
+		client.call("textDocument/completion", client.position(uri, line, char), &list)
+		return list
+	}
+	labels := func(list lsp.CompletionList) string {
+		var out []string
+		for _, item := range list.Items {
+			out = append(out, item.Label)
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := labels(complete(3, 8)); got != "todo,doing,review,done" {
+		t.Errorf("status completion = %s", got)
+	}
+	priority := complete(4, 11)
+	if got := labels(priority); got != "low,medium,high,critical" {
+		t.Errorf("priority completion = %s", got)
+	}
+	if edit := priority.Items[0].TextEdit; edit == nil || edit.Range.Start.Character != 10 || edit.Range.End.Character != 11 {
+		t.Errorf("priority completion must replace the typed value, got %+v", edit)
+	}
+	if got := labels(complete(5, 10)); got != "alice,bob,carol" {
+		t.Errorf("assignee completion = %s", got)
+	}
+	tags := complete(6, 18)
+	if got := labels(tags); !strings.Contains(got, "api") || !strings.Contains(got, "payments") {
+		t.Errorf("tags completion = %s", got)
+	}
+	if edit := tags.Items[0].TextEdit; edit.Range.Start.Character != 17 {
+		t.Errorf("flow sequence completion must replace the current item, got %+v", edit)
+	}
+	if got := labels(complete(7, 6)); got != "checkout,login" {
+		t.Errorf("enum field completion = %s", got)
+	}
+
+	deps := complete(9, 9)
+	if got := labels(deps); !strings.HasPrefix(got, "US-001,US-002,US-004") || strings.Contains(got, "US-010") {
+		t.Errorf("dependency completion = %s", got)
+	}
+	for _, item := range deps.Items {
+		if item.Label == "US-004" && (item.Detail != "Checkout with credit card" || item.Kind != lsp.CompletionKindReference) {
+			t.Errorf("unexpected US-004 item: %+v", item)
+		}
+	}
+
+	if got := complete(11, 8); len(got.Items) != 0 {
+		t.Errorf("body lines must not be completed, got %s", labels(got))
+	}
+}
+
+func TestLSP_HoverAndDefinition(t *testing.T) {
+	client, repoPath := startLSP(t)
+	uri := fileURI(filepath.Join(repoPath, "tasks", "backlog", "US-010.md"))
+	client.open(uri, "---\nid: US-010\ntitle: Save cards\ndepends_on: [US-004]\n---\n\nBlocked by US-004 and US-999.\n")
+
+	var hover *lsp.Hover
+	client.call("textDocument/hover", client.position(uri, 6, 13), &hover)
+	if hover == nil {
+		t.Fatal("expected a hover for US-004")
+	}
+	if !strings.Contains(hover.Contents.Value, "**US-004** Checkout with credit card") ||
+		!strings.Contains(hover.Contents.Value, "Status: doing") ||
+		!strings.Contains(hover.Contents.Value, "Sprint-02_Checkout") {
+		t.Errorf("unexpected hover: %s", hover.Contents.Value)
+	}
+	if hover.Range == nil || hover.Range.Start.Character != 11 || hover.Range.End.Character != 17 {
+		t.Errorf("unexpected hover range: %+v", hover.Range)
+	}
+
+	var missing *lsp.Hover
+	client.call("textDocument/hover", client.position(uri, 6, 24), &missing)
+	if missing != nil {
+		t.Errorf("expected no hover for an unknown story, got %+v", missing)
+	}
+
+	var location *lsp.Location
+	client.call("textDocument/definition", client.position(uri, 3, 15), &location)
+	want := fileURI(filepath.Join(repoPath, "tasks", "sprints", "!Sprint-02_Checkout", "US-004.md"))
+	if location == nil || location.URI != want {
+		t.Errorf("definition = %+v, want %s", location, want)
+	}
+
+	var none *lsp.Location
+	client.call("textDocument/definition", client.position(uri, 6, 24), &none)
+	if none != nil {
+		t.Errorf("expected no definition for an unknown story, got %+v", none)
+	}
+}
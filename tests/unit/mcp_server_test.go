@@ -0,0 +1,412 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	gittagit "github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/ui/mcp"
+)
+
+// mcpResponse is a JSON-RPC response from the MCP server.
+type mcpResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// mcpToolResult is the result of tools/call.
+type mcpToolResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent"`
+	IsError           bool            `json:"isError"`
+}
+
+// mcpTestConfig serves a copy of the testdata/site workspace. With withGit,
+// the copy is a Git repository so stories can be started.
+func mcpTestConfig(t *testing.T, withGit bool) (mcp.Config, string) {
+	t.Helper()
+	repoPath := t.TempDir()
+	copyTree(t, filepath.Join("..", "..", "testdata", "site"), repoPath)
+	if withGit {
+		repo, err := git.PlainInit(repoPath, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wt, _ := repo.Worktree()
+		wt.Add(".")
+		if _, err := wt.Commit("init", &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parser := filesystem.NewMarkdownParser()
+	repo := filesystem.NewRepository(parser)
+	workflow := core.DefaultWorkflow()
+	board := services.NewBoardService(repo, repo, noBranchesRepo{}, repoPath, workflow)
+	backlogPath := filepath.Join(repoPath, "tasks", "backlog")
+	return mcp.Config{
+		RepoPath: repoPath,
+		Workflow: workflow,
+		Stories:  repo,
+		Board:    board,
+		Edit: services.NewStoryEditService(parser, repo, board,
+			services.NewCreateService(filesystem.NewIDCounter(repoPath), parser, repo, backlogPath),
+			nil, repoPath, backlogPath, workflow),
+		Start:   services.NewStartService(repo, gittagit.NewRepository(), parser),
+		Version: "test",
+	}, repoPath
+}
+
+// runMCP plays a scripted client session: it sends each message (a string is
+// sent as is) on its own line and returns the server's responses in order.
+func runMCP(t *testing.T, cfg mcp.Config, messages ...interface{}) []mcpResponse {
+	t.Helper()
+	var in bytes.Buffer
+	for _, msg := range messages {
+		if raw, ok := msg.(string); ok {
+			in.WriteString(raw)
+		} else {
+			data, _ := json.Marshal(msg)
+			in.Write(data)
+		}
+		in.WriteByte('\n')
+	}
+
+	var out bytes.Buffer
+	if err := mcp.NewServer(cfg).Serve(context.Background(), &in, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	var responses []mcpResponse
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp mcpResponse
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("server wrote an invalid message: %q", line)
+		}
+		if resp.JSONRPC != "2.0" {
+			t.Errorf("response without jsonrpc 2.0: %s", line)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func mcpRequest(id interface{}, method string, params interface{}) map[string]interface{} {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method}
+	if params != nil {
+		msg["params"] = params
+	}
+	return msg
+}
+
+var mcpInitialize = mcpRequest(0, "initialize", map[string]interface{}{
+	"protocolVersion": "2025-06-18",
+	"capabilities":    map[string]interface{}{},
+	"clientInfo":      map[string]interface{}{"name": "test", "version": "1.0"},
+})
+
+var mcpInitialized = map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/initialized"}
+
+func mcpCall(id int, tool string, args interface{}) map[string]interface{} {
+	return mcpRequest(id, "tools/call", map[string]interface{}{"name": tool, "arguments": args})
+}
+
+// toolResult decodes a tools/call response.
+func toolResult(t *testing.T, resp mcpResponse) mcpToolResult {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("tools/call failed: %s", resp.Error.Message)
+	}
+	var result mcpToolResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("invalid tool result %s: %v", resp.Result, err)
+	}
+	if len(result.Content) != 1 || result.Content[0].Type != "text" {
+		t.Fatalf("tool result must have one text block: %s", resp.Result)
+	}
+	return result
+}
+
+func TestMCP_Initialize(t *testing.T) {
+	cfg, _ := mcpTestConfig(t, false)
+
+	responses := runMCP(t, cfg, mcpInitialize, mcpInitialized, mcpRequest("ping-1", "ping", nil))
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses (the notification has none), got %d", len(responses))
+	}
+	var result mcp.InitializeResult
+	if err := json.Unmarshal(responses[0].Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ProtocolVersion != "2025-06-18" || result.ServerInfo.Name != "gitta" || result.ServerInfo.Version != "test" {
+		t.Errorf("unexpected initialize result: %+v", result)
+	}
+	if result.Capabilities.Tools == nil || result.Capabilities.Resources == nil {
+		t.Errorf("tools and resources capabilities must be declared: %s", responses[0].Result)
+	}
+	if string(responses[1].ID) != `"ping-1"` || string(responses[1].Result) != "{}" {
+		t.Errorf("ping must echo string IDs and return an empty result, got %s %s", responses[1].ID, responses[1].Result)
+	}
+
+	// Unsupported versions are answered with the latest supported one
+	responses = runMCP(t, cfg, mcpRequest(1, "initialize", map[string]interface{}{"protocolVersion": "1999-01-01", "capabilities": map[string]interface{}{}}))
+	json.Unmarshal(responses[0].Result, &result)
+	if result.ProtocolVersion != "2025-06-18" {
+		t.Errorf("negotiated %q for an unsupported version", result.ProtocolVersion)
+	}
+	responses = runMCP(t, cfg, mcpRequest(1, "initialize", map[string]interface{}{"protocolVersion": "2024-11-05", "capabilities": map[string]interface{}{}}))
+	json.Unmarshal(responses[0].Result, &result)
+	if result.ProtocolVersion != "2024-11-05" {
+		t.Errorf("negotiated %q for a supported older version", result.ProtocolVersion)
+	}
+}
+
+func TestMCP_ProtocolErrors(t *testing.T) {
+	cfg, _ := mcpTestConfig(t, false)
+
+	responses := runMCP(t, cfg,
+		mcpRequest(1, "tools/list", nil),
+		mcpRequest(2, "ping", nil),
+		mcpInitialize,
+		`{"jsonrpc":"2.0","id":3,"method":`,
+		`[1, 2]`,
+		`{"jsonrpc":"1.0","id":4,"method":"ping"}`,
+		mcpRequest(5, "prompts/list", nil),
+		mcpCall(6, "no_such_tool", nil),
+		mcpRequest(7, "tools/call", "not an object"),
+	)
+
+	want := []struct {
+		id   string
+		code int
+	}{
+		{"1", -32600}, // before initialize
+		{"2", 0},      // ping is allowed before initialize
+		{"0", 0},
+		{"null", -32700}, // unparsable
+		{"null", -32600}, // valid JSON, not a request
+		{"4", -32600},    // wrong protocol version
+		{"5", -32601},
+		{"6", -32602},
+		{"7", -32602},
+	}
+	if len(responses) != len(want) {
+		t.Fatalf("expected %d responses, got %d", len(want), len(responses))
+	}
+	for i, w := range want {
+		resp := responses[i]
+		code := 0
+		if resp.Error != nil {
+			code = resp.Error.Code
+			if resp.Result != nil {
+				t.Errorf("response %d has both a result and an error", i)
+			}
+		}
+		if string(resp.ID) != w.id || code != w.code {
+			t.Errorf("response %d: id %s code %d, want id %s code %d", i, resp.ID, code, w.id, w.code)
+		}
+	}
+}
+
+func TestMCP_ListTools(t *testing.T) {
+	cfg, _ := mcpTestConfig(t, false)
+	responses := runMCP(t, cfg, mcpInitialize, mcpRequest(1, "tools/list", nil))
+
+	var result mcp.ListToolsResult
+	if err := json.Unmarshal(responses[1].Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+		if tool.InputSchema["type"] != "object" || tool.Description == "" {
+			t.Errorf("tool %s needs an object input schema and a description", tool.Name)
+		}
+	}
+	// The burndown tool is only listed when a burndown service is configured
+	want := "list_stories,search_stories,get_story,list_sprints,create_story,update_story,move_story,start_story"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("tools = %s, want %s", got, want)
+	}
+}
+
+func TestMCP_StoryTools(t *testing.T) {
+	cfg, repoPath := mcpTestConfig(t, false)
+	responses := runMCP(t, cfg, mcpInitialize,
+		mcpCall(1, "list_stories", map[string]interface{}{"sprint": "current", "status": "doing"}),
+		mcpCall(2, "search_stories", map[string]interface{}{"query": "PAYMENT PROVIDER"}),
+		mcpCall(3, "get_story", map[string]interface{}{"id": "US-004"}),
+		mcpCall(4, "update_story", map[string]interface{}{"id": "US-010", "status": "done"}),
+		mcpCall(5, "update_story", map[string]interface{}{"id": "US-010", "status": "doing", "assignee": "dana", "fields": map[string]interface{}{"points": 3}}),
+		mcpCall(6, "move_story", map[string]interface{}{"id": "US-010", "to": "current"}),
+		mcpCall(7, "create_story", map[string]interface{}{"title": "Refund orders", "priority": "high", "body": "## Description\n\nRefunds.\n"}),
+		mcpCall(8, "get_story", map[string]interface{}{"id": "US-999"}),
+		mcpCall(9, "list_stories", map[string]interface{}{"state": "doing"}),
+		mcpCall(10, "list_sprints", nil),
+	)
+
+	var list struct {
+		Stories []struct {
+			ID string `json:"id"`
+		} `json:"stories"`
+	}
+	json.Unmarshal(toolResult(t, responses[1]).StructuredContent, &list)
+	if len(list.Stories) != 1 || list.Stories[0].ID != "US-004" {
+		t.Errorf("list_stories = %+v", list)
+	}
+	json.Unmarshal(toolResult(t, responses[2]).StructuredContent, &list)
+	if len(list.Stories) != 1 || list.Stories[0].ID != "US-004" {
+		t.Errorf("search_stories = %+v", list)
+	}
+
+	var story struct {
+		ID       string                 `json:"id"`
+		Status   string                 `json:"status"`
+		Assignee *string                `json:"assignee"`
+		Fields   map[string]interface{} `json:"fields"`
+		Location string                 `json:"location"`
+		File     string                 `json:"file"`
+		Body     string                 `json:"body"`
+	}
+	get := toolResult(t, responses[3])
+	json.Unmarshal(get.StructuredContent, &story)
+	if story.File != "tasks/sprints/!Sprint-02_Checkout/US-004.md" || !strings.Contains(story.Body, "## Acceptance Criteria") {
+		t.Errorf("get_story = %+v", story)
+	}
+	// The text block carries the same JSON for clients without structured content
+	if !json.Valid([]byte(get.Content[0].Text)) || !strings.Contains(get.Content[0].Text, `"id":"US-004"`) {
+		t.Errorf("text content must mirror the structured content: %s", get.Content[0].Text)
+	}
+
+	if rejected := toolResult(t, responses[4]); !rejected.IsError || !strings.Contains(rejected.Content[0].Text, "transition") {
+		t.Errorf("invalid transitions must be tool errors: %+v", rejected)
+	}
+	updated := toolResult(t, responses[5])
+	json.Unmarshal(updated.StructuredContent, &story)
+	if updated.IsError || story.Status != "doing" || story.Assignee == nil || *story.Assignee != "dana" || story.Fields["points"] != float64(3) {
+		t.Errorf("update_story = %s", updated.Content[0].Text)
+	}
+	json.Unmarshal(toolResult(t, responses[6]).StructuredContent, &story)
+	if story.Location != "!Sprint-02_Checkout" {
+		t.Errorf("move_story left the story in %s", story.Location)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "tasks", "sprints", "!Sprint-02_Checkout", "US-010.md")); err != nil {
+		t.Errorf("story file not moved: %v", err)
+	}
+
+	created := toolResult(t, responses[7])
+	json.Unmarshal(created.StructuredContent, &story)
+	if created.IsError || story.ID == "" || story.Location != "Backlog" || story.Body != "## Description\n\nRefunds.\n" {
+		t.Errorf("create_story = %s", created.Content[0].Text)
+	}
+
+	if missing := toolResult(t, responses[8]); !missing.IsError || !strings.Contains(missing.Content[0].Text, "US-999") {
+		t.Errorf("unknown stories must be tool errors: %+v", missing)
+	}
+	if invalid := toolResult(t, responses[9]); !invalid.IsError || !strings.Contains(invalid.Content[0].Text, "state") {
+		t.Errorf("unknown arguments must be tool errors: %+v", invalid)
+	}
+
+	var sprints struct {
+		Sprints []struct {
+			Title  string `json:"title"`
+			Status string `json:"status"`
+		} `json:"sprints"`
+	}
+	json.Unmarshal(toolResult(t, responses[10]).StructuredContent, &sprints)
+	if len(sprints.Sprints) != 2 {
+		t.Errorf("list_sprints = %+v", sprints)
+	}
+}
+
+func TestMCP_StartStory(t *testing.T) {
+	cfg, repoPath := mcpTestConfig(t, true)
+	responses := runMCP(t, cfg, mcpInitialize,
+		mcpCall(1, "start_story", map[string]interface{}{"id": "US-010", "assignee": "dana"}),
+	)
+
+	var started struct {
+		ID       string `json:"id"`
+		Branch   string `json:"branch"`
+		Assignee string `json:"assignee"`
+	}
+	result := toolResult(t, responses[1])
+	json.Unmarshal(result.StructuredContent, &started)
+	if result.IsError || !strings.HasSuffix(started.Branch, "US-010") || started.Assignee != "dana" {
+		t.Fatalf("start_story = %s", result.Content[0].Text)
+	}
+
+	repo, _ := git.PlainOpen(repoPath)
+	head, _ := repo.Head()
+	if head.Name().Short() != started.Branch {
+		t.Errorf("HEAD is %s, want %s", head.Name().Short(), started.Branch)
+	}
+}
+
+func TestMCP_Resources(t *testing.T) {
+	cfg, _ := mcpTestConfig(t, false)
+	responses := runMCP(t, cfg, mcpInitialize,
+		mcpRequest(1, "resources/list", nil),
+		mcpRequest(2, "resources/read", map[string]interface{}{"uri": "gitta://stories/US-004"}),
+		mcpRequest(3, "resources/read", map[string]interface{}{"uri": "gitta://stories/US-999"}),
+		mcpRequest(4, "resources/read", map[string]interface{}{"uri": "file:///etc/passwd"}),
+		mcpRequest(5, "resources/templates/list", nil),
+	)
+
+	var list mcp.ListResourcesResult
+	json.Unmarshal(responses[1].Result, &list)
+	if len(list.Resources) != 8 {
+		t.Fatalf("expected 8 story resources, got %d", len(list.Resources))
+	}
+	var found bool
+	for _, r := range list.Resources {
+		if r.URI == "gitta://stories/US-004" {
+			found = r.Title == "Checkout with credit card" && r.MimeType == "text/markdown"
+		}
+	}
+	if !found {
+		t.Errorf("US-004 resource missing or incomplete: %+v", list.Resources)
+	}
+
+	var read mcp.ReadResourceResult
+	json.Unmarshal(responses[2].Result, &read)
+	if len(read.Contents) != 1 || !strings.HasPrefix(read.Contents[0].Text, "---\nid: US-004\n") || read.Contents[0].URI != "gitta://stories/US-004" {
+		t.Errorf("unexpected resource contents: %+v", read)
+	}
+	for _, resp := range responses[3:5] {
+		if resp.Error == nil || resp.Error.Code != -32002 {
+			t.Errorf("unknown resources must fail with -32002, got %+v", resp)
+		}
+	}
+
+	var templates mcp.ListResourceTemplatesResult
+	json.Unmarshal(responses[5].Result, &templates)
+	if len(templates.ResourceTemplates) != 1 || templates.ResourceTemplates[0].URITemplate != "gitta://stories/{id}" {
+		t.Errorf("unexpected resource templates: %+v", templates)
+	}
+}
@@ -0,0 +1,90 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gavin/gitta/internal/services"
+)
+
+func writePlugin(t *testing.T, dir, file string, mode os.FileMode) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPluginService_List(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are found by $PATHEXT on Windows")
+	}
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	binA, binB := t.TempDir(), t.TempDir()
+
+	project := writePlugin(t, filepath.Join(repoPath, ".gitta", "plugins"), "gitta-report", 0o755)
+	shadowed := writePlugin(t, binA, "gitta-report", 0o755)
+	deploy := writePlugin(t, binA, "gitta-deploy", 0o755)
+	writePlugin(t, binB, "gitta-deploy", 0o755)
+	writePlugin(t, binB, "gitta-notes", 0o644) // not executable
+	writePlugin(t, binB, "other-tool", 0o755)
+
+	plugins := services.NewPluginService(repoPath, binA+string(os.PathListSeparator)+binB)
+	list, err := plugins.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []services.Plugin{
+		{Name: "deploy", Path: deploy, Source: services.PluginSourcePath},
+		{Name: "deploy", Path: filepath.Join(binB, "gitta-deploy"), Source: services.PluginSourcePath, ShadowedBy: deploy},
+		{Name: "report", Path: project, Source: services.PluginSourceProject},
+		{Name: "report", Path: shadowed, Source: services.PluginSourcePath, ShadowedBy: project},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("plugins = %+v, want %+v", list, want)
+	}
+	for i := range want {
+		if list[i] != want[i] {
+			t.Errorf("plugin %d = %+v, want %+v", i, list[i], want[i])
+		}
+	}
+
+	// Project plugins run before those on $PATH
+	if plugin, err := plugins.Find(ctx, "report"); err != nil || plugin == nil || plugin.Path != project {
+		t.Errorf("Find(report) = %+v, %v", plugin, err)
+	}
+	if plugin, err := plugins.Find(ctx, "notes"); err != nil || plugin != nil {
+		t.Errorf("Find(notes) = %+v, %v; want no plugin", plugin, err)
+	}
+}
+
+func TestPluginService_Context(t *testing.T) {
+	repoPath := t.TempDir()
+	copyTree(t, filepath.Join("..", "..", "testdata", "site"), repoPath)
+	writeProjectConfig(t, repoPath, "fields:\n  points: {type: int}\n")
+
+	pluginContext, err := services.NewPluginService(repoPath, "").Context(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pluginContext.Structure != "consolidated" || pluginContext.BacklogDir != filepath.Join(repoPath, "tasks", "backlog") {
+		t.Errorf("unexpected workspace: %+v", pluginContext)
+	}
+	if _, ok := pluginContext.Config["fields"].(map[string]interface{}); !ok {
+		t.Errorf("config = %v", pluginContext.Config)
+	}
+
+	// Outside a repository only the empty context is available
+	empty, err := services.NewPluginService("", "").Context(context.Background())
+	if err != nil || empty.RepoRoot != "" || empty.Config == nil {
+		t.Errorf("context outside a repository = %+v, %v", empty, err)
+	}
+}
@@ -0,0 +1,178 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// buildTestSite builds the site for the testdata/site workspace and returns
+// the output directory.
+func buildTestSite(t *testing.T) (string, *services.SiteResult) {
+	t.Helper()
+	repoPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "site"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outDir := t.TempDir()
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	svc := services.NewSiteService(repo, repo, noBranchesRepo{}, nil, repoPath, core.DefaultWorkflow())
+
+	result, err := svc.Build(context.Background(), services.SiteRequest{OutDir: outDir, Title: "Demo"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	return outDir, result
+}
+
+func readSiteFile(t *testing.T, outDir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatalf("expected %s in the site: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestSiteBuild(t *testing.T) {
+	outDir, result := buildTestSite(t)
+
+	if result.Stories != 8 || result.Sprints != 2 {
+		t.Errorf("Build() = %d stories, %d sprints, want 8 and 2", result.Stories, result.Sprints)
+	}
+	// index, backlog, epics, search, activity, two boards and eight stories
+	if result.Pages != 15 {
+		t.Errorf("Pages = %d, want 15", result.Pages)
+	}
+	for _, name := range []string{"assets/site.css", "assets/search.js", ".nojekyll"} {
+		readSiteFile(t, outDir, name)
+	}
+
+	index := readSiteFile(t, outDir, "index.html")
+	for _, want := range []string{
+		"<title>Demo</title>",
+		`<a href="sprints/Sprint-02_Checkout.html">Sprint-02_Checkout</a>`,
+		`<a href="sprints/Sprint-01_Login.html">Sprint-01_Login</a>`,
+		`href="assets/site.css"`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html missing %q:\n%s", want, index)
+		}
+	}
+
+	backlog := readSiteFile(t, outDir, "backlog.html")
+	for _, id := range []string{"US-010", "US-011", "US-012"} {
+		if !strings.Contains(backlog, `<a href="stories/`+id+`.html">`+id+`</a>`) {
+			t.Errorf("backlog.html must link %s:\n%s", id, backlog)
+		}
+	}
+	if strings.Contains(backlog, "US-004") {
+		t.Errorf("backlog.html must not list sprint stories:\n%s", backlog)
+	}
+}
+
+func TestSiteBuild_SprintBoard(t *testing.T) {
+	outDir, _ := buildTestSite(t)
+	board := readSiteFile(t, outDir, "sprints/Sprint-02_Checkout.html")
+
+	// Each story appears in the column of its status, in workflow order
+	columns := strings.Split(board, `<section class="column">`)[1:]
+	want := map[string]string{"todo": "", "doing": "US-004", "review": "US-006", "done": "US-005"}
+	if len(columns) != len(want) {
+		t.Fatalf("expected %d columns, got %d:\n%s", len(want), len(columns), board)
+	}
+	for i, status := range []string{"todo", "doing", "review", "done"} {
+		if !strings.Contains(columns[i], `status-`+status+`"`) {
+			t.Errorf("column %d is not %s:\n%s", i, status, columns[i])
+		}
+		cards := strings.Count(columns[i], `<article class="card">`)
+		if id := want[status]; id == "" && cards != 0 || id != "" && (cards != 1 || !strings.Contains(columns[i], `href="../stories/`+id+`.html"`)) {
+			t.Errorf("column %s must hold %q:\n%s", status, id, columns[i])
+		}
+	}
+	if !strings.Contains(board, "1 of 3 stories done") {
+		t.Errorf("board must show progress:\n%s", board)
+	}
+	if !strings.Contains(board, `href="../assets/site.css"`) {
+		t.Errorf("sprint pages must link assets relative to the site root:\n%s", board)
+	}
+}
+
+func TestSiteBuild_StoryPage(t *testing.T) {
+	outDir, _ := buildTestSite(t)
+	page := readSiteFile(t, outDir, "stories/US-004.html")
+
+	for _, want := range []string{
+		"<h1>US-004: Checkout with credit card</h1>",
+		`<a href="../sprints/Sprint-02_Checkout.html">Sprint-02_Checkout</a>`,
+		"<dd>alice</dd>",
+		`<a href="../epics.html#epic-checkout">checkout</a>`,
+		`<span class="tag">payments</span>`,
+		"<strong>",
+		`<input checked="" disabled="" type="checkbox"`,
+		`<code class="language-go">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("story page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<script>alert") {
+		t.Errorf("raw HTML in story bodies must not be rendered:\n%s", page)
+	}
+
+	empty := readSiteFile(t, outDir, "stories/US-012.html")
+	if !strings.Contains(empty, `<a href="../backlog.html">Backlog</a>`) {
+		t.Errorf("backlog story must link the backlog:\n%s", empty)
+	}
+}
+
+func TestSiteBuild_Epics(t *testing.T) {
+	outDir, _ := buildTestSite(t)
+	epics := readSiteFile(t, outDir, "epics.html")
+
+	checkout := strings.Index(epics, `<section class="epic" id="epic-checkout">`)
+	login := strings.Index(epics, `<section class="epic" id="epic-login">`)
+	search := strings.Index(epics, `<section class="epic" id="epic-search">`)
+	if checkout < 0 || login < 0 || search < 0 || !(checkout < login && login < search) {
+		t.Fatalf("expected checkout, login and search epics in order:\n%s", epics)
+	}
+	if section := epics[login:search]; !strings.Contains(section, "2 of 2 stories done") {
+		t.Errorf("login epic must be complete:\n%s", section)
+	}
+}
+
+func TestSiteBuild_SearchIndex(t *testing.T) {
+	outDir, _ := buildTestSite(t)
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(readSiteFile(t, outDir, "search-index.json")), &entries); err != nil {
+		t.Fatalf("search-index.json is not valid JSON: %v", err)
+	}
+	if len(entries) != 8 {
+		t.Fatalf("expected 8 entries, got %d", len(entries))
+	}
+	byID := make(map[string]map[string]interface{})
+	for _, e := range entries {
+		byID[e["id"].(string)] = e
+	}
+	us004 := byID["US-004"]
+	if us004 == nil || us004["url"] != "stories/US-004.html" || us004["status"] != "doing" ||
+		us004["epic"] != "checkout" || us004["location"] != "Sprint-02_Checkout" {
+		t.Errorf("unexpected US-004 entry: %v", us004)
+	}
+	if text, _ := us004["text"].(string); !strings.Contains(text, "payment provider") {
+		t.Errorf("entry text must hold the story body, got %q", text)
+	}
+
+	search := readSiteFile(t, outDir, "search.html")
+	if !strings.Contains(search, `data-index="search-index.json"`) {
+		t.Errorf("search page must load the prebuilt index:\n%s", search)
+	}
+}
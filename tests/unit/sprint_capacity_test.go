@@ -0,0 +1,205 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// capacityStory writes a story with an assignee and points into dir.
+func capacityStory(t *testing.T, dir, id, assignee string, points int) *core.Story {
+	t.Helper()
+	story := &core.Story{ID: id, Title: "Story " + id, Status: core.StatusTodo, Priority: core.PriorityMedium}
+	if assignee != "" {
+		story.Assignee = &assignee
+	}
+	if points > 0 {
+		story.Extra = map[string]interface{}{"points": points}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := filesystem.NewMarkdownParser().WriteStory(context.Background(), filepath.Join(dir, id+".md"), story); err != nil {
+		t.Fatal(err)
+	}
+	return story
+}
+
+func writeCapacityFile(t *testing.T, sprintPath, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(sprintPath, ".gitta"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sprintPath, ".gitta", "capacity.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSprintCapacity_Report(t *testing.T) {
+	sprintPath := filepath.Join(t.TempDir(), "tasks", "sprints", "@Sprint-02")
+	writeCapacityFile(t, sprintPath, `days: 10
+focus_factor: 0.8
+holidays: [2025-05-01]
+members:
+  - name: alice
+  - name: bob
+    days: 5
+    focus_factor: 0.5
+`)
+	capacityStory(t, sprintPath, "US-001", "alice", 5)
+	capacityStory(t, sprintPath, "US-002", "alice", 3)
+	capacityStory(t, sprintPath, "US-003", "bob", 3)
+	capacityStory(t, sprintPath, "US-004", "carol", 2)
+	capacityStory(t, sprintPath, "US-005", "", 1)
+
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	report, err := services.NewSprintCapacityService(repo, repo).Report(context.Background(), sprintPath)
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	want := []core.AssigneeLoad{
+		{Assignee: "alice", Member: true, Capacity: 7.2, Committed: 8, Stories: 2},
+		{Assignee: "bob", Member: true, Capacity: 2, Committed: 3, Stories: 1},
+		{Assignee: "carol", Capacity: 0, Committed: 2, Stories: 1},
+	}
+	if len(report.Loads) != len(want) {
+		t.Fatalf("Loads = %+v, want %+v", report.Loads, want)
+	}
+	for i := range want {
+		if report.Loads[i] != want[i] {
+			t.Errorf("Loads[%d] = %+v, want %+v", i, report.Loads[i], want[i])
+		}
+	}
+	if report.UnassignedStories != 1 || report.UnassignedPoints != 1 {
+		t.Errorf("unassigned = %d stories, %g points", report.UnassignedStories, report.UnassignedPoints)
+	}
+	if report.TeamCapacity != 9.2 || report.TeamCommitted != 14 {
+		t.Errorf("team = %g of %g", report.TeamCommitted, report.TeamCapacity)
+	}
+	if !report.Overcommitted() {
+		t.Error("report must be overcommitted")
+	}
+}
+
+func TestSprintCapacity_CheckAddition(t *testing.T) {
+	sprintsDir := filepath.Join(t.TempDir(), "tasks", "sprints")
+	sprintPath := filepath.Join(sprintsDir, "@Sprint-01")
+	backlog := filepath.Join(filepath.Dir(sprintsDir), "backlog")
+	writeCapacityFile(t, sprintPath, "days: 4\nmembers:\n  - name: alice\n  - name: bob\n")
+	capacityStory(t, sprintPath, "US-001", "alice", 3)
+
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	svc := services.NewSprintCapacityService(repo, repo)
+	ctx := context.Background()
+
+	fits := capacityStory(t, backlog, "US-002", "bob", 4)
+	warnings, err := svc.CheckAddition(ctx, sprintPath, fits)
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("story within capacity: warnings = %v, err = %v", warnings, err)
+	}
+
+	over := capacityStory(t, backlog, "US-003", "alice", 2)
+	warnings, err = svc.CheckAddition(ctx, sprintPath, over)
+	if err != nil {
+		t.Fatalf("CheckAddition() error = %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Assignee != "alice" || warnings[0].Committed != 5 || warnings[0].Capacity != 4 {
+		t.Errorf("warnings = %+v, want alice 5 of 4", warnings)
+	}
+
+	teamOver := capacityStory(t, backlog, "US-004", "", 6)
+	warnings, err = svc.CheckAddition(ctx, sprintPath, teamOver)
+	if err != nil {
+		t.Fatalf("CheckAddition() error = %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Assignee != "" || warnings[0].Committed != 9 {
+		t.Errorf("warnings = %+v, want team 9 of 8", warnings)
+	}
+
+	noCapacity := filepath.Join(sprintsDir, "+Sprint-02")
+	capacityStory(t, noCapacity, "US-005", "alice", 40)
+	warnings, err = svc.CheckAddition(ctx, noCapacity, over)
+	if err != nil || warnings != nil {
+		t.Errorf("sprint without capacity must not warn: warnings = %v, err = %v", warnings, err)
+	}
+}
+
+func TestSprintCapacity_InitAndInherit(t *testing.T) {
+	sprintsDir := filepath.Join(t.TempDir(), "tasks", "sprints")
+	first := filepath.Join(sprintsDir, "~Sprint-01")
+	second := filepath.Join(sprintsDir, "@Sprint-02")
+	third := filepath.Join(sprintsDir, "+Sprint-03")
+	capacityStory(t, first, "US-001", "bob", 1)
+	capacityStory(t, first, "US-002", "alice", 1)
+	capacityStory(t, second, "US-003", "alice", 1)
+	capacityStory(t, third, "US-004", "alice", 1)
+
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	svc := services.NewSprintCapacityService(repo, repo)
+	ctx := context.Background()
+
+	capacity, from, err := svc.InitCapacity(ctx, first)
+	if err != nil {
+		t.Fatalf("InitCapacity() error = %v", err)
+	}
+	if from != "" || capacity.Days != 10 || len(capacity.Members) != 2 || capacity.Members[0].Name != "alice" {
+		t.Errorf("template = %+v from %q, want assignees alice and bob", capacity, from)
+	}
+	if _, _, err := svc.InitCapacity(ctx, first); err == nil {
+		t.Error("InitCapacity() must fail when capacity is already defined")
+	}
+
+	capacity.Holidays = []string{"2025-01-01"}
+	if err := repo.WriteSprintCapacity(ctx, first, capacity); err != nil {
+		t.Fatal(err)
+	}
+	from, err = svc.InheritCapacity(ctx, second)
+	if err != nil || from != "~Sprint-01" {
+		t.Fatalf("InheritCapacity() = %q, %v", from, err)
+	}
+	inherited, err := repo.ReadSprintCapacity(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inherited.Members) != 2 || len(inherited.Holidays) != 0 {
+		t.Errorf("inherited capacity = %+v, want members without holidays", inherited)
+	}
+
+	inherited.Days = 8
+	if err := repo.WriteSprintCapacity(ctx, second, inherited); err != nil {
+		t.Fatal(err)
+	}
+	_, from, err = svc.InitCapacity(ctx, third)
+	if err != nil || from != "@Sprint-02" {
+		t.Errorf("InitCapacity() must copy the most recent sprint, got %q, %v", from, err)
+	}
+}
+
+func TestSprintCapacity_ReadErrors(t *testing.T) {
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParser())
+	ctx := context.Background()
+
+	if _, err := repo.ReadSprintCapacity(ctx, t.TempDir()); !errors.Is(err, core.ErrNoCapacity) {
+		t.Errorf("missing file: expected ErrNoCapacity, got %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"focus factor": "days: 10\nfocus_factor: 1.5\nmembers: []\n",
+		"holiday":      "days: 10\nholidays: [May 1]\nmembers: []\n",
+		"duplicate":    "days: 10\nmembers:\n  - name: alice\n  - name: alice\n",
+		"unknown key":  "days: 10\nmember: []\n",
+	} {
+		sprintPath := t.TempDir()
+		writeCapacityFile(t, sprintPath, content)
+		if _, err := repo.ReadSprintCapacity(ctx, sprintPath); err == nil || errors.Is(err, core.ErrNoCapacity) {
+			t.Errorf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package unit
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+// fakeHistoryAnalyzer returns fixed snapshots.
+type fakeHistoryAnalyzer struct {
+	snapshots []core.CommitSnapshot
+}
+
+func (f *fakeHistoryAnalyzer) AnalyzeSprintHistory(ctx context.Context, req core.AnalyzeHistoryRequest) ([]core.CommitSnapshot, error) {
+	return f.snapshots, nil
+}
+
+func (f *fakeHistoryAnalyzer) ReconstructFileState(ctx context.Context, repoPath, commitHash, dirPath string) (map[string]*core.Story, error) {
+	return nil, nil
+}
+
+func chartSnapshot(daysAgo int, statuses map[string]core.Status) core.CommitSnapshot {
+	files := make(map[string]*core.Story, len(statuses))
+	for id, status := range statuses {
+		files[id+".md"] = &core.Story{ID: id, Status: status}
+	}
+	return core.CommitSnapshot{CommitDate: time.Now().AddDate(0, 0, -daysAgo), Files: files}
+}
+
+func newChartService(t *testing.T, workflow core.Workflow, snapshots ...core.CommitSnapshot) (services.SprintBurndownService, string) {
+	t.Helper()
+	repoPath := t.TempDir()
+	analyzer := &fakeHistoryAnalyzer{snapshots: snapshots}
+	service := services.NewSprintBurndownServiceWithWorkflow(analyzer, nil, nil, repoPath, workflow)
+	return service, filepath.Join(repoPath, "sprints", "Sprint-01")
+}
+
+func TestGenerateBurnup_ShowsScopeCreep(t *testing.T) {
+	service, sprintPath := newChartService(t, core.DefaultWorkflow(),
+		chartSnapshot(5, map[string]core.Status{"US-001": "todo", "US-002": "doing"}),
+		chartSnapshot(3, map[string]core.Status{"US-001": "done", "US-002": "doing", "US-003": "todo"}),
+	)
+
+	points, err := service.GenerateBurnup(context.Background(), sprintPath)
+	if err != nil {
+		t.Fatalf("GenerateBurnup() error = %v", err)
+	}
+	if len(points) != 15 {
+		t.Fatalf("expected one point per day (15), got %d", len(points))
+	}
+
+	byDate := make(map[string]core.BurnupDataPoint)
+	for _, p := range points {
+		byDate[p.Date.Format("2006-01-02")] = p
+	}
+	day := func(daysAgo int) core.BurnupDataPoint {
+		return byDate[time.Now().AddDate(0, 0, -daysAgo).Format("2006-01-02")]
+	}
+
+	if p := day(5); p.ScopeTasks != 2 || p.CompletedTasks != 0 {
+		t.Errorf("day -5 = %+v, want scope 2, completed 0", p)
+	}
+	if p := day(4); p.ScopeTasks != 2 {
+		t.Errorf("day -4 must repeat the previous day, got %+v", p)
+	}
+	if p := day(0); p.ScopeTasks != 3 || p.CompletedTasks != 1 || p.ScopePoints != 3 || p.CompletedPoints != 1 {
+		t.Errorf("today = %+v, want scope 3, completed 1 (tasks and points)", p)
+	}
+}
+
+func TestGenerateCumulativeFlow_CountsPerState(t *testing.T) {
+	workflow := loadTestConfig(t, workflowConfig).Workflow
+	service, sprintPath := newChartService(t, workflow,
+		chartSnapshot(2, map[string]core.Status{"US-001": "ready", "US-002": "qa", "US-003": "legacy"}),
+	)
+
+	flow, err := service.GenerateCumulativeFlow(context.Background(), sprintPath)
+	if err != nil {
+		t.Fatalf("GenerateCumulativeFlow() error = %v", err)
+	}
+
+	wantStates := "todo,ready,doing,review,qa,done,blocked,wontfix,legacy"
+	var states []string
+	for _, s := range flow.States {
+		states = append(states, string(s))
+	}
+	if strings.Join(states, ",") != wantStates {
+		t.Errorf("states = %v, want %s", states, wantStates)
+	}
+
+	last := flow.Points[len(flow.Points)-1]
+	if last.Counts["ready"] != 1 || last.Counts["qa"] != 1 || last.Counts["legacy"] != 1 || last.Counts["done"] != 0 {
+		t.Errorf("counts = %v", last.Counts)
+	}
+	if _, ok := last.Counts["todo"]; !ok {
+		t.Error("states without tasks must be reported as 0")
+	}
+}
+
+func TestGenerateBurnup_NoHistory(t *testing.T) {
+	service, sprintPath := newChartService(t, core.DefaultWorkflow())
+	if _, err := service.GenerateBurnup(context.Background(), sprintPath); !errors.Is(err, core.ErrInsufficientHistory) {
+		t.Errorf("error = %v, want ErrInsufficientHistory", err)
+	}
+}
+
+func testFlow() core.CumulativeFlow {
+	return core.CumulativeFlow{
+		States: []core.Status{core.StatusTodo, core.StatusDoing, core.StatusDone},
+		Points: []core.FlowDataPoint{
+			{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Counts: map[core.Status]int{"todo": 3, "doing": 0, "done": 0}},
+			{Date: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Counts: map[core.Status]int{"todo": 1, "doing": 1, "done": 2}},
+		},
+	}
+}
+
+func testBurnup() []core.BurnupDataPoint {
+	return []core.BurnupDataPoint{
+		{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), ScopeTasks: 3, CompletedTasks: 0, ScopePoints: 8, CompletedPoints: 0},
+		{Date: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), ScopeTasks: 4, CompletedTasks: 2, ScopePoints: 13, CompletedPoints: 5},
+	}
+}
+
+func TestChartCSV(t *testing.T) {
+	wantBurnup := "Date,ScopeTasks,CompletedTasks,ScopePoints,CompletedPoints\n2025-01-01,3,0,8,0\n2025-01-02,4,2,13,5"
+	if got := ui.FormatBurnupCSV(testBurnup()); got != wantBurnup {
+		t.Errorf("FormatBurnupCSV() =\n%s\nwant\n%s", got, wantBurnup)
+	}
+
+	wantFlow := "Date,todo,doing,done\n2025-01-01,3,0,0\n2025-01-02,1,1,2"
+	if got := ui.FormatCumulativeFlowCSV(testFlow()); got != wantFlow {
+		t.Errorf("FormatCumulativeFlowCSV() =\n%s\nwant\n%s", got, wantFlow)
+	}
+}
+
+func TestChartASCII(t *testing.T) {
+	burnup := ui.RenderBurnupChart(testBurnup())
+	for _, want := range []string{"Legend: = = Scope, # = Completed", "Scope: 3 → 4 tasks (+1), 8 → 13 points (+5)", "Completed: 2/4 tasks, 5/13 points", "2025-01-01 to 2025-01-02"} {
+		if !strings.Contains(burnup, want) {
+			t.Errorf("burnup chart missing %q:\n%s", want, burnup)
+		}
+	}
+
+	cfd := ui.RenderCumulativeFlowChart(testFlow())
+	if !strings.Contains(cfd, "Legend: # = done, = = doing, + = todo") {
+		t.Errorf("cfd chart legend must list bands bottom-up:\n%s", cfd)
+	}
+	if ui.RenderCumulativeFlowChart(core.CumulativeFlow{}) != "No data available for cumulative flow diagram" {
+		t.Error("expected empty-state message")
+	}
+}
+
+func TestChartSVG_IsWellFormed(t *testing.T) {
+	for name, doc := range map[string]string{
+		"burnup": ui.RenderBurnupSVG(testBurnup()),
+		"cfd":    ui.RenderCumulativeFlowSVG(testFlow()),
+		"empty":  ui.RenderBurnupSVG(nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if !strings.HasPrefix(doc, "<svg ") {
+				t.Fatalf("not an SVG document:\n%s", doc)
+			}
+			dec := xml.NewDecoder(strings.NewReader(doc))
+			for {
+				if _, err := dec.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("invalid XML: %v\n%s", err, doc)
+				}
+			}
+		})
+	}
+
+	if cfd := ui.RenderCumulativeFlowSVG(testFlow()); strings.Count(cfd, "<polygon") != 3 {
+		t.Errorf("expected one band per state:\n%s", cfd)
+	}
+}
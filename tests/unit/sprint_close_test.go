@@ -1,9 +1,14 @@
 package unit
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/gavin/gitta/infra/filesystem"
 	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
 )
 
 func TestIdentifyUnfinishedTasks(t *testing.T) {
@@ -88,6 +93,59 @@ func TestIdentifyUnfinishedTasks(t *testing.T) {
 	}
 }
 
+func TestSprintClose_UsesWorkflow(t *testing.T) {
+	ctx := context.Background()
+	workflow := core.Workflow{
+		Initial: "ready",
+		States: []core.WorkflowState{
+			{Name: "ready", Category: core.CategoryTodo},
+			{Name: "doing", Category: core.CategoryInProgress, Derive: core.DeriveBranch},
+			{Name: "qa", Category: core.CategoryInProgress},
+			{Name: "done", Category: core.CategoryDone, Derive: core.DeriveMerged},
+			{Name: "wontfix", Category: core.CategoryDone},
+		},
+	}
+	repoPath := t.TempDir()
+	source := filepath.Join(repoPath, "tasks", "sprints", "Sprint-01")
+	target := filepath.Join(repoPath, "tasks", "sprints", "Sprint-02")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for id, status := range map[string]string{"US-001": "qa", "US-002": "wontfix", "US-003": "done"} {
+		writeTestFile(t, filepath.Join(source, id+".md"), "---\nid: "+id+"\ntitle: Story\nstatus: "+status+"\n---\n")
+	}
+
+	parser := filesystem.NewMarkdownParserWithRules(services.ValidationRules{Workflow: &workflow})
+	repo := filesystem.NewRepository(parser)
+	closeService := services.NewSprintCloseServiceWithWorkflow(repo, repo, parser, repoPath, workflow)
+
+	// Done-category states such as wontfix are finished
+	unfinished, err := closeService.CloseSprint(ctx, source)
+	if err != nil {
+		t.Fatalf("CloseSprint() error = %v", err)
+	}
+	if len(unfinished) != 1 || unfinished[0].ID != "US-001" {
+		t.Fatalf("CloseSprint() = %+v, want only US-001", unfinished)
+	}
+
+	// Rolled over stories restart in the workflow's initial state
+	err = closeService.RolloverTasks(ctx, core.RolloverRequest{
+		SourceSprintPath: source,
+		TargetSprintPath: target,
+		SelectedTaskIDs:  []string{"US-001"},
+	})
+	if err != nil {
+		t.Fatalf("RolloverTasks() error = %v", err)
+	}
+	story, err := parser.ReadStory(ctx, filepath.Join(target, "US-001.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Status != "ready" {
+		t.Errorf("rolled over status = %s, want the initial state ready", story.Status)
+	}
+}
+
 // identifyUnfinishedTasks is a helper function that identifies unfinished tasks.
 // This will be implemented in the service.
 func identifyUnfinishedTasks(stories []*core.Story) []*core.Story {
@@ -0,0 +1,254 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	gittagit "github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// doctorRepo builds a repository exercising every story doctor check.
+func doctorRepo(t *testing.T) string {
+	t.Helper()
+	repoPath := t.TempDir()
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	wt, _ := repo.Worktree()
+
+	writeDoctorStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-001.md"), "US-001", "todo")
+	writeDoctorStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-002.md"), "US-002", "done")
+	writeDoctorStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-003.md"), "US-003", "doing")
+	writeDoctorStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-004.md"), "US-004", "todo")
+	writeDoctorStory(t, filepath.Join(repoPath, "tasks", "sprints", "Sprint-01", "US-004.md"), "US-004", "todo")
+
+	if _, err := wt.Add("."); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	old := time.Now().AddDate(0, 0, -30)
+	base, err := wt.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: old},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	// origin/main contains the base commit, so branches at base are merged.
+	setRef(t, repo, plumbing.NewRemoteReferenceName("origin", "main"), base)
+	setRef(t, repo, plumbing.NewBranchReferenceName("feat/US-001"), base)
+	setRef(t, repo, plumbing.NewBranchReferenceName("feat/US-009"), base)
+
+	// feat/US-002 and feat/US-003 carry an extra commit that is not on origin/main.
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feat/US-002"), Create: true}); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "work.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	wt.Add("work.txt")
+	unmerged, err := wt.Commit("wip", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: old},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	setRef(t, repo, plumbing.NewBranchReferenceName("feat/US-003"), unmerged)
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}); err != nil {
+		t.Fatalf("checkout master: %v", err)
+	}
+	return repoPath
+}
+
+func writeDoctorStory(t *testing.T, path, id, status string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	content := "---\nid: " + id + "\ntitle: Story " + id + "\nstatus: " + status + "\n---\n\nBody\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write story: %v", err)
+	}
+}
+
+func setRef(t *testing.T, repo *git.Repository, name plumbing.ReferenceName, hash plumbing.Hash) {
+	t.Helper()
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+		t.Fatalf("set ref %s: %v", name, err)
+	}
+}
+
+func newStoryDoctor(repoPath string) services.StoryDoctorService {
+	repo := filesystem.NewDefaultRepository()
+	gitRepo := gittagit.NewRepository()
+	return services.NewStoryDoctorService(repo, repo, gitRepo, gitRepo, repoPath)
+}
+
+func TestDetectStoryIssues(t *testing.T) {
+	repoPath := doctorRepo(t)
+	// A copy in the same directory is a duplicate too
+	writeDoctorStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-004-copy.md"), "US-004", "todo")
+	doctor := newStoryDoctor(repoPath)
+
+	issues, err := doctor.DetectStoryIssues(context.Background(), services.StoryDoctorOptions{})
+	if err != nil {
+		t.Fatalf("DetectStoryIssues() error = %v", err)
+	}
+
+	got := make(map[services.StoryIssueKind]services.StoryIssue)
+	for _, issue := range issues {
+		got[issue.Kind] = issue
+	}
+
+	tests := []struct {
+		kind    services.StoryIssueKind
+		storyID string
+		fixable bool
+	}{
+		{services.IssueOrphanBranch, "US-009", true},
+		{services.IssueMergedBranch, "US-001", true},
+		{services.IssueDoneUnmerged, "US-002", false},
+		{services.IssueStaleDoing, "US-003", false},
+		{services.IssueDuplicateID, "US-004", false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			issue, ok := got[tt.kind]
+			if !ok {
+				t.Fatalf("expected %s issue, got %+v", tt.kind, issues)
+			}
+			if issue.StoryID != tt.storyID {
+				t.Errorf("StoryID = %s, want %s", issue.StoryID, tt.storyID)
+			}
+			if issue.Fixable != tt.fixable {
+				t.Errorf("Fixable = %v, want %v", issue.Fixable, tt.fixable)
+			}
+		})
+	}
+
+	wantPaths := "tasks/backlog/US-004-copy.md,tasks/backlog/US-004.md,tasks/sprints/Sprint-01/US-004.md"
+	if dup := got[services.IssueDuplicateID]; strings.Join(dup.Paths, ",") != wantPaths {
+		t.Errorf("duplicate paths = %v, want %s", dup.Paths, wantPaths)
+	}
+}
+
+func TestDetectStoryIssues_StaleThreshold(t *testing.T) {
+	repoPath := doctorRepo(t)
+	doctor := newStoryDoctor(repoPath)
+
+	issues, err := doctor.DetectStoryIssues(context.Background(), services.StoryDoctorOptions{StaleAfter: 60 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("DetectStoryIssues() error = %v", err)
+	}
+	for _, issue := range issues {
+		if issue.Kind == services.IssueStaleDoing {
+			t.Fatalf("did not expect stale issue with 60 day threshold: %+v", issue)
+		}
+	}
+}
+
+func TestDetectStoryIssues_UsesWorkflowCategories(t *testing.T) {
+	repoPath := doctorRepo(t)
+	// Unmerged US-002 is in a done-category state and US-003 in an in-progress one
+	writeDoctorStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-002.md"), "US-002", "wontfix")
+	writeDoctorStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-003.md"), "US-003", "qa")
+	workflow := core.Workflow{
+		Initial: "todo",
+		States: []core.WorkflowState{
+			{Name: "todo", Category: core.CategoryTodo},
+			{Name: "doing", Category: core.CategoryInProgress, Derive: core.DeriveBranch},
+			{Name: "qa", Category: core.CategoryInProgress},
+			{Name: "done", Category: core.CategoryDone, Derive: core.DeriveMerged},
+			{Name: "wontfix", Category: core.CategoryDone},
+		},
+	}
+	repo := filesystem.NewRepository(filesystem.NewMarkdownParserWithRules(services.ValidationRules{Workflow: &workflow}))
+	gitRepo := gittagit.NewRepository()
+	doctor := services.NewStoryDoctorServiceWithWorkflow(repo, repo, gitRepo, gitRepo, repoPath, workflow)
+
+	issues, err := doctor.DetectStoryIssues(context.Background(), services.StoryDoctorOptions{})
+	if err != nil {
+		t.Fatalf("DetectStoryIssues() error = %v", err)
+	}
+	found := make(map[string]bool)
+	for _, issue := range issues {
+		found[string(issue.Kind)+":"+issue.StoryID] = true
+	}
+	for _, want := range []string{"done_unmerged:US-002", "stale_doing:US-003"} {
+		if !found[want] {
+			t.Errorf("missing issue %s in %+v", want, issues)
+		}
+	}
+}
+
+func TestRepairStoryIssues(t *testing.T) {
+	ctx := context.Background()
+	repoPath := doctorRepo(t)
+	doctor := newStoryDoctor(repoPath)
+
+	issues, err := doctor.DetectStoryIssues(ctx, services.StoryDoctorOptions{})
+	if err != nil {
+		t.Fatalf("DetectStoryIssues() error = %v", err)
+	}
+
+	result, err := doctor.RepairStoryIssues(ctx, issues)
+	if err != nil {
+		t.Fatalf("RepairStoryIssues() error = %v", err)
+	}
+	if result.RepairedCount != 2 || result.FailedCount != 0 {
+		t.Fatalf("RepairStoryIssues() = %+v, want 2 repaired", result)
+	}
+
+	repo, _ := git.PlainOpen(repoPath)
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName("feat/US-001"), true); err == nil {
+		t.Error("merged branch feat/US-001 should be deleted")
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName("feat/US-009"), true); err == nil {
+		t.Error("orphan branch feat/US-009 should be renamed")
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName("archive/feat/US-009"), true); err != nil {
+		t.Errorf("archived branch missing: %v", err)
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName("feat/US-002"), true); err != nil {
+		t.Errorf("unmerged branch feat/US-002 must be kept: %v", err)
+	}
+}
+
+func TestRepairStoryIssues_ReportsOnlyRepaired(t *testing.T) {
+	ctx := context.Background()
+	repoPath := doctorRepo(t)
+	doctor := newStoryDoctor(repoPath)
+
+	issues, err := doctor.DetectStoryIssues(ctx, services.StoryDoctorOptions{})
+	if err != nil {
+		t.Fatalf("DetectStoryIssues() error = %v", err)
+	}
+	// The merged branch disappears before the repair, which then fails
+	repo, _ := git.PlainOpen(repoPath)
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName("feat/US-001")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := doctor.RepairStoryIssues(ctx, issues)
+	if err != nil {
+		t.Fatalf("RepairStoryIssues() error = %v", err)
+	}
+	if result.RepairedCount != 1 || result.FailedCount != 1 {
+		t.Fatalf("RepairStoryIssues() = %+v, want 1 repaired and 1 failed", result)
+	}
+	if len(result.Repaired) != 1 || result.Repaired[0].Branch != "feat/US-009" {
+		t.Errorf("Repaired = %+v, want only the orphan branch feat/US-009", result.Repaired)
+	}
+}
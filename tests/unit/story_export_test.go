@@ -0,0 +1,224 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// newStoryExport exports from, and bulk-updates, a copy of the testdata/site
+// workspace with a points custom field.
+func newStoryExport(t *testing.T) (services.ExportService, services.BulkUpdateService, string) {
+	t.Helper()
+	repoPath := t.TempDir()
+	copyTree(t, filepath.Join("..", "..", "testdata", "site"), repoPath)
+	writeProjectConfig(t, repoPath, "fields:\n  points: {type: int, min: 0}\n")
+	cfg, err := services.LoadProjectConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := filesystem.NewMarkdownParserWithRules(cfg.ValidationRules())
+	repo := filesystem.NewRepository(parser)
+	board := services.NewBoardService(repo, repo, noBranchesRepo{}, repoPath, cfg.Workflow)
+	backlogPath := filepath.Join(repoPath, "tasks", "backlog")
+	edit := services.NewStoryEditService(parser, repo, board,
+		services.NewCreateService(filesystem.NewIDCounter(repoPath), parser, repo, backlogPath),
+		nil, repoPath, backlogPath, cfg.Workflow)
+	export := services.NewExportService(board, noBranchesRepo{}, cfg.Fields, repoPath)
+	bulk := services.NewBulkUpdateService(parser, repo, board, edit, cfg.Fields, repoPath, cfg.Workflow)
+	return export, bulk, repoPath
+}
+
+func exportText(t *testing.T, export services.ExportService, format services.TableFormat, opts services.ExportOptions) string {
+	t.Helper()
+	table, err := export.Export(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := table.Write(&out, format); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestParseStoryQuery(t *testing.T) {
+	filter, err := services.ParseStoryQuery(`sprint:current status:doing,review tag:payments points:8 "credit card" tag:api`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := services.StoryFilter{
+		Sprint:   "current",
+		Statuses: []string{"doing", "review"},
+		Tags:     []string{"payments", "api"},
+		Text:     "credit card",
+		Fields:   map[string][]string{"points": {"8"}},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("filter = %+v, want %+v", filter, want)
+	}
+
+	for _, query := range []string{`status:`, `sprint:a sprint:b`, `"open`} {
+		if _, err := services.ParseStoryQuery(query); !errors.Is(err, services.ErrInvalidInput) {
+			t.Errorf("%s: error = %v, want ErrInvalidInput", query, err)
+		}
+	}
+}
+
+func TestExportService(t *testing.T) {
+	export, _, _ := newStoryExport(t)
+
+	csv := exportText(t, export, services.TableCSV, services.ExportOptions{})
+	lines := strings.Split(strings.TrimSpace(csv), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("expected a header and 8 stories:\n%s", csv)
+	}
+	// Configured fields come first, then other frontmatter keys
+	if lines[0] != "id,title,status,derived_status,priority,assignee,tags,location,branch,created_at,updated_at,points,epic" {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if lines[3] != `US-004,Checkout with credit card,doing,todo,high,alice,"payments,api",!Sprint-02_Checkout,,,,8,checkout` {
+		t.Errorf("unexpected row %q", lines[3])
+	}
+
+	filter, err := services.ParseStoryQuery("tag:payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonl := exportText(t, export, services.TableJSONL, services.ExportOptions{Columns: []string{"id", "tags", "points"}, Filter: filter})
+	want := `{"id":"US-004","tags":["payments","api"],"points":8}` + "\n" + `{"id":"US-010","tags":["payments"],"points":5}` + "\n"
+	if jsonl != want {
+		t.Errorf("jsonl = %q, want %q", jsonl, want)
+	}
+
+	md := exportText(t, export, services.TableMarkdown, services.ExportOptions{Columns: []string{"id", "body"}, Filter: services.StoryFilter{Text: "credit card"}})
+	if !strings.HasPrefix(md, "| id | body |\n| --- | --- |\n| US-004 | ## Description<br><br>Accept") {
+		t.Errorf("unexpected markdown:\n%s", md)
+	}
+
+	yml := exportText(t, export, services.TableYAML, services.ExportOptions{Columns: []string{"all"}, Filter: services.StoryFilter{Sprint: "backlog"}})
+	if !strings.HasPrefix(yml, "- id: US-010\n") || !strings.Contains(yml, "  body: |\n") || strings.Count(yml, "- id:") != 3 {
+		t.Errorf("unexpected yaml:\n%s", yml)
+	}
+
+	if _, err := export.Export(context.Background(), services.ExportOptions{Columns: []string{"id", "size"}}); !errors.Is(err, services.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestBulkUpdateService(t *testing.T) {
+	export, bulk, repoPath := newStoryExport(t)
+	ctx := context.Background()
+
+	// Edit an exported CSV: rename US-010, move it to doing, change points
+	// and tags, assign US-012 and add a frontmatter map to it
+	csv := exportText(t, export, services.TableCSV, services.ExportOptions{})
+	csv = strings.Replace(csv, "US-010,Save cards for later,todo,todo,medium,,payments,Backlog,,,,5,checkout",
+		"US-010,Save cards for later (v2),doing,todo,medium,,\"payments,cards\",Backlog,,,,13,checkout", 1)
+	csv = strings.Replace(csv, "US-012,Dark mode,todo,todo,low,,,Backlog,,,,,",
+		`US-012,Dark mode,todo,todo,low,dana,,Backlog,,,,,"{""jira"": ""UI-7""}"`, 1)
+	table, err := services.ReadStoryTable(strings.NewReader(csv), services.TableCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, _ := os.ReadFile(filepath.Join(repoPath, "tasks", "backlog", "US-010.md"))
+	report, err := bulk.Apply(ctx, table, services.BulkUpdateOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 2 || report.Unchanged != 6 || len(report.Ignored) != 5 {
+		t.Fatalf("unexpected dry run: %+v", report)
+	}
+	if got := strings.Join(report.Changes[0].Changed, ","); got != "status,title,tags,points" {
+		t.Errorf("changed = %s", got)
+	}
+	if !strings.Contains(report.Changes[0].After, "title: Save cards for later (v2)") {
+		t.Errorf("unexpected preview:\n%s", report.Changes[0].After)
+	}
+	if after, _ := os.ReadFile(filepath.Join(repoPath, "tasks", "backlog", "US-010.md")); !bytes.Equal(before, after) {
+		t.Errorf("dry run changed the story")
+	}
+
+	if _, err := bulk.Apply(ctx, table, services.BulkUpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	us010 := readImportedStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-010.md"))
+	if us010.Title != "Save cards for later (v2)" || us010.Status != core.StatusDoing ||
+		strings.Join(us010.Tags, ",") != "payments,cards" || us010.Extra["points"] != 13 {
+		t.Errorf("unexpected US-010: %+v", us010)
+	}
+	us012 := readImportedStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-012.md"))
+	if refs, ok := us012.Extra["epic"].(map[string]interface{}); !ok || refs["jira"] != "UI-7" || us012.Assignee == nil {
+		t.Errorf("unexpected US-012: %+v", us012)
+	}
+
+	// The new export round-trips without changes
+	jsonl := exportText(t, export, services.TableJSONL, services.ExportOptions{Columns: []string{"all"}})
+	table, err = services.ReadStoryTable(strings.NewReader(jsonl), services.TableJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = bulk.Apply(ctx, table, services.BulkUpdateOptions{})
+	if err != nil || len(report.Changes) != 0 || report.Unchanged != 8 {
+		t.Errorf("round trip changed stories: %+v, %v", report, err)
+	}
+}
+
+func TestBulkUpdateService_Validation(t *testing.T) {
+	_, bulk, repoPath := newStoryExport(t)
+	ctx := context.Background()
+
+	// Every row is checked and nothing is written when any is invalid
+	jsonl := `{"id":"US-011","title":"Search everything"}
+{"id":"US-012","status":"done"}
+{"id":"US-010","points":-1}
+{"id":"US-099","title":"Missing"}
+{"id":"US-011","title":"Again"}
+{"title":"No ID"}
+`
+	table, err := services.ReadStoryTable(strings.NewReader(jsonl), services.TableJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := bulk.Apply(ctx, table, services.BulkUpdateOptions{})
+	if !errors.Is(err, services.ErrValidationFailed) {
+		t.Fatalf("error = %v, want ErrValidationFailed", err)
+	}
+	var rows []int
+	for _, rowErr := range report.Errors {
+		rows = append(rows, rowErr.Row)
+	}
+	if !reflect.DeepEqual(rows, []int{2, 3, 4, 5, 6}) {
+		t.Errorf("invalid rows = %v (%+v)", rows, report.Errors)
+	}
+	if story := readImportedStory(t, filepath.Join(repoPath, "tasks", "backlog", "US-011.md")); story.Title != "Search by product name" {
+		t.Errorf("story changed despite invalid rows: %s", story.Title)
+	}
+
+	// Undeclared transitions need Force
+	table, err = services.ReadStoryTable(strings.NewReader(`{"id":"US-010","status":"done"}`), services.TableJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bulk.Apply(ctx, table, services.BulkUpdateOptions{}); !errors.Is(err, services.ErrValidationFailed) {
+		t.Errorf("error = %v, want ErrValidationFailed", err)
+	}
+	if _, err := bulk.Apply(ctx, table, services.BulkUpdateOptions{Force: true}); err != nil {
+		t.Errorf("forced update failed: %v", err)
+	}
+
+	if _, err := services.ReadStoryTable(strings.NewReader("title\nA\n"), services.TableCSV); !errors.Is(err, services.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+}
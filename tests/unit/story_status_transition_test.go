@@ -0,0 +1,142 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	gittagit "github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+func TestWorkflowValidateTransition_Default(t *testing.T) {
+	wf := core.DefaultWorkflow()
+
+	tests := []struct {
+		name    string
+		from    core.Status
+		to      core.Status
+		wantErr bool
+		errMsg  string
+	}{
+		// Valid transitions
+		{"Todo to Doing", core.StatusTodo, core.StatusDoing, false, ""},
+		{"Doing to Review", core.StatusDoing, core.StatusReview, false, ""},
+		{"Doing to Done", core.StatusDoing, core.StatusDone, false, ""},
+		{"Doing back to Todo", core.StatusDoing, core.StatusTodo, false, ""},
+		{"Review to Done", core.StatusReview, core.StatusDone, false, ""},
+		{"Review back to Doing", core.StatusReview, core.StatusDoing, false, ""},
+		{"Reopen Done", core.StatusDone, core.StatusDoing, false, ""},
+		{"Same status (no-op)", core.StatusReview, core.StatusReview, false, ""},
+
+		// Invalid transitions
+		{"Todo to Done", core.StatusTodo, core.StatusDone, true, "invalid transition from todo to done (allowed: doing)"},
+		{"Todo to Review", core.StatusTodo, core.StatusReview, true, "invalid transition"},
+		{"Done to Todo", core.StatusDone, core.StatusTodo, true, "invalid transition"},
+		{"Unknown target", core.StatusTodo, core.Status("shipped"), true, "invalid status: shipped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wf.ValidateTransition(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateTransition(%v, %v) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidateTransition(%v, %v) error message = %q, want containing %q", tt.from, tt.to, err.Error(), tt.errMsg)
+			}
+		})
+	}
+}
+
+// newStatusTestService creates a backlog directory with one story and returns
+// an UpdateService for it along with the story path.
+func newStatusTestService(t *testing.T, status core.Status) (services.UpdateService, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	backlogDir := filepath.Join(tmpDir, "backlog")
+	if err := os.MkdirAll(backlogDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	filePath := createTestStory(t, backlogDir, "US-001", "Story", status)
+
+	parser := filesystem.NewMarkdownParser()
+	return services.NewUpdateService(parser, filesystem.NewRepository(parser), tmpDir), filePath
+}
+
+func TestUpdateService_EnforcesDefaultTransitions(t *testing.T) {
+	update, filePath := newStatusTestService(t, core.StatusTodo)
+	ctx := context.Background()
+
+	if err := update.UpdateStatus(ctx, "US-001", core.StatusDone); !errors.Is(err, services.ErrInvalidTransition) {
+		t.Fatalf("todo → done error = %v, want ErrInvalidTransition", err)
+	}
+	verifyStoryStatus(t, filePath, core.StatusTodo)
+
+	if err := update.ForceStatus(ctx, "US-001", core.StatusDone); err != nil {
+		t.Fatalf("ForceStatus() error = %v", err)
+	}
+	verifyStoryStatus(t, filePath, core.StatusDone)
+
+	if err := update.ForceStatus(ctx, "US-001", core.Status("shipped")); err == nil {
+		t.Error("ForceStatus() must still reject statuses outside the workflow")
+	}
+}
+
+func TestUpdateService_DefaultedStatusEnforcesTransitions(t *testing.T) {
+	update, filePath := newStatusTestService(t, core.StatusTodo)
+	if err := update.ClearStatus(context.Background(), "US-001"); err != nil {
+		t.Fatalf("ClearStatus() error = %v", err)
+	}
+
+	// Without a branch the story is in the initial todo, which cannot move to done
+	if err := update.UpdateStatus(context.Background(), "US-001", core.StatusDone); !errors.Is(err, services.ErrInvalidTransition) {
+		t.Fatalf("defaulted todo → done error = %v, want ErrInvalidTransition", err)
+	}
+	if data, err := os.ReadFile(filePath); err != nil || strings.Contains(string(data), "status:") {
+		t.Errorf("rejected transition was written: %v\n%s", err, data)
+	}
+}
+
+func TestUpdateService_DefaultedStatusDerivedFromGit(t *testing.T) {
+	ctx := context.Background()
+	// US-003 has an unmerged local branch, so it is in doing once its status is cleared
+	repoPath := doctorRepo(t)
+	parser := filesystem.NewMarkdownParser()
+	update := services.NewUpdateServiceWithGit(parser, filesystem.NewRepository(parser), gittagit.NewRepository(), repoPath, core.DefaultWorkflow(), nil, nil)
+	if err := update.ClearStatus(ctx, "US-003"); err != nil {
+		t.Fatalf("ClearStatus() error = %v", err)
+	}
+
+	if err := update.UpdateStatus(ctx, "US-003", core.StatusReview); err != nil {
+		t.Fatalf("derived doing → review error = %v", err)
+	}
+	verifyStoryStatus(t, filepath.Join(repoPath, "tasks", "backlog", "US-003.md"), core.StatusReview)
+}
+
+func TestUpdateService_ClearStatus(t *testing.T) {
+	update, filePath := newStatusTestService(t, core.StatusReview)
+	if err := update.ClearStatus(context.Background(), "US-001"); err != nil {
+		t.Fatalf("ClearStatus() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "status:") {
+		t.Errorf("explicit status was not cleared:\n%s", data)
+	}
+	if !strings.Contains(string(data), "updated_at:") {
+		t.Errorf("updated_at was not set:\n%s", data)
+	}
+
+	if err := update.ClearStatus(context.Background(), "US-999"); err == nil {
+		t.Error("expected error for unknown story")
+	}
+}
@@ -0,0 +1,328 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	switch {
+	case want == "" && !os.IsNotExist(err):
+		t.Errorf("%s exists (%v), want it removed", path, err)
+	case want != "" && err != nil:
+		t.Errorf("%s: %v", path, err)
+	case want != "" && string(data) != want:
+		t.Errorf("%s = %q, want %q", path, data, want)
+	}
+}
+
+// stage begins a transaction writing content to path and removing remove.
+func stage(t *testing.T, log *filesystem.TransactionLog, operation, path, content, remove string) core.FileTransaction {
+	t.Helper()
+	tx, err := log.Begin(context.Background(), operation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Write(path, func(staged string) error {
+		return os.WriteFile(staged, []byte(content), 0o644)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if remove != "" {
+		if err := tx.Remove(remove); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tx
+}
+
+func TestTransactionLog_CommitAndUndo(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	source := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	target := filepath.Join(repoPath, "tasks", "sprints", "Sprint-01", "US-001.md")
+	writeTestFile(t, source, "story")
+
+	move := filesystem.NewTransactionLog(repoPath, "gitta move US-001 sprints/Sprint-01")
+	if err := stage(t, move, "move US-001", target, "moved story", source).Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, source, "")
+	assertFile(t, target, "moved story")
+
+	// Each command is one operation, with all of its transactions
+	edit := filesystem.NewTransactionLog(repoPath, "gitta import stories.csv")
+	for _, content := range []string{"edit 1", "edit 2"} {
+		if err := stage(t, edit, "update US-001", target, content, "").Commit(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ops, err := edit.Operations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || ops[0].Command != "gitta import stories.csv" || len(ops[0].Changes) != 2 ||
+		ops[1].Command != "gitta move US-001 sprints/Sprint-01" ||
+		strings.Join(ops[1].Files, ",") != "tasks/sprints/Sprint-01/US-001.md,tasks/backlog/US-001.md" {
+		t.Fatalf("operations = %+v", ops)
+	}
+
+	// Files changed since an operation are not overwritten without force
+	writeTestFile(t, target, "edited by hand")
+	if _, err := edit.Undo(ctx, 1, false); !errors.Is(err, core.ErrUndoConflict) {
+		t.Fatalf("Undo after a manual edit = %v, want ErrUndoConflict", err)
+	}
+	assertFile(t, target, "edited by hand")
+
+	undone, err := edit.Undo(ctx, 2, true)
+	if err != nil || len(undone) != 2 {
+		t.Fatalf("Undo(2) = %+v, %v", undone, err)
+	}
+	assertFile(t, source, "story")
+	assertFile(t, target, "")
+	if _, err := os.Stat(filepath.Dir(target)); !os.IsNotExist(err) {
+		t.Errorf("directory created by the move was not removed: %v", err)
+	}
+	if _, err := edit.Undo(ctx, 1, false); !errors.Is(err, core.ErrNothingToUndo) {
+		t.Errorf("Undo with an empty history = %v, want ErrNothingToUndo", err)
+	}
+}
+
+func TestTransactionLog_FailedCommitRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	story := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	writeTestFile(t, story, "story")
+
+	// Removing a missing file fails after the story was written
+	log := filesystem.NewTransactionLog(repoPath, "gitta test")
+	tx := stage(t, log, "update US-001", story, "updated", filepath.Join(repoPath, "missing.md"))
+	if err := tx.Commit(ctx); err == nil {
+		t.Fatal("expected the commit to fail")
+	}
+	assertFile(t, story, "story")
+	if ops, err := log.Operations(ctx); err != nil || len(ops) != 0 {
+		t.Errorf("failed commit recorded as %+v, %v", ops, err)
+	}
+
+	// Rolled back transactions leave nothing behind
+	tx = stage(t, log, "update US-001", story, "discarded", "")
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, story, "story")
+	entries, _ := os.ReadDir(filepath.Join(repoPath, filepath.FromSlash(filesystem.TransactionDir)))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			t.Errorf("transaction %s left behind", entry.Name())
+		}
+	}
+}
+
+func TestTransactionLog_Recover(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	source := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	target := filepath.Join(repoPath, "tasks", "sprints", "Sprint-01", "US-001.md")
+	writeTestFile(t, source, "story")
+
+	log := filesystem.NewTransactionLog(repoPath, "gitta move US-001 sprints/Sprint-01")
+	if err := stage(t, log, "move US-001", target, "moved story", source).Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash while applying: the journal is still pending
+	txDirs, err := filepath.Glob(filepath.Join(repoPath, filepath.FromSlash(filesystem.TransactionDir), "*", "journal.json"))
+	if err != nil || len(txDirs) != 1 {
+		t.Fatalf("journals = %v, %v", txDirs, err)
+	}
+	journal, err := os.ReadFile(txDirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := strings.Replace(string(journal), `"state": "committed"`, `"state": "pending"`, 1)
+	writeTestFile(t, txDirs[0], pending)
+
+	// Recent transactions may still be committing in another process
+	if recovered, err := log.Recover(ctx); err != nil || len(recovered) != 0 {
+		t.Fatalf("Recover of a recent transaction = %v, %v", recovered, err)
+	}
+
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(filepath.Dir(txDirs[0]), old, old); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := log.Recover(ctx)
+	if err != nil || len(recovered) != 1 || recovered[0] != "move US-001" {
+		t.Fatalf("Recover = %v, %v", recovered, err)
+	}
+	assertFile(t, source, "story")
+	assertFile(t, target, "")
+	if ops, err := log.Operations(ctx); err != nil || len(ops) != 0 {
+		t.Errorf("rolled back transaction can still be undone: %+v, %v", ops, err)
+	}
+}
+
+func TestRolloverTasks_IsAtomic(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	copyTree(t, filepath.Join("..", "..", "testdata", "site"), repoPath)
+	sprints := filepath.Join(repoPath, "tasks", "sprints")
+	source := filepath.Join(sprints, "!Sprint-02_Checkout")
+	target := filepath.Join(sprints, "Sprint-03_Payments")
+	// US-006 cannot be written to the target sprint
+	writeTestFile(t, filepath.Join(target, "US-006.md", "blocker"), "")
+
+	parser := filesystem.NewMarkdownParser()
+	repo := filesystem.NewRepository(parser)
+	log := filesystem.NewTransactionLog(repoPath, "gitta sprint close")
+	closeService := services.NewSprintCloseServiceWithTransactions(repo, repo, parser, repoPath, core.DefaultWorkflow(), nil, log)
+
+	err := closeService.RolloverTasks(ctx, core.RolloverRequest{
+		SourceSprintPath: source,
+		TargetSprintPath: target,
+		SelectedTaskIDs:  []string{"US-004", "US-006"},
+	})
+	if err == nil {
+		t.Fatal("expected the rollover to fail")
+	}
+	for _, id := range []string{"US-004", "US-006"} {
+		if _, err := os.Stat(filepath.Join(source, id+".md")); err != nil {
+			t.Errorf("%s left the source sprint: %v", id, err)
+		}
+	}
+	assertFile(t, filepath.Join(target, "US-004.md"), "")
+
+	// Without the blocker every task moves, and the rollover can be undone
+	if err := os.RemoveAll(filepath.Join(target, "US-006.md")); err != nil {
+		t.Fatal(err)
+	}
+	err = closeService.RolloverTasks(ctx, core.RolloverRequest{
+		SourceSprintPath: source,
+		TargetSprintPath: target,
+		SelectedTaskIDs:  []string{"US-004", "US-006"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, filepath.Join(source, "US-004.md"), "")
+	if _, err := os.Stat(filepath.Join(target, "US-006.md")); err != nil {
+		t.Errorf("US-006 not rolled over: %v", err)
+	}
+	if _, err := log.Undo(ctx, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, filepath.Join(target, "US-004.md"), "")
+	if _, err := os.Stat(filepath.Join(source, "US-004.md")); err != nil {
+		t.Errorf("US-004 not restored: %v", err)
+	}
+}
+
+func TestUndoService_RecordsActivity(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	story := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	writeTestFile(t, story, "story")
+	if err := stage(t, filesystem.NewTransactionLog(repoPath, "gitta story status US-001 --status doing"), "update US-001", story, "updated", "").Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	log := filesystem.NewTransactionLog(repoPath, "gitta undo")
+	// A pre hook can veto the undo
+	hooks := []services.Hook{{Event: core.EventOperationUndone, Run: "exit 1", Pre: true, Timeout: services.DefaultHookTimeout}}
+	vetoed := services.NewUndoService(log, services.NewHookBus(repoPath, hooks, "", io.Discard))
+	if _, err := vetoed.Undo(ctx, 1, false); !errors.Is(err, core.ErrEventVetoed) {
+		t.Fatalf("vetoed Undo = %v, want ErrEventVetoed", err)
+	}
+	assertFile(t, story, "updated")
+
+	activity := services.NewActivityService(repoPath)
+	undo := services.NewUndoService(log, services.NewActivityJournal(activity, "gitta undo", io.Discard))
+	if undone, err := undo.Undo(ctx, 1, false); err != nil || len(undone) != 1 {
+		t.Fatalf("Undo = %+v, %v", undone, err)
+	}
+	assertFile(t, story, "story")
+	entries, err := activity.List(ctx, services.ActivityFilter{ID: "US-001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Event != core.EventOperationUndone || entries[0].Command != "gitta undo" ||
+		entries[0].Summary() != "undone: gitta story status US-001 --status doing" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestFormatStories_CanBeUndone(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	file := filepath.Join(repoPath, "tasks", "backlog", "US-001.md")
+	original := "---\ntitle: Messy\nid: US-001\ntags: [API, api]\n---\nBody\n"
+	writeTestFile(t, file, original)
+
+	log := filesystem.NewTransactionLog(repoPath, "gitta fmt")
+	format := services.NewFormatServiceWithTransactions(filesystem.NewMarkdownParser(), repoPath, log)
+	results, err := format.FormatStories(ctx, nil, true)
+	if err != nil || len(results) != 1 || !results[0].Changed || results[0].Err != nil {
+		t.Fatalf("FormatStories = %+v, %v", results, err)
+	}
+	assertFile(t, file, results[0].Formatted)
+
+	if _, err := log.Undo(ctx, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, file, original)
+}
+
+func TestRepairInconsistencies_CanBeUndone(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	sprintDir := filepath.Join(repoPath, "sprints", "!Sprint_24_Login")
+	writeTestFile(t, filepath.Join(sprintDir, ".gitta", "status"), "ready\n")
+	writeTestFile(t, filepath.Join(sprintDir, "US-001.md"), "story")
+
+	log := filesystem.NewTransactionLog(repoPath, "gitta doctor --fix")
+	doctor := services.NewSprintDoctorServiceWithTransactions(filesystem.NewDefaultRepository(), repoPath, nil, log)
+	inconsistencies, err := doctor.DetectInconsistencies(ctx)
+	if err != nil || len(inconsistencies) != 1 {
+		t.Fatalf("DetectInconsistencies = %+v, %v", inconsistencies, err)
+	}
+	if result, err := doctor.RepairInconsistencies(ctx, inconsistencies); err != nil || result.RepairedCount != 1 {
+		t.Fatalf("RepairInconsistencies = %+v, %v", result, err)
+	}
+	renamed := filepath.Join(repoPath, "sprints", "+Sprint_24_Login")
+	assertFile(t, filepath.Join(renamed, "US-001.md"), "story")
+	if _, err := os.Stat(sprintDir); !os.IsNotExist(err) {
+		t.Errorf("old sprint folder left behind: %v", err)
+	}
+
+	if _, err := log.Undo(ctx, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, filepath.Join(sprintDir, ".gitta", "status"), "ready\n")
+	assertFile(t, filepath.Join(sprintDir, "US-001.md"), "story")
+	if _, err := os.Stat(renamed); !os.IsNotExist(err) {
+		t.Errorf("renamed sprint folder left behind: %v", err)
+	}
+}
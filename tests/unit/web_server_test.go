@@ -0,0 +1,353 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	gittagit "github.com/gavin/gitta/infra/git"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/ui/web"
+)
+
+// copyTree copies the files under src into dst.
+func copyTree(t *testing.T, src, dst string) {
+	t.Helper()
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		t.Fatalf("failed to copy %s: %v", src, err)
+	}
+}
+
+// webTestServer serves a copy of the testdata/site workspace. With commit,
+// the copy is a Git repository and changes are committed.
+func webTestServer(t *testing.T, commit bool) (*httptest.Server, string) {
+	t.Helper()
+	repoPath := t.TempDir()
+	copyTree(t, filepath.Join("..", "..", "testdata", "site"), repoPath)
+
+	parser := filesystem.NewMarkdownParser()
+	repo := filesystem.NewRepository(parser)
+	workflow := core.DefaultWorkflow()
+	board := services.NewBoardService(repo, repo, noBranchesRepo{}, repoPath, workflow)
+	backlogPath := filepath.Join(repoPath, "tasks", "backlog")
+	var committer core.GitCommitter
+	if commit {
+		gitRepo, err := git.PlainInit(repoPath, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gitCfg, _ := gitRepo.Config()
+		gitCfg.User.Name, gitCfg.User.Email = "Test", "test@example.com"
+		if err := gitRepo.SetConfig(gitCfg); err != nil {
+			t.Fatal(err)
+		}
+		wt, _ := gitRepo.Worktree()
+		if _, err := wt.Add("."); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Commit("init", &git.CommitOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		committer = gittagit.NewRepository()
+	}
+	cfg := web.Config{
+		RepoPath: repoPath,
+		Workflow: workflow,
+		Stories:  repo,
+		Board:    board,
+		Edit: services.NewStoryEditService(parser, repo, board,
+			services.NewCreateService(filesystem.NewIDCounter(repoPath), parser, repo, backlogPath),
+			committer, repoPath, backlogPath, workflow),
+	}
+
+	server := httptest.NewServer(web.NewServer(cfg))
+	t.Cleanup(server.Close)
+	return server, repoPath
+}
+
+// call sends a request with an optional JSON body and decodes the JSON response into out.
+func call(t *testing.T, server *httptest.Server, method, path string, body interface{}, out interface{}) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req, _ := http.NewRequest(method, server.URL+path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: invalid JSON response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+type apiStory struct {
+	ID       string                 `json:"id"`
+	Title    string                 `json:"title"`
+	Status   string                 `json:"status"`
+	Priority string                 `json:"priority"`
+	Assignee *string                `json:"assignee"`
+	Tags     []string               `json:"tags"`
+	Fields   map[string]interface{} `json:"fields"`
+	Location string                 `json:"location"`
+	File     string                 `json:"file"`
+	Body     *string                `json:"body"`
+	Commit   string                 `json:"commit"`
+	Error    string                 `json:"error"`
+}
+
+func TestWebServer_ListAndGetStories(t *testing.T) {
+	server, _ := webTestServer(t, false)
+
+	var all []apiStory
+	if code := call(t, server, "GET", "/api/stories", nil, &all); code != http.StatusOK {
+		t.Fatalf("GET /api/stories = %d", code)
+	}
+	if len(all) != 8 || all[0].ID != "US-001" {
+		t.Fatalf("expected 8 stories sorted by ID, got %+v", all)
+	}
+
+	var current []apiStory
+	call(t, server, "GET", "/api/stories?sprint=current", nil, &current)
+	if len(current) != 3 {
+		t.Errorf("sprint=current: got %+v", current)
+	}
+
+	var doing []apiStory
+	call(t, server, "GET", "/api/stories?status=doing&status=review", nil, &doing)
+	if len(doing) != 2 || doing[0].ID != "US-004" || doing[1].ID != "US-006" {
+		t.Errorf("status filter: got %+v", doing)
+	}
+	var backlog []apiStory
+	call(t, server, "GET", "/api/stories?sprint=backlog&tag=payments", nil, &backlog)
+	if len(backlog) != 1 || backlog[0].ID != "US-010" || backlog[0].Location != "Backlog" {
+		t.Errorf("sprint and tag filter: got %+v", backlog)
+	}
+
+	var story apiStory
+	if code := call(t, server, "GET", "/api/stories/US-004", nil, &story); code != http.StatusOK {
+		t.Fatalf("GET /api/stories/US-004 = %d", code)
+	}
+	if story.Location != "!Sprint-02_Checkout" || story.File != "tasks/sprints/!Sprint-02_Checkout/US-004.md" ||
+		story.Body == nil || !strings.Contains(*story.Body, "## Acceptance Criteria") || story.Fields["epic"] != "checkout" {
+		t.Errorf("unexpected story: %+v", story)
+	}
+
+	var missing apiStory
+	if code := call(t, server, "GET", "/api/stories/US-999", nil, &missing); code != http.StatusNotFound || missing.Error == "" {
+		t.Errorf("GET missing story = %d %q, want 404 with an error", code, missing.Error)
+	}
+}
+
+func TestWebServer_Sprints(t *testing.T) {
+	server, _ := webTestServer(t, false)
+
+	var sprints []struct {
+		Name   string `json:"name"`
+		Title  string `json:"title"`
+		Status string `json:"status"`
+		Total  int    `json:"total"`
+		Done   int    `json:"done"`
+	}
+	call(t, server, "GET", "/api/sprints", nil, &sprints)
+	if len(sprints) != 2 {
+		t.Fatalf("expected 2 sprints, got %+v", sprints)
+	}
+
+	var current struct {
+		Name    string     `json:"name"`
+		Status  string     `json:"status"`
+		Done    int        `json:"done"`
+		Stories []apiStory `json:"stories"`
+	}
+	if code := call(t, server, "GET", "/api/sprints/current", nil, &current); code != http.StatusOK {
+		t.Fatalf("GET /api/sprints/current = %d", code)
+	}
+	if current.Name != "!Sprint-02_Checkout" || current.Status != "active" || current.Done != 1 || len(current.Stories) != 3 {
+		t.Errorf("unexpected current sprint: %+v", current)
+	}
+	if code := call(t, server, "GET", "/api/sprints/Sprint-01_Login", nil, nil); code != http.StatusOK {
+		t.Errorf("sprints must be found by title, got %d", code)
+	}
+	if code := call(t, server, "GET", "/api/sprints/nope/burndown", nil, nil); code != http.StatusNotFound {
+		t.Errorf("GET unknown sprint burndown = %d, want 404", code)
+	}
+}
+
+func TestWebServer_CreateUpdateMove(t *testing.T) {
+	server, repoPath := webTestServer(t, false)
+
+	var created apiStory
+	code := call(t, server, "POST", "/api/stories", map[string]interface{}{
+		"title": "Export orders", "priority": "high", "tags": []string{"api"}, "body": "## Description\n\nCSV export.\n",
+	}, &created)
+	if code != http.StatusCreated {
+		t.Fatalf("POST /api/stories = %d %s", code, created.Error)
+	}
+	if created.ID == "" || created.Status != "todo" || created.Priority != "high" || created.Location != "Backlog" || *created.Body != "## Description\n\nCSV export.\n" {
+		t.Errorf("unexpected created story: %+v", created)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(created.File))); err != nil {
+		t.Errorf("story file not written: %v", err)
+	}
+
+	var updated apiStory
+	code = call(t, server, "PATCH", "/api/stories/"+created.ID, map[string]interface{}{
+		"status": "doing", "assignee": "dana", "fields": map[string]interface{}{"points": 3},
+	}, &updated)
+	if code != http.StatusOK || updated.Status != "doing" || updated.Assignee == nil || *updated.Assignee != "dana" || updated.Fields["points"] != float64(3) {
+		t.Errorf("PATCH = %d %+v", code, updated)
+	}
+
+	// The workflow does not allow todo -> done
+	var rejected apiStory
+	if code := call(t, server, "PATCH", "/api/stories/US-010", map[string]interface{}{"status": "done"}, &rejected); code != http.StatusConflict {
+		t.Errorf("invalid transition = %d %q, want 409", code, rejected.Error)
+	}
+	if code := call(t, server, "PATCH", "/api/stories/US-010", map[string]interface{}{"status": "done", "force": true}, nil); code != http.StatusOK {
+		t.Errorf("forced transition = %d, want 200", code)
+	}
+	if code := call(t, server, "PATCH", "/api/stories/US-010", map[string]interface{}{"priority": "urgent"}, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid priority = %d, want 400", code)
+	}
+
+	var moved apiStory
+	code = call(t, server, "POST", "/api/stories/"+created.ID+"/move", map[string]interface{}{"to": "Sprint-02_Checkout"}, &moved)
+	if code != http.StatusOK || moved.Location != "!Sprint-02_Checkout" {
+		t.Errorf("move = %d %+v", code, moved)
+	}
+	code = call(t, server, "POST", "/api/stories/US-004/move", map[string]interface{}{"to": "backlog"}, &moved)
+	if code != http.StatusOK || moved.File != "tasks/backlog/US-004.md" {
+		t.Errorf("move to backlog = %d %+v", code, moved)
+	}
+	if code := call(t, server, "POST", "/api/stories/US-010/move", map[string]interface{}{"to": "/tmp"}, nil); code != http.StatusBadRequest {
+		t.Errorf("absolute move target = %d, want 400", code)
+	}
+
+	// Plain form posts are rejected
+	resp, err := http.Post(server.URL+"/api/stories", "application/x-www-form-urlencoded", strings.NewReader("title=x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("form post = %d, want 415", resp.StatusCode)
+	}
+}
+
+func TestWebServer_CommitsChanges(t *testing.T) {
+	server, repoPath := webTestServer(t, true)
+
+	var updated apiStory
+	call(t, server, "PATCH", "/api/stories/US-004", map[string]interface{}{"status": "review"}, &updated)
+	if updated.Commit == "" {
+		t.Fatalf("expected a commit, got %+v", updated)
+	}
+	var moved apiStory
+	call(t, server, "POST", "/api/stories/US-012/move", map[string]interface{}{"to": "Sprint-02_Checkout"}, &moved)
+
+	repo, _ := git.PlainOpen(repoPath)
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.Hash.String() != moved.Commit || commit.Message != "Move US-012 to Sprint-02_Checkout" {
+		t.Errorf("HEAD = %s %q, want the move commit %s", commit.Hash, commit.Message, moved.Commit)
+	}
+	parent, _ := commit.Parent(0)
+	if parent.Hash.String() != updated.Commit || parent.Message != "Set US-004 status to review" {
+		t.Errorf("HEAD~1 = %s %q, want the status commit %s", parent.Hash, parent.Message, updated.Commit)
+	}
+	wt, _ := repo.Worktree()
+	status, _ := wt.Status()
+	for file, s := range status {
+		if strings.Contains(file, "US-012") || strings.Contains(file, "US-004") {
+			t.Errorf("%s left uncommitted (%c%c)", file, s.Staging, s.Worktree)
+		}
+	}
+}
+
+func TestWebServer_ConcurrentUpdates(t *testing.T) {
+	server, repoPath := webTestServer(t, false)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tags := []string{"t" + string(rune('a'+i))}
+			call(t, server, "PATCH", "/api/stories/US-010", map[string]interface{}{"tags": tags}, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	story, err := filesystem.NewMarkdownParser().ReadStory(t.Context(), filepath.Join(repoPath, "tasks", "backlog", "US-010.md"))
+	if err != nil {
+		t.Fatalf("story file corrupted by concurrent writes: %v", err)
+	}
+	if len(story.Tags) != 1 {
+		t.Errorf("expected the tags of one request, got %v", story.Tags)
+	}
+}
+
+func TestWebServer_BoardAndOpenAPI(t *testing.T) {
+	server, _ := webTestServer(t, false)
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page), `<script src="app.js">`) {
+		t.Errorf("GET / = %d:\n%s", resp.StatusCode, page)
+	}
+
+	var spec struct {
+		OpenAPI string                 `json:"openapi"`
+		Paths   map[string]interface{} `json:"paths"`
+	}
+	call(t, server, "GET", "/api/openapi.json", nil, &spec)
+	for _, path := range []string{"/api/stories", "/api/stories/{id}", "/api/stories/{id}/move", "/api/sprints", "/api/sprints/{name}/burndown"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("OpenAPI document missing %s", path)
+		}
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("unexpected openapi version %q", spec.OpenAPI)
+	}
+}
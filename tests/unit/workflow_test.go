@@ -0,0 +1,229 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gavin/gitta/infra/filesystem"
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/ui/tui"
+)
+
+const workflowConfig = `workflow:
+  states:
+    - {name: todo, transitions: [ready, blocked, wontfix]}
+    - {name: ready, transitions: [doing, blocked]}
+    - {name: doing, derive: branch}
+    - {name: review, derive: pushed}
+    - {name: qa}
+    - {name: done, derive: merged}
+    - {name: blocked, derive: label, label: blocked}
+    - {name: wontfix, category: done}
+`
+
+func TestLoadProjectConfig_Workflow(t *testing.T) {
+	cfg := loadTestConfig(t, workflowConfig)
+	wf := cfg.Workflow
+
+	if got := wf.String(); got != "todo, ready, doing, review, qa, done, blocked, wontfix" {
+		t.Errorf("states = %s", got)
+	}
+	if wf.Initial != core.StatusTodo {
+		t.Errorf("initial = %s, want todo", wf.Initial)
+	}
+
+	categories := map[core.Status]core.StatusCategory{
+		"todo":    core.CategoryTodo,
+		"ready":   core.CategoryInProgress,
+		"qa":      core.CategoryInProgress,
+		"done":    core.CategoryDone,
+		"wontfix": core.CategoryDone,
+	}
+	for name, want := range categories {
+		if state, _ := wf.State(name); state.Category != want {
+			t.Errorf("%s category = %s, want %s", name, state.Category, want)
+		}
+	}
+
+	if !wf.CanTransition("todo", "ready") || wf.CanTransition("todo", "done") {
+		t.Error("todo transitions not enforced")
+	}
+	if !wf.CanTransition("qa", "todo") {
+		t.Error("states without transitions must allow any move")
+	}
+}
+
+func TestLoadProjectConfig_DefaultWorkflow(t *testing.T) {
+	cfg, err := services.LoadProjectConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if got := cfg.Workflow.String(); got != "todo, doing, review, done" {
+		t.Errorf("default workflow = %s", got)
+	}
+}
+
+func TestLoadProjectConfig_InvalidWorkflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"no states", "workflow:\n  states: []\n"},
+		{"duplicate state", "workflow:\n  states: [{name: todo}, {name: todo}, {name: done, category: done}]\n"},
+		{"unknown derive", "workflow:\n  states: [{name: todo, derive: commit}, {name: done, category: done}]\n"},
+		{"label without label", "workflow:\n  states: [{name: todo}, {name: blocked, derive: label}, {name: done, category: done}]\n"},
+		{"duplicate derive", "workflow:\n  states: [{name: todo}, {name: a, derive: branch}, {name: b, derive: branch}, {name: done, category: done}]\n"},
+		{"undeclared transition", "workflow:\n  states: [{name: todo, transitions: [doing]}, {name: done, category: done}]\n"},
+		{"unknown initial", "workflow:\n  initial: new\n  states: [{name: todo}, {name: done, category: done}]\n"},
+		{"no done state", "workflow:\n  states: [{name: todo}, {name: doing}]\n"},
+		{"invalid name", "workflow:\n  states: [{name: In Progress}, {name: done, category: done}]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			writeProjectConfig(t, repoPath, tt.config)
+			if _, err := services.LoadProjectConfig(repoPath); !errors.Is(err, services.ErrInvalidConfig) {
+				t.Errorf("error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestStatusEngine_WorkflowDerivation(t *testing.T) {
+	wf := loadTestConfig(t, workflowConfig).Workflow
+	engine := services.NewStatusEngineWithWorkflow(nil, wf)
+
+	local := core.Branch{Name: "feat/US-001", Type: core.BranchTypeLocal}
+	remote := core.Branch{Name: "feat/US-001", Type: core.BranchTypeRemote}
+
+	tests := []struct {
+		name     string
+		story    core.Story
+		branches []core.Branch
+		want     core.Status
+	}{
+		{"explicit status wins", core.Story{ID: "US-001", Status: "qa"}, []core.Branch{local}, "qa"},
+		{"defaulted status is derived", core.Story{ID: "US-001", Status: "todo", StatusDefaulted: true}, []core.Branch{local}, "doing"},
+		{"status outside workflow is derived", core.Story{ID: "US-001", Status: "unknown"}, nil, "todo"},
+		{"label rule", core.Story{ID: "US-001", Tags: []string{"blocked"}}, []core.Branch{local}, "blocked"},
+		{"no branch uses initial state", core.Story{ID: "US-001"}, nil, "todo"},
+		{"pushed branch", core.Story{ID: "US-001"}, []core.Branch{local, remote}, "review"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			story := tt.story
+			got, err := engine.DeriveStatus(context.Background(), &story, tt.branches, t.TempDir())
+			if err != nil {
+				t.Fatalf("DeriveStatus() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DeriveStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateStoryWithRules_Workflow(t *testing.T) {
+	rules := loadTestConfig(t, workflowConfig).ValidationRules()
+
+	story := &core.Story{ID: "US-001", Title: "Story", Status: "qa"}
+	if errs := services.ValidateStoryWithRules(story, rules); len(errs) != 0 {
+		t.Errorf("workflow state rejected: %v", errs)
+	}
+	if errs := services.ValidateStory(story); len(errs) != 1 || errs[0].Field != "status" {
+		t.Errorf("default workflow must reject qa, got %v", errs)
+	}
+
+	story.Status = "archived"
+	errs := services.ValidateStoryWithRules(story, rules)
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "wontfix") {
+		t.Errorf("errors = %v, want status error listing workflow states", errs)
+	}
+}
+
+func TestUpdateService_WorkflowTransitions(t *testing.T) {
+	cfg := loadTestConfig(t, workflowConfig)
+	tmpDir := t.TempDir()
+	backlogDir := filepath.Join(tmpDir, "backlog")
+	if err := os.MkdirAll(backlogDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	filePath := createTestStory(t, backlogDir, "US-001", "Story", core.StatusTodo)
+
+	parser := filesystem.NewMarkdownParserWithRules(cfg.ValidationRules())
+	update := services.NewUpdateServiceWithWorkflow(parser, filesystem.NewRepository(parser), tmpDir, cfg.Workflow)
+	ctx := context.Background()
+
+	if err := update.UpdateStatus(ctx, "US-001", "qa"); !errors.Is(err, services.ErrInvalidTransition) {
+		t.Errorf("todo → qa error = %v, want ErrInvalidTransition", err)
+	}
+	if err := update.UpdateStatus(ctx, "US-001", "ready"); err != nil {
+		t.Fatalf("todo → ready error = %v", err)
+	}
+	if err := update.UpdateStatus(ctx, "US-001", "shipped"); err == nil {
+		t.Error("expected error for status outside the workflow")
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "status: ready") {
+		t.Errorf("story file not updated:\n%s", data)
+	}
+}
+
+func TestMarkdownParser_DefaultedStatusStaysImplicit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "US-001.md")
+	if err := os.WriteFile(file, []byte("---\nid: US-001\ntitle: Story\n---\n\nBody\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := filesystem.NewMarkdownParser()
+	ctx := context.Background()
+	story, err := parser.ReadStory(ctx, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Status != core.StatusTodo || !story.StatusDefaulted {
+		t.Fatalf("status = %s (defaulted %v), want defaulted todo", story.Status, story.StatusDefaulted)
+	}
+	if err := parser.WriteStory(ctx, file, story); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(file); strings.Contains(string(data), "status:") {
+		t.Errorf("defaulted status was written:\n%s", data)
+	}
+}
+
+func TestNewWorkflowBoard(t *testing.T) {
+	wf := loadTestConfig(t, workflowConfig).Workflow
+	board := tui.NewWorkflowBoard(wf, []tui.Task{
+		{ID: "US-001", Status: "ready"},
+		{ID: "US-002", Status: "wontfix"},
+		{ID: "US-003", Status: "todo"},
+		{ID: "US-004", Status: "unknown"},
+		{ID: "US-005", Status: "qa"},
+	})
+
+	want := [3][]string{{"US-003", "US-004"}, {"US-001", "US-005"}, {"US-002"}}
+	for i, col := range board.Columns {
+		var ids []string
+		for _, task := range col.Tasks {
+			ids = append(ids, task.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(want[i], ",") {
+			t.Errorf("column %d (%s) = %v, want %v", i, col.Title, ids, want[i])
+		}
+	}
+	if board.Columns[2].Title != "Done (done, wontfix)" {
+		t.Errorf("done column title = %q", board.Columns[2].Title)
+	}
+}
@@ -0,0 +1,146 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/gavin/gitta/internal/services"
+)
+
+// document is an open text document.
+type document struct {
+	uri     string
+	path    string
+	version int
+	text    string
+}
+
+// lines splits the document into lines without line terminators.
+func (d *document) lines() []string {
+	return strings.Split(strings.ReplaceAll(d.text, "\r\n", "\n"), "\n")
+}
+
+// line returns the given zero-based line, or "" when out of range.
+func (d *document) line(n int) string {
+	lines := d.lines()
+	if n < 0 || n >= len(lines) {
+		return ""
+	}
+	return lines[n]
+}
+
+// frontmatterEnd returns the zero-based line of the closing --- delimiter, or
+// -1 when the document has no complete frontmatter block.
+func frontmatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return -1
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return i
+		}
+	}
+	return -1
+}
+
+// byteOffset converts a UTF-16 character offset within line to a byte offset.
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// character converts a byte offset within line to a UTF-16 character offset.
+func character(line string, offset int) int {
+	if offset > len(line) {
+		offset = len(line)
+	}
+	units := 0
+	for _, r := range line[:offset] {
+		units += utf16.RuneLen(r)
+	}
+	return units
+}
+
+// lineRange returns the range covering the whole given line.
+func lineRange(lines []string, n int) Range {
+	if n < 0 || n >= len(lines) {
+		return Range{Start: Position{Line: n}, End: Position{Line: n}}
+	}
+	return Range{Start: Position{Line: n}, End: Position{Line: n, Character: character(lines[n], len(lines[n]))}}
+}
+
+var (
+	// keyPattern matches a top-level frontmatter key and the start of its value.
+	keyPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*):[ \t]*(.*)$`)
+	// listItemPattern matches a block sequence item and the start of its value.
+	listItemPattern = regexp.MustCompile(`^[ \t]+-[ \t]*(.*)$`)
+)
+
+// storyRefAt returns the story ID at the given position with its range.
+func storyRefAt(line string, lineNo, char int) (string, Range, bool) {
+	offset := byteOffset(line, char)
+	for _, m := range services.FindStoryIDs(line) {
+		if offset >= m[0] && offset <= m[1] {
+			return line[m[0]:m[1]], Range{
+				Start: Position{Line: lineNo, Character: character(line, m[0])},
+				End:   Position{Line: lineNo, Character: character(line, m[1])},
+			}, true
+		}
+	}
+	return "", Range{}, false
+}
+
+// valueContext describes the frontmatter value being typed at a position.
+type valueContext struct {
+	// Key is the frontmatter key the value belongs to.
+	Key string
+	// Prefix is the part of the value before the cursor.
+	Prefix string
+	// Start is the byte offset in the line where the value being typed begins.
+	Start int
+}
+
+// frontmatterValueAt returns the frontmatter value being typed at the given
+// position. It handles scalar values (status: do|), flow sequences
+// (tags: [api, we|]) and block sequence items (- US-1|).
+func frontmatterValueAt(lines []string, lineNo, char int) (valueContext, bool) {
+	end := frontmatterEnd(lines)
+	if end < 0 || lineNo <= 0 || lineNo >= end {
+		return valueContext{}, false
+	}
+	line := lines[lineNo]
+	before := line[:byteOffset(line, char)]
+
+	if m := keyPattern.FindStringSubmatchIndex(before); m != nil {
+		key := before[m[2]:m[3]]
+		start := m[4]
+		value := before[start:]
+		if strings.HasPrefix(value, "[") {
+			// Flow sequence: complete the item after the last separator.
+			start += strings.LastIndexAny(value, "[,") + 1
+			for start < len(before) && before[start] == ' ' {
+				start++
+			}
+		}
+		return valueContext{Key: key, Prefix: before[start:], Start: start}, true
+	}
+
+	if m := listItemPattern.FindStringSubmatchIndex(before); m != nil {
+		for i := lineNo - 1; i > 0; i-- {
+			if k := keyPattern.FindStringSubmatch(lines[i]); k != nil {
+				return valueContext{Key: k[1], Prefix: before[m[2]:], Start: m[2]}, true
+			}
+			if !listItemPattern.MatchString(lines[i]) && strings.TrimSpace(lines[i]) != "" {
+				break
+			}
+		}
+	}
+	return valueContext{}, false
+}
@@ -0,0 +1,77 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// maxMessageSize bounds a single message; story files are small.
+const maxMessageSize = 64 << 20
+
+// conn reads and writes LSP base protocol messages: a Content-Length header
+// followed by a JSON body.
+type conn struct {
+	in *textproto.Reader
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{in: textproto.NewReader(bufio.NewReader(r)), out: w}
+}
+
+// read returns the body of the next message.
+func (c *conn) read() ([]byte, error) {
+	header, err := c.in.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 || length > maxMessageSize {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.in.R, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// write sends v as one message.
+func (c *conn) write(v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.out.Write(body)
+	return err
+}
+
+// reply sends the response to a request.
+func (c *conn) reply(id json.RawMessage, result interface{}, rerr *rpcError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rerr}
+	if rerr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = data
+	}
+	return c.write(resp)
+}
+
+// notify sends a notification.
+func (c *conn) notify(method string, params interface{}) error {
+	return c.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
@@ -0,0 +1,460 @@
+// Package lsp implements a Language Server Protocol server for story Markdown
+// files over stdio: lint diagnostics, frontmatter completion, and hover and
+// go-to-definition for story IDs.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// ErrExitWithoutShutdown is returned by Serve when the client sends exit
+// without a shutdown request first.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown")
+
+// dependencyFields are the frontmatter fields whose values are story IDs.
+var dependencyFields = map[string]bool{
+	"depends_on": true,
+	"blocked_by": true,
+	"blocks":     true,
+}
+
+// Config holds the services the server delegates to.
+type Config struct {
+	// RepoPath is the repository root.
+	RepoPath string
+	// StoryDirs limits diagnostics to documents under these directories (the
+	// backlog and sprints); all Markdown documents are checked when empty.
+	StoryDirs []string
+	// Workflow lists the statuses offered for completion.
+	Workflow core.Workflow
+	// Fields are the custom field definitions; enum and list values are
+	// offered for completion.
+	Fields  []core.FieldDefinition
+	Lint    services.LintService
+	Stories core.StoryRepository
+	Board   services.BoardService
+	// Version is reported to the client in serverInfo.
+	Version string
+}
+
+// Server is a language server for one client connection.
+type Server struct {
+	cfg  Config
+	conn *conn
+	docs map[string]*document
+	// snapshot caches the workspace for completion and hover; it is dropped
+	// when a story is saved or changed on disk.
+	snapshot *services.WorkspaceSnapshot
+	shutdown bool
+}
+
+// NewServer creates a Server.
+func NewServer(cfg Config) *Server {
+	return &Server{cfg: cfg, docs: make(map[string]*document)}
+}
+
+// Serve handles messages from r and writes responses to w until the client
+// sends exit or closes r. Messages are handled one at a time, in order.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		body, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.conn.reply(json.RawMessage("null"), nil, &rpcError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		if req.ID == nil {
+			s.handleNotification(ctx, req)
+			continue
+		}
+		result, rerr := s.handleRequest(ctx, req)
+		if err := s.conn.reply(*req.ID, result, rerr); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+}
+
+// handleRequest dispatches a request and returns its result.
+func (s *Server) handleRequest(ctx context.Context, req request) (interface{}, *rpcError) {
+	if s.shutdown {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:   TextDocumentSyncOptions{OpenClose: true, Change: TextDocumentSyncKindFull, Save: true},
+				CompletionProvider: CompletionOptions{TriggerCharacters: []string{" ", ",", "[", "-"}},
+				HoverProvider:      true,
+				DefinitionProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "gitta", Version: s.cfg.Version},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.completion(ctx, params), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		if hover := s.hover(ctx, params); hover != nil {
+			return hover, nil
+		}
+		return nil, nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		location, err := s.definition(ctx, params)
+		if err != nil {
+			return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		if location != nil {
+			return location, nil
+		}
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", req.Method)}
+}
+
+// handleNotification applies a notification; unknown ones are ignored.
+func (s *Server) handleNotification(ctx context.Context, req request) {
+	switch req.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			doc := &document{uri: params.TextDocument.URI, path: uriToPath(params.TextDocument.URI), version: params.TextDocument.Version, text: params.TextDocument.Text}
+			s.docs[doc.uri] = doc
+			s.publishDiagnostics(ctx, doc)
+		}
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			if doc, ok := s.docs[params.TextDocument.URI]; ok {
+				doc.version = params.TextDocument.Version
+				doc.text = params.ContentChanges[len(params.ContentChanges)-1].Text
+				s.publishDiagnostics(ctx, doc)
+			}
+		}
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.snapshot = nil
+			if doc, ok := s.docs[params.TextDocument.URI]; ok {
+				if params.Text != nil {
+					doc.text = *params.Text
+				}
+				s.publishDiagnostics(ctx, doc)
+			}
+		}
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			delete(s.docs, params.TextDocument.URI)
+			s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+		}
+	case "workspace/didChangeWatchedFiles":
+		s.snapshot = nil
+	}
+}
+
+// publishDiagnostics lints a story document and sends its diagnostics.
+func (s *Server) publishDiagnostics(ctx context.Context, doc *document) {
+	if !s.isStory(doc.path) {
+		return
+	}
+	lines := doc.lines()
+	diagnostics := []Diagnostic{}
+	for _, d := range s.cfg.Lint.LintContent(ctx, doc.path, []byte(doc.text)) {
+		line := d.Line - 1
+		if line < 0 {
+			line = 0
+		}
+		severity := SeverityError
+		if d.Severity == services.SeverityWarning {
+			severity = SeverityWarning
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    lineRange(lines, line),
+			Severity: severity,
+			Code:     d.Rule,
+			Source:   "gitta",
+			Message:  d.Message,
+		})
+	}
+	version := doc.version
+	s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: doc.uri, Version: &version, Diagnostics: diagnostics})
+}
+
+// isStory reports whether path is a Markdown file in a story directory.
+func (s *Server) isStory(path string) bool {
+	if path == "" || !strings.EqualFold(filepath.Ext(path), ".md") {
+		return false
+	}
+	if len(s.cfg.StoryDirs) == 0 {
+		return true
+	}
+	for _, dir := range s.cfg.StoryDirs {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSnapshot returns the cached workspace snapshot, reading it if needed.
+func (s *Server) loadSnapshot(ctx context.Context) *services.WorkspaceSnapshot {
+	if s.snapshot == nil && s.cfg.Board != nil {
+		snapshot, err := s.cfg.Board.Snapshot(ctx)
+		if err != nil {
+			return nil
+		}
+		s.snapshot = snapshot
+	}
+	return s.snapshot
+}
+
+// completion offers values for the frontmatter field at the cursor.
+func (s *Server) completion(ctx context.Context, params TextDocumentPositionParams) CompletionList {
+	list := CompletionList{Items: []CompletionItem{}}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return list
+	}
+	lines := doc.lines()
+	value, ok := frontmatterValueAt(lines, params.Position.Line, params.Position.Character)
+	if !ok {
+		return list
+	}
+
+	line := lines[params.Position.Line]
+	edit := Range{
+		Start: Position{Line: params.Position.Line, Character: character(line, value.Start)},
+		End:   params.Position,
+	}
+	for _, item := range s.valuesFor(ctx, value.Key, lines) {
+		item.FilterText = item.Label
+		item.TextEdit = &TextEdit{Range: edit, NewText: item.Label}
+		list.Items = append(list.Items, item)
+	}
+	return list
+}
+
+// valuesFor returns the completion values for a frontmatter key.
+func (s *Server) valuesFor(ctx context.Context, key string, lines []string) []CompletionItem {
+	var items []CompletionItem
+	add := func(label string, kind int, detail string) {
+		items = append(items, CompletionItem{Label: label, Kind: kind, Detail: detail})
+	}
+
+	switch {
+	case key == "status":
+		for _, state := range s.cfg.Workflow.States {
+			add(string(state.Name), CompletionKindEnumMember, string(state.Category))
+		}
+	case key == "priority":
+		for _, p := range []core.Priority{core.PriorityLow, core.PriorityMedium, core.PriorityHigh, core.PriorityCritical} {
+			add(string(p), CompletionKindEnumMember, "priority")
+		}
+	case key == "assignee" || key == "tags":
+		snapshot := s.loadSnapshot(ctx)
+		if snapshot == nil {
+			return nil
+		}
+		seen := make(map[string]bool)
+		for _, story := range snapshot.Stories() {
+			values := story.Story.Tags
+			if key == "assignee" {
+				values = nil
+				if story.Story.Assignee != nil {
+					values = []string{*story.Story.Assignee}
+				}
+			}
+			for _, v := range values {
+				seen[v] = true
+			}
+		}
+		for _, v := range sortedKeys(seen) {
+			add(v, CompletionKindValue, key)
+		}
+	case dependencyFields[key]:
+		snapshot := s.loadSnapshot(ctx)
+		if snapshot == nil {
+			return nil
+		}
+		self := frontmatterValue(lines, "id")
+		for _, story := range sortedStories(snapshot.Stories()) {
+			if story.Story.ID == self {
+				continue
+			}
+			items = append(items, CompletionItem{
+				Label:         story.Story.ID,
+				Kind:          CompletionKindReference,
+				Detail:        story.Story.Title,
+				Documentation: &MarkupContent{Kind: "markdown", Value: storySummary(story)},
+			})
+		}
+	default:
+		for _, def := range s.cfg.Fields {
+			if def.Name == key && (def.Type == core.FieldTypeEnum || def.Type == core.FieldTypeList) {
+				for _, v := range def.Values {
+					add(v, CompletionKindEnumMember, def.Name)
+				}
+			}
+		}
+	}
+	return items
+}
+
+// hover describes the story ID at the cursor.
+func (s *Server) hover(ctx context.Context, params TextDocumentPositionParams) *Hover {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	id, rng, ok := storyRefAt(doc.line(params.Position.Line), params.Position.Line, params.Position.Character)
+	if !ok {
+		return nil
+	}
+	snapshot := s.loadSnapshot(ctx)
+	if snapshot == nil {
+		return nil
+	}
+	for _, story := range snapshot.Stories() {
+		if story.Story.ID == id {
+			return &Hover{Contents: MarkupContent{Kind: "markdown", Value: storySummary(story)}, Range: &rng}
+		}
+	}
+	return nil
+}
+
+// definition returns the file of the story ID at the cursor.
+func (s *Server) definition(ctx context.Context, params TextDocumentPositionParams) (*Location, error) {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	id, _, ok := storyRefAt(doc.line(params.Position.Line), params.Position.Line, params.Position.Character)
+	if !ok {
+		return nil, nil
+	}
+	_, path, err := s.cfg.Stories.FindStoryByID(ctx, s.cfg.RepoPath, id)
+	if err != nil {
+		if errors.Is(err, core.ErrStoryNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &Location{URI: pathToURI(path)}, nil
+}
+
+// storySummary formats a story for hovers and completion documentation.
+func storySummary(story *services.StoryWithStatus) string {
+	status := string(story.Status)
+	switch {
+	case story.Story.StatusDefaulted:
+		status += " (from Git)"
+	case story.Drifted():
+		status += fmt.Sprintf(" (Git: %s)", story.Derived)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** %s\n\n", story.Story.ID, story.Story.Title)
+	fmt.Fprintf(&b, "Status: %s · Priority: %s", status, story.Story.Priority)
+	if story.Story.Assignee != nil {
+		fmt.Fprintf(&b, " · Assignee: %s", *story.Story.Assignee)
+	}
+	fmt.Fprintf(&b, "\n\n%s", services.SprintTitle(story.Source))
+	return b.String()
+}
+
+// frontmatterValue returns the scalar value of a top-level frontmatter key.
+func frontmatterValue(lines []string, key string) string {
+	end := frontmatterEnd(lines)
+	for i := 1; i < end; i++ {
+		if m := keyPattern.FindStringSubmatch(lines[i]); m != nil && m[1] == key {
+			return strings.Trim(strings.TrimSpace(m[2]), `"'`)
+		}
+	}
+	return ""
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStories(stories []*services.StoryWithStatus) []*services.StoryWithStatus {
+	sort.SliceStable(stories, func(i, j int) bool {
+		return stories[i].Story.ID < stories[j].Story.ID
+	})
+	return stories
+}
+
+// uriToPath converts a file URI to a local path; other URIs yield "".
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path)
+}
+
+// pathToURI converts a local path to a file URI.
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// conn reads and writes newline-delimited JSON-RPC messages, the MCP stdio
+// transport.
+type conn struct {
+	in *bufio.Reader
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{in: bufio.NewReader(r), out: w}
+}
+
+// read returns the next non-empty line.
+func (c *conn) read() ([]byte, error) {
+	for {
+		line, err := c.in.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// reply sends the response to a request. JSON encoding never emits raw
+// newlines, so each message stays on one line.
+func (c *conn) reply(id json.RawMessage, result interface{}, rerr *rpcError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rerr}
+	if rerr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = data
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.out.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// storyURIPrefix prefixes story resource URIs; the story ID follows.
+const storyURIPrefix = "gitta://stories/"
+
+// storyTemplate describes the story file resources.
+var storyTemplate = ResourceTemplate{
+	URITemplate: storyURIPrefix + "{id}",
+	Name:        "story",
+	Title:       "Story file",
+	Description: "Markdown story file with YAML frontmatter",
+	MimeType:    "text/markdown",
+}
+
+// listResources lists every story file.
+func (s *Server) listResources(ctx context.Context) (interface{}, *rpcError) {
+	snapshot, err := s.cfg.Board.Snapshot(ctx)
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	stories, _ := snapshot.Filter(services.StoryFilter{})
+	out := ListResourcesResult{Resources: make([]Resource, 0, len(stories))}
+	for _, story := range stories {
+		out.Resources = append(out.Resources, Resource{
+			URI:         storyURIPrefix + story.Story.ID,
+			Name:        story.Story.ID,
+			Title:       story.Story.Title,
+			Description: fmt.Sprintf("%s, %s", story.Status, services.SprintTitle(story.Source)),
+			MimeType:    "text/markdown",
+		})
+	}
+	return out, nil
+}
+
+// readResource returns the content of a story file.
+func (s *Server) readResource(ctx context.Context, uri string) (interface{}, *rpcError) {
+	id, ok := strings.CutPrefix(uri, storyURIPrefix)
+	if !ok || id == "" {
+		return nil, &rpcError{Code: codeResourceNotFound, Message: fmt.Sprintf("resource not found: %s", uri)}
+	}
+	_, path, err := s.cfg.Stories.FindStoryByID(ctx, s.cfg.RepoPath, id)
+	if err != nil {
+		if errors.Is(err, core.ErrStoryNotFound) || errors.Is(err, core.ErrInvalidPath) {
+			return nil, &rpcError{Code: codeResourceNotFound, Message: fmt.Sprintf("resource not found: %s", uri)}
+		}
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	return ReadResourceResult{Contents: []ResourceContents{{URI: uri, MimeType: "text/markdown", Text: string(data)}}}, nil
+}
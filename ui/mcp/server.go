@@ -0,0 +1,166 @@
+// Package mcp implements a Model Context Protocol server over stdio that lets
+// coding assistants list, read and change stories through gitta's services.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+// instructions tell the model how to use the server.
+const instructions = `Stories are Markdown files with YAML frontmatter managed by gitta. ` +
+	`Use the tools to list, search, create, update and move stories instead of editing frontmatter by hand; ` +
+	`they validate values and respect the workflow. Story files are also available as gitta://stories/{id} resources.`
+
+// Config holds the services the server delegates to.
+type Config struct {
+	// RepoPath is the repository root.
+	RepoPath string
+	// Workflow lists the valid statuses.
+	Workflow core.Workflow
+	// Fields are the project's custom field definitions for new stories.
+	Fields  []core.FieldDefinition
+	Stories core.StoryRepository
+	Board   services.BoardService
+	// Edit applies and optionally commits story changes.
+	Edit  services.StoryEditService
+	Start services.StartService
+	// Burndown generates sprint burndowns; nil disables the burndown tool.
+	Burndown services.SprintBurndownService
+	// Version is reported to the client in serverInfo.
+	Version string
+}
+
+// Server is an MCP server for one client connection.
+type Server struct {
+	cfg         Config
+	conn        *conn
+	initialized bool
+}
+
+// NewServer creates a Server.
+func NewServer(cfg Config) *Server {
+	return &Server{cfg: cfg}
+}
+
+// Serve handles messages from r and writes responses to w until r is closed.
+// Messages are handled one at a time, in order.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			code := codeParseError
+			if json.Valid(line) {
+				code = codeInvalidRequest
+			}
+			if err := s.conn.reply(json.RawMessage("null"), nil, &rpcError{Code: code, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.ID == nil {
+			// Notifications (initialized, cancelled, ...) need no action.
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			if err := s.conn.reply(*req.ID, nil, &rpcError{Code: codeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, rerr := s.handleRequest(ctx, req)
+		if err := s.conn.reply(*req.ID, result, rerr); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+}
+
+// handleRequest dispatches a request and returns its result.
+func (s *Server) handleRequest(ctx context.Context, req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		s.initialized = true
+		return InitializeResult{
+			ProtocolVersion: negotiateVersion(params.ProtocolVersion),
+			Capabilities: ServerCapabilities{
+				Tools:     &ListChangedCapability{},
+				Resources: &ResourcesCapability{},
+			},
+			ServerInfo:   Implementation{Name: "gitta", Title: "gitta task workspace", Version: s.cfg.Version},
+			Instructions: instructions,
+		}, nil
+	case "ping":
+		return struct{}{}, nil
+	}
+
+	if !s.initialized {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "server not initialized"}
+	}
+
+	switch req.Method {
+	case "tools/list":
+		return ListToolsResult{Tools: s.tools()}, nil
+	case "tools/call":
+		var params CallToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.callTool(ctx, params)
+	case "resources/list":
+		return s.listResources(ctx)
+	case "resources/templates/list":
+		return ListResourceTemplatesResult{ResourceTemplates: []ResourceTemplate{storyTemplate}}, nil
+	case "resources/read":
+		var params ReadResourceParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.readResource(ctx, params.URI)
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", req.Method)}
+}
+
+// negotiateVersion returns the requested protocol version when supported, and
+// the latest supported version otherwise.
+func negotiateVersion(requested string) string {
+	for _, v := range protocolVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return protocolVersions[0]
+}
+
+// relPath returns path relative to the repository root with forward slashes.
+func (s *Server) relPath(path string) string {
+	if rel, err := filepath.Rel(s.cfg.RepoPath, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,504 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+// toolHandler runs a tool with its decoded arguments.
+type toolHandler func(s *Server, ctx context.Context, args json.RawMessage) (interface{}, error)
+
+// toolEntry pairs a tool definition with its handler.
+type toolEntry struct {
+	tool Tool
+	call toolHandler
+	// available reports whether the server is configured for the tool.
+	available func(cfg Config) bool
+}
+
+// Schema helpers for tool input schemas.
+func object(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func boolProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "boolean", "description": description}
+}
+
+func stringsProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": description}
+}
+
+func fieldsProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "description": description}
+}
+
+var (
+	readOnly    = &ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true}
+	writes      = &ToolAnnotations{}
+	idempotent  = &ToolAnnotations{IdempotentHint: true}
+	sprintParam = stringProp(`"backlog", a sprint name, or "current" for the active sprint`)
+)
+
+// toolEntries lists every tool in the order shown to clients.
+var toolEntries = []toolEntry{
+	{
+		tool: Tool{
+			Name:        "list_stories",
+			Title:       "List stories",
+			Description: "List stories with their status, priority, assignee, tags and location, sorted by ID. All filters are optional.",
+			InputSchema: object(map[string]interface{}{
+				"sprint":   sprintParam,
+				"status":   stringProp("Only stories with this status"),
+				"priority": stringProp("Only stories with this priority (low, medium, high, critical)"),
+				"assignee": stringProp("Only stories assigned to this user"),
+				"tag":      stringProp("Only stories with this tag"),
+			}),
+			Annotations: readOnly,
+		},
+		call: (*Server).listStories,
+	},
+	{
+		tool: Tool{
+			Name:        "search_stories",
+			Title:       "Search stories",
+			Description: "Find stories whose ID, title or Markdown body contains the query (case-insensitive).",
+			InputSchema: object(map[string]interface{}{
+				"query":  stringProp("Text to search for"),
+				"sprint": sprintParam,
+			}, "query"),
+			Annotations: readOnly,
+		},
+		call: (*Server).searchStories,
+	},
+	{
+		tool: Tool{
+			Name:        "get_story",
+			Title:       "Get story",
+			Description: "Get one story with its custom fields, Markdown body and file path.",
+			InputSchema: object(map[string]interface{}{
+				"id": stringProp("Story ID, e.g. US-004"),
+			}, "id"),
+			Annotations: readOnly,
+		},
+		call: (*Server).getStory,
+	},
+	{
+		tool: Tool{
+			Name:        "list_sprints",
+			Title:       "List sprints",
+			Description: "List sprints with their state (active, ready, planning, archived) and progress.",
+			InputSchema: object(map[string]interface{}{}),
+			Annotations: readOnly,
+		},
+		call: (*Server).listSprints,
+	},
+	{
+		tool: Tool{
+			Name:        "create_story",
+			Title:       "Create story",
+			Description: "Create a story in the backlog with a new ID. Status defaults to the workflow's initial status and priority to medium.",
+			InputSchema: object(map[string]interface{}{
+				"title":    stringProp("Story title"),
+				"prefix":   stringProp("Two-letter ID prefix (default US)"),
+				"status":   stringProp("Initial status"),
+				"priority": stringProp("low, medium, high or critical"),
+				"assignee": stringProp("Assignee user name"),
+				"tags":     stringsProp("Tags"),
+				"fields":   fieldsProp("Custom field values by name"),
+				"body":     stringProp("Markdown body"),
+			}, "title"),
+			Annotations: writes,
+		},
+		call: (*Server).createStory,
+	},
+	{
+		tool: Tool{
+			Name:        "update_story",
+			Title:       "Update story",
+			Description: "Change a story's status, title, priority, assignee, tags, custom fields or body. Only the given attributes change. Status changes must follow the workflow unless force is set.",
+			InputSchema: object(map[string]interface{}{
+				"id":       stringProp("Story ID"),
+				"status":   stringProp("New status"),
+				"force":    boolProp("Allow a status change the workflow does not allow"),
+				"title":    stringProp("New title"),
+				"priority": stringProp("low, medium, high or critical"),
+				"assignee": stringProp("New assignee; an empty string clears it"),
+				"tags":     stringsProp("Replacement tags"),
+				"fields":   fieldsProp("Custom field values by name; null removes a field"),
+				"body":     stringProp("Replacement Markdown body"),
+			}, "id"),
+			Annotations: idempotent,
+		},
+		call: (*Server).updateStory,
+	},
+	{
+		tool: Tool{
+			Name:        "move_story",
+			Title:       "Move story",
+			Description: "Move a story file to the backlog, a sprint, or a directory relative to the repository.",
+			InputSchema: object(map[string]interface{}{
+				"id":    stringProp("Story ID"),
+				"to":    stringProp(`"backlog", a sprint name, "current", or a directory relative to the repository`),
+				"force": boolProp("Overwrite a file with the same name in the target"),
+			}, "id", "to"),
+			Annotations: idempotent,
+		},
+		call: (*Server).moveStory,
+	},
+	{
+		tool: Tool{
+			Name:        "start_story",
+			Title:       "Start story",
+			Description: "Create or check out the story's feature branch in the working tree and set its assignee (default: the Git user name).",
+			InputSchema: object(map[string]interface{}{
+				"id":       stringProp("Story ID"),
+				"assignee": stringProp("Assignee to set"),
+			}, "id"),
+			Annotations: writes,
+		},
+		call:      (*Server).startStory,
+		available: func(cfg Config) bool { return cfg.Start != nil },
+	},
+	{
+		tool: Tool{
+			Name:        "get_burndown",
+			Title:       "Get sprint burndown",
+			Description: "Get a sprint's burndown from Git history: remaining points and tasks per day, the ideal line and the projected completion.",
+			InputSchema: object(map[string]interface{}{
+				"sprint": stringProp(`Sprint name, or "current" for the active sprint (default)`),
+			}),
+			Annotations: readOnly,
+		},
+		call:      (*Server).getBurndown,
+		available: func(cfg Config) bool { return cfg.Burndown != nil },
+	},
+}
+
+// tools returns the definitions of the available tools.
+func (s *Server) tools() []Tool {
+	tools := make([]Tool, 0, len(toolEntries))
+	for _, entry := range toolEntries {
+		if entry.available == nil || entry.available(s.cfg) {
+			tools = append(tools, entry.tool)
+		}
+	}
+	return tools
+}
+
+// callTool runs a tool. Unknown tools are protocol errors; failures of the
+// tool itself are returned as error results.
+func (s *Server) callTool(ctx context.Context, params CallToolParams) (interface{}, *rpcError) {
+	for _, entry := range toolEntries {
+		if entry.tool.Name != params.Name || (entry.available != nil && !entry.available(s.cfg)) {
+			continue
+		}
+		result, err := entry.call(s, ctx, params.Arguments)
+		if err != nil {
+			return CallToolResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+		}
+		text, err := json.Marshal(result)
+		if err != nil {
+			return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		return CallToolResult{Content: []Content{{Type: "text", Text: string(text)}}, StructuredContent: result}, nil
+	}
+	return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+}
+
+// decodeArgs decodes tool arguments, rejecting unknown ones.
+func decodeArgs(args json.RawMessage, v interface{}) error {
+	if len(bytes.TrimSpace(args)) == 0 || string(bytes.TrimSpace(args)) == "null" {
+		args = json.RawMessage("{}")
+	}
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// storiesResult is the result of the list and search tools; structured tool
+// results must be objects.
+type storiesResult struct {
+	Stories []ui.StoryJSON `json:"stories"`
+}
+
+func (s *Server) filterStories(ctx context.Context, filter services.StoryFilter) (interface{}, error) {
+	snapshot, err := s.cfg.Board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := snapshot.Filter(filter)
+	if err != nil {
+		return nil, err
+	}
+	out := storiesResult{Stories: make([]ui.StoryJSON, 0, len(stories))}
+	for _, story := range stories {
+		out.Stories = append(out.Stories, ui.NewStoryJSON(story.Story, story.Status, story.Derived, story.Source))
+	}
+	return out, nil
+}
+
+// optional returns a one-value filter, or nil for an empty value.
+func optional(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+func (s *Server) listStories(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Sprint   string `json:"sprint"`
+		Status   string `json:"status"`
+		Priority string `json:"priority"`
+		Assignee string `json:"assignee"`
+		Tag      string `json:"tag"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	return s.filterStories(ctx, services.StoryFilter{
+		Sprint:     args.Sprint,
+		Statuses:   optional(args.Status),
+		Priorities: optional(args.Priority),
+		Assignees:  optional(args.Assignee),
+		Tags:       optional(args.Tag),
+	})
+}
+
+func (s *Server) searchStories(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Query  string `json:"query"`
+		Sprint string `json:"sprint"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if args.Query == "" {
+		return nil, fmt.Errorf("%w: query is required", services.ErrInvalidInput)
+	}
+	return s.filterStories(ctx, services.StoryFilter{Sprint: args.Sprint, Text: args.Query})
+}
+
+// story returns a story with its body and file, and the commit of a change.
+func (s *Server) story(ctx context.Context, id, commit string) (interface{}, error) {
+	_, path, err := s.cfg.Stories.FindStoryByID(ctx, s.cfg.RepoPath, id)
+	if err != nil {
+		if errors.Is(err, core.ErrStoryNotFound) {
+			return nil, fmt.Errorf("%w: %s", core.ErrStoryNotFound, id)
+		}
+		return nil, err
+	}
+	snapshot, err := s.cfg.Board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, story := range snapshot.Stories() {
+		if story.Story.ID == id {
+			out := ui.NewStoryJSON(story.Story, story.Status, story.Derived, story.Source)
+			out.File = s.relPath(path)
+			out.Body = &story.Story.Body
+			out.Commit = commit
+			return out, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", core.ErrStoryNotFound, id)
+}
+
+// changed returns the story of a change, keeping the change error.
+func (s *Server) changed(ctx context.Context, change *services.StoryChange, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	return s.story(ctx, change.ID, change.Commit)
+}
+
+func (s *Server) getStory(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	return s.story(ctx, args.ID, "")
+}
+
+// sprintJSON is a sprint in tool results.
+type sprintJSON struct {
+	Name   string `json:"name"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Path   string `json:"path"`
+	Total  int    `json:"total"`
+	Done   int    `json:"done"`
+}
+
+func (s *Server) listSprints(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct{}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	snapshot, err := s.cfg.Board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := struct {
+		Sprints []sprintJSON `json:"sprints"`
+	}{Sprints: make([]sprintJSON, 0, len(snapshot.Sprints))}
+	for _, sprint := range snapshot.Sprints {
+		item := sprintJSON{
+			Name:   sprint.Name,
+			Title:  services.SprintTitle(sprint.Name),
+			Status: sprint.Status.String(),
+			Path:   s.relPath(sprint.Path),
+			Total:  len(sprint.Stories),
+		}
+		for _, story := range sprint.Stories {
+			if s.cfg.Workflow.IsDone(story.Status) {
+				item.Done++
+			}
+		}
+		out.Sprints = append(out.Sprints, item)
+	}
+	return out, nil
+}
+
+func (s *Server) createStory(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Title    string                 `json:"title"`
+		Prefix   string                 `json:"prefix"`
+		Status   core.Status            `json:"status"`
+		Priority core.Priority          `json:"priority"`
+		Assignee string                 `json:"assignee"`
+		Tags     []string               `json:"tags"`
+		Fields   map[string]interface{} `json:"fields"`
+		Body     *string                `json:"body"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	req := services.CreateStoryRequest{
+		Title:            args.Title,
+		Prefix:           args.Prefix,
+		Status:           args.Status,
+		Priority:         args.Priority,
+		Tags:             args.Tags,
+		Fields:           args.Fields,
+		FieldDefinitions: s.cfg.Fields,
+	}
+	if args.Assignee != "" {
+		req.Assignee = &args.Assignee
+	}
+	change, err := s.cfg.Edit.CreateStory(ctx, req, args.Body)
+	return s.changed(ctx, change, err)
+}
+
+func (s *Server) updateStory(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		ID       string                 `json:"id"`
+		Status   *core.Status           `json:"status"`
+		Force    bool                   `json:"force"`
+		Title    *string                `json:"title"`
+		Priority *core.Priority         `json:"priority"`
+		Assignee *string                `json:"assignee"`
+		Tags     *[]string              `json:"tags"`
+		Fields   map[string]interface{} `json:"fields"`
+		Body     *string                `json:"body"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	change, err := s.cfg.Edit.UpdateStory(ctx, args.ID, services.StoryUpdate{
+		Status:   args.Status,
+		Force:    args.Force,
+		Title:    args.Title,
+		Priority: args.Priority,
+		Assignee: args.Assignee,
+		Tags:     args.Tags,
+		Fields:   args.Fields,
+		Body:     args.Body,
+	})
+	return s.changed(ctx, change, err)
+}
+
+func (s *Server) moveStory(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		ID    string `json:"id"`
+		To    string `json:"to"`
+		Force bool   `json:"force"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	change, err := s.cfg.Edit.MoveStory(ctx, args.ID, args.To, args.Force)
+	return s.changed(ctx, change, err)
+}
+
+func (s *Server) startStory(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		ID       string `json:"id"`
+		Assignee string `json:"assignee"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if args.ID == "" {
+		return nil, fmt.Errorf("%w: id is required", services.ErrInvalidInput)
+	}
+	var assignee *string
+	if args.Assignee != "" {
+		assignee = &args.Assignee
+	}
+	story, branch, err := s.cfg.Start.Start(ctx, s.cfg.RepoPath, args.ID, assignee)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		ID       string  `json:"id"`
+		Branch   string  `json:"branch"`
+		Assignee *string `json:"assignee"`
+	}{ID: story.ID, Branch: branch, Assignee: story.Assignee}, nil
+}
+
+func (s *Server) getBurndown(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Sprint string `json:"sprint"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if args.Sprint == "" {
+		args.Sprint = "current"
+	}
+	snapshot, err := s.cfg.Board.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sprint := snapshot.Sprint(args.Sprint)
+	if sprint == nil {
+		return nil, fmt.Errorf("%w: %s", core.ErrSprintNotFound, args.Sprint)
+	}
+	chart, err := s.cfg.Burndown.GenerateBurndownChart(ctx, sprint.Path)
+	if err != nil {
+		return nil, err
+	}
+	return ui.NewBurndownJSON(chart), nil
+}
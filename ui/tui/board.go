@@ -7,6 +7,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gavin/gitta/internal/core"
 )
 
 // Task represents a single task item displayed in a kanban column.
@@ -76,6 +77,52 @@ func newSampleBoard() BoardModel {
 	}
 }
 
+// categoryTitles are the column headers for the three workflow categories.
+var categoryTitles = [3]struct {
+	Category core.StatusCategory
+	Title    string
+}{
+	{core.CategoryTodo, "To Do"},
+	{core.CategoryInProgress, "In Progress"},
+	{core.CategoryDone, "Done"},
+}
+
+// NewWorkflowBoard groups tasks into the three workflow categories (to do, in
+// progress, done). Column titles list the workflow states they contain, and
+// tasks with a status outside the workflow are shown under To Do.
+func NewWorkflowBoard(workflow core.Workflow, tasks []Task) BoardModel {
+	var columns [3]Column
+	for i, c := range categoryTitles {
+		title := c.Title
+		var names []string
+		for _, state := range workflow.StatesIn(c.Category) {
+			names = append(names, string(state.Name))
+		}
+		if len(names) > 0 {
+			title = fmt.Sprintf("%s (%s)", title, strings.Join(names, ", "))
+		}
+		columns[i] = Column{Title: title, Status: string(c.Category), Tasks: []Task{}}
+	}
+
+	for _, task := range tasks {
+		idx := 0
+		if state, ok := workflow.State(core.Status(task.Status)); ok {
+			for i, c := range categoryTitles {
+				if c.Category == state.Category {
+					idx = i
+				}
+			}
+		}
+		columns[idx].Tasks = append(columns[idx].Tasks, task)
+	}
+
+	return BoardModel{
+		Columns: columns,
+		Width:   80,
+		Height:  24,
+	}
+}
+
 var (
 	columnStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
@@ -227,11 +274,15 @@ func (m BoardModel) View() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, col1, col2, col3)
 }
 
-// ShowBoard displays an interactive kanban board TUI.
+// ShowBoard displays an interactive kanban board TUI with sample data.
 // It creates a Bubble Tea program with the board model and runs it until the user quits.
 // Returns an error if the TUI fails to initialize or run.
 func ShowBoard(ctx context.Context) error {
-	model := newSampleBoard()
+	return RunBoard(ctx, newSampleBoard())
+}
+
+// RunBoard displays the given board model until the user quits.
+func RunBoard(ctx context.Context, model BoardModel) error {
 	model.Ctx = ctx
 
 	// Check context cancellation before starting
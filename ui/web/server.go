@@ -0,0 +1,150 @@
+// Package web serves the gitta REST API and the embedded web board.
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+)
+
+//go:embed openapi.json static
+var content embed.FS
+
+// maxRequestBody limits JSON request bodies; story files are small.
+const maxRequestBody = 1 << 20
+
+// Config holds the services the server delegates to.
+type Config struct {
+	// RepoPath is the repository root.
+	RepoPath string
+	// Workflow is the story workflow shown on the board.
+	Workflow core.Workflow
+	// Fields are the project's custom field definitions for new stories.
+	Fields  []core.FieldDefinition
+	Stories core.StoryRepository
+	Board   services.BoardService
+	// Edit applies and optionally commits story changes.
+	Edit services.StoryEditService
+	// Burndown generates sprint burndowns; nil disables the burndown endpoint.
+	Burndown services.SprintBurndownService
+}
+
+// Server is the HTTP adapter over gitta's services.
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+}
+
+// NewServer creates a Server with the API under /api/ and the board at /.
+func NewServer(cfg Config) *Server {
+	s := &Server{cfg: cfg, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("GET /api/openapi.json", s.handleOpenAPI)
+	s.mux.HandleFunc("GET /api/workflow", s.handleWorkflow)
+	s.mux.HandleFunc("GET /api/stories", s.handleListStories)
+	s.mux.HandleFunc("POST /api/stories", s.handleCreateStory)
+	s.mux.HandleFunc("GET /api/stories/{id}", s.handleGetStory)
+	s.mux.HandleFunc("PATCH /api/stories/{id}", s.handleUpdateStory)
+	s.mux.HandleFunc("POST /api/stories/{id}/move", s.handleMoveStory)
+	s.mux.HandleFunc("GET /api/sprints", s.handleListSprints)
+	s.mux.HandleFunc("GET /api/sprints/{name}", s.handleGetSprint)
+	s.mux.HandleFunc("GET /api/sprints/{name}/burndown", s.handleBurndown)
+	s.mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no such endpoint: %s %s", r.Method, r.URL.Path))
+	})
+
+	// The board is registered without a method so it does not conflict with
+	// the /api/ catch-all; writes are rejected here instead.
+	static, _ := fs.Sub(content, "static")
+	files := http.FileServer(http.FS(static))
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := content.ReadFile("openapi.json")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// relPath returns path relative to the repository root with forward slashes.
+func (s *Server) relPath(path string) string {
+	if rel, err := filepath.Rel(s.cfg.RepoPath, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// errorJSON is the body of every error response.
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorJSON{Error: err.Error()})
+}
+
+// writeServiceError maps service errors to HTTP statuses.
+func writeServiceError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, core.ErrStoryNotFound), errors.Is(err, core.ErrSprintNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidTransition):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvalidInput), errors.Is(err, services.ErrValidationFailed):
+		status = http.StatusBadRequest
+	case errors.Is(err, core.ErrInsufficientHistory):
+		status = http.StatusUnprocessableEntity
+	}
+	writeError(w, status, err)
+}
+
+// decodeBody decodes a JSON request body into v. Requiring a JSON content type
+// keeps other web pages from posting to the API with plain HTML forms.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("Content-Type must be application/json"))
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
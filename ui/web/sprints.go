@@ -0,0 +1,121 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+// sprintJSON is a sprint in API responses. Stories are only listed for a
+// single sprint.
+type sprintJSON struct {
+	Name    string         `json:"name"`
+	Title   string         `json:"title"`
+	Status  string         `json:"status"`
+	Path    string         `json:"path"`
+	Total   int            `json:"total"`
+	Done    int            `json:"done"`
+	Stories []ui.StoryJSON `json:"stories,omitempty"`
+}
+
+func (s *Server) toSprintJSON(sprint *services.SprintSnapshot) sprintJSON {
+	out := sprintJSON{
+		Name:   sprint.Name,
+		Title:  services.SprintTitle(sprint.Name),
+		Status: sprint.Status.String(),
+		Path:   s.relPath(sprint.Path),
+		Total:  len(sprint.Stories),
+	}
+	for _, story := range sprint.Stories {
+		if s.cfg.Workflow.IsDone(story.Status) {
+			out.Done++
+		}
+	}
+	return out
+}
+
+// workflowJSON describes the board columns.
+type workflowJSON struct {
+	Initial string              `json:"initial"`
+	States  []workflowStateJSON `json:"states"`
+}
+
+type workflowStateJSON struct {
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Transitions []string `json:"transitions"`
+}
+
+func (s *Server) handleWorkflow(w http.ResponseWriter, r *http.Request) {
+	out := workflowJSON{Initial: string(s.cfg.Workflow.Initial), States: make([]workflowStateJSON, 0, len(s.cfg.Workflow.States))}
+	for _, state := range s.cfg.Workflow.States {
+		transitions := make([]string, 0, len(state.Transitions))
+		for _, t := range state.Transitions {
+			transitions = append(transitions, string(t))
+		}
+		out.States = append(out.States, workflowStateJSON{Name: string(state.Name), Category: string(state.Category), Transitions: transitions})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleListSprints(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.cfg.Board.Snapshot(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	out := make([]sprintJSON, 0, len(snapshot.Sprints))
+	for _, sprint := range snapshot.Sprints {
+		out = append(out, s.toSprintJSON(sprint))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// findSprint resolves a sprint by folder name or title; "current" is the
+// active sprint.
+func (s *Server) findSprint(r *http.Request) (*services.SprintSnapshot, error) {
+	snapshot, err := s.cfg.Board.Snapshot(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	name := r.PathValue("name")
+	if sprint := snapshot.Sprint(name); sprint != nil {
+		return sprint, nil
+	}
+	return nil, fmt.Errorf("%w: %s", core.ErrSprintNotFound, name)
+}
+
+func (s *Server) handleGetSprint(w http.ResponseWriter, r *http.Request) {
+	sprint, err := s.findSprint(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	out := s.toSprintJSON(sprint)
+	out.Stories = make([]ui.StoryJSON, 0, len(sprint.Stories))
+	for _, story := range sprint.Stories {
+		out.Stories = append(out.Stories, toStoryJSON(story))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleBurndown(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Burndown == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("burndown is not available"))
+		return
+	}
+	sprint, err := s.findSprint(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	chart, err := s.cfg.Burndown.GenerateBurndownChart(r.Context(), sprint.Path)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ui.NewBurndownJSON(chart))
+}
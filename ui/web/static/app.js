@@ -0,0 +1,163 @@
+// Board for 'gitta serve': one column per workflow state. Dragging a card
+// changes the story status; clicking it opens the editor.
+(function () {
+  var board = document.getElementById("board");
+  var sprintSelect = document.getElementById("sprint");
+  var errorBox = document.getElementById("error");
+  var dialog = document.getElementById("story");
+  var form = document.getElementById("story-form");
+  var workflow = null;
+  var sprints = [];
+  var editing = null; // story being edited, or null for a new story
+
+  function api(method, path, body) {
+    var options = { method: method, headers: {} };
+    if (body !== undefined) {
+      options.headers["Content-Type"] = "application/json";
+      options.body = JSON.stringify(body);
+    }
+    return fetch("api/" + path, options).then(function (response) {
+      return response.json().then(function (data) {
+        if (!response.ok) throw new Error(data.error || response.statusText);
+        return data;
+      });
+    });
+  }
+
+  function showError(err) {
+    errorBox.textContent = err ? err.message : "";
+    errorBox.hidden = !err;
+  }
+
+  function option(select, value, label) {
+    var o = document.createElement("option");
+    o.value = value;
+    o.textContent = label || value;
+    select.appendChild(o);
+  }
+
+  function loadBoard() {
+    var selected = sprintSelect.value;
+    var path = selected === "backlog" ? "stories?sprint=backlog" : "sprints/" + encodeURIComponent(selected);
+    return api("GET", path).then(function (data) {
+      render(Array.isArray(data) ? data : data.stories || []);
+      showError(null);
+    }).catch(showError);
+  }
+
+  function render(stories) {
+    board.innerHTML = "";
+    workflow.states.forEach(function (state) {
+      var column = document.createElement("section");
+      column.className = "column";
+      column.dataset.status = state.name;
+      var heading = document.createElement("h2");
+      column.appendChild(heading);
+      var count = 0;
+      stories.forEach(function (story) {
+        if (story.status !== state.name) return;
+        column.appendChild(card(story));
+        count++;
+      });
+      heading.textContent = state.name + " (" + count + ")";
+      column.addEventListener("dragover", function (e) { e.preventDefault(); column.classList.add("over"); });
+      column.addEventListener("dragleave", function () { column.classList.remove("over"); });
+      column.addEventListener("drop", function (e) {
+        e.preventDefault();
+        column.classList.remove("over");
+        var id = e.dataTransfer.getData("text/plain");
+        api("PATCH", "stories/" + encodeURIComponent(id), { status: state.name }).then(loadBoard).catch(showError);
+      });
+      board.appendChild(column);
+    });
+  }
+
+  function card(story) {
+    var el = document.createElement("article");
+    el.className = "card";
+    el.draggable = true;
+    var id = document.createElement("div");
+    id.className = "id";
+    id.textContent = story.id;
+    var title = document.createElement("div");
+    title.textContent = story.title;
+    var meta = document.createElement("div");
+    meta.className = "meta";
+    meta.textContent = [story.priority, story.assignee].filter(Boolean).join(" · ");
+    el.appendChild(id);
+    el.appendChild(title);
+    el.appendChild(meta);
+    el.addEventListener("dragstart", function (e) { e.dataTransfer.setData("text/plain", story.id); });
+    el.addEventListener("click", function () {
+      api("GET", "stories/" + encodeURIComponent(story.id)).then(openEditor).catch(showError);
+    });
+    return el;
+  }
+
+  function openEditor(story) {
+    editing = story;
+    document.getElementById("story-heading").textContent = story ? story.id : "New story";
+    form.title.value = story ? story.title : "";
+    form.status.value = story ? story.status : workflow.initial;
+    form.priority.value = story ? story.priority || "medium" : "medium";
+    form.assignee.value = story && story.assignee ? story.assignee : "";
+    form.tags.value = story ? story.tags.join(", ") : "";
+    form.body.value = story ? story.body || "" : "";
+    form.location.value = story ? (story.location === "Backlog" ? "backlog" : story.location) : "backlog";
+    document.getElementById("story-meta").textContent = story && story.file ? story.file : "";
+    dialog.showModal();
+  }
+
+  form.addEventListener("submit", function (e) {
+    if (e.submitter && e.submitter.value !== "save") return;
+    e.preventDefault();
+    var tags = form.tags.value.split(",").map(function (t) { return t.trim(); }).filter(Boolean);
+    var change = {
+      title: form.title.value,
+      status: form.status.value,
+      priority: form.priority.value,
+      assignee: form.assignee.value,
+      tags: tags,
+      body: form.body.value
+    };
+    var location = form.location.value;
+    var saved;
+    if (editing) {
+      // Only send what changed, so derived statuses are not pinned
+      var before = { title: editing.title, status: editing.status, priority: editing.priority || "medium",
+        assignee: editing.assignee || "", tags: editing.tags, body: editing.body || "" };
+      Object.keys(change).forEach(function (key) {
+        if (JSON.stringify(change[key]) === JSON.stringify(before[key])) delete change[key];
+      });
+      var current = editing.location === "Backlog" ? "backlog" : editing.location;
+      var patched = Object.keys(change).length ? api("PATCH", "stories/" + encodeURIComponent(editing.id), change) : Promise.resolve(editing);
+      saved = patched.then(function (story) {
+        return location === current ? story : api("POST", "stories/" + encodeURIComponent(story.id) + "/move", { to: location });
+      });
+    } else {
+      saved = api("POST", "stories", change).then(function (story) {
+        return location === "backlog" ? story : api("POST", "stories/" + encodeURIComponent(story.id) + "/move", { to: location });
+      });
+    }
+    saved.then(function () { dialog.close(); return loadBoard(); }).catch(showError);
+  });
+
+  document.getElementById("new-story").addEventListener("click", function () { openEditor(null); });
+  sprintSelect.addEventListener("change", loadBoard);
+
+  Promise.all([api("GET", "workflow"), api("GET", "sprints")]).then(function (results) {
+    workflow = results[0];
+    sprints = results[1];
+    workflow.states.forEach(function (state) { option(form.status, state.name); });
+    var current = null;
+    sprints.forEach(function (sprint) {
+      option(sprintSelect, sprint.name, sprint.title + " (" + sprint.status + ")");
+      option(form.location, sprint.name, sprint.title);
+      if (sprint.status === "active") current = sprint.name;
+    });
+    option(sprintSelect, "backlog", "Backlog");
+    option(form.location, "backlog", "Backlog");
+    sprintSelect.value = current || "backlog";
+    return loadBoard();
+  }).catch(showError);
+})();
@@ -0,0 +1,172 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gavin/gitta/internal/core"
+	"github.com/gavin/gitta/internal/services"
+	"github.com/gavin/gitta/pkg/ui"
+)
+
+func toStoryJSON(s *services.StoryWithStatus) ui.StoryJSON {
+	return ui.NewStoryJSON(s.Story, s.Status, s.Derived, s.Source)
+}
+
+// findStory returns a story with its derived status and location, and its file.
+func (s *Server) findStory(ctx context.Context, id string) (*services.StoryWithStatus, string, error) {
+	snapshot, err := s.cfg.Board.Snapshot(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	_, path, err := s.cfg.Stories.FindStoryByID(ctx, s.cfg.RepoPath, id)
+	if err != nil {
+		if errors.Is(err, core.ErrStoryNotFound) {
+			return nil, "", fmt.Errorf("%w: %s", core.ErrStoryNotFound, id)
+		}
+		return nil, "", err
+	}
+	for _, story := range snapshot.Stories() {
+		if story.Story.ID == id {
+			return story, path, nil
+		}
+	}
+	return nil, "", fmt.Errorf("%w: %s", core.ErrStoryNotFound, id)
+}
+
+// writeStory responds with a story read back after a change.
+func (s *Server) writeStory(w http.ResponseWriter, r *http.Request, status int, id, commit string) {
+	story, path, err := s.findStory(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	out := toStoryJSON(story)
+	out.File = s.relPath(path)
+	out.Body = &story.Story.Body
+	out.Commit = commit
+	writeJSON(w, status, out)
+}
+
+// writeChange responds with the story of a change, or with the change error.
+func (s *Server) writeChange(w http.ResponseWriter, r *http.Request, status int, change *services.StoryChange, err error) {
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	s.writeStory(w, r, status, change.ID, change.Commit)
+}
+
+func (s *Server) handleListStories(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.cfg.Board.Snapshot(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	stories, err := snapshot.Filter(services.StoryFilter{
+		Sprint:     query.Get("sprint"),
+		Statuses:   query["status"],
+		Priorities: query["priority"],
+		Assignees:  query["assignee"],
+		Tags:       query["tag"],
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	out := make([]ui.StoryJSON, 0, len(stories))
+	for _, story := range stories {
+		out = append(out, toStoryJSON(story))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleGetStory(w http.ResponseWriter, r *http.Request) {
+	s.writeStory(w, r, http.StatusOK, r.PathValue("id"), "")
+}
+
+// createRequest is the body of POST /api/stories.
+type createRequest struct {
+	Title    string                 `json:"title"`
+	Prefix   string                 `json:"prefix"`
+	Status   core.Status            `json:"status"`
+	Priority core.Priority          `json:"priority"`
+	Assignee string                 `json:"assignee"`
+	Tags     []string               `json:"tags"`
+	Fields   map[string]interface{} `json:"fields"`
+	Body     *string                `json:"body"`
+}
+
+func (s *Server) handleCreateStory(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	create := services.CreateStoryRequest{
+		Title:            req.Title,
+		Prefix:           req.Prefix,
+		Status:           req.Status,
+		Priority:         req.Priority,
+		Tags:             req.Tags,
+		Fields:           req.Fields,
+		FieldDefinitions: s.cfg.Fields,
+	}
+	if req.Assignee != "" {
+		create.Assignee = &req.Assignee
+	}
+	change, err := s.cfg.Edit.CreateStory(r.Context(), create, req.Body)
+	s.writeChange(w, r, http.StatusCreated, change, err)
+}
+
+// updateRequest is the body of PATCH /api/stories/{id}. Omitted fields are
+// left unchanged; a null field value removes the field.
+type updateRequest struct {
+	Status   *core.Status           `json:"status"`
+	Force    bool                   `json:"force"`
+	Title    *string                `json:"title"`
+	Priority *core.Priority         `json:"priority"`
+	Assignee *string                `json:"assignee"`
+	Tags     *[]string              `json:"tags"`
+	Fields   map[string]interface{} `json:"fields"`
+	Body     *string                `json:"body"`
+}
+
+func (s *Server) handleUpdateStory(w http.ResponseWriter, r *http.Request) {
+	var req updateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	change, err := s.cfg.Edit.UpdateStory(r.Context(), r.PathValue("id"), services.StoryUpdate{
+		Status:   req.Status,
+		Force:    req.Force,
+		Title:    req.Title,
+		Priority: req.Priority,
+		Assignee: req.Assignee,
+		Tags:     req.Tags,
+		Fields:   req.Fields,
+		Body:     req.Body,
+	})
+	s.writeChange(w, r, http.StatusOK, change, err)
+}
+
+// moveRequest is the body of POST /api/stories/{id}/move.
+type moveRequest struct {
+	// To is "backlog", a sprint name, or a directory relative to the
+	// repository root.
+	To    string `json:"to"`
+	Force bool   `json:"force"`
+}
+
+func (s *Server) handleMoveStory(w http.ResponseWriter, r *http.Request) {
+	var req moveRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	change, err := s.cfg.Edit.MoveStory(r.Context(), r.PathValue("id"), req.To, req.Force)
+	s.writeChange(w, r, http.StatusOK, change, err)
+}